@@ -19,14 +19,18 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/alerting"
-	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
 )
 
 const (
@@ -37,16 +41,14 @@ const (
 	// alertSchedulerInterval is the interval at which the scheduler
 	// runs rule checks.
 	alertSchedulerInterval = time.Minute
-)
 
-func runAlerting(ctx context.Context, token, platformURL string, store *metrics.Store, fetcher *state.Fetcher) error {
-	retryableClient := retryablehttp.NewClient()
-	retryableClient.RetryWaitMin = time.Second
-	retryableClient.RetryWaitMax = 10 * time.Second
-	retryableClient.RetryMax = 4
-	retryableClient.Logger = logger.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "alerting_client").Logger())
+	// notifiersSecretKey is the key of the notifiers secret holding the notifiers configuration.
+	notifiersSecretKey = "notifiers.yaml"
+)
 
-	httpClient := retryableClient.StandardClient()
+func runAlerting(ctx context.Context, token, platformURL string, wrapTransport func(http.RoundTripper) http.RoundTripper, store *metrics.Store, fetcher *state.Fetcher, notifiers []alerting.Notifier) error {
+	httpClient := newAlertingHTTPClient()
+	httpClient.Transport = wrapTransport(httpClient.Transport)
 
 	client, err := alerting.NewClient(httpClient, platformURL, token)
 	if err != nil {
@@ -54,14 +56,71 @@ func runAlerting(ctx context.Context, token, platformURL string, store *metrics.
 	}
 
 	threshProc := alerting.NewThresholdProcessor(metrics.NewDataPointView(store), fetcher)
+	certProc := alerting.NewCertificateExpiryProcessor(fetcher)
 
 	mgr := alerting.NewManager(client,
 		map[string]alerting.Processor{
-			alerting.ThresholdType: threshProc,
+			alerting.ThresholdType:         threshProc,
+			alerting.CertificateExpiryType: certProc,
 		},
 		alertRefreshInterval,
 		alertSchedulerInterval,
 	)
 
+	for _, notifier := range notifiers {
+		mgr.AddNotifier(notifier)
+	}
+
 	return mgr.Run(ctx)
 }
+
+// newAlertingNotifiers returns the notifiers configured in the given secret of the agent namespace, if any, along
+// with a webhook notifier for the given URL, if any.
+func newAlertingNotifiers(ctx context.Context, kubeClient clientset.Interface, secretName, webhookURL string) ([]alerting.Notifier, error) {
+	httpClient := newAlertingHTTPClient()
+
+	var notifiers []alerting.Notifier
+	if webhookURL != "" {
+		webhook, err := alerting.NewWebhookNotifier(httpClient, webhookURL, "")
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, webhook)
+	}
+
+	if secretName == "" {
+		return notifiers, nil
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(currentNamespace()).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get notifiers secret: %w", err)
+	}
+
+	data, ok := secret.Data[notifiersSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("notifiers secret %q has no %q key", secretName, notifiersSecretKey)
+	}
+
+	cfg, err := alerting.ParseNotifiersConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	configured, err := alerting.NewNotifiers(httpClient, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create notifiers: %w", err)
+	}
+
+	return append(notifiers, configured...), nil
+}
+
+func newAlertingHTTPClient() *http.Client {
+	retryableClient := retryablehttp.NewClient()
+	retryableClient.RetryWaitMin = time.Second
+	retryableClient.RetryWaitMax = 10 * time.Second
+	retryableClient.RetryMax = 4
+	retryableClient.Logger = logwrapper.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "alerting_client").Logger())
+
+	return retryableClient.StandardClient()
+}
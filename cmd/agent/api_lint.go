@@ -0,0 +1,53 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	"github.com/urfave/cli/v2"
+)
+
+const flagAPILintRulesetFile = "api-lint.ruleset-file"
+
+func apiLintFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagAPILintRulesetFile,
+			Usage:   "Path to the YAML ruleset used to lint the OpenAPI specs of the APIs. The default ruleset is used when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagAPILintRulesetFile)},
+		},
+	}
+}
+
+// loadLintRuleset loads the ruleset configured through the API lint flags.
+func loadLintRuleset(cliCtx *cli.Context) (lint.Ruleset, error) {
+	path := cliCtx.String(flagAPILintRulesetFile)
+	if path == "" {
+		return lint.DefaultRuleset(), nil
+	}
+
+	ruleset, err := lint.LoadRuleset(path)
+	if err != nil {
+		return lint.Ruleset{}, fmt.Errorf("load API lint ruleset: %w", err)
+	}
+
+	return ruleset, nil
+}
@@ -19,24 +19,45 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	stdlog "log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/bruteforce"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/filesource"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	hublisters "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
-	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 	"github.com/urfave/cli/v2"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	flagAccessLog                  = "access-log"
+	flagAccessLogSuccessSampleRate = "access-log.success-sample-rate"
+	flagAPITokenCacheTTL           = "api-token.cache-ttl"
+	flagAPITokenRevocationSync     = "api-token.revocation-sync-interval"
+	flagLongLivedReauthInterval    = "long-lived.reauth-interval"
+	flagBruteForceMaxFailures      = "brute-force.max-failures"
+	flagBruteForceWindow           = "brute-force.window"
+	flagBruteForceBlockDuration    = "brute-force.block-duration"
+	flagBruteForceTarpit           = "brute-force.tarpit"
+	flagSimulateToken              = "simulate.token"
+	flagACPResyncInterval          = "acp.resync-interval"
+	flagACPFiles                   = "acp.files"
+	flagStandalone                 = "standalone"
 )
 
 type authServerCmd struct {
@@ -47,13 +68,100 @@ func newAuthServerCmd() authServerCmd {
 	flgs := []cli.Flag{
 		&cli.StringFlag{
 			Name:    flagListenAddr,
-			Usage:   "Address on which the auth server listens for auth requests",
+			Usage:   "Address on which the auth server listens for auth requests. Use a loopback address, such as 127.0.0.1:80, to only accept requests from a colocated ingress controller, or unix:<path> to listen on a Unix domain socket",
 			EnvVars: []string{"AUTH_SERVER_LISTEN_ADDR"},
 			Value:   "0.0.0.0:80",
 		},
+		&cli.BoolFlag{
+			Name:    flagAccessLog,
+			Usage:   "Enable JSON access logs of the auth decisions on the standard output",
+			EnvVars: []string{"AUTH_SERVER_ACCESS_LOG"},
+		},
+		&cli.Float64Flag{
+			Name:    flagAccessLogSuccessSampleRate,
+			Usage:   "Ratio, between 0 and 1, of allowed requests to log. Denied requests and errors are always logged",
+			EnvVars: []string{"AUTH_SERVER_ACCESS_LOG_SUCCESS_SAMPLE_RATE"},
+			Value:   1,
+		},
+		&cli.StringFlag{
+			Name:    flagPlatformURL,
+			Usage:   "The URL at which to reach the Hub platform API",
+			Value:   "https://platform.hub.traefik.io/agent",
+			EnvVars: []string{"AUTH_SERVER_PLATFORM_URL"},
+			Hidden:  true,
+		},
+		&cli.StringFlag{
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls. API tokens of the requests sent to the APIGateways aren't validated when neither it nor --" + flagTokenFile + " is set",
+			EnvVars: []string{"AUTH_SERVER_TOKEN"},
+		},
+		tokenFileFlag("AUTH_SERVER_WORKLOAD_IDENTITY_TOKEN_FILE"),
+		&cli.DurationFlag{
+			Name:    flagAPITokenCacheTTL,
+			Usage:   "Duration during which API token validation results are cached",
+			EnvVars: []string{"AUTH_SERVER_API_TOKEN_CACHE_TTL"},
+			Value:   time.Minute,
+		},
+		&cli.DurationFlag{
+			Name:    flagAPITokenRevocationSync,
+			Usage:   "Interval at which revoked and suspended API tokens are synchronized from the platform",
+			EnvVars: []string{"AUTH_SERVER_API_TOKEN_REVOCATION_SYNC_INTERVAL"},
+			Value:   5 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagLongLivedReauthInterval,
+			Usage:   "Interval at which the backends of authorized WebSocket and Server-Sent Events connections must re-authenticate them through the control channel. Connections aren't re-authenticated when zero. The controller acp-server.long-lived-reauth-interval flag must be set too, so the generated middlewares forward the connection ID",
+			EnvVars: []string{"AUTH_SERVER_LONG_LIVED_REAUTH_INTERVAL"},
+		},
+		&cli.IntFlag{
+			Name:    flagBruteForceMaxFailures,
+			Usage:   "Number of failed authentications on an ACP after which a client IP or a basic auth username is blocked. Clients aren't blocked when zero",
+			EnvVars: []string{"AUTH_SERVER_BRUTE_FORCE_MAX_FAILURES"},
+		},
+		&cli.DurationFlag{
+			Name:    flagBruteForceWindow,
+			Usage:   "Period over which failed authentications are counted",
+			EnvVars: []string{"AUTH_SERVER_BRUTE_FORCE_WINDOW"},
+			Value:   time.Minute,
+		},
+		&cli.DurationFlag{
+			Name:    flagBruteForceBlockDuration,
+			Usage:   "Duration during which blocked clients are rejected",
+			EnvVars: []string{"AUTH_SERVER_BRUTE_FORCE_BLOCK_DURATION"},
+			Value:   5 * time.Minute,
+		},
+		&cli.DurationFlag{
+			Name:    flagBruteForceTarpit,
+			Usage:   "Delay before rejecting the requests of blocked clients",
+			EnvVars: []string{"AUTH_SERVER_BRUTE_FORCE_TARPIT"},
+		},
+		&cli.StringFlag{
+			Name:    flagSimulateToken,
+			Usage:   "Bearer token required to call the " + auth.SimulatePathPrefix + " endpoint, returning the decision an ACP takes for a synthetic request POSTed as JSON. Simulated failures are logged and counted as failed authentications. The endpoint is disabled when empty",
+			EnvVars: []string{"AUTH_SERVER_SIMULATE_TOKEN"},
+		},
+		&cli.DurationFlag{
+			Name:    flagACPResyncInterval,
+			Usage:   "Interval at which the informers watching the ACPs and their Secrets replay their whole cache. Disabled when 0",
+			EnvVars: []string{"AUTH_SERVER_ACP_RESYNC_INTERVAL"},
+			Value:   5 * time.Minute,
+		},
+		&cli.StringSliceFlag{
+			Name:    flagACPFiles,
+			Usage:   "Files or directories holding the ACPs, and the Secrets and APIGateways they use. When set, they are read from these files, and reloaded when modified, instead of the Kubernetes API",
+			EnvVars: []string{"AUTH_SERVER_ACP_FILES"},
+		},
+		&cli.BoolFlag{
+			Name:    flagStandalone,
+			Usage:   "Run without Kubernetes, for instance as a sidecar of a Traefik proxy running outside Kubernetes. The ACPs are read from the files set with --" + flagACPFiles + " and the TLS certificate, if any, from the files set with --" + flagTLSCertFile,
+			EnvVars: []string{"AUTH_SERVER_STANDALONE"},
+		},
 	}
 
+	flgs = append(flgs, tokenRotationFlags("AUTH_SERVER_")...)
 	flgs = append(flgs, globalFlags()...)
+	flgs = append(flgs, serverFlags()...)
+	flgs = append(flgs, tlsFlags()...)
 
 	return authServerCmd{
 		flags: flgs,
@@ -70,104 +178,253 @@ func (c authServerCmd) build() *cli.Command {
 }
 
 func (c authServerCmd) run(cliCtx *cli.Context) error {
-	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+	loggers := logwrapper.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	version.Log()
 
-	config, err := kube.InClusterConfigWithRetrier(2)
-	if err != nil {
-		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+	acpFiles := cliCtx.StringSlice(flagACPFiles)
+	if cliCtx.Bool(flagStandalone) {
+		if len(acpFiles) == 0 {
+			return fmt.Errorf("--%s requires --%s", flagStandalone, flagACPFiles)
+		}
+		if cliCtx.String(flagTLSSecret) != "" {
+			return fmt.Errorf("--%s can't be used with --%s, use --%s instead", flagTLSSecret, flagStandalone, flagTLSCertFile)
+		}
 	}
 
-	hubClientSet, err := hubclientset.NewForConfig(config)
+	// The Kubernetes API isn't reached when the ACPs are read from files, unless the TLS certificate is held by a Secret.
+	var (
+		config        *rest.Config
+		kubeClientSet clientset.Interface
+		err           error
+	)
+	if len(acpFiles) == 0 || cliCtx.String(flagTLSSecret) != "" {
+		config, err = kube.InClusterConfigWithRetrier(2)
+		if err != nil {
+			return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+		}
+
+		kubeClientSet, err = clientset.NewForConfig(config)
+		if err != nil {
+			return fmt.Errorf("create Kube client set: %w", err)
+		}
+	}
+
+	sampleRate := cliCtx.Float64(flagAccessLogSuccessSampleRate)
+	if sampleRate < 0 || sampleRate > 1 {
+		return fmt.Errorf("invalid access log success sample rate %v: must be between 0 and 1", sampleRate)
+	}
+
+	var accessLog *auth.AccessLogger
+	if cliCtx.Bool(flagAccessLog) {
+		accessLog = auth.NewAccessLogger(os.Stdout, sampleRate)
+	}
+
+	ctx, cancel := context.WithCancel(cliCtx.Context)
+	defer cancel()
+
+	tlsConfig, err := newTLSConfig(ctx, cliCtx, kubeClientSet)
 	if err != nil {
-		return fmt.Errorf("create Hub client set: %w", err)
+		return fmt.Errorf("create TLS configuration: %w", err)
 	}
 
-	kubeClientSet, err := clientset.NewForConfig(config)
+	creds, err := newPlatformCredentials(cliCtx, false)
 	if err != nil {
-		return fmt.Errorf("create Kube client set: %w", err)
+		return err
 	}
 
-	switcher := auth.NewHandlerSwitcher()
-	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 5*time.Minute)
-	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
-	acpWatcher := auth.NewWatcher(
-		switcher,
-		hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister(),
-		acp.NewKubeSecretValueGetter(kubeInformer.Core().V1().Secrets().Lister()),
-	)
+	var platformClient *platform.Client
+	if creds.enabled() {
+		platformClient, err = creds.newPlatformClient(cliCtx, kubeClientSet)
+		if err != nil {
+			return fmt.Errorf("build platform client: %w", err)
+		}
 
-	if _, err = hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddEventHandler(acpWatcher); err != nil {
-		return fmt.Errorf("add ACP watcher: %w", err)
+		go creds.watchRotation(ctx)
 	}
 
-	hubInformer.Start(cliCtx.Context.Done())
+	acpLogger := loggers.Component(logwrapper.ComponentACP)
 
-	for t, ok := range hubInformer.WaitForCacheSync(cliCtx.Context.Done()) {
-		if !ok {
-			return fmt.Errorf("wait for cache sync: %s: %w", t, cliCtx.Context.Err())
+	var guard *bruteforce.Guard
+	if maxFailures := cliCtx.Int(flagBruteForceMaxFailures); maxFailures > 0 {
+		var sender bruteforce.EventSender
+		if platformClient != nil {
+			sender = platformClient
+		}
+
+		guard, err = bruteforce.NewGuard(bruteforce.Config{
+			MaxFailures:   maxFailures,
+			Window:        cliCtx.Duration(flagBruteForceWindow),
+			BlockDuration: cliCtx.Duration(flagBruteForceBlockDuration),
+			Tarpit:        cliCtx.Duration(flagBruteForceTarpit),
+		}, sender, acpLogger)
+		if err != nil {
+			return fmt.Errorf("create brute force guard: %w", err)
 		}
+
+		go guard.Run(ctx)
 	}
 
-	if _, err = kubeInformer.Core().V1().Secrets().Informer().AddEventHandler(acpWatcher); err != nil {
-		return fmt.Errorf("add secret watcher: %w", err)
+	switcher := auth.NewHandlerSwitcher()
+
+	var (
+		acpWatcher *auth.Watcher
+		gateways   hublisters.APIGatewayLister
+	)
+	if len(acpFiles) > 0 {
+		var source *filesource.Source
+		source, err = filesource.NewSource(acpFiles, acpLogger)
+		if err != nil {
+			return fmt.Errorf("create ACP file source: %w", err)
+		}
+
+		acpWatcher = auth.NewWatcher(switcher, source.AccessControlPolicies(), source.Secrets(), accessLog, guard, acpLogger)
+		gateways = source.APIGateways()
+
+		go func() {
+			if errRun := source.Run(ctx, acpWatcher.Refresh); errRun != nil {
+				acpLogger.Error().Err(errRun).Msg("ACP files won't be reloaded")
+			}
+		}()
+		acpWatcher.Refresh()
+	} else {
+		var shutdown func()
+		acpWatcher, gateways, shutdown, err = newKubernetesACPWatcher(ctx, cliCtx, config, kubeClientSet, switcher, accessLog, guard, platformClient != nil, acpLogger)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			cancel()
+			shutdown()
+		}()
 	}
 
-	kubeInformer.Start(cliCtx.Context.Done())
+	var apiTokenHandler http.Handler
+	if platformClient != nil {
+		revocations := apitoken.NewRevocationList(platformClient)
+		go revocations.Run(ctx, cliCtx.Duration(flagAPITokenRevocationSync))
 
-	for t, ok := range kubeInformer.WaitForCacheSync(cliCtx.Context.Done()) {
-		if !ok {
-			return fmt.Errorf("wait for cache Kubernetes sync: %s: %w", t, cliCtx.Context.Err())
+		apiTokenHandler = apitoken.NewHandler(platformClient, revocations, gateways, cliCtx.Duration(flagAPITokenCacheTTL))
+		if accessLog != nil {
+			apiTokenHandler = accessLog.Wrap("api-gateways", "API Token", apiTokenHandler)
 		}
 	}
 
-	go acpWatcher.Run(cliCtx.Context)
+	watcherDone := make(chan struct{})
+	go func() {
+		acpWatcher.Run(ctx)
+		close(watcherDone)
+	}()
 
 	listenAddr := cliCtx.String(flagListenAddr)
 
+	ready := &readiness{}
+
 	mux := http.NewServeMux()
 
 	mux.Handle("/_live", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
 		rw.WriteHeader(http.StatusOK)
 	}))
-	mux.Handle("/_ready", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	}))
+	mux.Handle("/_ready", ready)
+	mux.Handle("/_log-levels", loggers.LevelHandler())
 
-	mux.Handle("/", switcher)
+	if apiTokenHandler != nil {
+		mux.Handle(apitoken.PathPrefix+"/", http.StripPrefix(apitoken.PathPrefix, apiTokenHandler))
+	}
 
-	server := &http.Server{
-		Addr:              listenAddr,
-		Handler:           mux,
-		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
-		ReadHeaderTimeout: 2 * time.Second,
+	if simulateToken := cliCtx.String(flagSimulateToken); simulateToken != "" {
+		mux.Handle(auth.SimulatePathPrefix, acpWatcher.SimulationHandler(simulateToken))
 	}
 
-	srvDone := make(chan struct{})
+	reauthInterval := cliCtx.Duration(flagLongLivedReauthInterval)
+	longLived := auth.NewLongLivedConnections(switcher, reauthInterval)
+	if reauthInterval > 0 {
+		mux.Handle(auth.ConnectionsPathPrefix+"/", http.StripPrefix(auth.ConnectionsPathPrefix, longLived.ControlHandler()))
+	}
 
-	go func() {
-		log.Info().Str("addr", listenAddr).Msg("Starting auth server")
-		if err = server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			log.Err(err).Msg("Unable to listen and serve auth requests")
+	mux.Handle("/", longLived)
+
+	server, err := newServer(cliCtx, listenAddr, mux)
+	if err != nil {
+		return fmt.Errorf("create server: %w", err)
+	}
+	server.TLSConfig = tlsConfig
+
+	err = serve(ctx, "auth server", server, listenAndServe(server), ready)
+
+	cancel()
+	<-watcherDone
+
+	return err
+}
+
+// newKubernetesACPWatcher creates a Watcher building the ACP handlers out of the AccessControlPolicies of the cluster
+// and the Secrets they use. It starts the informers watching them, and the APIGateways when watchGateways is set, and
+// waits for their caches to be synced. The returned function stops the informers.
+func newKubernetesACPWatcher(ctx context.Context, cliCtx *cli.Context, config *rest.Config, kubeClientSet clientset.Interface, switcher *auth.HTTPHandlerSwitcher, accessLog *auth.AccessLogger, guard *bruteforce.Guard, watchGateways bool, acpLogger zerolog.Logger) (*auth.Watcher, hublisters.APIGatewayLister, func(), error) {
+	hubClientSet, err := hubclientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("create Hub client set: %w", err)
+	}
+
+	resync := cliCtx.Duration(flagACPResyncInterval)
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, resync)
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, resync)
+	acpWatcher := auth.NewWatcher(
+		switcher,
+		hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister(),
+		acp.NewKubeSecretValueGetter(kubeInformer.Core().V1().Secrets().Lister()),
+		accessLog,
+		guard,
+		acpLogger,
+	)
+
+	var gateways hublisters.APIGatewayLister
+	if watchGateways {
+		gateways = hubInformer.Hub().V1alpha1().APIGateways().Lister()
+	}
+
+	acpIndexers := cache.Indexers{hublisters.AccessControlPoliciesBySecretIndex: hublisters.IndexAccessControlPoliciesBySecret}
+	if err = hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddIndexers(acpIndexers); err != nil {
+		return nil, nil, nil, fmt.Errorf("add ACP indexers: %w", err)
+	}
+
+	if _, err = hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddEventHandler(acpWatcher); err != nil {
+		return nil, nil, nil, fmt.Errorf("add ACP watcher: %w", err)
+	}
+
+	cached := []cache.SharedIndexInformer{hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer()}
+	if watchGateways {
+		cached = append(cached, hubInformer.Hub().V1alpha1().APIGateways().Informer())
+	}
+	if err = kube.SetTransform(kube.StripObject, cached...); err != nil {
+		return nil, nil, nil, fmt.Errorf("hub informers: %w", err)
+	}
+
+	hubInformer.Start(ctx.Done())
+
+	for t, ok := range hubInformer.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("wait for cache sync: %s: %w", t, ctx.Err())
 		}
-		close(srvDone)
-	}()
+	}
 
-	select {
-	case <-cliCtx.Context.Done():
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
+	if _, err = kubeInformer.Core().V1().Secrets().Informer().AddEventHandler(acpWatcher); err != nil {
+		return nil, nil, nil, fmt.Errorf("add secret watcher: %w", err)
+	}
 
-		if err = server.Shutdown(gracefulCtx); err != nil {
-			log.Error().Err(err).Msg("Failed to shutdown auth server gracefully")
-			if err = server.Close(); err != nil {
-				return fmt.Errorf("close auth server: %w", err)
-			}
+	if err = kube.SetTransform(kube.StripObject, kubeInformer.Core().V1().Secrets().Informer()); err != nil {
+		return nil, nil, nil, fmt.Errorf("secrets informer: %w", err)
+	}
+
+	kubeInformer.Start(ctx.Done())
+
+	for t, ok := range kubeInformer.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			kubeInformer.Shutdown()
+			return nil, nil, nil, fmt.Errorf("wait for cache Kubernetes sync: %s: %w", t, ctx.Err())
 		}
-	case <-srvDone:
-		return errors.New("auth server stopped")
 	}
 
-	return nil
+	return acpWatcher, gateways, kubeInformer.Shutdown, nil
 }
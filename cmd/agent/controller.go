@@ -19,18 +19,29 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/ettle/strcase"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	"github.com/traefik/hub-agent-kubernetes/pkg/commands"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
 	"github.com/traefik/hub-agent-kubernetes/pkg/heartbeat"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
-	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/leaderelection"
+	"github.com/traefik/hub-agent-kubernetes/pkg/localapi"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
+	"github.com/traefik/hub-agent-kubernetes/pkg/telemetry"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/store"
@@ -40,13 +51,30 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 )
 
 const (
 	flagPlatformURL       = "platform-url"
 	flagToken             = "token"
 	flagTraefikMetricsURL = "traefik.metrics-url"
+	flagMeshMetricsURL    = "mesh.metrics-url"
+	flagMeshMetricsParser = "mesh.metrics-parser"
+	flagMetricsRelabel    = "metrics.relabel-config-file"
+	flagMetricsTopGroups  = "metrics.top-groups"
+	flagTopologyEvents    = "topology.warning-events"
+	flagTopologyResync    = "topology.resync-interval"
+	flagAlertingWebhook   = "alerting.webhook-url"
+	flagAlertingNotifiers = "alerting.notifiers-secret"
+	flagStatusTunnelURL   = "status.tunnel-url"
+	flagTelemetryUsage    = "telemetry.feature-usage"
+
+	flagTokenRotationRenewBefore = "token-rotation.renew-before"
+
+	flagLeaderElection          = "leader-election"
+	flagLeaderElectionLeaseName = "leader-election.lease-name"
 )
 
 type controllerCmd struct {
@@ -63,21 +91,97 @@ func newControllerCmd() controllerCmd {
 			Hidden:  true,
 		},
 		&cli.StringFlag{
-			Name:     flagToken,
-			Usage:    "The token to use for Hub platform API calls",
-			EnvVars:  []string{strcase.ToSNAKE(flagToken)},
-			Required: true,
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls. Required unless --" + flagTokenFile + " is set",
+			EnvVars: []string{strcase.ToSNAKE(flagToken)},
+		},
+		tokenFileFlag(strcase.ToSNAKE(flagTokenFile)),
+		&cli.DurationFlag{
+			Name:    flagTokenRotationRenewBefore,
+			Usage:   "How long before its expiry the token is renewed",
+			EnvVars: []string{strcase.ToSNAKE(flagTokenRotationRenewBefore)},
+			Value:   24 * time.Hour,
 		},
 		&cli.StringFlag{
 			Name:    flagTraefikMetricsURL,
 			Usage:   "The url used by Traefik to expose metrics",
 			EnvVars: []string{strcase.ToSNAKE(flagTraefikMetricsURL)},
 		},
+		&cli.StringFlag{
+			Name:    flagMeshMetricsURL,
+			Usage:   "The url exposing the metrics of the service mesh sidecars, such as the federation endpoint of the Prometheus scraping them, used to report the metrics of the Services behind the ingresses. Requires the Traefik metrics url",
+			EnvVars: []string{strcase.ToSNAKE(flagMeshMetricsURL)},
+		},
+		&cli.StringFlag{
+			Name:    flagMeshMetricsParser,
+			Usage:   "The service mesh exposing the sidecar metrics (istio or linkerd). Detected from the metrics when auto",
+			EnvVars: []string{strcase.ToSNAKE(flagMeshMetricsParser)},
+			Value:   metrics.ParserAuto,
+		},
+		&cli.StringFlag{
+			Name:    flagMetricsRelabel,
+			Usage:   "Path to the YAML file holding the rules dropping series or labels from the scraped Traefik and service mesh metrics before they are parsed, such as high cardinality ones",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsRelabel)},
+		},
+		&cli.IntFlag{
+			Name:    flagMetricsTopGroups,
+			Usage:   "Number of busiest ingresses and services whose metrics are sent to the platform as is, the metrics of the others being rolled into a single \"" + metrics.OtherGroup + "\" group. Metrics are all sent as is when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagMetricsTopGroups)},
+		},
+		&cli.StringFlag{
+			Name:    flagAlertingWebhook,
+			Usage:   "The url of a webhook to which the alerts raised by the agent are posted as soon as they are raised, even when the platform is delayed or unavailable. Requires the Traefik metrics url",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingWebhook)},
+		},
+		&cli.StringFlag{
+			Name:    flagAlertingNotifiers,
+			Usage:   "The name of the secret, in the agent namespace, whose \"notifiers.yaml\" key configures the Slack, webhook, email and PagerDuty channels to which the alerts raised by the agent are notified. Requires the Traefik metrics url",
+			EnvVars: []string{strcase.ToSNAKE(flagAlertingNotifiers)},
+		},
+		&cli.BoolFlag{
+			Name:    flagTopologyEvents,
+			Usage:   "Report the Warning Events about the objects of the namespaces holding ingresses and services, and about the Hub resources, along with the topology, allowing the platform to correlate route errors with cluster events",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyEvents)},
+		},
+		&cli.DurationFlag{
+			Name:    flagTopologyResync,
+			Usage:   "Interval at which the informers watching the resources reported in the topology replay their whole cache. Larger intervals reduce the load on the API server of large clusters. Disabled when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagTopologyResync)},
+			Value:   5 * time.Minute,
+		},
+		&cli.BoolFlag{
+			Name:    flagTelemetryUsage,
+			Usage:   "Report the anonymized usage of the agent features, like the number of ACPs by type, of published APIs and of managed certificates, along with the heartbeat, allowing the platform to give upgrade and configuration advice",
+			EnvVars: []string{strcase.ToSNAKE(flagTelemetryUsage)},
+		},
+		&cli.StringFlag{
+			Name:    flagStatusTunnelURL,
+			Usage:   "URL of the status endpoint of the agent tunnel, whose state is then reported by the controller status endpoint",
+			EnvVars: []string{strcase.ToSNAKE(flagStatusTunnelURL)},
+		},
+		&cli.BoolFlag{
+			Name:    flagLeaderElection,
+			Usage:   "Elect a leader among the controller replicas to run the controllers, allowing to run multiple replicas",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElection)},
+		},
+		&cli.StringFlag{
+			Name:    flagLeaderElectionLeaseName,
+			Usage:   "The name of the lease used for the leader election",
+			EnvVars: []string{strcase.ToSNAKE(flagLeaderElectionLeaseName)},
+			Value:   "hub-agent-controller",
+			Hidden:  true,
+		},
 	}
 
+	flgs = append(flgs, tokenRotationFlags("")...)
 	flgs = append(flgs, globalFlags()...)
+	flgs = append(flgs, serverFlags()...)
 	flgs = append(flgs, admissionFlags()...)
 	flgs = append(flgs, devPortalFlags()...)
+	flgs = append(flgs, apiLintFlags()...)
+	flgs = append(flgs, federationFlags()...)
+	flgs = append(flgs, localAPIFlags()...)
+	flgs = append(flgs, upstreamFlags()...)
 
 	return controllerCmd{
 		flags: flgs,
@@ -94,12 +198,17 @@ func (c controllerCmd) build() *cli.Command {
 }
 
 func (c controllerCmd) run(cliCtx *cli.Context) error {
-	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+	loggers := logwrapper.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	version.Log()
 
 	platformURL, token := cliCtx.String(flagPlatformURL), cliCtx.String(flagToken)
 
+	creds, err := newPlatformCredentials(cliCtx, true)
+	if err != nil {
+		return err
+	}
+
 	kubeCfg, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
 		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
@@ -110,6 +219,12 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Kubernetes client set: %w", err)
 	}
 
+	caps, err := capability.Probe(cliCtx.Context, kubeClient)
+	if err != nil {
+		return fmt.Errorf("probe cluster capabilities: %w", err)
+	}
+	caps.Log()
+
 	if err = setupOIDCSecret(cliCtx, kubeClient, token); err != nil {
 		return fmt.Errorf("setup OIDC secret: %w", err)
 	}
@@ -124,74 +239,177 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Traefik Hub client set: %w", err)
 	}
 
-	platformClient, err := platform.NewClient(platformURL, token)
+	platformClient, err := creds.newPlatformClient(cliCtx, kubeClient)
 	if err != nil {
 		return fmt.Errorf("build platform client: %w", err)
 	}
 
 	configWatcher := platform.NewConfigWatcher(time.Minute, platformClient)
-
-	heartbeater := heartbeat.NewHeartbeater(platformClient)
+	watchLogLevels(configWatcher, loggers)
 
 	agentCfg, err := setup(cliCtx.Context, platformClient, kubeClient)
 	if err != nil {
 		return fmt.Errorf("setup agent: %w", err)
 	}
 
-	topoFetcher, err := state.NewFetcher(cliCtx.Context, kubeClient, traefikClientSet, hubClientSet)
+	metadataClientSet, err := metadata.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes metadata client set: %w", err)
+	}
+
+	dynamicClientSet, err := dynamic.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes dynamic client set: %w", err)
+	}
+
+	// Every informer factory started by the controller is tracked, for the status endpoint to report them.
+	informerTracker := status.NewInformerTracker()
+
+	topoFetcher, err := state.NewFetcher(cliCtx.Context, caps, kubeClient, metadataClientSet, dynamicClientSet, traefikClientSet, hubClientSet, cliCtx.Duration(flagTopologyResync), informerTracker)
+	if err != nil {
+		return err
+	}
+	if cliCtx.Bool(flagTopologyEvents) {
+		if err = topoFetcher.WatchWarningEvents(cliCtx.Context); err != nil {
+			return fmt.Errorf("watch warning events: %w", err)
+		}
+	}
+	topoWatch := topology.NewWatcher(topoFetcher, store.New(platformClient), loggers.Component(logwrapper.ComponentTopology))
+
+	var pinger heartbeat.Pinger = platformClient
+	if cliCtx.Bool(flagTelemetryUsage) {
+		collector := telemetry.NewCollector(metadataClientSet)
+		topoWatch.AddListener(collector.TopologyStateChanged)
+		pinger = telemetry.NewPinger(platformClient, collector)
+	}
+	heartbeater := heartbeat.NewHeartbeater(pinger)
+
+	federationClient, err := newFederationClient(cliCtx)
 	if err != nil {
 		return err
 	}
-	topoWatch := topology.NewWatcher(topoFetcher, store.New(platformClient))
+	if federationClient != nil {
+		defer func() { _ = federationClient.Close() }()
+
+		topoWatch.AddListener(func(ctx context.Context, s *state.Cluster) {
+			ctxReport, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			if errReport := federationClient.ReportTopology(ctxReport, s); errReport != nil {
+				log.Error().Err(errReport).Msg("Unable to report topology to the federation server")
+			}
+		})
+	}
 
 	checker := version.NewChecker(platformClient)
 
 	commandWatcher := commands.NewWatcher(10*time.Second, platformClient, kubeClient, traefikClientSet)
 
+	elector, err := newElector(cliCtx, kubeClient)
+	if err != nil {
+		return fmt.Errorf("create leader elector: %w", err)
+	}
+
+	statusHandler := status.NewHandler()
+	statusHandler.Register("platform", configWatcher.Status)
+	statusHandler.Register("informers", informerTracker.Check)
+	statusHandler.Register("topology", status.LeaderOnly(elector.IsLeader, topoWatch.Status))
+	if tunnelURL := cliCtx.String(flagStatusTunnelURL); tunnelURL != "" {
+		statusHandler.Register("tunnel", status.RemoteCheck(&http.Client{Timeout: 2 * time.Second}, tunnelURL))
+	}
+
 	group, ctx := errgroup.WithContext(cliCtx.Context)
 
 	group.Go(func() error {
-		configWatcher.Run(ctx)
+		elector.Run(ctx)
 		return nil
 	})
 
 	group.Go(func() error {
-		heartbeater.Run(ctx)
+		configWatcher.Run(ctx)
 		return nil
 	})
 
+	elector.Go(ctx, heartbeater.Run)
+
+	if creds.rotation != nil {
+		// The leader renews the token, the other replicas and components pick it up from the secret.
+		elector.Go(ctx, creds.rotation.Run)
+		group.Go(func() error {
+			creds.watchRotation(ctx)
+			return nil
+		})
+	}
+
 	if cliCtx.String(flagTraefikMetricsURL) != "" {
-		mtrcsMgr, mtrcsStore, errMetrics := newMetrics(topoWatch, token, platformURL, cliCtx.String(flagTraefikMetricsURL), agentCfg.Metrics, configWatcher)
+		transport, errTransport := newUpstreamTransport(cliCtx)
+		if errTransport != nil {
+			return errTransport
+		}
+
+		relabeler, errRelabel := loadMetricsRelabeler(cliCtx.String(flagMetricsRelabel))
+		if errRelabel != nil {
+			return errRelabel
+		}
+
+		mtrcsMgr, mtrcsStore, errMetrics := newMetrics(topoWatch, token, platformURL, cliCtx.String(flagTraefikMetricsURL), agentCfg.Metrics, configWatcher, creds.Wrap(transport), relabeler, loggers.Component(logwrapper.ComponentMetrics))
 		if errMetrics != nil {
 			return errMetrics
 		}
 
-		group.Go(func() error {
-			errMM := mtrcsMgr.Run(ctx)
-			if errMM != nil {
+		topGroups := cliCtx.Int(flagMetricsTopGroups)
+		if topGroups < 0 {
+			return fmt.Errorf("invalid metrics top groups %d: must not be negative", topGroups)
+		}
+		mtrcsMgr.SetTopGroups(topGroups)
+		statusHandler.Register("metrics", status.LeaderOnly(elector.IsLeader, mtrcsMgr.Status))
+
+		if meshURL := cliCtx.String(flagMeshMetricsURL); meshURL != "" {
+			if errMesh := addMeshMetricsTarget(mtrcsMgr, cliCtx.String(flagMeshMetricsParser), meshURL); errMesh != nil {
+				return errMesh
+			}
+		}
+
+		elector.Go(ctx, func(ctx context.Context) {
+			if errMM := mtrcsMgr.Run(ctx); errMM != nil {
 				log.Error().Err(errMM).Msg("metrics manager stopped")
 			}
+		})
 
-			return errMM
+		notifiers, errNotifiers := newAlertingNotifiers(ctx, kubeClient, cliCtx.String(flagAlertingNotifiers), cliCtx.String(flagAlertingWebhook))
+		if errNotifiers != nil {
+			return fmt.Errorf("create alerting notifiers: %w", errNotifiers)
+		}
+
+		elector.Go(ctx, func(ctx context.Context) {
+			if errAlerting := runAlerting(ctx, token, platformURL, creds.Wrap, mtrcsStore, topoFetcher, notifiers); errAlerting != nil {
+				log.Error().Err(errAlerting).Msg("alerts stopped")
+			}
 		})
+	}
+
+	if addr := cliCtx.String(flagLocalAPIListenAddr); addr != "" {
+		// The topology is only available on the leader, which runs the topology watcher.
+		topology := &localapi.TopologySnapshot{}
+		topoWatch.AddListener(topology.Update)
 
 		group.Go(func() error {
-			errAlerting := runAlerting(ctx, token, platformURL, mtrcsStore, topoFetcher)
-			if errAlerting != nil {
-				log.Error().Err(errAlerting).Msg("alerts stopped")
+			errLocalAPI := runLocalAPI(ctx, addr, caps, kubeClient, hubClientSet, topology, informerTracker, loggers.Component(logwrapper.ComponentDevPortal))
+			if errLocalAPI != nil {
+				log.Error().Err(errLocalAPI).Msg("local API stopped")
 			}
 
-			return errAlerting
+			return errLocalAPI
 		})
 	}
 
-	group.Go(func() error {
-		topoWatch.Start(ctx)
-		return nil
-	})
+	elector.Go(ctx, topoWatch.Start)
+	if federationClient != nil {
+		elector.Go(ctx, federationClient.Run)
+	}
 
 	group.Go(func() error {
-		errWh := webhookAdmission(ctx, cliCtx, platformClient, configWatcher)
+		errWh := webhookAdmission(ctx, cliCtx, caps, platformClient, configWatcher, elector, statusHandler, informerTracker, loggers)
 		if errWh != nil {
 			log.Error().Err(errWh).Msg("webhook stopped")
 		}
@@ -208,10 +426,7 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 		return errCheck
 	})
 
-	group.Go(func() error {
-		commandWatcher.Start(ctx)
-		return nil
-	})
+	elector.Go(ctx, commandWatcher.Start)
 
 	err = group.Wait()
 	if err != nil {
@@ -221,10 +436,63 @@ func (c controllerCmd) run(cliCtx *cli.Context) error {
 	return err
 }
 
+// watchLogLevels applies the component log levels of the agent configuration when they change. Levels aren't applied
+// on unrelated configuration changes so the ones set through the log levels endpoint are kept.
+func watchLogLevels(configWatcher *platform.ConfigWatcher, loggers *logwrapper.Loggers) {
+	var (
+		mu        sync.Mutex
+		logLevels map[string]string
+	)
+
+	configWatcher.AddListener(func(cfg platform.Config) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if reflect.DeepEqual(logLevels, cfg.LogLevels) {
+			return
+		}
+		logLevels = cfg.LogLevels
+
+		if err := loggers.SetComponentLevels(cfg.LogLevels); err != nil {
+			log.Error().Err(err).Msg("Unable to set component log levels")
+		}
+	})
+}
+
+func newElector(cliCtx *cli.Context, kubeClient clientset.Interface) (*leaderelection.Elector, error) {
+	if !cliCtx.Bool(flagLeaderElection) {
+		return leaderelection.NewAlwaysLeaderElector(), nil
+	}
+
+	identity, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("get hostname: %w", err)
+	}
+
+	return leaderelection.NewElector(kubeClient, leaderelection.Config{
+		LeaseName:      cliCtx.String(flagLeaderElectionLeaseName),
+		LeaseNamespace: currentNamespace(),
+		Identity:       identity,
+		LeaseDuration:  15 * time.Second,
+		RenewDeadline:  10 * time.Second,
+		RetryPeriod:    2 * time.Second,
+	})
+}
+
 func setupOIDCSecret(cliCtx *cli.Context, client clientset.Interface, token string) error {
 	ctx, cancel := context.WithTimeout(cliCtx.Context, time.Second*5)
 	defer cancel()
 
+	key := []byte(token)
+	if token == "" {
+		// Without a static token, for instance when using the workload identity, a random session key is generated.
+		// It is only used when the Secret doesn't exist yet, so the sessions survive the restarts of the agent.
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("generate session key: %w", err)
+		}
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "hub-secret",
@@ -234,7 +502,7 @@ func setupOIDCSecret(cliCtx *cli.Context, client clientset.Interface, token stri
 		},
 		Type: corev1.SecretTypeOpaque,
 		Data: map[string][]byte{
-			"key": []byte(token),
+			"key": key,
 		},
 	}
 
@@ -124,3 +124,15 @@ func TestSetupOIDCSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestSetupOIDCSecret_randomKey(t *testing.T) {
+	cliCtx := &cli.Context{Context: context.Background()}
+	kubeClient := kubemock.NewSimpleClientset()
+
+	require.NoError(t, setupOIDCSecret(cliCtx, kubeClient, ""))
+
+	secret, err := kubeClient.CoreV1().Secrets("default").Get(context.Background(), "hub-secret", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Len(t, secret.Data["key"], 32)
+}
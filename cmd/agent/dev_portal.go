@@ -19,24 +19,38 @@ package main
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	stdlog "log"
 	"net/http"
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
 	"github.com/traefik/hub-agent-kubernetes/pkg/api/devportal"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
-	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 	"github.com/urfave/cli/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeinformers "k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 )
 
+const (
+	flagIdentityJWKsURL     = "identity.jwks-url"
+	flagIdentityTokenHeader = "identity.token-header"
+	flagIdentityIssuer      = "identity.issuer"
+	flagIdentityAudience    = "identity.audience"
+	flagSDKGeneratorURL     = "sdk-generator.url"
+	flagCatalogResync       = "catalog.resync-interval"
+	flagCatalogFiles        = "catalog.files"
+	flagCatalogPoll         = "catalog.poll-interval"
+	flagGroupsSync          = "groups.sync-interval"
+)
+
 type devPortalCmd struct {
 	flags []cli.Flag
 }
@@ -49,9 +63,77 @@ func newDevPortalCmd() devPortalCmd {
 			EnvVars: []string{"DEV_PORTAL_LISTEN_ADDR"},
 			Value:   "0.0.0.0:80",
 		},
+		&cli.StringFlag{
+			Name:    flagIdentityJWKsURL,
+			Usage:   "URL of the JWKs used to verify the identity token matching the identity headers configured on the APIGateways. The verification is disabled when empty",
+			EnvVars: []string{"DEV_PORTAL_IDENTITY_JWKS_URL"},
+		},
+		&cli.StringFlag{
+			Name:    flagIdentityTokenHeader,
+			Usage:   "Header holding the identity token",
+			EnvVars: []string{"DEV_PORTAL_IDENTITY_TOKEN_HEADER"},
+			Value:   "Hub-Identity-Token",
+		},
+		&cli.StringFlag{
+			Name:    flagIdentityIssuer,
+			Usage:   "Expected issuer of the identity token",
+			EnvVars: []string{"DEV_PORTAL_IDENTITY_ISSUER"},
+		},
+		&cli.StringFlag{
+			Name:    flagIdentityAudience,
+			Usage:   "Expected audience of the identity token",
+			EnvVars: []string{"DEV_PORTAL_IDENTITY_AUDIENCE"},
+		},
+		&cli.StringFlag{
+			Name:    flagSDKGeneratorURL,
+			Usage:   "URL of the openapi-generator-online server used to generate the client SDKs. SDK downloads are disabled when empty",
+			EnvVars: []string{"DEV_PORTAL_SDK_GENERATOR_URL"},
+		},
+		&cli.DurationFlag{
+			Name:    flagCatalogResync,
+			Usage:   "Interval at which the informers watching the portals, gateways, APIs and their collections, accesses and rate limits replay their whole cache. Disabled when 0",
+			EnvVars: []string{"DEV_PORTAL_CATALOG_RESYNC_INTERVAL"},
+			Value:   5 * time.Minute,
+		},
+		&cli.StringSliceFlag{
+			Name:    flagCatalogFiles,
+			Usage:   "Files or directories holding the portals, gateways, APIs and their collections, accesses and rate limits. When set, the catalog is read from these files instead of the Kubernetes API",
+			EnvVars: []string{"DEV_PORTAL_CATALOG_FILES"},
+		},
+		&cli.DurationFlag{
+			Name:    flagCatalogPoll,
+			Usage:   "Interval at which the catalog files are checked for changes",
+			EnvVars: []string{"DEV_PORTAL_CATALOG_POLL_INTERVAL"},
+			Value:   10 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagGroupsSync,
+			Usage:   "Interval at which the groups pushed by the identity provider through SCIM are fetched from the platform",
+			EnvVars: []string{"DEV_PORTAL_GROUPS_SYNC_INTERVAL"},
+			Value:   time.Minute,
+		},
+		&cli.StringFlag{
+			Name:    flagPlatformURL,
+			Usage:   "The URL at which to reach the Hub platform API",
+			Value:   "https://platform.hub.traefik.io/agent",
+			EnvVars: []string{"DEV_PORTAL_PLATFORM_URL"},
+			Hidden:  true,
+		},
+		&cli.StringFlag{
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls. API usage metrics, captured examples, terms of service acceptances and SCIM groups are disabled when neither it nor --" + flagTokenFile + " is set",
+			EnvVars: []string{"DEV_PORTAL_TOKEN"},
+		},
+		tokenFileFlag("DEV_PORTAL_WORKLOAD_IDENTITY_TOKEN_FILE"),
 	}
 
+	flgs = append(flgs, tokenRotationFlags("DEV_PORTAL_")...)
 	flgs = append(flgs, globalFlags()...)
+	flgs = append(flgs, serverFlags()...)
+	flgs = append(flgs, tlsFlags()...)
+	flgs = append(flgs, apiLintFlags()...)
+	flgs = append(flgs, federationFlags()...)
+	flgs = append(flgs, upstreamFlags()...)
 
 	return devPortalCmd{
 		flags: flgs,
@@ -68,10 +150,55 @@ func (c devPortalCmd) build() *cli.Command {
 }
 
 func (c devPortalCmd) run(cliCtx *cli.Context) error {
-	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+	loggers := logwrapper.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+	logger := loggers.Component(logwrapper.ComponentDevPortal)
 
 	version.Log()
 
+	var identityVerifier *devportal.IdentityVerifier
+	if jwksURL := cliCtx.String(flagIdentityJWKsURL); jwksURL != "" {
+		var err error
+		identityVerifier, err = devportal.NewIdentityVerifier(devportal.IdentityConfig{
+			JWKsURL:     jwksURL,
+			TokenHeader: cliCtx.String(flagIdentityTokenHeader),
+			Issuer:      cliCtx.String(flagIdentityIssuer),
+			Audience:    cliCtx.String(flagIdentityAudience),
+		})
+		if err != nil {
+			return fmt.Errorf("create identity verifier: %w", err)
+		}
+	}
+
+	ruleset, err := loadLintRuleset(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	var sdkGenerator *devportal.SDKGenerator
+	if generatorURL := cliCtx.String(flagSDKGeneratorURL); generatorURL != "" {
+		sdkGenerator, err = devportal.NewSDKGenerator(generatorURL)
+		if err != nil {
+			return fmt.Errorf("create SDK generator: %w", err)
+		}
+	}
+
+	transport, err := newUpstreamTransport(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	federationClient, err := newFederationClient(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	var catalogReporter devportal.CatalogReporter
+	if federationClient != nil {
+		defer func() { _ = federationClient.Close() }()
+
+		catalogReporter = federationClient
+	}
+
 	config, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
 		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
@@ -82,89 +209,156 @@ func (c devPortalCmd) run(cliCtx *cli.Context) error {
 		return fmt.Errorf("create Hub client set: %w", err)
 	}
 
-	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
+	kubeClientSet, err := clientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("create Kube client set: %w", err)
+	}
 
-	portalInformer := hubInformer.Hub().V1alpha1().APIPortals()
-	gatewayInformer := hubInformer.Hub().V1alpha1().APIGateways()
-	apiInformer := hubInformer.Hub().V1alpha1().APIs()
-	collectionInformer := hubInformer.Hub().V1alpha1().APICollections()
-	accessInformer := hubInformer.Hub().V1alpha1().APIAccesses()
+	ctx, cancel := context.WithCancel(cliCtx.Context)
+	defer cancel()
 
-	handler := devportal.NewHandler()
-	portalWatcher := devportal.NewWatcher(handler,
-		portalInformer.Lister(),
-		gatewayInformer.Lister(),
-		apiInformer.Lister(),
-		collectionInformer.Lister(),
-		accessInformer.Lister())
+	tlsConfig, err := newTLSConfig(ctx, cliCtx, kubeClientSet)
+	if err != nil {
+		return fmt.Errorf("create TLS configuration: %w", err)
+	}
 
-	informers := []cache.SharedInformer{
-		portalInformer.Informer(),
-		gatewayInformer.Informer(),
-		apiInformer.Informer(),
-		collectionInformer.Informer(),
-		accessInformer.Informer(),
+	var (
+		platformClient devportal.PlatformClient
+		groups         *devportal.GroupDirectory
+	)
+	creds, err := newPlatformCredentials(cliCtx, false)
+	if err != nil {
+		return err
 	}
-	for _, informer := range informers {
-		if _, errInformer := informer.AddEventHandler(portalWatcher); errInformer != nil {
-			return fmt.Errorf("add event handler: %w", errInformer)
+
+	if creds.enabled() {
+		client, errClient := creds.newPlatformClient(cliCtx, kubeClientSet)
+		if errClient != nil {
+			return fmt.Errorf("build platform client: %w", errClient)
 		}
+
+		platformClient = client
+		groups = devportal.NewGroupDirectory(client, logger)
+
+		go creds.watchRotation(ctx)
 	}
 
-	hubInformer.Start(cliCtx.Context.Done())
+	resync := cliCtx.Duration(flagCatalogResync)
+	// Only the ConfigMaps holding the OpenAPI spec snapshots taken by the controller are watched.
+	kubeInformer := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClientSet, resync,
+		kubeinformers.WithNamespace(currentNamespace()),
+		kubeinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = api.LabelSpecSnapshots + "=true"
+		}),
+	)
+	configMapInformer := kubeInformer.Core().V1().ConfigMaps()
+
+	snapshots := api.NewSpecSnapshotStore(configMapInformer.Lister().ConfigMaps(currentNamespace()))
+	handler := devportal.NewHandler(ruleset, snapshots, kubeClientSet.CoreV1(), sdkGenerator, platformClient, transport, identityVerifier, groups, logger)
+
+	var (
+		portalWatcher *devportal.Watcher
+		fileProvider  *devportal.FileProvider
+	)
+	// The secrets signing the events sent to the portal webhooks are read from the agent namespace.
+	secrets := kubeClientSet.CoreV1().Secrets(currentNamespace())
+	if files := cliCtx.StringSlice(flagCatalogFiles); len(files) > 0 {
+		fileProvider, err = devportal.NewFileProvider(files, logger)
+		if err != nil {
+			return fmt.Errorf("create catalog file provider: %w", err)
+		}
 
-	for t, ok := range hubInformer.WaitForCacheSync(cliCtx.Context.Done()) {
+		portalWatcher = devportal.NewWatcher(handler, fileProvider, secrets, catalogReporter, logger)
+	} else {
+		portalWatcher, err = newKubernetesCatalogWatcher(ctx, handler, hubClientSet, secrets, resync, catalogReporter, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	kubeInformer.Start(ctx.Done())
+	defer kubeInformer.Shutdown()
+
+	for t, ok := range kubeInformer.WaitForCacheSync(ctx.Done()) {
 		if !ok {
-			return fmt.Errorf("wait for cache sync: %s: %w", t, cliCtx.Context.Err())
+			return fmt.Errorf("wait for cache Kubernetes sync: %s: %w", t, ctx.Err())
 		}
 	}
 
-	go portalWatcher.Run(cliCtx.Context)
+	watcherDone := make(chan struct{})
+	go func() {
+		portalWatcher.Run(ctx)
+		close(watcherDone)
+	}()
+
+	if fileProvider != nil {
+		go fileProvider.Run(ctx, cliCtx.Duration(flagCatalogPoll), portalWatcher.Refresh)
+	}
+
+	if groups != nil {
+		go groups.Run(ctx, cliCtx.Duration(flagGroupsSync))
+	}
+
+	if federationClient != nil {
+		go federationClient.Run(ctx)
+	}
 
 	listenAddr := cliCtx.String(flagListenAddr)
 
+	ready := &readiness{}
+
 	mux := http.NewServeMux()
 
 	mux.Handle("/_live", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
 		rw.WriteHeader(http.StatusOK)
 	}))
-	mux.Handle("/_ready", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
-		rw.WriteHeader(http.StatusOK)
-	}))
+	mux.Handle("/_ready", ready)
 
 	mux.Handle("/", handler)
 
-	server := &http.Server{
-		Addr:              listenAddr,
-		Handler:           mux,
-		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
-		ReadHeaderTimeout: 2 * time.Second,
+	server, err := newServer(cliCtx, listenAddr, mux)
+	if err != nil {
+		return fmt.Errorf("create server: %w", err)
 	}
+	server.TLSConfig = tlsConfig
 
-	srvDone := make(chan struct{})
+	err = serve(ctx, "dev portal", server, listenAndServe(server), ready)
 
-	go func() {
-		log.Info().Str("addr", listenAddr).Msg("Starting dev portal server")
-		if err = server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			log.Err(err).Msg("Unable to listen and serve dev portal requests")
+	cancel()
+	<-watcherDone
+
+	return err
+}
+
+// newKubernetesCatalogWatcher creates a Watcher reading the catalog from the Hub resources of the cluster.
+// It starts the informers watching them and waits for their caches to be synced.
+func newKubernetesCatalogWatcher(ctx context.Context, handler devportal.UpdatableHandler, hubClientSet hubclientset.Interface, secrets corev1client.SecretInterface, resync time.Duration, catalogReporter devportal.CatalogReporter, logger zerolog.Logger) (*devportal.Watcher, error) {
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, resync)
+	hub := hubInformer.Hub().V1alpha1()
+
+	portalWatcher := devportal.NewWatcher(handler, devportal.NewKubernetesProvider(hub), secrets, catalogReporter, logger)
+
+	informers := []cache.SharedInformer{
+		hub.APIPortals().Informer(),
+		hub.APIGateways().Informer(),
+		hub.APIs().Informer(),
+		hub.APICollections().Informer(),
+		hub.APIAccesses().Informer(),
+		hub.APIRateLimits().Informer(),
+	}
+	for _, informer := range informers {
+		if _, err := informer.AddEventHandler(portalWatcher); err != nil {
+			return nil, fmt.Errorf("add event handler: %w", err)
 		}
-		close(srvDone)
-	}()
+	}
 
-	select {
-	case <-cliCtx.Context.Done():
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
+	hubInformer.Start(ctx.Done())
 
-		if err = server.Shutdown(gracefulCtx); err != nil {
-			log.Error().Err(err).Msg("Failed to shutdown dev portal gracefully")
-			if err = server.Close(); err != nil {
-				return fmt.Errorf("close dev portal: %w", err)
-			}
+	for t, ok := range hubInformer.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("wait for cache sync: %s: %w", t, ctx.Err())
 		}
-	case <-srvDone:
-		return errors.New("dev portal stopped")
 	}
 
-	return nil
+	return portalWatcher, nil
 }
@@ -0,0 +1,146 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/traefikfile"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/urfave/cli/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const flagExportFormat = "format"
+
+type exportACPsCmd struct {
+	flags []cli.Flag
+}
+
+func newExportACPsCmd() exportACPsCmd {
+	return exportACPsCmd{
+		flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    flagRenderFilename,
+				Aliases: []string{"f"},
+				Usage:   "Manifest file, or directory of manifest files, holding the ACPs to export. ACPs are read from the cluster when not set",
+			},
+			&cli.StringFlag{
+				Name:  flagRenderKubeconfig,
+				Usage: "Path to the kubeconfig file used to read the ACPs from the cluster. The KUBECONFIG environment variable, the default kubeconfig file or the in-cluster configuration are used when empty",
+			},
+			&cli.StringFlag{
+				Name:  flagExportFormat,
+				Usage: `Format of the exported configuration: "yaml" or "toml"`,
+				Value: traefikfile.FormatYAML,
+			},
+			&cli.StringFlag{
+				Name:  flagACPServerAuthServerAddr,
+				Usage: "Address Traefik can reach the auth server on",
+				Value: "http://hub-agent-auth-server.hub.svc.cluster.local",
+			},
+			&cli.DurationFlag{
+				Name:  flagACPServerLongLivedReauth,
+				Usage: "Value of the long-lived.reauth-interval flag of the auth server. When set, the middlewares forward the connection ID the backends need to re-authenticate WebSocket and Server-Sent Events connections",
+			},
+		},
+	}
+}
+
+func (c exportACPsCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "export-acps",
+		Usage:  "Prints the ForwardAuth middlewares calling the auth server for the ACPs as a Traefik dynamic configuration, to be read by the Traefik file provider",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c exportACPsCmd) run(cliCtx *cli.Context) error {
+	var (
+		policies []*hubv1alpha1.AccessControlPolicy
+		err      error
+	)
+	if paths := cliCtx.StringSlice(flagRenderFilename); len(paths) > 0 {
+		policies, err = loadManifestPolicies(paths)
+	} else {
+		policies, err = loadClusterPolicies(cliCtx.Context, cliCtx.String(flagRenderKubeconfig))
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg, warnings := traefikfile.Export(cliCtx.String(flagACPServerAuthServerAddr), cliCtx.Duration(flagACPServerLongLivedReauth) > 0, policies)
+
+	b, err := cfg.Marshal(cliCtx.String(flagExportFormat))
+	if err != nil {
+		return err
+	}
+
+	return printExport(cliCtx.App.Writer, b, warnings)
+}
+
+func loadManifestPolicies(paths []string) ([]*hubv1alpha1.AccessControlPolicy, error) {
+	objects, err := loadManifestObjects(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []*hubv1alpha1.AccessControlPolicy
+	for _, obj := range objects {
+		if policy, ok := obj.(*hubv1alpha1.AccessControlPolicy); ok {
+			policies = append(policies, policy)
+		}
+	}
+
+	return policies, nil
+}
+
+func loadClusterPolicies(ctx context.Context, kubeconfig string) ([]*hubv1alpha1.AccessControlPolicy, error) {
+	hubClientSet, err := newKubeconfigHubClientSet(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := hubClientSet.HubV1alpha1().AccessControlPolicies().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list AccessControlPolicies: %w", err)
+	}
+
+	policies := make([]*hubv1alpha1.AccessControlPolicy, 0, len(list.Items))
+	for i := range list.Items {
+		policies = append(policies, &list.Items[i])
+	}
+
+	return policies, nil
+}
+
+// printExport prints the exported configuration, preceded by the export warnings as comments, which YAML and TOML
+// both start with a '#'.
+func printExport(w io.Writer, cfg []byte, warnings []string) error {
+	for _, warning := range warnings {
+		if _, err := fmt.Fprintf(w, "# WARNING: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write(cfg)
+	return err
+}
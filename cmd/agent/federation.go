@@ -0,0 +1,250 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/federation"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	"github.com/urfave/cli/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	flagFederationGRPCListenAddr   = "grpc-listen-addr"
+	flagFederationClusterTokens    = "cluster-tokens"
+	flagFederationReportExpiration = "report-expiration"
+
+	flagFederationAddr     = "federation.addr"
+	flagFederationToken    = "federation.token"
+	flagFederationInsecure = "federation.insecure"
+)
+
+// federationResendInterval is the interval at which the agents send again their last reports to the federation server.
+const federationResendInterval = time.Minute
+
+type federationCmd struct {
+	flags []cli.Flag
+}
+
+func newFederationCmd() federationCmd {
+	flgs := []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagListenAddr,
+			Usage:   "Address on which the merged catalog is served",
+			EnvVars: []string{"FEDERATION_LISTEN_ADDR"},
+			Value:   "0.0.0.0:80",
+		},
+		&cli.StringFlag{
+			Name:    flagFederationGRPCListenAddr,
+			Usage:   "Address on which the reports of the agents are received",
+			EnvVars: []string{"FEDERATION_GRPC_LISTEN_ADDR"},
+			Value:   "0.0.0.0:9443",
+		},
+		&cli.StringSliceFlag{
+			Name:     flagFederationClusterTokens,
+			Usage:    "Tokens authenticating the agents, as cluster=token pairs",
+			EnvVars:  []string{"FEDERATION_CLUSTER_TOKENS"},
+			Required: true,
+		},
+		&cli.DurationFlag{
+			Name:    flagFederationReportExpiration,
+			Usage:   "Duration after which the reports of a cluster which stopped reporting are dropped",
+			EnvVars: []string{"FEDERATION_REPORT_EXPIRATION"},
+			Value:   5 * time.Minute,
+		},
+	}
+
+	flgs = append(flgs, globalFlags()...)
+	flgs = append(flgs, serverFlags()...)
+	flgs = append(flgs, tlsFlags()...)
+
+	return federationCmd{
+		flags: flgs,
+	}
+}
+
+func (c federationCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "federation",
+		Usage:  "Runs the Hub agent federation server, merging the portal catalogs of several clusters",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c federationCmd) run(cliCtx *cli.Context) error {
+	logwrapper.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+
+	version.Log()
+
+	tokens, err := parseClusterTokens(cliCtx.StringSlice(flagFederationClusterTokens))
+	if err != nil {
+		return err
+	}
+
+	config, err := kube.InClusterConfigWithRetrier(2)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+	}
+
+	kubeClientSet, err := clientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("create Kube client set: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(cliCtx.Context)
+	defer cancel()
+
+	tlsConfig, err := newTLSConfig(ctx, cliCtx, kubeClientSet)
+	if err != nil {
+		return fmt.Errorf("create TLS configuration: %w", err)
+	}
+
+	federationServer := federation.NewServer(federation.ServerConfig{
+		Tokens:     tokens,
+		Expiration: cliCtx.Duration(flagFederationReportExpiration),
+	})
+
+	var grpcOpts []grpc.ServerOption
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else {
+		log.Warn().Msg("No TLS certificate configured: agent reports and tokens are received in plain text")
+	}
+	grpcServer := federationServer.NewGRPCServer(grpcOpts...)
+
+	grpcAddr := cliCtx.String(flagFederationGRPCListenAddr)
+	ln, err := listen(grpcAddr)
+	if err != nil {
+		return fmt.Errorf("listen on %q: %w", grpcAddr, err)
+	}
+
+	grpcDone := make(chan struct{})
+	go func() {
+		log.Info().Str("addr", grpcAddr).Msg("Starting federation gRPC server")
+		if errServe := grpcServer.Serve(ln); errServe != nil {
+			log.Error().Err(errServe).Msg("Unable to serve federation gRPC requests")
+		}
+		close(grpcDone)
+	}()
+
+	ready := &readiness{}
+
+	mux := http.NewServeMux()
+	mux.Handle("/_live", http.HandlerFunc(func(rw http.ResponseWriter, request *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	mux.Handle("/_ready", ready)
+	mux.Handle("/", federationServer.Handler())
+
+	server, err := newServer(cliCtx, cliCtx.String(flagListenAddr), mux)
+	if err != nil {
+		return fmt.Errorf("create server: %w", err)
+	}
+	server.TLSConfig = tlsConfig
+
+	err = serve(ctx, "federation", server, listenAndServe(server), ready)
+
+	grpcServer.GracefulStop()
+	<-grpcDone
+
+	return err
+}
+
+// parseClusterTokens parses the given cluster=token pairs into the tokens indexed by cluster.
+func parseClusterTokens(pairs []string) (map[string]string, error) {
+	tokens := make(map[string]string, len(pairs))
+	seen := make(map[string]struct{}, len(pairs))
+	for i, pair := range pairs {
+		cluster, token, ok := strings.Cut(pair, "=")
+		if !ok || cluster == "" || token == "" {
+			// The pair isn't part of the error as it may hold a token.
+			return nil, fmt.Errorf("invalid cluster token at position %d: must be a cluster=token pair", i)
+		}
+		if _, exists := tokens[cluster]; exists {
+			return nil, fmt.Errorf("duplicated token for cluster %q", cluster)
+		}
+		if _, exists := seen[token]; exists {
+			return nil, fmt.Errorf("token of cluster %q is used by another cluster", cluster)
+		}
+
+		tokens[cluster] = token
+		seen[token] = struct{}{}
+	}
+
+	return tokens, nil
+}
+
+// federationFlags are the flags configuring the report of the cluster topology and catalog to a federation server.
+func federationFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagFederationAddr,
+			Usage:   "Address of the federation server the topology and the portal catalog of the cluster are reported to. Nothing is reported when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagFederationAddr)},
+		},
+		&cli.StringFlag{
+			Name:    flagFederationToken,
+			Usage:   "Token authenticating the cluster on the federation server",
+			EnvVars: []string{strcase.ToSNAKE(flagFederationToken)},
+		},
+		&cli.BoolFlag{
+			Name:    flagFederationInsecure,
+			Usage:   "Connect to the federation server without TLS",
+			EnvVars: []string{strcase.ToSNAKE(flagFederationInsecure)},
+		},
+	}
+}
+
+// newFederationClient returns the client configured through the federation flags, nil if no federation server is
+// configured.
+func newFederationClient(cliCtx *cli.Context) (*federation.Client, error) {
+	addr := cliCtx.String(flagFederationAddr)
+	if addr == "" {
+		return nil, nil
+	}
+
+	token := cliCtx.String(flagFederationToken)
+	if token == "" {
+		return nil, fmt.Errorf("missing %s flag to report to the federation server", flagFederationToken)
+	}
+
+	client, err := federation.NewClient(federation.ClientConfig{
+		Addr:           addr,
+		Token:          token,
+		Insecure:       cliCtx.Bool(flagFederationInsecure),
+		ResendInterval: federationResendInterval,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create federation client: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseClusterTokens(t *testing.T) {
+	tests := []struct {
+		desc    string
+		pairs   []string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			desc:  "valid pairs",
+			pairs: []string{"eu=eu-token", "us=us=token"},
+			want:  map[string]string{"eu": "eu-token", "us": "us=token"},
+		},
+		{
+			desc:    "missing token",
+			pairs:   []string{"eu=eu-token", "us"},
+			wantErr: "invalid cluster token at position 1: must be a cluster=token pair",
+		},
+		{
+			desc:    "missing cluster",
+			pairs:   []string{"=secret"},
+			wantErr: "invalid cluster token at position 0: must be a cluster=token pair",
+		},
+		{
+			desc:    "duplicated cluster",
+			pairs:   []string{"eu=token-1", "eu=token-2"},
+			wantErr: `duplicated token for cluster "eu"`,
+		},
+		{
+			desc:    "shared token",
+			pairs:   []string{"eu=token", "us=token"},
+			wantErr: `token of cluster "us" is used by another cluster`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseClusterTokens(test.pairs)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
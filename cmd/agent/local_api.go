@@ -0,0 +1,109 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/devportal"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/localapi"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
+	"github.com/urfave/cli/v2"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const flagLocalAPIListenAddr = "local-api.listen-addr"
+
+func localAPIFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagLocalAPIListenAddr,
+			Usage:   "Address, or unix:<path> socket, on which the local gRPC API (traefik.hub.agent.v1.Agent, with server reflection) exposing the catalog, the topology and an AccessControlPolicy test endpoint listens. It is unauthenticated and must not be exposed outside the cluster. Disabled when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagLocalAPIListenAddr)},
+		},
+	}
+}
+
+// runLocalAPI serves the local gRPC API on the given address until the given context is done. The catalog is read
+// through a devportal watcher logging with the given logger. The informers are tracked by the given tracker.
+func runLocalAPI(ctx context.Context, addr string, caps capability.Capabilities, kubeClientSet clientset.Interface, hubClientSet hubclientset.Interface, topology localapi.TopologySource, informerTracker *status.InformerTracker, catalogLogger zerolog.Logger) error {
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
+	hub := hubInformer.Hub().V1alpha1()
+	policies := hub.AccessControlPolicies().Lister()
+
+	// The informers are all registered before the factory is started, the catalog listers being only used later on.
+	cached := []cache.SharedIndexInformer{hub.AccessControlPolicies().Informer()}
+
+	var catalog localapi.CatalogSource
+	if caps.APIManagement {
+		catalog = devportal.NewWatcher(nil, devportal.NewKubernetesProvider(hub), nil, nil, catalogLogger)
+		cached = append(cached,
+			hub.APIPortals().Informer(),
+			hub.APIGateways().Informer(),
+			hub.APIs().Informer(),
+			hub.APICollections().Informer(),
+			hub.APIAccesses().Informer(),
+			hub.APIRateLimits().Informer(),
+		)
+	}
+
+	if err := informerTracker.Track(cached...); err != nil {
+		return fmt.Errorf("track local API informers: %w", err)
+	}
+
+	hubInformer.Start(ctx.Done())
+	for t, ok := range hubInformer.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("wait for local API cache sync: %s: %w", t, ctx.Err())
+		}
+	}
+
+	// Secrets are only read when testing AccessControlPolicies, which doesn't justify watching them.
+	secrets := acp.NewKubeSecretClientGetter(kubeClientSet.CoreV1())
+
+	grpcServer := localapi.NewServer(catalog, topology, policies, secrets).NewGRPCServer()
+
+	ln, err := listen(addr)
+	if err != nil {
+		return fmt.Errorf("listen on %q: %w", addr, err)
+	}
+
+	srvDone := make(chan error, 1)
+	go func() {
+		log.Info().Str("addr", addr).Msg("Starting local API server")
+		srvDone <- grpcServer.Serve(ln)
+	}()
+
+	select {
+	case <-ctx.Done():
+		grpcServer.GracefulStop()
+		return nil
+	case err = <-srvDone:
+		return fmt.Errorf("local API server stopped: %w", err)
+	}
+}
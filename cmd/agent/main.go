@@ -53,6 +53,11 @@ func run() error {
 			newTunnelCmd().build(),
 			newVersionCmd().build(),
 			newDevPortalCmd().build(),
+			newFederationCmd().build(),
+			newValidateCmd().build(),
+			newRenderCmd().build(),
+			newExportACPsCmd().build(),
+			newPreflightCmd().build(),
 		},
 	}
 
@@ -19,23 +19,26 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology"
 )
 
-func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string, cfg platform.MetricsConfig, cfgWatcher *platform.ConfigWatcher) (*metrics.Manager, *metrics.Store, error) {
+func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string, cfg platform.MetricsConfig, cfgWatcher *platform.ConfigWatcher, transport http.RoundTripper, relabeler *metrics.Relabeler, logger zerolog.Logger) (*metrics.Manager, *metrics.Store, error) {
 	rc := retryablehttp.NewClient()
+	rc.HTTPClient.Transport = transport
 	rc.RetryWaitMin = time.Second
 	rc.RetryWaitMax = 10 * time.Second
 	rc.RetryMax = 4
-	rc.Logger = logger.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "metrics_client").Logger())
+	rc.Logger = logwrapper.NewRetryableHTTPWrapper(log.Logger.With().Str("component", "metrics_client").Logger())
 
 	httpClient := rc.StandardClient()
 
@@ -44,19 +47,15 @@ func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string,
 		return nil, nil, err
 	}
 
-	u, err := url.ParseRequestURI(traefikURL)
-	if err != nil {
+	if err = validateMetricsURL(traefikURL); err != nil {
 		return nil, nil, fmt.Errorf("parse traefik metrics url: %w", err)
 	}
-	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, nil, fmt.Errorf("only http and https is supported, %s found", u.Scheme)
-	}
 
 	store := metrics.NewStore()
 
-	scraper := metrics.NewScraper(httpClient)
+	scraper := metrics.NewScraper(httpClient, relabeler)
 
-	mgr := metrics.NewManager(client, traefikURL, store, scraper)
+	mgr := metrics.NewManager(client, traefikURL, store, scraper, logger)
 
 	mgr.SetConfig(cfg.Interval, cfg.Tables)
 
@@ -68,3 +67,52 @@ func newMetrics(watch *topology.Watcher, token, platformURL, traefikURL string,
 
 	return mgr, store, nil
 }
+
+// addMeshMetricsTarget makes the given manager scrape the service mesh sidecar metrics exposed on the given url.
+func addMeshMetricsTarget(mgr *metrics.Manager, parser, meshURL string) error {
+	switch parser {
+	case metrics.ParserIstio, metrics.ParserLinkerd, metrics.ParserAuto:
+	default:
+		return fmt.Errorf("invalid service mesh metrics parser %q", parser)
+	}
+
+	if err := validateMetricsURL(meshURL); err != nil {
+		return fmt.Errorf("parse service mesh metrics url: %w", err)
+	}
+
+	mgr.AddTarget(parser, meshURL)
+
+	return nil
+}
+
+func validateMetricsURL(rawURL string) error {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("only http and https is supported, %s found", u.Scheme)
+	}
+
+	return nil
+}
+
+// loadMetricsRelabeler loads the relabel rules applied to the scraped metrics from the given file. No rule is applied
+// when the path is empty.
+func loadMetricsRelabeler(path string) (*metrics.Relabeler, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	cfg, err := metrics.LoadRelabelConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("load metrics relabel config: %w", err)
+	}
+
+	relabeler, err := metrics.NewRelabeler(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metrics relabel config: %w", err)
+	}
+
+	return relabeler, nil
+}
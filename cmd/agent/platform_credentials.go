@@ -0,0 +1,197 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/tokenrotation"
+	"github.com/traefik/hub-agent-kubernetes/pkg/workloadidentity"
+	"github.com/urfave/cli/v2"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	flagTokenFile = "workload-identity.token-file"
+
+	flagTokenRotationSecret    = "token-rotation.secret"
+	flagTokenRotationSecretKey = "token-rotation.secret-key"
+)
+
+// tokenFileFlag returns the flag of the file holding the service account token exchanged for platform tokens.
+func tokenFileFlag(envVar string) *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    flagTokenFile,
+		Usage:   "File holding a service account token projected with the platform URL as audience, exchanged for the tokens used for Hub platform API calls instead of a static token. Mutually exclusive with --" + flagToken,
+		EnvVars: []string{envVar},
+	}
+}
+
+// tokenRotationFlags returns the flags locating the Secret in which the controller persists the token it renews.
+// Their environment variables are prefixed with the given prefix.
+func tokenRotationFlags(envPrefix string) []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagTokenRotationSecret,
+			Usage:   "The name of the secret, in the agent namespace, in which the controller persists the token when renewing it before its expiry. The token held by the secret takes precedence over --" + flagToken + ". The token rotation is disabled when empty. Incompatible with --" + flagTokenFile,
+			EnvVars: []string{envPrefix + "TOKEN_ROTATION_SECRET"},
+		},
+		&cli.StringFlag{
+			Name:    flagTokenRotationSecretKey,
+			Usage:   "The key of the token in the token rotation secret",
+			EnvVars: []string{envPrefix + "TOKEN_ROTATION_SECRET_KEY"},
+			Value:   "token",
+		},
+	}
+}
+
+// platformCredentials authenticates the agent on the platform, either with its static token, possibly renewed by the
+// token rotation, or with the platform tokens exchanged for its service account token.
+type platformCredentials struct {
+	token    string
+	identity *workloadidentity.TokenSource
+	rotation *tokenrotation.Manager
+}
+
+// newPlatformCredentials returns the credentials configured by the --token and --workload-identity.token-file flags,
+// which are mutually exclusive. When not required, neither of them may be set, in which case the platform is not
+// called.
+func newPlatformCredentials(cliCtx *cli.Context, required bool) (*platformCredentials, error) {
+	token, tokenFile := cliCtx.String(flagToken), cliCtx.String(flagTokenFile)
+
+	switch {
+	case token != "" && tokenFile != "":
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", flagToken, flagTokenFile)
+	case token != "":
+		return &platformCredentials{token: token}, nil
+	case tokenFile == "":
+		if required {
+			return nil, fmt.Errorf("either --%s or --%s must be set", flagToken, flagTokenFile)
+		}
+
+		return &platformCredentials{}, nil
+	}
+
+	identity, err := workloadidentity.NewTokenSource(cliCtx.String(flagPlatformURL), tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("create workload identity token source: %w", err)
+	}
+
+	return &platformCredentials{identity: identity}, nil
+}
+
+// enabled reports whether the agent can authenticate on the platform.
+func (c *platformCredentials) enabled() bool {
+	return c.token != "" || c.identity != nil
+}
+
+// Token returns the token authenticating the agent on the platform.
+func (c *platformCredentials) Token(ctx context.Context) (string, error) {
+	switch {
+	case c.identity != nil:
+		return c.identity.Token(ctx)
+	case c.rotation != nil:
+		return c.rotation.Token(), nil
+	default:
+		return c.token, nil
+	}
+}
+
+// Wrap returns a transport authenticating the requests sent to the platform with the exchanged or the rotated
+// tokens. The given transport is returned as is with a static token, which the platform clients set themselves.
+func (c *platformCredentials) Wrap(rt http.RoundTripper) http.RoundTripper {
+	return c.rotation.Wrap(c.identity.Wrap(rt))
+}
+
+// newPlatformClient returns a client of the platform, authenticated with the credentials. When the token rotation is
+// enabled, the token persisted in its Secret is adopted, and the credentials keep using the token held by the Secret
+// once watchRotation runs. An in-cluster Kubernetes client is created when the given one is nil.
+func (c *platformCredentials) newPlatformClient(cliCtx *cli.Context, kubeClient clientset.Interface) (*platform.Client, error) {
+	client, err := platform.NewClient(cliCtx.String(flagPlatformURL), c.token)
+	if err != nil {
+		return nil, err
+	}
+
+	if cliCtx.String(flagTokenRotationSecret) != "" && kubeClient == nil {
+		kubeCfg, errCfg := kube.InClusterConfigWithRetrier(2)
+		if errCfg != nil {
+			return nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", errCfg)
+		}
+
+		kubeClient, err = clientset.NewForConfig(kubeCfg)
+		if err != nil {
+			return nil, fmt.Errorf("create Kubernetes client set: %w", err)
+		}
+	}
+
+	c.rotation, err = newTokenRotation(cliCtx, c.token, client, kubeClient)
+	if err != nil {
+		return nil, err
+	}
+
+	client.WrapTransport(c.Wrap)
+
+	return client, nil
+}
+
+// watchRotation reloads the token from the Secret of the token rotation, in which the controller persists it when
+// renewed, until the context is done. It returns right away when the token rotation is disabled.
+func (c *platformCredentials) watchRotation(ctx context.Context) {
+	if c.rotation == nil {
+		return
+	}
+
+	c.rotation.Watch(ctx)
+}
+
+// newTokenRotation returns the Manager renewing the token of the agent before it expires, nil when the token rotation
+// is disabled. The token previously renewed, if any, is loaded from the secret.
+func newTokenRotation(cliCtx *cli.Context, token string, platformClient *platform.Client, kubeClient clientset.Interface) (*tokenrotation.Manager, error) {
+	secretName := cliCtx.String(flagTokenRotationSecret)
+	if secretName == "" {
+		return nil, nil
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("--%s requires --%s", flagTokenRotationSecret, flagToken)
+	}
+
+	rotation, err := tokenrotation.NewManager(cliCtx.String(flagPlatformURL), token, platformClient, kubeClient, tokenrotation.Config{
+		SecretNamespace: currentNamespace(),
+		SecretName:      secretName,
+		SecretKey:       cliCtx.String(flagTokenRotationSecretKey),
+		RenewBefore:     cliCtx.Duration(flagTokenRotationRenewBefore),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create token rotation manager: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(cliCtx.Context, 5*time.Second)
+	defer cancel()
+
+	if err = rotation.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load rotated token: %w", err)
+	}
+
+	return rotation, nil
+}
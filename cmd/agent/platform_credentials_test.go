@@ -0,0 +1,138 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewPlatformCredentials(t *testing.T) {
+	tests := []struct {
+		desc         string
+		args         []string
+		required     bool
+		wantErr      bool
+		wantEnabled  bool
+		wantToken    string
+		wantExchange bool
+	}{
+		{
+			desc:        "static token",
+			args:        []string{"--token=secret"},
+			required:    true,
+			wantEnabled: true,
+			wantToken:   "secret",
+		},
+		{
+			desc:         "workload identity",
+			args:         []string{"--workload-identity.token-file=/var/run/secrets/hub/token"},
+			required:     true,
+			wantEnabled:  true,
+			wantExchange: true,
+		},
+		{
+			desc: "none when optional",
+		},
+		{
+			desc:     "none when required",
+			required: true,
+			wantErr:  true,
+		},
+		{
+			desc:    "both",
+			args:    []string{"--token=secret", "--workload-identity.token-file=/var/run/secrets/hub/token"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags := []cli.Flag{
+				&cli.StringFlag{Name: flagPlatformURL, Value: "https://platform.hub.traefik.io/agent"},
+				&cli.StringFlag{Name: flagToken},
+				tokenFileFlag("WORKLOAD_IDENTITY_TOKEN_FILE"),
+			}
+			for _, f := range flags {
+				require.NoError(t, f.Apply(set))
+			}
+			require.NoError(t, set.Parse(test.args))
+
+			creds, err := newPlatformCredentials(cli.NewContext(nil, set, nil), test.required)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantEnabled, creds.enabled())
+			assert.Equal(t, test.wantExchange, creds.identity != nil)
+
+			if !test.wantExchange {
+				token, err := creds.Token(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, test.wantToken, token)
+			}
+		})
+	}
+}
+
+func TestPlatformCredentials_newPlatformClient_rotatedToken(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: flagPlatformURL, Value: "https://platform.hub.traefik.io/agent"},
+		&cli.StringFlag{Name: flagToken},
+		tokenFileFlag("WORKLOAD_IDENTITY_TOKEN_FILE"),
+	}
+	flags = append(flags, tokenRotationFlags("")...)
+	for _, f := range flags {
+		require.NoError(t, f.Apply(set))
+	}
+	require.NoError(t, set.Parse([]string{"--token=static", "--token-rotation.secret=hub-agent-token"}))
+
+	cliCtx := cli.NewContext(nil, set, nil)
+	cliCtx.Context = context.Background()
+
+	kubeClient := kubemock.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-agent-token", Namespace: currentNamespace()},
+		Data:       map[string][]byte{"token": []byte("rotated")},
+	})
+
+	creds, err := newPlatformCredentials(cliCtx, true)
+	require.NoError(t, err)
+
+	_, err = creds.newPlatformClient(cliCtx, kubeClient)
+	require.NoError(t, err)
+
+	token, err := creds.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", token)
+}
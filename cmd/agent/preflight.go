@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	"github.com/traefik/hub-agent-kubernetes/pkg/preflight"
+	"github.com/traefik/hub-agent-kubernetes/pkg/rbac"
+	"github.com/urfave/cli/v2"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+type preflightCmd struct {
+	flags []cli.Flag
+}
+
+func newPreflightCmd() preflightCmd {
+	// The checks run against the controller configuration, so the command can run in the controller Pod with its
+	// flags and environment.
+	return preflightCmd{flags: newControllerCmd().flags}
+}
+
+func (c preflightCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "preflight",
+		Usage:  "Verifies the requirements of the controller, like its RBAC permissions, the reachability of its webhooks and of the platform, and prints a report",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c preflightCmd) run(cliCtx *cli.Context) error {
+	platformURL, err := url.ParseRequestURI(cliCtx.String(flagPlatformURL))
+	if err != nil {
+		return fmt.Errorf("parse platform URL: %w", err)
+	}
+
+	creds, err := newPlatformCredentials(cliCtx, true)
+	if err != nil {
+		return err
+	}
+
+	kubeCfg, err := kube.InClusterConfigWithRetrier(2)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+	}
+
+	kubeClient, err := clientset.NewForConfig(kubeCfg)
+	if err != nil {
+		return fmt.Errorf("create Kubernetes client set: %w", err)
+	}
+
+	platformClient, err := creds.newPlatformClient(cliCtx, kubeClient)
+	if err != nil {
+		return fmt.Errorf("build platform client: %w", err)
+	}
+
+	// The capabilities select the optional subsystems, and thus the permissions they require.
+	var (
+		caps   capability.Capabilities
+		probed bool
+	)
+	probe := func(ctx context.Context) error {
+		var errProbe error
+		caps, errProbe = capability.Probe(ctx, kubeClient)
+		probed = errProbe == nil
+
+		return errProbe
+	}
+
+	checks := []preflight.Check{
+		{Name: "Platform DNS", Run: preflight.ResolveHost(net.DefaultResolver, platformURL.Hostname())},
+		{Name: "Platform egress", Run: preflight.Dial(platformAddr(platformURL))},
+		{Name: "Platform token", Run: preflight.PlatformToken(platformClient)},
+		{Name: "Hub CRDs", Run: preflight.Kinds(kubeClient.Discovery(), map[string][]string{
+			hubv1alpha1.SchemeGroupVersion.String(): {"AccessControlPolicy", "EdgeIngress", "IngressClass"},
+		})},
+		{Name: "Cluster capabilities", Run: probe},
+		{Name: "Optional CRDs", Optional: true, Run: func(context.Context) error {
+			if !probed {
+				return errors.New("cluster capabilities not probed")
+			}
+
+			return disabledSubsystems(caps)
+		}},
+		{Name: "RBAC", Run: func(ctx context.Context) error {
+			checker := rbac.NewChecker(kubeClient, controllerRBACRules(cliCtx, caps.APIManagement, caps.OpenShiftRoutes))
+			return preflight.RBAC(checker)(ctx)
+		}},
+		{Name: "Admission webhook", Run: preflight.Reachable(fmt.Sprintf("https://%s.%s.svc", cliCtx.String(flagACPServerServiceName), currentNamespace()))},
+	}
+
+	results := preflight.Run(cliCtx.Context, checks)
+	if err = preflight.WriteReport(cliCtx.App.Writer, results); err != nil {
+		return err
+	}
+
+	if preflight.Failed(results) {
+		return errors.New("preflight checks failed")
+	}
+
+	return nil
+}
+
+// disabledSubsystems returns an error listing the subsystems disabled because a capability is missing, if any.
+func disabledSubsystems(caps capability.Capabilities) error {
+	disabled := caps.Disabled()
+	if len(disabled) == 0 {
+		return nil
+	}
+
+	subsystems := make([]string, 0, len(disabled))
+	for subsystem, reason := range disabled {
+		subsystems = append(subsystems, subsystem+": "+reason)
+	}
+	sort.Strings(subsystems)
+
+	return fmt.Errorf("disabled subsystems: %s", strings.Join(subsystems, "; "))
+}
+
+// platformAddr returns the address of the platform, with the default port of its scheme if not set.
+func platformAddr(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+
+	if u.Scheme == "http" {
+		return net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	return net.JoinHostPort(u.Hostname(), "443")
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"github.com/traefik/hub-agent-kubernetes/pkg/rbac"
+	"github.com/urfave/cli/v2"
+)
+
+// controllerRBACRules returns the RBAC rules the controller needs given its configuration.
+func controllerRBACRules(cliCtx *cli.Context, apiManagement, openShiftRoutes bool) []rbac.Rule {
+	ns := currentNamespace()
+
+	var rules []rbac.Rule
+	add := func(feature, group, resource, namespace string, verbs ...string) {
+		rules = append(rules, rbac.Rules(feature, group, resource, namespace, verbs...)...)
+	}
+
+	add("core", "", "namespaces", "", "get")
+	add("core", "", "services", "", "list", "watch")
+	add("core", "", "pods", "", "list", "watch")
+	add("core", "", "secrets", "", "list", "watch")
+	add("core", "", "secrets", ns, "get", "create", "update", "patch")
+	add("core", "networking.k8s.io", "ingresses", "", "list", "watch", "create", "update", "patch", "delete")
+	add("core", "networking.k8s.io", "ingressclasses", "", "list", "watch", "create")
+	add("core", "hub.traefik.io", "ingressclasses", "", "list", "watch")
+
+	add("access-control-policies", "hub.traefik.io", "accesscontrolpolicies", "", "list", "watch", "update")
+	add("access-control-policies", "traefik.containo.us", "middlewares", "", "get", "create", "update", "patch", "delete")
+	add("access-control-policies", "traefik.containo.us", "ingressroutes", "", "list", "watch", "update", "patch")
+
+	if openShiftRoutes {
+		add("openshift-routes", "route.openshift.io", "routes", "", "list", "watch", "update")
+	}
+
+	add("edge-ingresses", "hub.traefik.io", "edgeingresses", "", "list", "watch", "update")
+	add("edge-ingresses", "traefik.containo.us", "traefikservices", "", "list", "watch", "patch", "delete")
+	add("edge-ingresses", "traefik.containo.us", "ingressroutes", "", "patch", "delete")
+	add("edge-ingresses", "traefik.containo.us", "middlewares", "", "get", "create", "update", "patch", "delete")
+
+	if apiManagement {
+		for _, resource := range []string{"apis", "apicollections", "apiaccesses", "apiportals", "apigateways", "apiratelimits"} {
+			add("api-management", "hub.traefik.io", resource, "", "list", "watch", "update")
+		}
+		// APIs are created from the platform, discovered from annotated Ingresses and IngressRoutes and imported in batch.
+		add("api-management", "hub.traefik.io", "apis", "", "get", "create")
+		// APIs having weighted services are routed through generated TraefikServices and IngressRoutes.
+		add("api-management", "traefik.containo.us", "traefikservices", "", "patch", "delete")
+		add("api-management", "traefik.containo.us", "ingressroutes", "", "patch", "delete")
+		add("api-management", "", "configmaps", "", "list", "watch")
+		add("api-management", "", "configmaps", ns, "create", "update", "delete")
+	}
+
+	if cliCtx.String(flagTraefikMetricsURL) != "" {
+		// Alerts are raised on the expiry of the certificates of the ingresses.
+		add("alerting", "", "secrets", "", "get")
+	}
+
+	if cliCtx.Bool(flagTopologyEvents) {
+		add("topology-events", "", "events", "", "list", "watch")
+	}
+
+	if cliCtx.Bool(flagLeaderElection) {
+		add("leader-election", "coordination.k8s.io", "leases", ns, "get", "create", "update")
+	}
+
+	if cliCtx.String(flagACPServerCertSecret) != "" {
+		if len(cliCtx.StringSlice(flagACPServerWebhookConfigurations)) > 0 {
+			add("webhook-certificate", "admissionregistration.k8s.io", "mutatingwebhookconfigurations", "", "get", "update")
+		}
+		if len(cliCtx.StringSlice(flagACPServerValidatingWebhooks)) > 0 {
+			add("webhook-certificate", "admissionregistration.k8s.io", "validatingwebhookconfigurations", "", "get", "update")
+		}
+		if len(cliCtx.StringSlice(flagACPServerConversionCRDs)) > 0 {
+			add("webhook-certificate", "apiextensions.k8s.io", "customresourcedefinitions", "", "get", "update")
+		}
+	}
+
+	if cliCtx.Bool(flagACPServerAuditEvents) {
+		add("admission-audit", "events.k8s.io", "events", "", "create")
+	}
+
+	if cliCtx.Duration(flagACPServerDriftInterval) > 0 {
+		add("drift", "traefik.containo.us", "middlewares", "", "list")
+		add("drift", "traefik.containo.us", "ingressroutes", "", "list")
+		add("drift", "traefik.containo.us", "traefikservices", "", "list")
+		add("drift", "events.k8s.io", "events", "", "create")
+	}
+
+	if cliCtx.String(flagACPServerWebhookConfigName) != "" {
+		add("webhook-configuration", "admissionregistration.k8s.io", "mutatingwebhookconfigurations", "", "get", "create", "update")
+		if apiManagement {
+			add("webhook-configuration", "admissionregistration.k8s.io", "validatingwebhookconfigurations", "", "get", "create", "update")
+			add("webhook-configuration", "apiextensions.k8s.io", "customresourcedefinitions", "", "get", "update")
+		}
+	}
+
+	return rules
+}
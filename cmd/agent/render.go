@@ -0,0 +1,280 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"github.com/traefik/hub-agent-kubernetes/pkg/manifest"
+	"github.com/urfave/cli/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	flagRenderFilename   = "filename"
+	flagRenderKubeconfig = "kubeconfig"
+	flagRenderGateway    = "gateway"
+)
+
+type renderCmd struct {
+	flags []cli.Flag
+}
+
+func newRenderCmd() renderCmd {
+	return renderCmd{
+		flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    flagRenderFilename,
+				Aliases: []string{"f"},
+				Usage:   "Manifest file, or directory of manifest files, holding the API management resources to render. Resources are read from the cluster when not set",
+			},
+			&cli.StringFlag{
+				Name:  flagRenderKubeconfig,
+				Usage: "Path to the kubeconfig file used to read the resources from the cluster. The KUBECONFIG environment variable, the default kubeconfig file or the in-cluster configuration are used when empty",
+			},
+			&cli.StringSliceFlag{
+				Name:  flagRenderGateway,
+				Usage: "Names of the APIGateways to render. All APIGateways are rendered when not set",
+			},
+			&cli.StringFlag{
+				Name:    flagIngressClassName,
+				Usage:   "The ingress class name used for ingresses managed by Hub",
+				EnvVars: []string{strcase.ToSNAKE(flagIngressClassName)},
+				Value:   "traefik-hub",
+			},
+			&cli.StringFlag{
+				Name:    flagTraefikAPIEntryPoint,
+				Usage:   "The entry point used by Traefik to expose APIs",
+				EnvVars: []string{strcase.ToSNAKE(flagTraefikAPIEntryPoint)},
+				Value:   "websecure",
+			},
+			&cli.StringFlag{
+				Name:    flagTraefikTunnelEntryPoint,
+				Usage:   "The entry point used by Traefik to expose tunnels",
+				EnvVars: []string{strcase.ToSNAKE(flagTraefikTunnelEntryPoint)},
+				Value:   "traefikhub-tunl",
+			},
+			&cli.BoolFlag{
+				Name:    flagAPIGatewayAPITokenValidation,
+				Usage:   "Render the middlewares validating, using the auth server, the API tokens of the requests sent to the APIGateways",
+				EnvVars: []string{strcase.ToSNAKE(flagAPIGatewayAPITokenValidation)},
+			},
+			&cli.StringFlag{
+				Name:    flagACPServerAuthServerAddr,
+				Usage:   "Address the ACP server can reach the auth server on",
+				EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerAddr)},
+				Value:   "http://hub-agent-auth-server.hub.svc.cluster.local",
+			},
+		},
+	}
+}
+
+func (c renderCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "render",
+		Usage:  "Prints the Traefik resources the agent generates to expose the APIs of the APIGateways",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c renderCmd) run(cliCtx *cli.Context) error {
+	var (
+		objects []runtime.Object
+		err     error
+	)
+	if paths := cliCtx.StringSlice(flagRenderFilename); len(paths) > 0 {
+		objects, err = loadManifestObjects(paths)
+	} else {
+		objects, err = loadClusterObjects(cliCtx.Context, cliCtx.String(flagRenderKubeconfig))
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg := &api.WatcherGatewayConfig{
+		IngressClassName:        cliCtx.String(flagIngressClassName),
+		TraefikAPIEntryPoint:    cliCtx.String(flagTraefikAPIEntryPoint),
+		TraefikTunnelEntryPoint: cliCtx.String(flagTraefikTunnelEntryPoint),
+	}
+	if cliCtx.Bool(flagAPIGatewayAPITokenValidation) {
+		cfg.AuthServerAddr = cliCtx.String(flagACPServerAuthServerAddr)
+	}
+
+	renderer, err := api.NewRenderer(cfg, objects)
+	if err != nil {
+		return fmt.Errorf("create renderer: %w", err)
+	}
+
+	gateways, err := selectGateways(objects, cliCtx.StringSlice(flagRenderGateway))
+	if err != nil {
+		return err
+	}
+
+	for _, gateway := range gateways {
+		render, err := renderer.Render(gateway)
+		if err != nil {
+			return fmt.Errorf("render APIGateway %q: %w", gateway.Name, err)
+		}
+
+		if err = printGatewayRender(cliCtx.App.Writer, render); err != nil {
+			return fmt.Errorf("print APIGateway %q: %w", gateway.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func loadManifestObjects(paths []string) ([]runtime.Object, error) {
+	manifestObjects, err := manifest.Load(paths)
+	if err != nil {
+		return nil, fmt.Errorf("load manifests: %w", err)
+	}
+
+	objects := make([]runtime.Object, 0, len(manifestObjects))
+	for _, object := range manifestObjects {
+		objects = append(objects, object.Object)
+	}
+
+	return objects, nil
+}
+
+func loadClusterObjects(ctx context.Context, kubeconfig string) ([]runtime.Object, error) {
+	hubClientSet, err := newKubeconfigHubClientSet(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	client := hubClientSet.HubV1alpha1()
+
+	var objects []runtime.Object
+
+	apis, err := client.APIs("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list APIs: %w", err)
+	}
+	for i := range apis.Items {
+		objects = append(objects, &apis.Items[i])
+	}
+
+	collections, err := client.APICollections().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list APICollections: %w", err)
+	}
+	for i := range collections.Items {
+		objects = append(objects, &collections.Items[i])
+	}
+
+	accesses, err := client.APIAccesses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list APIAccesses: %w", err)
+	}
+	for i := range accesses.Items {
+		objects = append(objects, &accesses.Items[i])
+	}
+
+	gateways, err := client.APIGateways().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list APIGateways: %w", err)
+	}
+	for i := range gateways.Items {
+		objects = append(objects, &gateways.Items[i])
+	}
+
+	return objects, nil
+}
+
+// newKubeconfigHubClientSet creates a Hub client set from the given kubeconfig file. The KUBECONFIG environment
+// variable, the default kubeconfig file or the in-cluster configuration are used when empty.
+func newKubeconfigHubClientSet(kubeconfig string) (hubclientset.Interface, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("create Kubernetes configuration: %w", err)
+	}
+
+	hubClientSet, err := hubclientset.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("create Hub client set: %w", err)
+	}
+
+	return hubClientSet, nil
+}
+
+// selectGateways returns the APIGateways among the given objects with the given names, or all of them when no name is
+// given.
+func selectGateways(objects []runtime.Object, names []string) ([]*hubv1alpha1.APIGateway, error) {
+	gatewaysByName := make(map[string]*hubv1alpha1.APIGateway)
+	var gateways []*hubv1alpha1.APIGateway
+	for _, obj := range objects {
+		if gateway, ok := obj.(*hubv1alpha1.APIGateway); ok {
+			gatewaysByName[gateway.Name] = gateway
+			gateways = append(gateways, gateway)
+		}
+	}
+
+	if len(names) == 0 {
+		return gateways, nil
+	}
+
+	selected := make([]*hubv1alpha1.APIGateway, 0, len(names))
+	for _, name := range names {
+		gateway, ok := gatewaysByName[name]
+		if !ok {
+			return nil, fmt.Errorf("APIGateway %q not found", name)
+		}
+		selected = append(selected, gateway)
+	}
+
+	return selected, nil
+}
+
+// printGatewayRender prints the rendered resources as a YAML stream, preceded by the render warnings as comments.
+func printGatewayRender(w io.Writer, render *api.GatewayRender) error {
+	if _, err := fmt.Fprintf(w, "# APIGateway %q\n", render.Gateway); err != nil {
+		return err
+	}
+	for _, warning := range render.Warnings {
+		if _, err := fmt.Fprintf(w, "# WARNING: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	for _, obj := range render.Objects {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("marshal %T: %w", obj, err)
+		}
+
+		if _, err = fmt.Fprintf(w, "---\n%s", b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	stdlog "log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	"github.com/urfave/cli/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	kubeinformers "k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	flagServerReadTimeout    = "server.read-timeout"
+	flagServerWriteTimeout   = "server.write-timeout"
+	flagServerIdleTimeout    = "server.idle-timeout"
+	flagServerMaxHeaderBytes = "server.max-header-bytes"
+	flagServerHTTP2          = "server.http2"
+	flagServerKeepAlive      = "server.keep-alive"
+	flagTLSSecret            = "tls.secret"
+	flagTLSCertFile          = "tls.cert-file"
+	flagTLSKeyFile           = "tls.key-file"
+)
+
+// readHeaderTimeout is the time given to clients to send the headers of their requests.
+const readHeaderTimeout = 2 * time.Second
+
+const (
+	// drainDelay is the time given to Kubernetes to stop routing traffic to a server reported as not ready anymore
+	// before the server stops accepting new connections.
+	drainDelay = 5 * time.Second
+	// shutdownTimeout is the time given to a server to complete its in-flight requests once it stopped accepting
+	// new connections.
+	shutdownTimeout = 15 * time.Second
+)
+
+func serverFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:    flagServerReadTimeout,
+			Usage:   "Maximum duration for reading an entire request, including its body",
+			EnvVars: []string{strcase.ToSNAKE(flagServerReadTimeout)},
+			Value:   10 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagServerWriteTimeout,
+			Usage:   "Maximum duration before timing out writes of a response",
+			EnvVars: []string{strcase.ToSNAKE(flagServerWriteTimeout)},
+			Value:   30 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagServerIdleTimeout,
+			Usage:   "Maximum duration to wait for the next request when keep-alives are enabled",
+			EnvVars: []string{strcase.ToSNAKE(flagServerIdleTimeout)},
+			Value:   90 * time.Second,
+		},
+		&cli.IntFlag{
+			Name:    flagServerMaxHeaderBytes,
+			Usage:   "Maximum number of bytes read when parsing the request headers",
+			EnvVars: []string{strcase.ToSNAKE(flagServerMaxHeaderBytes)},
+			Value:   http.DefaultMaxHeaderBytes,
+		},
+		&cli.BoolFlag{
+			Name:    flagServerHTTP2,
+			Usage:   "Enable HTTP/2 on TLS connections",
+			EnvVars: []string{strcase.ToSNAKE(flagServerHTTP2)},
+			Value:   true,
+		},
+		&cli.BoolFlag{
+			Name:    flagServerKeepAlive,
+			Usage:   "Enable HTTP keep-alives",
+			EnvVars: []string{strcase.ToSNAKE(flagServerKeepAlive)},
+			Value:   true,
+		},
+	}
+}
+
+// newServer creates a server listening on the given address and configured through the server flags.
+func newServer(cliCtx *cli.Context, addr string, handler http.Handler) (*http.Server, error) {
+	maxHeaderBytes := cliCtx.Int(flagServerMaxHeaderBytes)
+	if maxHeaderBytes <= 0 {
+		return nil, fmt.Errorf("invalid max header bytes %d: must be positive", maxHeaderBytes)
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       cliCtx.Duration(flagServerReadTimeout),
+		WriteTimeout:      cliCtx.Duration(flagServerWriteTimeout),
+		IdleTimeout:       cliCtx.Duration(flagServerIdleTimeout),
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+
+	if !cliCtx.Bool(flagServerHTTP2) {
+		// A non-nil empty map prevents the server from negotiating HTTP/2 over TLS.
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	server.SetKeepAlivesEnabled(cliCtx.Bool(flagServerKeepAlive))
+
+	return server, nil
+}
+
+func tlsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    flagTLSSecret,
+			Usage:   "Name of the Secret, of type kubernetes.io/tls and in the agent namespace, holding the certificate used to serve requests over TLS. Requests are served over plain HTTP when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagTLSSecret)},
+		},
+		&cli.StringFlag{
+			Name:    flagTLSCertFile,
+			Usage:   "PEM file holding the certificate used to serve requests over TLS, for servers running outside Kubernetes. Mutually exclusive with --" + flagTLSSecret,
+			EnvVars: []string{strcase.ToSNAKE(flagTLSCertFile)},
+		},
+		&cli.StringFlag{
+			Name:    flagTLSKeyFile,
+			Usage:   "PEM file holding the private key of the certificate set with --" + flagTLSCertFile,
+			EnvVars: []string{strcase.ToSNAKE(flagTLSKeyFile)},
+		},
+	}
+}
+
+// newTLSConfig returns the TLS configuration serving the certificate of the Secret or the files configured through the
+// TLS flags, nil if none is configured. The Secret is watched until the given context is done, so renewed certificates
+// are served without restarting. The given client set is only used when a Secret is configured.
+func newTLSConfig(ctx context.Context, cliCtx *cli.Context, kubeClientSet clientset.Interface) (*tls.Config, error) {
+	name := cliCtx.String(flagTLSSecret)
+	certFile, keyFile := cliCtx.String(flagTLSCertFile), cliCtx.String(flagTLSKeyFile)
+
+	switch {
+	case name != "" && (certFile != "" || keyFile != ""):
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", flagTLSSecret, flagTLSCertFile)
+	case (certFile == "") != (keyFile == ""):
+		return nil, fmt.Errorf("--%s and --%s must be set together", flagTLSCertFile, flagTLSKeyFile)
+	case certFile != "":
+		return newFileTLSConfig(certFile, keyFile)
+	case name == "":
+		return nil, nil
+	}
+
+	ns := currentNamespace()
+	informer := kubeinformers.NewSharedInformerFactoryWithOptions(kubeClientSet, 5*time.Minute,
+		kubeinformers.WithNamespace(ns),
+		kubeinformers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+	secrets := informer.Core().V1().Secrets().Lister().Secrets(ns)
+
+	informer.Start(ctx.Done())
+	for t, ok := range informer.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("wait for TLS secret cache sync: %s: %w", t, ctx.Err())
+		}
+	}
+
+	cert := kube.NewSecretCertificate(secrets, name)
+	if _, err := cert.GetCertificate(nil); err != nil {
+		return nil, fmt.Errorf("load TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.GetCertificate,
+	}, nil
+}
+
+// newFileTLSConfig returns the TLS configuration serving the certificate of the given files, which are checked on
+// every TLS handshake so renewed certificates are served without restarting.
+func newFileTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	cert := kube.NewFileCertificate(certFile, keyFile)
+	if _, err := cert.GetCertificate(nil); err != nil {
+		return nil, fmt.Errorf("load TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.GetCertificate,
+	}, nil
+}
+
+// unixSocketPrefix is the prefix of the listen addresses designating a Unix domain socket.
+const unixSocketPrefix = "unix:"
+
+// listenAndServe returns the function running the given server, over TLS when it has a TLS configuration.
+func listenAndServe(server *http.Server) func() error {
+	return func() error {
+		ln, err := listen(server.Addr)
+		if err != nil {
+			return err
+		}
+
+		if server.TLSConfig != nil {
+			// The certificate is provided by the TLS configuration.
+			return server.ServeTLS(ln, "", "")
+		}
+
+		return server.Serve(ln)
+	}
+}
+
+// listen listens on the given address. Addresses prefixed with "unix:" designate the path of a Unix domain socket,
+// which is removed first if left over by a previous run. Other addresses are TCP addresses.
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixSocketPrefix) {
+		return net.Listen("tcp", addr)
+	}
+
+	path := strings.TrimPrefix(addr, unixSocketPrefix)
+	if path == "" {
+		return nil, fmt.Errorf("invalid listen address %q: missing socket path", addr)
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err = os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	return net.Listen("unix", path)
+}
+
+// readiness is an HTTP handler reporting the server as ready until it starts shutting down.
+type readiness struct {
+	shuttingDown atomic.Bool
+}
+
+// ServeHTTP implements http.Handler.
+func (r *readiness) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	if r.shuttingDown.Load() {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// serve runs the given server using listenAndServe until the context is done. It then reports the server as not
+// ready, if a readiness is given, waits for the traffic to be drained and shuts the server down, letting in-flight
+// requests complete.
+func serve(ctx context.Context, name string, server *http.Server, listenAndServe func() error, ready *readiness) error {
+	logger := log.With().Str("server", name).Logger()
+
+	srvDone := make(chan struct{})
+
+	go func() {
+		logger.Info().Str("addr", server.Addr).Msg("Starting server")
+		if err := listenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logger.Err(err).Msg("Unable to listen and serve requests")
+		}
+		close(srvDone)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-srvDone:
+		return fmt.Errorf("%s stopped", name)
+	}
+
+	if ready != nil {
+		ready.shuttingDown.Store(true)
+
+		logger.Info().Dur("delay", drainDelay).Msg("Draining traffic")
+		time.Sleep(drainDelay)
+	}
+
+	gracefulCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(gracefulCtx); err != nil {
+		logger.Error().Err(err).Msg("Failed to shutdown server gracefully")
+		if err = server.Close(); err != nil {
+			return fmt.Errorf("close %s: %w", name, err)
+		}
+	}
+	logger.Info().Msg("Successfully shutdown server")
+
+	return nil
+}
@@ -0,0 +1,211 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestNewServer(t *testing.T) {
+	tests := []struct {
+		desc            string
+		args            []string
+		wantErr         bool
+		wantReadTimeout time.Duration
+		wantHeaderBytes int
+		wantHTTP2       bool
+	}{
+		{
+			desc:            "defaults",
+			wantReadTimeout: 10 * time.Second,
+			wantHeaderBytes: http.DefaultMaxHeaderBytes,
+			wantHTTP2:       true,
+		},
+		{
+			desc:            "custom configuration",
+			args:            []string{"--server.read-timeout=1m", "--server.max-header-bytes=4096", "--server.http2=false"},
+			wantReadTimeout: time.Minute,
+			wantHeaderBytes: 4096,
+		},
+		{
+			desc:    "invalid max header bytes",
+			args:    []string{"--server.max-header-bytes=0"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			for _, f := range serverFlags() {
+				require.NoError(t, f.Apply(set))
+			}
+			require.NoError(t, set.Parse(test.args))
+
+			server, err := newServer(cli.NewContext(nil, set, nil), "127.0.0.1:8080", http.NotFoundHandler())
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "127.0.0.1:8080", server.Addr)
+			assert.Equal(t, readHeaderTimeout, server.ReadHeaderTimeout)
+			assert.Equal(t, test.wantReadTimeout, server.ReadTimeout)
+			assert.Equal(t, test.wantHeaderBytes, server.MaxHeaderBytes)
+			assert.Equal(t, test.wantHTTP2, server.TLSNextProto == nil)
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate(t, certFile, keyFile)
+
+	tests := []struct {
+		desc       string
+		args       []string
+		wantErr    bool
+		wantConfig bool
+	}{
+		{
+			desc: "no TLS",
+		},
+		{
+			desc:       "certificate files",
+			args:       []string{"--tls.cert-file=" + certFile, "--tls.key-file=" + keyFile},
+			wantConfig: true,
+		},
+		{
+			desc:    "certificate file without key file",
+			args:    []string{"--tls.cert-file=" + certFile},
+			wantErr: true,
+		},
+		{
+			desc:    "missing certificate files",
+			args:    []string{"--tls.cert-file=" + filepath.Join(dir, "missing.crt"), "--tls.key-file=" + keyFile},
+			wantErr: true,
+		},
+		{
+			desc:    "secret and certificate files",
+			args:    []string{"--tls.secret=hub-agent-tls", "--tls.cert-file=" + certFile, "--tls.key-file=" + keyFile},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("test", flag.ContinueOnError)
+			for _, f := range tlsFlags() {
+				require.NoError(t, f.Apply(set))
+			}
+			require.NoError(t, set.Parse(test.args))
+
+			// No Kubernetes client set is needed unless a Secret is configured.
+			cfg, err := newTLSConfig(context.Background(), cli.NewContext(nil, set, nil), nil)
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if !test.wantConfig {
+				assert.Nil(t, cfg)
+				return
+			}
+
+			require.NotNil(t, cfg)
+			cert, err := cfg.GetCertificate(&tls.ClientHelloInfo{})
+			require.NoError(t, err)
+			assert.NotEmpty(t, cert.Certificate)
+		})
+	}
+}
+
+func writeCertificate(t *testing.T, certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "auth-server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+}
+
+func TestListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth-server.sock")
+
+	// Sockets left over by a previous run don't prevent the server from listening.
+	ln, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+
+	ln, err = listen("unix:" + path)
+	require.NoError(t, err)
+	assert.Equal(t, "unix", ln.Addr().Network())
+	require.NoError(t, ln.Close())
+
+	ln, err = listen("127.0.0.1:0")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", ln.Addr().Network())
+	require.NoError(t, ln.Close())
+
+	_, err = listen("unix:")
+	require.Error(t, err)
+}
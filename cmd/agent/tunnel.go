@@ -18,11 +18,22 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net"
+	"net/http"
+	"time"
 
 	"github.com/ettle/strcase"
-	"github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/heartbeat"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
 	"github.com/traefik/hub-agent-kubernetes/pkg/tunnel"
 	"github.com/urfave/cli/v2"
 )
@@ -34,8 +45,19 @@ type tunnelCmd struct {
 const (
 	flagTraefikTunnelHost = "traefik.tunnel-host"
 	flagTraefikTunnelPort = "traefik.tunnel-port"
+
+	flagTunnelHeartbeatInterval   = "tunnel.heartbeat-interval"
+	flagTunnelHeartbeatTimeout    = "tunnel.heartbeat-timeout"
+	flagTunnelStreamWindowSize    = "tunnel.stream-window-size"
+	flagTunnelAcceptBacklog       = "tunnel.accept-backlog"
+	flagTunnelReconnectMinBackoff = "tunnel.reconnect-min-backoff"
+	flagTunnelReconnectMaxBackoff = "tunnel.reconnect-max-backoff"
+	flagTunnelMetricsListenAddr   = "tunnel.metrics-listen-addr"
 )
 
+// minStreamWindowSize is the initial window size of a stream, under which the window can't be configured.
+const minStreamWindowSize = 256 * 1024
+
 func newTunnelCmd() tunnelCmd {
 	flags := []cli.Flag{
 		&cli.StringFlag{
@@ -46,11 +68,11 @@ func newTunnelCmd() tunnelCmd {
 			Hidden:  true,
 		},
 		&cli.StringFlag{
-			Name:     flagToken,
-			Usage:    "The token to use for Hub platform API calls",
-			EnvVars:  []string{strcase.ToSNAKE(flagToken)},
-			Required: true,
+			Name:    flagToken,
+			Usage:   "The token to use for Hub platform API calls. Required unless --" + flagTokenFile + " is set",
+			EnvVars: []string{strcase.ToSNAKE(flagToken)},
 		},
+		tokenFileFlag(strcase.ToSNAKE(flagTokenFile)),
 		&cli.StringFlag{
 			Name:     flagTraefikTunnelHost,
 			Usage:    "The Traefik tunnel host",
@@ -64,9 +86,53 @@ func newTunnelCmd() tunnelCmd {
 			Value:    "9901",
 			Required: false,
 		},
+		&cli.DurationFlag{
+			Name:    flagTunnelHeartbeatInterval,
+			Usage:   "Interval at which heartbeats are sent to the broker to detect dead tunnel connections",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelHeartbeatInterval)},
+			Value:   30 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagTunnelHeartbeatTimeout,
+			Usage:   "Maximum time to wait for a heartbeat answer or a write to complete before reconnecting the tunnel",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelHeartbeatTimeout)},
+			Value:   10 * time.Second,
+		},
+		&cli.UintFlag{
+			Name:    flagTunnelStreamWindowSize,
+			Usage:   "Maximum amount of bytes buffered for each tunnel stream before applying back-pressure on the broker",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelStreamWindowSize)},
+			Value:   minStreamWindowSize,
+		},
+		&cli.IntFlag{
+			Name:    flagTunnelAcceptBacklog,
+			Usage:   "Maximum number of tunnel streams waiting to be accepted",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelAcceptBacklog)},
+			Value:   256,
+		},
+		&cli.DurationFlag{
+			Name:    flagTunnelReconnectMinBackoff,
+			Usage:   "Delay before the first attempt to reconnect a lost tunnel connection",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelReconnectMinBackoff)},
+			Value:   time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagTunnelReconnectMaxBackoff,
+			Usage:   "Maximum delay between two attempts to reconnect a lost tunnel connection",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelReconnectMaxBackoff)},
+			Value:   time.Minute,
+		},
+		&cli.StringFlag{
+			Name:    flagTunnelMetricsListenAddr,
+			Usage:   "Address on which the tunnel Prometheus metrics and status are exposed. They are disabled when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagTunnelMetricsListenAddr)},
+			Value:   ":9090",
+		},
 	}
 
+	flags = append(flags, tokenRotationFlags("")...)
 	flags = append(flags, globalFlags()...)
+	flags = append(flags, serverFlags()...)
 
 	return tunnelCmd{
 		flags: flags,
@@ -83,21 +149,146 @@ func (c tunnelCmd) build() *cli.Command {
 }
 
 func (c tunnelCmd) run(cliCtx *cli.Context) error {
-	logger.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
+	logwrapper.Setup(cliCtx.String(flagLogLevel), cliCtx.String(flagLogFormat))
 
 	ctx := cliCtx.Context
 
 	platformURL := cliCtx.String(flagPlatformURL)
-	token := cliCtx.String(flagToken)
 
-	tunnelClient, err := tunnel.NewClient(platformURL, token)
+	creds, err := newPlatformCredentials(cliCtx, true)
+	if err != nil {
+		return err
+	}
+
+	// The platform client is created first, so the tunnels use the rotated token, if any.
+	platformClient, err := creds.newPlatformClient(cliCtx, nil)
+	if err != nil {
+		return fmt.Errorf("create platform client: %w", err)
+	}
+	go creds.watchRotation(ctx)
+
+	tunnelClient, err := tunnel.NewClient(platformURL, creds)
 	if err != nil {
 		return fmt.Errorf("create tunnel client: %w", err)
 	}
 
+	tunnelConfig, err := newTunnelConfig(cliCtx)
+	if err != nil {
+		return fmt.Errorf("create tunnel config: %w", err)
+	}
+
 	traefikAddr := net.JoinHostPort(cliCtx.String(flagTraefikTunnelHost), cliCtx.String(flagTraefikTunnelPort))
-	tunnelManager := tunnel.NewManager(tunnelClient, traefikAddr, token)
+	tunnelManager := tunnel.NewManager(tunnelClient, traefikAddr, creds, tunnelConfig)
+
+	if addr := cliCtx.String(flagTunnelMetricsListenAddr); addr != "" {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(&tunnelManager)
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+		statusHandler := status.NewHandler()
+		statusHandler.Register("tunnels", tunnelsStatus(&tunnelManager))
+		mux.Handle("/status", statusHandler)
+
+		server, err := newServer(cliCtx, addr, mux)
+		if err != nil {
+			return fmt.Errorf("create metrics server: %w", err)
+		}
+
+		go func() {
+			if err := serve(ctx, "tunnel metrics", server, listenAndServe(server), nil); err != nil {
+				log.Error().Err(err).Msg("Tunnel metrics server stopped")
+			}
+		}()
+	}
+
+	heartbeater := heartbeat.NewHeartbeater(tunnelStatsPinger{platform: platformClient, tunnels: &tunnelManager})
+	go heartbeater.Run(ctx)
+
 	tunnelManager.Run(ctx)
 
 	return nil
 }
+
+// tunnelStatsPinger pings the platform with the statistics of the tunnels.
+type tunnelStatsPinger struct {
+	platform *platform.Client
+	tunnels  *tunnel.Manager
+}
+
+func (p tunnelStatsPinger) Ping(ctx context.Context) error {
+	var stats []platform.TunnelStats
+	for _, s := range p.tunnels.Stats() {
+		stats = append(stats, platform.TunnelStats{
+			TunnelID:      s.TunnelID,
+			BytesReceived: s.BytesReceived,
+			BytesSent:     s.BytesSent,
+			ActiveStreams: s.ActiveStreams,
+			Reconnects:    s.Reconnects,
+			RTTMillis:     s.RTT.Milliseconds(),
+		})
+	}
+
+	return p.platform.PingWithTunnelStats(ctx, stats)
+}
+
+// tunnelsStatus returns a check reporting the tunnels as down when none of them is connected to its broker, and as
+// degraded when some of them aren't.
+func tunnelsStatus(tunnels *tunnel.Manager) status.Check {
+	return func(_ context.Context) status.Report {
+		stats := tunnels.Stats()
+
+		report := status.Report{State: status.StateOK, Details: make(map[string]string, len(stats))}
+
+		var disconnected int
+		for _, s := range stats {
+			if !s.Connected {
+				disconnected++
+				report.Details[s.TunnelID] = "disconnected"
+				continue
+			}
+
+			report.Details[s.TunnelID] = "connected"
+		}
+
+		switch {
+		case disconnected > 0 && disconnected == len(stats):
+			report.State = status.StateDown
+			report.Message = "No tunnel connected"
+		case disconnected > 0:
+			report.State = status.StateDegraded
+			report.Message = fmt.Sprintf("%d of %d tunnels disconnected", disconnected, len(stats))
+		}
+
+		return report
+	}
+}
+
+func newTunnelConfig(cliCtx *cli.Context) (tunnel.Config, error) {
+	cfg := tunnel.Config{
+		HeartbeatInterval:   cliCtx.Duration(flagTunnelHeartbeatInterval),
+		HeartbeatTimeout:    cliCtx.Duration(flagTunnelHeartbeatTimeout),
+		StreamWindowSize:    uint32(cliCtx.Uint(flagTunnelStreamWindowSize)),
+		AcceptBacklog:       cliCtx.Int(flagTunnelAcceptBacklog),
+		ReconnectMinBackoff: cliCtx.Duration(flagTunnelReconnectMinBackoff),
+		ReconnectMaxBackoff: cliCtx.Duration(flagTunnelReconnectMaxBackoff),
+	}
+
+	switch {
+	case cfg.HeartbeatInterval <= 0:
+		return tunnel.Config{}, errors.New("heartbeat interval must be positive")
+	case cfg.HeartbeatTimeout <= 0:
+		return tunnel.Config{}, errors.New("heartbeat timeout must be positive")
+	case cliCtx.Uint(flagTunnelStreamWindowSize) < minStreamWindowSize || cliCtx.Uint(flagTunnelStreamWindowSize) > math.MaxUint32:
+		return tunnel.Config{}, fmt.Errorf("stream window size must be between %d and %d bytes", minStreamWindowSize, uint32(math.MaxUint32))
+	case cfg.AcceptBacklog <= 0:
+		return tunnel.Config{}, errors.New("accept backlog must be positive")
+	case cfg.ReconnectMinBackoff <= 0:
+		return tunnel.Config{}, errors.New("reconnect min backoff must be positive")
+	case cfg.ReconnectMaxBackoff < cfg.ReconnectMinBackoff:
+		return tunnel.Config{}, errors.New("reconnect max backoff must be greater than or equal to the min backoff")
+	}
+
+	return cfg, nil
+}
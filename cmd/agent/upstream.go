@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	flagUpstreamMaxIdleConns        = "upstream.max-idle-conns"
+	flagUpstreamMaxIdleConnsPerHost = "upstream.max-idle-conns-per-host"
+	flagUpstreamIdleConnTimeout     = "upstream.idle-conn-timeout"
+	flagUpstreamDialTimeout         = "upstream.dial-timeout"
+	flagUpstreamHTTP2               = "upstream.http2"
+)
+
+func upstreamFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:    flagUpstreamMaxIdleConns,
+			Usage:   "Maximum number of idle connections kept to the upstreams, namely the pods serving OpenAPI specs and metrics. No limit when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagUpstreamMaxIdleConns)},
+			Value:   512,
+		},
+		&cli.IntFlag{
+			Name:    flagUpstreamMaxIdleConnsPerHost,
+			Usage:   "Maximum number of idle connections kept to each upstream",
+			EnvVars: []string{strcase.ToSNAKE(flagUpstreamMaxIdleConnsPerHost)},
+			Value:   8,
+		},
+		&cli.DurationFlag{
+			Name:    flagUpstreamIdleConnTimeout,
+			Usage:   "Duration after which idle connections to the upstreams are closed. No limit when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagUpstreamIdleConnTimeout)},
+			Value:   90 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagUpstreamDialTimeout,
+			Usage:   "Maximum duration for establishing a connection to an upstream",
+			EnvVars: []string{strcase.ToSNAKE(flagUpstreamDialTimeout)},
+			Value:   5 * time.Second,
+		},
+		&cli.BoolFlag{
+			Name:    flagUpstreamHTTP2,
+			Usage:   "Enable HTTP/2 on TLS connections to the upstreams",
+			EnvVars: []string{strcase.ToSNAKE(flagUpstreamHTTP2)},
+			Value:   true,
+		},
+	}
+}
+
+// newUpstreamTransport creates the transport, configured through the upstream flags, shared by the clients reaching
+// the upstreams.
+func newUpstreamTransport(cliCtx *cli.Context) (*http.Transport, error) {
+	transport, err := httpclient.NewTransport(httpclient.TransportConfig{
+		MaxIdleConns:        cliCtx.Int(flagUpstreamMaxIdleConns),
+		MaxIdleConnsPerHost: cliCtx.Int(flagUpstreamMaxIdleConnsPerHost),
+		IdleConnTimeout:     cliCtx.Duration(flagUpstreamIdleConnTimeout),
+		DialTimeout:         cliCtx.Duration(flagUpstreamDialTimeout),
+		HTTP2:               cliCtx.Bool(flagUpstreamHTTP2),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create upstream transport: %w", err)
+	}
+
+	return transport, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/manifest"
+	"github.com/urfave/cli/v2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const flagValidateFilename = "filename"
+
+type validateCmd struct {
+	flags []cli.Flag
+}
+
+func newValidateCmd() validateCmd {
+	return validateCmd{
+		flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     flagValidateFilename,
+				Aliases:  []string{"f"},
+				Usage:    "Manifest file, or directory of manifest files, to validate",
+				Required: true,
+			},
+		},
+	}
+}
+
+func (c validateCmd) build() *cli.Command {
+	return &cli.Command{
+		Name:   "validate",
+		Usage:  "Validates AccessControlPolicy and API management manifests offline, as the admission webhooks would",
+		Flags:  c.flags,
+		Action: c.run,
+	}
+}
+
+func (c validateCmd) run(cliCtx *cli.Context) error {
+	objects, err := manifest.Load(cliCtx.StringSlice(flagValidateFilename))
+	if err != nil {
+		return fmt.Errorf("load manifests: %w", err)
+	}
+
+	results, err := manifest.Validate(objects)
+	if err != nil {
+		return fmt.Errorf("validate manifests: %w", err)
+	}
+
+	if invalid := printValidationReport(cliCtx.App.Writer, results); invalid > 0 {
+		return fmt.Errorf("%d invalid resources", invalid)
+	}
+
+	return nil
+}
+
+// printValidationReport prints the outcome of the validation of every resource and returns the number of invalid
+// resources.
+func printValidationReport(w io.Writer, results []manifest.Result) int {
+	var invalid, skipped int
+	for _, result := range results {
+		name := result.Kind.Kind + " " + resourceName(result.Object.Object)
+
+		switch {
+		case !result.Valid():
+			invalid++
+			_, _ = fmt.Fprintf(w, "INVALID  %s (%s)\n", name, result.Source)
+			for _, e := range result.Errors {
+				_, _ = fmt.Fprintf(w, "         - %s\n", e)
+			}
+		case result.Skipped != "":
+			skipped++
+			_, _ = fmt.Fprintf(w, "SKIPPED  %s (%s): %s\n", name, result.Source, result.Skipped)
+		default:
+			_, _ = fmt.Fprintf(w, "VALID    %s (%s)\n", name, result.Source)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\n%d resources validated: %d valid, %d invalid, %d skipped\n",
+		len(results), len(results)-invalid-skipped, invalid, skipped)
+
+	return invalid
+}
+
+func resourceName(obj runtime.Object) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+
+	if accessor.GetNamespace() == "" {
+		return accessor.GetName()
+	}
+
+	return accessor.GetNamespace() + "/" + accessor.GetName()
+}
@@ -19,9 +19,8 @@ package main
 
 import (
 	"context"
-	"errors"
+	"crypto/tls"
 	"fmt"
-	stdlog "log"
 	"net/http"
 	"net/url"
 	"os"
@@ -31,7 +30,6 @@ import (
 	"github.com/ettle/strcase"
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
@@ -39,25 +37,39 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/api"
 	apiadmission "github.com/traefik/hub-agent-kubernetes/pkg/api/admission"
 	apireviewer "github.com/traefik/hub-agent-kubernetes/pkg/api/admission/reviewer"
-	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
-	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	apivalidation "github.com/traefik/hub-agent-kubernetes/pkg/api/admission/validation"
+	apiconversion "github.com/traefik/hub-agent-kubernetes/pkg/api/conversion"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/importer"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/scim"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/drift"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
 	edgeadmission "github.com/traefik/hub-agent-kubernetes/pkg/edgeingress/admission"
+	"github.com/traefik/hub-agent-kubernetes/pkg/keystore"
 	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
-	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
+	"github.com/traefik/hub-agent-kubernetes/pkg/leaderelection"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
 	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/rbac"
+	"github.com/traefik/hub-agent-kubernetes/pkg/replication"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhookcert"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhookconfig"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhooklimit"
 	"github.com/urfave/cli/v2"
+	admv1 "k8s.io/api/admissionregistration/v1"
 	netv1 "k8s.io/api/networking/v1"
+	apiextclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/strings/slices"
 )
@@ -67,12 +79,33 @@ const (
 	flagACPServerCertificate              = "acp-server.cert"
 	flagACPServerKey                      = "acp-server.key"
 	flagACPServerAuthServerAddr           = "acp-server.auth-server-addr"
+	flagACPServerLongLivedReauth          = "acp-server.long-lived-reauth-interval"
+	flagACPServerCertSecret               = "acp-server.cert-secret"
+	flagACPServerServiceName              = "acp-server.service-name"
+	flagACPServerWebhookConfigurations    = "acp-server.webhook-configurations"
+	flagACPServerValidatingWebhooks       = "acp-server.validating-webhook-configurations"
+	flagACPServerConversionCRDs           = "acp-server.conversion-crds"
+	flagACPServerWebhookConfigName        = "acp-server.webhook-configuration-name"
+	flagACPServerFailurePolicy            = "acp-server.failure-policy"
+	flagACPServerNamespaceSelector        = "acp-server.namespace-selector"
+	flagACPServerMaxReviewSize            = "acp-server.max-review-size"
+	flagACPServerReviewTimeout            = "acp-server.review-timeout"
+	flagACPServerReconcileInterval        = "acp-server.reconcile-interval"
+	flagACPServerAuditEvents              = "acp-server.audit-events"
+	flagACPServerDriftInterval            = "acp-server.drift-interval"
+	flagACPServerDriftRepair              = "acp-server.drift-repair"
+	flagACPServerResyncInterval           = "acp-server.resync-interval"
 	flagIngressClassName                  = "ingress-class-name"
 	flagTraefikAPIEntryPoint              = "traefik.api.entryPoint"
 	flagTraefikTunnelEntryPoint           = "traefik.tunnel.entryPoint"
 	flagTraefikTunnelEntryPointDeprecated = "traefik.entryPoint"
 	flagDevPortalServiceName              = "dev-portal.service-name"
 	flagDevPortalPort                     = "dev-portal.port"
+	flagSCIMToken                         = "scim.token"
+	flagAPIImportToken                    = "api-import.token"
+	flagAPIGatewayAPITokenValidation      = "api-gateway.api-token-validation"
+	flagAPIGatewayKeystoreFormats         = "api-gateway.keystore-formats"
+	flagAPIGatewayKeystorePasswordSecret  = "api-gateway.keystore-password-secret"
 )
 
 const apiManagementFeature = "api-management"
@@ -114,12 +147,98 @@ func admissionFlags() []cli.Flag {
 			EnvVars: []string{strcase.ToSNAKE(flagACPServerKey)},
 			Value:   "/var/run/hub-agent-kubernetes/key.pem",
 		},
+		&cli.StringFlag{
+			Name:    flagACPServerCertSecret,
+			Usage:   "Name of the Secret, in the agent namespace, storing the certificate generated for the admission webhooks. When set, this certificate is renewed before expiry and used instead of the certificate and key files",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerCertSecret)},
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerServiceName,
+			Usage:   "Name of the Service exposing the admission webhooks, used to generate their certificate",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerServiceName)},
+			Value:   "admission",
+		},
+		&cli.StringSliceFlag{
+			Name:    flagACPServerWebhookConfigurations,
+			Usage:   "Names of the MutatingWebhookConfigurations in which the CA of the generated certificate is injected",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerWebhookConfigurations)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagACPServerValidatingWebhooks,
+			Usage:   "Names of the ValidatingWebhookConfigurations in which the CA of the generated certificate is injected",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerValidatingWebhooks)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagACPServerConversionCRDs,
+			Usage:   "Names of the CustomResourceDefinitions in whose conversion webhook the CA of the generated certificate is injected",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerConversionCRDs)},
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerWebhookConfigName,
+			Usage:   "Name of the webhook configurations of the agent. When set, the MutatingWebhookConfiguration, and with API management the ValidatingWebhookConfiguration and the conversion webhook of the API management CRDs, are created and continuously reconciled with the agent configuration. The CRDs themselves are installed by the Helm chart",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerWebhookConfigName)},
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerFailurePolicy,
+			Usage:   "Failure policy of the reconciled webhooks (Ignore or Fail)",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerFailurePolicy)},
+			Value:   "Ignore",
+		},
+		&cli.StringFlag{
+			Name:    flagACPServerNamespaceSelector,
+			Usage:   "Label selector restricting the namespaces handled by the reconciled webhooks. All namespaces are handled when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerNamespaceSelector)},
+		},
+		&cli.Int64Flag{
+			Name:    flagACPServerMaxReviewSize,
+			Usage:   "Size, in bytes, above which AdmissionReviews are rejected without being reviewed",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerMaxReviewSize)},
+			Value:   8 << 20,
+		},
+		&cli.DurationFlag{
+			Name:    flagACPServerReviewTimeout,
+			Usage:   "Duration after which reviews are abandoned, allowing the object with a warning when the failure policy is Ignore and denying it otherwise. Must be lower than the 10s webhook timeout",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerReviewTimeout)},
+			Value:   8 * time.Second,
+		},
+		&cli.DurationFlag{
+			Name:    flagACPServerReconcileInterval,
+			Usage:   "Interval at which the Ingresses and IngressRoutes which drifted from their ACP, for instance while the webhook was unavailable, are patched. Disabled when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerReconcileInterval)},
+			Value:   5 * time.Minute,
+		},
+		&cli.BoolFlag{
+			Name:    flagACPServerAuditEvents,
+			Usage:   "Record an Event for each mutation made by the ACP admission webhook and the drift reconciler, holding the annotations before and after the patch",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerAuditEvents)},
+		},
+		&cli.DurationFlag{
+			Name:    flagACPServerDriftInterval,
+			Usage:   "Interval at which the Middlewares, IngressRoutes and TraefikServices generated by the agent are checked for manual edits, recording a Warning Event on the edited ones. Disabled when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerDriftInterval)},
+		},
+		&cli.BoolFlag{
+			Name:    flagACPServerDriftRepair,
+			Usage:   "Restore the spec of the Middlewares, IngressRoutes and TraefikServices edited since they were generated by the agent",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerDriftRepair)},
+		},
+		&cli.DurationFlag{
+			Name:    flagACPServerResyncInterval,
+			Usage:   "Interval at which the informers watching the ACPs, the resources they apply to, and the Edge Ingresses, portals and gateways whose ACME certificates are synced, replay their whole cache. Disabled when 0",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerResyncInterval)},
+			Value:   5 * time.Minute,
+		},
 		&cli.StringFlag{
 			Name:    flagACPServerAuthServerAddr,
 			Usage:   "Address the ACP server can reach the auth server on",
 			EnvVars: []string{strcase.ToSNAKE(flagACPServerAuthServerAddr)},
 			Value:   "http://hub-agent-auth-server.hub.svc.cluster.local",
 		},
+		&cli.DurationFlag{
+			Name:    flagACPServerLongLivedReauth,
+			Usage:   "Value of the long-lived.reauth-interval flag of the auth server. When set, the generated middlewares forward the connection ID the backends need to re-authenticate WebSocket and Server-Sent Events connections",
+			EnvVars: []string{strcase.ToSNAKE(flagACPServerLongLivedReauth)},
+		},
 		&cli.StringFlag{
 			Name:    flagIngressClassName,
 			Usage:   "The ingress class name used for ingresses managed by Hub",
@@ -138,6 +257,31 @@ func admissionFlags() []cli.Flag {
 			EnvVars: []string{strcase.ToSNAKE(flagTraefikTunnelEntryPoint)},
 			Value:   "traefikhub-tunl",
 		},
+		&cli.StringFlag{
+			Name:    flagSCIMToken,
+			Usage:   "Bearer token identity providers must use to push groups on the SCIM endpoint. The endpoint is disabled when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagSCIMToken)},
+		},
+		&cli.StringFlag{
+			Name:    flagAPIImportToken,
+			Usage:   "Bearer token required to import APIs in batch from an OpenAPI index. The endpoint is disabled when empty",
+			EnvVars: []string{strcase.ToSNAKE(flagAPIImportToken)},
+		},
+		&cli.BoolFlag{
+			Name:    flagAPIGatewayAPITokenValidation,
+			Usage:   "Validate, using the auth server, the API tokens of the requests sent to the APIGateways. The auth server must be given a platform token",
+			EnvVars: []string{strcase.ToSNAKE(flagAPIGatewayAPITokenValidation)},
+		},
+		&cli.StringSliceFlag{
+			Name:    flagAPIGatewayKeystoreFormats,
+			Usage:   "Additional keystore formats (pkcs12, jks) written in the APIGateway certificate Secrets, for workloads unable to load PEM certificates",
+			EnvVars: []string{strcase.ToSNAKE(flagAPIGatewayKeystoreFormats)},
+		},
+		&cli.StringFlag{
+			Name:    flagAPIGatewayKeystorePasswordSecret,
+			Usage:   "Name of the Secret, in the agent namespace, holding the keystores password under the \"password\" key",
+			EnvVars: []string{strcase.ToSNAKE(flagAPIGatewayKeystorePasswordSecret)},
+		},
 		&cli.StringFlag{
 			Name:    flagTraefikTunnelEntryPointDeprecated,
 			Usage:   fmt.Sprintf("Deprecated - Please use --%s instead", flagTraefikTunnelEntryPoint),
@@ -147,7 +291,7 @@ func admissionFlags() []cli.Flag {
 	}
 }
 
-func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *platform.Client, cfgWatcher *platform.ConfigWatcher) error {
+func webhookAdmission(ctx context.Context, cliCtx *cli.Context, caps capability.Capabilities, platformClient *platform.Client, cfgWatcher *platform.ConfigWatcher, elector *leaderelection.Elector, statusHandler *status.Handler, informerTracker *status.InformerTracker, loggers *logwrapper.Loggers) error {
 	var (
 		listenAddr     = cliCtx.String(flagACPServerListenAddr)
 		certFile       = cliCtx.String(flagACPServerCertificate)
@@ -165,6 +309,11 @@ func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *
 		return fmt.Errorf("invalid auth server address: %w", err)
 	}
 
+	ruleset, err := loadLintRuleset(cliCtx)
+	if err != nil {
+		return err
+	}
+
 	edgeIngressWatcherCfg := edgeingress.WatcherConfig{
 		IngressClassName:        cliCtx.String(flagIngressClassName),
 		TraefikTunnelEntryPoint: traefikTunnelEntrypoint,
@@ -191,149 +340,324 @@ func webhookAdmission(ctx context.Context, cliCtx *cli.Context, platformClient *
 		AgentNamespace:          currentNamespace(),
 		TraefikAPIEntryPoint:    cliCtx.String(flagTraefikAPIEntryPoint),
 		TraefikTunnelEntryPoint: cliCtx.String(flagTraefikTunnelEntryPoint),
+		KeystoreFormats:         cliCtx.StringSlice(flagAPIGatewayKeystoreFormats),
+		KeystorePasswordSecret:  cliCtx.String(flagAPIGatewayKeystorePasswordSecret),
 		GatewaySyncInterval:     time.Minute,
 		CertSyncInterval:        time.Hour,
 		CertRetryInterval:       time.Minute,
 	}
+	if cliCtx.Bool(flagAPIGatewayAPITokenValidation) {
+		gatewayWatcherCfg.AuthServerAddr = authServerAddr
+	}
+	if err = keystore.ValidateFormats(gatewayWatcherCfg.KeystoreFormats); err != nil {
+		return fmt.Errorf("invalid keystore formats: %w", err)
+	}
 
-	acpAdmission, edgeIngressAdmission, apiAdmission, err := setupAdmissionHandlers(ctx, platformClient, authServerAddr, edgeIngressWatcherCfg, portalWatcherCfg, gatewayWatcherCfg, cfgWatcher)
+	acpAdmission, edgeIngressAdmission, apiAdmission, apiValidation, err := setupAdmissionHandlers(ctx, caps, platformClient, authServerAddr, cliCtx.Duration(flagACPServerLongLivedReauth) > 0, edgeIngressWatcherCfg, portalWatcherCfg, gatewayWatcherCfg, ruleset, cfgWatcher, elector, cliCtx.Duration(flagACPServerReconcileInterval), cliCtx.Bool(flagACPServerAuditEvents), cliCtx.Duration(flagACPServerDriftInterval), cliCtx.Bool(flagACPServerDriftRepair), cliCtx.Duration(flagACPServerResyncInterval), informerTracker, loggers.Component(logwrapper.ComponentACME))
 	if err != nil {
 		return fmt.Errorf("create admission handler: %w", err)
 	}
 
 	webAdmissionACP := admission.NewACPHandler(platformClient)
 
+	reviewLimits, err := newReviewLimits(cliCtx)
+	if err != nil {
+		return err
+	}
+
 	router := chi.NewRouter()
-	router.Handle("/edge-ingress", edgeIngressAdmission)
+	router.Group(func(r chi.Router) {
+		r.Use(webhooklimit.Middleware(reviewLimits))
+
+		r.Handle("/edge-ingress", edgeIngressAdmission)
+		if apiAdmission != nil {
+			r.Handle("/api", apiAdmission)
+			r.Handle("/api-collection", apiAdmission)
+			r.Handle("/api-access", apiAdmission)
+			r.Handle("/api-gateway", apiAdmission)
+			r.Handle("/api-portal", apiAdmission)
+			r.Handle("/api-validation", apiValidation)
+		}
+		r.Handle("/ingress", acpAdmission)
+		r.Handle("/acp", webAdmissionACP)
+	})
 	if apiAdmission != nil {
-		router.Handle("/api", apiAdmission)
-		router.Handle("/api-collection", apiAdmission)
-		router.Handle("/api-access", apiAdmission)
-		router.Handle("/api-gateway", apiAdmission)
-		router.Handle("/api-portal", apiAdmission)
-	}
-	router.Handle("/ingress", acpAdmission)
-	router.Handle("/acp", webAdmissionACP)
-
-	server := &http.Server{
-		Addr:              listenAddr,
-		Handler:           router,
-		ErrorLog:          stdlog.New(log.Logger.Level(zerolog.DebugLevel), "", 0),
-		ReadHeaderTimeout: 2 * time.Second,
-	}
-	srvDone := make(chan struct{})
-
-	go func() {
-		log.Info().Str("addr", listenAddr).Msg("Starting admission server")
-		if err = server.ListenAndServeTLS(certFile, keyFile); !errors.Is(err, http.ErrServerClosed) {
-			log.Err(err).Msg("Unable to listen and serve admission requests")
+		router.Handle("/conversion", apiconversion.NewHandler())
+
+		if scimToken := cliCtx.String(flagSCIMToken); scimToken != "" {
+			router.Mount("/scim/v2", scim.NewHandler(platformClient, scimToken))
 		}
-		close(srvDone)
-	}()
-
-	select {
-	case <-ctx.Done():
-		gracefulCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-
-		if err = server.Shutdown(gracefulCtx); err != nil {
-			log.Error().Err(err).Msg("Failed to shutdown admission server gracefully")
-			if err = server.Close(); err != nil {
-				return fmt.Errorf("close admission server: %w", err)
+
+		if importToken := cliCtx.String(flagAPIImportToken); importToken != "" {
+			importHandler, errImport := newAPIImportHandler(importToken)
+			if errImport != nil {
+				return fmt.Errorf("create API import handler: %w", errImport)
 			}
+			router.Handle("/api-import", importHandler)
 		}
-		log.Info().Msg("Successfully shutdown admission server")
-	case <-srvDone:
-		return errors.New("admission server stopped")
 	}
 
-	return nil
-}
+	server, err := newServer(cliCtx, listenAddr, router)
+	if err != nil {
+		return fmt.Errorf("create server: %w", err)
+	}
+
+	var (
+		certSecret        = cliCtx.String(flagACPServerCertSecret)
+		webhookConfigName = cliCtx.String(flagACPServerWebhookConfigName)
+	)
 
-func setupAdmissionHandlers(ctx context.Context, platformClient *platform.Client, authServerAddr string, edgeIngressWatcherCfg edgeingress.WatcherConfig, portalWatcherCfg *api.WatcherPortalConfig, gatewayWatcherCfg *api.WatcherGatewayConfig, cfgWatcher *platform.ConfigWatcher) (acpHandler, edgeIngressHandler, apiHandler http.Handler, err error) {
 	config, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+		return fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
 	}
 
 	kubeClientSet, err := clientset.NewForConfig(config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Kubernetes client set: %w", err)
+		return fmt.Errorf("create Kubernetes client set: %w", err)
 	}
 
-	if err = initIngressClass(ctx, kubeClientSet, edgeIngressWatcherCfg.IngressClassName); err != nil {
-		return nil, nil, nil, fmt.Errorf("initialize ingressClass: %w", err)
+	extClientSet, err := apiextclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("create apiextensions client set: %w", err)
+	}
+
+	rbacChecker := rbac.NewChecker(kubeClientSet, controllerRBACRules(cliCtx, apiAdmission != nil, caps.OpenShiftRoutes))
+	router.Handle("/rbac", rbacChecker)
+	router.Handle("/log-levels", loggers.LevelHandler())
+	router.Handle("/status", statusHandler)
+	go rbacChecker.Run(ctx, 10*time.Minute)
+
+	var certManager *webhookcert.Manager
+	if certSecret != "" {
+		certManager = webhookcert.NewManager(kubeClientSet, extClientSet, webhookcert.Config{
+			Namespace:                       currentNamespace(),
+			SecretName:                      certSecret,
+			ServiceName:                     cliCtx.String(flagACPServerServiceName),
+			MutatingWebhookConfigurations:   cliCtx.StringSlice(flagACPServerWebhookConfigurations),
+			ValidatingWebhookConfigurations: cliCtx.StringSlice(flagACPServerValidatingWebhooks),
+			CustomResourceDefinitions:       cliCtx.StringSlice(flagACPServerConversionCRDs),
+		})
+		if err = certManager.Sync(ctx); err != nil {
+			return fmt.Errorf("sync webhook certificate: %w", err)
+		}
+		go certManager.Run(ctx)
 	}
 
-	hubClientSet, err := hubclientset.NewForConfig(config)
+	if webhookConfigName != "" {
+		reconciler, errReconciler := newWebhookConfigReconciler(cliCtx, kubeClientSet, extClientSet, certManager, apiAdmission != nil, caps.OpenShiftRoutes)
+		if errReconciler != nil {
+			return errReconciler
+		}
+
+		elector.Go(ctx, reconciler.Run)
+	}
+
+	if certManager == nil {
+		statusHandler.Register("webhookCertificate", status.CertificateCheck(func() (*tls.Certificate, error) {
+			cert, errCert := tls.LoadX509KeyPair(certFile, keyFile)
+			if errCert != nil {
+				return nil, fmt.Errorf("load certificate: %w", errCert)
+			}
+
+			return &cert, nil
+		}))
+
+		return serve(ctx, "admission server", server, func() error {
+			return server.ListenAndServeTLS(certFile, keyFile)
+		}, nil)
+	}
+
+	statusHandler.Register("webhookCertificate", status.CertificateCheck(func() (*tls.Certificate, error) {
+		return certManager.GetCertificate(nil)
+	}))
+
+	server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certManager.GetCertificate,
+	}
+
+	return serve(ctx, "admission server", server, listenAndServe(server), nil)
+}
+
+func newWebhookConfigReconciler(cliCtx *cli.Context, kubeClientSet clientset.Interface, extClientSet apiextclientset.Interface, certManager *webhookcert.Manager, apiManagement, openShiftRoutes bool) (*webhookconfig.Reconciler, error) {
+	config := webhookconfig.Config{
+		Name:             cliCtx.String(flagACPServerWebhookConfigName),
+		ServiceName:      cliCtx.String(flagACPServerServiceName),
+		ServiceNamespace: currentNamespace(),
+		FailurePolicy:    admv1.FailurePolicyType(cliCtx.String(flagACPServerFailurePolicy)),
+		APIManagement:    apiManagement,
+		OpenShiftRoutes:  openShiftRoutes,
+		SyncInterval:     time.Minute,
+	}
+
+	if selector := cliCtx.String(flagACPServerNamespaceSelector); selector != "" {
+		namespaceSelector, err := metav1.ParseToLabelSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("parse namespace selector: %w", err)
+		}
+		config.NamespaceSelector = namespaceSelector
+	}
+
+	if certManager != nil {
+		config.CABundle = certManager.CABundle
+	}
+
+	reconciler, err := webhookconfig.NewReconciler(kubeClientSet, extClientSet, config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Hub client set: %w", err)
+		return nil, fmt.Errorf("create webhook configuration reconciler: %w", err)
+	}
+
+	return reconciler, nil
+}
+
+func newReviewLimits(cliCtx *cli.Context) (webhooklimit.Config, error) {
+	maxReviewSize := cliCtx.Int64(flagACPServerMaxReviewSize)
+	if maxReviewSize <= 0 {
+		return webhooklimit.Config{}, fmt.Errorf("invalid max review size %d: must be positive", maxReviewSize)
 	}
-	traefikClientSet, err := createTraefikClientSet(kubeClientSet, config)
+
+	reviewTimeout := cliCtx.Duration(flagACPServerReviewTimeout)
+	if reviewTimeout <= 0 {
+		return webhooklimit.Config{}, fmt.Errorf("invalid review timeout %s: must be positive", reviewTimeout)
+	}
+
+	return webhooklimit.Config{
+		MaxBodySize:    maxReviewSize,
+		Timeout:        reviewTimeout,
+		AllowOnTimeout: admv1.FailurePolicyType(cliCtx.String(flagACPServerFailurePolicy)) == admv1.Ignore,
+	}, nil
+}
+
+func newAPIImportHandler(token string) (*importer.Handler, error) {
+	config, err := kube.InClusterConfigWithRetrier(2)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create Traefik client set: %w", err)
+		return nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
 	}
 
-	kubeVers, err := kubeClientSet.Discovery().ServerVersion()
+	hubClientSet, err := hubclientset.NewForConfig(config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("detect Kubernetes version: %w", err)
+		return nil, fmt.Errorf("create Hub client set: %w", err)
 	}
 
-	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 5*time.Minute)
-	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 5*time.Minute)
+	return importer.NewHandler(hubClientSet, token), nil
+}
+
+func setupAdmissionHandlers(ctx context.Context, caps capability.Capabilities, platformClient *platform.Client, authServerAddr string, longLived bool, edgeIngressWatcherCfg edgeingress.WatcherConfig, portalWatcherCfg *api.WatcherPortalConfig, gatewayWatcherCfg *api.WatcherGatewayConfig, ruleset lint.Ruleset, cfgWatcher *platform.ConfigWatcher, elector *leaderelection.Elector, reconcileInterval time.Duration, auditEvents bool, driftInterval time.Duration, driftRepair bool, resync time.Duration, informerTracker *status.InformerTracker, acmeLogger zerolog.Logger) (acpHandler, edgeIngressHandler, apiHandler, apiValidationHandler http.Handler, err error) {
+	config, err := kube.InClusterConfigWithRetrier(2)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create Kubernetes in-cluster configuration: %w", err)
+	}
 
-	ingressUpdater := admission.NewIngressUpdater(kubeInformer, kubeClientSet, kubeVers.GitVersion)
+	kubeClientSet, err := clientset.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create Kubernetes client set: %w", err)
+	}
 
-	acpEventHandler := admission.NewEventHandler(ingressUpdater)
-	ingClassWatcher := ingclass.NewWatcher()
+	if err = initIngressClass(ctx, kubeClientSet, edgeIngressWatcherCfg.IngressClassName); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("initialize ingressClass: %w", err)
+	}
 
-	err = startKubeInformer(ctx, kubeVers.GitVersion, kubeInformer, ingClassWatcher)
+	hubClientSet, err := hubclientset.NewForConfig(config)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("create Hub client set: %w", err)
+	}
+	var traefikClientSet v1alpha1.TraefikV1alpha1Interface
+	if caps.TraefikMiddlewares {
+		traefikClients, errClientSet := traefikclientset.NewForConfig(config)
+		if errClientSet != nil {
+			return nil, nil, nil, nil, fmt.Errorf("create Traefik client set: %w", errClientSet)
+		}
+		traefikClientSet = traefikClients.TraefikV1alpha1()
+	}
+
+	// OpenShift Routes are updated as unstructured objects as the agent doesn't depend on the OpenShift client.
+	var routeClientSet dynamic.Interface
+	if caps.OpenShiftRoutes {
+		routeClientSet, err = dynamic.NewForConfig(config)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("create Kubernetes dynamic client set: %w", err)
+		}
 	}
 
-	isAPIManagementCRDsAvailable, err := hasAPIManagementCRDs(kubeClientSet)
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, resync)
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, resync)
+
+	ingressUpdater := admission.NewIngressUpdater(kubeInformer, kubeClientSet, routeClientSet, caps.NetV1Ingresses)
+	serviceUpdater := admission.NewServiceUpdater(kubeInformer, kubeClientSet, traefikClientSet, caps.NetV1Ingresses)
+
+	acpEventHandler := admission.NewEventHandler(ingressUpdater)
+	ingClassWatcher := ingclass.NewWatcher()
+
+	err = startKubeInformer(ctx, caps, kubeInformer, informerTracker, ingClassWatcher, serviceUpdater)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("API available: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
 	}
 
-	err = startHubInformer(ctx, hubInformer, ingClassWatcher, acpEventHandler, isAPIManagementCRDsAvailable)
+	err = startHubInformer(ctx, hubInformer, informerTracker, ingClassWatcher, acpEventHandler, caps.APIManagement)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("start kube informer: %w", err)
 	}
 
 	acpWatcher := acp.NewWatcher(time.Minute, platformClient, hubClientSet, hubInformer)
 
-	edgeIngressWatcher, err := edgeingress.NewWatcher(platformClient, hubClientSet, kubeClientSet, traefikClientSet, hubInformer, edgeIngressWatcherCfg)
+	edgeIngressWatcher, err := edgeingress.NewWatcher(platformClient, hubClientSet, kubeClientSet, traefikClientSet, hubInformer, edgeIngressWatcherCfg, acmeLogger)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("create edge ingress watcher: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("create edge ingress watcher: %w", err)
 	}
 
-	go acpWatcher.Run(ctx)
+	certReplicationWatcher := replication.NewWatcher(time.Minute, kubeClientSet, kubeInformer, traefikClientSet)
+
+	// The ingress updater runs on every replica as the ACP event handler blocks until it consumes the events.
 	go ingressUpdater.Run(ctx)
-	go edgeIngressWatcher.Run(ctx)
+	// The service updater runs on every replica as the Service event handler blocks until it consumes the events.
+	go serviceUpdater.Run(ctx)
+
+	elector.Go(ctx, acpWatcher.Run)
+	elector.Go(ctx, edgeIngressWatcher.Run)
+	elector.Go(ctx, certReplicationWatcher.Run)
 
-	if isAPIManagementCRDsAvailable {
+	if driftInterval > 0 && caps.TraefikMiddlewares {
+		instance, err := os.Hostname()
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("get hostname: %w", err)
+		}
+
+		driftWatcher := drift.NewWatcher(driftInterval, driftRepair, caps.TraefikRoutes, traefikClientSet, kubeClientSet.EventsV1(), instance)
+		elector.Go(ctx, driftWatcher.Run)
+	}
+
+	if caps.APIManagement {
 		if err = setupAPIManagementWatcher(ctx,
 			platformClient, kubeClientSet, hubClientSet,
 			traefikClientSet, kubeInformer, hubInformer,
-			portalWatcherCfg, gatewayWatcherCfg, cfgWatcher); err != nil {
-			return nil, nil, nil, fmt.Errorf("setup API management watcher: %w", err)
+			portalWatcherCfg, gatewayWatcherCfg, ruleset, cfgWatcher, elector, acmeLogger); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("setup API management watcher: %w", err)
 		}
 	}
 
 	polGetter := reviewer.NewPolGetter(hubInformer)
 
-	fwdAuthMdlwrs := reviewer.NewFwdAuthMiddlewares(authServerAddr, polGetter, traefikClientSet)
+	fwdAuthMdlwrs := reviewer.NewFwdAuthMiddlewares(authServerAddr, longLived, polGetter, traefikClientSet)
 
 	traefikReviewer := reviewer.NewTraefikIngress(ingClassWatcher, fwdAuthMdlwrs)
-	reviewers := []admission.Reviewer{
-		reviewer.NewNginxIngress(authServerAddr, ingClassWatcher, polGetter),
-		reviewer.NewTraefikIngressRoute(fwdAuthMdlwrs),
-		traefikReviewer,
+	reviewers := admission.NewRegistry()
+	if err = reviewers.Register("nginx-ingress", reviewer.NewNginxIngress(authServerAddr, longLived, ingClassWatcher, polGetter), 0); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("register NGINX Ingress reviewer: %w", err)
+	}
+	if err = reviewers.Register("traefik-ingress-route", reviewer.NewTraefikIngressRoute(fwdAuthMdlwrs), 0); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("register Traefik IngressRoute reviewer: %w", err)
+	}
+	if err = reviewers.Register("traefik-ingress", traefikReviewer, 0); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("register Traefik Ingress reviewer: %w", err)
+	}
+	if caps.OpenShiftRoutes {
+		if err = reviewers.Register("openshift-route", reviewer.NewOpenShiftRoute(authServerAddr, longLived, polGetter), 0); err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("register OpenShift Route reviewer: %w", err)
+		}
 	}
 
-	if isAPIManagementCRDsAvailable {
+	if caps.APIManagement {
 		rev := []apiadmission.Reviewer{
 			apireviewer.NewAPI(platformClient),
 			apireviewer.NewCollection(platformClient),
@@ -342,21 +666,51 @@ func setupAdmissionHandlers(ctx context.Context, platformClient *platform.Client
 			apireviewer.NewGateway(platformClient),
 		}
 		apiHandler = apiadmission.NewHandler(rev)
+		apiValidationHandler = apivalidation.NewHandler(kubeInformer, hubInformer)
 	}
 
-	return admission.NewHandler(reviewers, traefikReviewer), edgeadmission.NewHandler(platformClient), apiHandler, nil
+	servicePolicies := admission.NewServicePolicies(kubeInformer.Core().V1().Services().Lister())
+	var auditor *admission.Auditor
+	if auditEvents {
+		instance, err := os.Hostname()
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("get hostname: %w", err)
+		}
+
+		auditor = admission.NewAuditor(kubeClientSet.EventsV1(), instance)
+	}
+
+	handler := admission.NewHandler(reviewers, traefikReviewer, servicePolicies, auditor)
+
+	if reconcileInterval > 0 {
+		reconciler := admission.NewReconciler(handler, kubeInformer, kubeClientSet, traefikClientSet, caps.NetV1Ingresses, reconcileInterval)
+		elector.Go(ctx, reconciler.Run)
+	}
+
+	return handler, edgeadmission.NewHandler(platformClient), apiHandler, apiValidationHandler, nil
 }
 
 func setupAPIManagementWatcher(ctx context.Context, platformClient *platform.Client,
 	kubeClientSet *clientset.Clientset, hubClientSet *hubclientset.Clientset, traefikClientSet v1alpha1.TraefikV1alpha1Interface,
 	kubeInformer informers.SharedInformerFactory, hubInformer hubinformer.SharedInformerFactory,
-	portalWatcherCfg *api.WatcherPortalConfig, gatewayWatcherCfg *api.WatcherGatewayConfig, cfgWatcher *platform.ConfigWatcher,
+	portalWatcherCfg *api.WatcherPortalConfig, gatewayWatcherCfg *api.WatcherGatewayConfig, ruleset lint.Ruleset, cfgWatcher *platform.ConfigWatcher,
+	elector *leaderelection.Elector, acmeLogger zerolog.Logger,
 ) error {
-	portalWatcher := api.NewWatcherPortal(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, portalWatcherCfg)
-	gatewayWatcher := api.NewWatcherGateway(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet, gatewayWatcherCfg)
-	apiWatcher := api.NewWatcherAPI(platformClient, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	portalWatcher := api.NewWatcherPortal(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, portalWatcherCfg, acmeLogger)
+	// API gateways are exposed through the Middlewares, TraefikServices and IngressRoutes they generate.
+	var gatewayWatcher *api.WatcherGateway
+	if traefikClientSet != nil {
+		var err error
+		gatewayWatcher, err = api.NewWatcherGateway(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet, gatewayWatcherCfg, acmeLogger)
+		if err != nil {
+			return fmt.Errorf("create gateway watcher: %w", err)
+		}
+	}
+	apiWatcher := api.NewWatcherAPI(platformClient, kubeClientSet, kubeInformer, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval, ruleset)
 	collectionWatcher := api.NewWatcherCollection(platformClient, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
 	accessWatcher := api.NewWatcherAccess(platformClient, hubClientSet, hubInformer, portalWatcherCfg.PortalSyncInterval)
+	snapshotWatcher := api.NewWatcherSnapshot(kubeClientSet, hubInformer, portalWatcherCfg.AgentNamespace, portalWatcherCfg.PortalSyncInterval)
+	discoveryWatcher := api.NewWatcherDiscovery(kubeInformer, hubClientSet, hubInformer, traefikClientSet, portalWatcherCfg.PortalSyncInterval)
 
 	var cancel func()
 	var watcherStarted bool
@@ -364,11 +718,15 @@ func setupAPIManagementWatcher(ctx context.Context, platformClient *platform.Cli
 		var apiCtx context.Context
 		apiCtx, cancel = context.WithCancel(ctx)
 
-		go portalWatcher.Run(apiCtx)
-		go gatewayWatcher.Run(apiCtx)
-		go apiWatcher.Run(apiCtx)
-		go collectionWatcher.Run(apiCtx)
-		go accessWatcher.Run(apiCtx)
+		elector.Go(apiCtx, portalWatcher.Run)
+		if gatewayWatcher != nil {
+			elector.Go(apiCtx, gatewayWatcher.Run)
+		}
+		elector.Go(apiCtx, apiWatcher.Run)
+		elector.Go(apiCtx, collectionWatcher.Run)
+		elector.Go(apiCtx, accessWatcher.Run)
+		elector.Go(apiCtx, snapshotWatcher.Run)
+		elector.Go(apiCtx, discoveryWatcher.Run)
 
 		watcherStarted = true
 	}
@@ -400,25 +758,7 @@ func setupAPIManagementWatcher(ctx context.Context, platformClient *platform.Cli
 	return nil
 }
 
-func createTraefikClientSet(clientSet *clientset.Clientset, config *rest.Config) (v1alpha1.TraefikV1alpha1Interface, error) {
-	crd, err := hasMiddlewareCRD(clientSet.Discovery())
-	if err != nil {
-		return nil, fmt.Errorf("check presence of Traefik Middleware CRD: %w", err)
-	}
-
-	if !crd {
-		return nil, nil
-	}
-
-	traefikClientSet, errClientSet := traefikclientset.NewForConfig(config)
-	if errClientSet != nil {
-		return nil, fmt.Errorf("create Traefik client set: %w", errClientSet)
-	}
-
-	return traefikClientSet.TraefikV1alpha1(), nil
-}
-
-func startHubInformer(ctx context.Context, hubInformer hubinformer.SharedInformerFactory, ingClassWatcher, acpEventHandler cache.ResourceEventHandler, apiAvailable bool) error {
+func startHubInformer(ctx context.Context, hubInformer hubinformer.SharedInformerFactory, informerTracker *status.InformerTracker, ingClassWatcher, acpEventHandler cache.ResourceEventHandler, apiAvailable bool) error {
 	if _, err := hubInformer.Hub().V1alpha1().IngressClasses().Informer().AddEventHandler(ingClassWatcher); err != nil {
 		return fmt.Errorf("add ingressClass event handler: %w", err)
 	}
@@ -427,14 +767,28 @@ func startHubInformer(ctx context.Context, hubInformer hubinformer.SharedInforme
 		return fmt.Errorf("add accessControlPolicy event handler: %w", err)
 	}
 
-	hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+	cached := []cache.SharedIndexInformer{
+		hubInformer.Hub().V1alpha1().IngressClasses().Informer(),
+		hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer(),
+		hubInformer.Hub().V1alpha1().EdgeIngresses().Informer(),
+	}
 
 	if apiAvailable {
-		hubInformer.Hub().V1alpha1().APIAccesses().Informer()
-		hubInformer.Hub().V1alpha1().APIPortals().Informer()
-		hubInformer.Hub().V1alpha1().APIGateways().Informer()
-		hubInformer.Hub().V1alpha1().APICollections().Informer()
-		hubInformer.Hub().V1alpha1().APIs().Informer()
+		cached = append(cached,
+			hubInformer.Hub().V1alpha1().APIAccesses().Informer(),
+			hubInformer.Hub().V1alpha1().APIPortals().Informer(),
+			hubInformer.Hub().V1alpha1().APIGateways().Informer(),
+			hubInformer.Hub().V1alpha1().APICollections().Informer(),
+			hubInformer.Hub().V1alpha1().APIs().Informer(),
+		)
+	}
+
+	if err := kube.SetTransform(kube.StripObject, cached...); err != nil {
+		return fmt.Errorf("hub informers: %w", err)
+	}
+
+	if err := informerTracker.Track(cached...); err != nil {
+		return fmt.Errorf("track hub informers: %w", err)
 	}
 
 	hubInformer.Start(ctx.Done())
@@ -448,22 +802,43 @@ func startHubInformer(ctx context.Context, hubInformer hubinformer.SharedInforme
 	return nil
 }
 
-func startKubeInformer(ctx context.Context, kubeVers string, kubeInformer informers.SharedInformerFactory, ingClassEventHandler cache.ResourceEventHandler) error {
-	if kubevers.SupportsNetV1IngressClasses(kubeVers) {
-		if _, err := kubeInformer.Networking().V1().IngressClasses().Informer().AddEventHandler(ingClassEventHandler); err != nil {
+func startKubeInformer(ctx context.Context, caps capability.Capabilities, kubeInformer informers.SharedInformerFactory, informerTracker *status.InformerTracker, ingClassEventHandler, serviceEventHandler cache.ResourceEventHandler) error {
+	var cached []cache.SharedIndexInformer
+
+	if caps.NetV1IngressClasses {
+		informer := kubeInformer.Networking().V1().IngressClasses().Informer()
+		if _, err := informer.AddEventHandler(ingClassEventHandler); err != nil {
 			return fmt.Errorf("add v1 IngressClass event handler: %w", err)
 		}
-	} else if kubevers.SupportsNetV1Beta1IngressClasses(kubeVers) {
-		if _, err := kubeInformer.Networking().V1beta1().IngressClasses().Informer().AddEventHandler(ingClassEventHandler); err != nil {
+		cached = append(cached, informer)
+	} else if caps.NetV1Beta1IngressClasses {
+		informer := kubeInformer.Networking().V1beta1().IngressClasses().Informer()
+		if _, err := informer.AddEventHandler(ingClassEventHandler); err != nil {
 			return fmt.Errorf("add v1beta1 IngressClass event handler: %w", err)
 		}
+		cached = append(cached, informer)
 	}
 
-	if kubevers.SupportsNetV1Ingresses(kubeVers) {
-		kubeInformer.Networking().V1().Ingresses().Informer()
+	if caps.NetV1Ingresses {
+		cached = append(cached, kubeInformer.Networking().V1().Ingresses().Informer())
 	} else {
 		// Since we only support Kubernetes v1.14 and up, we should always at least have net v1beta1 Ingresses.
-		kubeInformer.Networking().V1beta1().Ingresses().Informer()
+		cached = append(cached, kubeInformer.Networking().V1beta1().Ingresses().Informer())
+	}
+
+	// Services are required to apply their ACP to the resources targeting them and to validate the services referenced
+	// by APIs.
+	if _, err := kubeInformer.Core().V1().Services().Informer().AddEventHandler(serviceEventHandler); err != nil {
+		return fmt.Errorf("add Service event handler: %w", err)
+	}
+	cached = append(cached, kubeInformer.Core().V1().Services().Informer())
+
+	if err := kube.SetTransform(kube.StripObject, cached...); err != nil {
+		return fmt.Errorf("kubernetes informers: %w", err)
+	}
+
+	if err := informerTracker.Track(cached...); err != nil {
+		return fmt.Errorf("track kubernetes informers: %w", err)
 	}
 
 	kubeInformer.Start(ctx.Done())
@@ -508,44 +883,3 @@ func currentNamespace() string {
 
 	return "default"
 }
-
-func hasMiddlewareCRD(clientSet discovery.DiscoveryInterface) (bool, error) {
-	crdList, err := clientSet.ServerResourcesForGroupVersion(traefikv1alpha1.SchemeGroupVersion.String())
-	if err != nil {
-		if kerror.IsNotFound(err) ||
-			// Because the fake client doesn't return the right error type.
-			strings.HasSuffix(err.Error(), " not found") {
-			return false, nil
-		}
-		return false, err
-	}
-
-	for _, resource := range crdList.APIResources {
-		if resource.Kind == "Middleware" {
-			return true, nil
-		}
-	}
-
-	return true, nil
-}
-
-func hasAPIManagementCRDs(clientSet discovery.DiscoveryInterface) (bool, error) {
-	crdList, err := clientSet.ServerResourcesForGroupVersion(hubv1alpha1.SchemeGroupVersion.String())
-	if err != nil {
-		if kerror.IsNotFound(err) {
-			return false, nil
-		}
-
-		return false, err
-	}
-
-	log.Info().Interface("crds", crdList.APIResources).Msg("crds list")
-
-	for _, resource := range crdList.APIResources {
-		if resource.Kind == "APIPortal" {
-			return true, nil
-		}
-	}
-
-	return false, nil
-}
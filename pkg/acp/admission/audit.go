@@ -0,0 +1,231 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	admv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	eventsv1client "k8s.io/client-go/kubernetes/typed/events/v1"
+)
+
+// Annotations of the audit Events, holding the changes made to the audited resource.
+const (
+	AnnotationAuditBefore = "hub.traefik.io/audit-annotations-before"
+	AnnotationAuditAfter  = "hub.traefik.io/audit-annotations-after"
+	AnnotationAuditPatch  = "hub.traefik.io/audit-patch"
+	AnnotationAuditUser   = "hub.traefik.io/audit-user"
+)
+
+// Reasons of the audit Events.
+const (
+	AuditReasonAdmission = "ACPAdmissionMutation"
+	AuditReasonDrift     = "ACPDriftReconciliation"
+)
+
+const auditReportingController = "hub.traefik.io/hub-agent-kubernetes"
+
+// auditTimeout is the maximum duration of the creation of an audit Event.
+const auditTimeout = 5 * time.Second
+
+// Auditor records an audit trail of the mutations made to resources by the admission Handler and the Reconciler, as
+// Events referring to the mutated resource and to its ACP. The Events are retained as long as the API server retains
+// Events, which is configured with its --event-ttl option.
+type Auditor struct {
+	events   eventsv1client.EventsGetter
+	instance string
+	now      func() time.Time
+}
+
+// NewAuditor returns an Auditor creating Events with the given client. The instance identifies the agent replica
+// reporting the Events.
+func NewAuditor(events eventsv1client.EventsGetter, instance string) *Auditor {
+	return &Auditor{
+		events:   events,
+		instance: instance,
+		now:      time.Now,
+	}
+}
+
+// Record records the mutation of the resource under review by the given JSON patch. Failures are logged as the audit
+// must not prevent resources from being mutated.
+func (a *Auditor) Record(ctx context.Context, reason string, req *admv1.AdmissionRequest, patch []byte) {
+	logger := log.Ctx(ctx)
+
+	event, err := a.newEvent(reason, req, patch)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to build admission audit event")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+
+	if _, err = a.events.Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		logger.Error().Err(err).Msg("Unable to create admission audit event")
+	}
+}
+
+func (a *Auditor) newEvent(reason string, req *admv1.AdmissionRequest, patch []byte) (*eventsv1.Event, error) {
+	before, err := annotations(req.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("get annotations before mutation: %w", err)
+	}
+
+	decodedPatch, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, fmt.Errorf("decode patch: %w", err)
+	}
+	patched, err := decodedPatch.Apply(req.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("apply patch: %w", err)
+	}
+	after, err := annotations(patched)
+	if err != nil {
+		return nil, fmt.Errorf("get annotations after mutation: %w", err)
+	}
+
+	changedBefore, changedAfter := changedAnnotations(before, after)
+	beforeJSON, err := json.Marshal(changedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("marshal annotations before mutation: %w", err)
+	}
+	afterJSON, err := json.Marshal(changedAfter)
+	if err != nil {
+		return nil, fmt.Errorf("marshal annotations after mutation: %w", err)
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	now := a.now()
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", req.Name, now.UnixNano()),
+			Namespace: namespace,
+			Annotations: map[string]string{
+				AnnotationAuditBefore: string(beforeJSON),
+				AnnotationAuditAfter:  string(afterJSON),
+				AnnotationAuditPatch:  string(patch),
+				AnnotationAuditUser:   req.UserInfo.Username,
+			},
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: auditReportingController,
+		ReportingInstance:   a.instance,
+		Action:              string(req.Operation),
+		Reason:              reason,
+		Type:                corev1.EventTypeNormal,
+		Regarding: corev1.ObjectReference{
+			APIVersion: metav1.GroupVersion{Group: req.Kind.Group, Version: req.Kind.Version}.String(),
+			Kind:       req.Kind.Kind,
+			Namespace:  req.Namespace,
+			Name:       req.Name,
+			UID:        req.UID,
+		},
+		Note: auditNote(changedBefore, changedAfter),
+	}
+
+	polName := reviewer.PolicyName(after)
+	if polName == "" {
+		polName = reviewer.PolicyName(before)
+	}
+	if polName != "" {
+		event.Related = &corev1.ObjectReference{
+			APIVersion: hubv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "AccessControlPolicy",
+			Name:       polName,
+		}
+	}
+
+	return event, nil
+}
+
+func annotations(raw []byte) (map[string]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var obj struct {
+		Metadata metav1.ObjectMeta `json:"metadata"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+
+	return obj.Metadata.Annotations, nil
+}
+
+// changedAnnotations returns the annotations which differ between before and after, with their values before and
+// after.
+func changedAnnotations(before, after map[string]string) (changedBefore, changedAfter map[string]string) {
+	changedBefore, changedAfter = make(map[string]string), make(map[string]string)
+	for key, value := range before {
+		if afterValue, ok := after[key]; !ok || afterValue != value {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range after {
+		if beforeValue, ok := before[key]; !ok || beforeValue != value {
+			changedAfter[key] = value
+		}
+	}
+
+	return changedBefore, changedAfter
+}
+
+// auditNote returns a human-readable summary of the changed annotations, within the 1kB limit of Event notes.
+func auditNote(before, after map[string]string) string {
+	keys := make(map[string]struct{})
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+	if len(keys) == 0 {
+		return "Resource mutated without annotation changes"
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	note := "Changed annotations: " + strings.Join(sorted, ", ")
+	if len(note) > 1024 {
+		note = note[:1021] + "..."
+	}
+
+	return note
+}
@@ -0,0 +1,97 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admission/v1"
+	authnv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAuditor_Record(t *testing.T) {
+	kubeClientSet := kubemock.NewSimpleClientset()
+
+	now := time.Date(2023, 5, 2, 10, 0, 0, 0, time.UTC)
+	auditor := NewAuditor(kubeClientSet.EventsV1(), "agent-0")
+	auditor.now = func() time.Time { return now }
+
+	req := &admv1.AdmissionRequest{
+		UID:       "uid",
+		Kind:      metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+		Name:      "whoami",
+		Namespace: "ns",
+		Operation: admv1.Update,
+		UserInfo:  authnv1.UserInfo{Username: "jane"},
+		Object: runtime.RawExtension{
+			Raw: []byte(`{"metadata":{"name":"whoami","annotations":{"hub.traefik.io/access-control-policy":"jwt","kept":"value","removed":"value"}}}`),
+		},
+	}
+	patch := []byte(`[` +
+		`{"op":"add","path":"/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares","value":"hub-jwt@kubernetescrd"},` +
+		`{"op":"remove","path":"/metadata/annotations/removed"}` +
+		`]`)
+
+	auditor.Record(context.Background(), AuditReasonAdmission, req, patch)
+
+	events, err := kubeClientSet.EventsV1().Events("ns").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+
+	want := eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "whoami.175b4b3a694f4000",
+			Namespace: "ns",
+			Annotations: map[string]string{
+				AnnotationAuditBefore: `{"removed":"value"}`,
+				AnnotationAuditAfter:  `{"traefik.ingress.kubernetes.io/router.middlewares":"hub-jwt@kubernetescrd"}`,
+				AnnotationAuditPatch:  string(patch),
+				AnnotationAuditUser:   "jane",
+			},
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: "hub.traefik.io/hub-agent-kubernetes",
+		ReportingInstance:   "agent-0",
+		Action:              "UPDATE",
+		Reason:              AuditReasonAdmission,
+		Type:                corev1.EventTypeNormal,
+		Regarding: corev1.ObjectReference{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "Ingress",
+			Namespace:  "ns",
+			Name:       "whoami",
+			UID:        "uid",
+		},
+		Related: &corev1.ObjectReference{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "AccessControlPolicy",
+			Name:       "jwt",
+		},
+		Note: "Changed annotations: removed, traefik.ingress.kubernetes.io/router.middlewares",
+	}
+	assert.Equal(t, want, events.Items[0])
+}
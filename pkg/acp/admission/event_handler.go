@@ -19,6 +19,7 @@ package admission
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 
 	"github.com/rs/zerolog/log"
@@ -98,6 +99,10 @@ func (w *EventHandler) OnDelete(obj interface{}) {
 }
 
 func headersChanged(oldCfg, newCfg hubv1alpha1.AccessControlPolicySpec) bool {
+	if !reflect.DeepEqual(oldCfg.ForwardAuth, newCfg.ForwardAuth) {
+		return true
+	}
+
 	switch {
 	case newCfg.JWT != nil:
 		if oldCfg.JWT == nil {
@@ -105,7 +110,8 @@ func headersChanged(oldCfg, newCfg hubv1alpha1.AccessControlPolicySpec) bool {
 		}
 
 		return !reflect.DeepEqual(oldCfg.JWT.ForwardHeaders, newCfg.JWT.ForwardHeaders) ||
-			oldCfg.JWT.StripAuthorizationHeader != newCfg.JWT.StripAuthorizationHeader
+			oldCfg.JWT.StripAuthorizationHeader != newCfg.JWT.StripAuthorizationHeader ||
+			internalTokenHeader(oldCfg.JWT) != internalTokenHeader(newCfg.JWT)
 
 	case newCfg.BasicAuth != nil:
 		if oldCfg.BasicAuth == nil {
@@ -143,7 +149,26 @@ func headersChanged(oldCfg, newCfg hubv1alpha1.AccessControlPolicySpec) bool {
 
 		return !reflect.DeepEqual(oldCfg.OAuthIntro.ForwardHeaders, newCfg.OAuthIntro.ForwardHeaders)
 
+	case newCfg.GeoIP != nil:
+		if oldCfg.GeoIP == nil {
+			return true
+		}
+
+		return !reflect.DeepEqual(oldCfg.GeoIP.ForwardHeaders, newCfg.GeoIP.ForwardHeaders)
+
 	default:
 		return false
 	}
 }
+
+// internalTokenHeader returns the header the internal token of the given JWT policy is forwarded in, if any.
+func internalTokenHeader(cfg *hubv1alpha1.AccessControlPolicyJWT) string {
+	if cfg.InternalToken == nil {
+		return ""
+	}
+	if cfg.InternalToken.Header == "" {
+		return "Authorization"
+	}
+
+	return http.CanonicalHeaderKey(cfg.InternalToken.Header)
+}
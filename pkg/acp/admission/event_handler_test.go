@@ -91,3 +91,60 @@ func TestEventHandler_OnUpdate(t *testing.T) {
 
 	assert.Equal(t, expected, updater.policies)
 }
+
+func TestHeadersChanged_jwtInternalToken(t *testing.T) {
+	tests := []struct {
+		desc     string
+		oldToken *hubv1alpha1.AccessControlPolicyJWTInternalToken
+		newToken *hubv1alpha1.AccessControlPolicyJWTInternalToken
+		want     bool
+	}{
+		{
+			desc:     "internal token added",
+			newToken: &hubv1alpha1.AccessControlPolicyJWTInternalToken{SigningSecret: "internal"},
+			want:     true,
+		},
+		{
+			desc:     "internal token header changed",
+			oldToken: &hubv1alpha1.AccessControlPolicyJWTInternalToken{SigningSecret: "internal"},
+			newToken: &hubv1alpha1.AccessControlPolicyJWTInternalToken{SigningSecret: "internal", Header: "X-Internal-Token"},
+			want:     true,
+		},
+		{
+			desc:     "internal token signing secret changed",
+			oldToken: &hubv1alpha1.AccessControlPolicyJWTInternalToken{SigningSecret: "internal"},
+			newToken: &hubv1alpha1.AccessControlPolicyJWTInternalToken{SigningSecret: "other", Header: "authorization"},
+			want:     false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			oldCfg := hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{SigningSecret: "secret", InternalToken: test.oldToken},
+			}
+			newCfg := hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{SigningSecret: "secret", InternalToken: test.newToken},
+			}
+
+			assert.Equal(t, test.want, headersChanged(oldCfg, newCfg))
+		})
+	}
+}
+
+func TestHeadersChanged_forwardAuth(t *testing.T) {
+	oldCfg := hubv1alpha1.AccessControlPolicySpec{
+		BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{Users: []string{"user:password"}},
+	}
+	newCfg := hubv1alpha1.AccessControlPolicySpec{
+		BasicAuth:   &hubv1alpha1.AccessControlPolicyBasicAuth{Users: []string{"user:password"}},
+		ForwardAuth: &hubv1alpha1.AccessControlPolicyForwardAuth{TrustForwardHeader: true},
+	}
+
+	assert.True(t, headersChanged(oldCfg, newCfg))
+	assert.True(t, headersChanged(newCfg, oldCfg))
+	assert.False(t, headersChanged(newCfg, newCfg))
+}
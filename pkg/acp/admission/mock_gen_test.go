@@ -468,14 +468,14 @@ func (_c *reviewerCanReviewCall) OnReviewRaw(ar interface{}) *reviewerReviewCall
 	return _c.Parent.OnReviewRaw(ar)
 }
 
-func (_m *reviewerMock) Review(_ context.Context, ar v1.AdmissionReview) (map[string]interface{}, error) {
+func (_m *reviewerMock) Review(_ context.Context, ar v1.AdmissionReview) ([]map[string]interface{}, error) {
 	_ret := _m.Called(ar)
 
-	if _rf, ok := _ret.Get(0).(func(v1.AdmissionReview) (map[string]interface{}, error)); ok {
+	if _rf, ok := _ret.Get(0).(func(v1.AdmissionReview) ([]map[string]interface{}, error)); ok {
 		return _rf(ar)
 	}
 
-	_ra0, _ := _ret.Get(0).(map[string]interface{})
+	_ra0, _ := _ret.Get(0).([]map[string]interface{})
 	_rb1 := _ret.Error(1)
 
 	return _ra0, _rb1
@@ -534,12 +534,12 @@ func (_c *reviewerReviewCall) Maybe() *reviewerReviewCall {
 	return _c
 }
 
-func (_c *reviewerReviewCall) TypedReturns(a map[string]interface{}, b error) *reviewerReviewCall {
+func (_c *reviewerReviewCall) TypedReturns(a []map[string]interface{}, b error) *reviewerReviewCall {
 	_c.Call = _c.Return(a, b)
 	return _c
 }
 
-func (_c *reviewerReviewCall) ReturnsFn(fn func(v1.AdmissionReview) (map[string]interface{}, error)) *reviewerReviewCall {
+func (_c *reviewerReviewCall) ReturnsFn(fn func(v1.AdmissionReview) ([]map[string]interface{}, error)) *reviewerReviewCall {
 	_c.Call = _c.Return(fn)
 	return _c
 }
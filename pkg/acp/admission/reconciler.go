@@ -0,0 +1,240 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	traefikclient "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// Reconciler periodically patches the Ingresses and IngressRoutes which drifted from their ACP, for instance because
+// they were created or updated while the admission webhook was unavailable. The patches are the ones the admission
+// Handler would have returned.
+type Reconciler struct {
+	handler          *Handler
+	informer         informers.SharedInformerFactory
+	clientSet        clientset.Interface
+	traefikClientSet traefikclient.TraefikV1alpha1Interface
+	interval         time.Duration
+
+	supportsNetV1Ingresses bool
+}
+
+// NewReconciler returns a new Reconciler reviewing resources with the given handler every interval. IngressRoutes are
+// not reconciled if traefikClientSet is nil.
+func NewReconciler(handler *Handler, informer informers.SharedInformerFactory, clientSet clientset.Interface, traefikClientSet traefikclient.TraefikV1alpha1Interface, netV1Ingresses bool, interval time.Duration) *Reconciler {
+	return &Reconciler{
+		handler:                handler,
+		informer:               informer,
+		clientSet:              clientSet,
+		traefikClientSet:       traefikClientSet,
+		interval:               interval,
+		supportsNetV1Ingresses: netV1Ingresses,
+	}
+}
+
+// Run runs the Reconciler control loop.
+func (r *Reconciler) Run(ctx context.Context) {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		r.reconcile(ctx)
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *Reconciler) reconcile(ctx context.Context) {
+	if r.supportsNetV1Ingresses {
+		if err := r.reconcileV1Ingresses(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to reconcile ingresses")
+		}
+	} else if err := r.reconcileV1beta1Ingresses(ctx); err != nil {
+		log.Error().Err(err).Msg("Unable to reconcile legacy ingresses")
+	}
+
+	if r.traefikClientSet == nil {
+		return
+	}
+
+	if err := r.reconcileIngressRoutes(ctx); err != nil {
+		log.Error().Err(err).Msg("Unable to reconcile ingress routes")
+	}
+}
+
+func (r *Reconciler) reconcileV1Ingresses(ctx context.Context) error {
+	ingList, err := r.informer.Networking().V1().Ingresses().Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list ingresses: %w", err)
+	}
+
+	for _, ing := range ingList {
+		req, patch, err := r.drift(ctx, netV1IngressKind, ing, ing.ObjectMeta)
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to review ingress")
+			continue
+		}
+		if patch == nil {
+			continue
+		}
+
+		log.Info().Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Patching drifted ingress")
+
+		_, err = r.clientSet.NetworkingV1().Ingresses(ing.Namespace).Patch(ctx, ing.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to patch ingress")
+			continue
+		}
+		r.audit(ctx, req, patch)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) reconcileV1beta1Ingresses(ctx context.Context) error {
+	ingList, err := r.informer.Networking().V1beta1().Ingresses().Lister().List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list legacy ingresses: %w", err)
+	}
+
+	for _, ing := range ingList {
+		req, patch, err := r.drift(ctx, netV1Beta1IngressKind, ing, ing.ObjectMeta)
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to review legacy ingress")
+			continue
+		}
+		if patch == nil {
+			continue
+		}
+
+		log.Info().Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Patching drifted legacy ingress")
+
+		_, err = r.clientSet.NetworkingV1beta1().Ingresses(ing.Namespace).Patch(ctx, ing.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to patch legacy ingress")
+			continue
+		}
+		r.audit(ctx, req, patch)
+	}
+
+	return nil
+}
+
+func (r *Reconciler) reconcileIngressRoutes(ctx context.Context) error {
+	ingRouteList, err := r.traefikClientSet.IngressRoutes(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list ingress routes: %w", err)
+	}
+
+	for i := range ingRouteList.Items {
+		ingRoute := &ingRouteList.Items[i]
+
+		req, patch, err := r.drift(ctx, traefikIngressRouteKind, ingRoute, ingRoute.ObjectMeta)
+		if err != nil {
+			log.Error().Err(err).Str("ingress_route_name", ingRoute.Name).Str("ingress_route_namespace", ingRoute.Namespace).Msg("Unable to review ingress route")
+			continue
+		}
+		if patch == nil {
+			continue
+		}
+
+		log.Info().Str("ingress_route_name", ingRoute.Name).Str("ingress_route_namespace", ingRoute.Namespace).Msg("Patching drifted ingress route")
+
+		_, err = r.traefikClientSet.IngressRoutes(ingRoute.Namespace).Patch(ctx, ingRoute.Name, types.JSONPatchType, patch, metav1.PatchOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("ingress_route_name", ingRoute.Name).Str("ingress_route_namespace", ingRoute.Namespace).Msg("Unable to patch ingress route")
+			continue
+		}
+		r.audit(ctx, req, patch)
+	}
+
+	return nil
+}
+
+// drift reviews the given resource as if it was updated without changes and returns the review request and the JSON
+// Patch to apply to the resource, nil if it didn't drift from its ACP. The patch only applies to the reviewed version of
+// the resource.
+func (r *Reconciler) drift(ctx context.Context, kind metav1.GroupVersionKind, obj runtime.Object, meta metav1.ObjectMeta) (*admv1.AdmissionRequest, []byte, error) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal resource: %w", err)
+	}
+
+	// Reviewing an update where the old resource is the current one makes the reviewers compute the patches required
+	// by the current ACP without removing anything.
+	ar := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			UID:       meta.UID,
+			Kind:      kind,
+			Name:      meta.Name,
+			Namespace: meta.Namespace,
+			Operation: admv1.Update,
+			Object:    runtime.RawExtension{Raw: raw},
+			OldObject: runtime.RawExtension{Raw: raw},
+		},
+	}
+
+	resp, err := r.handler.review(ctx, ar)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.Patch == nil {
+		return nil, nil, nil
+	}
+
+	var patches []map[string]interface{}
+	if err = json.Unmarshal(resp.Patch, &patches); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal patch: %w", err)
+	}
+
+	// Make sure the resource was not modified since it was reviewed.
+	test := map[string]interface{}{"op": "test", "path": "/metadata/resourceVersion", "value": meta.ResourceVersion}
+
+	patch, err := json.Marshal(append([]map[string]interface{}{test}, patches...))
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal patch: %w", err)
+	}
+
+	return ar.Request, patch, nil
+}
+
+// audit records the given patch applied to the resource under review, when the handler audits its mutations.
+func (r *Reconciler) audit(ctx context.Context, req *admv1.AdmissionRequest, patch []byte) {
+	if r.handler.auditor == nil {
+		return
+	}
+
+	r.handler.auditor.Record(ctx, AuditReasonDrift, req, patch)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestReconciler_reconcile(t *testing.T) {
+	drifted := newIngress("drifted", map[string]string{reviewer.AnnotationHubAuth: "acp"}, "whoami")
+	drifted.ResourceVersion = "1"
+	upToDate := newIngress("up-to-date", map[string]string{reviewer.AnnotationHubAuth: "acp", "patched": "true"}, "whoami")
+	unprotected := newIngress("unprotected", nil, "whoami")
+
+	kubeClientSet := kubemock.NewSimpleClientset(drifted, upToDate, unprotected)
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Networking().V1().Ingresses().Informer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	rev := newReviewerMock(t)
+	rev.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil)
+	rev.OnReviewRaw(mock.Anything).ReturnsFn(func(ar admv1.AdmissionReview) ([]map[string]interface{}, error) {
+		if ar.Request.Name != "drifted" {
+			return nil, nil
+		}
+
+		return []map[string]interface{}{{"op": "add", "path": "/metadata/annotations/patched", "value": "true"}}, nil
+	}).Twice()
+
+	registry := NewRegistry()
+	require.NoError(t, registry.Register("reviewer", rev, 0))
+
+	auditor := NewAuditor(kubeClientSet.EventsV1(), "agent-0")
+	r := NewReconciler(NewHandler(registry, rev, nil, auditor), kubeInformer, kubeClientSet, nil, true, 0)
+	r.reconcile(ctx)
+
+	var patches []string
+	for _, action := range kubeClientSet.Actions() {
+		patchAction, ok := action.(ktesting.PatchAction)
+		if !ok {
+			continue
+		}
+
+		assert.Equal(t, "drifted", patchAction.GetName())
+		patches = append(patches, string(patchAction.GetPatch()))
+	}
+
+	assert.Equal(t, []string{
+		`[{"op":"test","path":"/metadata/resourceVersion","value":"1"},{"op":"add","path":"/metadata/annotations/patched","value":"true"}]`,
+	}, patches)
+
+	ing, err := kubeClientSet.NetworkingV1().Ingresses("ns").Get(ctx, "drifted", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "true", ing.Annotations["patched"])
+
+	events, err := kubeClientSet.EventsV1().Events("ns").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, AuditReasonDrift, events.Items[0].Reason)
+	assert.Equal(t, "drifted", events.Items[0].Regarding.Name)
+	assert.Equal(t, `{"patched":"true"}`, events.Items[0].Annotations[AnnotationAuditAfter])
+}
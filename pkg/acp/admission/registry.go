@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KindReviewer is implemented by the reviewers only reviewing objects of some kinds. They are only asked whether they
+// can review objects of these kinds.
+type KindReviewer interface {
+	ReviewsKind(kind metav1.GroupVersionKind) bool
+}
+
+// registration is a reviewer registered in a Registry.
+type registration struct {
+	name     string
+	reviewer Reviewer
+	priority int
+}
+
+// Registry holds the reviewers objects are reviewed by.
+type Registry struct {
+	registrations []registration
+}
+
+// NewRegistry returns a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register registers the given reviewer under the given name. Among the reviewers able to review an object, the one
+// with the highest priority reviews it. Reviewers of equal priority able to review the same object conflict, and the
+// object is not reviewed.
+func (r *Registry) Register(name string, rev Reviewer, priority int) error {
+	if name == "" {
+		return errors.New("reviewer name is required")
+	}
+	if rev == nil {
+		return fmt.Errorf("reviewer %q is nil", name)
+	}
+
+	for _, reg := range r.registrations {
+		if reg.name == name {
+			return fmt.Errorf("reviewer %q already registered", name)
+		}
+	}
+
+	r.registrations = append(r.registrations, registration{name: name, reviewer: rev, priority: priority})
+
+	// Registrations are kept sorted by decreasing priority, the registration order being kept for equal priorities.
+	sort.SliceStable(r.registrations, func(i, j int) bool {
+		return r.registrations[i].priority > r.registrations[j].priority
+	})
+
+	return nil
+}
+
+// find returns the reviewer of the given admission review, nil if none of the reviewers can review it.
+func (r *Registry) find(ctx context.Context, ar admv1.AdmissionReview) (Reviewer, error) {
+	var claimed *registration
+	for i, reg := range r.registrations {
+		if claimed != nil && reg.priority < claimed.priority {
+			// The remaining reviewers have a lower priority than the one which claimed the object.
+			break
+		}
+
+		if kindRev, ok := reg.reviewer.(KindReviewer); ok && !kindRev.ReviewsKind(ar.Request.Kind) {
+			continue
+		}
+
+		ok, err := reg.reviewer.CanReview(ar)
+		if err != nil {
+			return nil, fmt.Errorf("reviewer %q: %w", reg.name, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if claimed != nil {
+			return nil, fmt.Errorf("conflicting reviewers %q and %q, with priority %d, can both review the resource", claimed.name, reg.name, reg.priority)
+		}
+
+		claimed = &r.registrations[i]
+	}
+
+	if claimed == nil {
+		return nil, nil
+	}
+
+	log.Ctx(ctx).Debug().Str("reviewer", claimed.name).Msg("Reviewer found")
+
+	return claimed.reviewer, nil
+}
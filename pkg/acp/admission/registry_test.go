@@ -0,0 +1,167 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+
+	require.NoError(t, registry.Register("reviewer", newReviewerMock(t), 0))
+
+	assert.EqualError(t, registry.Register("reviewer", newReviewerMock(t), 1), `reviewer "reviewer" already registered`)
+	assert.EqualError(t, registry.Register("", newReviewerMock(t), 0), "reviewer name is required")
+	assert.EqualError(t, registry.Register("nil", nil, 0), `reviewer "nil" is nil`)
+}
+
+func TestRegistry_find(t *testing.T) {
+	ar := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			Kind: metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+		},
+	}
+
+	tests := []struct {
+		desc         string
+		reviewers    func(t *testing.T) (*Registry, Reviewer)
+		wantReviewer bool
+		wantErr      string
+	}{
+		{
+			desc: "no reviewer registered",
+			reviewers: func(t *testing.T) (*Registry, Reviewer) {
+				t.Helper()
+
+				return NewRegistry(), nil
+			},
+		},
+		{
+			desc: "only reviewer able to review",
+			reviewers: func(t *testing.T) (*Registry, Reviewer) {
+				t.Helper()
+
+				cannot := newReviewerMock(t)
+				cannot.OnCanReviewRaw(mock.Anything).TypedReturns(false, nil).Once()
+				can := newReviewerMock(t)
+				can.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
+
+				registry := NewRegistry()
+				require.NoError(t, registry.Register("cannot", cannot, 0))
+				require.NoError(t, registry.Register("can", can, 0))
+
+				return registry, can
+			},
+			wantReviewer: true,
+		},
+		{
+			desc: "reviewer of other kinds is not asked",
+			reviewers: func(t *testing.T) (*Registry, Reviewer) {
+				t.Helper()
+
+				// The mock fails the test if its CanReview method is called.
+				other := kindReviewer{Reviewer: newReviewerMock(t), kind: "IngressRoute"}
+				can := newReviewerMock(t)
+				can.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
+
+				registry := NewRegistry()
+				require.NoError(t, registry.Register("other", other, 1))
+				require.NoError(t, registry.Register("can", can, 0))
+
+				return registry, can
+			},
+			wantReviewer: true,
+		},
+		{
+			desc: "highest priority reviewer wins",
+			reviewers: func(t *testing.T) (*Registry, Reviewer) {
+				t.Helper()
+
+				// The low priority reviewer is not asked as a higher priority reviewer already claimed the object.
+				low := newReviewerMock(t)
+				high := newReviewerMock(t)
+				high.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
+
+				registry := NewRegistry()
+				require.NoError(t, registry.Register("low", low, 0))
+				require.NoError(t, registry.Register("high", high, 10))
+
+				return registry, high
+			},
+			wantReviewer: true,
+		},
+		{
+			desc: "reviewers of equal priority conflict",
+			reviewers: func(t *testing.T) (*Registry, Reviewer) {
+				t.Helper()
+
+				first := newReviewerMock(t)
+				first.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
+				second := newReviewerMock(t)
+				second.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
+
+				registry := NewRegistry()
+				require.NoError(t, registry.Register("first", first, 1))
+				require.NoError(t, registry.Register("second", second, 1))
+
+				return registry, nil
+			},
+			wantErr: `conflicting reviewers "first" and "second", with priority 1, can both review the resource`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry, wantReviewer := test.reviewers(t)
+
+			got, err := registry.find(context.Background(), ar)
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if !test.wantReviewer {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Same(t, wantReviewer, got)
+		})
+	}
+}
+
+type kindReviewer struct {
+	Reviewer
+
+	kind string
+}
+
+func (r kindReviewer) ReviewsKind(kind metav1.GroupVersionKind) bool {
+	return kind.Kind == r.kind
+}
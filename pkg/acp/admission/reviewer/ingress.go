@@ -21,8 +21,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -86,7 +88,10 @@ func parseIngressClass(obj []byte) (ingClassName, ingClassAnno string, err error
 	return ing.Spec.IngressClassName, ing.ObjectMeta.Annotations["kubernetes.io/ingress.class"], nil
 }
 
-func headerToForward(cfg *acp.Config) ([]string, error) {
+// headerToForward returns the headers to copy from the auth response to the request forwarded to the backend. When
+// longLived is true, the auth server gives authorized long-lived connections an ID the backend needs to re-authenticate
+// them, which is forwarded as well.
+func headerToForward(cfg *acp.Config, longLived bool) ([]string, error) {
 	var headerToFwd []string
 
 	switch {
@@ -94,9 +99,12 @@ func headerToForward(cfg *acp.Config) ([]string, error) {
 		for headerName := range cfg.JWT.ForwardHeaders {
 			headerToFwd = append(headerToFwd, headerName)
 		}
-		if cfg.JWT.StripAuthorizationHeader {
+		if cfg.JWT.StripAuthorizationHeader || cfg.JWT.InternalToken != nil {
 			headerToFwd = append(headerToFwd, "Authorization")
 		}
+		if tok := cfg.JWT.InternalToken; tok != nil && tok.Header != "" && !strings.EqualFold(tok.Header, "Authorization") {
+			headerToFwd = append(headerToFwd, tok.Header)
+		}
 
 	case cfg.BasicAuth != nil:
 		if headerName := cfg.BasicAuth.ForwardUsernameHeader; headerName != "" {
@@ -128,13 +136,27 @@ func headerToForward(cfg *acp.Config) ([]string, error) {
 			headerToFwd = append(headerToFwd, headerName)
 		}
 
+	case cfg.GeoIP != nil:
+		for headerName := range cfg.GeoIP.ForwardHeaders {
+			headerToFwd = append(headerToFwd, headerName)
+		}
+
 	default:
 		return nil, errors.New("unsupported ACP type")
 	}
 
+	if longLived {
+		headerToFwd = append(headerToFwd, auth.HeaderConnectionID)
+	}
+
 	return headerToFwd, nil
 }
 
+// EscapeJSONPointer escapes the given JSON Pointer reference token, as defined by RFC 6901.
+func EscapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
 func isDefaultIngressClassValue(value string) bool {
 	switch value {
 	case defaultAnnotationTraefik, defaultAnnotationNginx:
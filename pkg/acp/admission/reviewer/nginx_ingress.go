@@ -26,30 +26,39 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // NginxIngress is a reviewer that handles Nginx Ingress resources.
 type NginxIngress struct {
 	agentAddress   string
+	longLived      bool
 	ingressClasses IngressClasses
 	policies       PolicyGetter
 }
 
-// NewNginxIngress returns an Nginx ingress reviewer.
-func NewNginxIngress(authServerAddr string, ingClasses IngressClasses, policies PolicyGetter) *NginxIngress {
+// NewNginxIngress returns an Nginx ingress reviewer. When longLived is true, the ID the auth server gives to authorized
+// long-lived connections is forwarded to the backends.
+func NewNginxIngress(authServerAddr string, longLived bool, ingClasses IngressClasses, policies PolicyGetter) *NginxIngress {
 	return &NginxIngress{
 		agentAddress:   authServerAddr,
+		longLived:      longLived,
 		ingressClasses: ingClasses,
 		policies:       policies,
 	}
 }
 
+// ReviewsKind returns whether this reviewer reviews resources of the given kind.
+func (r NginxIngress) ReviewsKind(kind metav1.GroupVersionKind) bool {
+	return IsIngress(kind)
+}
+
 // CanReview returns whether this reviewer can handle the given admission review request.
 func (r NginxIngress) CanReview(ar admv1.AdmissionReview) (bool, error) {
 	resource := ar.Request.Kind
 
 	// Check resource type. Only continue if it's a legacy Ingress (<1.18) or an Ingress resource.
-	if !isNetV1Ingress(resource) && !isNetV1Beta1Ingress(resource) && !isExtV1Beta1Ingress(resource) {
+	if !r.ReviewsKind(resource) {
 		return false, nil
 	}
 
@@ -102,7 +111,7 @@ func (r NginxIngress) CanReview(ar admv1.AdmissionReview) (bool, error) {
 }
 
 // Review reviews the given admission review request and optionally returns the required patch.
-func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) ([]map[string]interface{}, error) {
 	l := log.Ctx(ctx).With().Str("reviewer", "NginxIngress").Logger()
 	ctx = l.WithContext(ctx)
 
@@ -118,8 +127,8 @@ func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map
 		return nil, fmt.Errorf("parse raw objects: %w", err)
 	}
 
-	prevPolName := oldIng.Metadata.Annotations[AnnotationHubAuth]
-	polName := ing.Metadata.Annotations[AnnotationHubAuth]
+	prevPolName := PolicyName(oldIng.Metadata.Annotations)
+	polName := PolicyName(ing.Metadata.Annotations)
 
 	if prevPolName == "" && polName == "" {
 		log.Ctx(ctx).Debug().Msg("No ACP defined")
@@ -136,9 +145,9 @@ func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map
 		polCfg, err = r.policies.GetConfig(polName)
 		switch {
 		case errors.Is(err, ErrPolicyNotFound):
-			nginxAnno, err = genNginxAnnotations(polName, nil, r.agentAddress)
+			nginxAnno, err = genNginxAnnotations(polName, nil, r.agentAddress, r.longLived)
 		case err == nil:
-			nginxAnno, err = genNginxAnnotations(polName, polCfg, r.agentAddress)
+			nginxAnno, err = genNginxAnnotations(polName, polCfg, r.agentAddress, r.longLived)
 		}
 
 		if err != nil {
@@ -147,24 +156,26 @@ func (r NginxIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map
 	}
 	nginxAnno = mergeSnippets(nginxAnno, ing.Metadata.Annotations)
 
-	if noNginxPatchRequired(ing.Metadata.Annotations, nginxAnno) {
+	if noAnnotationPatchRequired(ing.Metadata.Annotations, nginxAnno) {
 		log.Ctx(ctx).Debug().Str("acp_name", polName).Msg("No patch required")
 		return nil, nil
 	}
 
-	setNginxAnnotations(ing.Metadata.Annotations, nginxAnno)
+	setAnnotations(ing.Metadata.Annotations, nginxAnno)
 
 	log.Ctx(ctx).Info().Str("acp_name", polName).Msg("Patching resource")
 
-	return map[string]interface{}{
-		"op":    "replace",
-		"path":  "/metadata/annotations",
-		"value": ing.Metadata.Annotations,
+	return []map[string]interface{}{
+		{
+			"op":    "replace",
+			"path":  "/metadata/annotations",
+			"value": ing.Metadata.Annotations,
+		},
 	}, nil
 }
 
-func noNginxPatchRequired(anno, nginxAnno map[string]string) bool {
-	for k, v := range nginxAnno {
+func noAnnotationPatchRequired(anno, patched map[string]string) bool {
+	for k, v := range patched {
 		if anno[k] != v {
 			return false
 		}
@@ -173,8 +184,8 @@ func noNginxPatchRequired(anno, nginxAnno map[string]string) bool {
 	return true
 }
 
-func setNginxAnnotations(anno, nginxAnno map[string]string) {
-	for k, v := range nginxAnno {
+func setAnnotations(anno, patched map[string]string) {
+	for k, v := range patched {
 		if v == "" {
 			delete(anno, k)
 			continue
@@ -37,7 +37,7 @@ const (
 	serverSnippet        = "nginx.ingress.kubernetes.io/server-snippet"
 )
 
-func genNginxAnnotations(polName string, polCfg *acp.Config, agentAddr string) (map[string]string, error) {
+func genNginxAnnotations(polName string, polCfg *acp.Config, agentAddr string, longLived bool) (map[string]string, error) {
 	// If there's no policy given, force a 404 response. It allows to untie ACP creation from ACP reference and
 	// remove ordering constraints while still not exposing publicly a protected resource.
 	if polCfg == nil {
@@ -46,7 +46,7 @@ func genNginxAnnotations(polName string, polCfg *acp.Config, agentAddr string) (
 		}, nil
 	}
 
-	headerToFwd, err := headerToForward(polCfg)
+	headerToFwd, err := headerToForward(polCfg, longLived)
 	if err != nil {
 		return nil, fmt.Errorf("get header to forward: %w", err)
 	}
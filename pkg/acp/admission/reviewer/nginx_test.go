@@ -124,7 +124,7 @@ func TestNginxIngress_CanReviewChecksKind(t *testing.T) {
 			ic := newIngressClassesMock(t).
 				OnGetDefaultController().TypedReturns(ingclass.ControllerTypeNginxCommunity, nil).Maybe().
 				Parent
-			review := NewNginxIngress("", ic, nil)
+			review := NewNginxIngress("", false, ic, nil)
 
 			var ing netv1.Ingress
 			b, err := json.Marshal(ing)
@@ -193,7 +193,7 @@ func TestNginxIngress_CanReviewChecksIngressClass(t *testing.T) {
 				OnGetDefaultController().TypedReturns(test.defaultController, nil).Maybe().
 				Parent
 
-			review := NewNginxIngress("", i, nil)
+			review := NewNginxIngress("", false, i, nil)
 
 			ing := netv1.Ingress{
 				ObjectMeta: metav1.ObjectMeta{
@@ -493,7 +493,7 @@ func TestNginxIngress_Review(t *testing.T) {
 				policyGetter.OnGetConfig(mock.Anything).TypedReturns(test.config, nil).Maybe()
 			}
 
-			rev := NewNginxIngress("http://hub-agent.default.svc.cluster.local", nil, policyGetter)
+			rev := NewNginxIngress("http://hub-agent.default.svc.cluster.local", false, nil, policyGetter)
 
 			ing := struct {
 				Metadata metav1.ObjectMeta `json:"metadata"`
@@ -537,12 +537,11 @@ func TestNginxIngress_Review(t *testing.T) {
 				assert.Nil(t, patch)
 				return
 			}
-			assert.NotNil(t, patch)
+			require.Len(t, patch, 1)
 
-			assert.Equal(t, 3, len(patch))
-			assert.Equal(t, "replace", patch["op"])
-			assert.Equal(t, "/metadata/annotations", patch["path"])
-			assert.Equal(t, test.wantPatch, patch["value"].(map[string]string))
+			assert.Equal(t, "replace", patch[0]["op"])
+			assert.Equal(t, "/metadata/annotations", patch[0]["path"])
+			assert.Equal(t, test.wantPatch, patch[0]["value"].(map[string]string))
 		})
 	}
 }
@@ -0,0 +1,163 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations set on OpenShift Routes protected by an ACP. The OpenShift router doesn't support forward
+// authentication natively: these annotations are meant to be read by a custom HAProxy router template, which sends an
+// authentication request to the auth URL before forwarding a request to the Route backends.
+const (
+	// AnnotationRouteAuthURL is the URL of the auth server endpoint of the ACP protecting the Route.
+	AnnotationRouteAuthURL = "hub.traefik.io/route-auth-url"
+	// AnnotationRouteAuthResponseHeaders is the comma-separated list of the headers of the auth server responses to
+	// copy in the requests forwarded to the Route backends.
+	AnnotationRouteAuthResponseHeaders = "hub.traefik.io/route-auth-response-headers"
+	// AnnotationRouteAuthRedirectPath is the path, set for OIDC ACPs only, on which the identity provider redirects
+	// users once authenticated. Requests on this path must be sent to the auth URL.
+	AnnotationRouteAuthRedirectPath = "hub.traefik.io/route-auth-redirect-path"
+)
+
+// OpenShiftRoute is a reviewer that handles OpenShift Route resources.
+type OpenShiftRoute struct {
+	agentAddress string
+	longLived    bool
+	policies     PolicyGetter
+}
+
+// NewOpenShiftRoute returns an OpenShift Route reviewer. When longLived is true, the ID the auth server gives to
+// authorized long-lived connections is forwarded to the backends.
+func NewOpenShiftRoute(authServerAddr string, longLived bool, policies PolicyGetter) *OpenShiftRoute {
+	return &OpenShiftRoute{
+		agentAddress: authServerAddr,
+		longLived:    longLived,
+		policies:     policies,
+	}
+}
+
+// ReviewsKind returns whether this reviewer reviews resources of the given kind.
+func (r OpenShiftRoute) ReviewsKind(kind metav1.GroupVersionKind) bool {
+	return IsOpenShiftRoute(kind)
+}
+
+// CanReview returns whether this reviewer can handle the given admission review request. Routes are always served by
+// the OpenShift router.
+func (r OpenShiftRoute) CanReview(ar admv1.AdmissionReview) (bool, error) {
+	return r.ReviewsKind(ar.Request.Kind), nil
+}
+
+// Review reviews the given admission review request and optionally returns the required patch.
+func (r OpenShiftRoute) Review(ctx context.Context, ar admv1.AdmissionReview) ([]map[string]interface{}, error) {
+	l := log.Ctx(ctx).With().Str("reviewer", "OpenShiftRoute").Logger()
+	ctx = l.WithContext(ctx)
+
+	log.Ctx(ctx).Info().Msg("Reviewing Route resource")
+
+	if ar.Request.Operation == admv1.Delete {
+		log.Ctx(ctx).Info().Msg("Deleting Route resource")
+		return nil, nil
+	}
+
+	route, oldRoute, err := parseRawIngresses(ar.Request.Object.Raw, ar.Request.OldObject.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse raw objects: %w", err)
+	}
+
+	prevPolName := PolicyName(oldRoute.Metadata.Annotations)
+	polName := PolicyName(route.Metadata.Annotations)
+
+	if prevPolName == "" && polName == "" {
+		log.Ctx(ctx).Debug().Msg("No ACP defined")
+		return nil, nil
+	}
+
+	routeAnno := map[string]string{
+		AnnotationRouteAuthURL:             "",
+		AnnotationRouteAuthResponseHeaders: "",
+		AnnotationRouteAuthRedirectPath:    "",
+	}
+	if polName == "" {
+		log.Ctx(ctx).Debug().Msg("No ACP annotation found")
+	} else {
+		log.Ctx(ctx).Debug().Str("acp_name", polName).Msg("ACP annotation is present")
+
+		// The auth server denies the requests of unknown ACPs, which keeps the Route protected until its ACP is
+		// created.
+		routeAnno[AnnotationRouteAuthURL] = fmt.Sprintf("%s/%s", r.agentAddress, polName)
+
+		var polCfg *acp.Config
+		polCfg, err = r.policies.GetConfig(polName)
+		if err != nil && !errors.Is(err, ErrPolicyNotFound) {
+			return nil, err
+		}
+
+		if polCfg != nil {
+			if err = setRouteAuthAnnotations(routeAnno, polCfg, r.longLived); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if route.Metadata.Annotations == nil {
+		route.Metadata.Annotations = map[string]string{}
+	}
+
+	if noAnnotationPatchRequired(route.Metadata.Annotations, routeAnno) {
+		log.Ctx(ctx).Debug().Str("acp_name", polName).Msg("No patch required")
+		return nil, nil
+	}
+
+	setAnnotations(route.Metadata.Annotations, routeAnno)
+
+	log.Ctx(ctx).Info().Str("acp_name", polName).Msg("Patching resource")
+
+	return []map[string]interface{}{
+		{
+			"op":    "replace",
+			"path":  "/metadata/annotations",
+			"value": route.Metadata.Annotations,
+		},
+	}, nil
+}
+
+func setRouteAuthAnnotations(routeAnno map[string]string, polCfg *acp.Config, longLived bool) error {
+	headerToFwd, err := headerToForward(polCfg, longLived)
+	if err != nil {
+		return fmt.Errorf("get header to forward: %w", err)
+	}
+	sort.Strings(headerToFwd)
+	routeAnno[AnnotationRouteAuthResponseHeaders] = strings.Join(headerToFwd, ",")
+
+	if polCfg.OIDC == nil {
+		return nil
+	}
+
+	routeAnno[AnnotationRouteAuthRedirectPath], err = redirectPath(polCfg)
+	return err
+}
@@ -0,0 +1,210 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestOpenShiftRoute_CanReview(t *testing.T) {
+	tests := []struct {
+		desc string
+		kind metav1.GroupVersionKind
+		want assert.BoolAssertionFunc
+	}{
+		{
+			desc: "can review route.openshift.io v1 Routes",
+			kind: metav1.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+			want: assert.True,
+		},
+		{
+			desc: "can't review invalid route.openshift.io Route version",
+			kind: metav1.GroupVersionKind{Group: "route.openshift.io", Version: "invalid", Kind: "Route"},
+			want: assert.False,
+		},
+		{
+			desc: "can't review networking.k8s.io v1 Ingresses",
+			kind: metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+			want: assert.False,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rev := NewOpenShiftRoute("http://hub-agent.default.svc.cluster.local", false, newPolicyGetterMock(t))
+
+			ok, err := rev.CanReview(admv1.AdmissionReview{Request: &admv1.AdmissionRequest{Kind: test.kind}})
+			require.NoError(t, err)
+
+			test.want(t, ok)
+		})
+	}
+}
+
+func TestOpenShiftRoute_Review(t *testing.T) {
+	tests := []struct {
+		desc             string
+		config           *acp.Config
+		prevAnnotations  map[string]string
+		routeAnnotations map[string]string
+		wantPatch        map[string]string
+		noPatch          bool
+	}{
+		{
+			desc: "adds authentication if ACP annotation is set",
+			config: &acp.Config{
+				JWT: &jwt.Config{
+					StripAuthorizationHeader: true,
+					ForwardHeaders:           map[string]string{"X-Tenant": "tenant", "X-Group": "group"},
+				},
+			},
+			routeAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy": "my-policy",
+				"custom-annotation":                    "foobar",
+			},
+			wantPatch: map[string]string{
+				"hub.traefik.io/access-control-policy":       "my-policy",
+				"hub.traefik.io/route-auth-url":              "http://hub-agent.default.svc.cluster.local/my-policy",
+				"hub.traefik.io/route-auth-response-headers": "Authorization,X-Group,X-Tenant",
+				"custom-annotation":                          "foobar",
+			},
+		},
+		{
+			desc: "adds the redirect path of OIDC ACPs",
+			config: &acp.Config{
+				OIDC: &oidc.Config{RedirectURL: "https://example.com/oidc/callback"},
+			},
+			routeAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy": "my-policy",
+			},
+			wantPatch: map[string]string{
+				"hub.traefik.io/access-control-policy":       "my-policy",
+				"hub.traefik.io/route-auth-url":              "http://hub-agent.default.svc.cluster.local/my-policy",
+				"hub.traefik.io/route-auth-response-headers": "Authorization,Cookie",
+				"hub.traefik.io/route-auth-redirect-path":    "/oidc/callback",
+			},
+		},
+		{
+			desc: "keeps the Route protected when the ACP is not found",
+			routeAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy": "my-policy",
+			},
+			wantPatch: map[string]string{
+				"hub.traefik.io/access-control-policy": "my-policy",
+				"hub.traefik.io/route-auth-url":        "http://hub-agent.default.svc.cluster.local/my-policy",
+			},
+		},
+		{
+			desc:   "removes authentication if ACP annotation is removed",
+			config: &acp.Config{JWT: &jwt.Config{}},
+			prevAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy": "my-policy",
+				"hub.traefik.io/route-auth-url":        "http://hub-agent.default.svc.cluster.local/my-policy",
+			},
+			routeAnnotations: map[string]string{
+				"hub.traefik.io/route-auth-url":              "http://hub-agent.default.svc.cluster.local/my-policy",
+				"hub.traefik.io/route-auth-response-headers": "Authorization",
+				"custom-annotation":                          "foobar",
+			},
+			wantPatch: map[string]string{
+				"custom-annotation": "foobar",
+			},
+		},
+		{
+			desc:   "returns no patch if annotations are already correct",
+			config: &acp.Config{JWT: &jwt.Config{StripAuthorizationHeader: true}},
+			routeAnnotations: map[string]string{
+				"hub.traefik.io/access-control-policy":       "my-policy",
+				"hub.traefik.io/route-auth-url":              "http://hub-agent.default.svc.cluster.local/my-policy",
+				"hub.traefik.io/route-auth-response-headers": "Authorization",
+			},
+			noPatch: true,
+		},
+		{
+			desc:    "no previous ACP and no current ACP returns an empty patch",
+			noPatch: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			policyGetter := newPolicyGetterMock(t)
+			if test.config == nil {
+				policyGetter.OnGetConfig(mock.Anything).TypedReturns(nil, ErrPolicyNotFound).Maybe()
+			} else {
+				policyGetter.OnGetConfig(mock.Anything).TypedReturns(test.config, nil).Maybe()
+			}
+
+			rev := NewOpenShiftRoute("http://hub-agent.default.svc.cluster.local", false, policyGetter)
+
+			b, err := json.Marshal(struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}{
+				Metadata: metav1.ObjectMeta{Name: "name", Namespace: "test", Annotations: test.routeAnnotations},
+			})
+			require.NoError(t, err)
+
+			oldB, err := json.Marshal(struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}{
+				Metadata: metav1.ObjectMeta{Name: "name", Namespace: "test", Annotations: test.prevAnnotations},
+			})
+			require.NoError(t, err)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Kind:      metav1.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+					Operation: admv1.Update,
+					Object:    runtime.RawExtension{Raw: b},
+					OldObject: runtime.RawExtension{Raw: oldB},
+				},
+			}
+
+			patch, err := rev.Review(context.Background(), ar)
+			require.NoError(t, err)
+
+			if test.noPatch {
+				assert.Nil(t, patch)
+				return
+			}
+			require.Len(t, patch, 1)
+
+			assert.Equal(t, "replace", patch[0]["op"])
+			assert.Equal(t, "/metadata/annotations", patch[0]["path"])
+			assert.Equal(t, test.wantPatch, patch[0]["value"].(map[string]string))
+		})
+	}
+}
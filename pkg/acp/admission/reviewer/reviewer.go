@@ -18,6 +18,7 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package reviewer
 
 import (
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -43,3 +44,22 @@ func isExtV1Beta1Ingress(resource metav1.GroupVersionKind) bool {
 func isTraefikV1Alpha1IngressRoute(resource metav1.GroupVersionKind) bool {
 	return resource.Group == "traefik.containo.us" && resource.Version == "v1alpha1" && resource.Kind == "IngressRoute"
 }
+
+func isOpenShiftV1Route(resource metav1.GroupVersionKind) bool {
+	return resource.Group == openshift.GroupName && resource.Version == "v1" && resource.Kind == "Route"
+}
+
+// IsIngress returns whether the given kind is an Ingress, legacy (<1.18) or not.
+func IsIngress(resource metav1.GroupVersionKind) bool {
+	return isNetV1Ingress(resource) || isNetV1Beta1Ingress(resource) || isExtV1Beta1Ingress(resource)
+}
+
+// IsIngressRoute returns whether the given kind is a Traefik IngressRoute.
+func IsIngressRoute(resource metav1.GroupVersionKind) bool {
+	return isTraefikV1Alpha1IngressRoute(resource)
+}
+
+// IsOpenShiftRoute returns whether the given kind is an OpenShift Route.
+func IsOpenShiftRoute(resource metav1.GroupVersionKind) bool {
+	return isOpenShiftV1Route(resource)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AnnotationServiceHubAuth is the annotation set by the agent on the Ingress and IngressRoute resources targeting a
+// Service having the AnnotationHubAuth annotation. It holds the ACP of the Service, which is used when the resource
+// doesn't have an ACP of its own.
+const AnnotationServiceHubAuth = "hub.traefik.io/service-access-control-policy"
+
+// PolicyName returns the name of the ACP protecting a resource having the given annotations. The ACP of the resource
+// takes precedence over the one of the Services it targets.
+func PolicyName(annotations map[string]string) string {
+	if polName := annotations[AnnotationHubAuth]; polName != "" {
+		return polName
+	}
+
+	return annotations[AnnotationServiceHubAuth]
+}
+
+// ServiceNames returns the sorted names of the Services the given raw Ingress, IngressRoute or OpenShift Route targets
+// in its own namespace.
+func ServiceNames(kind metav1.GroupVersionKind, raw []byte) ([]string, error) {
+	names := make(map[string]struct{})
+
+	switch {
+	case IsIngress(kind):
+		var ing struct {
+			Spec struct {
+				DefaultBackend *ingressBackend `json:"defaultBackend"`
+				Backend        *ingressBackend `json:"backend"`
+				Rules          []struct {
+					HTTP *struct {
+						Paths []struct {
+							Backend ingressBackend `json:"backend"`
+						} `json:"paths"`
+					} `json:"http"`
+				} `json:"rules"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(raw, &ing); err != nil {
+			return nil, fmt.Errorf("unmarshal ingress: %w", err)
+		}
+
+		backends := []*ingressBackend{ing.Spec.DefaultBackend, ing.Spec.Backend}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for i := range rule.HTTP.Paths {
+				backends = append(backends, &rule.HTTP.Paths[i].Backend)
+			}
+		}
+
+		for _, backend := range backends {
+			if name := backend.serviceName(); name != "" {
+				names[name] = struct{}{}
+			}
+		}
+
+	case IsIngressRoute(kind):
+		var ingRoute traefikv1alpha1.IngressRoute
+		if err := json.Unmarshal(raw, &ingRoute); err != nil {
+			return nil, fmt.Errorf("unmarshal ingress route: %w", err)
+		}
+
+		for _, route := range ingRoute.Spec.Routes {
+			for _, service := range route.Services {
+				if service.Kind != "" && service.Kind != "Service" {
+					continue
+				}
+				if service.Namespace != "" && service.Namespace != ingRoute.Namespace {
+					continue
+				}
+
+				names[service.Name] = struct{}{}
+			}
+		}
+
+	case IsOpenShiftRoute(kind):
+		var route openshift.Route
+		if err := json.Unmarshal(raw, &route); err != nil {
+			return nil, fmt.Errorf("unmarshal route: %w", err)
+		}
+
+		for _, name := range route.Services() {
+			names[name] = struct{}{}
+		}
+
+	default:
+		return nil, nil
+	}
+
+	serviceNames := make([]string, 0, len(names))
+	for name := range names {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	return serviceNames, nil
+}
+
+// ingressBackend is a generic form of netv1 and netv1beta1 ingress backends.
+type ingressBackend struct {
+	Service *struct {
+		Name string `json:"name"`
+	} `json:"service"`
+	ServiceName string `json:"serviceName"`
+}
+
+func (b *ingressBackend) serviceName() string {
+	if b == nil {
+		return ""
+	}
+	if b.Service != nil {
+		return b.Service.Name
+	}
+
+	return b.ServiceName
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPolicyName(t *testing.T) {
+	assert.Equal(t, "", PolicyName(nil))
+	assert.Equal(t, "service-acp", PolicyName(map[string]string{AnnotationServiceHubAuth: "service-acp"}))
+	assert.Equal(t, "acp", PolicyName(map[string]string{
+		AnnotationHubAuth:        "acp",
+		AnnotationServiceHubAuth: "service-acp",
+	}))
+}
+
+func TestServiceNames(t *testing.T) {
+	tests := []struct {
+		desc      string
+		kind      metav1.GroupVersionKind
+		raw       string
+		wantNames []string
+	}{
+		{
+			desc: "networking.k8s.io v1 Ingress",
+			kind: metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+			raw: `{"spec":{
+				"defaultBackend":{"service":{"name":"default"}},
+				"rules":[
+					{"http":{"paths":[{"backend":{"service":{"name":"whoami"}}},{"backend":{"resource":{"name":"bucket"}}}]}},
+					{"http":{"paths":[{"backend":{"service":{"name":"whoami"}}},{"backend":{"service":{"name":"api"}}}]}},
+					{"host":"example.com"}
+				]
+			}}`,
+			wantNames: []string{"api", "default", "whoami"},
+		},
+		{
+			desc: "extensions v1beta1 Ingress",
+			kind: metav1.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"},
+			raw: `{"spec":{
+				"backend":{"serviceName":"default"},
+				"rules":[{"http":{"paths":[{"backend":{"serviceName":"whoami"}}]}}]
+			}}`,
+			wantNames: []string{"default", "whoami"},
+		},
+		{
+			desc: "traefik.containo.us v1alpha1 IngressRoute",
+			kind: metav1.GroupVersionKind{Group: "traefik.containo.us", Version: "v1alpha1", Kind: "IngressRoute"},
+			raw: `{"metadata":{"namespace":"ns"},"spec":{"routes":[
+				{"services":[{"name":"whoami"},{"name":"api","namespace":"ns","kind":"Service"}]},
+				{"services":[{"name":"weighted","kind":"TraefikService"},{"name":"other","namespace":"other-ns"}]}
+			]}}`,
+			wantNames: []string{"api", "whoami"},
+		},
+		{
+			desc: "route.openshift.io v1 Route",
+			kind: metav1.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+			raw: `{"spec":{
+				"to":{"kind":"Service","name":"whoami"},
+				"alternateBackends":[{"kind":"Service","name":"whoami-canary"},{"name":"api"},{"kind":"Other","name":"other"}]
+			}}`,
+			wantNames: []string{"api", "whoami", "whoami-canary"},
+		},
+		{
+			desc: "unsupported kind",
+			kind: metav1.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"},
+			raw:  `{}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			names, err := ServiceNames(test.kind, []byte(test.raw))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantNames, names)
+		})
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/drift"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -34,14 +36,17 @@ import (
 // FwdAuthMiddlewares manages Traefik forwardAuth middlewares.
 type FwdAuthMiddlewares struct {
 	agentAddress     string
+	longLived        bool
 	policies         PolicyGetter
 	traefikClientSet v1alpha1.TraefikV1alpha1Interface
 }
 
-// NewFwdAuthMiddlewares returns a new FwdAuthMiddlewares.
-func NewFwdAuthMiddlewares(agentAddr string, policies PolicyGetter, traefikClientSet v1alpha1.TraefikV1alpha1Interface) FwdAuthMiddlewares {
+// NewFwdAuthMiddlewares returns a new FwdAuthMiddlewares. When longLived is true, the middlewares forward the ID the
+// auth server gives to authorized long-lived connections.
+func NewFwdAuthMiddlewares(agentAddr string, longLived bool, policies PolicyGetter, traefikClientSet v1alpha1.TraefikV1alpha1Interface) FwdAuthMiddlewares {
 	return FwdAuthMiddlewares{
 		agentAddress:     agentAddr,
+		longLived:        longLived,
 		policies:         policies,
 		traefikClientSet: traefikClientSet,
 	}
@@ -54,7 +59,7 @@ func NewFwdAuthMiddlewares(agentAddr string, policies PolicyGetter, traefikClien
 // a protected resource.
 // NOTE: forward auth middlewares deletion is to be done elsewhere, when ACPs are deleted.
 func (m FwdAuthMiddlewares) Setup(ctx context.Context, polName, namespace string) (string, error) {
-	name := middlewareName(polName)
+	name := MiddlewareName(polName)
 
 	logger := log.Ctx(ctx).With().
 		Str("acp_name", polName).
@@ -81,6 +86,10 @@ func (m FwdAuthMiddlewares) Setup(ctx context.Context, polName, namespace string
 }
 
 func (m *FwdAuthMiddlewares) setupMiddleware(ctx context.Context, name, namespace, canonicalPolName string, cfg *acp.Config) error {
+	if m.traefikClientSet == nil {
+		return errors.New("ACPs on Traefik resources require the Traefik Middleware CRD to be installed")
+	}
+
 	logger := log.Ctx(ctx)
 
 	currentMiddleware, err := m.findMiddleware(ctx, name, namespace)
@@ -88,31 +97,19 @@ func (m *FwdAuthMiddlewares) setupMiddleware(ctx context.Context, name, namespac
 		return err
 	}
 
-	if currentMiddleware == nil {
-		logger.Debug().Msg("No ForwardAuth middleware found, creating a new one")
-		return m.createMiddleware(ctx, name, namespace, canonicalPolName, cfg)
-	}
-
 	newSpec, err := m.newMiddlewareSpec(canonicalPolName, cfg)
 	if err != nil {
-		return err
+		return fmt.Errorf("new middleware spec: %w", err)
 	}
 
-	if reflect.DeepEqual(currentMiddleware.Spec, newSpec) {
+	if currentMiddleware != nil && reflect.DeepEqual(currentMiddleware.Spec, newSpec) && drift.IsStamped(currentMiddleware.ObjectMeta) {
 		logger.Debug().Msg("Existing ForwardAuth middleware is up do date")
 		return nil
 	}
 
-	logger.Debug().Msg("Existing ForwardAuth middleware is outdated, updating it")
-
-	currentMiddleware.Spec = newSpec
+	logger.Debug().Msg("ForwardAuth middleware missing or outdated, applying it")
 
-	_, err = m.traefikClientSet.Middlewares(namespace).Update(ctx, currentMiddleware, metav1.UpdateOptions{FieldManager: "hub-auth"})
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return m.applyMiddleware(ctx, name, namespace, newSpec)
 }
 
 func (m *FwdAuthMiddlewares) findMiddleware(ctx context.Context, name, namespace string) (*traefikv1alpha1.Middleware, error) {
@@ -128,36 +125,62 @@ func (m *FwdAuthMiddlewares) findMiddleware(ctx context.Context, name, namespace
 }
 
 func (m *FwdAuthMiddlewares) newMiddlewareSpec(canonicalPolName string, cfg *acp.Config) (traefikv1alpha1.MiddlewareSpec, error) {
-	authResponseHeaders, err := headerToForward(cfg)
+	forwardAuth, err := NewForwardAuth(m.agentAddress, canonicalPolName, cfg, m.longLived)
 	if err != nil {
 		return traefikv1alpha1.MiddlewareSpec{}, err
 	}
 
-	return traefikv1alpha1.MiddlewareSpec{
-		ForwardAuth: &traefikv1alpha1.ForwardAuth{
-			Address:             m.agentAddress + "/" + canonicalPolName,
-			AuthResponseHeaders: authResponseHeaders,
-		},
-	}, nil
+	return traefikv1alpha1.MiddlewareSpec{ForwardAuth: forwardAuth}, nil
 }
 
-func (m *FwdAuthMiddlewares) createMiddleware(ctx context.Context, name, namespace, canonicalPolName string, cfg *acp.Config) error {
-	spec, err := m.newMiddlewareSpec(canonicalPolName, cfg)
+// NewForwardAuth returns the configuration of the ForwardAuth middleware calling the auth server, reachable at the given
+// address, for the given ACP. When longLived is true, the ID the auth server gives to authorized long-lived connections
+// is forwarded to the backends.
+func NewForwardAuth(authServerAddr, polName string, cfg *acp.Config, longLived bool) (*traefikv1alpha1.ForwardAuth, error) {
+	authResponseHeaders, err := headerToForward(cfg, longLived)
 	if err != nil {
-		return fmt.Errorf("new middleware spec: %w", err)
+		return nil, err
+	}
+
+	forwardAuth := &traefikv1alpha1.ForwardAuth{
+		Address:             authServerAddr + "/" + polName,
+		AuthResponseHeaders: authResponseHeaders,
+	}
+
+	if cfg.ForwardAuth != nil {
+		forwardAuth.TrustForwardHeader = cfg.ForwardAuth.TrustForwardHeader
+		forwardAuth.AuthRequestHeaders = cfg.ForwardAuth.AuthRequestHeaders
+
+		if cfg.ForwardAuth.TLS != nil {
+			forwardAuth.TLS = &traefikv1alpha1.ClientTLS{
+				CASecret:   cfg.ForwardAuth.TLS.CASecret,
+				CertSecret: cfg.ForwardAuth.TLS.CertSecret,
+			}
+		}
 	}
 
+	return forwardAuth, nil
+}
+
+func (m *FwdAuthMiddlewares) applyMiddleware(ctx context.Context, name, namespace string, spec traefikv1alpha1.MiddlewareSpec) error {
 	mdlwr := &traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "Middleware",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
 		},
 		Spec: spec,
 	}
+	if err := drift.Stamp(&mdlwr.ObjectMeta, spec); err != nil {
+		return fmt.Errorf("stamp middleware: %w", err)
+	}
 
-	_, err = m.traefikClientSet.Middlewares(namespace).Create(ctx, mdlwr, metav1.CreateOptions{FieldManager: "hub-auth"})
+	_, err := kube.Apply(ctx, m.traefikClientSet.Middlewares(namespace).Patch, name, mdlwr)
 	if err != nil {
-		return fmt.Errorf("create middleware: %w", err)
+		return fmt.Errorf("apply middleware: %w", err)
 	}
 
 	return nil
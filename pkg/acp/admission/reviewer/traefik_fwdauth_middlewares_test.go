@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package reviewer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFwdAuthMiddlewares_Setup_forwardAuth(t *testing.T) {
+	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
+
+	policies := newPolicyGetterMock(t)
+	policies.OnGetConfig("my-policy@test").TypedReturns(&acp.Config{
+		BasicAuth: &basicauth.Config{ForwardUsernameHeader: "User"},
+		ForwardAuth: &acp.ForwardAuthConfig{
+			TrustForwardHeader: true,
+			AuthRequestHeaders: []string{"Authorization"},
+			TLS: &acp.ForwardAuthTLSConfig{
+				CASecret:   "agent-ca",
+				CertSecret: "traefik-client-cert",
+			},
+		},
+	}, nil).Once()
+
+	fwdAuthMdlwrs := NewFwdAuthMiddlewares("https://hub-agent-auth-server", false, policies, traefikClientSet.TraefikV1alpha1())
+
+	name, err := fwdAuthMdlwrs.Setup(context.Background(), "my-policy@test", "test")
+	require.NoError(t, err)
+
+	m, err := traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, &traefikv1alpha1.ForwardAuth{
+		Address:             "https://hub-agent-auth-server/my-policy@test",
+		TrustForwardHeader:  true,
+		AuthResponseHeaders: []string{"User"},
+		AuthRequestHeaders:  []string{"Authorization"},
+		TLS: &traefikv1alpha1.ClientTLS{
+			CASecret:   "agent-ca",
+			CertSecret: "traefik-client-cert",
+		},
+	}, m.Spec.ForwardAuth)
+}
+
+func TestFwdAuthMiddlewares_Setup_longLived(t *testing.T) {
+	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
+
+	policies := newPolicyGetterMock(t)
+	policies.OnGetConfig("my-policy@test").TypedReturns(&acp.Config{
+		BasicAuth: &basicauth.Config{ForwardUsernameHeader: "User"},
+	}, nil).Once()
+
+	fwdAuthMdlwrs := NewFwdAuthMiddlewares("https://hub-agent-auth-server", true, policies, traefikClientSet.TraefikV1alpha1())
+
+	name, err := fwdAuthMdlwrs.Setup(context.Background(), "my-policy@test", "test")
+	require.NoError(t, err)
+
+	m, err := traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), name, metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"User", auth.HeaderConnectionID}, m.Spec.ForwardAuth.AuthResponseHeaders)
+}
+
+func TestFwdAuthMiddlewares_Setup_missingMiddlewareCRD(t *testing.T) {
+	policies := newPolicyGetterMock(t)
+	policies.OnGetConfig("my-policy@test").TypedReturns(&acp.Config{
+		BasicAuth: &basicauth.Config{},
+	}, nil).Once()
+
+	fwdAuthMdlwrs := NewFwdAuthMiddlewares("https://hub-agent-auth-server", false, policies, nil)
+
+	_, err := fwdAuthMdlwrs.Setup(context.Background(), "my-policy@test", "test")
+	assert.ErrorContains(t, err, "Traefik Middleware CRD")
+}
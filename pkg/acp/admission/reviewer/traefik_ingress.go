@@ -25,6 +25,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/ingclass"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const annotationTraefikMiddlewares = "traefik.ingress.kubernetes.io/router.middlewares"
@@ -45,12 +46,17 @@ func NewTraefikIngress(ingClasses IngressClasses, fwdAuthMiddlewares FwdAuthMidd
 	}
 }
 
+// ReviewsKind returns whether this reviewer reviews resources of the given kind.
+func (r TraefikIngress) ReviewsKind(kind metav1.GroupVersionKind) bool {
+	return IsIngress(kind)
+}
+
 // CanReview returns whether this reviewer can handle the given admission review request.
 func (r TraefikIngress) CanReview(ar admv1.AdmissionReview) (bool, error) {
 	resource := ar.Request.Kind
 
 	// Check resource type. Only continue if it's a legacy Ingress (<1.18) or an Ingress resource.
-	if !isNetV1Ingress(resource) && !isNetV1Beta1Ingress(resource) && !isExtV1Beta1Ingress(resource) {
+	if !r.ReviewsKind(resource) {
 		return false, nil
 	}
 
@@ -102,7 +108,7 @@ func (r TraefikIngress) CanReview(ar admv1.AdmissionReview) (bool, error) {
 }
 
 // Review reviews the given admission review request and optionally returns the required patch.
-func (r TraefikIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+func (r TraefikIngress) Review(ctx context.Context, ar admv1.AdmissionReview) ([]map[string]interface{}, error) {
 	l := log.Ctx(ctx).With().Str("reviewer", "TraefikIngress").Logger()
 	ctx = l.WithContext(ctx)
 
@@ -118,8 +124,8 @@ func (r TraefikIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (m
 		return nil, fmt.Errorf("parse raw objects: %w", err)
 	}
 
-	prevPolName := oldIng.Metadata.Annotations[AnnotationHubAuth]
-	polName := ing.Metadata.Annotations[AnnotationHubAuth]
+	prevPolName := PolicyName(oldIng.Metadata.Annotations)
+	polName := PolicyName(ing.Metadata.Annotations)
 
 	if prevPolName == "" && polName == "" {
 		log.Ctx(ctx).Debug().Msg("No ACP defined")
@@ -150,26 +156,27 @@ func (r TraefikIngress) Review(ctx context.Context, ar admv1.AdmissionReview) (m
 		return nil, nil
 	}
 
-	if routerMiddlewares != "" {
-		ing.Metadata.Annotations[annotationTraefikMiddlewares] = routerMiddlewares
-	} else {
-		delete(ing.Metadata.Annotations, annotationTraefikMiddlewares)
-	}
-
 	log.Ctx(ctx).Info().Str("acp_name", polName).Msg("Patching resource")
 
-	return map[string]interface{}{
-		"op":    "replace",
-		"path":  "/metadata/annotations",
-		"value": ing.Metadata.Annotations,
+	// Only the middlewares annotation is patched to avoid conflicting with other mutating webhooks.
+	path := "/metadata/annotations/" + EscapeJSONPointer(annotationTraefikMiddlewares)
+	if routerMiddlewares == "" {
+		return []map[string]interface{}{
+			{"op": "remove", "path": path},
+		}, nil
+	}
+
+	// The "add" operation replaces the annotation value if it already exists.
+	return []map[string]interface{}{
+		{"op": "add", "path": path, "value": routerMiddlewares},
 	}, nil
 }
 
 func (r TraefikIngress) clearPreviousFwdAuthMiddleware(ctx context.Context, polName, namespace, routerMiddlewares string) string {
 	log.Ctx(ctx).Debug().Str("prev_acp_name", polName).Msg("Clearing previous ACP settings")
 
-	middlewareName := middlewareName(polName)
-	oldCanonicalMiddlewareName := fmt.Sprintf("%s-%s@kubernetescrd", namespace, middlewareName)
+	mdlwrName := MiddlewareName(polName)
+	oldCanonicalMiddlewareName := fmt.Sprintf("%s-%s@kubernetescrd", namespace, mdlwrName)
 
 	return removeMiddleware(routerMiddlewares, oldCanonicalMiddlewareName)
 }
@@ -196,8 +203,8 @@ func removeMiddleware(middlewareList, toRemove string) string {
 	return strings.Join(res, ",")
 }
 
-// middlewareName returns the ForwardAuth middleware desc for the given ACP.
-func middlewareName(polName string) string {
+// MiddlewareName returns the name of the ForwardAuth middleware calling the auth server for the given ACP.
+func MiddlewareName(polName string) string {
 	return fmt.Sprintf("zz-%s", strings.ReplaceAll(polName, "@", "-"))
 }
 
@@ -25,6 +25,7 @@ import (
 	"github.com/rs/zerolog/log"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // TraefikIngressRoute is a reviewer that can handle Traefik IngressRoute resources.
@@ -39,16 +40,21 @@ func NewTraefikIngressRoute(fwdAuthMiddlewares FwdAuthMiddlewares) *TraefikIngre
 	}
 }
 
+// ReviewsKind returns whether this reviewer reviews resources of the given kind.
+func (r TraefikIngressRoute) ReviewsKind(kind metav1.GroupVersionKind) bool {
+	return IsIngressRoute(kind)
+}
+
 // CanReview returns whether this reviewer can handle the given admission review request.
 func (r TraefikIngressRoute) CanReview(ar admv1.AdmissionReview) (bool, error) {
 	resource := ar.Request.Kind
 
 	// Check resource type. Only continue if it's an IngressRoute resource.
-	return isTraefikV1Alpha1IngressRoute(resource), nil
+	return r.ReviewsKind(resource), nil
 }
 
 // Review reviews the given admission review request and optionally returns the required patch.
-func (r TraefikIngressRoute) Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error) {
+func (r TraefikIngressRoute) Review(ctx context.Context, ar admv1.AdmissionReview) ([]map[string]interface{}, error) {
 	logger := log.Ctx(ctx).With().Str("reviewer", "TraefikIngressRoute").Logger()
 	ctx = logger.WithContext(ctx)
 
@@ -64,41 +70,80 @@ func (r TraefikIngressRoute) Review(ctx context.Context, ar admv1.AdmissionRevie
 		return nil, fmt.Errorf("parse raw objects: %w", err)
 	}
 
-	prevPolName := oldIngRoute.Annotations[AnnotationHubAuth]
-	polName := ingRoute.Annotations[AnnotationHubAuth]
+	prevPolName := PolicyName(oldIngRoute.Annotations)
+	polName := PolicyName(ingRoute.Annotations)
 	if prevPolName == "" && polName == "" {
 		logger.Debug().Msg("No ACP defined")
 		return nil, nil
 	}
 
-	var updated bool
+	prevMiddlewares := make([][]traefikv1alpha1.MiddlewareRef, len(ingRoute.Spec.Routes))
+	for i, route := range ingRoute.Spec.Routes {
+		prevMiddlewares[i] = route.Middlewares
+	}
+
 	if prevPolName != "" {
-		updated = r.clearPreviousFwdAuthMiddleware(ctx, &ingRoute.Spec, prevPolName, ingRoute.Namespace)
+		r.clearPreviousFwdAuthMiddleware(ctx, &ingRoute.Spec, prevPolName, ingRoute.Namespace)
 	}
 
-	var mdlwrName string
 	if polName != "" {
+		var mdlwrName string
 		mdlwrName, err = r.fwdAuthMiddlewares.Setup(ctx, polName, ingRoute.Namespace)
 		if err != nil {
 			return nil, err
 		}
+
+		updateIngressRoute(&ingRoute.Spec, mdlwrName, ingRoute.Namespace)
 	}
 
-	if !updateIngressRoute(&ingRoute.Spec, mdlwrName, ingRoute.Namespace) && !updated {
+	patches := routeMiddlewaresPatches(prevMiddlewares, ingRoute.Spec.Routes)
+	if len(patches) == 0 {
 		logger.Debug().Str("acp_name", polName).Msg("No patch required")
 		return nil, nil
 	}
 
 	logger.Info().Str("acp_name", polName).Msg("Patching resource")
 
-	return map[string]interface{}{
-		"op":    "replace",
-		"path":  "/spec/routes",
-		"value": ingRoute.Spec.Routes,
-	}, nil
+	return patches, nil
+}
+
+// routeMiddlewaresPatches returns the JSON Patch operations updating the middlewares of the routes which changed.
+// Only the middlewares of these routes are patched to avoid conflicting with other mutating webhooks.
+func routeMiddlewaresPatches(prevMiddlewares [][]traefikv1alpha1.MiddlewareRef, routes []traefikv1alpha1.Route) []map[string]interface{} {
+	var patches []map[string]interface{}
+	for i, route := range routes {
+		if middlewareRefsEqual(prevMiddlewares[i], route.Middlewares) {
+			continue
+		}
+
+		path := fmt.Sprintf("/spec/routes/%d/middlewares", i)
+		if len(route.Middlewares) == 0 {
+			patches = append(patches, map[string]interface{}{"op": "remove", "path": path})
+			continue
+		}
+
+		// The "add" operation replaces the middlewares if the route already has some.
+		patches = append(patches, map[string]interface{}{"op": "add", "path": path, "value": route.Middlewares})
+	}
+
+	return patches
 }
 
-func updateIngressRoute(spec *traefikv1alpha1.IngressRouteSpec, name, namespace string) (updated bool) {
+func middlewareRefsEqual(a, b []traefikv1alpha1.MiddlewareRef) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func updateIngressRoute(spec *traefikv1alpha1.IngressRouteSpec, name, namespace string) {
 	for i, route := range spec.Routes {
 		var found bool
 		for _, middleware := range route.Middlewares {
@@ -113,23 +158,19 @@ func updateIngressRoute(spec *traefikv1alpha1.IngressRouteSpec, name, namespace
 				Namespace: namespace,
 			})
 			spec.Routes[i].Middlewares = route.Middlewares
-			updated = true
 		}
 	}
-
-	return updated
 }
 
-func (r TraefikIngressRoute) clearPreviousFwdAuthMiddleware(ctx context.Context, spec *traefikv1alpha1.IngressRouteSpec, oldPolName, namespace string) (updated bool) {
+func (r TraefikIngressRoute) clearPreviousFwdAuthMiddleware(ctx context.Context, spec *traefikv1alpha1.IngressRouteSpec, oldPolName, namespace string) {
 	log.Ctx(ctx).Debug().Str("prev_acp_name", oldPolName).Msg("Clearing previous ACP settings")
 
-	mdlwrName := middlewareName(oldPolName)
+	mdlwrName := MiddlewareName(oldPolName)
 
 	for i, route := range spec.Routes {
 		var refs []traefikv1alpha1.MiddlewareRef
 		for _, middleware := range route.Middlewares {
 			if middleware.Name == mdlwrName && middleware.Namespace == namespace {
-				updated = true
 				continue
 			}
 			refs = append(refs, middleware)
@@ -137,8 +178,6 @@ func (r TraefikIngressRoute) clearPreviousFwdAuthMiddleware(ctx context.Context,
 
 		spec.Routes[i].Middlewares = refs
 	}
-
-	return updated
 }
 
 // parseRawIngressRoutes parses raw ingressRoutes from admission requests.
@@ -20,7 +20,6 @@ package reviewer
 import (
 	"context"
 	"encoding/json"
-	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +29,7 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
 	admv1 "k8s.io/api/admission/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -121,7 +121,7 @@ func TestTraefikIngressRoute_CanReviewChecksKind(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", nil, nil)
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, nil, nil)
 			review := NewTraefikIngressRoute(fwdAuthMdlwrs)
 
 			var ing netv1.Ingress
@@ -150,7 +150,7 @@ func TestTraefikIngressRoute_ReviewAddsAuthentication(t *testing.T) {
 		config                  *acp.Config
 		oldIng                  traefikv1alpha1.IngressRoute
 		ing                     traefikv1alpha1.IngressRoute
-		wantPatch               []traefikv1alpha1.Route
+		wantPatch               []map[string]interface{}
 		wantAuthResponseHeaders []string
 	}{
 		{
@@ -220,17 +220,11 @@ func TestTraefikIngressRoute_ReviewAddsAuthentication(t *testing.T) {
 					},
 				},
 			},
-			wantPatch: []traefikv1alpha1.Route{
+			wantPatch: []map[string]interface{}{
 				{
-					Match:    "match",
-					Kind:     "kind",
-					Priority: 2,
-					Services: []traefikv1alpha1.Service{
-						{
-							LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{Name: "Name", Namespace: "ns", Kind: "kind"},
-						},
-					},
-					Middlewares: []traefikv1alpha1.MiddlewareRef{
+					"op":   "add",
+					"path": "/spec/routes/0/middlewares",
+					"value": []traefikv1alpha1.MiddlewareRef{
 						{
 							Name:      "custom-middleware",
 							Namespace: "test",
@@ -276,9 +270,11 @@ func TestTraefikIngressRoute_ReviewAddsAuthentication(t *testing.T) {
 					Routes: []traefikv1alpha1.Route{{}},
 				},
 			},
-			wantPatch: []traefikv1alpha1.Route{
+			wantPatch: []map[string]interface{}{
 				{
-					Middlewares: []traefikv1alpha1.MiddlewareRef{
+					"op":   "add",
+					"path": "/spec/routes/0/middlewares",
+					"value": []traefikv1alpha1.MiddlewareRef{
 						{
 							Name:      "zz-my-policy-test",
 							Namespace: "test",
@@ -296,11 +292,12 @@ func TestTraefikIngressRoute_ReviewAddsAuthentication(t *testing.T) {
 			t.Parallel()
 
 			traefikClientSet := traefikkubemock.NewSimpleClientset()
+			kubetest.AddApplyReactor(traefikClientSet)
 
 			policies := newPolicyGetterMock(t)
 			policies.OnGetConfig("my-policy@test").TypedReturns(test.config, nil).Once()
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", policies, traefikClientSet.TraefikV1alpha1())
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, policies, traefikClientSet.TraefikV1alpha1())
 			rev := NewTraefikIngressRoute(fwdAuthMdlwrs)
 
 			oldB, err := json.Marshal(test.oldIng)
@@ -322,24 +319,7 @@ func TestTraefikIngressRoute_ReviewAddsAuthentication(t *testing.T) {
 
 			patch, err := rev.Review(context.Background(), ar)
 			assert.NoError(t, err)
-			assert.NotNil(t, patch)
-
-			assert.Equal(t, 3, len(patch))
-			assert.Equal(t, "replace", patch["op"])
-			assert.Equal(t, "/spec/routes", patch["path"])
-
-			b, err = json.Marshal(patch["value"])
-			require.NoError(t, err)
-
-			var middlewares []traefikv1alpha1.Route
-			err = json.Unmarshal(b, &middlewares)
-			require.NoError(t, err)
-
-			for i, route := range middlewares {
-				if !reflect.DeepEqual(route, test.wantPatch[i]) {
-					t.Fail()
-				}
-			}
+			assert.Equal(t, test.wantPatch, patch)
 
 			m, err := traefikClientSet.TraefikV1alpha1().Middlewares("test").Get(context.Background(), "zz-my-policy-test", metav1.GetOptions{})
 			assert.NoError(t, err)
@@ -393,11 +373,12 @@ func TestTraefikIngressRoute_ReviewUpdatesExistingMiddleware(t *testing.T) {
 				},
 			}
 			traefikClientSet := traefikkubemock.NewSimpleClientset(&middleware)
+			kubetest.AddApplyReactor(traefikClientSet)
 
 			policies := newPolicyGetterMock(t)
 			policies.OnGetConfig("my-policy@test").TypedReturns(test.config, nil).Once()
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", policies, traefikClientSet.TraefikV1alpha1())
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, policies, traefikClientSet.TraefikV1alpha1())
 			rev := NewTraefikIngressRoute(fwdAuthMdlwrs)
 
 			ing := traefikv1alpha1.IngressRoute{
@@ -453,3 +434,75 @@ func TestTraefikIngressRoute_ReviewUpdatesExistingMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestTraefikIngressRoute_ReviewRemovesAuthentication(t *testing.T) {
+	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, newPolicyGetterMock(t), traefikClientSet.TraefikV1alpha1())
+	rev := NewTraefikIngressRoute(fwdAuthMdlwrs)
+
+	routes := []traefikv1alpha1.Route{
+		{
+			Match: "Host(`a.example.com`)",
+			Middlewares: []traefikv1alpha1.MiddlewareRef{
+				{Name: "zz-my-old-policy-test", Namespace: "test"},
+			},
+		},
+		{
+			Match: "Host(`b.example.com`)",
+			Middlewares: []traefikv1alpha1.MiddlewareRef{
+				{Name: "custom-middleware", Namespace: "test"},
+			},
+		},
+		{
+			Match: "Host(`c.example.com`)",
+			Middlewares: []traefikv1alpha1.MiddlewareRef{
+				{Name: "custom-middleware", Namespace: "test"},
+				{Name: "zz-my-old-policy-test", Namespace: "test"},
+			},
+		},
+	}
+
+	oldIngRoute := traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "name",
+			Namespace:   "test",
+			Annotations: map[string]string{AnnotationHubAuth: "my-old-policy@test"},
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{Routes: routes},
+	}
+	oldB, err := json.Marshal(oldIngRoute)
+	require.NoError(t, err)
+
+	ingRoute := traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "test",
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{Routes: routes},
+	}
+	b, err := json.Marshal(ingRoute)
+	require.NoError(t, err)
+
+	ar := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			Object:    runtime.RawExtension{Raw: b},
+			OldObject: runtime.RawExtension{Raw: oldB},
+		},
+	}
+
+	patch, err := rev.Review(context.Background(), ar)
+	require.NoError(t, err)
+
+	// Routes which are not using the ACP middleware are left untouched.
+	wantPatch := []map[string]interface{}{
+		{"op": "remove", "path": "/spec/routes/0/middlewares"},
+		{
+			"op":   "add",
+			"path": "/spec/routes/2/middlewares",
+			"value": []traefikv1alpha1.MiddlewareRef{
+				{Name: "custom-middleware", Namespace: "test"},
+			},
+		},
+	}
+	assert.Equal(t, wantPatch, patch)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
 	admv1 "k8s.io/api/admission/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -126,7 +127,7 @@ func TestTraefikIngress_CanReviewChecksKind(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", nil, nil)
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, nil, nil)
 			review := NewTraefikIngress(ingClasses, fwdAuthMdlwrs)
 
 			var ing netv1.Ingress
@@ -266,7 +267,7 @@ func TestTraefikIngress_CanReviewChecksIngressClass(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", nil, nil)
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, nil, nil)
 
 			var ic IngressClasses
 			if test.ingressClassesMock != nil {
@@ -314,7 +315,7 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 		config                  *acp.Config
 		oldIngAnno              map[string]string
 		ingAnno                 map[string]string
-		wantPatch               map[string]string
+		wantPatch               []map[string]interface{}
 		wantAuthResponseHeaders []string
 	}{
 		{
@@ -334,10 +335,12 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 				"custom-annotation": "foobar",
 				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd",
 			},
-			wantPatch: map[string]string{
-				AnnotationHubAuth:   "my-policy@test",
-				"custom-annotation": "foobar",
-				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+					"value": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+				},
 			},
 			wantAuthResponseHeaders: []string{"fwdHeader"},
 		},
@@ -353,10 +356,12 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 				"custom-annotation": "foobar",
 				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd",
 			},
-			wantPatch: map[string]string{
-				AnnotationHubAuth:   "my-policy@test",
-				"custom-annotation": "foobar",
-				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+					"value": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+				},
 			},
 			wantAuthResponseHeaders: []string{"User", "Authorization"},
 		},
@@ -373,10 +378,12 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 				"custom-annotation": "foobar",
 				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd",
 			},
-			wantPatch: map[string]string{
-				AnnotationHubAuth:   "my-policy@test",
-				"custom-annotation": "foobar",
-				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+					"value": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+				},
 			},
 			wantAuthResponseHeaders: []string{"fwdHeader", "Authorization", "Cookie"},
 		},
@@ -398,10 +405,12 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 				"custom-annotation": "foobar",
 				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd",
 			},
-			wantPatch: map[string]string{
-				AnnotationHubAuth:   "my-policy@test",
-				"custom-annotation": "foobar",
-				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+					"value": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+				},
 			},
 			wantAuthResponseHeaders: []string{"fwdHeader", "Authorization", "Cookie"},
 		},
@@ -414,10 +423,12 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 				"custom-annotation": "foobar",
 				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd",
 			},
-			wantPatch: map[string]string{
-				AnnotationHubAuth:   "my-policy@test",
-				"custom-annotation": "foobar",
-				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+					"value": "custom-middleware@kubernetescrd,test-zz-my-policy-test@kubernetescrd",
+				},
 			},
 			wantAuthResponseHeaders: []string{"User", "Authorization"},
 		},
@@ -429,6 +440,7 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 			t.Parallel()
 
 			traefikClientSet := traefikkubemock.NewSimpleClientset()
+			kubetest.AddApplyReactor(traefikClientSet)
 
 			policies := newPolicyGetterMock(t)
 			if test.config == nil {
@@ -437,7 +449,7 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 				policies.OnGetConfig("my-policy@test").TypedReturns(test.config, nil).Once()
 			}
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", policies, traefikClientSet.TraefikV1alpha1())
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, policies, traefikClientSet.TraefikV1alpha1())
 
 			rev := NewTraefikIngress(newIngressClassesMock(t), fwdAuthMdlwrs)
 
@@ -478,15 +490,7 @@ func TestTraefikIngress_ReviewAddsAuthentication(t *testing.T) {
 
 			patch, err := rev.Review(context.Background(), ar)
 			assert.NoError(t, err)
-			assert.NotNil(t, patch)
-
-			assert.Equal(t, 3, len(patch))
-			assert.Equal(t, "replace", patch["op"])
-			assert.Equal(t, "/metadata/annotations", patch["path"])
-			assert.Equal(t, len(test.wantPatch), len(patch["value"].(map[string]string)))
-			for k := range test.wantPatch {
-				assert.Equal(t, test.wantPatch[k], patch["value"].(map[string]string)[k])
-			}
+			assert.Equal(t, test.wantPatch, patch)
 
 			if test.config == nil {
 				return
@@ -546,11 +550,12 @@ func TestTraefikIngress_ReviewUpdatesExistingMiddleware(t *testing.T) {
 			}
 
 			traefikClientSet := traefikkubemock.NewSimpleClientset(&middleware)
+			kubetest.AddApplyReactor(traefikClientSet)
 
 			policies := newPolicyGetterMock(t)
 			policies.OnGetConfig("my-policy@test").TypedReturns(test.config, nil).Once()
 
-			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", policies, traefikClientSet.TraefikV1alpha1())
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, policies, traefikClientSet.TraefikV1alpha1())
 			rev := NewTraefikIngress(newIngressClassesMock(t), fwdAuthMdlwrs)
 
 			ing := struct {
@@ -590,3 +595,90 @@ func TestTraefikIngress_ReviewUpdatesExistingMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestTraefikIngress_ReviewRemovesAuthentication(t *testing.T) {
+	tests := []struct {
+		desc      string
+		ingAnno   map[string]string
+		wantPatch []map[string]interface{}
+	}{
+		{
+			desc: "keeps other middlewares",
+			ingAnno: map[string]string{
+				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd,test-zz-my-old-policy-test@kubernetescrd",
+			},
+			wantPatch: []map[string]interface{}{
+				{
+					"op":    "add",
+					"path":  "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+					"value": "custom-middleware@kubernetescrd",
+				},
+			},
+		},
+		{
+			desc: "removes the annotation without other middlewares",
+			ingAnno: map[string]string{
+				"traefik.ingress.kubernetes.io/router.middlewares": "test-zz-my-old-policy-test@kubernetescrd",
+			},
+			wantPatch: []map[string]interface{}{
+				{
+					"op":   "remove",
+					"path": "/metadata/annotations/traefik.ingress.kubernetes.io~1router.middlewares",
+				},
+			},
+		},
+		{
+			desc: "no patch without the ACP middleware",
+			ingAnno: map[string]string{
+				"traefik.ingress.kubernetes.io/router.middlewares": "custom-middleware@kubernetescrd",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			traefikClientSet := traefikkubemock.NewSimpleClientset()
+			fwdAuthMdlwrs := NewFwdAuthMiddlewares("", false, newPolicyGetterMock(t), traefikClientSet.TraefikV1alpha1())
+			rev := NewTraefikIngress(newIngressClassesMock(t), fwdAuthMdlwrs)
+
+			oldIng := struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}{
+				Metadata: metav1.ObjectMeta{
+					Name:        "name",
+					Namespace:   "test",
+					Annotations: map[string]string{AnnotationHubAuth: "my-old-policy@test"},
+				},
+			}
+			oldB, err := json.Marshal(oldIng)
+			require.NoError(t, err)
+
+			ing := struct {
+				Metadata metav1.ObjectMeta `json:"metadata"`
+			}{
+				Metadata: metav1.ObjectMeta{
+					Name:        "name",
+					Namespace:   "test",
+					Annotations: test.ingAnno,
+				},
+			}
+			b, err := json.Marshal(ing)
+			require.NoError(t, err)
+
+			ar := admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					Object:    runtime.RawExtension{Raw: b},
+					OldObject: runtime.RawExtension{Raw: oldB},
+				},
+			}
+
+			patch, err := rev.Review(context.Background(), ar)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantPatch, patch)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	admv1 "k8s.io/api/admission/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// ServicePolicies gets the ACPs set on Services with the reviewer.AnnotationHubAuth annotation.
+type ServicePolicies struct {
+	services corev1listers.ServiceLister
+}
+
+// NewServicePolicies returns a new ServicePolicies.
+func NewServicePolicies(services corev1listers.ServiceLister) *ServicePolicies {
+	return &ServicePolicies{services: services}
+}
+
+// PolicyName returns the ACP set on the given Services of the given namespace, an empty string if none of them have
+// one. An error is returned if the Services have different ACPs.
+func (p ServicePolicies) PolicyName(namespace string, names []string) (string, error) {
+	var polName, polService string
+	for _, name := range names {
+		service, err := p.services.Services(namespace).Get(name)
+		if kerror.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("get service %q: %w", name, err)
+		}
+
+		svcPolName := service.Annotations[reviewer.AnnotationHubAuth]
+		if svcPolName == "" || svcPolName == polName {
+			continue
+		}
+
+		if polName != "" {
+			return "", fmt.Errorf("services %q and %q have different ACPs %q and %q", polService, name, polName, svcPolName)
+		}
+
+		polName, polService = svcPolName, name
+	}
+
+	return polName, nil
+}
+
+// servicePolicyPatch sets the reviewer.AnnotationServiceHubAuth annotation of the object of the given admission request
+// to the ACP of the Services it targets. It returns the updated request along with the corresponding patch, nil if the
+// annotation is already up-to-date.
+func servicePolicyPatch(policies *ServicePolicies, req *admv1.AdmissionRequest) (*admv1.AdmissionRequest, map[string]interface{}, error) {
+	names, err := reviewer.ServiceNames(req.Kind, req.Object.Raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get targeted services: %w", err)
+	}
+
+	polName, err := policies.PolicyName(req.Namespace, names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var obj map[string]interface{}
+	if err = json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal object: %w", err)
+	}
+
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, nil, nil
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+
+	if current, _ := annotations[reviewer.AnnotationServiceHubAuth].(string); current == polName {
+		return nil, nil, nil
+	}
+
+	var patch map[string]interface{}
+	path := "/metadata/annotations/" + reviewer.EscapeJSONPointer(reviewer.AnnotationServiceHubAuth)
+	switch {
+	case polName == "":
+		delete(annotations, reviewer.AnnotationServiceHubAuth)
+		patch = map[string]interface{}{"op": "remove", "path": path}
+	case annotations == nil:
+		metadata["annotations"] = map[string]interface{}{reviewer.AnnotationServiceHubAuth: polName}
+		patch = map[string]interface{}{
+			"op":    "add",
+			"path":  "/metadata/annotations",
+			"value": map[string]string{reviewer.AnnotationServiceHubAuth: polName},
+		}
+	default:
+		annotations[reviewer.AnnotationServiceHubAuth] = polName
+		patch = map[string]interface{}{"op": "add", "path": path, "value": polName}
+	}
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal object: %w", err)
+	}
+
+	updatedReq := *req
+	updatedReq.Object.Raw = raw
+
+	return &updatedReq, patch, nil
+}
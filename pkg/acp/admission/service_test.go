@@ -0,0 +1,216 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	admv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestServicePolicies_PolicyName(t *testing.T) {
+	policies := NewServicePolicies(newServiceLister(t,
+		newService("whoami", "acp"),
+		newService("api", "acp"),
+		newService("admin", "admin-acp"),
+		newService("public", ""),
+	))
+
+	polName, err := policies.PolicyName("ns", []string{"public", "unknown", "whoami", "api"})
+	require.NoError(t, err)
+	assert.Equal(t, "acp", polName)
+
+	polName, err = policies.PolicyName("ns", []string{"public"})
+	require.NoError(t, err)
+	assert.Equal(t, "", polName)
+
+	_, err = policies.PolicyName("ns", []string{"whoami", "admin"})
+	assert.EqualError(t, err, `services "whoami" and "admin" have different ACPs "acp" and "admin-acp"`)
+}
+
+func TestServicePolicyPatch(t *testing.T) {
+	tests := []struct {
+		desc            string
+		annotations     map[string]string
+		service         string
+		wantPatch       map[string]interface{}
+		wantAnnotations map[string]string
+	}{
+		{
+			desc:      "adds the annotations",
+			service:   "whoami",
+			wantPatch: map[string]interface{}{"op": "add", "path": "/metadata/annotations", "value": map[string]string{reviewer.AnnotationServiceHubAuth: "acp"}},
+			wantAnnotations: map[string]string{
+				reviewer.AnnotationServiceHubAuth: "acp",
+			},
+		},
+		{
+			desc:        "sets the annotation",
+			annotations: map[string]string{"foo": "bar", reviewer.AnnotationServiceHubAuth: "old-acp"},
+			service:     "whoami",
+			wantPatch: map[string]interface{}{
+				"op":    "add",
+				"path":  "/metadata/annotations/hub.traefik.io~1service-access-control-policy",
+				"value": "acp",
+			},
+			wantAnnotations: map[string]string{
+				"foo":                             "bar",
+				reviewer.AnnotationServiceHubAuth: "acp",
+			},
+		},
+		{
+			desc:        "removes the annotation",
+			annotations: map[string]string{"foo": "bar", reviewer.AnnotationServiceHubAuth: "acp"},
+			service:     "public",
+			wantPatch: map[string]interface{}{
+				"op":   "remove",
+				"path": "/metadata/annotations/hub.traefik.io~1service-access-control-policy",
+			},
+			wantAnnotations: map[string]string{"foo": "bar"},
+		},
+		{
+			desc:        "annotation up-to-date",
+			annotations: map[string]string{reviewer.AnnotationServiceHubAuth: "acp"},
+			service:     "whoami",
+		},
+	}
+
+	policies := NewServicePolicies(newServiceLister(t,
+		newService("whoami", "acp"),
+		newService("public", ""),
+	))
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ing := newIngress("ingress", test.annotations, test.service)
+			raw, err := json.Marshal(ing)
+			require.NoError(t, err)
+
+			req := &admv1.AdmissionRequest{
+				Kind:      netV1IngressKind,
+				Namespace: "ns",
+				Object:    runtime.RawExtension{Raw: raw},
+			}
+
+			gotReq, patch, err := servicePolicyPatch(policies, req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantPatch, patch)
+			if test.wantPatch == nil {
+				assert.Nil(t, gotReq)
+				return
+			}
+
+			// The reviewed request is left untouched.
+			assert.Equal(t, raw, req.Object.Raw)
+
+			var gotIng netv1.Ingress
+			require.NoError(t, json.Unmarshal(gotReq.Object.Raw, &gotIng))
+			assert.Equal(t, test.wantAnnotations, gotIng.Annotations)
+		})
+	}
+}
+
+func TestServiceUpdater(t *testing.T) {
+	kubeClientSet := kubemock.NewSimpleClientset(
+		newIngress("unprotected", nil, "whoami"),
+		newIngress("protected", map[string]string{reviewer.AnnotationServiceHubAuth: "acp"}, "whoami"),
+		newIngress("public", nil, "public"),
+	)
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Networking().V1().Ingresses().Informer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	updater := NewServiceUpdater(kubeInformer, kubeClientSet, nil, true)
+	go updater.Run(ctx)
+
+	updater.OnUpdate(newService("whoami", ""), newService("whoami", "acp"))
+	// Unchanged ACPs don't trigger any update.
+	updater.OnUpdate(newService("public", ""), newService("public", ""))
+
+	assert.Eventually(t, func() bool {
+		var updated []string
+		for _, action := range kubeClientSet.Actions() {
+			if updateAction, ok := action.(ktesting.UpdateAction); ok {
+				updated = append(updated, updateAction.GetObject().(*netv1.Ingress).Name)
+			}
+		}
+
+		return assert.ObjectsAreEqual([]string{"unprotected"}, updated)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func newServiceLister(t *testing.T, services ...*corev1.Service) corev1listers.ServiceLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, service := range services {
+		require.NoError(t, indexer.Add(service))
+	}
+
+	return corev1listers.NewServiceLister(indexer)
+}
+
+func newService(name, polName string) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+	}
+	if polName != "" {
+		service.Annotations = map[string]string{reviewer.AnnotationHubAuth: polName}
+	}
+
+	return service
+}
+
+func newIngress(name string, annotations map[string]string, service string) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "ns",
+			Annotations: annotations,
+		},
+		Spec: netv1.IngressSpec{
+			DefaultBackend: &netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{Name: service},
+			},
+		},
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	traefikclient "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	netV1IngressKind        = metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+	netV1Beta1IngressKind   = metav1.GroupVersionKind{Group: "networking.k8s.io", Version: "v1beta1", Kind: "Ingress"}
+	traefikIngressRouteKind = metav1.GroupVersionKind{Group: "traefik.containo.us", Version: "v1alpha1", Kind: "IngressRoute"}
+)
+
+// serviceEvent is a change of the ACP of a Service.
+type serviceEvent struct {
+	namespace string
+	name      string
+	polName   string
+}
+
+// ServiceUpdater updates the Ingresses and IngressRoutes targeting a Service when its ACP changes, for them to be
+// reviewed again and protected by the new ACP of the Service.
+type ServiceUpdater struct {
+	informer         informers.SharedInformerFactory
+	clientSet        clientset.Interface
+	traefikClientSet traefikclient.TraefikV1alpha1Interface
+
+	eventCh chan serviceEvent
+
+	supportsNetV1Ingresses bool
+}
+
+// NewServiceUpdater returns a new ServiceUpdater. IngressRoutes are not updated if traefikClientSet is nil.
+func NewServiceUpdater(informer informers.SharedInformerFactory, clientSet clientset.Interface, traefikClientSet traefikclient.TraefikV1alpha1Interface, netV1Ingresses bool) *ServiceUpdater {
+	return &ServiceUpdater{
+		informer:               informer,
+		clientSet:              clientSet,
+		traefikClientSet:       traefikClientSet,
+		eventCh:                make(chan serviceEvent),
+		supportsNetV1Ingresses: netV1Ingresses,
+	}
+}
+
+// Run runs the ServiceUpdater control loop, updating the resources targeting Services whose ACP changed.
+func (u *ServiceUpdater) Run(ctx context.Context) {
+	for {
+		select {
+		case event := <-u.eventCh:
+			if err := u.updateResources(ctx, event); err != nil {
+				log.Error().Err(err).
+					Str("service_name", event.name).
+					Str("service_namespace", event.namespace).
+					Msg("Unable to update resources targeting service")
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// OnAdd implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
+func (u *ServiceUpdater) OnAdd(obj interface{}) {
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		log.Error().
+			Str("component", "service_updater").
+			Str("type", fmt.Sprintf("%T", obj)).
+			Msg("Received add event of unknown type")
+		return
+	}
+
+	if polName := service.Annotations[reviewer.AnnotationHubAuth]; polName != "" {
+		u.eventCh <- serviceEvent{namespace: service.Namespace, name: service.Name, polName: polName}
+	}
+}
+
+// OnUpdate implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
+func (u *ServiceUpdater) OnUpdate(oldObj, newObj interface{}) {
+	oldService, ok := oldObj.(*corev1.Service)
+	if !ok {
+		log.Error().
+			Str("component", "service_updater").
+			Str("type", fmt.Sprintf("%T", oldObj)).
+			Msg("Received update event of unknown type (old)")
+		return
+	}
+
+	newService, ok := newObj.(*corev1.Service)
+	if !ok {
+		log.Error().
+			Str("component", "service_updater").
+			Str("type", fmt.Sprintf("%T", newObj)).
+			Msg("Received update event of unknown type (new)")
+		return
+	}
+
+	polName := newService.Annotations[reviewer.AnnotationHubAuth]
+	if polName == oldService.Annotations[reviewer.AnnotationHubAuth] {
+		return
+	}
+
+	u.eventCh <- serviceEvent{namespace: newService.Namespace, name: newService.Name, polName: polName}
+}
+
+// OnDelete implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
+func (u *ServiceUpdater) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	service, ok := obj.(*corev1.Service)
+	if !ok {
+		log.Error().
+			Str("component", "service_updater").
+			Str("type", fmt.Sprintf("%T", obj)).
+			Msg("Received delete event of unknown type")
+		return
+	}
+
+	if service.Annotations[reviewer.AnnotationHubAuth] != "" {
+		u.eventCh <- serviceEvent{namespace: service.Namespace, name: service.Name}
+	}
+}
+
+func (u *ServiceUpdater) updateResources(ctx context.Context, event serviceEvent) error {
+	if u.supportsNetV1Ingresses {
+		if err := u.updateV1Ingresses(ctx, event); err != nil {
+			return err
+		}
+	} else if err := u.updateV1beta1Ingresses(ctx, event); err != nil {
+		return err
+	}
+
+	if u.traefikClientSet == nil {
+		return nil
+	}
+
+	return u.updateIngressRoutes(ctx, event)
+}
+
+func (u *ServiceUpdater) updateV1Ingresses(ctx context.Context, event serviceEvent) error {
+	ingList, err := u.informer.Networking().V1().Ingresses().Lister().Ingresses(event.namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list ingresses: %w", err)
+	}
+
+	for _, ing := range ingList {
+		ok, err := targetsService(netV1IngressKind, ing, ing.Annotations, event)
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to determine if ingress should be updated")
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		_, err = u.clientSet.NetworkingV1().Ingresses(ing.Namespace).Update(ctx, ing, metav1.UpdateOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to update ingress")
+			continue
+		}
+	}
+
+	return nil
+}
+
+func (u *ServiceUpdater) updateV1beta1Ingresses(ctx context.Context, event serviceEvent) error {
+	ingList, err := u.informer.Networking().V1beta1().Ingresses().Lister().Ingresses(event.namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("list legacy ingresses: %w", err)
+	}
+
+	for _, ing := range ingList {
+		ok, err := targetsService(netV1Beta1IngressKind, ing, ing.Annotations, event)
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to determine if legacy ingress should be updated")
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		_, err = u.clientSet.NetworkingV1beta1().Ingresses(ing.Namespace).Update(ctx, ing, metav1.UpdateOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to update legacy ingress")
+			continue
+		}
+	}
+
+	return nil
+}
+
+func (u *ServiceUpdater) updateIngressRoutes(ctx context.Context, event serviceEvent) error {
+	ingRouteList, err := u.traefikClientSet.IngressRoutes(event.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list ingress routes: %w", err)
+	}
+
+	for i := range ingRouteList.Items {
+		ingRoute := &ingRouteList.Items[i]
+
+		ok, err := targetsService(traefikIngressRouteKind, ingRoute, ingRoute.Annotations, event)
+		if err != nil {
+			log.Error().Err(err).Str("ingress_route_name", ingRoute.Name).Str("ingress_route_namespace", ingRoute.Namespace).Msg("Unable to determine if ingress route should be updated")
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		_, err = u.traefikClientSet.IngressRoutes(ingRoute.Namespace).Update(ctx, ingRoute, metav1.UpdateOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("ingress_route_name", ingRoute.Name).Str("ingress_route_namespace", ingRoute.Namespace).Msg("Unable to update ingress route")
+			continue
+		}
+	}
+
+	return nil
+}
+
+// targetsService returns whether the given resource targets the Service of the given event and is not yet protected by
+// its new ACP.
+func targetsService(kind metav1.GroupVersionKind, obj interface{}, annotations map[string]string, event serviceEvent) (bool, error) {
+	if annotations[reviewer.AnnotationServiceHubAuth] == event.polName {
+		return false, nil
+	}
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return false, fmt.Errorf("marshal resource: %w", err)
+	}
+
+	names, err := reviewer.ServiceNames(kind, raw)
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range names {
+		if name == event.name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -23,9 +23,10 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
-	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 )
@@ -34,6 +35,8 @@ import (
 type IngressUpdater struct {
 	informer  informers.SharedInformerFactory
 	clientSet clientset.Interface
+	// routeClient is only set when OpenShift Routes are updated along with the Ingresses.
+	routeClient dynamic.Interface
 
 	cancelUpd map[string]context.CancelFunc
 
@@ -42,14 +45,15 @@ type IngressUpdater struct {
 	supportsNetV1Ingresses bool
 }
 
-// NewIngressUpdater return a new IngressUpdater.
-func NewIngressUpdater(informer informers.SharedInformerFactory, clientSet clientset.Interface, kubeVersion string) *IngressUpdater {
+// NewIngressUpdater return a new IngressUpdater. OpenShift Routes are not updated if routeClient is nil.
+func NewIngressUpdater(informer informers.SharedInformerFactory, clientSet clientset.Interface, routeClient dynamic.Interface, netV1Ingresses bool) *IngressUpdater {
 	return &IngressUpdater{
 		informer:               informer,
 		clientSet:              clientSet,
+		routeClient:            routeClient,
 		cancelUpd:              map[string]context.CancelFunc{},
 		polNameCh:              make(chan string),
-		supportsNetV1Ingresses: kubevers.SupportsNetV1Ingresses(kubeVersion),
+		supportsNetV1Ingresses: netV1Ingresses,
 	}
 }
 
@@ -86,11 +90,20 @@ func (u *IngressUpdater) Update(polName string) {
 }
 
 func (u *IngressUpdater) updateIngresses(ctx context.Context, polName string) error {
+	updateIngresses := u.updateV1Ingresses
 	if !u.supportsNetV1Ingresses {
-		return u.updateV1beta1Ingresses(ctx, polName)
+		updateIngresses = u.updateV1beta1Ingresses
 	}
 
-	return u.updateV1Ingresses(ctx, polName)
+	if err := updateIngresses(ctx, polName); err != nil {
+		return err
+	}
+
+	if u.routeClient == nil {
+		return nil
+	}
+
+	return u.updateRoutes(ctx, polName)
 }
 
 func (u *IngressUpdater) updateV1Ingresses(ctx context.Context, polName string) error {
@@ -110,7 +123,7 @@ func (u *IngressUpdater) updateV1Ingresses(ctx context.Context, polName string)
 		default:
 		}
 
-		ok := shouldUpdate(ing.Annotations[reviewer.AnnotationHubAuth], polName)
+		ok := shouldUpdate(reviewer.PolicyName(ing.Annotations), polName)
 		if err != nil {
 			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to determine if ingress should be updated")
 			continue
@@ -146,7 +159,7 @@ func (u *IngressUpdater) updateV1beta1Ingresses(ctx context.Context, polName str
 		default:
 		}
 
-		ok := shouldUpdate(ing.Annotations[reviewer.AnnotationHubAuth], polName)
+		ok := shouldUpdate(reviewer.PolicyName(ing.Annotations), polName)
 		if err != nil {
 			log.Error().Err(err).Str("ingress_name", ing.Name).Str("ingress_namespace", ing.Namespace).Msg("Unable to determine if legacy ingress should be updated")
 			continue
@@ -164,6 +177,41 @@ func (u *IngressUpdater) updateV1beta1Ingresses(ctx context.Context, polName str
 	return nil
 }
 
+func (u *IngressUpdater) updateRoutes(ctx context.Context, polName string) error {
+	routes := u.routeClient.Resource(openshift.RouteResource)
+
+	routeList, err := routes.Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list routes: %w", err)
+	}
+
+	log.Debug().Int("route_number", len(routeList.Items)).Msg("Updating routes")
+
+	for i := range routeList.Items {
+		route := &routeList.Items[i]
+
+		// Don't continue if the context was canceled to prevent being spammed
+		// with context canceled errors on every request we would send otherwise.
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		if !shouldUpdate(reviewer.PolicyName(route.GetAnnotations()), polName) {
+			continue
+		}
+
+		_, err = routes.Namespace(route.GetNamespace()).Update(ctx, route, metav1.UpdateOptions{FieldManager: "hub-auth"})
+		if err != nil {
+			log.Error().Err(err).Str("route_name", route.GetName()).Str("route_namespace", route.GetNamespace()).Msg("Unable to update route")
+			continue
+		}
+	}
+
+	return nil
+}
+
 func shouldUpdate(hubAuthAnno, polName string) bool {
 	if hubAuthAnno == "" {
 		return false
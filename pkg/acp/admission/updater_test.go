@@ -0,0 +1,83 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package admission
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestIngressUpdater_updatesRoutes(t *testing.T) {
+	newRoute := func(name, polName string) *unstructured.Unstructured {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(openshift.RouteKind)
+		route.SetName(name)
+		route.SetNamespace("ns")
+		if polName != "" {
+			route.SetAnnotations(map[string]string{"hub.traefik.io/access-control-policy": polName})
+		}
+
+		return route
+	}
+
+	kubeClient := kubemock.NewSimpleClientset()
+	kubeInformer := informers.NewSharedInformerFactory(kubeClient, 5*time.Minute)
+	kubeInformer.Networking().V1().Ingresses().Informer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	routeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{openshift.RouteResource: "RouteList"},
+		newRoute("protected", "my-acp"),
+		newRoute("other", "other-acp"),
+		newRoute("public", ""),
+	)
+
+	updater := NewIngressUpdater(kubeInformer, kubeClient, routeClient, true)
+
+	err := updater.updateIngresses(ctx, "my-acp")
+	require.NoError(t, err)
+
+	var updated []string
+	for _, action := range routeClient.Actions() {
+		if updateAction, ok := action.(ktesting.UpdateAction); ok {
+			obj, ok := updateAction.GetObject().(*unstructured.Unstructured)
+			require.True(t, ok)
+
+			updated = append(updated, obj.GetName())
+		}
+	}
+
+	assert.Equal(t, []string{"protected"}, updated)
+}
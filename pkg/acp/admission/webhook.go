@@ -34,20 +34,27 @@ import (
 // Reviewer allows to review an admission review request.
 type Reviewer interface {
 	CanReview(ar admv1.AdmissionReview) (bool, error)
-	Review(ctx context.Context, ar admv1.AdmissionReview) (map[string]interface{}, error)
+	Review(ctx context.Context, ar admv1.AdmissionReview) ([]map[string]interface{}, error)
 }
 
 // Handler is an HTTP handler that can be used as a Kubernetes Mutating Admission Controller.
 type Handler struct {
-	reviewers       []Reviewer
+	reviewers       *Registry
 	defaultReviewer Reviewer
+	services        *ServicePolicies
+	auditor         *Auditor
 }
 
-// NewHandler returns a new Handler that reviews incoming requests using the given reviewers.
-func NewHandler(reviewers []Reviewer, defaultReviewer Reviewer) *Handler {
+// NewHandler returns a new Handler that reviews incoming requests using the reviewers of the given registry. The
+// default reviewer reviews the requests none of the registered reviewers can review. When services is not nil,
+// resources targeting Services having an ACP are protected by this ACP. When auditor is not nil, every mutation is
+// recorded by this auditor.
+func NewHandler(reviewers *Registry, defaultReviewer Reviewer, services *ServicePolicies, auditor *Auditor) *Handler {
 	return &Handler{
 		reviewers:       reviewers,
 		defaultReviewer: defaultReviewer,
+		services:        services,
+		auditor:         auditor,
 	}
 }
 
@@ -105,6 +112,10 @@ func (h Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			t := admv1.PatchTypeJSONPatch
 			ar.Response.PatchType = &t
 			ar.Response.Patch = resp.Patch
+
+			if h.auditor != nil && (ar.Request.DryRun == nil || !*ar.Request.DryRun) {
+				h.auditor.Record(ctx, AuditReasonAdmission, ar.Request, resp.Patch)
+			}
 		}
 	}
 
@@ -123,6 +134,21 @@ type reviewResponse struct {
 func (h Handler) review(ctx context.Context, ar admv1.AdmissionReview) (*reviewResponse, error) {
 	var resp reviewResponse
 
+	var patches []map[string]interface{}
+	if h.services != nil && ar.Request.Operation != admv1.Delete {
+		req, patch, err := servicePolicyPatch(h.services, ar.Request)
+		switch {
+		case err != nil:
+			// The ACP of the resource is left unchanged to keep protecting it.
+			log.Ctx(ctx).Warn().Err(err).Msg("Unable to get the ACP of the targeted services")
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("unable to get the ACP of the services targeted by resource %q of kind %q in namespace %q: %v",
+				ar.Request.Name, ar.Request.Kind, ar.Request.Namespace, err))
+		case patch != nil:
+			ar.Request = req
+			patches = append(patches, patch)
+		}
+	}
+
 	usesACP, err := isUsingACP(ar)
 	if err != nil {
 		return nil, fmt.Errorf("unable to determine if resource uses ACP: %w", err)
@@ -131,7 +157,7 @@ func (h Handler) review(ctx context.Context, ar admv1.AdmissionReview) (*reviewR
 		return &resp, nil
 	}
 
-	rev, revErr := findReviewer(h.reviewers, ar)
+	rev, revErr := h.reviewers.find(ctx, ar)
 	if revErr != nil {
 		return nil, fmt.Errorf("find reviewer: %w", revErr)
 	}
@@ -147,16 +173,17 @@ func (h Handler) review(ctx context.Context, ar admv1.AdmissionReview) (*reviewR
 			ar.Request.Name, ar.Request.Kind, ar.Request.Namespace))
 	}
 
-	resourcePatch, err := rev.Review(ctx, ar)
+	revPatches, err := rev.Review(ctx, ar)
 	if err != nil {
 		return nil, fmt.Errorf("reviewing resource %q of kind %q in namespace %q: %w", ar.Request.Name, ar.Request.Kind, ar.Request.Namespace, err)
 	}
+	patches = append(patches, revPatches...)
 
-	if resourcePatch == nil {
+	if len(patches) == 0 {
 		return &resp, nil
 	}
 
-	resp.Patch, err = json.Marshal([]map[string]interface{}{resourcePatch})
+	resp.Patch, err = json.Marshal(patches)
 	if err != nil {
 		return nil, fmt.Errorf("serialize patches: %w", err)
 	}
@@ -164,27 +191,6 @@ func (h Handler) review(ctx context.Context, ar admv1.AdmissionReview) (*reviewR
 	return &resp, nil
 }
 
-func findReviewer(reviewers []Reviewer, ar admv1.AdmissionReview) (Reviewer, error) {
-	var rev Reviewer
-	for _, r := range reviewers {
-		ok, err := r.CanReview(ar)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			if rev != nil {
-				// This can only happen if reviewers' CanReview method overlap.
-				// It does not depend on user input.
-				return nil, fmt.Errorf("more than one reviewer found (at least %T and %T)", rev, r)
-			}
-
-			rev = r
-		}
-	}
-
-	return rev, nil
-}
-
 func isUsingACP(ar admv1.AdmissionReview) (bool, error) {
 	var obj struct {
 		Metadata metav1.ObjectMeta `json:"metadata"`
@@ -194,7 +200,7 @@ func isUsingACP(ar admv1.AdmissionReview) (bool, error) {
 		if err := json.Unmarshal(ar.Request.Object.Raw, &obj); err != nil {
 			return false, err
 		}
-		polName = obj.Metadata.Annotations[reviewer.AnnotationHubAuth]
+		polName = reviewer.PolicyName(obj.Metadata.Annotations)
 	}
 
 	var oldObj struct {
@@ -205,7 +211,7 @@ func isUsingACP(ar admv1.AdmissionReview) (bool, error) {
 		if err := json.Unmarshal(ar.Request.OldObject.Raw, &oldObj); err != nil {
 			return false, err
 		}
-		prevPolName = oldObj.Metadata.Annotations[reviewer.AnnotationHubAuth]
+		prevPolName = reviewer.PolicyName(oldObj.Metadata.Annotations)
 	}
 
 	if polName == "" && prevPolName == "" {
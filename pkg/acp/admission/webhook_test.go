@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -120,8 +121,8 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 				reviewer := newReviewerMock(t)
 				reviewer.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
 				reviewer.OnReviewRaw(mock.Anything).TypedReturns(
-					map[string]interface{}{
-						"value": "add-acp",
+					[]map[string]interface{}{
+						{"value": "add-acp"},
 					}, nil).Once()
 
 				return []Reviewer{reviewer}, nil
@@ -145,8 +146,8 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 				reviewer := newReviewerMock(t)
 				reviewer.OnCanReviewRaw(mock.Anything).TypedReturns(true, nil).Once()
 				reviewer.OnReviewRaw(mock.Anything).TypedReturns(
-					map[string]interface{}{
-						"value": "remove-acp",
+					[]map[string]interface{}{
+						{"value": "remove-acp"},
 					}, nil).Once()
 
 				return []Reviewer{reviewer}, nil
@@ -210,7 +211,7 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 
 				defaultReviewer := newReviewerMock(t)
 				defaultReviewer.OnReviewRaw(mock.Anything).
-					TypedReturns(map[string]interface{}{"value": "add-acp"}, nil).
+					TypedReturns([]map[string]interface{}{{"value": "add-acp"}}, nil).
 					Once()
 
 				return []Reviewer{reviewer}, defaultReviewer
@@ -247,7 +248,7 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 				Allowed: false,
 				Result: &metav1.Status{
 					Status:  "Failure",
-					Message: "find reviewer: boom",
+					Message: `find reviewer: reviewer "reviewer-0": boom`,
 				},
 			},
 		},
@@ -266,7 +267,13 @@ func TestWebhook_ServeHTTP(t *testing.T) {
 			require.NoError(t, err)
 
 			reviewers, defaultReviewer := test.reviewers(t)
-			h := NewHandler(reviewers, defaultReviewer)
+
+			registry := NewRegistry()
+			for i, reviewer := range reviewers {
+				require.NoError(t, registry.Register(fmt.Sprintf("reviewer-%d", i), reviewer, 0))
+			}
+
+			h := NewHandler(registry, defaultReviewer, nil, nil)
 
 			rec := httptest.NewRecorder()
 			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", bytes.NewBuffer(b))
@@ -0,0 +1,244 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apitoken
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubv1alpha1lister "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Default headers holding the identity of the consumer owning a valid API token.
+const (
+	HeaderEmail  = "Hub-Email"
+	HeaderGroups = "Hub-Groups"
+)
+
+// IdentityHeaders are the names of the headers holding the identity of the consumer owning a valid API token. Headers
+// with an empty name are not forwarded.
+type IdentityHeaders struct {
+	User   string
+	Email  string
+	Groups string
+}
+
+// GatewayIdentityHeaders returns the identity headers configured on the given gateway, defaulting to HeaderEmail and
+// HeaderGroups.
+func GatewayIdentityHeaders(gateway *hubv1alpha1.APIGateway) IdentityHeaders {
+	hdrs := IdentityHeaders{Email: HeaderEmail, Groups: HeaderGroups}
+
+	cfg := gateway.Spec.IdentityHeaders
+	if cfg == nil {
+		return hdrs
+	}
+
+	if cfg.User != "" {
+		hdrs.User = http.CanonicalHeaderKey(cfg.User)
+	}
+	if cfg.Email != "" {
+		hdrs.Email = http.CanonicalHeaderKey(cfg.Email)
+	}
+	if cfg.Groups != "" {
+		hdrs.Groups = http.CanonicalHeaderKey(cfg.Groups)
+	}
+
+	return hdrs
+}
+
+// Names returns the names of the headers to forward.
+func (h IdentityHeaders) Names() []string {
+	var names []string
+	for _, name := range []string{h.User, h.Email, h.Groups} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// set sets the identity of the given consumer on the given headers.
+func (h IdentityHeaders) set(hdr http.Header, consumer *Consumer) {
+	if h.User != "" {
+		hdr.Set(h.User, consumer.Email)
+	}
+	if h.Email != "" {
+		hdr.Set(h.Email, consumer.Email)
+	}
+	if h.Groups != "" {
+		hdr.Set(h.Groups, strings.Join(consumer.Groups, ","))
+	}
+}
+
+// PathPrefix is the auth server path prefix under which the Handler is served. ACP names, being valid Kubernetes
+// resource names, can't start with an underscore, hence the handler routes can't collide with the ACP ones.
+const PathPrefix = "/_gateways"
+
+// QueryParameter is the query parameter from which API tokens are read, when the gateway allows it.
+const QueryParameter = "api_key"
+
+// cacheSize is the maximum number of cached validation results.
+const cacheSize = 10000
+
+// Consumer is the consumer owning an API token.
+type Consumer struct {
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+}
+
+// Validator validates API tokens.
+type Validator interface {
+	// ValidateAPIToken returns the consumer owning the given token if it grants access to the given gateway, nil
+	// otherwise.
+	ValidateAPIToken(ctx context.Context, gateway, token string) (*Consumer, error)
+}
+
+// Handler is an ACP handler validating the API tokens, issued through the dev portals, of the requests sent to the
+// APIs of a gateway. The gateway is identified by the request path.
+type Handler struct {
+	validator   Validator
+	revocations *RevocationList
+	gateways    hubv1alpha1lister.APIGatewayLister
+	cacheTTL    time.Duration
+	now         func() time.Time
+
+	cache *validationCache
+}
+
+// NewHandler creates a new Handler. Validation results, valid or not, are cached for the given TTL, up to cacheSize
+// results, the least recently used ones being evicted first. Tokens part of
+// the given revocation list, if any, are rejected regardless of their cached validation result. When the validator is
+// unavailable, the last known validation result of a token is used even if it has expired.
+func NewHandler(validator Validator, revocations *RevocationList, gateways hubv1alpha1lister.APIGatewayLister, cacheTTL time.Duration) *Handler {
+	return &Handler{
+		validator:   validator,
+		revocations: revocations,
+		gateways:    gateways,
+		cacheTTL:    cacheTTL,
+		now:         time.Now,
+		cache:       newValidationCache(cacheSize),
+	}
+}
+
+// ServeHTTP serves an HTTP request.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	gatewayName := strings.Trim(req.URL.Path, "/")
+
+	l := log.With().Str("handler_type", "APIToken").Str("gateway_name", gatewayName).Logger()
+
+	gateway, err := h.gateways.Get(gatewayName)
+	if err != nil {
+		if kerror.IsNotFound(err) {
+			l.Debug().Msg("APIGateway not found")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		l.Error().Err(err).Msg("Unable to get APIGateway")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	tok, err := token.Extract(req, tokenSource(gateway.Spec.CredentialLocations))
+	if tok == "" {
+		l.Debug().Err(err).Msg("No token found in request")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	tokenHash := hashToken(tok)
+	if reason, revoked := h.revocations.Revoked(tokenHash); revoked {
+		l.Debug().Str("reason", reason).Msg("Revoked token")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	consumer, err := h.validate(req.Context(), gatewayName, tok, tokenHash)
+	if err != nil {
+		l.Error().Err(err).Msg("Unable to validate token")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if consumer == nil {
+		l.Debug().Msg("Invalid token")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	GatewayIdentityHeaders(gateway).set(rw.Header(), consumer)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// validate validates the given token against the platform, unless its validation result is cached.
+func (h *Handler) validate(ctx context.Context, gateway, tok, tokenHash string) (*Consumer, error) {
+	// Only a hash of the token is kept in memory.
+	key := gateway + "/" + tokenHash
+
+	now := h.now()
+
+	cached, ok := h.cache.get(key)
+	if ok && now.Before(cached.expiresAt) {
+		return cached.consumer, nil
+	}
+
+	consumer, err := h.validator.ValidateAPIToken(ctx, gateway, tok)
+	if err != nil {
+		if ok {
+			log.Warn().Err(err).
+				Str("gateway_name", gateway).
+				Msg("Unable to validate token, using its last known validation result")
+
+			return cached.consumer, nil
+		}
+
+		return nil, err
+	}
+
+	h.cache.set(key, cachedValidation{consumer: consumer, expiresAt: now.Add(h.cacheTTL)})
+
+	return consumer, nil
+}
+
+// tokenSource returns the source of the API tokens for the given credential locations. Tokens are read from both the
+// Authorization header and the query when no locations are given.
+func tokenSource(locations []string) token.Source {
+	if len(locations) == 0 {
+		locations = []string{hubv1alpha1.CredentialLocationHeader, hubv1alpha1.CredentialLocationQuery}
+	}
+
+	var src token.Source
+	for _, location := range locations {
+		switch location {
+		case hubv1alpha1.CredentialLocationHeader:
+			src.Header = "Authorization"
+			src.HeaderAuthScheme = "Bearer"
+		case hubv1alpha1.CredentialLocationQuery:
+			src.Query = QueryParameter
+		}
+	}
+
+	return src
+}
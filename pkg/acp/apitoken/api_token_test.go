@@ -0,0 +1,316 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubv1alpha1lister "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type validatorFunc func(ctx context.Context, gateway, token string) (*Consumer, error)
+
+func (f validatorFunc) ValidateAPIToken(ctx context.Context, gateway, token string) (*Consumer, error) {
+	return f(ctx, gateway, token)
+}
+
+type revocationSourceFunc func(ctx context.Context) ([]Revocation, error)
+
+func (f revocationSourceFunc) GetAPITokenRevocations(ctx context.Context) ([]Revocation, error) {
+	return f(ctx)
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc        string
+		path        string
+		header      string
+		uri         string
+		validateErr error
+		wantStatus  int
+		wantEmail   string
+		wantGroups  string
+	}{
+		{
+			desc:       "valid token in the Authorization header",
+			path:       "/my-gateway",
+			header:     "Bearer valid-token",
+			wantStatus: http.StatusOK,
+			wantEmail:  "john@example.com",
+			wantGroups: "consumer,supplier",
+		},
+		{
+			desc:       "valid token in the query",
+			path:       "/my-gateway",
+			uri:        "/books?api_key=valid-token",
+			wantStatus: http.StatusOK,
+			wantEmail:  "john@example.com",
+			wantGroups: "consumer,supplier",
+		},
+		{
+			desc:       "token in the query of a gateway only allowing the header",
+			path:       "/header-only-gateway",
+			uri:        "/books?api_key=valid-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "missing token",
+			path:       "/my-gateway",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "invalid token",
+			path:       "/my-gateway",
+			header:     "Bearer invalid-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "unknown gateway",
+			path:       "/unknown-gateway",
+			header:     "Bearer valid-token",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:        "unable to validate token",
+			path:        "/my-gateway",
+			header:      "Bearer valid-token",
+			validateErr: errors.New("boom"),
+			wantStatus:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			validator := validatorFunc(func(_ context.Context, gateway, token string) (*Consumer, error) {
+				if test.validateErr != nil {
+					return nil, test.validateErr
+				}
+				if token != "valid-token" {
+					return nil, nil
+				}
+
+				return &Consumer{Email: "john@example.com", Groups: []string{"consumer", "supplier"}}, nil
+			})
+
+			h := NewHandler(validator, nil, newGatewayLister(t), time.Minute)
+
+			req := httptest.NewRequest(http.MethodGet, test.path, http.NoBody)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+			if test.uri != "" {
+				req.Header.Set("X-Forwarded-Uri", test.uri)
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, test.wantEmail, rec.Header().Get(HeaderEmail))
+			assert.Equal(t, test.wantGroups, rec.Header().Get(HeaderGroups))
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_identityHeaders(t *testing.T) {
+	validator := validatorFunc(func(_ context.Context, _, _ string) (*Consumer, error) {
+		return &Consumer{Email: "john@example.com", Groups: []string{"consumer", "supplier"}}, nil
+	})
+
+	h := NewHandler(validator, nil, newGatewayLister(t), time.Minute)
+
+	req := httptest.NewRequest(http.MethodGet, "/custom-headers-gateway", http.NoBody)
+	req.Header.Set("Authorization", "Bearer valid-token")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, http.Header{
+		"X-Remote-User":  []string{"john@example.com"},
+		"X-Remote-Email": []string{"john@example.com"},
+		HeaderGroups:     []string{"consumer,supplier"},
+	}, rec.Header())
+}
+
+func TestGatewayIdentityHeaders(t *testing.T) {
+	tests := []struct {
+		desc      string
+		headers   *hubv1alpha1.APIGatewayIdentityHeaders
+		wantNames []string
+	}{
+		{
+			desc:      "default headers",
+			wantNames: []string{HeaderEmail, HeaderGroups},
+		},
+		{
+			desc:      "custom user header",
+			headers:   &hubv1alpha1.APIGatewayIdentityHeaders{User: "x-remote-user"},
+			wantNames: []string{"X-Remote-User", HeaderEmail, HeaderGroups},
+		},
+		{
+			desc: "custom headers",
+			headers: &hubv1alpha1.APIGatewayIdentityHeaders{
+				Email:  "X-Forwarded-Email",
+				Groups: "X-Forwarded-Groups",
+			},
+			wantNames: []string{"X-Forwarded-Email", "X-Forwarded-Groups"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gateway := &hubv1alpha1.APIGateway{
+				Spec: hubv1alpha1.APIGatewaySpec{IdentityHeaders: test.headers},
+			}
+
+			assert.Equal(t, test.wantNames, GatewayIdentityHeaders(gateway).Names())
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_cache(t *testing.T) {
+	var callCount int
+	validator := validatorFunc(func(_ context.Context, _, token string) (*Consumer, error) {
+		callCount++
+
+		if token != "valid-token" {
+			return nil, nil
+		}
+
+		return &Consumer{Email: "john@example.com"}, nil
+	})
+
+	now := time.Now()
+	h := NewHandler(validator, nil, newGatewayLister(t), time.Minute)
+	h.now = func() time.Time { return now }
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/my-gateway", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("valid-token"))
+	assert.Equal(t, http.StatusUnauthorized, serve("invalid-token"))
+	assert.Equal(t, http.StatusOK, serve("valid-token"))
+	assert.Equal(t, http.StatusUnauthorized, serve("invalid-token"))
+	assert.Equal(t, 2, callCount)
+
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, http.StatusOK, serve("valid-token"))
+	assert.Equal(t, 3, callCount)
+}
+
+func TestHandler_ServeHTTP_revocations(t *testing.T) {
+	var validateErr error
+	validator := validatorFunc(func(_ context.Context, _, _ string) (*Consumer, error) {
+		if validateErr != nil {
+			return nil, validateErr
+		}
+
+		return &Consumer{Email: "john@example.com"}, nil
+	})
+
+	var revocations []Revocation
+	source := revocationSourceFunc(func(_ context.Context) ([]Revocation, error) {
+		return revocations, nil
+	})
+	list := NewRevocationList(source)
+
+	now := time.Now()
+	h := NewHandler(validator, list, newGatewayLister(t), time.Minute)
+	h.now = func() time.Time { return now }
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/my-gateway", http.NoBody)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("token-1"))
+	assert.Equal(t, http.StatusOK, serve("token-2"))
+
+	// The platform becomes unavailable: the last known validation results are used.
+	validateErr = errors.New("boom")
+	now = now.Add(time.Minute)
+
+	assert.Equal(t, http.StatusOK, serve("token-1"))
+	assert.Equal(t, http.StatusOK, serve("token-2"))
+	assert.Equal(t, http.StatusInternalServerError, serve("token-3"))
+
+	// Revocations take precedence over cached validation results.
+	revocations = []Revocation{{TokenHash: hashToken("token-1"), Reason: "suspended"}}
+	require.NoError(t, list.sync(context.Background()))
+
+	assert.Equal(t, http.StatusUnauthorized, serve("token-1"))
+	assert.Equal(t, http.StatusOK, serve("token-2"))
+}
+
+func newGatewayLister(t *testing.T) hubv1alpha1lister.APIGatewayLister {
+	t.Helper()
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(&hubv1alpha1.APIGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+	}))
+	require.NoError(t, indexer.Add(&hubv1alpha1.APIGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "header-only-gateway"},
+		Spec: hubv1alpha1.APIGatewaySpec{
+			CredentialLocations: []string{hubv1alpha1.CredentialLocationHeader},
+		},
+	}))
+	require.NoError(t, indexer.Add(&hubv1alpha1.APIGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "custom-headers-gateway"},
+		Spec: hubv1alpha1.APIGatewaySpec{
+			IdentityHeaders: &hubv1alpha1.APIGatewayIdentityHeaders{
+				User:  "X-Remote-User",
+				Email: "x-remote-email",
+			},
+		},
+	}))
+
+	return hubv1alpha1lister.NewAPIGatewayLister(indexer)
+}
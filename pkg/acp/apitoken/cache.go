@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apitoken
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// cachedValidation is the result of the validation of a token.
+type cachedValidation struct {
+	// consumer is nil when the token is invalid.
+	consumer  *Consumer
+	expiresAt time.Time
+}
+
+// validationCache caches token validation results. Above its size, the least recently used results are evicted, so
+// requests sent with random tokens can't grow it indefinitely.
+type validationCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru orders the entries from the most to the least recently used.
+	lru *list.List
+}
+
+type cacheEntry struct {
+	key        string
+	validation cachedValidation
+}
+
+func newValidationCache(size int) *validationCache {
+	return &validationCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// get returns the validation result cached under the given key, expired or not.
+func (c *validationCache) get(key string) (cachedValidation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elt, ok := c.entries[key]
+	if !ok {
+		return cachedValidation{}, false
+	}
+	c.lru.MoveToFront(elt)
+
+	return elt.Value.(*cacheEntry).validation, true
+}
+
+// set caches the given validation result under the given key, evicting the least recently used result if the cache
+// is full.
+func (c *validationCache) set(key string, validation cachedValidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elt, ok := c.entries[key]; ok {
+		elt.Value.(*cacheEntry).validation = validation
+		c.lru.MoveToFront(elt)
+
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, validation: validation})
+
+	if c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
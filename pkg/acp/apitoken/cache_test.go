@@ -0,0 +1,66 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationCache_evictsLeastRecentlyUsed(t *testing.T) {
+	c := newValidationCache(2)
+
+	alice := cachedValidation{consumer: &Consumer{Email: "alice@example.com"}}
+	bob := cachedValidation{consumer: &Consumer{Email: "bob@example.com"}}
+
+	c.set("alice", alice)
+	c.set("bob", bob)
+
+	// Reading alice makes bob the least recently used result.
+	got, ok := c.get("alice")
+	assert.True(t, ok)
+	assert.Equal(t, alice, got)
+
+	c.set("invalid", cachedValidation{})
+
+	_, ok = c.get("bob")
+	assert.False(t, ok)
+
+	got, ok = c.get("alice")
+	assert.True(t, ok)
+	assert.Equal(t, alice, got)
+
+	_, ok = c.get("invalid")
+	assert.True(t, ok)
+
+	assert.Equal(t, 2, c.lru.Len())
+	assert.Len(t, c.entries, 2)
+}
+
+func TestValidationCache_set_updatesExistingResult(t *testing.T) {
+	c := newValidationCache(2)
+
+	c.set("alice", cachedValidation{})
+	c.set("alice", cachedValidation{consumer: &Consumer{Email: "alice@example.com"}})
+
+	got, ok := c.get("alice")
+	assert.True(t, ok)
+	assert.Equal(t, "alice@example.com", got.consumer.Email)
+	assert.Equal(t, 1, c.lru.Len())
+}
@@ -0,0 +1,116 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apitoken
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Revocation is an API token which has been revoked or suspended from a dev portal.
+type Revocation struct {
+	// TokenHash is the hex encoded SHA-256 hash of the token.
+	TokenHash string `json:"tokenHash"`
+	// Reason is the reason why the token can no longer be used, either "revoked" or "suspended".
+	Reason string `json:"reason"`
+}
+
+// RevocationSource provides the API tokens which have been revoked or suspended.
+type RevocationSource interface {
+	GetAPITokenRevocations(ctx context.Context) ([]Revocation, error)
+}
+
+// RevocationList keeps a local copy of the API tokens which have been revoked or suspended. The last synchronized list
+// keeps being used when the source becomes unavailable.
+type RevocationList struct {
+	source RevocationSource
+
+	mu      sync.RWMutex
+	revoked map[string]string
+}
+
+// NewRevocationList creates a new RevocationList synchronized from the given source.
+func NewRevocationList(source RevocationSource) *RevocationList {
+	return &RevocationList{
+		source:  source,
+		revoked: make(map[string]string),
+	}
+}
+
+// Run synchronizes the list at the given interval until the given context is canceled.
+func (l *RevocationList) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if err := l.sync(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to synchronize API token revocations")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (l *RevocationList) sync(ctx context.Context) error {
+	revocations, err := l.source.GetAPITokenRevocations(ctx)
+	if err != nil {
+		return err
+	}
+
+	revoked := make(map[string]string, len(revocations))
+	for _, revocation := range revocations {
+		revoked[revocation.TokenHash] = revocation.Reason
+	}
+
+	l.mu.Lock()
+	l.revoked = revoked
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Revoked returns whether the token with the given hash has been revoked or suspended, and why. It is safe to call on
+// a nil RevocationList, in which case no tokens are revoked.
+func (l *RevocationList) Revoked(tokenHash string) (reason string, revoked bool) {
+	if l == nil {
+		return "", false
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	reason, revoked = l.revoked[tokenHash]
+
+	return reason, revoked
+}
+
+// hashToken returns the hex encoded SHA-256 hash of the given token.
+func hashToken(tok string) string {
+	sum := sha256.Sum256([]byte(tok))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocationList_sync(t *testing.T) {
+	var (
+		revocations []Revocation
+		err         error
+	)
+	list := NewRevocationList(revocationSourceFunc(func(_ context.Context) ([]Revocation, error) {
+		return revocations, err
+	}))
+
+	revocations = []Revocation{
+		{TokenHash: hashToken("token-1"), Reason: "revoked"},
+		{TokenHash: hashToken("token-2"), Reason: "suspended"},
+	}
+	require.NoError(t, list.sync(context.Background()))
+
+	reason, revoked := list.Revoked(hashToken("token-2"))
+	assert.True(t, revoked)
+	assert.Equal(t, "suspended", reason)
+
+	// The last known revocations are kept when the source is unavailable.
+	err = errors.New("boom")
+	require.Error(t, list.sync(context.Background()))
+
+	_, revoked = list.Revoked(hashToken("token-1"))
+	assert.True(t, revoked)
+
+	// Tokens are no longer revoked once removed from the source, e.g. when resuming a suspended token.
+	revocations, err = []Revocation{{TokenHash: hashToken("token-1"), Reason: "revoked"}}, nil
+	require.NoError(t, list.sync(context.Background()))
+
+	_, revoked = list.Revoked(hashToken("token-1"))
+	assert.True(t, revoked)
+	_, revoked = list.Revoked(hashToken("token-2"))
+	assert.False(t, revoked)
+	_, revoked = list.Revoked(hashToken("token-3"))
+	assert.False(t, revoked)
+}
+
+func TestRevocationList_Revoked_nil(t *testing.T) {
+	var list *RevocationList
+
+	_, revoked := list.Revoked(hashToken("token"))
+	assert.False(t, revoked)
+}
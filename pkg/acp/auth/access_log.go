@@ -0,0 +1,100 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/forwarded"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/statusrecorder"
+)
+
+// Access log outcomes.
+const (
+	OutcomeAllowed = "allowed"
+	OutcomeDenied  = "denied"
+	OutcomeError   = "error"
+)
+
+// AccessLogger logs, as JSON, the decisions taken by the ACP handlers. Denials and errors are always logged while
+// only a sample of the allowed requests is.
+type AccessLogger struct {
+	logger            zerolog.Logger
+	successSampleRate float64
+	random            func() float64
+	now               func() time.Time
+}
+
+// NewAccessLogger returns an AccessLogger writing to w. successSampleRate is the ratio, between 0 and 1,
+// of allowed requests to log.
+func NewAccessLogger(w io.Writer, successSampleRate float64) *AccessLogger {
+	return &AccessLogger{
+		logger:            zerolog.New(w).With().Timestamp().Logger(),
+		successSampleRate: successSampleRate,
+		random:            rand.Float64,
+		now:               time.Now,
+	}
+}
+
+// Wrap wraps the handler of the given ACP to log its decisions. Decisions on simulated requests are flagged as such.
+func (l *AccessLogger) Wrap(name, acpType string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := l.now()
+
+		recorder := statusrecorder.New(rw)
+		next.ServeHTTP(recorder, req)
+
+		outcome := decisionOutcome(recorder.Status)
+		if outcome == OutcomeAllowed && l.random() >= l.successSampleRate {
+			return
+		}
+
+		event := l.logger.Log()
+		if isSimulated(req) {
+			event = event.Bool("simulated", true)
+		}
+
+		event.
+			Str("acp_name", name).
+			Str("acp_type", acpType).
+			Str("outcome", outcome).
+			Int("status", recorder.Status).
+			Dur("duration", l.now().Sub(start)).
+			Str("method", forwarded.Method(req)).
+			Str("host", req.Header.Get("X-Forwarded-Host")).
+			Str("uri", forwarded.URI(req.Header)).
+			Str("client_ip", clientip.FromRequest(req)).
+			Msg("")
+	})
+}
+
+func decisionOutcome(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return OutcomeAllowed
+	case status == http.StatusUnauthorized, status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return OutcomeDenied
+	default:
+		return OutcomeError
+	}
+}
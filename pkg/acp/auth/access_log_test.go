@@ -0,0 +1,175 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+)
+
+func TestAccessLogger_Wrap(t *testing.T) {
+	tests := []struct {
+		desc        string
+		status      int
+		sampleRate  float64
+		random      float64
+		wantLog     bool
+		wantOutcome string
+	}{
+		{
+			desc:        "allowed request sampled in",
+			status:      http.StatusOK,
+			sampleRate:  0.5,
+			random:      0.2,
+			wantLog:     true,
+			wantOutcome: OutcomeAllowed,
+		},
+		{
+			desc:       "allowed request sampled out",
+			status:     http.StatusOK,
+			sampleRate: 0.5,
+			random:     0.7,
+		},
+		{
+			desc:       "allowed requests never logged",
+			status:     http.StatusOK,
+			sampleRate: 0,
+			random:     0,
+		},
+		{
+			desc:        "unauthorized request always logged",
+			status:      http.StatusUnauthorized,
+			sampleRate:  0,
+			random:      0.9,
+			wantLog:     true,
+			wantOutcome: OutcomeDenied,
+		},
+		{
+			desc:        "forbidden request always logged",
+			status:      http.StatusForbidden,
+			sampleRate:  0,
+			random:      0.9,
+			wantLog:     true,
+			wantOutcome: OutcomeDenied,
+		},
+		{
+			desc:        "blocked request always logged",
+			status:      http.StatusTooManyRequests,
+			sampleRate:  0,
+			random:      0.9,
+			wantLog:     true,
+			wantOutcome: OutcomeDenied,
+		},
+		{
+			desc:        "error always logged",
+			status:      http.StatusInternalServerError,
+			sampleRate:  0,
+			random:      0.9,
+			wantLog:     true,
+			wantOutcome: OutcomeError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			accessLog := NewAccessLogger(&buf, test.sampleRate)
+			accessLog.random = func() float64 { return test.random }
+
+			start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			calls := 0
+			accessLog.now = func() time.Time {
+				calls++
+				return start.Add(time.Duration(calls-1) * 10 * time.Millisecond)
+			}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(test.status)
+			})
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/my-acp", nil)
+			req.Header.Set("X-Forwarded-Method", http.MethodPost)
+			req.Header.Set("X-Forwarded-Host", "example.com")
+			req.Header.Set("X-Forwarded-Uri", "/api/products")
+			req.Header.Set("X-Forwarded-For", "6.6.6.6, 10.0.0.1")
+
+			accessLog.Wrap("my-acp", "JWT", next).ServeHTTP(rw, req)
+
+			assert.Equal(t, test.status, rw.Code)
+
+			if !test.wantLog {
+				assert.Empty(t, buf.String())
+				return
+			}
+
+			var got map[string]interface{}
+			err := json.Unmarshal(buf.Bytes(), &got)
+			require.NoError(t, err)
+
+			assert.NotEmpty(t, got["time"])
+			delete(got, "time")
+
+			assert.Equal(t, map[string]interface{}{
+				"acp_name":  "my-acp",
+				"acp_type":  "JWT",
+				"outcome":   test.wantOutcome,
+				"status":    float64(test.status),
+				"duration":  float64(10),
+				"method":    http.MethodPost,
+				"host":      "example.com",
+				"uri":       "/api/products",
+				"client_ip": "10.0.0.1",
+			}, got)
+		})
+	}
+}
+
+func TestAccessLogger_Wrap_clientIPStrategy(t *testing.T) {
+	var buf bytes.Buffer
+	accessLog := NewAccessLogger(&buf, 1)
+
+	ips, err := clientip.NewStrategy(&clientip.Config{TrustedIPs: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusForbidden)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/my-acp", nil)
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 1.1.1.1, 10.0.0.1")
+
+	ips.Wrap(accessLog.Wrap("my-acp", "JWT", next)).ServeHTTP(httptest.NewRecorder(), req)
+
+	var got map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &got)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.1.1.1", got["client_ip"])
+}
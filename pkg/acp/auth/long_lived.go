@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HeaderConnectionID is the header holding the ID identifying, on the control channel, an authorized long-lived
+// connection. It must be part of the headers copied by the ingress controller from the auth response to the request.
+const HeaderConnectionID = "Hub-Connection-Id"
+
+// ConnectionsPathPrefix is the path prefix of the control channel used to re-authenticate long-lived connections.
+const ConnectionsPathPrefix = "/_connections"
+
+// maxTrackedConnections is the maximum number of tracked connections. Above it, the connections authenticated the
+// longest time ago are forgotten, so auth requests opening connections can't grow the tracked ones indefinitely.
+const maxTrackedConnections = 10000
+
+// LongLivedConnections handles the auth requests of long-lived connections, namely WebSocket upgrades and
+// Server-Sent Events streams. Their auth responses are never cached as the decision taken when the connection is
+// opened must not outlive it.
+//
+// When a re-authentication interval is configured, authorized long-lived connections are given an ID, returned
+// through the HeaderConnectionID header. The backend serving the connection must then call the control channel, at
+// least once per interval, with this ID. The auth request which opened the connection is replayed against the current
+// ACPs and the backend is expected to close the connection as soon as a non 200 status code is returned.
+type LongLivedConnections struct {
+	next           http.Handler
+	reauthInterval time.Duration
+	maxConns       int
+	now            func() time.Time
+
+	connsMu sync.Mutex
+	conns   map[string]*list.Element
+	// lru orders the connections from the most to the least recently authenticated. As they all expire after the same
+	// interval, it's also ordered from the last to the first connection to expire.
+	lru *list.List
+}
+
+type longLivedConnection struct {
+	id        string
+	path      string
+	header    http.Header
+	expiresAt time.Time
+}
+
+// NewLongLivedConnections creates a new LongLivedConnections handling the auth requests of long-lived connections
+// before passing them to next. Connections are not tracked when reauthInterval is zero.
+func NewLongLivedConnections(next http.Handler, reauthInterval time.Duration) *LongLivedConnections {
+	return &LongLivedConnections{
+		next:           next,
+		reauthInterval: reauthInterval,
+		maxConns:       maxTrackedConnections,
+		now:            time.Now,
+		conns:          make(map[string]*list.Element),
+		lru:            list.New(),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (c *LongLivedConnections) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !isLongLived(req) {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-store")
+
+	if c.reauthInterval <= 0 {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	tracker := &connectionTracker{ResponseWriter: rw, req: req, conns: c}
+	c.next.ServeHTTP(tracker, req)
+
+	// Handlers not writing any response implicitly authorize the request.
+	tracker.WriteHeader(http.StatusOK)
+}
+
+// ControlHandler returns the handler of the control channel. The connection ID is given by the request path.
+func (c *LongLivedConnections) ControlHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id := strings.Trim(req.URL.Path, "/")
+		now := c.now()
+
+		c.connsMu.Lock()
+		var conn longLivedConnection
+		elt, ok := c.conns[id]
+		if ok {
+			conn = *elt.Value.(*longLivedConnection)
+		}
+		c.connsMu.Unlock()
+
+		if !ok || !now.Before(conn.expiresAt) {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		authReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, conn.path, http.NoBody)
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		authReq.Header = conn.header.Clone()
+
+		recorder := &discardRecorder{header: make(http.Header), status: http.StatusOK}
+		c.next.ServeHTTP(recorder, authReq)
+
+		c.connsMu.Lock()
+		// The connection may have been forgotten while it was being re-authenticated.
+		if elt, ok = c.conns[id]; ok {
+			if recorder.status == http.StatusOK {
+				elt.Value.(*longLivedConnection).expiresAt = now.Add(c.reauthInterval)
+				c.lru.MoveToFront(elt)
+			} else {
+				c.forget(elt)
+			}
+		}
+		c.connsMu.Unlock()
+
+		rw.WriteHeader(recorder.status)
+	})
+}
+
+// track starts tracking the connection opened by the given auth request and returns its ID. Expired connections are
+// forgotten and, if there are still too many tracked connections, the least recently authenticated one is forgotten
+// as well: its next re-authentication fails and the backend closes it.
+func (c *LongLivedConnections) track(req *http.Request) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate connection ID: %w", err)
+	}
+	id := hex.EncodeToString(b)
+
+	now := c.now()
+
+	c.connsMu.Lock()
+	defer c.connsMu.Unlock()
+
+	for oldest := c.lru.Back(); oldest != nil && !now.Before(oldest.Value.(*longLivedConnection).expiresAt); oldest = c.lru.Back() {
+		c.forget(oldest)
+	}
+	if c.lru.Len() >= c.maxConns {
+		c.forget(c.lru.Back())
+	}
+
+	c.conns[id] = c.lru.PushFront(&longLivedConnection{
+		id:        id,
+		path:      req.URL.Path,
+		header:    req.Header.Clone(),
+		expiresAt: now.Add(c.reauthInterval),
+	})
+
+	return id, nil
+}
+
+// forget stops tracking the given connection. It must be called with connsMu held.
+func (c *LongLivedConnections) forget(elt *list.Element) {
+	c.lru.Remove(elt)
+	delete(c.conns, elt.Value.(*longLivedConnection).id)
+}
+
+// isLongLived returns whether the given auth request is about a long-lived connection. As ingress controllers don't
+// forward the hop-by-hop Upgrade header to the auth server, WebSocket upgrades are also detected using the WebSocket
+// handshake headers.
+func isLongLived(req *http.Request) bool {
+	if req.Header.Get("Upgrade") != "" || req.Header.Get("Sec-Websocket-Key") != "" {
+		return true
+	}
+
+	return strings.Contains(req.Header.Get("Accept"), "text/event-stream")
+}
+
+// connectionTracker tracks the connection opened by an auth request once it has been authorized.
+type connectionTracker struct {
+	http.ResponseWriter
+
+	req         *http.Request
+	conns       *LongLivedConnections
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (t *connectionTracker) WriteHeader(status int) {
+	if t.wroteHeader {
+		return
+	}
+	t.wroteHeader = true
+
+	if status == http.StatusOK {
+		id, err := t.conns.track(t.req)
+		if err != nil {
+			t.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		t.Header().Set(HeaderConnectionID, id)
+	}
+
+	t.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (t *connectionTracker) Write(b []byte) (int, error) {
+	t.WriteHeader(http.StatusOK)
+
+	return t.ResponseWriter.Write(b)
+}
+
+// discardRecorder records the status code written by a handler and discards its response.
+type discardRecorder struct {
+	header http.Header
+	status int
+}
+
+// Header implements http.ResponseWriter.
+func (r *discardRecorder) Header() http.Header {
+	return r.header
+}
+
+// Write implements http.ResponseWriter.
+func (r *discardRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *discardRecorder) WriteHeader(status int) {
+	r.status = status
+}
@@ -0,0 +1,200 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongLivedConnections_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc             string
+		header           http.Header
+		reauthInterval   time.Duration
+		status           int
+		wantCacheControl string
+		wantConnectionID bool
+	}{
+		{
+			desc:   "regular request",
+			header: http.Header{"Authorization": {"Bearer token"}},
+			status: http.StatusOK,
+		},
+		{
+			desc:             "WebSocket upgrade",
+			header:           http.Header{"Sec-Websocket-Key": {"dGhlIHNhbXBsZSBub25jZQ=="}},
+			status:           http.StatusForbidden,
+			wantCacheControl: "no-store",
+		},
+		{
+			desc:             "Server-Sent Events stream",
+			header:           http.Header{"Accept": {"text/event-stream"}},
+			status:           http.StatusOK,
+			wantCacheControl: "no-store",
+		},
+		{
+			desc:             "authorized WebSocket upgrade with re-authentication",
+			header:           http.Header{"Upgrade": {"websocket"}},
+			reauthInterval:   time.Minute,
+			status:           http.StatusOK,
+			wantCacheControl: "no-store",
+			wantConnectionID: true,
+		},
+		{
+			desc:             "denied WebSocket upgrade with re-authentication",
+			header:           http.Header{"Upgrade": {"websocket"}},
+			reauthInterval:   time.Minute,
+			status:           http.StatusUnauthorized,
+			wantCacheControl: "no-store",
+		},
+		{
+			desc:           "regular request with re-authentication",
+			header:         http.Header{"Authorization": {"Bearer token"}},
+			reauthInterval: time.Minute,
+			status:         http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(test.status)
+			})
+			c := NewLongLivedConnections(next, test.reauthInterval)
+
+			req := httptest.NewRequest(http.MethodGet, "/my-acp", http.NoBody)
+			req.Header = test.header
+
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			assert.Equal(t, test.wantCacheControl, rec.Header().Get("Cache-Control"))
+			assert.Equal(t, test.wantConnectionID, rec.Header().Get(HeaderConnectionID) != "")
+		})
+	}
+}
+
+func TestLongLivedConnections_ControlHandler(t *testing.T) {
+	allowed := true
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/my-acp" || req.Header.Get("Authorization") != "Bearer token" || !allowed {
+			rw.WriteHeader(http.StatusForbidden)
+		}
+	})
+
+	now := time.Now()
+	c := NewLongLivedConnections(next, time.Minute)
+	c.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodGet, "/my-acp", http.NoBody)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Authorization", "Bearer token")
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	id := rec.Header().Get(HeaderConnectionID)
+	require.NotEmpty(t, id)
+
+	reauth := func(id string) int {
+		rec := httptest.NewRecorder()
+		c.ControlHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id, http.NoBody))
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, reauth("unknown"))
+
+	// Re-authenticating the connection extends its lifetime.
+	now = now.Add(50 * time.Second)
+	assert.Equal(t, http.StatusOK, reauth(id))
+	now = now.Add(50 * time.Second)
+	assert.Equal(t, http.StatusOK, reauth(id))
+
+	// The connection is forgotten once it's no longer authorized.
+	allowed = false
+	assert.Equal(t, http.StatusForbidden, reauth(id))
+	allowed = true
+	assert.Equal(t, http.StatusNotFound, reauth(id))
+}
+
+func TestLongLivedConnections_ControlHandler_expired(t *testing.T) {
+	now := time.Now()
+	c := NewLongLivedConnections(http.NotFoundHandler(), time.Minute)
+	c.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodGet, "/my-acp", http.NoBody)
+	req.Header.Set("Accept", "text/event-stream")
+
+	id, err := c.track(req)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+
+	rec := httptest.NewRecorder()
+	c.ControlHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id, http.NoBody))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestLongLivedConnections_track_cap(t *testing.T) {
+	now := time.Now()
+	c := NewLongLivedConnections(http.NotFoundHandler(), time.Minute)
+	c.now = func() time.Time { return now }
+	c.maxConns = 2
+
+	req := httptest.NewRequest(http.MethodGet, "/my-acp", http.NoBody)
+	req.Header.Set("Accept", "text/event-stream")
+
+	expired, err := c.track(req)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+
+	first, err := c.track(req)
+	require.NoError(t, err)
+	second, err := c.track(req)
+	require.NoError(t, err)
+
+	// Expired connections are forgotten first.
+	assert.NotContains(t, c.conns, expired)
+	assert.Contains(t, c.conns, first)
+	assert.Contains(t, c.conns, second)
+
+	// Then the least recently authenticated ones.
+	third, err := c.track(req)
+	require.NoError(t, err)
+
+	assert.Len(t, c.conns, 2)
+	assert.Equal(t, 2, c.lru.Len())
+	assert.NotContains(t, c.conns, first)
+	assert.Contains(t, c.conns, second)
+	assert.Contains(t, c.conns, third)
+}
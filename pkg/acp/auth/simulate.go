@@ -0,0 +1,215 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// SimulatePathPrefix is the path of the endpoint simulating auth requests against the ACPs.
+const SimulatePathPrefix = "/_simulate"
+
+const (
+	maxSimulationRequestSize = 1 << 20
+	maxSimulationBodySize    = 4 << 10
+)
+
+// simulatedKey is the context key marking the simulated auth requests.
+type simulatedKey struct{}
+
+// SimulationRequest describes a synthetic request to submit to an ACP.
+type SimulationRequest struct {
+	// Policy is the name of the ACP the request is submitted to.
+	Policy string `json:"policy"`
+	// Method is the method of the request, GET by default.
+	Method string `json:"method,omitempty"`
+	// Host is the host the request is sent to.
+	Host string `json:"host,omitempty"`
+	// Path is the path of the request, including its query, "/" by default.
+	Path string `json:"path,omitempty"`
+	// ClientIP is the IP address of the client, 127.0.0.1 by default.
+	ClientIP string `json:"clientIp,omitempty"`
+	// Headers are the headers of the request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Token, when set, is sent as a bearer token in the Authorization header, unless this header is already given.
+	Token string `json:"token,omitempty"`
+}
+
+// SimulationResult is the decision taken by an ACP for a SimulationRequest.
+type SimulationResult struct {
+	Policy     string `json:"policy"`
+	Allowed    bool   `json:"allowed"`
+	StatusCode int    `json:"statusCode"`
+	// Headers are the headers returned by the ACP: when the request is allowed, they are the ones forwarded to
+	// the backend.
+	Headers http.Header `json:"headers,omitempty"`
+	// Body is the beginning of the response body returned by the ACP, if any.
+	Body string `json:"body,omitempty"`
+}
+
+// SimulationHandler returns the handler of the endpoint simulating auth requests, which must be called with the given
+// bearer token. It accepts a SimulationRequest POSTed as JSON and returns the SimulationResult of the ACP it targets.
+// Requests are submitted to the ACP handlers currently served: simulated failures are logged, flagged as simulated, and
+// counted by the brute force protection like any other, so the endpoint can't be used to guess credentials.
+func (w *Watcher) SimulationHandler(token string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		want := "Bearer " + token
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte(want)) != 1 {
+			http.Error(rw, "Invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		if req.Method != http.MethodPost {
+			rw.Header().Set("Allow", http.MethodPost)
+			http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var simReq SimulationRequest
+		if err := json.NewDecoder(http.MaxBytesReader(rw, req.Body, maxSimulationRequestSize)).Decode(&simReq); err != nil {
+			http.Error(rw, fmt.Sprintf("decode simulation request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		w.policiesMu.RLock()
+		policy, ok := w.policies[simReq.Policy]
+		w.policiesMu.RUnlock()
+
+		if !ok {
+			http.Error(rw, fmt.Sprintf("ACP %q not found", simReq.Policy), http.StatusNotFound)
+			return
+		}
+
+		authReq, err := newSimulatedRequest(req, simReq)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("build simulated request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		recorder := &simulationRecorder{header: make(http.Header)}
+		policy.ServeHTTP(recorder, authReq)
+
+		status := recorder.status
+		if status == 0 {
+			// Handlers not writing any response implicitly authorize the request.
+			status = http.StatusOK
+		}
+
+		result := SimulationResult{
+			Policy:     simReq.Policy,
+			Allowed:    status == http.StatusOK,
+			StatusCode: status,
+			Headers:    recorder.header,
+			Body:       recorder.body.String(),
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(rw).Encode(result); err != nil {
+			w.logger.Error().Err(err).Str("acp_name", simReq.Policy).Msg("Unable to write simulation result")
+		}
+	})
+}
+
+// newSimulatedRequest builds the auth request an ingress controller would send for the given simulation request.
+func newSimulatedRequest(req *http.Request, simReq SimulationRequest) (*http.Request, error) {
+	method := simReq.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	path := simReq.Path
+	if path == "" {
+		path = "/"
+	}
+	clientIP := simReq.ClientIP
+	if clientIP == "" {
+		clientIP = "127.0.0.1"
+	}
+	if net.ParseIP(clientIP) == nil {
+		return nil, fmt.Errorf("invalid client IP %q", clientIP)
+	}
+
+	ctx := context.WithValue(req.Context(), simulatedKey{}, true)
+
+	authReq, err := http.NewRequestWithContext(ctx, http.MethodGet, "/"+simReq.Policy, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	authReq.RemoteAddr = net.JoinHostPort(clientIP, "0")
+
+	authReq.Header.Set("X-Forwarded-Method", method)
+	authReq.Header.Set("X-Forwarded-Proto", "https")
+	authReq.Header.Set("X-Forwarded-Host", simReq.Host)
+	authReq.Header.Set("X-Forwarded-Uri", path)
+	for name, value := range simReq.Headers {
+		authReq.Header.Set(name, value)
+	}
+
+	if simReq.Token != "" && authReq.Header.Get("Authorization") == "" {
+		authReq.Header.Set("Authorization", "Bearer "+simReq.Token)
+	}
+
+	return authReq, nil
+}
+
+// isSimulated reports whether the given auth request is simulated.
+func isSimulated(req *http.Request) bool {
+	simulated, _ := req.Context().Value(simulatedKey{}).(bool)
+	return simulated
+}
+
+// simulationRecorder records the response written by an ACP handler, keeping only the beginning of its body.
+type simulationRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header implements http.ResponseWriter.
+func (r *simulationRecorder) Header() http.Header {
+	return r.header
+}
+
+// Write implements http.ResponseWriter.
+func (r *simulationRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	if remaining := maxSimulationBodySize - r.body.Len(); remaining > 0 {
+		if len(b) > remaining {
+			r.body.Write(b[:remaining])
+		} else {
+			r.body.Write(b)
+		}
+	}
+
+	return len(b), nil
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *simulationRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
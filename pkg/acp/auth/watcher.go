@@ -24,10 +24,14 @@ import (
 	"sync"
 
 	"github.com/mitchellh/hashstructure/v2"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/bruteforce"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/denyall"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/geoip"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oauthintro"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
@@ -49,26 +53,35 @@ type Watcher struct {
 	configs   map[string]*acp.Config
 	previous  uint64
 
-	acps               hubv1alpha1lister.AccessControlPolicyLister
-	secrets            acp.SecretGetter
-	secretRefCounterMu sync.RWMutex
-	secretRefCounter   map[string]int
+	acps    hubv1alpha1lister.AccessControlPolicyLister
+	secrets acp.SecretGetter
 
 	refresh chan struct{}
 
-	switcher *HTTPHandlerSwitcher
+	// policiesMu guards policies, the ACP handlers currently served.
+	policiesMu sync.RWMutex
+	policies   map[string]http.Handler
+
+	switcher  *HTTPHandlerSwitcher
+	accessLog *AccessLogger
+	guard     *bruteforce.Guard
+	logger    zerolog.Logger
 }
 
 // NewWatcher returns a new watcher to track ACP resources. It calls the given Updater when an ACP is modified at most
-// once every throttle.
-func NewWatcher(switcher *HTTPHandlerSwitcher, acps hubv1alpha1lister.AccessControlPolicyLister, secrets acp.SecretGetter) *Watcher {
+// once every throttle. The AccessControlPoliciesBySecretIndex must be registered on the ACP informer.
+// Decisions taken by the ACP handlers are logged using the given AccessLogger, if any. Clients failing to authenticate
+// too often are blocked by the given Guard, if any.
+func NewWatcher(switcher *HTTPHandlerSwitcher, acps hubv1alpha1lister.AccessControlPolicyLister, secrets acp.SecretGetter, accessLog *AccessLogger, guard *bruteforce.Guard, logger zerolog.Logger) *Watcher {
 	return &Watcher{
-		configs:          make(map[string]*acp.Config),
-		acps:             acps,
-		secrets:          secrets,
-		secretRefCounter: make(map[string]int),
-		refresh:          make(chan struct{}, 1),
-		switcher:         switcher,
+		configs:   make(map[string]*acp.Config),
+		acps:      acps,
+		secrets:   secrets,
+		refresh:   make(chan struct{}, 1),
+		switcher:  switcher,
+		accessLog: accessLog,
+		guard:     guard,
+		logger:    logger,
 	}
 }
 
@@ -79,12 +92,12 @@ func (w *Watcher) Run(ctx context.Context) {
 		case <-w.refresh:
 			configs, err := w.makeConfigs()
 			if err != nil {
-				log.Error().Err(err).Msg("Could not build ACP configs")
+				w.logger.Error().Err(err).Msg("Could not build ACP configs")
 			}
 
 			hash, err := hashstructure.Hash(configs, hashstructure.FormatV2, nil)
 			if err != nil {
-				log.Error().Err(err).Msg("Could not to compute ACP configs hash")
+				w.logger.Error().Err(err).Msg("Could not to compute ACP configs hash")
 			}
 
 			if err == nil && w.previous == hash {
@@ -97,7 +110,7 @@ func (w *Watcher) Run(ctx context.Context) {
 
 			w.previous = hash
 
-			log.Debug().Msg("Refreshing ACP handlers")
+			w.logger.Debug().Msg("Refreshing ACP handlers")
 
 			w.switcher.UpdateHandler(w.buildRoutes(ctx))
 
@@ -111,104 +124,62 @@ func (w *Watcher) Run(ctx context.Context) {
 func (w *Watcher) OnAdd(obj interface{}) {
 	switch v := obj.(type) {
 	case *hubv1alpha1.AccessControlPolicy:
-		refs := secretReferences(v)
-		w.secretRefCounterMu.Lock()
-		for _, ref := range refs {
-			w.secretRefCounter[ref]++
-		}
-		w.secretRefCounterMu.Unlock()
-
 	case *corev1.Secret:
-		w.secretRefCounterMu.RLock()
-		c := w.secretRefCounter[secretKey(v.Name, v.Namespace)]
-		w.secretRefCounterMu.RUnlock()
-		if c == 0 {
+		if !w.isSecretUsed(v) {
 			return
 		}
 
 	default:
-		log.Error().
+		w.logger.Error().
 			Str("type", fmt.Sprintf("%T", obj)).
 			Msg("Received add event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.Refresh()
 }
 
 // OnUpdate implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
 func (w *Watcher) OnUpdate(oldObj, newObj interface{}) {
 	switch v := newObj.(type) {
 	case *hubv1alpha1.AccessControlPolicy:
-		oldRefs := secretReferences(oldObj.(*hubv1alpha1.AccessControlPolicy))
-		newRefs := secretReferences(v)
-		w.secretRefCounterMu.Lock()
-		for _, ref := range oldRefs {
-			if w.secretRefCounter[ref] > 1 {
-				w.secretRefCounter[ref]--
-			} else {
-				delete(w.secretRefCounter, ref)
-			}
-		}
-		for _, ref := range newRefs {
-			w.secretRefCounter[ref]++
-		}
-		w.secretRefCounterMu.Unlock()
-
 	case *corev1.Secret:
-		w.secretRefCounterMu.RLock()
-		c := w.secretRefCounter[secretKey(v.Name, v.Namespace)]
-		w.secretRefCounterMu.RUnlock()
-		if c == 0 {
+		if !w.isSecretUsed(v) {
 			return
 		}
 
 	default:
-		log.Error().
+		w.logger.Error().
 			Str("type", fmt.Sprintf("%T", newObj)).
 			Msg("Received update event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.Refresh()
 }
 
 // OnDelete implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
 func (w *Watcher) OnDelete(obj interface{}) {
 	switch v := obj.(type) {
 	case *hubv1alpha1.AccessControlPolicy:
-		refs := secretReferences(v)
-		w.secretRefCounterMu.Lock()
-		for _, ref := range refs {
-			if w.secretRefCounter[ref] > 1 {
-				w.secretRefCounter[ref]--
-			} else {
-				delete(w.secretRefCounter, ref)
-			}
-		}
-		w.secretRefCounterMu.Unlock()
-
 	case *corev1.Secret:
-		w.secretRefCounterMu.RLock()
-		c := w.secretRefCounter[secretKey(v.Name, v.Namespace)]
-		w.secretRefCounterMu.RUnlock()
-		if c == 0 {
+		if !w.isSecretUsed(v) {
 			return
 		}
 
 	default:
-		log.Error().
+		w.logger.Error().
 			Str("type", fmt.Sprintf("%T", obj)).
 			Msg("Received delete event of unknown type")
 		return
 	}
 
+	w.Refresh()
+}
+
+// Refresh schedules a rebuild of the ACP handlers. It is called by the ACP sources which aren't backed by informers
+// when their resources change.
+func (w *Watcher) Refresh() {
 	select {
 	case w.refresh <- struct{}{}:
 	default:
@@ -225,7 +196,7 @@ func (w *Watcher) makeConfigs() (map[string]*acp.Config, error) {
 	for _, policy := range policies {
 		config, err := acp.ConfigFromPolicyWithSecret(policy, w.secrets)
 		if err != nil {
-			log.Error().
+			w.logger.Error().
 				Err(err).
 				Str("acp_name", policy.Name).
 				Msg("Could not create ACP configuration")
@@ -243,11 +214,13 @@ func (w *Watcher) buildRoutes(ctx context.Context) http.Handler {
 	defer w.configsMu.RUnlock()
 
 	mux := http.NewServeMux()
+	policies := make(map[string]http.Handler, len(w.configs))
 
 	for name, cfg := range w.configs {
 		path := "/" + name
+		acpType := getACPType(cfg)
 
-		logger := log.With().Str("acp_name", name).Str("acp_type", getACPType(cfg)).Logger()
+		logger := w.logger.With().Str("acp_name", name).Str("acp_type", acpType).Logger()
 
 		route, err := buildRoute(ctx, name, cfg)
 		if err != nil {
@@ -255,16 +228,81 @@ func (w *Watcher) buildRoutes(ctx context.Context) http.Handler {
 			continue
 		}
 
+		ips, err := clientip.NewStrategy(cfg.ClientIP)
+		if err != nil {
+			logger.Error().Err(err).Msg("Could not Create ACP client IP strategy")
+			continue
+		}
+
+		if w.guard != nil {
+			route = w.guard.Wrap(name, route)
+		}
+		if w.accessLog != nil {
+			route = w.accessLog.Wrap(name, acpType, route)
+		}
+		route = ips.Wrap(route)
+		policies[name] = route
+
 		logger.Debug().Msg("Registering ACP handler")
 
 		mux.Handle(path, route)
 	}
 
+	w.policiesMu.Lock()
+	w.policies = policies
+	w.policiesMu.Unlock()
+
 	return mux
 }
 
+// NewPolicyHandler returns the handler of the ACP with the given name and configuration, as served by the auth server
+// but without access log nor brute force protection.
+func NewPolicyHandler(ctx context.Context, name string, cfg *acp.Config) (http.Handler, error) {
+	route, err := buildRoute(ctx, name, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := clientip.NewStrategy(cfg.ClientIP)
+	if err != nil {
+		return nil, fmt.Errorf("create client IP strategy: %w", err)
+	}
+
+	return ips.Wrap(route), nil
+}
+
+// ValidatePolicy makes sure the handler of the ACP with the given name and configuration can be built, without
+// reaching any external resource: the configuration of OIDC policies is validated without discovering their provider,
+// and GeoIP policies reading their databases from the filesystem of the auth server are not checked.
+func ValidatePolicy(name string, cfg *acp.Config) error {
+	if _, err := clientip.NewStrategy(cfg.ClientIP); err != nil {
+		return fmt.Errorf("create client IP strategy: %w", err)
+	}
+
+	switch {
+	case cfg.OIDC != nil:
+		return cfg.OIDC.Validate()
+
+	case cfg.OIDCGoogle != nil:
+		return cfg.OIDCGoogle.Validate()
+
+	case cfg.GeoIP != nil:
+		for _, db := range cfg.GeoIP.Databases {
+			if db.Secret == nil {
+				return nil
+			}
+		}
+	}
+
+	_, err := buildRoute(context.Background(), name, cfg)
+	return err
+}
+
 func buildRoute(ctx context.Context, name string, cfg *acp.Config) (http.Handler, error) {
 	switch {
+	case cfg.DenyAll != nil:
+		return denyall.NewHandler(cfg.DenyAll, name)
+
 	case cfg.JWT != nil:
 		return jwt.NewHandler(cfg.JWT, name)
 
@@ -283,6 +321,9 @@ func buildRoute(ctx context.Context, name string, cfg *acp.Config) (http.Handler
 	case cfg.OAuthIntro != nil:
 		return oauthintro.NewHandler(cfg.OAuthIntro, name)
 
+	case cfg.GeoIP != nil:
+		return geoip.NewHandler(cfg.GeoIP, name)
+
 	default:
 		return nil, fmt.Errorf("unknown handler type for ACP %s", name)
 	}
@@ -290,6 +331,9 @@ func buildRoute(ctx context.Context, name string, cfg *acp.Config) (http.Handler
 
 func getACPType(cfg *acp.Config) string {
 	switch {
+	case cfg.DenyAll != nil:
+		return "Deny All"
+
 	case cfg.JWT != nil:
 		return "JWT"
 
@@ -308,32 +352,25 @@ func getACPType(cfg *acp.Config) string {
 	case cfg.OAuthIntro != nil:
 		return "OAuth Introspection"
 
+	case cfg.GeoIP != nil:
+		return "GeoIP"
+
 	default:
 		return "unknown"
 	}
 }
 
-func secretKey(name, namespace string) string {
-	return name + "@" + namespace
-}
-
-func secretReferences(policy *hubv1alpha1.AccessControlPolicy) []string {
-	var refs []string
-
-	switch {
-	case policy.Spec.OIDC != nil:
-		if policy.Spec.OIDC.Secret != nil {
-			refs = append(refs, secretKey(policy.Spec.OIDC.Secret.Name, policy.Spec.OIDC.Secret.Namespace))
-		}
-
-	case policy.Spec.OIDCGoogle != nil:
-		if policy.Spec.OIDCGoogle.Secret != nil {
-			refs = append(refs, secretKey(policy.Spec.OIDCGoogle.Secret.Name, policy.Spec.OIDCGoogle.Secret.Namespace))
-		}
-
-	case policy.Spec.OAuthIntro != nil:
-		refs = append(refs, secretKey(policy.Spec.OAuthIntro.ClientConfig.Auth.Secret.Name, policy.Spec.OAuthIntro.ClientConfig.Auth.Secret.Namespace))
+// isSecretUsed returns whether the given secret is used by an ACP.
+func (w *Watcher) isSecretUsed(secret *corev1.Secret) bool {
+	policies, err := w.acps.ListBySecret(secret.Namespace, secret.Name)
+	if err != nil {
+		w.logger.Error().
+			Err(err).
+			Str("secret_name", secret.Name).
+			Str("secret_namespace", secret.Namespace).
+			Msg("Unable to list ACPs using secret")
+		return false
 	}
 
-	return refs
+	return len(policies) > 0
 }
@@ -25,17 +25,20 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	hublisters "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ktypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	kubemock "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestWatcher_CreateOIDCACP(t *testing.T) {
@@ -347,9 +350,16 @@ func startWatcher(t *testing.T, switcher *HTTPHandlerSwitcher, kubeClientSet *ku
 		switcher,
 		hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister(),
 		acp.NewKubeSecretValueGetter(kubeInformer.Core().V1().Secrets().Lister()),
+		nil,
+		nil,
+		zerolog.Nop(),
 	)
 
-	_, err := hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddEventHandler(watcher)
+	acpIndexers := cache.Indexers{hublisters.AccessControlPoliciesBySecretIndex: hublisters.IndexAccessControlPoliciesBySecret}
+	err := hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddIndexers(acpIndexers)
+	require.NoError(t, err)
+
+	_, err = hubInformer.Hub().V1alpha1().AccessControlPolicies().Informer().AddEventHandler(watcher)
 	require.NoError(t, err)
 	_, err = kubeInformer.Core().V1().Secrets().Informer().AddEventHandler(watcher)
 	require.NoError(t, err)
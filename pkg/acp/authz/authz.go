@@ -0,0 +1,121 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/forwarded"
+)
+
+// Config configures the authorization stage of an ACP handler.
+type Config struct {
+	// Rules are CEL expressions evaluated over the claims, headers, method, path and client IP of the request.
+	// The request is allowed as soon as one of them evaluates to true.
+	Rules []string `json:"rules,omitempty"`
+}
+
+// Authorizer authorizes authenticated requests.
+type Authorizer struct {
+	programs []cel.Program
+}
+
+// NewAuthorizer compiles the rules of the given configuration and returns an Authorizer evaluating them.
+func NewAuthorizer(cfg *Config) (*Authorizer, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, errors.New("at least one rule is required")
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("claims", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("headers", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("method", cel.StringType),
+		cel.Variable("path", cel.StringType),
+		cel.Variable("clientIp", cel.StringType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create CEL environment: %w", err)
+	}
+
+	programs := make([]cel.Program, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		ast, issues := env.Compile(rule)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("compile rule %q: %w", rule, issues.Err())
+		}
+
+		// Rules accessing claims are dynamically typed, their result can only be checked at evaluation.
+		if out := ast.OutputType(); !cel.BoolType.IsAssignableType(out) && !out.IsAssignableType(cel.BoolType) {
+			return nil, fmt.Errorf("rule %q must evaluate to a bool, got %s", rule, out)
+		}
+
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("build rule %q: %w", rule, err)
+		}
+
+		programs = append(programs, program)
+	}
+
+	return &Authorizer{programs: programs}, nil
+}
+
+// Authorize returns whether the given request, authenticated with the given claims, is allowed.
+// A rule failing to evaluate, for instance because it references a missing claim, doesn't allow the request.
+func (a *Authorizer) Authorize(req *http.Request, claims map[string]interface{}) bool {
+	if claims == nil {
+		claims = make(map[string]interface{})
+	}
+
+	vars := map[string]interface{}{
+		"claims":   claims,
+		"headers":  flattenHeaders(req.Header),
+		"method":   forwarded.Method(req),
+		"path":     forwarded.Path(req),
+		"clientIp": clientip.FromRequest(req),
+	}
+
+	for _, program := range a.programs {
+		val, _, err := program.Eval(vars)
+		if err != nil {
+			log.Debug().Err(err).Msg("Unable to evaluate authorization rule")
+			continue
+		}
+
+		if allowed, ok := val.Value().(bool); ok && allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func flattenHeaders(hdr http.Header) map[string]string {
+	headers := make(map[string]string, len(hdr))
+	for name, values := range hdr {
+		headers[name] = strings.Join(values, ",")
+	}
+
+	return headers
+}
@@ -0,0 +1,141 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package authz
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuthorizer(t *testing.T) {
+	tests := []struct {
+		desc    string
+		rules   []string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			desc:    "no rules",
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "valid rules",
+			rules:   []string{`method == "GET"`, `"admin" in claims.groups`},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:    "invalid syntax",
+			rules:   []string{`method ==`},
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "unknown variable",
+			rules:   []string{`user == "john"`},
+			wantErr: assert.Error,
+		},
+		{
+			desc:    "non boolean rule",
+			rules:   []string{`path + "/"`},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewAuthorizer(&Config{Rules: test.rules})
+			test.wantErr(t, err)
+		})
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	tests := []struct {
+		desc    string
+		rules   []string
+		headers map[string]string
+		claims  map[string]interface{}
+		want    bool
+	}{
+		{
+			desc:    "method and group allowed",
+			rules:   []string{`method == "GET" && "developers" in claims.groups`},
+			headers: map[string]string{"X-Forwarded-Method": http.MethodGet},
+			claims:  map[string]interface{}{"groups": []interface{}{"developers"}},
+			want:    true,
+		},
+		{
+			desc:    "method denied for group",
+			rules:   []string{`method == "GET" && "developers" in claims.groups`},
+			headers: map[string]string{"X-Forwarded-Method": http.MethodPost},
+			claims:  map[string]interface{}{"groups": []interface{}{"developers"}},
+		},
+		{
+			desc:    "one of the rules allows the request",
+			rules:   []string{`claims.admin == true`, `path.startsWith("/public")`},
+			headers: map[string]string{"X-Forwarded-Uri": "/public/docs?page=1"},
+			claims:  map[string]interface{}{},
+			want:    true,
+		},
+		{
+			desc:    "original URL set by Nginx",
+			rules:   []string{`path == "/admin"`},
+			headers: map[string]string{"X-Original-Url": "https://example.com/admin"},
+			want:    true,
+		},
+		{
+			desc:    "header based rule",
+			rules:   []string{`headers["X-Tenant"] == claims.tenant`},
+			headers: map[string]string{"X-Tenant": "acme"},
+			claims:  map[string]interface{}{"tenant": "acme"},
+			want:    true,
+		},
+		{
+			desc:    "client IP based rule",
+			rules:   []string{`clientIp == "1.1.1.1"`},
+			headers: map[string]string{"X-Forwarded-For": "6.6.6.6, 1.1.1.1"},
+			want:    true,
+		},
+		{
+			desc:  "missing claim",
+			rules: []string{`claims.admin == true`},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			authorizer, err := NewAuthorizer(&Config{Rules: test.rules})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			assert.Equal(t, test.want, authorizer.Authorize(req, test.claims))
+		})
+	}
+}
@@ -18,6 +18,7 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package basicauth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -33,16 +34,31 @@ type Users []string
 
 // Config configures a basic auth ACP handler.
 type Config struct {
-	Users                    Users  `json:"users,omitempty"`
+	Users Users `json:"users,omitempty"`
+	// UsersSecret references a Secret holding users, in the same format as Users, one per line under its `users` key.
+	UsersSecret *SecretReference `json:"usersSecret,omitempty"`
+	// SecretUsers are the users read from the UsersSecret.
+	SecretUsers Users `json:"-"`
+	// UserLookup configures an external HTTP service users not found in Users and the UsersSecret are authenticated
+	// against.
+	UserLookup *UserLookupConfig `json:"userLookup,omitempty"`
+
 	Realm                    string `json:"realm,omitempty"`
 	StripAuthorizationHeader bool   `json:"stripAuthorizationHeader,omitempty"`
 	ForwardUsernameHeader    string `json:"forwardUsernameHeader,omitempty"`
 }
 
+// SecretReference represents a Secret Reference.
+// It has enough information to retrieve secret in any namespace.
+type SecretReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
 // Handler is a basic auth ACP Handler.
 type Handler struct {
 	auth               *goauth.BasicAuth
-	users              map[string]string
+	users              UserStore
 	forwardUsername    string
 	stripAuthorization bool
 	name               string
@@ -50,16 +66,20 @@ type Handler struct {
 
 // NewHandler creates a new basic auth ACP Handler.
 func NewHandler(cfg *Config, name string) (*Handler, error) {
-	users, err := getUsers(cfg.Users, basicUserParser)
+	static, err := newStaticUserStore(append(append([]string{}, cfg.Users...), cfg.SecretUsers...))
 	if err != nil {
 		return nil, err
 	}
 
-	h := &Handler{
-		users:              users,
-		forwardUsername:    cfg.ForwardUsernameHeader,
-		stripAuthorization: cfg.StripAuthorizationHeader,
-		name:               name,
+	users := chainUserStore{static}
+	if cfg.UserLookup != nil {
+		var lookup *httpUserStore
+		lookup, err = newHTTPUserStore(cfg.UserLookup)
+		if err != nil {
+			return nil, fmt.Errorf("make user lookup: %w", err)
+		}
+
+		users = append(users, lookup)
 	}
 
 	realm := defaultRealm
@@ -67,9 +87,13 @@ func NewHandler(cfg *Config, name string) (*Handler, error) {
 		realm = cfg.Realm
 	}
 
-	h.auth = &goauth.BasicAuth{Realm: realm, Secrets: h.secretBasic}
-
-	return h, nil
+	return &Handler{
+		auth:               &goauth.BasicAuth{Realm: realm},
+		users:              users,
+		forwardUsername:    cfg.ForwardUsernameHeader,
+		stripAuthorization: cfg.StripAuthorizationHeader,
+		name:               name,
+	}, nil
 }
 
 func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
@@ -77,10 +101,14 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	username, password, ok := req.BasicAuth()
 	if ok {
-		secret := h.auth.Secrets(username, h.auth.Realm)
-		if secret == "" || !goauth.CheckSecret(password, secret) {
-			ok = false
+		authenticated, err := h.users.Authenticate(req.Context(), username, password)
+		if err != nil && !errors.Is(err, ErrUnknownUser) {
+			l.Error().Err(err).Msg("Unable to authenticate user")
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
 		}
+
+		ok = authenticated
 	}
 
 	if !ok {
@@ -101,14 +129,6 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	rw.WriteHeader(http.StatusOK)
 }
 
-func (h *Handler) secretBasic(user, _ string) string {
-	if secret, ok := h.users[user]; ok {
-		return secret
-	}
-
-	return ""
-}
-
 func basicUserParser(user string) (username, password string, err error) {
 	split := strings.Split(user, ":")
 	if len(split) != 2 {
@@ -0,0 +1,279 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package basicauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	goauth "github.com/abbot/go-http-auth"
+)
+
+const (
+	defaultLookupCacheTTL = time.Minute
+	defaultLookupTimeout  = 5 * time.Second
+
+	// maxLookupCacheEntries bounds the number of lookup results cached, for the cache not to be grown indefinitely by
+	// requests with random credentials.
+	maxLookupCacheEntries = 10000
+)
+
+// ErrUnknownUser is returned by user stores when authenticating users they don't hold.
+var ErrUnknownUser = errors.New("unknown user")
+
+// UserStore authenticates users.
+type UserStore interface {
+	// Authenticate returns whether the given password is the password of the given user. ErrUnknownUser is returned
+	// when the user is not in the store.
+	Authenticate(ctx context.Context, username, password string) (bool, error)
+}
+
+// UserLookupConfig configures an external HTTP service users are authenticated against.
+type UserLookupConfig struct {
+	// URL is the URL credentials are posted to, as a JSON object with a `username` and a `password` field. The
+	// service must reply with a 200 when the credentials are valid, a 401 or a 403 when they are not, and a 404 when
+	// the user is unknown.
+	URL string `json:"url"`
+	// CacheTTL is how long lookup results are cached. Defaults to 1 minute.
+	CacheTTL time.Duration `json:"cacheTtl,omitempty"`
+	// Timeout is the timeout of lookups. Defaults to 5 seconds.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// staticUserStore authenticates users against a fixed set of users, mapping usernames to password hashes.
+type staticUserStore map[string]string
+
+func newStaticUserStore(users []string) (staticUserStore, error) {
+	s, err := getUsers(users, basicUserParser)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Authenticate implements UserStore.
+func (s staticUserStore) Authenticate(_ context.Context, username, password string) (bool, error) {
+	secret, ok := s[username]
+	if !ok {
+		return false, ErrUnknownUser
+	}
+
+	return goauth.CheckSecret(password, secret), nil
+}
+
+// chainUserStore authenticates users using the first store holding them.
+type chainUserStore []UserStore
+
+// Authenticate implements UserStore.
+func (c chainUserStore) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	for _, store := range c {
+		ok, err := store.Authenticate(ctx, username, password)
+		if errors.Is(err, ErrUnknownUser) {
+			continue
+		}
+
+		return ok, err
+	}
+
+	return false, ErrUnknownUser
+}
+
+// httpUserStore authenticates users against an external HTTP service, caching its results.
+type httpUserStore struct {
+	url    string
+	client *http.Client
+
+	cacheTTL time.Duration
+	// cacheKey is used to derive cache keys from credentials, for passwords not to be kept in memory.
+	cacheKey []byte
+	cacheMu  sync.Mutex
+	cache    map[string]lookupResult
+
+	now func() time.Time
+}
+
+type lookupResult struct {
+	authenticated bool
+	unknown       bool
+	expiresAt     time.Time
+}
+
+func newHTTPUserStore(cfg *UserLookupConfig) (*httpUserStore, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("user lookup URL is required")
+	}
+	if cfg.CacheTTL < 0 {
+		return nil, errors.New("user lookup cache TTL must not be negative")
+	}
+	if cfg.Timeout < 0 {
+		return nil, errors.New("user lookup timeout must not be negative")
+	}
+
+	cacheKey := make([]byte, 32)
+	if _, err := rand.Read(cacheKey); err != nil {
+		return nil, fmt.Errorf("generate cache key: %w", err)
+	}
+
+	cacheTTL := cfg.CacheTTL
+	if cacheTTL == 0 {
+		cacheTTL = defaultLookupCacheTTL
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultLookupTimeout
+	}
+
+	return &httpUserStore{
+		url:      cfg.URL,
+		client:   &http.Client{Timeout: timeout},
+		cacheTTL: cacheTTL,
+		cacheKey: cacheKey,
+		cache:    make(map[string]lookupResult),
+		now:      time.Now,
+	}, nil
+}
+
+// Authenticate implements UserStore.
+func (s *httpUserStore) Authenticate(ctx context.Context, username, password string) (bool, error) {
+	key := s.credentialsKey(username, password)
+
+	if res, ok := s.cached(key); ok {
+		return res.result()
+	}
+
+	res, err := s.lookup(ctx, username, password)
+	if err != nil {
+		return false, err
+	}
+
+	s.store(key, res)
+
+	return res.result()
+}
+
+func (s *httpUserStore) lookup(ctx context.Context, username, password string) (lookupResult, error) {
+	body, err := json.Marshal(struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{Username: username, Password: password})
+	if err != nil {
+		return lookupResult{}, fmt.Errorf("marshal credentials: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return lookupResult{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return lookupResult{}, fmt.Errorf("look up user: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return lookupResult{authenticated: true}, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return lookupResult{}, nil
+	case http.StatusNotFound:
+		return lookupResult{unknown: true}, nil
+	default:
+		return lookupResult{}, fmt.Errorf("look up user: unexpected status code %d", resp.StatusCode)
+	}
+}
+
+func (s *httpUserStore) cached(key string) (lookupResult, bool) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	res, ok := s.cache[key]
+	if !ok || !s.now().Before(res.expiresAt) {
+		return lookupResult{}, false
+	}
+
+	return res, true
+}
+
+func (s *httpUserStore) store(key string, res lookupResult) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	now := s.now()
+	if len(s.cache) >= maxLookupCacheEntries {
+		for k, r := range s.cache {
+			if !now.Before(r.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+		if len(s.cache) >= maxLookupCacheEntries {
+			s.cache = make(map[string]lookupResult)
+		}
+	}
+
+	res.expiresAt = now.Add(s.cacheTTL)
+	s.cache[key] = res
+}
+
+func (s *httpUserStore) credentialsKey(username, password string) string {
+	mac := hmac.New(sha256.New, s.cacheKey)
+	mac.Write([]byte(username))
+	mac.Write([]byte{0})
+	mac.Write([]byte(password))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (r lookupResult) result() (bool, error) {
+	if r.unknown {
+		return false, ErrUnknownUser
+	}
+
+	return r.authenticated, nil
+}
+
+// ParseUsers parses users, in the same format as Config.Users, from the given content holding one user per line.
+// Empty lines and lines starting with a `#` are ignored.
+func ParseUsers(content string) Users {
+	var users Users
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		users = append(users, line)
+	}
+
+	return users
+}
@@ -0,0 +1,190 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package basicauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPUserStore_Authenticate(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		var creds struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&creds); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case creds.Username == "broken":
+			rw.WriteHeader(http.StatusInternalServerError)
+		case creds.Username != "john":
+			rw.WriteHeader(http.StatusNotFound)
+		case creds.Password != "secret":
+			rw.WriteHeader(http.StatusUnauthorized)
+		default:
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	store, err := newHTTPUserStore(&UserLookupConfig{URL: srv.URL, CacheTTL: time.Minute})
+	require.NoError(t, err)
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	ok, err := store.Authenticate(context.Background(), "john", "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.Authenticate(context.Background(), "john", "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = store.Authenticate(context.Background(), "jane", "secret")
+	assert.ErrorIs(t, err, ErrUnknownUser)
+
+	_, err = store.Authenticate(context.Background(), "broken", "secret")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnknownUser)
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// Results are cached, errors aside.
+	ok, err = store.Authenticate(context.Background(), "john", "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, err = store.Authenticate(context.Background(), "jane", "secret")
+	assert.ErrorIs(t, err, ErrUnknownUser)
+	_, err = store.Authenticate(context.Background(), "broken", "secret")
+	assert.Error(t, err)
+
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+
+	// Until they expire.
+	now = now.Add(time.Minute)
+
+	ok, err = store.Authenticate(context.Background(), "john", "secret")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+}
+
+func TestHandler_userStores(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var creds struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&creds); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// The lookup accepts any password, for tests to make sure users of the other stores aren't looked up.
+		if creds.Username == "test" || creds.Username == "jane" || creds.Username == "lookup" {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &Config{
+		Users:       []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+		SecretUsers: ParseUsers("# Users\njane:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/\n\n"),
+		UserLookup:  &UserLookupConfig{URL: srv.URL},
+	}
+	handler, err := NewHandler(cfg, "acp@my-ns")
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc     string
+		username string
+		password string
+		wantCode int
+	}{
+		{
+			desc:     "static user",
+			username: "test",
+			password: "test",
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:     "static user with wrong password",
+			username: "test",
+			password: "wrong",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			desc:     "secret user",
+			username: "jane",
+			password: "test",
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:     "secret user with wrong password",
+			username: "jane",
+			password: "wrong",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			desc:     "looked up user",
+			username: "lookup",
+			password: "whatever",
+			wantCode: http.StatusOK,
+		},
+		{
+			desc:     "unknown user",
+			username: "unknown",
+			password: "test",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.SetBasicAuth(test.username, test.password)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package bruteforce
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/statusrecorder"
+)
+
+// maxPendingEvents is the maximum number of events waiting to be sent. Events are dropped once it is reached.
+const maxPendingEvents = 1000
+
+// Config configures the brute force protection of the ACP handlers.
+type Config struct {
+	// MaxFailures is the number of failed authentications after which a client is blocked.
+	MaxFailures int
+	// Window is the period over which failed authentications are counted.
+	Window time.Duration
+	// BlockDuration is the duration during which blocked clients are rejected.
+	BlockDuration time.Duration
+	// Tarpit is the delay before rejecting the requests of blocked clients.
+	Tarpit time.Duration
+}
+
+// Event reports a client blocked after too many failed authentications on an ACP.
+type Event struct {
+	ACP string `json:"acp"`
+	// ClientIP is the IP of the blocked client, if it was blocked by IP.
+	ClientIP string `json:"clientIp,omitempty"`
+	// Username is the targeted username, if the client was blocked by username.
+	Username     string    `json:"username,omitempty"`
+	Failures     int       `json:"failures"`
+	BlockedAt    time.Time `json:"blockedAt"`
+	BlockedUntil time.Time `json:"blockedUntil"`
+}
+
+// EventSender sends the events to the platform.
+type EventSender interface {
+	SendBruteForceEvents(ctx context.Context, events []Event) error
+}
+
+// Guard tracks the failed authentications of the ACP handlers, by client IP and by basic auth username, and blocks
+// clients failing too often. Tracking by username mitigates credential stuffing attacks spread over many IPs.
+type Guard struct {
+	cfg    Config
+	sender EventSender
+	now    func() time.Time
+	logger zerolog.Logger
+
+	mu        sync.Mutex
+	offenders map[offenderKey]*offender
+	events    []Event
+}
+
+type offenderKey struct {
+	acp      string
+	clientIP string
+	username string
+}
+
+type offender struct {
+	failures     int
+	windowStart  time.Time
+	blockedUntil time.Time
+}
+
+// NewGuard creates a new Guard. Events are sent using the given sender, if any.
+func NewGuard(cfg Config, sender EventSender, logger zerolog.Logger) (*Guard, error) {
+	if cfg.MaxFailures <= 0 {
+		return nil, errors.New("max failures must be positive")
+	}
+	if cfg.Window <= 0 {
+		return nil, errors.New("window must be positive")
+	}
+	if cfg.BlockDuration <= 0 {
+		return nil, errors.New("block duration must be positive")
+	}
+	if cfg.Tarpit < 0 {
+		return nil, errors.New("tarpit must not be negative")
+	}
+
+	return &Guard{
+		cfg:       cfg,
+		sender:    sender,
+		now:       time.Now,
+		logger:    logger,
+		offenders: make(map[offenderKey]*offender),
+	}, nil
+}
+
+// Run forgets expired offenders and sends pending events every window, until the given context is canceled.
+func (g *Guard) Run(ctx context.Context) {
+	t := time.NewTicker(g.cfg.Window)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		g.cleanup()
+
+		if err := g.flush(ctx); err != nil {
+			g.logger.Error().Err(err).Msg("Unable to send brute force events")
+		}
+	}
+}
+
+// Wrap wraps the handler of the given ACP to reject blocked clients. The client IP is the one extracted by the client
+// IP strategy of the ACP, which must wrap the returned handler, see clientip.FromRequest.
+func (g *Guard) Wrap(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		keys := requestKeys(name, req)
+
+		if until, blocked := g.blockedUntil(keys); blocked {
+			g.tarpit(req.Context())
+
+			retryAfter := math.Ceil(until.Sub(g.now()).Seconds())
+			rw.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+			rw.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		recorder := statusrecorder.New(rw)
+		next.ServeHTTP(recorder, req)
+
+		switch {
+		case recorder.Status == http.StatusUnauthorized:
+			g.fail(keys)
+		case recorder.Status < http.StatusBadRequest:
+			g.succeed(keys)
+		}
+	})
+}
+
+func (g *Guard) blockedUntil(keys []offenderKey) (time.Time, bool) {
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var until time.Time
+	for _, key := range keys {
+		if o, ok := g.offenders[key]; ok && o.blockedUntil.After(now) && o.blockedUntil.After(until) {
+			until = o.blockedUntil
+		}
+	}
+
+	return until, !until.IsZero()
+}
+
+func (g *Guard) fail(keys []offenderKey) {
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, key := range keys {
+		o, ok := g.offenders[key]
+		if !ok || now.Sub(o.windowStart) >= g.cfg.Window {
+			o = &offender{windowStart: now}
+			g.offenders[key] = o
+		}
+
+		o.failures++
+		if o.failures < g.cfg.MaxFailures {
+			continue
+		}
+
+		o.blockedUntil = now.Add(g.cfg.BlockDuration)
+
+		g.logger.Warn().
+			Str("acp_name", key.acp).
+			Str("client_ip", key.clientIP).
+			Str("username", key.username).
+			Int("failures", o.failures).
+			Time("blocked_until", o.blockedUntil).
+			Msg("Blocking client after too many failed authentications")
+
+		g.addEvent(Event{
+			ACP:          key.acp,
+			ClientIP:     key.clientIP,
+			Username:     key.username,
+			Failures:     o.failures,
+			BlockedAt:    now,
+			BlockedUntil: o.blockedUntil,
+		})
+
+		// The failures are counted again once the client is unblocked.
+		o.failures = 0
+		o.windowStart = o.blockedUntil
+	}
+}
+
+// succeed forgets the failures of the username of an authenticated request. Failures by IP are kept, as an attacker
+// may own valid credentials.
+func (g *Guard) succeed(keys []offenderKey) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, key := range keys {
+		if key.username != "" {
+			delete(g.offenders, key)
+		}
+	}
+}
+
+func (g *Guard) addEvent(event Event) {
+	if g.sender == nil {
+		return
+	}
+
+	if len(g.events) >= maxPendingEvents {
+		g.logger.Warn().Str("acp_name", event.ACP).Msg("Too many pending brute force events, dropping event")
+		return
+	}
+
+	g.events = append(g.events, event)
+}
+
+func (g *Guard) cleanup() {
+	now := g.now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for key, o := range g.offenders {
+		if now.Sub(o.windowStart) >= g.cfg.Window && !o.blockedUntil.After(now) {
+			delete(g.offenders, key)
+		}
+	}
+}
+
+func (g *Guard) flush(ctx context.Context) error {
+	g.mu.Lock()
+	events := g.events
+	g.events = nil
+	g.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	return g.sender.SendBruteForceEvents(ctx, events)
+}
+
+func (g *Guard) tarpit(ctx context.Context) {
+	if g.cfg.Tarpit == 0 {
+		return
+	}
+
+	t := time.NewTimer(g.cfg.Tarpit)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// requestKeys returns the keys the failures of the given request are tracked under.
+func requestKeys(name string, req *http.Request) []offenderKey {
+	var keys []offenderKey
+	if ip := clientip.FromRequest(req); ip != "" {
+		keys = append(keys, offenderKey{acp: name, clientIP: ip})
+	}
+	if username, _, ok := req.BasicAuth(); ok && username != "" {
+		keys = append(keys, offenderKey{acp: name, username: username})
+	}
+
+	return keys
+}
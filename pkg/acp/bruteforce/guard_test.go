@@ -0,0 +1,218 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package bruteforce
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+)
+
+func TestGuard_Wrap_blocksClientIP(t *testing.T) {
+	sender := &eventRecorder{}
+	guard, err := NewGuard(Config{MaxFailures: 3, Window: time.Minute, BlockDuration: 5 * time.Minute}, sender, zerolog.Nop())
+	require.NoError(t, err)
+
+	now := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	var calls int
+	handler := guard.Wrap("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	for i := 0; i < 3; i++ {
+		rw := serve(handler, "1.1.1.1", "")
+		assert.Equal(t, http.StatusUnauthorized, rw.Code)
+	}
+
+	rw := serve(handler, "1.1.1.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "300", rw.Header().Get("Retry-After"))
+	assert.Equal(t, 3, calls)
+
+	// Other clients are not blocked.
+	rw = serve(handler, "2.2.2.2", "")
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	now = now.Add(5 * time.Minute)
+
+	rw = serve(handler, "1.1.1.1", "")
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	err = guard.flush(context.Background())
+	require.NoError(t, err)
+
+	blockedAt := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, []Event{
+		{
+			ACP:          "my-acp",
+			ClientIP:     "1.1.1.1",
+			Failures:     3,
+			BlockedAt:    blockedAt,
+			BlockedUntil: blockedAt.Add(5 * time.Minute),
+		},
+	}, sender.events)
+}
+
+func TestGuard_Wrap_forgedForwardedFor(t *testing.T) {
+	tests := []struct {
+		desc    string
+		cfg     *clientip.Config
+		proxies string
+	}{
+		{
+			desc: "default strategy",
+		},
+		{
+			desc:    "trusted proxies",
+			cfg:     &clientip.Config{TrustedIPs: []string{"10.0.0.0/8"}},
+			proxies: ", 10.0.0.1",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			guard, err := NewGuard(Config{MaxFailures: 2, Window: time.Minute, BlockDuration: time.Minute}, nil, zerolog.Nop())
+			require.NoError(t, err)
+
+			ips, err := clientip.NewStrategy(test.cfg)
+			require.NoError(t, err)
+
+			handler := ips.Wrap(guard.Wrap("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusUnauthorized)
+			})))
+
+			// Prepending a different IP to the header on each attempt doesn't change the tracked client IP.
+			assert.Equal(t, http.StatusUnauthorized, serve(handler, "6.6.6.1, 1.1.1.1"+test.proxies, "").Code)
+			assert.Equal(t, http.StatusUnauthorized, serve(handler, "6.6.6.2, 1.1.1.1"+test.proxies, "").Code)
+			assert.Equal(t, http.StatusTooManyRequests, serve(handler, "6.6.6.3, 1.1.1.1"+test.proxies, "").Code)
+		})
+	}
+}
+
+func TestGuard_Wrap_blocksUsername(t *testing.T) {
+	guard, err := NewGuard(Config{MaxFailures: 2, Window: time.Minute, BlockDuration: time.Minute}, nil, zerolog.Nop())
+	require.NoError(t, err)
+
+	handler := guard.Wrap("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if _, password, _ := req.BasicAuth(); password == "valid" {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	// A successful authentication forgets the previous failures of the username.
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "1.1.1.1", "invalid").Code)
+	assert.Equal(t, http.StatusOK, serve(handler, "2.2.2.2", "valid").Code)
+
+	// Failures of a username are counted across client IPs.
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "3.3.3.3", "invalid").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "4.4.4.4", "invalid").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(handler, "5.5.5.5", "valid").Code)
+}
+
+func TestGuard_Wrap_windowExpires(t *testing.T) {
+	guard, err := NewGuard(Config{MaxFailures: 2, Window: time.Minute, BlockDuration: time.Minute}, nil, zerolog.Nop())
+	require.NoError(t, err)
+
+	now := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	handler := guard.Wrap("my-acp", http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "1.1.1.1", "").Code)
+
+	now = now.Add(time.Minute)
+	guard.cleanup()
+	assert.Empty(t, guard.offenders)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "1.1.1.1", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(handler, "1.1.1.1", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(handler, "1.1.1.1", "").Code)
+}
+
+func TestNewGuard_invalidConfig(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  Config
+	}{
+		{
+			desc: "no max failures",
+			cfg:  Config{Window: time.Minute, BlockDuration: time.Minute},
+		},
+		{
+			desc: "no window",
+			cfg:  Config{MaxFailures: 1, BlockDuration: time.Minute},
+		},
+		{
+			desc: "no block duration",
+			cfg:  Config{MaxFailures: 1, Window: time.Minute},
+		},
+		{
+			desc: "negative tarpit",
+			cfg:  Config{MaxFailures: 1, Window: time.Minute, BlockDuration: time.Minute, Tarpit: -time.Second},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewGuard(test.cfg, nil, zerolog.Nop())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func serve(handler http.Handler, forwardedFor, password string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/my-acp", nil)
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	if password != "" {
+		req.SetBasicAuth("john", password)
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	return rw
+}
+
+type eventRecorder struct {
+	events []Event
+}
+
+func (r *eventRecorder) SendBruteForceEvents(_ context.Context, events []Event) error {
+	r.events = append(r.events, events...)
+	return nil
+}
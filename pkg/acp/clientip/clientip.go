@@ -0,0 +1,203 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package clientip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Config configures how the IP of the client which sent a request to the ingress controller is extracted
+// from the X-Forwarded-For header. Depth, TrustedIPs and UseLeftmost are mutually exclusive. When none of them is
+// set, the right-most IP of the header, appended by the ingress controller, is used.
+type Config struct {
+	// TrustedIPs are the IPs and CIDRs of the proxies in front of the ingress controller. The client IP is the
+	// right-most IP of the header which is not trusted.
+	TrustedIPs []string `json:"trustedIps,omitempty"`
+	// Depth is the position of the client IP in the header, starting from the right.
+	Depth int `json:"depth,omitempty"`
+	// UseLeftmost uses the left-most IP of the header. Clients can forge it, so it must only be set when every proxy
+	// in front of the ingress controller overwrites the header.
+	UseLeftmost bool `json:"useLeftmost,omitempty"`
+}
+
+// Strategy extracts the client IP of requests.
+type Strategy struct {
+	depth    int
+	trusted  []netip.Prefix
+	leftmost bool
+}
+
+// NewStrategy returns a Strategy extracting client IPs according to the given configuration.
+// A nil configuration gives the default strategy, using the right-most IP of the X-Forwarded-For header.
+func NewStrategy(cfg *Config) (*Strategy, error) {
+	if cfg == nil {
+		return &Strategy{depth: 1}, nil
+	}
+
+	if cfg.Depth < 0 {
+		return nil, fmt.Errorf("depth must be positive, got %d", cfg.Depth)
+	}
+
+	var set int
+	for _, ok := range []bool{cfg.Depth > 0, len(cfg.TrustedIPs) > 0, cfg.UseLeftmost} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, errors.New("depth, trustedIps and useLeftmost are mutually exclusive")
+	}
+
+	trusted := make([]netip.Prefix, 0, len(cfg.TrustedIPs))
+	for _, ip := range cfg.TrustedIPs {
+		prefix, err := parsePrefix(ip)
+		if err != nil {
+			return nil, fmt.Errorf("parse trusted IP %q: %w", ip, err)
+		}
+
+		trusted = append(trusted, prefix)
+	}
+
+	depth := cfg.Depth
+	if set == 0 {
+		depth = 1
+	}
+
+	return &Strategy{
+		depth:    depth,
+		trusted:  trusted,
+		leftmost: cfg.UseLeftmost,
+	}, nil
+}
+
+// ClientIP returns the IP of the client which sent the given request to the ingress controller, falling back to the
+// remote address of the request when it has no X-Forwarded-For header.
+// It returns an empty string if the IP can't be determined.
+func (s *Strategy) ClientIP(req *http.Request) string {
+	ips := forwardedFor(req)
+	if len(ips) == 0 {
+		return remoteIP(req)
+	}
+
+	switch {
+	case s.leftmost:
+		return ips[0]
+	case len(s.trusted) > 0:
+		for i := len(ips) - 1; i >= 0; i-- {
+			if !s.isTrusted(ips[i]) {
+				return ips[i]
+			}
+		}
+
+		// All the IPs are trusted proxies: the left-most one is the closest to the client.
+		return ips[0]
+	default:
+		if len(ips) < s.depth {
+			return ""
+		}
+
+		return ips[len(ips)-s.depth]
+	}
+}
+
+// Wrap makes the client IP of the requests handled by next available through FromRequest.
+func (s *Strategy) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx := context.WithValue(req.Context(), clientIPKey{}, s.ClientIP(req))
+
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+func (s *Strategy) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range s.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type clientIPKey struct{}
+
+// FromRequest returns the client IP of the given request, as extracted by the Strategy of its ACP.
+// It falls back to the default strategy for requests which didn't go through Strategy.Wrap.
+func FromRequest(req *http.Request) string {
+	if ip, ok := req.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+
+	s := Strategy{depth: 1}
+
+	return s.ClientIP(req)
+}
+
+// remoteIP returns the IP of the remote address of the given request.
+func remoteIP(req *http.Request) string {
+	addr, err := netip.ParseAddrPort(req.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+
+	return addr.Addr().Unmap().String()
+}
+
+// forwardedFor returns the IPs listed in the X-Forwarded-For headers of the given request, from left to right.
+func forwardedFor(req *http.Request) []string {
+	var ips []string
+	for _, value := range req.Header.Values("X-Forwarded-For") {
+		for _, ip := range strings.Split(value, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				ips = append(ips, ip)
+			}
+		}
+	}
+
+	return ips
+}
+
+func parsePrefix(ip string) (netip.Prefix, error) {
+	if strings.Contains(ip, "/") {
+		prefix, err := netip.ParsePrefix(ip)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
@@ -0,0 +1,168 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategy_ClientIP(t *testing.T) {
+	tests := []struct {
+		desc       string
+		cfg        *Config
+		remoteAddr string
+		headers    map[string][]string
+		want       string
+	}{
+		{
+			desc:    "default strategy uses the right-most IP",
+			headers: map[string][]string{"X-Forwarded-For": {"6.6.6.6, 10.0.0.1", "1.1.1.1"}},
+			want:    "1.1.1.1",
+		},
+		{
+			desc:    "empty configuration uses the right-most IP",
+			cfg:     &Config{},
+			headers: map[string][]string{"X-Forwarded-For": {"6.6.6.6, 1.1.1.1"}},
+			want:    "1.1.1.1",
+		},
+		{
+			desc:       "default strategy falls back to the remote address",
+			remoteAddr: "1.1.1.1:1234",
+			headers:    map[string][]string{"X-Real-Ip": {"6.6.6.6"}},
+			want:       "1.1.1.1",
+		},
+		{
+			desc:    "left-most IP",
+			cfg:     &Config{UseLeftmost: true},
+			headers: map[string][]string{"X-Forwarded-For": {"1.1.1.1, 10.0.0.1", "10.0.0.2"}},
+			want:    "1.1.1.1",
+		},
+		{
+			desc:    "depth",
+			cfg:     &Config{Depth: 2},
+			headers: map[string][]string{"X-Forwarded-For": {"6.6.6.6, 1.1.1.1, 10.0.0.1"}},
+			want:    "1.1.1.1",
+		},
+		{
+			desc:    "depth greater than the number of IPs",
+			cfg:     &Config{Depth: 3},
+			headers: map[string][]string{"X-Forwarded-For": {"1.1.1.1, 10.0.0.1"}},
+			want:    "",
+		},
+		{
+			desc: "trusted IPs skip the proxies",
+			cfg:  &Config{TrustedIPs: []string{"10.0.0.0/8", "192.168.1.1"}},
+			headers: map[string][]string{
+				"X-Forwarded-For": {"6.6.6.6, 1.1.1.1", "192.168.1.1, 10.0.0.1"},
+			},
+			want: "1.1.1.1",
+		},
+		{
+			desc:    "trusted IPs with only trusted IPs",
+			cfg:     &Config{TrustedIPs: []string{"10.0.0.0/8"}},
+			headers: map[string][]string{"X-Forwarded-For": {"10.0.0.2, 10.0.0.1"}},
+			want:    "10.0.0.2",
+		},
+		{
+			desc:    "trusted IPv6 proxies",
+			cfg:     &Config{TrustedIPs: []string{"fd00::/8"}},
+			headers: map[string][]string{"X-Forwarded-For": {"2001:db8::1, fd00::1"}},
+			want:    "2001:db8::1",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			strategy, err := NewStrategy(test.cfg)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.remoteAddr != "" {
+				req.RemoteAddr = test.remoteAddr
+			}
+			for name, values := range test.headers {
+				for _, value := range values {
+					req.Header.Add(name, value)
+				}
+			}
+
+			assert.Equal(t, test.want, strategy.ClientIP(req))
+		})
+	}
+}
+
+func TestNewStrategy_invalidConfig(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  Config
+	}{
+		{
+			desc: "negative depth",
+			cfg:  Config{Depth: -1},
+		},
+		{
+			desc: "depth and trusted IPs",
+			cfg:  Config{Depth: 1, TrustedIPs: []string{"10.0.0.1"}},
+		},
+		{
+			desc: "left-most IP and trusted IPs",
+			cfg:  Config{UseLeftmost: true, TrustedIPs: []string{"10.0.0.1"}},
+		},
+		{
+			desc: "invalid trusted IP",
+			cfg:  Config{TrustedIPs: []string{"10.0.0"}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewStrategy(&test.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStrategy_Wrap(t *testing.T) {
+	strategy, err := NewStrategy(&Config{Depth: 2})
+	require.NoError(t, err)
+
+	var got string
+	handler := strategy.Wrap(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		got = FromRequest(req)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 1.1.1.1")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "6.6.6.6", got)
+
+	// Requests which didn't go through the strategy use the default one.
+	assert.Equal(t, "1.1.1.1", FromRequest(req))
+}
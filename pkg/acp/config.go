@@ -25,9 +25,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apikey"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/denyall"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/geoip"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oauthintro"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/oidc"
@@ -36,6 +41,7 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
 	"github.com/traefik/hub-agent-kubernetes/pkg/optional"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Config is the configuration of an Access Control Policy. It is used to set up ACP handlers.
@@ -46,6 +52,24 @@ type Config struct {
 	OIDC       *oidc.Config       `json:"oidc,omitempty"`
 	OIDCGoogle *OIDCGoogle        `json:"oidcGoogle,omitempty"`
 	OAuthIntro *oauthintro.Config `json:"oAuthIntro,omitempty"`
+	GeoIP      *geoip.Config      `json:"geoIp,omitempty"`
+
+	ForwardAuth *ForwardAuthConfig `json:"forwardAuth,omitempty"`
+	DenyAll     *denyall.Config    `json:"denyAll,omitempty"`
+	ClientIP    *clientip.Config   `json:"clientIp,omitempty"`
+}
+
+// ForwardAuthConfig configures the Traefik ForwardAuth middlewares calling an ACP.
+type ForwardAuthConfig struct {
+	TrustForwardHeader bool                  `json:"trustForwardHeader,omitempty"`
+	AuthRequestHeaders []string              `json:"authRequestHeaders,omitempty"`
+	TLS                *ForwardAuthTLSConfig `json:"tls,omitempty"`
+}
+
+// ForwardAuthTLSConfig configures TLS communication between the Traefik ForwardAuth middlewares and the agent.
+type ForwardAuthTLSConfig struct {
+	CASecret   string `json:"caSecret,omitempty"`
+	CertSecret string `json:"certSecret,omitempty"`
 }
 
 // OIDCGoogle is the Google OIDC configuration.
@@ -69,12 +93,25 @@ func ConfigFromPolicy(policy *hubv1alpha1.AccessControlPolicy) *Config {
 
 // ConfigFromPolicyWithSecret returns an ACP configuration for the given policy and resolves its secret references.
 func ConfigFromPolicyWithSecret(policy *hubv1alpha1.AccessControlPolicy, secrets SecretGetter) (*Config, error) {
+	cfg, err := makeConfig(policy, secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.ForwardAuth = makeForwardAuthConfig(policy.Spec.ForwardAuth)
+	cfg.DenyAll = makeDenyAllConfig(policy.Spec.DenyAll)
+	cfg.ClientIP = makeClientIPConfig(policy.Spec.ClientIP)
+
+	return cfg, nil
+}
+
+func makeConfig(policy *hubv1alpha1.AccessControlPolicy, secrets SecretGetter) (*Config, error) {
 	switch {
 	case policy.Spec.JWT != nil:
 		return makeJWTConfig(policy.Spec.JWT), nil
 
 	case policy.Spec.BasicAuth != nil:
-		return makeBasicAuthConfig(policy.Spec.BasicAuth), nil
+		return makeBasicAuthConfig(policy.Spec.BasicAuth, secrets)
 
 	case policy.Spec.APIKey != nil:
 		return makeAPIKeyConfig(policy.Spec.APIKey), nil
@@ -87,9 +124,12 @@ func ConfigFromPolicyWithSecret(policy *hubv1alpha1.AccessControlPolicy, secrets
 
 	case policy.Spec.OAuthIntro != nil:
 		return makeOAuthIntro(policy.Spec.OAuthIntro, secrets)
+
+	case policy.Spec.GeoIP != nil:
+		return makeGeoIPConfig(policy.Spec.GeoIP, secrets)
 	}
 
-	return nil, errors.New(`exactly one of "jwt", "basicAuth", "apiKey", "oidc", "oidcGoogle" or "oAuthIntro" must be set`)
+	return nil, errors.New(`exactly one of "jwt", "basicAuth", "apiKey", "oidc", "oidcGoogle", "oAuthIntro" or "geoIp" must be set`)
 }
 
 // buildClaims builds the claims from the emails.
@@ -102,6 +142,57 @@ func buildClaims(emails []string) string {
 	return strings.Join(matchers, " || ")
 }
 
+func makeForwardAuthConfig(policy *hubv1alpha1.AccessControlPolicyForwardAuth) *ForwardAuthConfig {
+	if policy == nil {
+		return nil
+	}
+
+	cfg := &ForwardAuthConfig{
+		TrustForwardHeader: policy.TrustForwardHeader,
+		AuthRequestHeaders: policy.AuthRequestHeaders,
+	}
+
+	if policy.TLS != nil {
+		cfg.TLS = &ForwardAuthTLSConfig{
+			CASecret:   policy.TLS.CASecret,
+			CertSecret: policy.TLS.CertSecret,
+		}
+	}
+
+	return cfg
+}
+
+func makeDenyAllConfig(policy *hubv1alpha1.AccessControlPolicyDenyAll) *denyall.Config {
+	if policy == nil {
+		return nil
+	}
+
+	return &denyall.Config{
+		StatusCode: policy.StatusCode,
+		Message:    policy.Message,
+	}
+}
+
+func makeClientIPConfig(policy *hubv1alpha1.AccessControlPolicyClientIP) *clientip.Config {
+	if policy == nil {
+		return nil
+	}
+
+	return &clientip.Config{
+		TrustedIPs:  policy.TrustedIPs,
+		Depth:       policy.Depth,
+		UseLeftmost: policy.UseLeftmost,
+	}
+}
+
+func makeAuthorizationConfig(policy *hubv1alpha1.AccessControlPolicyAuthorization) *authz.Config {
+	if policy == nil {
+		return nil
+	}
+
+	return &authz.Config{Rules: policy.Rules}
+}
+
 func makeJWTConfig(policy *hubv1alpha1.AccessControlPolicyJWT) *Config {
 	return &Config{
 		JWT: &jwt.Config{
@@ -110,25 +201,100 @@ func makeJWTConfig(policy *hubv1alpha1.AccessControlPolicyJWT) *Config {
 			PublicKey:                  policy.PublicKey,
 			JWKsFile:                   jwt.FileOrContent(policy.JWKsFile),
 			JWKsURL:                    policy.JWKsURL,
+			Issuers:                    makeJWTIssuersConfig(policy.Issuers),
+			Leeway:                     makeDuration(policy.Leeway),
+			MaxTokenAge:                makeDuration(policy.MaxTokenAge),
+			InternalToken:              makeJWTInternalTokenConfig(policy.InternalToken),
 			StripAuthorizationHeader:   policy.StripAuthorizationHeader,
 			ForwardHeaders:             policy.ForwardHeaders,
 			TokenQueryKey:              policy.TokenQueryKey,
 			Claims:                     policy.Claims,
+			Authorization:              makeAuthorizationConfig(policy.Authorization),
 		},
 	}
 }
 
-func makeBasicAuthConfig(policy *hubv1alpha1.AccessControlPolicyBasicAuth) *Config {
-	return &Config{
-		BasicAuth: &basicauth.Config{
-			Users:                    policy.Users,
-			Realm:                    policy.Realm,
-			StripAuthorizationHeader: policy.StripAuthorizationHeader,
-			ForwardUsernameHeader:    policy.ForwardUsernameHeader,
-		},
+func makeJWTIssuersConfig(issuers []hubv1alpha1.AccessControlPolicyJWTIssuer) []jwt.IssuerConfig {
+	if len(issuers) == 0 {
+		return nil
+	}
+
+	cfgs := make([]jwt.IssuerConfig, 0, len(issuers))
+	for _, iss := range issuers {
+		cfgs = append(cfgs, jwt.IssuerConfig{
+			Issuer:                     iss.Issuer,
+			SigningSecret:              iss.SigningSecret,
+			SigningSecretBase64Encoded: iss.SigningSecretBase64Encoded,
+			PublicKey:                  iss.PublicKey,
+			JWKsFile:                   jwt.FileOrContent(iss.JWKsFile),
+			JWKsURL:                    iss.JWKsURL,
+			Audiences:                  iss.Audiences,
+			ClaimMappings:              iss.ClaimMappings,
+		})
+	}
+
+	return cfgs
+}
+
+func makeJWTInternalTokenConfig(tok *hubv1alpha1.AccessControlPolicyJWTInternalToken) *jwt.InternalTokenConfig {
+	if tok == nil {
+		return nil
+	}
+
+	return &jwt.InternalTokenConfig{
+		SigningSecret:              tok.SigningSecret,
+		SigningSecretBase64Encoded: tok.SigningSecretBase64Encoded,
+		PrivateKey:                 tok.PrivateKey,
+		KeyID:                      tok.KeyID,
+		Issuer:                     tok.Issuer,
+		Audience:                   tok.Audience,
+		TTL:                        makeDuration(tok.TTL),
+		Claims:                     tok.Claims,
+		Header:                     tok.Header,
 	}
 }
 
+func makeDuration(d *metav1.Duration) time.Duration {
+	if d == nil {
+		return 0
+	}
+
+	return d.Duration
+}
+
+func makeBasicAuthConfig(policy *hubv1alpha1.AccessControlPolicyBasicAuth, secrets SecretGetter) (*Config, error) {
+	basicAuthConfig := &basicauth.Config{
+		Users:                    policy.Users,
+		Realm:                    policy.Realm,
+		StripAuthorizationHeader: policy.StripAuthorizationHeader,
+		ForwardUsernameHeader:    policy.ForwardUsernameHeader,
+	}
+
+	if policy.UsersSecret != nil {
+		basicAuthConfig.UsersSecret = &basicauth.SecretReference{
+			Name:      policy.UsersSecret.Name,
+			Namespace: policy.UsersSecret.Namespace,
+		}
+
+		users, err := secrets.GetValue(policy.UsersSecret, "users")
+		if err != nil {
+			return nil, fmt.Errorf("getting users: %w", err)
+		}
+
+		basicAuthConfig.SecretUsers = basicauth.ParseUsers(string(users))
+	}
+
+	if policy.UserLookup != nil {
+		basicAuthConfig.UserLookup = &basicauth.UserLookupConfig{
+			URL:      policy.UserLookup.URL,
+			CacheTTL: makeDuration(policy.UserLookup.CacheTTL),
+			Timeout:  makeDuration(policy.UserLookup.Timeout),
+		}
+	}
+
+	return &Config{BasicAuth: basicAuthConfig}, nil
+}
+
 func makeAPIKeyConfig(policy *hubv1alpha1.AccessControlPolicyAPIKey) *Config {
 	keys := make([]apikey.Key, 0, len(policy.Keys))
 	for _, k := range policy.Keys {
@@ -163,6 +329,7 @@ func makeOIDCConfig(policy *hubv1alpha1.AccessControlPolicyOIDC, secrets SecretG
 		AuthParams:     policy.AuthParams,
 		ForwardHeaders: policy.ForwardHeaders,
 		Claims:         policy.Claims,
+		Authorization:  makeAuthorizationConfig(policy.Authorization),
 	}
 
 	if policy.Secret != nil {
@@ -219,6 +386,7 @@ func makeOIDCGoogleConfig(policy *hubv1alpha1.AccessControlPolicyOIDCGoogle, sec
 			AuthParams:     policy.AuthParams,
 			ForwardHeaders: policy.ForwardHeaders,
 			Claims:         buildClaims(policy.Emails),
+			Authorization:  makeAuthorizationConfig(policy.Authorization),
 		},
 		Emails: policy.Emails,
 	}
@@ -266,10 +434,43 @@ func makeOIDCGoogleConfig(policy *hubv1alpha1.AccessControlPolicyOIDCGoogle, sec
 	return &Config{OIDCGoogle: oidcGoogleConfig}, nil
 }
 
+func makeGeoIPConfig(policy *hubv1alpha1.AccessControlPolicyGeoIP, secrets SecretGetter) (*Config, error) {
+	databases := make([]geoip.Database, 0, len(policy.Databases))
+	for _, db := range policy.Databases {
+		database := geoip.Database{Path: db.Path}
+
+		if db.Secret != nil {
+			database.Secret = &geoip.SecretReference{
+				Name:      db.Secret.Name,
+				Namespace: db.Secret.Namespace,
+			}
+
+			content, err := secrets.GetValue(db.Secret, "database")
+			if err != nil {
+				return nil, fmt.Errorf("getting database: %w", err)
+			}
+
+			database.Content = content
+		}
+
+		databases = append(databases, database)
+	}
+
+	return &Config{GeoIP: &geoip.Config{
+		Databases:        databases,
+		AllowedCountries: policy.AllowedCountries,
+		DeniedCountries:  policy.DeniedCountries,
+		AllowedASNs:      policy.AllowedASNs,
+		DeniedASNs:       policy.DeniedASNs,
+		ForwardHeaders:   policy.ForwardHeaders,
+	}}, nil
+}
+
 func makeOAuthIntro(policy *hubv1alpha1.AccessControlOAuthIntro, secrets SecretGetter) (*Config, error) {
 	oauthIntroConfig := &oauthintro.Config{
 		Claims:         policy.Claims,
 		ForwardHeaders: policy.ForwardHeaders,
+		Authorization:  makeAuthorizationConfig(policy.Authorization),
 	}
 
 	oauthIntroConfig.ClientConfig = oauthintro.ClientConfig{
@@ -18,9 +18,21 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package acp
 
 import (
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/basicauth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/denyall"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/geoip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestBuildClaims(t *testing.T) {
@@ -53,3 +65,209 @@ func TestBuildClaims(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigFromPolicy_jwt(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		JWT: &hubv1alpha1.AccessControlPolicyJWT{
+			Issuers: []hubv1alpha1.AccessControlPolicyJWTIssuer{
+				{
+					Issuer:        "https://idp1.example.com",
+					JWKsURL:       "https://idp1.example.com/jwks.json",
+					Audiences:     []string{"books"},
+					ClaimMappings: map[string]string{"groups": "realm.roles"},
+				},
+				{
+					Issuer:        "https://idp2.example.com",
+					SigningSecret: "secret",
+				},
+			},
+			Claims:      "Contains(`groups`, `admin`)",
+			Leeway:      &metav1.Duration{Duration: 30 * time.Second},
+			MaxTokenAge: &metav1.Duration{Duration: time.Hour},
+			InternalToken: &hubv1alpha1.AccessControlPolicyJWTInternalToken{
+				SigningSecret: "internal",
+				Issuer:        "https://gateway.example.com",
+				TTL:           &metav1.Duration{Duration: time.Minute},
+				Claims:        map[string]string{"groups": "groups"},
+			},
+		},
+	}
+
+	cfg := ConfigFromPolicy(&hubv1alpha1.AccessControlPolicy{Spec: spec})
+
+	assert.Equal(t, []jwt.IssuerConfig{
+		{
+			Issuer:        "https://idp1.example.com",
+			JWKsURL:       "https://idp1.example.com/jwks.json",
+			Audiences:     []string{"books"},
+			ClaimMappings: map[string]string{"groups": "realm.roles"},
+		},
+		{
+			Issuer:        "https://idp2.example.com",
+			SigningSecret: "secret",
+		},
+	}, cfg.JWT.Issuers)
+	assert.Equal(t, 30*time.Second, cfg.JWT.Leeway)
+	assert.Equal(t, time.Hour, cfg.JWT.MaxTokenAge)
+	assert.Equal(t, &jwt.InternalTokenConfig{
+		SigningSecret: "internal",
+		Issuer:        "https://gateway.example.com",
+		TTL:           time.Minute,
+		Claims:        map[string]string{"groups": "groups"},
+	}, cfg.JWT.InternalToken)
+
+	// Policies synchronized from the platform are built back from their configuration.
+	assert.Equal(t, spec, buildAccessControlPolicySpec(ACP{Config: *cfg}))
+}
+
+func TestConfigFromPolicy_forwardAuth(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+			KeySource: hubv1alpha1.TokenSource{Header: "Api-Key"},
+			Keys:      []hubv1alpha1.AccessControlPolicyAPIKeyKey{{ID: "key", Value: "hash"}},
+		},
+		ForwardAuth: &hubv1alpha1.AccessControlPolicyForwardAuth{
+			TrustForwardHeader: true,
+			AuthRequestHeaders: []string{"Api-Key", "X-Forwarded-For"},
+			TLS: &hubv1alpha1.AccessControlPolicyForwardAuthTLS{
+				CASecret:   "agent-ca",
+				CertSecret: "traefik-client-cert",
+			},
+		},
+	}
+
+	cfg := ConfigFromPolicy(&hubv1alpha1.AccessControlPolicy{Spec: spec})
+
+	assert.Equal(t, &ForwardAuthConfig{
+		TrustForwardHeader: true,
+		AuthRequestHeaders: []string{"Api-Key", "X-Forwarded-For"},
+		TLS: &ForwardAuthTLSConfig{
+			CASecret:   "agent-ca",
+			CertSecret: "traefik-client-cert",
+		},
+	}, cfg.ForwardAuth)
+
+	// Policies synchronized from the platform are built back from their configuration.
+	assert.Equal(t, spec, buildAccessControlPolicySpec(ACP{Config: *cfg}))
+}
+
+func TestConfigFromPolicy_denyAll(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+			KeySource: hubv1alpha1.TokenSource{Header: "Api-Key"},
+			Keys:      []hubv1alpha1.AccessControlPolicyAPIKeyKey{{ID: "key", Value: "hash"}},
+		},
+		DenyAll: &hubv1alpha1.AccessControlPolicyDenyAll{
+			StatusCode: http.StatusForbidden,
+			Message:    "Access suspended",
+		},
+	}
+
+	cfg := ConfigFromPolicy(&hubv1alpha1.AccessControlPolicy{Spec: spec})
+
+	assert.Equal(t, &denyall.Config{
+		StatusCode: http.StatusForbidden,
+		Message:    "Access suspended",
+	}, cfg.DenyAll)
+
+	assert.Equal(t, spec, buildAccessControlPolicySpec(ACP{Config: *cfg}))
+}
+
+func TestConfigFromPolicy_clientIP(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		APIKey: &hubv1alpha1.AccessControlPolicyAPIKey{
+			KeySource: hubv1alpha1.TokenSource{Header: "Api-Key"},
+			Keys:      []hubv1alpha1.AccessControlPolicyAPIKeyKey{{ID: "key", Value: "hash"}},
+		},
+		ClientIP: &hubv1alpha1.AccessControlPolicyClientIP{
+			TrustedIPs: []string{"10.0.0.0/8"},
+		},
+	}
+
+	cfg := ConfigFromPolicy(&hubv1alpha1.AccessControlPolicy{Spec: spec})
+
+	assert.Equal(t, &clientip.Config{TrustedIPs: []string{"10.0.0.0/8"}}, cfg.ClientIP)
+
+	assert.Equal(t, spec, buildAccessControlPolicySpec(ACP{Config: *cfg}))
+}
+
+func TestConfigFromPolicyWithSecret_basicAuth(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+			Users:       []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+			UsersSecret: &corev1.SecretReference{Name: "users", Namespace: "my-ns"},
+			UserLookup: &hubv1alpha1.AccessControlPolicyBasicAuthUserLookup{
+				URL:      "http://users.my-ns.svc/authenticate",
+				CacheTTL: &metav1.Duration{Duration: 30 * time.Second},
+			},
+		},
+	}
+
+	secrets := secretGetterFunc(func(secret *corev1.SecretReference, key string) ([]byte, error) {
+		if secret.Namespace != "my-ns" || secret.Name != "users" || key != "users" {
+			return nil, errors.New("not found")
+		}
+		return []byte("jane:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/\n"), nil
+	})
+
+	cfg, err := ConfigFromPolicyWithSecret(&hubv1alpha1.AccessControlPolicy{Spec: spec}, secrets)
+	require.NoError(t, err)
+
+	assert.Equal(t, &basicauth.Config{
+		Users:       basicauth.Users{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+		UsersSecret: &basicauth.SecretReference{Name: "users", Namespace: "my-ns"},
+		SecretUsers: basicauth.Users{"jane:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+		UserLookup: &basicauth.UserLookupConfig{
+			URL:      "http://users.my-ns.svc/authenticate",
+			CacheTTL: 30 * time.Second,
+		},
+	}, cfg.BasicAuth)
+
+	// Policies synchronized from the platform are built back from their configuration.
+	assert.Equal(t, spec, buildAccessControlPolicySpec(ACP{Config: *cfg}))
+}
+
+func TestConfigFromPolicyWithSecret_geoIP(t *testing.T) {
+	spec := hubv1alpha1.AccessControlPolicySpec{
+		GeoIP: &hubv1alpha1.AccessControlPolicyGeoIP{
+			Databases: []hubv1alpha1.AccessControlPolicyGeoIPDatabase{
+				{Path: "/var/lib/geoip/GeoLite2-Country.mmdb"},
+				{Secret: &corev1.SecretReference{Name: "geoip-asn", Namespace: "my-ns"}},
+			},
+			AllowedCountries: []string{"FR", "DE"},
+			DeniedASNs:       []uint{64496},
+			ForwardHeaders:   map[string]string{"X-Country": "country"},
+		},
+	}
+
+	secrets := secretGetterFunc(func(secret *corev1.SecretReference, key string) ([]byte, error) {
+		if secret.Namespace != "my-ns" || secret.Name != "geoip-asn" || key != "database" {
+			return nil, errors.New("not found")
+		}
+		return []byte("database"), nil
+	})
+
+	cfg, err := ConfigFromPolicyWithSecret(&hubv1alpha1.AccessControlPolicy{Spec: spec}, secrets)
+	require.NoError(t, err)
+
+	assert.Equal(t, &geoip.Config{
+		Databases: []geoip.Database{
+			{Path: "/var/lib/geoip/GeoLite2-Country.mmdb"},
+			{
+				Secret:  &geoip.SecretReference{Name: "geoip-asn", Namespace: "my-ns"},
+				Content: []byte("database"),
+			},
+		},
+		AllowedCountries: []string{"FR", "DE"},
+		DeniedASNs:       []uint{64496},
+		ForwardHeaders:   map[string]string{"X-Country": "country"},
+	}, cfg.GeoIP)
+
+	assert.Equal(t, spec, buildAccessControlPolicySpec(ACP{Config: *cfg}))
+}
+
+type secretGetterFunc func(secret *corev1.SecretReference, key string) ([]byte, error)
+
+func (f secretGetterFunc) GetValue(secret *corev1.SecretReference, key string) ([]byte, error) {
+	return f(secret, key)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package denyall
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Config configures a deny all ACP handler.
+type Config struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Handler is a deny all ACP Handler. It rejects every request.
+type Handler struct {
+	name       string
+	statusCode int
+	message    string
+}
+
+// NewHandler creates a new deny all ACP Handler.
+func NewHandler(cfg *Config, name string) (*Handler, error) {
+	statusCode := cfg.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	if statusCode < 400 || statusCode > 599 {
+		return nil, fmt.Errorf("status code %d is not an error status code", statusCode)
+	}
+
+	return &Handler{
+		name:       name,
+		statusCode: statusCode,
+		message:    cfg.Message,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	log.Debug().
+		Str("handler_type", "DenyAll").
+		Str("handler_name", h.name).
+		Int("status_code", h.statusCode).
+		Msg("Rejecting request")
+
+	if h.message == "" {
+		rw.WriteHeader(h.statusCode)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(h.statusCode)
+	_, _ = fmt.Fprintln(rw, h.message)
+}
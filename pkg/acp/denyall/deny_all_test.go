@@ -0,0 +1,77 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package denyall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandler(t *testing.T) {
+	tests := []struct {
+		desc       string
+		cfg        Config
+		wantStatus int
+		wantBody   string
+		wantErr    bool
+	}{
+		{
+			desc:       "default status code",
+			cfg:        Config{},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:       "custom status code and message",
+			cfg:        Config{StatusCode: http.StatusForbidden, Message: "Access suspended"},
+			wantStatus: http.StatusForbidden,
+			wantBody:   "Access suspended\n",
+		},
+		{
+			desc:    "successful status code",
+			cfg:     Config{StatusCode: http.StatusOK},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := NewHandler(&test.cfg, "acp@ns")
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			rw := httptest.NewRecorder()
+
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.wantStatus, rw.Code)
+			assert.Equal(t, test.wantBody, rw.Body.String())
+		})
+	}
+}
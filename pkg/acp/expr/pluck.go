@@ -54,6 +54,12 @@ func PluckClaim(selection string, claims map[string]interface{}) ([]string, erro
 	return result, nil
 }
 
+// ResolveClaim returns the value of the claim with the given name from a set of claims. Nested claims are addressed
+// using dots.
+func ResolveClaim(name string, claims map[string]interface{}) (interface{}, bool) {
+	return resolve(name, claims)
+}
+
 // PluckClaims returns the claims with the given names from a set of claims.
 func PluckClaims(selection map[string]string, claims map[string]interface{}) (map[string][]string, error) {
 	result := make(map[string][]string, len(selection))
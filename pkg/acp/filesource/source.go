@@ -0,0 +1,254 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package filesource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublisters "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/manifest"
+	corev1 "k8s.io/api/core/v1"
+	corev1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fileReloadDelay is the time waited for a burst of file system events to settle before reloading the files, as
+// editors and the kubelet, when updating a mounted ConfigMap or Secret, modify several files at once.
+const fileReloadDelay = 100 * time.Millisecond
+
+// Source provides the AccessControlPolicies, and the Secrets and APIGateways they rely on, read from manifest
+// files instead of the Kubernetes API. It allows running the auth server where the CRDs can't be installed.
+type Source struct {
+	paths []string
+
+	mu   sync.Mutex
+	hash []byte
+
+	acps     cache.Indexer
+	secrets  cache.Indexer
+	gateways cache.Indexer
+
+	logger zerolog.Logger
+}
+
+// NewSource returns a source of the resources held by the given manifest files. Directories are walked
+// recursively. The files are read once before returning.
+func NewSource(paths []string, logger zerolog.Logger) (*Source, error) {
+	s := &Source{
+		paths:  paths,
+		logger: logger,
+		acps: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+			hublisters.AccessControlPoliciesBySecretIndex: hublisters.IndexAccessControlPoliciesBySecret,
+		}),
+		secrets:  cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+		gateways: cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+	}
+
+	if _, err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// AccessControlPolicies returns a lister of the AccessControlPolicies read from the files.
+func (s *Source) AccessControlPolicies() hublisters.AccessControlPolicyLister {
+	return hublisters.NewAccessControlPolicyLister(s.acps)
+}
+
+// APIGateways returns a lister of the APIGateways read from the files.
+func (s *Source) APIGateways() hublisters.APIGatewayLister {
+	return hublisters.NewAPIGatewayLister(s.gateways)
+}
+
+// Secrets returns a getter of the values of the Secrets read from the files.
+func (s *Source) Secrets() *acp.KubeSecretGetter {
+	return acp.NewKubeSecretValueGetter(corev1lister.NewSecretLister(s.secrets))
+}
+
+// Run watches the files and reloads them when they are modified, until the given context is done. The given onChange
+// function is called every time the resources they hold changed.
+func (s *Source) Run(ctx context.Context, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create file watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	if err = s.watch(watcher); err != nil {
+		return err
+	}
+
+	reload := time.NewTimer(fileReloadDelay)
+	reload.Stop()
+	defer reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			s.logger.Debug().
+				Str("file", event.Name).
+				Str("op", event.Op.String()).
+				Msg("ACP file event")
+
+			reload.Reset(fileReloadDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+
+			s.logger.Error().Err(err).Msg("Watching ACP files")
+
+		case <-reload.C:
+			// Directories may have been created since the last time the files were read.
+			if err = s.watch(watcher); err != nil {
+				s.logger.Error().Err(err).Msg("Unable to watch ACP files")
+			}
+
+			changed, err := s.load()
+			if err != nil {
+				s.logger.Error().Err(err).Msg("Unable to reload ACP files")
+				continue
+			}
+
+			if changed {
+				s.logger.Info().Msg("ACP files reloaded")
+				onChange()
+			}
+		}
+	}
+}
+
+// watch adds the directories holding the files to the given watcher. The parent directory of a file is watched rather
+// than the file itself, since files are often updated by being replaced.
+func (s *Source) watch(watcher *fsnotify.Watcher) error {
+	for _, path := range s.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("stat %q: %w", path, err)
+		}
+
+		if !info.IsDir() {
+			if err = watcher.Add(filepath.Dir(path)); err != nil {
+				return fmt.Errorf("watch %q: %w", filepath.Dir(path), err)
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+
+			if err = watcher.Add(p); err != nil {
+				return fmt.Errorf("watch %q: %w", p, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load reads the manifest files and returns whether the resources they hold changed since the last time they were
+// read.
+func (s *Source) load() (bool, error) {
+	objects, err := manifest.Load(s.paths)
+	if err != nil {
+		return false, fmt.Errorf("load manifests: %w", err)
+	}
+
+	var acps, secrets, gateways []interface{}
+	hash := fnv.New128a()
+	for _, object := range objects {
+		switch object.Object.(type) {
+		case *hubv1alpha1.AccessControlPolicy:
+			acps = append(acps, object.Object)
+		case *corev1.Secret:
+			secrets = append(secrets, object.Object)
+		case *hubv1alpha1.APIGateway:
+			gateways = append(gateways, object.Object)
+		default:
+			continue
+		}
+
+		if object.DecodeErr != nil {
+			s.logger.Warn().
+				Err(object.DecodeErr).
+				Str("source", object.Source).
+				Msg("ACP manifest resource has unknown or duplicated fields")
+		}
+
+		b, err := json.Marshal(object.Object)
+		if err != nil {
+			return false, fmt.Errorf("marshal %s: %w", object.Source, err)
+		}
+		hash.Write(b)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sum := hash.Sum(nil)
+	if bytes.Equal(s.hash, sum) {
+		return false, nil
+	}
+
+	for _, replace := range []struct {
+		indexer cache.Indexer
+		objects []interface{}
+	}{
+		{indexer: s.acps, objects: acps},
+		{indexer: s.secrets, objects: secrets},
+		{indexer: s.gateways, objects: gateways},
+	} {
+		if err = replace.indexer.Replace(replace.objects, ""); err != nil {
+			return false, fmt.Errorf("index resources: %w", err)
+		}
+	}
+	s.hash = sum
+
+	return true, nil
+}
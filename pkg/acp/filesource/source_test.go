@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package filesource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const policies = `
+apiVersion: hub.traefik.io/v1alpha1
+kind: AccessControlPolicy
+metadata:
+  name: basic-auth
+spec:
+  basicAuth:
+    usersSecret:
+      name: users
+      namespace: hub-agent
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: users
+  namespace: hub-agent
+data:
+  users: dXNlcjokYXByMSQ5Q3YvT01HaiRab21XUXp1UWJMLjNUUkNTODFBMWcv
+`
+
+const gateway = `
+apiVersion: hub.traefik.io/v1alpha1
+kind: APIGateway
+metadata:
+  name: gateway
+spec:
+  apiAccesses:
+    - access
+`
+
+func TestNewSource(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "policies.yaml"), []byte(policies), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "gateways"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gateways", "gateway.yaml"), []byte(gateway), 0o600))
+
+	source, err := NewSource([]string{dir}, zerolog.Nop())
+	require.NoError(t, err)
+
+	policy, err := source.AccessControlPolicies().Get("basic-auth")
+	require.NoError(t, err)
+	assert.Equal(t, "users", policy.Spec.BasicAuth.UsersSecret.Name)
+
+	users, err := source.AccessControlPolicies().ListBySecret("hub-agent", "users")
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	value, err := source.Secrets().GetValue(&corev1.SecretReference{Name: "users", Namespace: "hub-agent"}, "users")
+	require.NoError(t, err)
+	assert.Equal(t, "user:$apr1$9Cv/OMGj$ZomWQzuQbL.3TRCS81A1g/", string(value))
+
+	gateways, err := source.APIGateways().List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, gateways, 1)
+}
+
+func TestNewSource_missingFile(t *testing.T) {
+	_, err := NewSource([]string{filepath.Join(t.TempDir(), "missing.yaml")}, zerolog.Nop())
+	assert.Error(t, err)
+}
+
+func TestSource_Run(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "policies.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(policies), 0o600))
+
+	source, err := NewSource([]string{file}, zerolog.Nop())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	changed := make(chan struct{}, 10)
+	runDone := make(chan error, 1)
+	go func() { runDone <- source.Run(ctx, func() { changed <- struct{}{} }) }()
+
+	// Give the watcher some time to be set up.
+	time.Sleep(50 * time.Millisecond)
+
+	// Rewriting the same resources isn't a change.
+	require.NoError(t, os.WriteFile(file, []byte(policies), 0o600))
+
+	select {
+	case <-changed:
+		require.FailNow(t, "unexpected change")
+	case <-time.After(3 * fileReloadDelay):
+	}
+
+	// Files are often updated by being replaced.
+	tmp := filepath.Join(dir, ".policies.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte(policies+"---\n"+gateway), 0o600))
+	require.NoError(t, os.Rename(tmp, file))
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "change not detected")
+	}
+
+	gateways, err := source.APIGateways().List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, gateways, 1)
+
+	cancel()
+	assert.NoError(t, <-runDone)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package forwarded gets the attributes of the requests sent to the ingress controllers from the forward auth requests
+// they send to the ACP handlers, regardless of the ingress controller type. It currently supports Traefik
+// (X-Forwarded-Method and X-Forwarded-Uri) and Nginx Community (X-Original-Method and X-Original-Url).
+package forwarded
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// Method returns the method of the request sent to the ingress controller. It falls back on the method of the given
+// request when unknown.
+func Method(req *http.Request) string {
+	if method := req.Header.Get("X-Forwarded-Method"); method != "" {
+		return method
+	}
+	if method := req.Header.Get("X-Original-Method"); method != "" {
+		return method
+	}
+
+	return req.Method
+}
+
+// URI returns the URI of the request sent to the ingress controller, empty when unknown.
+func URI(hdr http.Header) string {
+	if uri := hdr.Get("X-Forwarded-Uri"); uri != "" {
+		return uri
+	}
+
+	return hdr.Get("X-Original-Url")
+}
+
+// Path returns the path of the request sent to the ingress controller. It falls back on the path of the given request
+// when unknown, and is empty when the URI can't be parsed.
+func Path(req *http.Request) string {
+	uri := URI(req.Header)
+	if uri == "" {
+		return req.URL.Path
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+
+	return u.Path
+}
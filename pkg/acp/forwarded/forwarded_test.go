@@ -0,0 +1,113 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package forwarded
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethod(t *testing.T) {
+	tests := []struct {
+		desc   string
+		header http.Header
+		want   string
+	}{
+		{
+			desc:   "Traefik",
+			header: http.Header{"X-Forwarded-Method": {http.MethodPost}},
+			want:   http.MethodPost,
+		},
+		{
+			desc:   "Nginx",
+			header: http.Header{"X-Original-Method": {http.MethodPut}},
+			want:   http.MethodPut,
+		},
+		{
+			desc: "unknown",
+			want: http.MethodGet,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+
+			assert.Equal(t, test.want, Method(req))
+		})
+	}
+}
+
+func TestURIAndPath(t *testing.T) {
+	tests := []struct {
+		desc     string
+		header   http.Header
+		wantURI  string
+		wantPath string
+	}{
+		{
+			desc:     "Traefik",
+			header:   http.Header{"X-Forwarded-Uri": {"/traefik?foo=bar"}},
+			wantURI:  "/traefik?foo=bar",
+			wantPath: "/traefik",
+		},
+		{
+			desc:     "Nginx",
+			header:   http.Header{"X-Original-Url": {"/nginx?foo=bar"}},
+			wantURI:  "/nginx?foo=bar",
+			wantPath: "/nginx",
+		},
+		{
+			desc:     "unparsable URI",
+			header:   http.Header{"X-Forwarded-Uri": {"/%zz"}},
+			wantURI:  "/%zz",
+			wantPath: "",
+		},
+		{
+			desc:     "unknown",
+			wantURI:  "",
+			wantPath: "/auth",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/auth", http.NoBody)
+			for name, values := range test.header {
+				req.Header[name] = values
+			}
+
+			assert.Equal(t, test.wantURI, URI(req.Header))
+			assert.Equal(t, test.wantPath, Path(req))
+		})
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+)
+
+// Geo data which can be forwarded in headers.
+const (
+	FieldCountry        = "country"
+	FieldASN            = "asn"
+	FieldASOrganization = "asOrganization"
+)
+
+// Config configures a GeoIP ACP handler.
+type Config struct {
+	// Databases are the MaxMind DB databases the client IPs are looked up in, using the GeoIP2 schema.
+	Databases []Database `json:"databases"`
+
+	AllowedCountries []string `json:"allowedCountries,omitempty"`
+	DeniedCountries  []string `json:"deniedCountries,omitempty"`
+	AllowedASNs      []uint   `json:"allowedAsns,omitempty"`
+	DeniedASNs       []uint   `json:"deniedAsns,omitempty"`
+
+	// ForwardHeaders are the headers the geo data of allowed requests is forwarded in, indexed by header name.
+	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
+}
+
+// Database references a MaxMind DB database, either on the file system or in a Secret.
+type Database struct {
+	Path   string           `json:"path,omitempty"`
+	Secret *SecretReference `json:"secret,omitempty"`
+	// Content is the database read from the Secret.
+	Content []byte `json:"-"`
+}
+
+// SecretReference represents a Secret Reference.
+// It has enough information to retrieve secret in any namespace.
+type SecretReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// record is the data of a network, in the GeoIP2 Country and ASN databases.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	ASN            uint   `maxminddb:"autonomous_system_number"`
+	ASOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Handler is a GeoIP ACP Handler.
+type Handler struct {
+	name       string
+	readers    []*maxminddb.Reader
+	allowedCC  map[string]struct{}
+	deniedCC   map[string]struct{}
+	allowedASN map[uint]struct{}
+	deniedASN  map[uint]struct{}
+	fwdHeaders map[string]string
+}
+
+// NewHandler creates a new GeoIP ACP Handler.
+func NewHandler(cfg *Config, name string) (*Handler, error) {
+	if len(cfg.Databases) == 0 {
+		return nil, errors.New("at least one database must be defined")
+	}
+
+	if len(cfg.AllowedCountries) == 0 && len(cfg.DeniedCountries) == 0 &&
+		len(cfg.AllowedASNs) == 0 && len(cfg.DeniedASNs) == 0 {
+		return nil, errors.New(`at least one of "allowedCountries", "deniedCountries", "allowedAsns" or "deniedAsns" must be set`)
+	}
+
+	for header, field := range cfg.ForwardHeaders {
+		switch field {
+		case FieldCountry, FieldASN, FieldASOrganization:
+		default:
+			return nil, fmt.Errorf("unsupported field %q forwarded in header %q", field, header)
+		}
+	}
+
+	readers := make([]*maxminddb.Reader, 0, len(cfg.Databases))
+	for i, db := range cfg.Databases {
+		reader, err := openDatabase(db)
+		if err != nil {
+			return nil, fmt.Errorf("open database %d: %w", i, err)
+		}
+
+		readers = append(readers, reader)
+	}
+
+	return &Handler{
+		name:       name,
+		readers:    readers,
+		allowedCC:  countrySet(cfg.AllowedCountries),
+		deniedCC:   countrySet(cfg.DeniedCountries),
+		allowedASN: asnSet(cfg.AllowedASNs),
+		deniedASN:  asnSet(cfg.DeniedASNs),
+		fwdHeaders: cfg.ForwardHeaders,
+	}, nil
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	l := log.With().Str("handler_type", "GeoIP").Str("handler_name", h.name).Logger()
+
+	ip := net.ParseIP(clientip.FromRequest(req))
+	if ip == nil {
+		l.Debug().Msg("Unable to determine the client IP")
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	rec, err := h.lookup(ip)
+	if err != nil {
+		l.Error().Err(err).Str("client_ip", ip.String()).Msg("Unable to look up client IP")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !h.allowed(rec) {
+		l.Debug().
+			Str("client_ip", ip.String()).
+			Str("country", rec.Country.ISOCode).
+			Uint("asn", rec.ASN).
+			Msg("Client IP location denied")
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	for name, field := range h.fwdHeaders {
+		if v := rec.field(field); v != "" {
+			rw.Header().Add(name, v)
+		}
+	}
+
+	rw.WriteHeader(http.StatusOK)
+}
+
+// lookup looks up the given IP in the databases. Each field is taken from the first database holding it.
+func (h *Handler) lookup(ip net.IP) (record, error) {
+	var rec record
+	for i, reader := range h.readers {
+		var r record
+		if err := reader.Lookup(ip, &r); err != nil {
+			return record{}, fmt.Errorf("database %d: %w", i, err)
+		}
+
+		if rec.Country.ISOCode == "" {
+			rec.Country.ISOCode = r.Country.ISOCode
+		}
+		if rec.ASN == 0 {
+			rec.ASN = r.ASN
+			rec.ASOrganization = r.ASOrganization
+		}
+	}
+
+	return rec, nil
+}
+
+// allowed returns whether the given location is allowed. It must not be denied and must match every allow list set.
+// Unknown locations are only allowed when no allow list is set.
+func (h *Handler) allowed(rec record) bool {
+	if _, ok := h.deniedCC[rec.Country.ISOCode]; ok {
+		return false
+	}
+	if _, ok := h.deniedASN[rec.ASN]; ok {
+		return false
+	}
+
+	if len(h.allowedCC) > 0 {
+		if _, ok := h.allowedCC[rec.Country.ISOCode]; !ok {
+			return false
+		}
+	}
+	if len(h.allowedASN) > 0 {
+		if _, ok := h.allowedASN[rec.ASN]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r record) field(name string) string {
+	switch name {
+	case FieldCountry:
+		return r.Country.ISOCode
+	case FieldASN:
+		if r.ASN == 0 {
+			return ""
+		}
+		return strconv.FormatUint(uint64(r.ASN), 10)
+	case FieldASOrganization:
+		return r.ASOrganization
+	default:
+		return ""
+	}
+}
+
+// openDatabase opens the given database. Databases are read in memory rather than memory-mapped, as handlers are
+// dropped without being closed when the ACPs change.
+func openDatabase(db Database) (*maxminddb.Reader, error) {
+	content := db.Content
+	if db.Secret == nil {
+		if db.Path == "" {
+			return nil, errors.New(`one of "path" or "secret" must be set`)
+		}
+
+		var err error
+		content, err = os.ReadFile(db.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+	}
+
+	reader, err := maxminddb.FromBytes(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+
+	return reader, nil
+}
+
+func countrySet(codes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(code)] = struct{}{}
+	}
+
+	return set
+}
+
+func asnSet(asns []uint) map[uint]struct{} {
+	set := make(map[uint]struct{}, len(asns))
+	for _, asn := range asns {
+		set[asn] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,385 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	countries := writeDatabase(t, map[string]map[string]interface{}{
+		"1.1.1.0/24": {"country": map[string]interface{}{"iso_code": "FR"}},
+		"2.2.2.0/24": {"country": map[string]interface{}{"iso_code": "US"}},
+	})
+	asns := writeDatabase(t, map[string]map[string]interface{}{
+		"1.1.1.0/24": {"autonomous_system_number": uint32(13335), "autonomous_system_organization": "Cloudflare"},
+		"2.2.2.0/24": {"autonomous_system_number": uint32(15169), "autonomous_system_organization": "Google"},
+	})
+
+	path := filepath.Join(t.TempDir(), "countries.mmdb")
+	err := os.WriteFile(path, countries, 0o600)
+	require.NoError(t, err)
+
+	databases := []Database{
+		{Path: path},
+		{Secret: &SecretReference{Name: "asn", Namespace: "default"}, Content: asns},
+	}
+
+	tests := []struct {
+		desc        string
+		cfg         Config
+		clientIP    string
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			desc: "allowed country",
+			cfg: Config{
+				AllowedCountries: []string{"fr"},
+				ForwardHeaders: map[string]string{
+					"X-Country":         FieldCountry,
+					"X-Asn":             FieldASN,
+					"X-As-Organization": FieldASOrganization,
+				},
+			},
+			clientIP:   "1.1.1.1",
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"X-Country":         "FR",
+				"X-Asn":             "13335",
+				"X-As-Organization": "Cloudflare",
+			},
+		},
+		{
+			desc:       "country not allowed",
+			cfg:        Config{AllowedCountries: []string{"FR"}},
+			clientIP:   "2.2.2.2",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "denied ASN",
+			cfg:        Config{DeniedASNs: []uint{15169}},
+			clientIP:   "2.2.2.2",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "ASN not denied",
+			cfg:        Config{DeniedASNs: []uint{15169}},
+			clientIP:   "1.1.1.1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "allowed country from a ASN not allowed",
+			cfg:        Config{AllowedCountries: []string{"FR"}, AllowedASNs: []uint{15169}},
+			clientIP:   "1.1.1.1",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "unknown location with an allow list",
+			cfg:        Config{AllowedCountries: []string{"FR"}},
+			clientIP:   "3.3.3.3",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "unknown location with a deny list",
+			cfg:        Config{DeniedCountries: []string{"US"}},
+			clientIP:   "3.3.3.3",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "no client IP",
+			cfg:        Config{DeniedCountries: []string{"US"}},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "lookup error",
+			cfg:        Config{DeniedCountries: []string{"US"}},
+			clientIP:   "2001:db8::1",
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.cfg.Databases = databases
+			handler, err := NewHandler(&test.cfg, "acp@ns")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = ""
+			if test.clientIP != "" {
+				req.Header.Set("X-Forwarded-For", test.clientIP)
+			}
+			rw := httptest.NewRecorder()
+
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.wantStatus, rw.Code)
+			for name, value := range test.wantHeaders {
+				assert.Equal(t, value, rw.Header().Get(name))
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_forgedForwardedFor(t *testing.T) {
+	db := writeDatabase(t, map[string]map[string]interface{}{
+		"1.1.1.0/24": {"country": map[string]interface{}{"iso_code": "FR"}},
+		"2.2.2.0/24": {"country": map[string]interface{}{"iso_code": "US"}},
+	})
+
+	tests := []struct {
+		desc         string
+		cfg          *clientip.Config
+		forwardedFor string
+	}{
+		{
+			desc:         "default strategy",
+			forwardedFor: "1.1.1.1, 2.2.2.2",
+		},
+		{
+			desc:         "trusted proxies",
+			cfg:          &clientip.Config{TrustedIPs: []string{"10.0.0.0/8"}},
+			forwardedFor: "1.1.1.1, 2.2.2.2, 10.0.0.1",
+		},
+		{
+			desc:         "depth",
+			cfg:          &clientip.Config{Depth: 2},
+			forwardedFor: "1.1.1.1, 2.2.2.2, 10.0.0.1",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := NewHandler(&Config{
+				Databases:        []Database{{Content: db, Secret: &SecretReference{}}},
+				AllowedCountries: []string{"FR"},
+			}, "acp@ns")
+			require.NoError(t, err)
+
+			ips, err := clientip.NewStrategy(test.cfg)
+			require.NoError(t, err)
+
+			// The left-most IP, located in an allowed country, is forged by the client.
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Forwarded-For", test.forwardedFor)
+			rw := httptest.NewRecorder()
+
+			ips.Wrap(handler).ServeHTTP(rw, req)
+
+			assert.Equal(t, http.StatusForbidden, rw.Code)
+		})
+	}
+}
+
+func TestNewHandler_invalidConfig(t *testing.T) {
+	db := writeDatabase(t, map[string]map[string]interface{}{
+		"1.1.1.0/24": {"country": map[string]interface{}{"iso_code": "FR"}},
+	})
+
+	tests := []struct {
+		desc string
+		cfg  Config
+	}{
+		{
+			desc: "no database",
+			cfg:  Config{AllowedCountries: []string{"FR"}},
+		},
+		{
+			desc: "no rule",
+			cfg:  Config{Databases: []Database{{Content: db, Secret: &SecretReference{}}}},
+		},
+		{
+			desc: "unsupported forwarded field",
+			cfg: Config{
+				Databases:        []Database{{Content: db, Secret: &SecretReference{}}},
+				AllowedCountries: []string{"FR"},
+				ForwardHeaders:   map[string]string{"X-City": "city"},
+			},
+		},
+		{
+			desc: "missing database file",
+			cfg: Config{
+				Databases:        []Database{{Path: filepath.Join(t.TempDir(), "missing.mmdb")}},
+				AllowedCountries: []string{"FR"},
+			},
+		},
+		{
+			desc: "invalid database",
+			cfg: Config{
+				Databases:        []Database{{Content: []byte("not a database"), Secret: &SecretReference{}}},
+				AllowedCountries: []string{"FR"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewHandler(&test.cfg, "acp@ns")
+			assert.Error(t, err)
+		})
+	}
+}
+
+// writeDatabase builds an IPv4 MaxMind DB database, with 24 bits records, holding the given data indexed by network.
+// Networks must not overlap.
+func writeDatabase(t *testing.T, networks map[string]map[string]interface{}) []byte {
+	t.Helper()
+
+	type node struct {
+		// records are either a child *node, the offset of their data, as an int, or nil if empty.
+		records [2]interface{}
+	}
+
+	var data bytes.Buffer
+	root := &node{}
+	for cidr, value := range networks {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+
+		offset := data.Len()
+		encodeValue(&data, value)
+
+		ip := ipNet.IP.To4()
+		ones, _ := ipNet.Mask.Size()
+
+		n := root
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - i%8)) & 1
+			if i == ones-1 {
+				n.records[bit] = offset
+				break
+			}
+
+			child, ok := n.records[bit].(*node)
+			if !ok {
+				child = &node{}
+				n.records[bit] = child
+			}
+			n = child
+		}
+	}
+
+	nodes := []*node{root}
+	ids := map[*node]int{root: 0}
+	for i := 0; i < len(nodes); i++ {
+		for _, record := range nodes[i].records {
+			if child, ok := record.(*node); ok {
+				ids[child] = len(nodes)
+				nodes = append(nodes, child)
+			}
+		}
+	}
+
+	var db bytes.Buffer
+	for _, n := range nodes {
+		for _, record := range n.records {
+			value := len(nodes)
+			switch r := record.(type) {
+			case *node:
+				value = ids[r]
+			case int:
+				value = len(nodes) + 16 + r
+			}
+
+			db.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+
+	db.Write(make([]byte, 16))
+	db.Write(data.Bytes())
+	db.WriteString("\xab\xcd\xefMaxMind.com")
+	encodeValue(&db, map[string]interface{}{
+		"node_count":  uint32(len(nodes)),
+		"record_size": uint16(24),
+		"ip_version":  uint16(4),
+	})
+
+	return db.Bytes()
+}
+
+// encodeValue encodes the given value using the MaxMind DB data section format. Only values smaller than 285 bytes
+// are supported.
+func encodeValue(buf *bytes.Buffer, value interface{}) {
+	const (
+		typeString = 2
+		typeUint16 = 5
+		typeUint32 = 6
+		typeMap    = 7
+	)
+
+	switch v := value.(type) {
+	case string:
+		writeControl(buf, typeString, len(v))
+		buf.WriteString(v)
+
+	case uint16:
+		b := bytes.TrimLeft(binary.BigEndian.AppendUint16(nil, v), "\x00")
+		writeControl(buf, typeUint16, len(b))
+		buf.Write(b)
+
+	case uint32:
+		b := bytes.TrimLeft(binary.BigEndian.AppendUint32(nil, v), "\x00")
+		writeControl(buf, typeUint32, len(b))
+		buf.Write(b)
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeControl(buf, typeMap, len(v))
+		for _, key := range keys {
+			encodeValue(buf, key)
+			encodeValue(buf, v[key])
+		}
+	}
+}
+
+func writeControl(buf *bytes.Buffer, typ byte, size int) {
+	if size < 29 {
+		buf.WriteByte(typ<<5 | byte(size))
+		return
+	}
+
+	buf.WriteByte(typ<<5 | 29)
+	buf.WriteByte(byte(size - 29))
+}
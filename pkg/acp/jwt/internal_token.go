@@ -0,0 +1,239 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
+	"github.com/traefik/hub-agent-kubernetes/pkg/pemkey"
+)
+
+const defaultInternalTokenTTL = 5 * time.Minute
+
+// InternalTokenConfig configures the internal JWT minted by a JWT ACP handler once a JWT validated. The internal
+// JWT is forwarded to the backends in place of the original JWT, for them to never see end-user tokens.
+type InternalTokenConfig struct {
+	// SigningSecret is the secret used to sign internal JWTs with HS256. Either it or a private key must be set.
+	SigningSecret              string `json:"signingSecret,omitempty"`
+	SigningSecretBase64Encoded bool   `json:"signingSecretBase64Encoded,omitempty"`
+	// PrivateKey is the PEM encoded RSA or ECDSA private key used to sign internal JWTs with RS256 or ES256/384/512.
+	PrivateKey string `json:"privateKey,omitempty"`
+	// KeyID is set as the `kid` header of internal JWTs, for backends to find the key to verify them with.
+	KeyID    string `json:"keyId,omitempty"`
+	Issuer   string `json:"issuer,omitempty"`
+	Audience string `json:"audience,omitempty"`
+	// TTL is the lifetime of internal JWTs. Internal JWTs never outlive the JWT they are minted from.
+	// Defaults to 5 minutes.
+	TTL time.Duration `json:"ttl,omitempty"`
+	// Claims maps claim names of internal JWTs to the claims of the validated JWT holding their value. The `sub`
+	// claim is always copied.
+	Claims map[string]string `json:"claims,omitempty"`
+	// Header is the header internal JWTs are forwarded in. Defaults to the Authorization header, using the Bearer
+	// scheme. The Authorization header is always stripped.
+	Header string `json:"header,omitempty"`
+}
+
+// isReservedClaim returns whether the given claim is set by the internal token minter, in which case it can't be
+// copied from validated JWTs.
+func isReservedClaim(name string) bool {
+	switch name {
+	case "iss", "aud", "exp", "nbf", "iat", "jti":
+		return true
+	default:
+		return false
+	}
+}
+
+// tokenMinter mints internal JWTs from validated JWTs.
+type tokenMinter struct {
+	method jwt.SigningMethod
+	key    interface{}
+	keyID  string
+
+	issuer   string
+	audience string
+	ttl      time.Duration
+	claims   map[string]string
+	header   string
+}
+
+func newTokenMinter(cfg *InternalTokenConfig) (*tokenMinter, error) {
+	if cfg.TTL < 0 {
+		return nil, errors.New("internal token TTL must not be negative")
+	}
+
+	for name := range cfg.Claims {
+		if isReservedClaim(name) {
+			return nil, fmt.Errorf("internal token claim %q is reserved", name)
+		}
+	}
+
+	m := &tokenMinter{
+		keyID:    cfg.KeyID,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.TTL,
+		claims:   cfg.Claims,
+		header:   http.CanonicalHeaderKey(cfg.Header),
+	}
+	if m.ttl == 0 {
+		m.ttl = defaultInternalTokenTTL
+	}
+	if m.header == "" {
+		m.header = "Authorization"
+	}
+
+	switch {
+	case cfg.SigningSecret != "" && cfg.PrivateKey != "":
+		return nil, errors.New("internal token signing secret and private key are mutually exclusive")
+
+	case cfg.SigningSecret != "":
+		secret := []byte(cfg.SigningSecret)
+		if cfg.SigningSecretBase64Encoded {
+			var err error
+			secret, err = base64.StdEncoding.DecodeString(cfg.SigningSecret)
+			if err != nil {
+				return nil, fmt.Errorf("decode base64-encoded internal token signing secret: %w", err)
+			}
+		}
+
+		m.method = jwt.SigningMethodHS256
+		m.key = secret
+
+	case cfg.PrivateKey != "":
+		var err error
+		m.method, m.key, err = parsePrivateKey(cfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("parse internal token private key: %w", err)
+		}
+
+	default:
+		return nil, errors.New("internal token signing secret or private key is required")
+	}
+
+	return m, nil
+}
+
+// parsePrivateKey parses the given PEM encoded private key and returns the signing method to use along with it.
+func parsePrivateKey(privateKey string) (jwt.SigningMethod, interface{}, error) {
+	key, err := pemkey.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, k, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256, k, nil
+		case 384:
+			return jwt.SigningMethodES384, k, nil
+		case 521:
+			return jwt.SigningMethodES512, k, nil
+		default:
+			return nil, nil, fmt.Errorf("unsupported elliptic curve %q", k.Curve.Params().Name)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// mint mints an internal JWT at the given time from the given validated JWT claims.
+func (m *tokenMinter) mint(claims jwt.MapClaims, now time.Time) (string, error) {
+	exp := now.Add(m.ttl)
+	if _, ok := claims["exp"]; ok {
+		tokExp, err := claimTime(claims, "exp")
+		if err != nil {
+			return "", err
+		}
+		if tokExp.Before(exp) {
+			exp = tokExp
+		}
+	}
+
+	jti, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("new token ID: %w", err)
+	}
+
+	internal := jwt.MapClaims{
+		"iat": now.Unix(),
+		"exp": exp.Unix(),
+		"jti": jti,
+	}
+	if m.issuer != "" {
+		internal["iss"] = m.issuer
+	}
+	if m.audience != "" {
+		internal["aud"] = m.audience
+	}
+	if sub, ok := claims["sub"]; ok {
+		internal["sub"] = sub
+	}
+
+	for name, claim := range m.claims {
+		if value, ok := expr.ResolveClaim(claim, claims); ok {
+			internal[name] = value
+		}
+	}
+
+	tok := jwt.NewWithClaims(m.method, internal)
+	if m.keyID != "" {
+		tok.Header["kid"] = m.keyID
+	}
+
+	signed, err := tok.SignedString(m.key)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+
+	return signed, nil
+}
+
+// setHeaders sets the given internal JWT on the given headers, stripping the Authorization header holding the
+// original JWT.
+func (m *tokenMinter) setHeaders(hdr http.Header, tok string) {
+	if m.header == "Authorization" {
+		hdr.Set("Authorization", "Bearer "+tok)
+		return
+	}
+
+	hdr.Set("Authorization", "")
+	hdr.Set(m.header, tok)
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
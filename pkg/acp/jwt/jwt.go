@@ -21,6 +21,7 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -28,10 +29,12 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	jwtreq "github.com/golang-jwt/jwt/v4/request"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
 )
 
@@ -42,62 +45,81 @@ type Config struct {
 	PublicKey                  string            `json:"publicKey,omitempty"`
 	JWKsFile                   FileOrContent     `json:"jwksFile,omitempty"`
 	JWKsURL                    string            `json:"jwksUrl,omitempty"`
+	Issuers                    []IssuerConfig    `json:"issuers,omitempty"`
 	StripAuthorizationHeader   bool              `json:"stripAuthorizationHeader,omitempty"`
 	ForwardHeaders             map[string]string `json:"forwardHeaders,omitempty"`
 	TokenQueryKey              string            `json:"tokenQueryKey,omitempty"`
 	Claims                     string            `json:"claims,omitempty"`
+	Authorization              *authz.Config     `json:"authorization,omitempty"`
+
+	// Leeway is the tolerated clock skew between the issuers and the handler when validating the `exp`, `nbf` and
+	// `iat` claims.
+	Leeway time.Duration `json:"leeway,omitempty"`
+	// MaxTokenAge is the maximum age of the JWTs, computed from their `iat` claim, which is required when set.
+	MaxTokenAge time.Duration `json:"maxTokenAge,omitempty"`
+	// InternalToken configures the internal JWT forwarded to the backends in place of the validated JWT.
+	InternalToken *InternalTokenConfig `json:"internalToken,omitempty"`
 }
 
-func (cfg *Config) keySet() (KeySet, error) {
-	if cfg == nil {
-		return nil, nil
-	}
-
-	if cfg.JWKsFile != "" {
-		if cfg.JWKsFile.IsPath() {
-			return NewFileKeySet(cfg.JWKsFile.String()), nil
-		}
-
-		ks, err := NewContentKeySet([]byte(cfg.JWKsFile))
-		if err != nil {
-			return nil, fmt.Errorf("new content key set: %w. If using a file path, maybe the file does not exist", err)
-		}
-		return ks, nil
-	}
-
-	if cfg.JWKsURL != "" && !strings.HasPrefix(cfg.JWKsURL, "/") {
-		return NewRemoteKeySet(cfg.JWKsURL), nil
-	}
-
-	return nil, nil
+// IssuerConfig configures an issuer whose JWTs are accepted by a JWT ACP handler. JWTs are matched to their issuer
+// using their `iss` claim.
+type IssuerConfig struct {
+	Issuer                     string        `json:"issuer"`
+	SigningSecret              string        `json:"signingSecret,omitempty"`
+	SigningSecretBase64Encoded bool          `json:"signingSecretBase64Encoded,omitempty"`
+	PublicKey                  string        `json:"publicKey,omitempty"`
+	JWKsFile                   FileOrContent `json:"jwksFile,omitempty"`
+	JWKsURL                    string        `json:"jwksUrl,omitempty"`
+	// Audiences are the audiences JWTs must be issued for. At least one of them must be in the `aud` claim.
+	Audiences []string `json:"audiences,omitempty"`
+	// ClaimMappings maps claim names to the claims of the JWTs of this issuer holding them, for policies to rely on
+	// the same claims whatever the issuer.
+	ClaimMappings map[string]string `json:"claimMappings,omitempty"`
 }
 
 // Handler is a JWT ACP Handler.
 type Handler struct {
 	name string
 
-	signingSecret string
-	pubKey        interface{}
-	tokQryKey     string
+	tokQryKey string
 
-	// Either `keySet` or `dynKeySets` should be set at a time.
-	// If `jwksURL` is a complete URL, `keySet` is used.
-	// If `jwksURL` is a path, `dynKeySets` is used.
-	jwksURL      string
-	keySet       KeySet
-	dynKeySetsMu sync.RWMutex
-	dynKeySets   map[string]*RemoteKeySet
+	leeway      time.Duration
+	maxTokenAge time.Duration
+
+	// Either `keys` or `issuers` is set. When issuers are configured, JWTs are verified using the keys of their issuer.
+	keys    *keySource
+	issuers map[string]*issuer
 
 	stripAuthorization bool
 	fwdHeaders         map[string]string
+	minter             *tokenMinter
 
 	validateCustomClaims expr.Predicate
+	authorizer           *authz.Authorizer
+}
+
+// issuer is an issuer whose JWTs are accepted.
+type issuer struct {
+	keys          *keySource
+	audiences     []string
+	claimMappings map[string]string
 }
 
 // NewHandler returns a new JWT ACP Handler.
 func NewHandler(cfg *Config, polName string) (*Handler, error) {
-	if cfg.PublicKey == "" && cfg.SigningSecret == "" && cfg.JWKsFile == "" && cfg.JWKsURL == "" {
-		return nil, errors.New("at least a signing secret, public key or a JWKs file or URL is required")
+	hasKeys := cfg.PublicKey != "" || cfg.SigningSecret != "" || cfg.JWKsFile != "" || cfg.JWKsURL != ""
+	switch {
+	case !hasKeys && len(cfg.Issuers) == 0:
+		return nil, errors.New("at least a signing secret, public key, JWKs file or URL, or an issuer is required")
+	case hasKeys && len(cfg.Issuers) > 0:
+		return nil, errors.New("signing secret, public key and JWKs must be configured on the issuers when issuers are set")
+	}
+
+	if cfg.Leeway < 0 {
+		return nil, errors.New("leeway must not be negative")
+	}
+	if cfg.MaxTokenAge < 0 {
+		return nil, errors.New("max token age must not be negative")
 	}
 
 	var (
@@ -111,26 +133,19 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		}
 	}
 
-	signingSecret := cfg.SigningSecret
-	if cfg.SigningSecretBase64Encoded {
-		var b []byte
-		b, err = base64.StdEncoding.DecodeString(signingSecret)
+	var authorizer *authz.Authorizer
+	if cfg.Authorization != nil {
+		authorizer, err = authz.NewAuthorizer(cfg.Authorization)
 		if err != nil {
-			return nil, fmt.Errorf("decode base64-encoded signing secret: %w", err)
+			return nil, fmt.Errorf("create authorizer: %w", err)
 		}
-		signingSecret = string(b)
 	}
 
-	var pubKey interface{}
-	if cfg.PublicKey != "" {
-		block, _ := pem.Decode([]byte(cfg.PublicKey))
-		if block == nil {
-			return nil, errors.New("empty or ill-formatted public key")
-		}
-
-		pubKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	var minter *tokenMinter
+	if cfg.InternalToken != nil {
+		minter, err = newTokenMinter(cfg.InternalToken)
 		if err != nil {
-			return nil, fmt.Errorf("parse public key: %w", err)
+			return nil, fmt.Errorf("make internal token minter: %w", err)
 		}
 	}
 
@@ -139,30 +154,61 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		tokenQueryKey = cfg.TokenQueryKey
 	}
 
-	ks, err := cfg.keySet()
-	if err != nil {
-		return nil, err
-	}
-
-	return &Handler{
+	h := &Handler{
 		name:                 polName,
-		signingSecret:        signingSecret,
-		pubKey:               pubKey,
-		jwksURL:              cfg.JWKsURL,
-		keySet:               ks,
-		dynKeySets:           make(map[string]*RemoteKeySet),
 		stripAuthorization:   cfg.StripAuthorizationHeader,
 		fwdHeaders:           cfg.ForwardHeaders,
+		minter:               minter,
 		tokQryKey:            tokenQueryKey,
+		leeway:               cfg.Leeway,
+		maxTokenAge:          cfg.MaxTokenAge,
 		validateCustomClaims: pred,
-	}, nil
+		authorizer:           authorizer,
+	}
+
+	if len(cfg.Issuers) == 0 {
+		h.keys, err = newKeySource(cfg.SigningSecret, cfg.SigningSecretBase64Encoded, cfg.PublicKey, cfg.JWKsFile, cfg.JWKsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return h, nil
+	}
+
+	h.issuers = make(map[string]*issuer, len(cfg.Issuers))
+	for _, issCfg := range cfg.Issuers {
+		if issCfg.Issuer == "" {
+			return nil, errors.New("issuer is required")
+		}
+		if _, ok := h.issuers[issCfg.Issuer]; ok {
+			return nil, fmt.Errorf("duplicate issuer %q", issCfg.Issuer)
+		}
+		if issCfg.PublicKey == "" && issCfg.SigningSecret == "" && issCfg.JWKsFile == "" && issCfg.JWKsURL == "" {
+			return nil, fmt.Errorf("issuer %q: at least a signing secret, public key or a JWKs file or URL is required", issCfg.Issuer)
+		}
+
+		var keys *keySource
+		keys, err = newKeySource(issCfg.SigningSecret, issCfg.SigningSecretBase64Encoded, issCfg.PublicKey, issCfg.JWKsFile, issCfg.JWKsURL)
+		if err != nil {
+			return nil, fmt.Errorf("issuer %q: %w", issCfg.Issuer, err)
+		}
+
+		h.issuers[issCfg.Issuer] = &issuer{
+			keys:          keys,
+			audiences:     issCfg.Audiences,
+			claimMappings: issCfg.ClaimMappings,
+		}
+	}
+
+	return h, nil
 }
 
 func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	l := log.With().Str("handler_type", "JWT").Str("handler_name", h.name).Logger()
 
 	extractor := jwtExtractor{tokQryKey: h.tokQryKey}
-	p := &jwt.Parser{UseJSONNumber: true}
+	// Time based claims are validated once the signature verified, to tolerate clock skews.
+	p := &jwt.Parser{UseJSONNumber: true, SkipClaimsValidation: true}
 	tok, err := jwtreq.ParseFromRequest(req, extractor, h.keyFunc(req.Context()), jwtreq.WithParser(p))
 	if err != nil {
 		var jwtErr *jwt.ValidationError
@@ -176,14 +222,39 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	claims := tok.Claims.(jwt.MapClaims)
+	if err = h.validateTimeClaims(claims, time.Now()); err != nil {
+		l.Error().Err(err).Msg("Invalid JWT")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if h.issuers != nil {
+		// The issuer is known, JWTs of unknown issuers being rejected while looking up their key.
+		iss := h.issuers[claims["iss"].(string)]
+
+		if !iss.allowsAudience(claims) {
+			l.Error().Interface("aud", claims["aud"]).Msg("Unexpected JWT audience")
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		claims = iss.mapClaims(claims)
+	}
+
 	if h.validateCustomClaims != nil {
-		if !h.validateCustomClaims(tok.Claims.(jwt.MapClaims)) {
+		if !h.validateCustomClaims(claims) {
 			rw.WriteHeader(http.StatusForbidden)
 			return
 		}
 	}
 
-	hdrs, err := expr.PluckClaims(h.fwdHeaders, tok.Claims.(jwt.MapClaims))
+	if h.authorizer != nil && !h.authorizer.Authorize(req, claims) {
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	hdrs, err := expr.PluckClaims(h.fwdHeaders, claims)
 	if err != nil {
 		l.Error().Err(err).Msg("Unable to set forwarded header")
 		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -196,108 +267,311 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 	}
 
-	if h.stripAuthorization {
+	if h.minter != nil {
+		var internalTok string
+		internalTok, err = h.minter.mint(claims, time.Now())
+		if err != nil {
+			l.Error().Err(err).Msg("Unable to mint internal JWT")
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		h.minter.setHeaders(rw.Header(), internalTok)
+	} else if h.stripAuthorization {
 		rw.Header().Add("Authorization", "")
 	}
 
 	rw.WriteHeader(http.StatusOK)
 }
 
+// validateTimeClaims validates the `exp`, `nbf` and `iat` claims of a JWT at the given time, tolerating the configured
+// leeway, along with the age of the JWT when a maximum token age is configured.
+func (h *Handler) validateTimeClaims(claims jwt.MapClaims, now time.Time) error {
+	if !claims.VerifyExpiresAt(now.Add(-h.leeway).Unix(), false) {
+		return errors.New("token is expired")
+	}
+	if !claims.VerifyNotBefore(now.Add(h.leeway).Unix(), false) {
+		return errors.New("token is not valid yet")
+	}
+	if !claims.VerifyIssuedAt(now.Add(h.leeway).Unix(), false) {
+		return errors.New("token used before issued")
+	}
+
+	if h.maxTokenAge == 0 {
+		return nil
+	}
+
+	iat, err := claimTime(claims, "iat")
+	if err != nil {
+		return err
+	}
+	if now.Sub(iat) > h.maxTokenAge+h.leeway {
+		return errors.New("token is too old")
+	}
+
+	return nil
+}
+
+// claimTime returns the time held, as a NumericDate, by the claim with the given name.
+func claimTime(claims jwt.MapClaims, name string) (time.Time, error) {
+	var (
+		seconds float64
+		err     error
+	)
+	switch value := claims[name].(type) {
+	case nil:
+		return time.Time{}, fmt.Errorf("expected `%s` claim to be set", name)
+	case json.Number:
+		seconds, err = value.Float64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("expected `%s` claim to be a number: %w", name, err)
+		}
+	case float64:
+		seconds = value
+	default:
+		return time.Time{}, fmt.Errorf("expected `%s` claim to be a number", name)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))), nil
+}
+
 // keyFunc returns a function to find the correct key to validate its given JWT's signature.
 func (h *Handler) keyFunc(ctx context.Context) jwt.Keyfunc {
 	return func(tok *jwt.Token) (key interface{}, err error) {
-		var prefix string
-		if len(tok.Method.Alg()) > 2 {
-			prefix = tok.Method.Alg()[:2]
+		if h.issuers == nil {
+			return h.keys.key(ctx, tok)
 		}
 
-		kid, _ := tok.Header["kid"].(string)
+		iss, err := tokenIssuer(tok)
+		if err != nil {
+			return nil, err
+		}
 
-		switch prefix {
-		case "RS", "ES":
-			if kid != "" {
-				return h.resolveKey(ctx, tok, kid)
-			}
+		i, ok := h.issuers[iss]
+		if !ok {
+			return nil, fmt.Errorf("untrusted issuer %q", iss)
+		}
 
-			if h.pubKey == nil {
-				return nil, errors.New("no public key configured")
-			}
-			return h.pubKey, nil
+		return i.keys.key(ctx, tok)
+	}
+}
 
-		case "HS":
-			if h.signingSecret == "" {
-				return nil, errors.New("no signing secret configured")
-			}
-			return []byte(h.signingSecret), nil
+// allowsAudience returns whether the given claims hold one of the audiences required by the issuer. Any audience is
+// allowed when the issuer doesn't require any.
+func (i *issuer) allowsAudience(claims jwt.MapClaims) bool {
+	if len(i.audiences) == 0 {
+		return true
+	}
 
-		default:
-			return nil, fmt.Errorf("unsupported signing algorithm %q", tok.Method.Alg())
+	for _, aud := range i.audiences {
+		if claims.VerifyAudience(aud, true) {
+			return true
 		}
 	}
+
+	return false
 }
 
-// resolveKey finds the correct key that was used to sign the given JWT.
-func (h *Handler) resolveKey(ctx context.Context, tok *jwt.Token, kid string) (key interface{}, err error) {
-	ks := h.keySet
-	if ks == nil {
-		c, ok := tok.Claims.(jwt.MapClaims)
+// mapClaims returns the given claims along with the claims mapped by the issuer. Mapped claims are only taken from
+// the claims they are mapped to, for JWTs not to set them directly.
+func (i *issuer) mapClaims(claims jwt.MapClaims) jwt.MapClaims {
+	if len(i.claimMappings) == 0 {
+		return claims
+	}
+
+	mapped := make(jwt.MapClaims, len(claims)+len(i.claimMappings))
+	for name, value := range claims {
+		mapped[name] = value
+	}
+
+	for name, claim := range i.claimMappings {
+		value, ok := expr.ResolveClaim(claim, claims)
 		if !ok {
-			return nil, errors.New("invalid JWT claims")
+			delete(mapped, name)
+			continue
 		}
+		mapped[name] = value
+	}
+
+	return mapped
+}
+
+// tokenIssuer returns the issuer of the given JWT.
+func tokenIssuer(tok *jwt.Token) (string, error) {
+	c, ok := tok.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", errors.New("invalid JWT claims")
+	}
 
-		if _, ok = c["iss"]; !ok {
-			return nil, errors.New("expected `iss` claim to be set")
+	if _, ok = c["iss"]; !ok {
+		return "", errors.New("expected `iss` claim to be set")
+	}
+
+	iss, ok := c["iss"].(string)
+	if !ok {
+		return "", errors.New("expected `iss` claim to be a string")
+	}
+
+	return iss, nil
+}
+
+// keySource holds the keys used to verify the signature of JWTs.
+type keySource struct {
+	signingSecret string
+	pubKey        interface{}
+
+	// Either `keySet` or `dynKeySets` should be set at a time.
+	// If `jwksURL` is a complete URL, `keySet` is used.
+	// If `jwksURL` is a path, `dynKeySets` is used.
+	jwksURL      string
+	keySet       KeySet
+	dynKeySetsMu sync.RWMutex
+	dynKeySets   map[string]*RemoteKeySet
+}
+
+func newKeySource(signingSecret string, signingSecretBase64Encoded bool, publicKey string, jwksFile FileOrContent, jwksURL string) (*keySource, error) {
+	if signingSecretBase64Encoded {
+		b, err := base64.StdEncoding.DecodeString(signingSecret)
+		if err != nil {
+			return nil, fmt.Errorf("decode base64-encoded signing secret: %w", err)
 		}
+		signingSecret = string(b)
+	}
 
-		if _, ok = c["iss"].(string); !ok {
-			return nil, errors.New("expected `iss` claim to be a string")
+	var pubKey interface{}
+	if publicKey != "" {
+		block, _ := pem.Decode([]byte(publicKey))
+		if block == nil {
+			return nil, errors.New("empty or ill-formatted public key")
+		}
+
+		var err error
+		pubKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+	}
+
+	ks, err := newKeySet(jwksFile, jwksURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keySource{
+		signingSecret: signingSecret,
+		pubKey:        pubKey,
+		jwksURL:       jwksURL,
+		keySet:        ks,
+		dynKeySets:    make(map[string]*RemoteKeySet),
+	}, nil
+}
+
+func newKeySet(jwksFile FileOrContent, jwksURL string) (KeySet, error) {
+	if jwksFile != "" {
+		if jwksFile.IsPath() {
+			return NewFileKeySet(jwksFile.String()), nil
+		}
+
+		ks, err := NewContentKeySet([]byte(jwksFile))
+		if err != nil {
+			return nil, fmt.Errorf("new content key set: %w. If using a file path, maybe the file does not exist", err)
+		}
+		return ks, nil
+	}
+
+	if jwksURL != "" && !strings.HasPrefix(jwksURL, "/") {
+		return NewRemoteKeySet(jwksURL), nil
+	}
+
+	return nil, nil
+}
+
+// key finds the correct key to validate the given JWT's signature.
+func (k *keySource) key(ctx context.Context, tok *jwt.Token) (interface{}, error) {
+	var prefix string
+	if len(tok.Method.Alg()) > 2 {
+		prefix = tok.Method.Alg()[:2]
+	}
+
+	kid, _ := tok.Header["kid"].(string)
+
+	switch prefix {
+	case "RS", "ES":
+		if kid != "" {
+			return k.resolveKey(ctx, tok, kid)
+		}
+
+		if k.pubKey == nil {
+			return nil, errors.New("no public key configured")
+		}
+		return k.pubKey, nil
+
+	case "HS":
+		if k.signingSecret == "" {
+			return nil, errors.New("no signing secret configured")
+		}
+		return []byte(k.signingSecret), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", tok.Method.Alg())
+	}
+}
+
+// resolveKey finds the correct key that was used to sign the given JWT.
+func (k *keySource) resolveKey(ctx context.Context, tok *jwt.Token, kid string) (key interface{}, err error) {
+	ks := k.keySet
+	if ks == nil {
+		var iss string
+		iss, err = tokenIssuer(tok)
+		if err != nil {
+			return nil, err
 		}
 
-		ks, err = h.remoteKeySet(c["iss"].(string))
+		ks, err = k.remoteKeySet(iss)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	k, err := ks.Key(ctx, kid)
+	jwk, err := ks.Key(ctx, kid)
 	if err != nil {
 		return nil, fmt.Errorf("error searching for JSON web key: %w", err)
 	}
 
-	if k == nil {
+	if jwk == nil {
 		return nil, fmt.Errorf("no key with id %q found", kid)
 	}
-	return k.Key, nil
+	return jwk.Key, nil
 }
 
 // remoteKeySet returns the remote key set for the given issuer, or creates a new one if none is found.
-func (h *Handler) remoteKeySet(iss string) (*RemoteKeySet, error) {
+func (k *keySource) remoteKeySet(iss string) (*RemoteKeySet, error) {
 	base, err := url.Parse(iss)
 	if err != nil {
 		return nil, err
 	}
 
-	parsed, err := base.Parse(h.jwksURL)
+	parsed, err := base.Parse(k.jwksURL)
 	if err != nil {
 		return nil, err
 	}
 
 	ksURL := parsed.String()
 
-	h.dynKeySetsMu.RLock()
-	rks, ok := h.dynKeySets[ksURL]
-	h.dynKeySetsMu.RUnlock()
+	k.dynKeySetsMu.RLock()
+	rks, ok := k.dynKeySets[ksURL]
+	k.dynKeySetsMu.RUnlock()
 	if ok {
 		return rks, nil
 	}
 
-	h.dynKeySetsMu.Lock()
-	rks = h.dynKeySets[ksURL]
+	k.dynKeySetsMu.Lock()
+	rks = k.dynKeySets[ksURL]
 	if rks == nil {
 		rks = NewRemoteKeySet(ksURL)
-		h.dynKeySets[ksURL] = rks
+		k.dynKeySets[ksURL] = rks
 	}
-	h.dynKeySetsMu.Unlock()
+	k.dynKeySetsMu.Unlock()
 
 	return rks, nil
 }
@@ -19,11 +19,19 @@ package jwt
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +39,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
 )
 
 const (
@@ -84,6 +93,76 @@ func TestNew(t *testing.T) {
 			jwtCfg:  Config{JWKsURL: "http://example.com"},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "issuers",
+			jwtCfg: Config{Issuers: []IssuerConfig{
+				{Issuer: "https://idp1.example.com", SigningSecret: "foobar"},
+				{Issuer: "https://idp2.example.com", JWKsURL: "/.well-known/jwks.json"},
+			}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "issuers and keys",
+			jwtCfg: Config{
+				SigningSecret: "foobar",
+				Issuers:       []IssuerConfig{{Issuer: "https://idp.example.com", SigningSecret: "foobar"}},
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "issuer without keys",
+			jwtCfg:  Config{Issuers: []IssuerConfig{{Issuer: "https://idp.example.com"}}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "issuer without name",
+			jwtCfg:  Config{Issuers: []IssuerConfig{{SigningSecret: "foobar"}}},
+			wantErr: assert.Error,
+		},
+		{
+			name: "duplicate issuers",
+			jwtCfg: Config{Issuers: []IssuerConfig{
+				{Issuer: "https://idp.example.com", SigningSecret: "foobar"},
+				{Issuer: "https://idp.example.com", SigningSecret: "bibi"},
+			}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "issuer with invalid public key",
+			jwtCfg:  Config{Issuers: []IssuerConfig{{Issuer: "https://idp.example.com", PublicKey: invalidPubKey}}},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "internal token",
+			jwtCfg:  Config{SigningSecret: "foobar", InternalToken: &InternalTokenConfig{SigningSecret: "internal"}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "internal token without key",
+			jwtCfg:  Config{SigningSecret: "foobar", InternalToken: &InternalTokenConfig{Issuer: "gateway"}},
+			wantErr: assert.Error,
+		},
+		{
+			name: "internal token with signing secret and private key",
+			jwtCfg: Config{
+				SigningSecret: "foobar",
+				InternalToken: &InternalTokenConfig{SigningSecret: "internal", PrivateKey: validPubKey},
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "internal token with invalid private key",
+			jwtCfg:  Config{SigningSecret: "foobar", InternalToken: &InternalTokenConfig{PrivateKey: validPubKey}},
+			wantErr: assert.Error,
+		},
+		{
+			name: "internal token with reserved claim",
+			jwtCfg: Config{
+				SigningSecret: "foobar",
+				InternalToken: &InternalTokenConfig{SigningSecret: "internal", Claims: map[string]string{"exp": "exp"}},
+			},
+			wantErr: assert.Error,
+		},
 	}
 
 	for _, test := range tests {
@@ -151,6 +230,24 @@ func TestServeHTTP(t *testing.T) {
 			token:          validJWTWithNestedClaim,
 			wantStatusCode: http.StatusForbidden,
 		},
+		{
+			name: "authorization rule allows the request",
+			jwtCfg: Config{
+				SigningSecret: "bibi",
+				Authorization: &authz.Config{Rules: []string{`method == "GET" && claims.grp == "admin"`}},
+			},
+			token:          validJWT,
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "authorization rule denies the request",
+			jwtCfg: Config{
+				SigningSecret: "bibi",
+				Authorization: &authz.Config{Rules: []string{`claims.grp == "developers"`}},
+			},
+			token:          validJWT,
+			wantStatusCode: http.StatusForbidden,
+		},
 		{
 			name: "group header is forwarded",
 			jwtCfg: Config{
@@ -214,6 +311,331 @@ func TestServeHTTP(t *testing.T) {
 	}
 }
 
+func TestServeHTTP_issuers(t *testing.T) {
+	cfg := Config{
+		Issuers: []IssuerConfig{
+			{
+				Issuer:        "https://idp1.example.com",
+				SigningSecret: "secret1",
+				Audiences:     []string{"books", "orders"},
+			},
+			{
+				Issuer:        "https://idp2.example.com",
+				SigningSecret: "secret2",
+				ClaimMappings: map[string]string{"grp": "realm.group"},
+			},
+		},
+		Claims:         "Equals(`grp`, `admin`)",
+		ForwardHeaders: map[string]string{"Group": "grp"},
+	}
+
+	tests := []struct {
+		name           string
+		secret         string
+		claims         jwt.MapClaims
+		wantStatusCode int
+		wantHeader     http.Header
+	}{
+		{
+			name:           "token of the first issuer",
+			secret:         "secret1",
+			claims:         jwt.MapClaims{"iss": "https://idp1.example.com", "aud": []string{"books"}, "grp": "admin"},
+			wantStatusCode: http.StatusOK,
+			wantHeader:     http.Header{"Group": []string{"admin"}},
+		},
+		{
+			name:           "token of the second issuer with mapped claims",
+			secret:         "secret2",
+			claims:         jwt.MapClaims{"iss": "https://idp2.example.com", "realm": map[string]interface{}{"group": "admin"}},
+			wantStatusCode: http.StatusOK,
+			wantHeader:     http.Header{"Group": []string{"admin"}},
+		},
+		{
+			name:           "token signed with the key of another issuer",
+			secret:         "secret1",
+			claims:         jwt.MapClaims{"iss": "https://idp2.example.com", "realm": map[string]interface{}{"group": "admin"}},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token of an unknown issuer",
+			secret:         "secret1",
+			claims:         jwt.MapClaims{"iss": "https://idp3.example.com", "aud": "books", "grp": "admin"},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token without issuer",
+			secret:         "secret1",
+			claims:         jwt.MapClaims{"aud": "books", "grp": "admin"},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token for another audience",
+			secret:         "secret1",
+			claims:         jwt.MapClaims{"iss": "https://idp1.example.com", "aud": "users", "grp": "admin"},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token without audience",
+			secret:         "secret1",
+			claims:         jwt.MapClaims{"iss": "https://idp1.example.com", "grp": "admin"},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "token with unexpected claims",
+			secret:         "secret2",
+			claims:         jwt.MapClaims{"iss": "https://idp2.example.com", "grp": "admin"},
+			wantStatusCode: http.StatusForbidden,
+		},
+	}
+
+	handler, err := NewHandler(&cfg, "acp@my-ns")
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, test.claims).SignedString([]byte(test.secret))
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.wantStatusCode, rec.Code)
+			assert.Equal(t, len(test.wantHeader), len(rec.Header()))
+			for k := range test.wantHeader {
+				assert.Equal(t, test.wantHeader[k], rec.Header()[k])
+			}
+		})
+	}
+}
+
+func TestServeHTTP_internalToken(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecKeyDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	ecKeyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecKeyDER}))
+
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		internalToken InternalTokenConfig
+		claims        jwt.MapClaims
+		wantHeader    string
+		verifyKey     interface{}
+		wantKeyID     string
+		wantClaims    jwt.MapClaims
+		wantExp       time.Time
+	}{
+		{
+			name: "signed with a secret forwarded as bearer token",
+			internalToken: InternalTokenConfig{
+				SigningSecret: "internal",
+				Issuer:        "https://gateway.example.com",
+				Audience:      "backend",
+				Claims:        map[string]string{"groups": "realm.groups"},
+			},
+			claims: jwt.MapClaims{
+				"sub":   "john",
+				"email": "john@example.com",
+				"realm": map[string]interface{}{"groups": []string{"admin"}},
+			},
+			wantHeader: "Authorization",
+			verifyKey:  []byte("internal"),
+			wantClaims: jwt.MapClaims{
+				"sub":    "john",
+				"iss":    "https://gateway.example.com",
+				"aud":    "backend",
+				"groups": []interface{}{"admin"},
+			},
+			wantExp: now.Add(defaultInternalTokenTTL),
+		},
+		{
+			name: "signed with a private key forwarded in a custom header",
+			internalToken: InternalTokenConfig{
+				PrivateKey: ecKeyPEM,
+				KeyID:      "gateway-key",
+				TTL:        time.Minute,
+				Header:     "x-internal-token",
+			},
+			claims:     jwt.MapClaims{"sub": "john"},
+			wantHeader: "X-Internal-Token",
+			verifyKey:  &ecKey.PublicKey,
+			wantKeyID:  "gateway-key",
+			wantClaims: jwt.MapClaims{"sub": "john"},
+			wantExp:    now.Add(time.Minute),
+		},
+		{
+			name:          "not outliving the validated token",
+			internalToken: InternalTokenConfig{SigningSecret: "internal", TTL: time.Hour},
+			claims:        jwt.MapClaims{"sub": "john", "exp": now.Add(10 * time.Minute).Unix()},
+			wantHeader:    "Authorization",
+			verifyKey:     []byte("internal"),
+			wantClaims:    jwt.MapClaims{"sub": "john"},
+			wantExp:       now.Add(10 * time.Minute),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := NewHandler(&Config{SigningSecret: "secret", InternalToken: &test.internalToken}, "acp@my-ns")
+			require.NoError(t, err)
+
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, test.claims).SignedString([]byte("secret"))
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			internalToken := rec.Header().Get(test.wantHeader)
+			if test.wantHeader == "Authorization" {
+				internalToken = strings.TrimPrefix(internalToken, "Bearer ")
+			} else {
+				assert.Equal(t, []string{""}, rec.Header()["Authorization"])
+			}
+			require.NotEmpty(t, internalToken)
+			assert.NotEqual(t, token, internalToken)
+
+			var claims jwt.MapClaims
+			tok, err := jwt.ParseWithClaims(internalToken, &claims, func(*jwt.Token) (interface{}, error) {
+				return test.verifyKey, nil
+			})
+			require.NoError(t, err)
+			kid, _ := tok.Header["kid"].(string)
+			assert.Equal(t, test.wantKeyID, kid)
+
+			assert.NotEmpty(t, claims["jti"])
+			assert.InDelta(t, now.Unix(), claims["iat"], 5)
+			assert.InDelta(t, test.wantExp.Unix(), claims["exp"], 5)
+			for _, name := range []string{"jti", "iat", "exp"} {
+				delete(claims, name)
+			}
+			assert.Equal(t, test.wantClaims, claims)
+		})
+	}
+}
+
+func TestHandler_validateTimeClaims(t *testing.T) {
+	now := time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC)
+	numericDate := func(d time.Duration) json.Number {
+		return json.Number(strconv.FormatInt(now.Add(d).Unix(), 10))
+	}
+
+	tests := []struct {
+		name        string
+		leeway      time.Duration
+		maxTokenAge time.Duration
+		claims      jwt.MapClaims
+		wantErr     assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "no time claims",
+			claims:  jwt.MapClaims{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "valid time claims",
+			claims:  jwt.MapClaims{"exp": numericDate(time.Minute), "nbf": numericDate(-time.Minute), "iat": numericDate(-time.Minute)},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "expired",
+			claims:  jwt.MapClaims{"exp": numericDate(-10 * time.Second)},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "expired within leeway",
+			leeway:  30 * time.Second,
+			claims:  jwt.MapClaims{"exp": numericDate(-10 * time.Second)},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "expired beyond leeway",
+			leeway:  30 * time.Second,
+			claims:  jwt.MapClaims{"exp": numericDate(-time.Minute)},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "not valid yet",
+			claims:  jwt.MapClaims{"nbf": numericDate(10 * time.Second)},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "not valid yet within leeway",
+			leeway:  30 * time.Second,
+			claims:  jwt.MapClaims{"nbf": numericDate(10 * time.Second)},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "issued in the future",
+			claims:  jwt.MapClaims{"iat": numericDate(10 * time.Second)},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "issued in the future within leeway",
+			leeway:  30 * time.Second,
+			claims:  jwt.MapClaims{"iat": numericDate(10 * time.Second)},
+			wantErr: assert.NoError,
+		},
+		{
+			name:        "max token age without iat",
+			maxTokenAge: time.Hour,
+			claims:      jwt.MapClaims{},
+			wantErr:     assert.Error,
+		},
+		{
+			name:        "max token age with invalid iat",
+			maxTokenAge: time.Hour,
+			claims:      jwt.MapClaims{"iat": "yesterday"},
+			wantErr:     assert.Error,
+		},
+		{
+			name:        "token younger than max token age",
+			maxTokenAge: time.Hour,
+			claims:      jwt.MapClaims{"iat": numericDate(-30 * time.Minute)},
+			wantErr:     assert.NoError,
+		},
+		{
+			name:        "token older than max token age",
+			maxTokenAge: time.Hour,
+			claims:      jwt.MapClaims{"iat": numericDate(-61 * time.Minute)},
+			wantErr:     assert.Error,
+		},
+		{
+			name:        "token older than max token age within leeway",
+			leeway:      time.Minute,
+			maxTokenAge: time.Hour,
+			claims:      jwt.MapClaims{"iat": numericDate(-61 * time.Minute)},
+			wantErr:     assert.NoError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &Handler{leeway: test.leeway, maxTokenAge: test.maxTokenAge}
+
+			test.wantErr(t, h.validateTimeClaims(test.claims, now))
+		})
+	}
+}
+
 func TestExtractJWT(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -279,7 +701,7 @@ func TestKeyFunc(t *testing.T) {
 		{
 			name: "signing secret found",
 			handler: &Handler{
-				signingSecret: "signing-secret",
+				keys: &keySource{signingSecret: "signing-secret"},
 			},
 			tok:     &jwt.Token{Method: jwt.SigningMethodHS512},
 			wantKey: []byte("signing-secret"),
@@ -287,26 +709,26 @@ func TestKeyFunc(t *testing.T) {
 		},
 		{
 			name:    "no signing secret found",
-			handler: &Handler{},
+			handler: &Handler{keys: &keySource{}},
 			tok:     &jwt.Token{Method: jwt.SigningMethodHS512},
 			wantErr: assert.Error,
 		},
 		{
 			name:    "unsupported signing algorithm",
-			handler: &Handler{},
+			handler: &Handler{keys: &keySource{}},
 			tok:     &jwt.Token{Method: jwt.SigningMethodPS512},
 			wantErr: assert.Error,
 		},
 		{
 			name:    "no public key found",
-			handler: &Handler{},
+			handler: &Handler{keys: &keySource{}},
 			tok:     &jwt.Token{Method: jwt.SigningMethodRS512},
 			wantErr: assert.Error,
 		},
 		{
 			name: "public key found",
 			handler: &Handler{
-				pubKey: rsa.PublicKey{},
+				keys: &keySource{pubKey: rsa.PublicKey{}},
 			},
 			tok:     &jwt.Token{Method: jwt.SigningMethodRS512},
 			wantKey: rsa.PublicKey{},
@@ -315,7 +737,7 @@ func TestKeyFunc(t *testing.T) {
 		{
 			name: "jwks key found",
 			handler: &Handler{
-				keySet: &RemoteKeySet{
+				keys: &keySource{keySet: &RemoteKeySet{
 					expiry: time.Now().Add(60 * time.Second),
 					keys: jose.JSONWebKeySet{
 						Keys: []jose.JSONWebKey{
@@ -325,7 +747,7 @@ func TestKeyFunc(t *testing.T) {
 							},
 						},
 					},
-				},
+				}},
 			},
 			tok:     &jwt.Token{Method: jwt.SigningMethodRS512, Header: map[string]interface{}{"kid": "foo"}},
 			wantKey: rsa.PublicKey{},
@@ -334,19 +756,19 @@ func TestKeyFunc(t *testing.T) {
 		{
 			name: "jwks key not found",
 			handler: &Handler{
-				keySet: &RemoteKeySet{
+				keys: &keySource{keySet: &RemoteKeySet{
 					expiry: time.Now().Add(60 * time.Second),
 					keys: jose.JSONWebKeySet{
 						Keys: []jose.JSONWebKey{},
 					},
-				},
+				}},
 			},
 			tok:     &jwt.Token{Method: jwt.SigningMethodRS512, Header: map[string]interface{}{"kid": "foo"}},
 			wantErr: assert.Error,
 		},
 		{
 			name:    "jwks no keyset",
-			handler: &Handler{},
+			handler: &Handler{keys: &keySource{}},
 			tok:     &jwt.Token{Method: jwt.SigningMethodRS512, Header: map[string]interface{}{"kid": "foo"}},
 			wantErr: assert.Error,
 		},
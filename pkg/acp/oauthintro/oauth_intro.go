@@ -28,6 +28,7 @@ import (
 	"text/template"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/token"
 	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
@@ -39,6 +40,7 @@ type Config struct {
 	TokenSource    token.Source      `json:"tokenSource,omitempty"`
 	Claims         string            `json:"claims,omitempty"`
 	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
+	Authorization  *authz.Config     `json:"authorization,omitempty"`
 }
 
 // ClientConfig configures the HTTP client of the OAuth 2.0 Token Introspection ACP handler.
@@ -81,6 +83,7 @@ type Handler struct {
 	tokenSrc             token.Source
 	fwdHeaders           map[string]string
 	validateCustomClaims expr.Predicate
+	authorizer           *authz.Authorizer
 }
 
 // NewHandler creates a new OAuth 2.0 Token Introspection ACP Handler.
@@ -118,6 +121,14 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		}
 	}
 
+	var authorizer *authz.Authorizer
+	if cfg.Authorization != nil {
+		authorizer, err = authz.NewAuthorizer(cfg.Authorization)
+		if err != nil {
+			return nil, fmt.Errorf("create authorizer: %w", err)
+		}
+	}
+
 	var tmpls template.Template
 	for key, val := range cfg.ClientConfig.Headers {
 		if _, err = tmpls.New(key).Parse(val); err != nil {
@@ -135,6 +146,7 @@ func NewHandler(cfg *Config, polName string) (*Handler, error) {
 		auth:                 cfg.ClientConfig.Auth,
 		fwdHeaders:           cfg.ForwardHeaders,
 		validateCustomClaims: pred,
+		authorizer:           authorizer,
 	}, nil
 }
 
@@ -169,6 +181,11 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	if h.authorizer != nil && !h.authorizer.Authorize(req, claims) {
+		rw.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	hdrs, err := expr.PluckClaims(h.fwdHeaders, claims)
 	if err != nil {
 		l.Error().Err(err).Msg("Unable to set forwarded header")
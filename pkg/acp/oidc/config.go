@@ -19,6 +19,8 @@ package oidc
 
 import (
 	"errors"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
 )
 
 // Config holds the configuration for the OIDC middleware.
@@ -41,6 +43,8 @@ type Config struct {
 	// Claims defines an expression to perform validation on the ID token. For example:
 	//     Equals(`grp`, `admin`) && Equals(`scope`, `deploy`)
 	Claims string `json:"claims,omitempty"`
+	// Authorization defines CEL rules, evaluated over the ID token claims and the request, to authorize the request.
+	Authorization *authz.Config `json:"authorization,omitempty"`
 }
 
 // ApplyDefaultValues applies default values on the given dynamic configuration.
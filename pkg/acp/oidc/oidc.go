@@ -34,6 +34,7 @@ import (
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/expr"
 	"golang.org/x/oauth2"
 )
@@ -112,6 +113,7 @@ type Handler struct {
 	block    cipher.Block
 
 	validateClaims expr.Predicate
+	authorizer     *authz.Authorizer
 
 	client *http.Client
 
@@ -139,6 +141,14 @@ func NewHandler(ctx context.Context, cfg *Config, name string) (*Handler, error)
 		}
 	}
 
+	var authorizer *authz.Authorizer
+	if cfg.Authorization != nil {
+		authorizer, err = authz.NewAuthorizer(cfg.Authorization)
+		if err != nil {
+			return nil, fmt.Errorf("create authorizer: %w", err)
+		}
+	}
+
 	block, err := aes.NewCipher([]byte(cfg.SessionKey))
 	if err != nil {
 		return nil, fmt.Errorf("new cipher: %w", err)
@@ -158,6 +168,7 @@ func NewHandler(ctx context.Context, cfg *Config, name string) (*Handler, error)
 		session:        NewCookieSessionStore(name+"-session", block, cfg.Session, newRandom(), maxCookieSize),
 		block:          block,
 		validateClaims: pred,
+		authorizer:     authorizer,
 		client:         client,
 	}, nil
 }
@@ -301,6 +312,13 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if h.authorizer != nil && !h.authorizer.Authorize(req, claims) {
+		logger.Debug().Msg("Unauthorized request")
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+		return
+	}
+
 	if err = h.forwardHeader(rw, claims); err != nil {
 		logger.Error().Err(err).Msg("Unable to set forwarded header")
 		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
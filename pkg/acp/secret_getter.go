@@ -18,9 +18,13 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package acp
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	corev1lister "k8s.io/client-go/listers/core/v1"
 )
 
@@ -49,6 +53,35 @@ func (g KubeSecretGetter) GetValue(secret *corev1.SecretReference, key string) (
 	return value, nil
 }
 
+// KubeSecretClientGetter allows getting Kubernetes secrets directly from the API server, for occasional reads not
+// worth watching every secret.
+type KubeSecretClientGetter struct {
+	secrets corev1client.SecretsGetter
+}
+
+// NewKubeSecretClientGetter creates a KubeSecretClientGetter instance.
+func NewKubeSecretClientGetter(secrets corev1client.SecretsGetter) *KubeSecretClientGetter {
+	return &KubeSecretClientGetter{secrets: secrets}
+}
+
+// GetValue returns the value of the given key in the given Kubernetes secret.
+func (g KubeSecretClientGetter) GetValue(secret *corev1.SecretReference, key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s, err := g.secrets.Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %q in namespace %q: %w", secret.Name, secret.Namespace, err)
+	}
+
+	value, ok := s.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("no key %q in secret %q in namespace %q", key, secret.Name, secret.Namespace)
+	}
+
+	return value, nil
+}
+
 type emptySecretGetter struct{}
 
 func (g emptySecretGetter) GetValue(*corev1.SecretReference, string) ([]byte, error) {
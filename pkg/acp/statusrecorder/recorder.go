@@ -0,0 +1,41 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package statusrecorder records the status codes written by the ACP handlers, for the middlewares wrapping them to
+// act on their decision.
+package statusrecorder
+
+import "net/http"
+
+// Recorder records the status code written by a handler.
+type Recorder struct {
+	http.ResponseWriter
+
+	// Status is the status code written by the handler, http.StatusOK if none was written.
+	Status int
+}
+
+// New returns a Recorder recording the status code written to the given response writer.
+func New(rw http.ResponseWriter) *Recorder {
+	return &Recorder{ResponseWriter: rw, Status: http.StatusOK}
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *Recorder) WriteHeader(status int) {
+	r.Status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -22,6 +22,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/forwarded"
 )
 
 // Source describes where to find a token in an HTTP request.
@@ -78,7 +80,7 @@ func getTokenFromHeader(header http.Header, headerName, headerAuthScheme string)
 }
 
 func getTokenFromQuery(header http.Header, key string) (string, error) {
-	if uri := originalURI(header); uri != "" {
+	if uri := forwarded.URI(header); uri != "" {
 		parsedURI, err := url.Parse(uri)
 		if err != nil {
 			return "", err
@@ -91,13 +93,3 @@ func getTokenFromQuery(header http.Header, key string) (string, error) {
 
 	return "", nil
 }
-
-// originalURI gets the original URI that was sent to the ingress controller, regardless of its type.
-// It currently supports Traefik (X-Forwarded-Uri) and Nginx Community (X-Original-Url).
-func originalURI(hdr http.Header) string {
-	if xfu := hdr.Get("X-Forwarded-Uri"); xfu != "" {
-		return xfu
-	}
-
-	return hdr.Get("X-Original-Url")
-}
@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package traefikfile exports the ForwardAuth middlewares protecting routes with ACPs as a Traefik dynamic
+// configuration, for Traefik instances reading their configuration with the file provider rather than from Kubernetes.
+package traefikfile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// Formats of the exported configuration.
+const (
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// Configuration is a Traefik dynamic configuration holding ForwardAuth middlewares.
+type Configuration struct {
+	HTTP HTTPConfiguration `json:"http" toml:"http"`
+}
+
+// HTTPConfiguration is the HTTP section of a Traefik dynamic configuration.
+type HTTPConfiguration struct {
+	Middlewares map[string]Middleware `json:"middlewares" toml:"middlewares"`
+}
+
+// Middleware is a Traefik middleware.
+type Middleware struct {
+	ForwardAuth *ForwardAuth `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty"`
+}
+
+// ForwardAuth is the configuration of a Traefik ForwardAuth middleware.
+type ForwardAuth struct {
+	Address             string   `json:"address" toml:"address"`
+	TrustForwardHeader  bool     `json:"trustForwardHeader,omitempty" toml:"trustForwardHeader,omitempty"`
+	AuthResponseHeaders []string `json:"authResponseHeaders,omitempty" toml:"authResponseHeaders,omitempty"`
+	AuthRequestHeaders  []string `json:"authRequestHeaders,omitempty" toml:"authRequestHeaders,omitempty"`
+}
+
+// Export returns the Traefik dynamic configuration holding the ForwardAuth middlewares calling the auth server,
+// reachable at the given address, for the given ACPs. Middlewares are named as the ones the agent creates in
+// Kubernetes, so routers reference them as "<name>@file". It also returns warnings about the parts of the ACPs which
+// can't be exported. ACPs which can't be exported at all are skipped. When longLived is true, the middlewares forward
+// the ID the auth server gives to authorized long-lived connections.
+func Export(authServerAddr string, longLived bool, policies []*hubv1alpha1.AccessControlPolicy) (*Configuration, []string) {
+	sorted := make([]*hubv1alpha1.AccessControlPolicy, len(policies))
+	copy(sorted, policies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	cfg := &Configuration{HTTP: HTTPConfiguration{Middlewares: make(map[string]Middleware)}}
+
+	var warnings []string
+	for _, policy := range sorted {
+		acpCfg := acp.ConfigFromPolicy(policy)
+		if acpCfg == nil {
+			warnings = append(warnings, fmt.Sprintf("ACP %q skipped: invalid configuration", policy.Name))
+			continue
+		}
+
+		forwardAuth, err := reviewer.NewForwardAuth(authServerAddr, policy.Name, acpCfg, longLived)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("ACP %q skipped: %v", policy.Name, err))
+			continue
+		}
+
+		if forwardAuth.TLS != nil {
+			warnings = append(warnings, fmt.Sprintf("ACP %q: the TLS configuration of its middleware references Kubernetes Secrets and must be set with the tls options of the file provider", policy.Name))
+		}
+
+		authResponseHeaders := append([]string(nil), forwardAuth.AuthResponseHeaders...)
+		sort.Strings(authResponseHeaders)
+
+		cfg.HTTP.Middlewares[reviewer.MiddlewareName(policy.Name)] = Middleware{
+			ForwardAuth: &ForwardAuth{
+				Address:             forwardAuth.Address,
+				TrustForwardHeader:  forwardAuth.TrustForwardHeader,
+				AuthResponseHeaders: authResponseHeaders,
+				AuthRequestHeaders:  forwardAuth.AuthRequestHeaders,
+			},
+		}
+	}
+
+	return cfg, warnings
+}
+
+// Marshal encodes the configuration in the given format.
+func (c *Configuration) Marshal(format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		b, err := yaml.Marshal(c)
+		if err != nil {
+			return nil, fmt.Errorf("marshal YAML: %w", err)
+		}
+		return b, nil
+
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+			return nil, fmt.Errorf("marshal TOML: %w", err)
+		}
+		return buf.Bytes(), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported format %q: must be %q or %q", format, FormatYAML, FormatTOML)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package traefikfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExport(t *testing.T) {
+	policies := []*hubv1alpha1.AccessControlPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "jwt"},
+			Spec: hubv1alpha1.AccessControlPolicySpec{
+				JWT: &hubv1alpha1.AccessControlPolicyJWT{
+					SigningSecret:            "secret",
+					StripAuthorizationHeader: true,
+					ForwardHeaders:           map[string]string{"X-User": "sub", "X-Group": "grp"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic-auth"},
+			Spec: hubv1alpha1.AccessControlPolicySpec{
+				BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+					Users: []string{"user:$apr1$9Cv/OMGj$ZomWQzuQbL.3TRCS81A1g/"},
+				},
+				ForwardAuth: &hubv1alpha1.AccessControlPolicyForwardAuth{
+					TrustForwardHeader: true,
+					AuthRequestHeaders: []string{"Authorization"},
+					TLS:                &hubv1alpha1.AccessControlPolicyForwardAuthTLS{CASecret: "auth-server-ca"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "empty"},
+		},
+	}
+
+	cfg, warnings := Export("http://127.0.0.1:8080", false, policies)
+
+	assert.Equal(t, []string{
+		`ACP "basic-auth": the TLS configuration of its middleware references Kubernetes Secrets and must be set with the tls options of the file provider`,
+		`ACP "empty" skipped: invalid configuration`,
+	}, warnings)
+
+	gotYAML, err := cfg.Marshal(FormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, `http:
+  middlewares:
+    zz-basic-auth:
+      forwardAuth:
+        address: http://127.0.0.1:8080/basic-auth
+        authRequestHeaders:
+        - Authorization
+        trustForwardHeader: true
+    zz-jwt:
+      forwardAuth:
+        address: http://127.0.0.1:8080/jwt
+        authResponseHeaders:
+        - Authorization
+        - X-Group
+        - X-User
+`, string(gotYAML))
+
+	gotTOML, err := cfg.Marshal(FormatTOML)
+	require.NoError(t, err)
+	assert.Equal(t, `[http]
+  [http.middlewares]
+    [http.middlewares.zz-basic-auth]
+      [http.middlewares.zz-basic-auth.forwardAuth]
+        address = "http://127.0.0.1:8080/basic-auth"
+        trustForwardHeader = true
+        authRequestHeaders = ["Authorization"]
+    [http.middlewares.zz-jwt]
+      [http.middlewares.zz-jwt.forwardAuth]
+        address = "http://127.0.0.1:8080/jwt"
+        authResponseHeaders = ["Authorization", "X-Group", "X-User"]
+`, string(gotTOML))
+
+	_, err = cfg.Marshal("json")
+	assert.Error(t, err)
+}
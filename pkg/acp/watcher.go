@@ -24,6 +24,10 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/authz"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/clientip"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/denyall"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
@@ -197,10 +201,15 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 			PublicKey:                  a.JWT.PublicKey,
 			JWKsFile:                   a.JWT.JWKsFile.String(),
 			JWKsURL:                    a.JWT.JWKsURL,
+			Issuers:                    buildJWTIssuers(a.JWT.Issuers),
+			Leeway:                     buildDuration(a.JWT.Leeway),
+			MaxTokenAge:                buildDuration(a.JWT.MaxTokenAge),
+			InternalToken:              buildJWTInternalToken(a.JWT.InternalToken),
 			StripAuthorizationHeader:   a.JWT.StripAuthorizationHeader,
 			ForwardHeaders:             a.JWT.ForwardHeaders,
 			TokenQueryKey:              a.JWT.TokenQueryKey,
 			Claims:                     a.JWT.Claims,
+			Authorization:              buildAuthorization(a.JWT.Authorization),
 		}
 
 	case a.BasicAuth != nil:
@@ -211,6 +220,21 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 			ForwardUsernameHeader:    a.BasicAuth.ForwardUsernameHeader,
 		}
 
+		if a.BasicAuth.UsersSecret != nil {
+			spec.BasicAuth.UsersSecret = &corev1.SecretReference{
+				Name:      a.BasicAuth.UsersSecret.Name,
+				Namespace: a.BasicAuth.UsersSecret.Namespace,
+			}
+		}
+
+		if a.BasicAuth.UserLookup != nil {
+			spec.BasicAuth.UserLookup = &hubv1alpha1.AccessControlPolicyBasicAuthUserLookup{
+				URL:      a.BasicAuth.UserLookup.URL,
+				CacheTTL: buildDuration(a.BasicAuth.UserLookup.CacheTTL),
+				Timeout:  buildDuration(a.BasicAuth.UserLookup.Timeout),
+			}
+		}
+
 	case a.APIKey != nil:
 		keys := make([]hubv1alpha1.AccessControlPolicyAPIKeyKey, 0, len(a.APIKey.Keys))
 		for _, k := range a.APIKey.Keys {
@@ -242,6 +266,7 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 			Scopes:         a.OIDC.Scopes,
 			ForwardHeaders: a.OIDC.ForwardHeaders,
 			Claims:         a.OIDC.Claims,
+			Authorization:  buildAuthorization(a.OIDC.Authorization),
 		}
 
 		if a.OIDC.Secret != nil {
@@ -278,6 +303,7 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 			AuthParams:     a.OIDCGoogle.AuthParams,
 			ForwardHeaders: a.OIDCGoogle.ForwardHeaders,
 			Emails:         a.OIDCGoogle.Emails,
+			Authorization:  buildAuthorization(a.OIDCGoogle.Authorization),
 		}
 
 		if a.OIDCGoogle.Secret != nil {
@@ -310,6 +336,7 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 		spec.OAuthIntro = &hubv1alpha1.AccessControlOAuthIntro{
 			Claims:         a.OAuthIntro.Claims,
 			ForwardHeaders: a.OAuthIntro.ForwardHeaders,
+			Authorization:  buildAuthorization(a.OAuthIntro.Authorization),
 		}
 
 		spec.OAuthIntro.TokenSource = hubv1alpha1.TokenSource{
@@ -344,7 +371,131 @@ func buildAccessControlPolicySpec(a ACP) hubv1alpha1.AccessControlPolicySpec {
 			Name:      a.OAuthIntro.ClientConfig.Auth.Secret.Name,
 			Namespace: a.OAuthIntro.ClientConfig.Auth.Secret.Namespace,
 		}
+
+	case a.GeoIP != nil:
+		spec.GeoIP = &hubv1alpha1.AccessControlPolicyGeoIP{
+			AllowedCountries: a.GeoIP.AllowedCountries,
+			DeniedCountries:  a.GeoIP.DeniedCountries,
+			AllowedASNs:      a.GeoIP.AllowedASNs,
+			DeniedASNs:       a.GeoIP.DeniedASNs,
+			ForwardHeaders:   a.GeoIP.ForwardHeaders,
+		}
+
+		for _, db := range a.GeoIP.Databases {
+			database := hubv1alpha1.AccessControlPolicyGeoIPDatabase{Path: db.Path}
+			if db.Secret != nil {
+				database.Secret = &corev1.SecretReference{
+					Name:      db.Secret.Name,
+					Namespace: db.Secret.Namespace,
+				}
+			}
+
+			spec.GeoIP.Databases = append(spec.GeoIP.Databases, database)
+		}
 	}
 
+	spec.ForwardAuth = buildForwardAuth(a.ForwardAuth)
+	spec.DenyAll = buildDenyAll(a.DenyAll)
+	spec.ClientIP = buildClientIP(a.ClientIP)
+
 	return spec
 }
+
+func buildForwardAuth(cfg *ForwardAuthConfig) *hubv1alpha1.AccessControlPolicyForwardAuth {
+	if cfg == nil {
+		return nil
+	}
+
+	forwardAuth := &hubv1alpha1.AccessControlPolicyForwardAuth{
+		TrustForwardHeader: cfg.TrustForwardHeader,
+		AuthRequestHeaders: cfg.AuthRequestHeaders,
+	}
+
+	if cfg.TLS != nil {
+		forwardAuth.TLS = &hubv1alpha1.AccessControlPolicyForwardAuthTLS{
+			CASecret:   cfg.TLS.CASecret,
+			CertSecret: cfg.TLS.CertSecret,
+		}
+	}
+
+	return forwardAuth
+}
+
+func buildDenyAll(cfg *denyall.Config) *hubv1alpha1.AccessControlPolicyDenyAll {
+	if cfg == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.AccessControlPolicyDenyAll{
+		StatusCode: cfg.StatusCode,
+		Message:    cfg.Message,
+	}
+}
+
+func buildClientIP(cfg *clientip.Config) *hubv1alpha1.AccessControlPolicyClientIP {
+	if cfg == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.AccessControlPolicyClientIP{
+		TrustedIPs:  cfg.TrustedIPs,
+		Depth:       cfg.Depth,
+		UseLeftmost: cfg.UseLeftmost,
+	}
+}
+
+func buildAuthorization(cfg *authz.Config) *hubv1alpha1.AccessControlPolicyAuthorization {
+	if cfg == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.AccessControlPolicyAuthorization{Rules: cfg.Rules}
+}
+
+func buildJWTIssuers(cfgs []jwt.IssuerConfig) []hubv1alpha1.AccessControlPolicyJWTIssuer {
+	if len(cfgs) == 0 {
+		return nil
+	}
+
+	issuers := make([]hubv1alpha1.AccessControlPolicyJWTIssuer, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		issuers = append(issuers, hubv1alpha1.AccessControlPolicyJWTIssuer{
+			Issuer:                     cfg.Issuer,
+			SigningSecret:              cfg.SigningSecret,
+			SigningSecretBase64Encoded: cfg.SigningSecretBase64Encoded,
+			PublicKey:                  cfg.PublicKey,
+			JWKsFile:                   cfg.JWKsFile.String(),
+			JWKsURL:                    cfg.JWKsURL,
+			Audiences:                  cfg.Audiences,
+			ClaimMappings:              cfg.ClaimMappings,
+		})
+	}
+
+	return issuers
+}
+
+func buildJWTInternalToken(cfg *jwt.InternalTokenConfig) *hubv1alpha1.AccessControlPolicyJWTInternalToken {
+	if cfg == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.AccessControlPolicyJWTInternalToken{
+		SigningSecret:              cfg.SigningSecret,
+		SigningSecretBase64Encoded: cfg.SigningSecretBase64Encoded,
+		PrivateKey:                 cfg.PrivateKey,
+		KeyID:                      cfg.KeyID,
+		Issuer:                     cfg.Issuer,
+		Audience:                   cfg.Audience,
+		TTL:                        buildDuration(cfg.TTL),
+		Claims:                     cfg.Claims,
+		Header:                     cfg.Header,
+	}
+}
+
+func buildDuration(d time.Duration) *metav1.Duration {
+	if d == 0 {
+		return nil
+	}
+
+	return &metav1.Duration{Duration: d}
+}
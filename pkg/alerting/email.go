@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// EmailConfig configures the SMTP server through which alerts are emailed.
+type EmailConfig struct {
+	Host string `json:"host"`
+	// Port is the port of the SMTP server, 587 by default.
+	Port     int      `json:"port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// EmailNotifier notifies alerts by email.
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier returns a notifier emailing alerts through the given SMTP server. The connection is upgraded
+// with STARTTLS when the server supports it.
+func NewEmailNotifier(cfg EmailConfig) (*EmailNotifier, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("email host is required")
+	}
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("email from and to addresses are required")
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &EmailNotifier{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		auth:     auth,
+		from:     cfg.From,
+		to:       cfg.To,
+		sendMail: smtp.SendMail,
+	}, nil
+}
+
+// Notify emails the given alerts.
+func (n *EmailNotifier) Notify(_ context.Context, alerts []Alert) error {
+	var msg bytes.Buffer
+	msg.WriteString("From: " + n.from + "\r\n")
+	msg.WriteString("To: " + strings.Join(n.to, ", ") + "\r\n")
+	msg.WriteString(fmt.Sprintf("Subject: Traefik Hub agent raised %d alert(s)\r\n", len(alerts)))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	for _, alert := range alerts {
+		msg.WriteString("- " + summary(alert) + "\r\n")
+	}
+
+	if err := n.sendMail(n.addr, n.auth, n.from, n.to, msg.Bytes()); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	return nil
+}
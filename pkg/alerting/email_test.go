@@ -0,0 +1,67 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	notifier, err := NewEmailNotifier(EmailConfig{
+		Host:     "smtp.example.com",
+		Port:     2525,
+		Username: "hub-agent",
+		Password: "s3cr3t",
+		From:     "hub-agent@example.com",
+		To:       []string{"ops@example.com", "dev@example.com"},
+	})
+	require.NoError(t, err)
+
+	var callCount int
+	notifier.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		callCount++
+
+		assert.Equal(t, "smtp.example.com:2525", addr)
+		assert.NotNil(t, a)
+		assert.Equal(t, "hub-agent@example.com", from)
+		assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, to)
+
+		want := "From: hub-agent@example.com\r\n" +
+			"To: ops@example.com, dev@example.com\r\n" +
+			"Subject: Traefik Hub agent raised 1 alert(s)\r\n" +
+			"MIME-Version: 1.0\r\n" +
+			"Content-Type: text/plain; charset=UTF-8\r\n" +
+			"\r\n" +
+			"- Certificate of ingress web@myns expiring within 24h0m0s (rule 456)\r\n"
+		assert.Equal(t, want, string(msg))
+
+		return nil
+	}
+
+	err = notifier.Notify(context.Background(), []Alert{
+		{RuleID: "456", Ingress: "web@myns", CertificateExpiry: &CertificateExpiry{Within: 24 * time.Hour}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, callCount)
+}
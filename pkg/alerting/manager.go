@@ -38,15 +38,24 @@ type Backend interface {
 	SendAlerts(ctx context.Context, alerts []Alert) error
 }
 
+// Notifier is capable of notifying alerts, independently of the platform.
+type Notifier interface {
+	Notify(ctx context.Context, alerts []Alert) error
+}
+
 // Manager manages rule synchronization and scheduling.
 type Manager struct {
-	backend Backend
+	backend   Backend
+	notifiers []Notifier
 
 	rulesMu sync.Mutex
 	rules   []Rule
 
 	procs map[string]Processor
 
+	// firing holds the keys of the alerts raised on the last check, only accessed by the scheduler.
+	firing map[string]struct{}
+
 	refreshInterval   time.Duration
 	schedulerInterval time.Duration
 
@@ -66,6 +75,13 @@ func NewManager(backend Backend, procs map[string]Processor, refreshInterval, sc
 	}
 }
 
+// AddNotifier adds a notifier, notified of the alerts as soon as they are raised by the agent. Unlike the platform
+// notifications, they don't depend on the ingestion of the metrics by the platform. It must be called before running
+// the manager.
+func (m *Manager) AddNotifier(notifier Notifier) {
+	m.notifiers = append(m.notifiers, notifier)
+}
+
 // Run runs the alert manager.
 func (m *Manager) Run(ctx context.Context) error {
 	rules, err := m.backend.GetRules(ctx)
@@ -150,6 +166,8 @@ func (m *Manager) checkAlerts(ctx context.Context) error {
 
 	m.rulesMu.Unlock()
 
+	m.notify(ctx, alerts)
+
 	log.Debug().Int("count", len(alerts)).Msg("Checking alerts to send")
 
 	// Make a preflight request even if there is no alerts as it's also used for resolving existing alerts.
@@ -170,3 +188,45 @@ func (m *Manager) checkAlerts(ctx context.Context) error {
 
 	return nil
 }
+
+// notify notifies the alerts which weren't raised on the previous check. Alerts failing to be notified are notified
+// again on the next check.
+func (m *Manager) notify(ctx context.Context, alerts []Alert) {
+	firing := make(map[string]struct{}, len(alerts))
+
+	var raised []Alert
+	for _, alert := range alerts {
+		key := alertKey(alert)
+		firing[key] = struct{}{}
+
+		if _, ok := m.firing[key]; !ok {
+			raised = append(raised, alert)
+		}
+	}
+
+	m.firing = firing
+
+	if len(raised) == 0 || len(m.notifiers) == 0 {
+		return
+	}
+
+	log.Debug().Int("count", len(raised)).Msg("Notifying alerts")
+
+	var failed bool
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, raised); err != nil {
+			log.Error().Err(err).Msg("Unable to notify alerts")
+			failed = true
+		}
+	}
+
+	if failed {
+		for _, alert := range raised {
+			delete(m.firing, alertKey(alert))
+		}
+	}
+}
+
+func alertKey(alert Alert) string {
+	return alert.RuleID + "|" + alert.Ingress + "|" + alert.Service
+}
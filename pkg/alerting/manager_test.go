@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -679,3 +680,61 @@ func TestManager_checkAlerts(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_checkAlerts_notifiesRaisedAlerts(t *testing.T) {
+	rules := []Rule{
+		{
+			ID:      "123",
+			Ingress: "web@myns",
+			Threshold: &Threshold{
+				Metric:     "requestErrorsPercent",
+				Condition:  ThresholdCondition{Above: true, Value: 0.1},
+				Occurrence: 1,
+				TimeRange:  time.Hour,
+			},
+		},
+		{
+			ID:                "456",
+			Ingress:           "web@myns",
+			CertificateExpiry: &CertificateExpiry{Within: 7 * 24 * time.Hour},
+		},
+	}
+
+	errorAlert := Alert{RuleID: "123", Ingress: "web@myns", Threshold: rules[0].Threshold}
+	certAlert := Alert{RuleID: "456", Ingress: "web@myns", CertificateExpiry: rules[1].CertificateExpiry}
+
+	thresholdProc := newProcessorMock(t).
+		OnProcess(&rules[0]).TypedReturns(&errorAlert, nil).Times(3)
+	certProc := newProcessorMock(t).
+		OnProcess(&rules[1]).TypedReturns(nil, nil).Once().
+		OnProcess(&rules[1]).TypedReturns(&certAlert, nil).Twice()
+
+	// The platform is unavailable, which must not prevent alerts from being notified.
+	backend := newBackendMock(t).
+		OnPreflightAlertsRaw(mock.Anything).TypedReturns(nil, errors.New("unavailable")).Times(3)
+
+	notifier := newNotifierMock(t).
+		OnNotify([]Alert{errorAlert}).TypedReturns(nil).Once().
+		OnNotify([]Alert{certAlert}).TypedReturns(errors.New("boom")).Once().
+		OnNotify([]Alert{certAlert}).TypedReturns(nil).Once()
+
+	mgr := NewManager(backend.Parent, map[string]Processor{
+		ThresholdType:         thresholdProc.Parent,
+		CertificateExpiryType: certProc.Parent,
+	}, time.Second, time.Second)
+	mgr.AddNotifier(notifier.Parent)
+	mgr.rules = rules
+
+	// The error rate alert is raised and notified.
+	err := mgr.checkAlerts(context.Background())
+	require.Error(t, err)
+
+	// The certificate expiry alert is raised but fails to be notified, the error rate one is still raised and isn't
+	// notified again.
+	err = mgr.checkAlerts(context.Background())
+	require.Error(t, err)
+
+	// The certificate expiry alert is notified again.
+	err = mgr.checkAlerts(context.Background())
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ package alerting
 
 import (
 	"context"
+	"crypto/x509"
 	"testing"
 	"time"
 
@@ -911,3 +912,216 @@ func (_c *logProviderGetServiceLogsCall) OnGetServiceLogs(namespace string, name
 func (_c *logProviderGetServiceLogsCall) OnGetServiceLogsRaw(namespace interface{}, name interface{}, lines interface{}, maxLen interface{}) *logProviderGetServiceLogsCall {
 	return _c.Parent.OnGetServiceLogsRaw(namespace, name, lines, maxLen)
 }
+
+// certificateFinderMock mock of CertificateFinder.
+type certificateFinderMock struct{ mock.Mock }
+
+// newCertificateFinderMock creates a new certificateFinderMock.
+func newCertificateFinderMock(tb testing.TB) *certificateFinderMock {
+	tb.Helper()
+
+	m := &certificateFinderMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *certificateFinderMock) GetIngressCertificates(_ context.Context, ingress string) ([]*x509.Certificate, error) {
+	_ret := _m.Called(ingress)
+
+	if _rf, ok := _ret.Get(0).(func(string) ([]*x509.Certificate, error)); ok {
+		return _rf(ingress)
+	}
+
+	_ra0, _ := _ret.Get(0).([]*x509.Certificate)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *certificateFinderMock) OnGetIngressCertificates(ingress string) *certificateFinderGetIngressCertificatesCall {
+	return &certificateFinderGetIngressCertificatesCall{Call: _m.Mock.On("GetIngressCertificates", ingress), Parent: _m}
+}
+
+func (_m *certificateFinderMock) OnGetIngressCertificatesRaw(ingress interface{}) *certificateFinderGetIngressCertificatesCall {
+	return &certificateFinderGetIngressCertificatesCall{Call: _m.Mock.On("GetIngressCertificates", ingress), Parent: _m}
+}
+
+type certificateFinderGetIngressCertificatesCall struct {
+	*mock.Call
+	Parent *certificateFinderMock
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) Panic(msg string) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) Once() *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) Twice() *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) Times(i int) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) WaitUntil(w <-chan time.Time) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) After(d time.Duration) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) Run(fn func(args mock.Arguments)) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) Maybe() *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) TypedReturns(a []*x509.Certificate, b error) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) ReturnsFn(fn func(string) ([]*x509.Certificate, error)) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) TypedRun(fn func(string)) *certificateFinderGetIngressCertificatesCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_ingress := args.String(0)
+		fn(_ingress)
+	})
+	return _c
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) OnGetIngressCertificates(ingress string) *certificateFinderGetIngressCertificatesCall {
+	return _c.Parent.OnGetIngressCertificates(ingress)
+}
+
+func (_c *certificateFinderGetIngressCertificatesCall) OnGetIngressCertificatesRaw(ingress interface{}) *certificateFinderGetIngressCertificatesCall {
+	return _c.Parent.OnGetIngressCertificatesRaw(ingress)
+}
+
+// notifierMock mock of Notifier.
+type notifierMock struct{ mock.Mock }
+
+// newNotifierMock creates a new notifierMock.
+func newNotifierMock(tb testing.TB) *notifierMock {
+	tb.Helper()
+
+	m := &notifierMock{}
+	m.Mock.Test(tb)
+
+	tb.Cleanup(func() { m.AssertExpectations(tb) })
+
+	return m
+}
+
+func (_m *notifierMock) Notify(_ context.Context, alerts []Alert) error {
+	_ret := _m.Called(alerts)
+
+	if _rf, ok := _ret.Get(0).(func([]Alert) error); ok {
+		return _rf(alerts)
+	}
+
+	_ra0 := _ret.Error(0)
+
+	return _ra0
+}
+
+func (_m *notifierMock) OnNotify(alerts []Alert) *notifierNotifyCall {
+	return &notifierNotifyCall{Call: _m.Mock.On("Notify", alerts), Parent: _m}
+}
+
+func (_m *notifierMock) OnNotifyRaw(alerts interface{}) *notifierNotifyCall {
+	return &notifierNotifyCall{Call: _m.Mock.On("Notify", alerts), Parent: _m}
+}
+
+type notifierNotifyCall struct {
+	*mock.Call
+	Parent *notifierMock
+}
+
+func (_c *notifierNotifyCall) Panic(msg string) *notifierNotifyCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *notifierNotifyCall) Once() *notifierNotifyCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *notifierNotifyCall) Twice() *notifierNotifyCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *notifierNotifyCall) Times(i int) *notifierNotifyCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *notifierNotifyCall) WaitUntil(w <-chan time.Time) *notifierNotifyCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *notifierNotifyCall) After(d time.Duration) *notifierNotifyCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *notifierNotifyCall) Run(fn func(args mock.Arguments)) *notifierNotifyCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *notifierNotifyCall) Maybe() *notifierNotifyCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *notifierNotifyCall) TypedReturns(a error) *notifierNotifyCall {
+	_c.Call = _c.Return(a)
+	return _c
+}
+
+func (_c *notifierNotifyCall) ReturnsFn(fn func([]Alert) error) *notifierNotifyCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *notifierNotifyCall) TypedRun(fn func([]Alert)) *notifierNotifyCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_alerts, _ := args.Get(0).([]Alert)
+		fn(_alerts)
+	})
+	return _c
+}
+
+func (_c *notifierNotifyCall) OnNotify(alerts []Alert) *notifierNotifyCall {
+	return _c.Parent.OnNotify(alerts)
+}
+
+func (_c *notifierNotifyCall) OnNotifyRaw(alerts interface{}) *notifierNotifyCall {
+	return _c.Parent.OnNotifyRaw(alerts)
+}
@@ -21,3 +21,5 @@ package alerting
 // mocktail:Processor
 // mocktail:DataPointsFinder
 // mocktail:LogProvider
+// mocktail:CertificateFinder
+// mocktail:Notifier
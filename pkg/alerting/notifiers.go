@@ -0,0 +1,138 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// NotifiersConfig configures the channels to which the alerts raised by the agent are notified:
+//
+//	notifiers:
+//	  - slack:
+//	      url: https://hooks.slack.com/services/T000/B000/XXXX
+//	  - webhook:
+//	      url: https://alerts.example.com/hub
+//	      hmacSecret: s3cr3t
+//	  - email:
+//	      host: smtp.example.com
+//	      port: 587
+//	      username: hub-agent
+//	      password: s3cr3t
+//	      from: hub-agent@example.com
+//	      to:
+//	        - ops@example.com
+//	  - pagerDuty:
+//	      routingKey: R0UT1NGK3Y
+type NotifiersConfig struct {
+	Notifiers []NotifierConfig `json:"notifiers"`
+}
+
+// NotifierConfig configures a notification channel. Exactly one channel must be set.
+type NotifierConfig struct {
+	Slack     *SlackConfig     `json:"slack,omitempty"`
+	Webhook   *WebhookConfig   `json:"webhook,omitempty"`
+	Email     *EmailConfig     `json:"email,omitempty"`
+	PagerDuty *PagerDutyConfig `json:"pagerDuty,omitempty"`
+}
+
+// WebhookConfig configures a generic webhook.
+type WebhookConfig struct {
+	URL        string `json:"url"`
+	HMACSecret string `json:"hmacSecret,omitempty"`
+}
+
+// ParseNotifiersConfig parses a YAML or JSON notifiers configuration.
+func ParseNotifiersConfig(b []byte) (NotifiersConfig, error) {
+	var cfg NotifiersConfig
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return NotifiersConfig{}, fmt.Errorf("unmarshal notifiers config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// NewNotifiers returns the notifiers of the given configuration.
+func NewNotifiers(client *http.Client, cfg NotifiersConfig) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0, len(cfg.Notifiers))
+	for i, c := range cfg.Notifiers {
+		notifier, err := newNotifier(client, c)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %d: %w", i, err)
+		}
+
+		notifiers = append(notifiers, notifier)
+	}
+
+	return notifiers, nil
+}
+
+func newNotifier(client *http.Client, cfg NotifierConfig) (Notifier, error) {
+	var set int
+	for _, ok := range []bool{cfg.Slack != nil, cfg.Webhook != nil, cfg.Email != nil, cfg.PagerDuty != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.New("exactly one of slack, webhook, email or pagerDuty must be set")
+	}
+
+	switch {
+	case cfg.Slack != nil:
+		return NewSlackNotifier(client, *cfg.Slack)
+	case cfg.Webhook != nil:
+		return NewWebhookNotifier(client, cfg.Webhook.URL, cfg.Webhook.HMACSecret)
+	case cfg.Email != nil:
+		return NewEmailNotifier(*cfg.Email)
+	default:
+		return NewPagerDutyNotifier(client, *cfg.PagerDuty)
+	}
+}
+
+// summary returns a human-readable summary of the given alert.
+func summary(alert Alert) string {
+	var targets []string
+	if alert.Ingress != "" {
+		targets = append(targets, "ingress "+alert.Ingress)
+	}
+	if alert.Service != "" {
+		targets = append(targets, "service "+alert.Service)
+	}
+	target := strings.Join(targets, " and ")
+
+	switch {
+	case alert.Threshold != nil:
+		direction := "below"
+		if alert.Threshold.Condition.Above {
+			direction = "above"
+		}
+
+		return fmt.Sprintf("%s %s %g on %s (rule %s)",
+			alert.Threshold.Metric, direction, alert.Threshold.Condition.Value, target, alert.RuleID)
+	case alert.CertificateExpiry != nil:
+		return fmt.Sprintf("Certificate of %s expiring within %s (rule %s)", target, alert.CertificateExpiry.Within, alert.RuleID)
+	default:
+		return fmt.Sprintf("Alert on %s (rule %s)", target, alert.RuleID)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNotifiers(t *testing.T) {
+	cfg, err := ParseNotifiersConfig([]byte(`
+notifiers:
+  - slack:
+      url: https://hooks.slack.com/services/T000/B000/XXXX
+  - webhook:
+      url: https://alerts.example.com/hub
+      hmacSecret: s3cr3t
+  - email:
+      host: smtp.example.com
+      from: hub-agent@example.com
+      to:
+        - ops@example.com
+  - pagerDuty:
+      routingKey: R0UT1NGK3Y
+      severity: critical
+`))
+	require.NoError(t, err)
+
+	notifiers, err := NewNotifiers(http.DefaultClient, cfg)
+	require.NoError(t, err)
+	require.Len(t, notifiers, 4)
+
+	assert.IsType(t, &SlackNotifier{}, notifiers[0])
+	assert.IsType(t, &WebhookNotifier{}, notifiers[1])
+	assert.IsType(t, &EmailNotifier{}, notifiers[2])
+	assert.Equal(t, "smtp.example.com:587", notifiers[2].(*EmailNotifier).addr)
+	assert.IsType(t, &PagerDutyNotifier{}, notifiers[3])
+	assert.Equal(t, pagerDutyEventsURL, notifiers[3].(*PagerDutyNotifier).url)
+}
+
+func TestNewNotifiers_invalid(t *testing.T) {
+	tests := []struct {
+		desc string
+		cfg  NotifierConfig
+	}{
+		{
+			desc: "no channel",
+		},
+		{
+			desc: "multiple channels",
+			cfg: NotifierConfig{
+				Slack:   &SlackConfig{URL: "https://hooks.slack.com/services/T000/B000/XXXX"},
+				Webhook: &WebhookConfig{URL: "https://alerts.example.com/hub"},
+			},
+		},
+		{
+			desc: "slack url not https",
+			cfg:  NotifierConfig{Slack: &SlackConfig{URL: "http://hooks.slack.com/services/T000/B000/XXXX"}},
+		},
+		{
+			desc: "email without recipients",
+			cfg:  NotifierConfig{Email: &EmailConfig{Host: "smtp.example.com", From: "hub-agent@example.com"}},
+		},
+		{
+			desc: "pagerDuty without routing key",
+			cfg:  NotifierConfig{PagerDuty: &PagerDutyConfig{}},
+		},
+		{
+			desc: "pagerDuty with invalid severity",
+			cfg:  NotifierConfig{PagerDuty: &PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", Severity: "bad"}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewNotifiers(http.DefaultClient, NotifiersConfig{Notifiers: []NotifierConfig{test.cfg}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseNotifiersConfig_unknownField(t *testing.T) {
+	_, err := ParseNotifiersConfig([]byte(`
+notifiers:
+  - slack:
+      webhook: https://hooks.slack.com/services/T000/B000/XXXX
+`))
+	assert.Error(t, err)
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		desc  string
+		alert Alert
+		want  string
+	}{
+		{
+			desc: "threshold",
+			alert: Alert{
+				RuleID:  "123",
+				Ingress: "web@myns",
+				Service: "whoami@myns",
+				Threshold: &Threshold{
+					Metric:    "p95ResponseTime",
+					Condition: ThresholdCondition{Above: true, Value: 0.5},
+				},
+			},
+			want: "p95ResponseTime above 0.5 on ingress web@myns and service whoami@myns (rule 123)",
+		},
+		{
+			desc: "certificate expiry",
+			alert: Alert{
+				RuleID:            "456",
+				Ingress:           "web@myns",
+				CertificateExpiry: &CertificateExpiry{Within: 72 * time.Hour},
+			},
+			want: "Certificate of ingress web@myns expiring within 72h0m0s (rule 456)",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, summary(test.alert))
+		})
+	}
+}
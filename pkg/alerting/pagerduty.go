@@ -0,0 +1,146 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyConfig configures a PagerDuty service integration using the Events API v2.
+type PagerDutyConfig struct {
+	RoutingKey string `json:"routingKey"`
+	// Severity is the severity of the triggered events: critical, error (default), warning or info.
+	Severity string `json:"severity,omitempty"`
+	// URL is the Events API URL, such as the one of the EU service region. Defaults to the US service region.
+	URL string `json:"url,omitempty"`
+}
+
+// PagerDutyNotifier notifies alerts by triggering PagerDuty events.
+type PagerDutyNotifier struct {
+	url        string
+	routingKey string
+	severity   string
+	httpClient *http.Client
+}
+
+// NewPagerDutyNotifier returns a notifier triggering a PagerDuty event for each alert.
+func NewPagerDutyNotifier(client *http.Client, cfg PagerDutyConfig) (*PagerDutyNotifier, error) {
+	if cfg.RoutingKey == "" {
+		return nil, errors.New("pagerDuty routing key is required")
+	}
+
+	severity := cfg.Severity
+	switch severity {
+	case "":
+		severity = "error"
+	case "critical", "error", "warning", "info":
+	default:
+		return nil, fmt.Errorf("invalid pagerDuty severity %q", severity)
+	}
+
+	url := cfg.URL
+	if url == "" {
+		url = pagerDutyEventsURL
+	}
+
+	return &PagerDutyNotifier{
+		url:        url,
+		routingKey: cfg.RoutingKey,
+		severity:   severity,
+		httpClient: client,
+	}, nil
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string `json:"summary"`
+	Source        string `json:"source"`
+	Severity      string `json:"severity"`
+	CustomDetails Alert  `json:"custom_details"`
+}
+
+// Notify triggers a PagerDuty event for each alert, deduplicated by rule, ingress and service.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	for _, alert := range alerts {
+		// Logs are compressed, they are of no use in the event details.
+		alert.Logs = nil
+
+		event := pagerDutyEvent{
+			RoutingKey:  n.routingKey,
+			EventAction: "trigger",
+			DedupKey:    alertKey(alert),
+			Payload: pagerDutyPayload{
+				Summary:       summary(alert),
+				Source:        "traefik-hub-agent",
+				Severity:      n.severity,
+				CustomDetails: alert,
+			},
+		}
+
+		if err := n.trigger(ctx, event); err != nil {
+			return fmt.Errorf("trigger event for rule %s: %w", alert.RuleID, err)
+		}
+	}
+
+	return nil
+}
+
+func (n *PagerDutyNotifier) trigger(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	version.SetUserAgent(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending event: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("sending event got %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagerDutyNotifier_Notify(t *testing.T) {
+	alerts := []Alert{
+		{
+			RuleID:            "456",
+			Ingress:           "web@myns",
+			Logs:              []byte("logs"),
+			CertificateExpiry: &CertificateExpiry{Within: 72 * time.Hour},
+		},
+		{
+			RuleID:    "123",
+			Service:   "whoami@myns",
+			Threshold: &Threshold{Metric: "requestErrorsPercent", Condition: ThresholdCondition{Above: true, Value: 0.1}},
+		},
+	}
+
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		err := json.NewDecoder(r.Body).Decode(&event)
+		require.NoError(t, err)
+
+		events = append(events, event)
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier, err := NewPagerDutyNotifier(http.DefaultClient, PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", URL: srv.URL})
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), alerts)
+	require.NoError(t, err)
+
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "R0UT1NGK3Y", events[0].RoutingKey)
+	assert.Equal(t, "trigger", events[0].EventAction)
+	assert.Equal(t, "456|web@myns|", events[0].DedupKey)
+	assert.Equal(t, "Certificate of ingress web@myns expiring within 72h0m0s (rule 456)", events[0].Payload.Summary)
+	assert.Equal(t, "error", events[0].Payload.Severity)
+	assert.Nil(t, events[0].Payload.CustomDetails.Logs)
+
+	assert.Equal(t, "123||whoami@myns", events[1].DedupKey)
+	assert.Equal(t, "requestErrorsPercent above 0.1 on service whoami@myns (rule 123)", events[1].Payload.Summary)
+}
+
+func TestPagerDutyNotifier_Notify_handlesErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier, err := NewPagerDutyNotifier(http.DefaultClient, PagerDutyConfig{RoutingKey: "R0UT1NGK3Y", URL: srv.URL})
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []Alert{{RuleID: "123", Ingress: "web@myns"}})
+	assert.Error(t, err)
+}
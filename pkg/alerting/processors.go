@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"strings"
@@ -47,6 +48,11 @@ type LogProvider interface {
 	GetServiceLogs(ctx context.Context, namespace, name string, lines, maxLen int) ([]byte, error)
 }
 
+// CertificateFinder is capable of finding the certificates of an ingress.
+type CertificateFinder interface {
+	GetIngressCertificates(ctx context.Context, ingress string) ([]*x509.Certificate, error)
+}
+
 // ThresholdProcessor processes threshold rules.
 type ThresholdProcessor struct {
 	dataPoints DataPointsFinder
@@ -163,16 +169,76 @@ func (p *ThresholdProcessor) getLogs(ctx context.Context, service string) ([]byt
 	return logs, nil
 }
 
+// CertificateExpiryProcessor processes certificate expiry rules.
+type CertificateExpiryProcessor struct {
+	certificates CertificateFinder
+
+	nowFunc func() time.Time
+}
+
+// NewCertificateExpiryProcessor returns a certificate expiry processor.
+func NewCertificateExpiryProcessor(certificates CertificateFinder) *CertificateExpiryProcessor {
+	return &CertificateExpiryProcessor{
+		certificates: certificates,
+		nowFunc:      time.Now,
+	}
+}
+
+// Process processes a certificate expiry rule returning an alert or nil. The alert holds a point per certificate
+// expiring within the delay of the rule, whose timestamp is the expiry date and whose value is the number of seconds
+// remaining before it, negative if the certificate already expired.
+func (p *CertificateExpiryProcessor) Process(ctx context.Context, rule *Rule) (*Alert, error) {
+	if rule.Ingress == "" {
+		return nil, errors.New("invalid rule")
+	}
+
+	certs, err := p.certificates.GetIngressCertificates(ctx, rule.Ingress)
+	if err != nil {
+		return nil, fmt.Errorf("get ingress certificates: %w", err)
+	}
+
+	now := p.nowFunc()
+
+	var points []Point
+	for _, cert := range certs {
+		remaining := cert.NotAfter.Sub(now)
+		if remaining > rule.CertificateExpiry.Within {
+			continue
+		}
+
+		points = append(points, Point{
+			Timestamp: cert.NotAfter.Unix(),
+			Value:     remaining.Seconds(),
+		})
+	}
+
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	return &Alert{
+		RuleID:            rule.ID,
+		Ingress:           rule.Ingress,
+		Service:           rule.Service,
+		Points:            points,
+		CertificateExpiry: rule.CertificateExpiry,
+	}, nil
+}
+
 func getValue(metric string, pnt metrics.DataPoint) (float64, error) {
 	switch metric {
 	case "requestsPerSecond":
 		return pnt.ReqPerS, nil
 	case "requestErrorsPerSecond":
 		return pnt.RequestErrPerS, nil
+	case "requestErrorsPercent":
+		return pnt.RequestErrPercent, nil
 	case "requestClientErrorsPerSecond":
 		return pnt.RequestClientErrPerS, nil
 	case "averageResponseTime":
 		return pnt.AvgResponseTime, nil
+	case "p95ResponseTime":
+		return pnt.P95ResponseTime, nil
 	default:
 		return 0, fmt.Errorf("invalid metric type: %s", metric)
 	}
@@ -19,6 +19,7 @@ package alerting
 
 import (
 	"context"
+	"crypto/x509"
 	"errors"
 	"testing"
 	"time"
@@ -820,6 +821,93 @@ func TestThresholdProcessor_Process(t *testing.T) {
 	}
 }
 
+func TestCertificateExpiryProcessor_Process(t *testing.T) {
+	now := time.Date(2021, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	rule := &Rule{
+		ID:                "rule-1",
+		Ingress:           "web@myns",
+		CertificateExpiry: &CertificateExpiry{Within: 7 * 24 * time.Hour},
+	}
+
+	tests := []struct {
+		desc       string
+		rule       *Rule
+		certs      []*x509.Certificate
+		certsErr   error
+		want       *Alert
+		requireErr require.ErrorAssertionFunc
+	}{
+		{
+			desc: "No alert: Rule with no ingress",
+			rule: &Rule{
+				ID:                "rule-1",
+				Service:           "whoami@myns",
+				CertificateExpiry: &CertificateExpiry{Within: 7 * 24 * time.Hour},
+			},
+			requireErr: require.Error,
+		},
+		{
+			desc:       "No alert: no certificates",
+			rule:       rule,
+			requireErr: require.NoError,
+		},
+		{
+			desc: "No alert: certificates not expiring soon",
+			rule: rule,
+			certs: []*x509.Certificate{
+				{NotAfter: now.Add(30 * 24 * time.Hour)},
+			},
+			requireErr: require.NoError,
+		},
+		{
+			desc:       "No alert: unable to get certificates",
+			rule:       rule,
+			certsErr:   errors.New("boom"),
+			requireErr: require.Error,
+		},
+		{
+			desc: "Alert: certificates expiring soon or expired",
+			rule: rule,
+			certs: []*x509.Certificate{
+				{NotAfter: now.Add(30 * 24 * time.Hour)},
+				{NotAfter: now.Add(24 * time.Hour)},
+				{NotAfter: now.Add(-time.Hour)},
+			},
+			want: &Alert{
+				RuleID:  "rule-1",
+				Ingress: "web@myns",
+				Points: []Point{
+					{Timestamp: now.Add(24 * time.Hour).Unix(), Value: 86400},
+					{Timestamp: now.Add(-time.Hour).Unix(), Value: -3600},
+				},
+				CertificateExpiry: rule.CertificateExpiry,
+			},
+			requireErr: require.NoError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certificates := newCertificateFinderMock(t)
+			if test.rule.Ingress != "" {
+				certificates.OnGetIngressCertificates(test.rule.Ingress).TypedReturns(test.certs, test.certsErr).Once()
+			}
+
+			p := NewCertificateExpiryProcessor(certificates)
+			p.nowFunc = func() time.Time { return now }
+
+			got, err := p.Process(context.Background(), test.rule)
+			test.requireErr(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
 func TestGetValue(t *testing.T) {
 	type expected struct {
 		value float64
@@ -850,6 +938,18 @@ func TestGetValue(t *testing.T) {
 			point:    metrics.DataPoint{RequestClientErrPerS: 100},
 			expected: expected{value: 100},
 		},
+		{
+			desc:     "with request errors percent metric",
+			metric:   "requestErrorsPercent",
+			point:    metrics.DataPoint{RequestErrPercent: 0.5},
+			expected: expected{value: 0.5},
+		},
+		{
+			desc:     "with p95 response time metric",
+			metric:   "p95ResponseTime",
+			point:    metrics.DataPoint{P95ResponseTime: 100},
+			expected: expected{value: 100},
+		},
 		{
 			desc:     "with average response time metric",
 			metric:   "averageResponseTime",
@@ -0,0 +1,92 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+)
+
+// SlackConfig configures a Slack incoming webhook.
+type SlackConfig struct {
+	URL string `json:"url"`
+}
+
+// SlackNotifier notifies alerts to a Slack channel through an incoming webhook.
+type SlackNotifier struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewSlackNotifier returns a notifier posting alerts to the given Slack incoming webhook.
+func NewSlackNotifier(client *http.Client, cfg SlackConfig) (*SlackNotifier, error) {
+	if !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, fmt.Errorf("invalid slack url %q: must be an https url", cfg.URL)
+	}
+
+	return &SlackNotifier{
+		url:        cfg.URL,
+		httpClient: client,
+	}, nil
+}
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify posts a message listing the given alerts to Slack.
+func (n *SlackNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	text := []string{fmt.Sprintf("*Traefik Hub agent raised %d alert(s)*", len(alerts))}
+	for _, alert := range alerts {
+		text = append(text, "• "+summary(alert))
+	}
+
+	body, err := json.Marshal(slackMessage{Text: strings.Join(text, "\n")})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	version.SetUserAgent(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting slack message: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("posting slack message got %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
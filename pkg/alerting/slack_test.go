@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var callCount int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		assert.Equal(t, http.MethodPost, r.Method)
+
+		var msg slackMessage
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.NoError(t, err)
+
+		want := "*Traefik Hub agent raised 1 alert(s)*\n" +
+			"• requestsPerSecond below 1 on ingress web@myns (rule 123)"
+		assert.Equal(t, want, msg.Text)
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier, err := NewSlackNotifier(srv.Client(), SlackConfig{URL: srv.URL})
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []Alert{
+		{
+			RuleID:    "123",
+			Ingress:   "web@myns",
+			Threshold: &Threshold{Metric: "requestsPerSecond", Condition: ThresholdCondition{Value: 1}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, callCount)
+}
@@ -21,8 +21,9 @@ import "time"
 
 // Rule types.
 const (
-	UnknownType   = "unknown"
-	ThresholdType = "threshold"
+	UnknownType           = "unknown"
+	ThresholdType         = "threshold"
+	CertificateExpiryType = "certificateExpiry"
 )
 
 // Rule defines evaluation configuration for alerting
@@ -32,15 +33,20 @@ type Rule struct {
 	Ingress string `json:"ingress"`
 	Service string `json:"service"`
 
-	Threshold *Threshold `json:"threshold"`
+	Threshold         *Threshold         `json:"threshold"`
+	CertificateExpiry *CertificateExpiry `json:"certificateExpiry,omitempty"`
 }
 
-// Type returns the rule type (for now, only threshold).
+// Type returns the rule type.
 func (r *Rule) Type() string {
-	if r.Threshold != nil {
+	switch {
+	case r.Threshold != nil:
 		return ThresholdType
+	case r.CertificateExpiry != nil:
+		return CertificateExpiryType
+	default:
+		return UnknownType
 	}
-	return UnknownType
 }
 
 // Threshold contains a threshold and its direction.
@@ -79,6 +85,12 @@ func (t Threshold) Granularity() time.Duration {
 	}
 }
 
+// CertificateExpiry contains the delay before the expiry of the certificates of an ingress from which an alert is
+// raised.
+type CertificateExpiry struct {
+	Within time.Duration `json:"within"`
+}
+
 // ThresholdCondition contains a threshold condition.
 type ThresholdCondition struct {
 	Above bool    `json:"above"`
@@ -93,6 +105,8 @@ type Alert struct {
 	Points    []Point    `json:"points"`
 	Logs      []byte     `json:"logs"`
 	Threshold *Threshold `json:"threshold"`
+
+	CertificateExpiry *CertificateExpiry `json:"certificateExpiry,omitempty"`
 }
 
 // Point contains a point and its timestamp.
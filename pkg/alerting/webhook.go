@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhooksig"
+)
+
+// WebhookNotifier notifies alerts by posting them to a webhook.
+type WebhookNotifier struct {
+	url        string
+	hmacSecret []byte
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier returns a notifier posting alerts to the given webhook URL. Alerts are signed with the given
+// HMAC secret, if any.
+func NewWebhookNotifier(client *http.Client, webhookURL, hmacSecret string) (*WebhookNotifier, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook url %q: scheme must be http or https", webhookURL)
+	}
+
+	return &WebhookNotifier{
+		url:        u.String(),
+		hmacSecret: []byte(hmacSecret),
+		httpClient: client,
+	}, nil
+}
+
+type webhookPayload struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+// Notify posts the given alerts to the webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, alerts []Alert) error {
+	body, err := json.Marshal(webhookPayload{Alerts: alerts})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	version.SetUserAgent(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(n.hmacSecret) > 0 {
+		webhooksig.Sign(req, n.hmacSecret, body)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("notifying alerts: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("notifying alerts got %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package alerting_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/alerting"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhooksig"
+)
+
+func TestNewWebhookNotifier_invalidURL(t *testing.T) {
+	_, err := alerting.NewWebhookNotifier(http.DefaultClient, "ftp://example.com/hook", "")
+	assert.Error(t, err)
+}
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	data := []alerting.Alert{
+		{
+			RuleID:  "123",
+			Ingress: "ing",
+			Points: []alerting.Point{
+				{
+					Timestamp: time.Now().Unix(),
+					Value:     42,
+				},
+			},
+			CertificateExpiry: &alerting.CertificateExpiry{Within: time.Hour},
+		},
+	}
+
+	var callCount int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/hook", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload struct {
+			Alerts []alerting.Alert `json:"alerts"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		require.NoError(t, err)
+		assert.Equal(t, data, payload.Alerts)
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier, err := alerting.NewWebhookNotifier(http.DefaultClient, srv.URL+"/hook", "")
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, callCount)
+}
+
+func TestWebhookNotifier_Notify_signsAlerts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("s3cr3t"))
+		_, _ = mac.Write(body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(webhooksig.Header))
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier, err := alerting.NewWebhookNotifier(http.DefaultClient, srv.URL, "s3cr3t")
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []alerting.Alert{{RuleID: "123"}})
+	assert.NoError(t, err)
+}
+
+func TestWebhookNotifier_Notify_handlesErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	notifier, err := alerting.NewWebhookNotifier(http.DefaultClient, srv.URL, "")
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), []alerting.Alert{{RuleID: "123"}})
+	assert.Error(t, err)
+}
@@ -32,6 +32,7 @@ type Access struct {
 	Labels map[string]string `json:"labels,omitempty"`
 
 	Groups                []string              `json:"groups"`
+	Users                 []string              `json:"users,omitempty"`
 	APISelector           *metav1.LabelSelector `json:"apiSelector,omitempty"`
 	APICollectionSelector *metav1.LabelSelector `json:"apiCollectionSelector,omitempty"`
 
@@ -54,6 +55,7 @@ func (a *Access) Resource() (*hubv1alpha1.APIAccess, error) {
 		},
 		Spec: hubv1alpha1.APIAccessSpec{
 			Groups:                a.Groups,
+			Users:                 a.Users,
 			APISelector:           a.APISelector,
 			APICollectionSelector: a.APICollectionSelector,
 		},
@@ -75,6 +77,7 @@ func (a *Access) Resource() (*hubv1alpha1.APIAccess, error) {
 
 type accessHash struct {
 	Groups                []string          `json:"groups"`
+	Users                 []string          `json:"users,omitempty"`
 	APISelector           string            `json:"apiSelector"`
 	APICollectionSelector string            `json:"apiCollectionSelector"`
 	Labels                sortedMap[string] `json:"labels"`
@@ -84,6 +87,7 @@ type accessHash struct {
 func HashAccess(a *hubv1alpha1.APIAccess) (string, error) {
 	ah := accessHash{
 		Groups: a.Spec.Groups,
+		Users:  a.Spec.Users,
 		Labels: newSortedMap(a.Labels),
 	}
 	if a.Spec.APISelector != nil {
@@ -98,6 +98,7 @@ func (a *Access) reviewCreateOperation(ctx context.Context, accessCRD *hubv1alph
 		Name:                  accessCRD.Name,
 		Labels:                accessCRD.Labels,
 		Groups:                accessCRD.Spec.Groups,
+		Users:                 accessCRD.Spec.Users,
 		APISelector:           accessCRD.Spec.APISelector,
 		APICollectionSelector: accessCRD.Spec.APICollectionSelector,
 	}
@@ -116,6 +117,7 @@ func (a *Access) reviewUpdateOperation(ctx context.Context, oldAccess, newAccess
 	updateReq := &platform.UpdateAccessReq{
 		Labels:                newAccess.Labels,
 		Groups:                newAccess.Spec.Groups,
+		Users:                 newAccess.Spec.Users,
 		APISelector:           newAccess.Spec.APISelector,
 		APICollectionSelector: newAccess.Spec.APICollectionSelector,
 	}
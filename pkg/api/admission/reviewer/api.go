@@ -104,18 +104,15 @@ func (a *API) reviewCreateOperation(ctx context.Context, apiCRD *hubv1alpha1.API
 		Labels:     apiCRD.Labels,
 		PathPrefix: apiCRD.Spec.PathPrefix,
 		Service: platform.APIService{
-			Name: apiCRD.Spec.Service.Name,
-			Port: int(apiCRD.Spec.Service.Port.Number),
-			OpenAPISpec: platform.OpenAPISpec{
-				URL:  apiCRD.Spec.Service.OpenAPISpec.URL,
-				Path: apiCRD.Spec.Service.OpenAPISpec.Path,
-			},
+			Name:        apiCRD.Spec.Service.Name,
+			Port:        int(apiCRD.Spec.Service.Port.Number),
+			OpenAPISpec: buildOpenAPISpec(apiCRD.Spec.Service.OpenAPISpec),
 		},
 	}
 
-	if apiCRD.Spec.Service.OpenAPISpec.Port != nil {
-		createReq.Service.OpenAPISpec.Port = int(apiCRD.Spec.Service.OpenAPISpec.Port.Number)
-	}
+	createReq.Service.Weight, createReq.Service.Weighted = buildWeightedServices(apiCRD.Spec.Service)
+	createReq.Versions = buildVersions(apiCRD.Spec.Versions)
+	createReq.TermsOfService = buildTermsOfService(apiCRD.Spec.TermsOfService)
 
 	createdAPI, err := a.platform.CreateAPI(ctx, createReq)
 	if err != nil {
@@ -136,18 +133,15 @@ func (a *API) reviewUpdateOperation(ctx context.Context, oldAPI, newAPI *hubv1al
 		Labels:     newAPI.Labels,
 		PathPrefix: newAPI.Spec.PathPrefix,
 		Service: platform.APIService{
-			Name: newAPI.Spec.Service.Name,
-			Port: int(newAPI.Spec.Service.Port.Number),
-			OpenAPISpec: platform.OpenAPISpec{
-				URL:  newAPI.Spec.Service.OpenAPISpec.URL,
-				Path: newAPI.Spec.Service.OpenAPISpec.Path,
-			},
+			Name:        newAPI.Spec.Service.Name,
+			Port:        int(newAPI.Spec.Service.Port.Number),
+			OpenAPISpec: buildOpenAPISpec(newAPI.Spec.Service.OpenAPISpec),
 		},
 	}
 
-	if newAPI.Spec.Service.OpenAPISpec.Port != nil {
-		updateReq.Service.OpenAPISpec.Port = int(newAPI.Spec.Service.OpenAPISpec.Port.Number)
-	}
+	updateReq.Service.Weight, updateReq.Service.Weighted = buildWeightedServices(newAPI.Spec.Service)
+	updateReq.Versions = buildVersions(newAPI.Spec.Versions)
+	updateReq.TermsOfService = buildTermsOfService(newAPI.Spec.TermsOfService)
 
 	updateAPI, err := a.platform.UpdateAPI(ctx, oldAPI.Namespace, oldAPI.Name, oldAPI.Status.Version, updateReq)
 	if err != nil {
@@ -182,6 +176,83 @@ func (a *API) CanReview(req *admv1.AdmissionRequest) bool {
 	return req.Kind.Kind == "API" && req.Kind.Group == hubv1alpha1.SchemeGroupVersion.Group && req.Kind.Version == hubv1alpha1.SchemeGroupVersion.Version
 }
 
+func buildWeightedServices(svc hubv1alpha1.APIService) (*int, []platform.APIWeightedService) {
+	var weighted []platform.APIWeightedService
+	for _, weightedSvc := range svc.Weighted {
+		weighted = append(weighted, platform.APIWeightedService{
+			Name:   weightedSvc.Name,
+			Port:   int(weightedSvc.Port.Number),
+			Weight: weightedSvc.Weight,
+		})
+	}
+
+	return svc.Weight, weighted
+}
+
+func buildVersions(versions []hubv1alpha1.APIVersion) []platform.APIVersion {
+	var res []platform.APIVersion
+	for _, version := range versions {
+		res = append(res, platform.APIVersion{
+			Name:        version.Name,
+			PathPrefix:  version.PathPrefix,
+			OpenAPISpec: buildOpenAPISpec(version.OpenAPISpec),
+			Deprecated:  version.Deprecated,
+		})
+	}
+
+	return res
+}
+
+func buildOpenAPISpec(spec hubv1alpha1.OpenAPISpec) platform.OpenAPISpec {
+	res := platform.OpenAPISpec{
+		URL:          spec.URL,
+		Path:         spec.Path,
+		Port:         buildPort(spec.Port),
+		ConfigMapRef: buildConfigMapRef(spec.ConfigMapRef),
+	}
+
+	for _, fallback := range spec.Fallbacks {
+		res.Fallbacks = append(res.Fallbacks, platform.OpenAPISpecSource{
+			URL:          fallback.URL,
+			Path:         fallback.Path,
+			Port:         buildPort(fallback.Port),
+			ConfigMapRef: buildConfigMapRef(fallback.ConfigMapRef),
+		})
+	}
+
+	return res
+}
+
+func buildPort(port *hubv1alpha1.APIServiceBackendPort) int {
+	if port == nil {
+		return 0
+	}
+
+	return int(port.Number)
+}
+
+func buildConfigMapRef(ref *hubv1alpha1.OpenAPISpecConfigMapRef) *platform.OpenAPISpecConfigMapRef {
+	if ref == nil {
+		return nil
+	}
+
+	return &platform.OpenAPISpecConfigMapRef{
+		Name: ref.Name,
+		Key:  ref.Key,
+	}
+}
+
+func buildTermsOfService(tos *hubv1alpha1.TermsOfService) *platform.TermsOfService {
+	if tos == nil {
+		return nil
+	}
+
+	return &platform.TermsOfService{
+		URL:     tos.URL,
+		Version: tos.Version,
+	}
+}
+
 type patch struct {
 	Op    string      `json:"op"`
 	Path  string      `json:"path"`
@@ -99,6 +99,10 @@ func (g *Gateway) reviewCreateOperation(ctx context.Context, gateway *hubv1alpha
 		Labels:        gateway.Labels,
 		Accesses:      gateway.Spec.APIAccesses,
 		CustomDomains: gateway.Spec.CustomDomains,
+
+		CredentialLocations: gateway.Spec.CredentialLocations,
+		ExampleCapture:      buildExampleCapture(gateway.Spec.ExampleCapture),
+		IdentityHeaders:     buildIdentityHeaders(gateway.Spec.IdentityHeaders),
 	}
 
 	createdGateway, err := g.platform.CreateGateway(ctx, createReq)
@@ -116,6 +120,10 @@ func (g *Gateway) reviewUpdateOperation(ctx context.Context, oldGateway, newGate
 		Labels:        newGateway.Labels,
 		Accesses:      newGateway.Spec.APIAccesses,
 		CustomDomains: newGateway.Spec.CustomDomains,
+
+		CredentialLocations: newGateway.Spec.CredentialLocations,
+		ExampleCapture:      buildExampleCapture(newGateway.Spec.ExampleCapture),
+		IdentityHeaders:     buildIdentityHeaders(newGateway.Spec.IdentityHeaders),
 	}
 
 	updatedGateway, err := g.platform.UpdateGateway(ctx, oldGateway.Name, oldGateway.Status.Version, updateReq)
@@ -150,3 +158,29 @@ func (g *Gateway) buildPatches(gateway *api.Gateway) ([]byte, error) {
 func (g *Gateway) CanReview(req *admv1.AdmissionRequest) bool {
 	return req.Kind.Kind == "APIGateway" && req.Kind.Group == hubv1alpha1.SchemeGroupVersion.Group && req.Kind.Version == hubv1alpha1.SchemeGroupVersion.Version
 }
+
+func buildExampleCapture(capture *hubv1alpha1.APIGatewayExampleCapture) *platform.ExampleCapture {
+	if capture == nil {
+		return nil
+	}
+
+	return &platform.ExampleCapture{
+		SampleRate:              capture.SampleRate,
+		MaxExamplesPerOperation: capture.MaxExamplesPerOperation,
+		MaxBodySize:             capture.MaxBodySize,
+		RedactedHeaders:         capture.RedactedHeaders,
+		RedactedFields:          capture.RedactedFields,
+	}
+}
+
+func buildIdentityHeaders(headers *hubv1alpha1.APIGatewayIdentityHeaders) *platform.IdentityHeaders {
+	if headers == nil {
+		return nil
+	}
+
+	return &platform.IdentityHeaders{
+		User:   headers.User,
+		Email:  headers.Email,
+		Groups: headers.Groups,
+	}
+}
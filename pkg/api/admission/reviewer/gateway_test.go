@@ -145,8 +145,11 @@ func TestGateway_Review_updateOperation(t *testing.T) {
 				},
 				ObjectMeta: metav1.ObjectMeta{Name: "gateway-name"},
 				Spec: hubv1alpha1.APIGatewaySpec{
-					APIAccesses:   []string{"newAccess"},
-					CustomDomains: []string{"newCustomDomain"},
+					APIAccesses:         []string{"newAccess"},
+					CustomDomains:       []string{"newCustomDomain"},
+					CredentialLocations: []string{hubv1alpha1.CredentialLocationHeader},
+					ExampleCapture:      &hubv1alpha1.APIGatewayExampleCapture{SampleRate: 10},
+					IdentityHeaders:     &hubv1alpha1.APIGatewayIdentityHeaders{User: "X-Remote-User"},
 				},
 			}),
 		},
@@ -177,8 +180,11 @@ func TestGateway_Review_updateOperation(t *testing.T) {
 			desc: "call APIGateway service on update admission request",
 			req:  updateReq,
 			wantUpdateReq: &platform.UpdateGatewayReq{
-				Accesses:      []string{"newAccess"},
-				CustomDomains: []string{"newCustomDomain"},
+				Accesses:            []string{"newAccess"},
+				CustomDomains:       []string{"newCustomDomain"},
+				CredentialLocations: []string{hubv1alpha1.CredentialLocationHeader},
+				ExampleCapture:      &platform.ExampleCapture{SampleRate: 10},
+				IdentityHeaders:     &platform.IdentityHeaders{User: "X-Remote-User"},
 			},
 			wantPatch: mustMarshal(t, []patch{
 				{Op: "replace", Path: "/status", Value: hubv1alpha1.APIGatewayStatus{
@@ -193,8 +199,11 @@ func TestGateway_Review_updateOperation(t *testing.T) {
 			desc: "APIGateway service is broken",
 			req:  updateReq,
 			wantUpdateReq: &platform.UpdateGatewayReq{
-				Accesses:      []string{"newAccess"},
-				CustomDomains: []string{"newCustomDomain"},
+				Accesses:            []string{"newAccess"},
+				CustomDomains:       []string{"newCustomDomain"},
+				CredentialLocations: []string{hubv1alpha1.CredentialLocationHeader},
+				ExampleCapture:      &platform.ExampleCapture{SampleRate: 10},
+				IdentityHeaders:     &platform.IdentityHeaders{User: "X-Remote-User"},
 			},
 			errUpdate: errors.New("boom"),
 		},
@@ -0,0 +1,211 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// route is a path prefix under which a gateway exposes an API.
+type route struct {
+	api        string
+	collection string
+	pathPrefix string
+}
+
+// overlap describes an API exposed under the same path prefix as another API on a gateway.
+type overlap struct {
+	gateway    string
+	api        string
+	other      string
+	pathPrefix string
+}
+
+// findOverlaps finds, for every gateway, the APIs exposed under the same path prefix as the given API or as one of
+// the APIs of the given collection. The candidate replaces the existing resource with the same name, if any.
+func (h Handler) findOverlaps(candidateAPI *hubv1alpha1.API, candidateCollection *hubv1alpha1.APICollection) ([]overlap, error) {
+	apis, err := h.apis.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIs: %w", err)
+	}
+	if candidateAPI != nil {
+		apis = replaceAPI(apis, candidateAPI)
+	}
+
+	collections, err := h.collections.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	if candidateCollection != nil {
+		collections = replaceCollection(collections, candidateCollection)
+	}
+
+	gateways, err := h.gateways.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list gateways: %w", err)
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].Name < gateways[j].Name
+	})
+
+	isCandidate := func(r route) bool {
+		if candidateAPI != nil {
+			return r.api == apiKey(candidateAPI)
+		}
+		return r.collection == candidateCollection.Name
+	}
+
+	var overlaps []overlap
+	for _, gateway := range gateways {
+		routes, err := h.gatewayRoutes(gateway, apis, collections)
+		if err != nil {
+			return nil, fmt.Errorf("get routes of gateway %q: %w", gateway.Name, err)
+		}
+
+		apisByPrefix := make(map[string]map[string]struct{})
+		for _, r := range routes {
+			if apisByPrefix[r.pathPrefix] == nil {
+				apisByPrefix[r.pathPrefix] = make(map[string]struct{})
+			}
+			apisByPrefix[r.pathPrefix][r.api] = struct{}{}
+		}
+
+		reported := make(map[[2]string]struct{})
+		for _, r := range routes {
+			if !isCandidate(r) {
+				continue
+			}
+
+			for _, other := range sortedKeys(apisByPrefix[r.pathPrefix]) {
+				pair := [2]string{r.api, other}
+				if pair[0] > pair[1] {
+					pair[0], pair[1] = pair[1], pair[0]
+				}
+				if _, ok := reported[pair]; ok || other == r.api {
+					continue
+				}
+				reported[pair] = struct{}{}
+
+				overlaps = append(overlaps, overlap{
+					gateway:    gateway.Name,
+					api:        r.api,
+					other:      other,
+					pathPrefix: r.pathPrefix,
+				})
+			}
+		}
+	}
+
+	return overlaps, nil
+}
+
+// gatewayRoutes returns the routes of the given gateway, resolved the same way the gateway watcher does.
+func (h Handler) gatewayRoutes(gateway *hubv1alpha1.APIGateway, apis []*hubv1alpha1.API, collections []*hubv1alpha1.APICollection) ([]route, error) {
+	var routes []route
+	for _, accessName := range gateway.Spec.APIAccesses {
+		access, err := h.accesses.Get(accessName)
+		if kerror.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get access %q: %w", accessName, err)
+		}
+
+		for _, a := range apis {
+			if matches(access.Spec.APISelector, a.Labels) {
+				routes = append(routes, route{api: apiKey(a), pathPrefix: path.Clean(a.Spec.PathPrefix)})
+			}
+		}
+
+		for _, collection := range collections {
+			if !matches(access.Spec.APICollectionSelector, collection.Labels) {
+				continue
+			}
+
+			for _, a := range apis {
+				if !matches(&collection.Spec.APISelector, a.Labels) {
+					continue
+				}
+
+				routes = append(routes, route{
+					api:        apiKey(a),
+					collection: collection.Name,
+					pathPrefix: path.Join(collection.Spec.PathPrefix, a.Spec.PathPrefix),
+				})
+			}
+		}
+	}
+
+	return routes, nil
+}
+
+func matches(selector *metav1.LabelSelector, lbls map[string]string) bool {
+	if selector == nil {
+		return false
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return s.Matches(labels.Set(lbls))
+}
+
+func replaceAPI(apis []*hubv1alpha1.API, candidate *hubv1alpha1.API) []*hubv1alpha1.API {
+	res := []*hubv1alpha1.API{candidate}
+	for _, a := range apis {
+		if apiKey(a) != apiKey(candidate) {
+			res = append(res, a)
+		}
+	}
+
+	return res
+}
+
+func replaceCollection(collections []*hubv1alpha1.APICollection, candidate *hubv1alpha1.APICollection) []*hubv1alpha1.APICollection {
+	res := []*hubv1alpha1.APICollection{candidate}
+	for _, c := range collections {
+		if c.Name != candidate.Name {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+func apiKey(a *hubv1alpha1.API) string {
+	return a.Name + "@" + a.Namespace
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,264 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func (h Handler) validateAPI(a *hubv1alpha1.API) (field.ErrorList, error) {
+	var errs field.ErrorList
+
+	specPath := field.NewPath("spec")
+	errs = append(errs, validatePathPrefix(specPath.Child("pathPrefix"), a.Spec.PathPrefix, true)...)
+
+	svcPath := specPath.Child("service")
+	svcErrs, err := h.validateServiceRef(svcPath, a.Namespace, a.Spec.Service.Name, a.Spec.Service.Port)
+	if err != nil {
+		return nil, fmt.Errorf("validate service: %w", err)
+	}
+	errs = append(errs, svcErrs...)
+
+	for i, weighted := range a.Spec.Service.Weighted {
+		svcErrs, err = h.validateServiceRef(svcPath.Child("weighted").Index(i), a.Namespace, weighted.Name, weighted.Port)
+		if err != nil {
+			return nil, fmt.Errorf("validate weighted service: %w", err)
+		}
+		errs = append(errs, svcErrs...)
+	}
+
+	errs = append(errs, validateOpenAPISpec(svcPath.Child("openApiSpec"), a.Spec.Service.OpenAPISpec)...)
+
+	versionNames := make(map[string]struct{})
+	for i, version := range a.Spec.Versions {
+		versionPath := specPath.Child("versions").Index(i)
+
+		if version.Name == "" {
+			errs = append(errs, field.Required(versionPath.Child("name"), ""))
+		} else if _, ok := versionNames[version.Name]; ok {
+			errs = append(errs, field.Duplicate(versionPath.Child("name"), version.Name))
+		}
+		versionNames[version.Name] = struct{}{}
+
+		errs = append(errs, validateOpenAPISpec(versionPath.Child("openApiSpec"), version.OpenAPISpec)...)
+	}
+
+	// Overlaps can only be reliably detected once the path prefix is valid.
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	overlaps, err := h.findOverlaps(a, nil)
+	if err != nil {
+		return nil, fmt.Errorf("find overlapping path prefixes: %w", err)
+	}
+
+	for _, o := range overlaps {
+		errs = append(errs, field.Invalid(specPath.Child("pathPrefix"), a.Spec.PathPrefix,
+			fmt.Sprintf("path prefix %q overlaps with API %q on APIGateway %q", o.pathPrefix, o.other, o.gateway)))
+	}
+
+	return errs, nil
+}
+
+func (h Handler) validateCollection(collection *hubv1alpha1.APICollection) (field.ErrorList, error) {
+	pathPrefixPath := field.NewPath("spec", "pathPrefix")
+
+	errs := validatePathPrefix(pathPrefixPath, collection.Spec.PathPrefix, false)
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	overlaps, err := h.findOverlaps(nil, collection)
+	if err != nil {
+		return nil, fmt.Errorf("find overlapping path prefixes: %w", err)
+	}
+
+	for _, o := range overlaps {
+		errs = append(errs, field.Invalid(pathPrefixPath, collection.Spec.PathPrefix,
+			fmt.Sprintf("path prefix %q of API %q overlaps with API %q on APIGateway %q", o.pathPrefix, o.api, o.other, o.gateway)))
+	}
+
+	return errs, nil
+}
+
+// validateServiceRef makes sure the referenced Service exists and exposes the referenced port.
+func (h Handler) validateServiceRef(fldPath *field.Path, namespace, name string, port hubv1alpha1.APIServiceBackendPort) (field.ErrorList, error) {
+	var errs field.ErrorList
+
+	if name == "" {
+		errs = append(errs, field.Required(fldPath.Child("name"), ""))
+	}
+
+	portErrs := validatePort(fldPath.Child("port"), port)
+	errs = append(errs, portErrs...)
+
+	// Static Handlers given no Service can't resolve the reference, it is assumed to be valid.
+	if name == "" || h.services == nil {
+		return errs, nil
+	}
+
+	svc, err := h.services.Services(namespace).Get(name)
+	if kerror.IsNotFound(err) {
+		return append(errs, field.NotFound(fldPath.Child("name"), name)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get service %s/%s: %w", namespace, name, err)
+	}
+
+	if len(portErrs) == 0 && !hasPort(svc, port) {
+		if port.Name != "" {
+			errs = append(errs, field.NotFound(fldPath.Child("port", "name"), port.Name))
+		} else {
+			errs = append(errs, field.NotFound(fldPath.Child("port", "number"), port.Number))
+		}
+	}
+
+	return errs, nil
+}
+
+func validatePort(fldPath *field.Path, port hubv1alpha1.APIServiceBackendPort) field.ErrorList {
+	switch {
+	case port.Name != "" && port.Number != 0:
+		return field.ErrorList{field.Invalid(fldPath, port, "only one of name or number may be set")}
+	case port.Name != "":
+		var errs field.ErrorList
+		for _, msg := range validation.IsValidPortName(port.Name) {
+			errs = append(errs, field.Invalid(fldPath.Child("name"), port.Name, msg))
+		}
+		return errs
+	case port.Number != 0:
+		var errs field.ErrorList
+		for _, msg := range validation.IsValidPortNum(int(port.Number)) {
+			errs = append(errs, field.Invalid(fldPath.Child("number"), port.Number, msg))
+		}
+		return errs
+	default:
+		return field.ErrorList{field.Required(fldPath, "one of name or number must be set")}
+	}
+}
+
+func hasPort(svc *corev1.Service, port hubv1alpha1.APIServiceBackendPort) bool {
+	for _, p := range svc.Spec.Ports {
+		if port.Name != "" && p.Name == port.Name || port.Number != 0 && p.Port == port.Number {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateOpenAPISpec(fldPath *field.Path, spec hubv1alpha1.OpenAPISpec) field.ErrorList {
+	sources := spec.Sources()
+
+	// The first source is the OpenAPI spec itself. Its empty value is allowed as it means the spec is served by the
+	// API service.
+	errs := validateOpenAPISpecSource(fldPath, sources[0])
+	for i, fallback := range spec.Fallbacks {
+		fallbackPath := fldPath.Child("fallbacks").Index(i)
+		if fallback.URL == "" && fallback.Path == "" && fallback.Port == nil && fallback.ConfigMapRef == nil {
+			errs = append(errs, field.Required(fallbackPath, "one of url, path, port or configMapRef must be set"))
+			continue
+		}
+
+		errs = append(errs, validateOpenAPISpecSource(fallbackPath, fallback)...)
+	}
+
+	return errs
+}
+
+func validateOpenAPISpecSource(fldPath *field.Path, spec hubv1alpha1.OpenAPISpecSource) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.URL != "" {
+		u, err := url.ParseRequestURI(spec.URL)
+		switch {
+		case err != nil:
+			errs = append(errs, field.Invalid(fldPath.Child("url"), spec.URL, "must be a valid URL"))
+		case u.Scheme != "http" && u.Scheme != "https":
+			errs = append(errs, field.NotSupported(fldPath.Child("url"), spec.URL, []string{"http", "https"}))
+		case u.Host == "":
+			errs = append(errs, field.Invalid(fldPath.Child("url"), spec.URL, "must be an absolute URL"))
+		}
+	}
+
+	if spec.Path != "" && !strings.HasPrefix(spec.Path, "/") {
+		errs = append(errs, field.Invalid(fldPath.Child("path"), spec.Path, "must start with a '/'"))
+	}
+
+	if spec.Port != nil {
+		errs = append(errs, validatePort(fldPath.Child("port"), *spec.Port)...)
+	}
+
+	if spec.Protocol != "" && spec.Protocol != "http" && spec.Protocol != "https" {
+		errs = append(errs, field.NotSupported(fldPath.Child("protocol"), spec.Protocol, []string{"http", "https"}))
+	}
+
+	if spec.ConfigMapRef != nil {
+		if spec.URL != "" || spec.Path != "" || spec.Port != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("configMapRef"), spec.ConfigMapRef.Name, "must not be set along with a url, path or port"))
+		}
+		if spec.ConfigMapRef.Name == "" {
+			errs = append(errs, field.Required(fldPath.Child("configMapRef", "name"), ""))
+		}
+		if spec.ConfigMapRef.Key == "" {
+			errs = append(errs, field.Required(fldPath.Child("configMapRef", "key"), ""))
+		}
+	}
+
+	return errs
+}
+
+func validatePathPrefix(fldPath *field.Path, pathPrefix string, required bool) field.ErrorList {
+	if pathPrefix == "" {
+		if required {
+			return field.ErrorList{field.Required(fldPath, "")}
+		}
+		return nil
+	}
+
+	if !strings.HasPrefix(pathPrefix, "/") {
+		return field.ErrorList{field.Invalid(fldPath, pathPrefix, "must start with a '/'")}
+	}
+
+	return nil
+}
+
+func validateCustomDomains(fldPath *field.Path, domains []string) field.ErrorList {
+	var errs field.ErrorList
+
+	seen := make(map[string]struct{})
+	for i, domain := range domains {
+		errs = append(errs, validation.IsFullyQualifiedDomainName(fldPath.Index(i), domain)...)
+
+		if _, ok := seen[domain]; ok {
+			errs = append(errs, field.Duplicate(fldPath.Index(i), domain))
+		}
+		seen[domain] = struct{}{}
+	}
+
+	return errs
+}
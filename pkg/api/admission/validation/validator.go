@@ -0,0 +1,217 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	hublisters "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	admv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/informers"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Handler is an HTTP handler that can be used as a Kubernetes Validating Admission Controller.
+// It rejects API management resources which can't be deployed as configured, reporting the faulty fields.
+type Handler struct {
+	services    corelisters.ServiceLister
+	apis        hublisters.APILister
+	collections hublisters.APICollectionLister
+	accesses    hublisters.APIAccessLister
+	gateways    hublisters.APIGatewayLister
+}
+
+// NewHandler returns a new Handler. Services, APIs, APICollections, APIAccesses and APIGateways informers
+// must be registered on the given factories before they are started.
+func NewHandler(kubeInformer informers.SharedInformerFactory, hubInformer hubinformer.SharedInformerFactory) *Handler {
+	return &Handler{
+		services:    kubeInformer.Core().V1().Services().Lister(),
+		apis:        hubInformer.Hub().V1alpha1().APIs().Lister(),
+		collections: hubInformer.Hub().V1alpha1().APICollections().Lister(),
+		accesses:    hubInformer.Hub().V1alpha1().APIAccesses().Lister(),
+		gateways:    hubInformer.Hub().V1alpha1().APIGateways().Lister(),
+	}
+}
+
+// NewStaticHandler returns a Handler validating resources against the given objects instead of the cluster state,
+// for instance to validate manifests before applying them. Services, APIs, APICollections, APIAccesses and APIGateways
+// are indexed while other objects are ignored. When no Service is given, the Services referenced by APIs are assumed
+// to exist.
+func NewStaticHandler(objects []runtime.Object) (*Handler, error) {
+	newIndexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	services, apis, collections, accesses, gateways := newIndexer(), newIndexer(), newIndexer(), newIndexer(), newIndexer()
+
+	var hasServices bool
+	for _, obj := range objects {
+		var indexer cache.Indexer
+		switch obj.(type) {
+		case *corev1.Service:
+			indexer = services
+			hasServices = true
+		case *hubv1alpha1.API:
+			indexer = apis
+		case *hubv1alpha1.APICollection:
+			indexer = collections
+		case *hubv1alpha1.APIAccess:
+			indexer = accesses
+		case *hubv1alpha1.APIGateway:
+			indexer = gateways
+		default:
+			continue
+		}
+
+		if err := indexer.Add(obj); err != nil {
+			return nil, fmt.Errorf("index %T: %w", obj, err)
+		}
+	}
+
+	h := &Handler{
+		apis:        hublisters.NewAPILister(apis),
+		collections: hublisters.NewAPICollectionLister(collections),
+		accesses:    hublisters.NewAPIAccessLister(accesses),
+		gateways:    hublisters.NewAPIGatewayLister(gateways),
+	}
+	if hasServices {
+		h.services = corelisters.NewServiceLister(services)
+	}
+
+	return h, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var ar admv1.AdmissionReview
+	if err := json.NewDecoder(req.Body).Decode(&ar); err != nil {
+		log.Error().Err(err).Msg("Unable to decode admission request")
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if ar.Request == nil {
+		log.Error().Msg("No request found")
+		http.Error(rw, "No request found", http.StatusUnprocessableEntity)
+		return
+	}
+
+	l := log.Logger.With().
+		Str("uid", string(ar.Request.UID)).
+		Str("resource_kind", ar.Request.Kind.String()).
+		Str("resource_name", ar.Request.Name).
+		Logger()
+	ctx := l.WithContext(req.Context())
+
+	ar.Response = &admv1.AdmissionResponse{
+		Allowed: true,
+		UID:     ar.Request.UID,
+	}
+
+	errs, err := h.validate(ar.Request)
+	switch {
+	case err != nil:
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to validate admission request")
+
+		ar.Response.Allowed = false
+		ar.Response.Result = &metav1.Status{
+			Status:  "Failure",
+			Message: err.Error(),
+		}
+	case len(errs) > 0:
+		log.Ctx(ctx).Debug().Err(errs.ToAggregate()).Msg("Rejecting invalid resource")
+
+		gk := hubv1alpha1.SchemeGroupVersion.WithKind(ar.Request.Kind.Kind).GroupKind()
+		status := kerror.NewInvalid(gk, ar.Request.Name, errs).ErrStatus
+
+		ar.Response.Allowed = false
+		ar.Response.Result = &status
+	}
+
+	if err = json.NewEncoder(rw).Encode(ar); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to encode admission response")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h Handler) validate(req *admv1.AdmissionRequest) (field.ErrorList, error) {
+	if req.Operation == admv1.Delete {
+		return nil, nil
+	}
+
+	if req.Kind.Group != hubv1alpha1.SchemeGroupVersion.Group || req.Kind.Version != hubv1alpha1.SchemeGroupVersion.Version {
+		return nil, fmt.Errorf("unsupported resource %s", req.Kind.String())
+	}
+
+	var obj runtime.Object
+	switch req.Kind.Kind {
+	case "API":
+		obj = &hubv1alpha1.API{}
+	case "APICollection":
+		obj = &hubv1alpha1.APICollection{}
+	case "APIPortal":
+		obj = &hubv1alpha1.APIPortal{}
+	case "APIGateway":
+		obj = &hubv1alpha1.APIGateway{}
+	default:
+		return nil, fmt.Errorf("unsupported resource %s", req.Kind.String())
+	}
+
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", req.Kind.Kind, err)
+	}
+	if a, ok := obj.(*hubv1alpha1.API); ok && a.Namespace == "" {
+		a.Namespace = req.Namespace
+	}
+
+	return h.Validate(obj)
+}
+
+// Validate validates the given API management resource, reporting the faulty fields.
+// APIs without namespace are considered to be in the default namespace.
+func (h Handler) Validate(obj runtime.Object) (field.ErrorList, error) {
+	switch o := obj.(type) {
+	case *hubv1alpha1.API:
+		a := o
+		if a.Namespace == "" {
+			a = a.DeepCopy()
+			a.Namespace = "default"
+		}
+
+		return h.validateAPI(a)
+	case *hubv1alpha1.APICollection:
+		return h.validateCollection(o)
+	case *hubv1alpha1.APIPortal:
+		return validateCustomDomains(field.NewPath("spec", "customDomains"), o.Spec.CustomDomains), nil
+	case *hubv1alpha1.APIGateway:
+		return validateCustomDomains(field.NewPath("spec", "customDomains"), o.Spec.CustomDomains), nil
+	default:
+		return nil, fmt.Errorf("unsupported resource %T", obj)
+	}
+}
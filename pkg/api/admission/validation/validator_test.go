@@ -0,0 +1,423 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	admv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc       string
+		kind       string
+		operation  admv1.Operation
+		object     interface{}
+		wantCauses []metav1.StatusCause
+	}{
+		{
+			desc:      "valid API",
+			kind:      "API",
+			operation: admv1.Create,
+			object: &hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", Labels: map[string]string{"area": "products"}},
+				Spec: hubv1alpha1.APISpec{
+					PathPrefix: "/books",
+					Service: hubv1alpha1.APIService{
+						Name: "books-svc",
+						Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+						OpenAPISpec: hubv1alpha1.OpenAPISpec{
+							URL: "https://example.com/openapi.json",
+						},
+					},
+				},
+			},
+		},
+		{
+			desc:      "deleted API is always allowed",
+			kind:      "API",
+			operation: admv1.Delete,
+		},
+		{
+			desc:      "API with missing services and invalid ports",
+			kind:      "API",
+			operation: admv1.Update,
+			object: &hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default"},
+				Spec: hubv1alpha1.APISpec{
+					PathPrefix: "/books",
+					Service: hubv1alpha1.APIService{
+						Name: "books-svc",
+						Port: hubv1alpha1.APIServiceBackendPort{Name: "grpc"},
+						Weighted: []hubv1alpha1.APIWeightedService{
+							{Name: "unknown-svc", Port: hubv1alpha1.APIServiceBackendPort{Number: 80}, Weight: 10},
+							{Name: "books-svc", Weight: 10},
+						},
+					},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueNotFound, Message: `Not found: "grpc"`, Field: "spec.service.port.name"},
+				{Type: metav1.CauseTypeFieldValueNotFound, Message: `Not found: "unknown-svc"`, Field: "spec.service.weighted[0].name"},
+				{Type: metav1.CauseTypeFieldValueRequired, Message: "Required value: one of name or number must be set", Field: "spec.service.weighted[1].port"},
+			},
+		},
+		{
+			desc:      "API with malformed path prefix and OpenAPI specs",
+			kind:      "API",
+			operation: admv1.Create,
+			object: &hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default"},
+				Spec: hubv1alpha1.APISpec{
+					PathPrefix: "books",
+					Service: hubv1alpha1.APIService{
+						Name: "books-svc",
+						Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+						OpenAPISpec: hubv1alpha1.OpenAPISpec{
+							URL: "ftp://example.com/openapi.json",
+						},
+					},
+					Versions: []hubv1alpha1.APIVersion{
+						{
+							Name: "v1",
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{
+								Path:     "openapi.json",
+								Protocol: "grpc",
+								Fallbacks: []hubv1alpha1.OpenAPISpecSource{
+									{},
+									{URL: "https://example.com/openapi.json", ConfigMapRef: &hubv1alpha1.OpenAPISpecConfigMapRef{Name: "openapi"}},
+								},
+							},
+						},
+						{Name: "v1"},
+					},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueInvalid, Message: `Invalid value: "books": must start with a '/'`, Field: "spec.pathPrefix"},
+				{Type: metav1.CauseTypeFieldValueNotSupported, Message: `Unsupported value: "ftp://example.com/openapi.json": supported values: "http", "https"`, Field: "spec.service.openApiSpec.url"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Message: `Invalid value: "openapi.json": must start with a '/'`, Field: "spec.versions[0].openApiSpec.path"},
+				{Type: metav1.CauseTypeFieldValueNotSupported, Message: `Unsupported value: "grpc": supported values: "http", "https"`, Field: "spec.versions[0].openApiSpec.protocol"},
+				{Type: metav1.CauseTypeFieldValueRequired, Message: "Required value: one of url, path, port or configMapRef must be set", Field: "spec.versions[0].openApiSpec.fallbacks[0]"},
+				{Type: metav1.CauseTypeFieldValueInvalid, Message: `Invalid value: "openapi": must not be set along with a url, path or port`, Field: "spec.versions[0].openApiSpec.fallbacks[1].configMapRef"},
+				{Type: metav1.CauseTypeFieldValueRequired, Message: "Required value", Field: "spec.versions[0].openApiSpec.fallbacks[1].configMapRef.key"},
+				{Type: metav1.CauseTypeFieldValueDuplicate, Message: `Duplicate value: "v1"`, Field: "spec.versions[1].name"},
+			},
+		},
+		{
+			desc:      "API overlapping with another API of the gateway",
+			kind:      "API",
+			operation: admv1.Create,
+			object: &hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "authors", Namespace: "default", Labels: map[string]string{"area": "products"}},
+				Spec: hubv1alpha1.APISpec{
+					PathPrefix: "/books/",
+					Service: hubv1alpha1.APIService{
+						Name: "books-svc",
+						Port: hubv1alpha1.APIServiceBackendPort{Name: "http"},
+					},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "/books/": path prefix "/books" overlaps with API "books@default" on APIGateway "gateway"`,
+					Field:   "spec.pathPrefix",
+				},
+			},
+		},
+		{
+			desc:      "API not exposed on the same gateway",
+			kind:      "API",
+			operation: admv1.Create,
+			object: &hubv1alpha1.API{
+				ObjectMeta: metav1.ObjectMeta{Name: "authors", Namespace: "default"},
+				Spec: hubv1alpha1.APISpec{
+					PathPrefix: "/books",
+					Service: hubv1alpha1.APIService{
+						Name: "books-svc",
+						Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+					},
+				},
+			},
+		},
+		{
+			desc:      "collection overlapping with another API of the gateway",
+			kind:      "APICollection",
+			operation: admv1.Update,
+			object: &hubv1alpha1.APICollection{
+				ObjectMeta: metav1.ObjectMeta{Name: "stores", Labels: map[string]string{"area": "stores"}},
+				Spec: hubv1alpha1.APICollectionSpec{
+					PathPrefix:  "/",
+					APISelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "stores"}},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "/": path prefix "/books" of API "stores@default" overlaps with API "books@default" on APIGateway "gateway"`,
+					Field:   "spec.pathPrefix",
+				},
+			},
+		},
+		{
+			desc:      "collection with malformed path prefix",
+			kind:      "APICollection",
+			operation: admv1.Create,
+			object: &hubv1alpha1.APICollection{
+				ObjectMeta: metav1.ObjectMeta{Name: "stores"},
+				Spec: hubv1alpha1.APICollectionSpec{
+					PathPrefix: "stores",
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueInvalid, Message: `Invalid value: "stores": must start with a '/'`, Field: "spec.pathPrefix"},
+			},
+		},
+		{
+			desc:      "valid portal",
+			kind:      "APIPortal",
+			operation: admv1.Create,
+			object: &hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "portal"},
+				Spec: hubv1alpha1.APIPortalSpec{
+					APIGateway:    "gateway",
+					CustomDomains: []string{"portal.example.com"},
+				},
+			},
+		},
+		{
+			desc:      "portal with invalid custom domains",
+			kind:      "APIPortal",
+			operation: admv1.Create,
+			object: &hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "portal"},
+				Spec: hubv1alpha1.APIPortalSpec{
+					APIGateway:    "gateway",
+					CustomDomains: []string{"portal.example.com", "https://example.com", "portal.example.com"},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: `Invalid value: "https://example.com": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+					Field:   "spec.customDomains[1]",
+				},
+				{Type: metav1.CauseTypeFieldValueDuplicate, Message: `Duplicate value: "portal.example.com"`, Field: "spec.customDomains[2]"},
+			},
+		},
+		{
+			desc:      "gateway with invalid custom domains",
+			kind:      "APIGateway",
+			operation: admv1.Update,
+			object: &hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "gateway"},
+				Spec: hubv1alpha1.APIGatewaySpec{
+					CustomDomains: []string{"localhost"},
+				},
+			},
+			wantCauses: []metav1.StatusCause{
+				{Type: metav1.CauseTypeFieldValueInvalid, Message: `Invalid value: "localhost": should be a domain with at least two segments separated by dots`, Field: "spec.customDomains[0]"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newHandler(t)
+
+			var raw []byte
+			if test.object != nil {
+				var err error
+				raw, err = json.Marshal(test.object)
+				require.NoError(t, err)
+			}
+
+			b, err := json.Marshal(admv1.AdmissionReview{
+				Request: &admv1.AdmissionRequest{
+					UID:       "id",
+					Kind:      metav1.GroupVersionKind{Group: "hub.traefik.io", Version: "v1alpha1", Kind: test.kind},
+					Name:      "name",
+					Operation: test.operation,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(b))
+
+			handler.ServeHTTP(rw, req)
+			require.Equal(t, http.StatusOK, rw.Code)
+
+			var gotAr admv1.AdmissionReview
+			err = json.NewDecoder(rw.Body).Decode(&gotAr)
+			require.NoError(t, err)
+
+			require.NotNil(t, gotAr.Response)
+			assert.Equal(t, "id", string(gotAr.Response.UID))
+
+			if len(test.wantCauses) == 0 {
+				assert.True(t, gotAr.Response.Allowed)
+				assert.Nil(t, gotAr.Response.Result)
+				return
+			}
+
+			assert.False(t, gotAr.Response.Allowed)
+			require.NotNil(t, gotAr.Response.Result)
+			assert.Equal(t, metav1.StatusReasonInvalid, gotAr.Response.Result.Reason)
+			require.NotNil(t, gotAr.Response.Result.Details)
+			assert.Equal(t, test.kind, gotAr.Response.Result.Details.Kind)
+			assert.Equal(t, test.wantCauses, gotAr.Response.Result.Details.Causes)
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_unsupportedResource(t *testing.T) {
+	handler := newHandler(t)
+
+	b, err := json.Marshal(admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			UID:       "id",
+			Kind:      metav1.GroupVersionKind{Group: "hub.traefik.io", Version: "v1alpha1", Kind: "Unknown"},
+			Operation: admv1.Create,
+		},
+	})
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(b))
+
+	handler.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var gotAr admv1.AdmissionReview
+	err = json.NewDecoder(rw.Body).Decode(&gotAr)
+	require.NoError(t, err)
+
+	assert.Equal(t, &admv1.AdmissionResponse{
+		UID:     "id",
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  "Failure",
+			Message: "unsupported resource hub.traefik.io/v1alpha1, Kind=Unknown",
+		},
+	}, gotAr.Response)
+}
+
+func newHandler(t *testing.T) *Handler {
+	t.Helper()
+
+	kubeClientSet := kubemock.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "books-svc", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+	)
+	hubClientSet := hubkubemock.NewSimpleClientset(
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", Labels: map[string]string{"area": "products"}},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/books",
+				Service: hubv1alpha1.APIService{
+					Name: "books-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+				},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores", Namespace: "default", Labels: map[string]string{"team": "stores"}},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/books",
+				Service: hubv1alpha1.APIService{
+					Name: "books-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+				},
+			},
+		},
+		&hubv1alpha1.APICollection{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores", Labels: map[string]string{"area": "stores"}},
+			Spec: hubv1alpha1.APICollectionSpec{
+				PathPrefix:  "/stores",
+				APISelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "stores"}},
+			},
+		},
+		&hubv1alpha1.APIAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "products"},
+			Spec: hubv1alpha1.APIAccessSpec{
+				APISelector: &metav1.LabelSelector{MatchLabels: map[string]string{"area": "products"}},
+			},
+		},
+		&hubv1alpha1.APIAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores"},
+			Spec: hubv1alpha1.APIAccessSpec{
+				APICollectionSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"area": "stores"}},
+			},
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// The fake clientset can't guess the resource of APIGateways, they must be created through the client.
+	_, err := hubClientSet.HubV1alpha1().APIGateways().Create(ctx, &hubv1alpha1.APIGateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway"},
+		Spec: hubv1alpha1.APIGatewaySpec{
+			APIAccesses: []string{"products", "stores", "unknown"},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 0)
+
+	handler := NewHandler(kubeInformer, hubInformer)
+
+	kubeInformer.Start(ctx.Done())
+	hubInformer.Start(ctx.Done())
+
+	for typ, ok := range kubeInformer.WaitForCacheSync(ctx.Done()) {
+		require.True(t, ok, "wait for %s cache sync", typ)
+	}
+	for typ, ok := range hubInformer.WaitForCacheSync(ctx.Done()) {
+		require.True(t, ok, "wait for %s cache sync", typ)
+	}
+
+	return handler
+}
@@ -18,12 +18,16 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package api
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
 // API is an API exposed within a portal.
@@ -33,6 +37,9 @@ type API struct {
 	Labels     map[string]string `json:"labels,omitempty"`
 	PathPrefix string            `json:"pathPrefix"`
 	Service    Service           `json:"service"`
+	Versions   []Version         `json:"versions,omitempty"`
+
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
 
 	Version string `json:"version"`
 
@@ -45,9 +52,27 @@ type Service struct {
 	Name string `json:"name" bson:"name"`
 	Port int    `json:"port" bson:"port"`
 
+	Weight   *int              `json:"weight,omitempty" bson:"weight,omitempty"`
+	Weighted []WeightedService `json:"weighted,omitempty" bson:"weighted,omitempty"`
+
 	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty" bson:"openApiSpec,omitempty"`
 }
 
+// WeightedService is a Kubernetes Service receiving a weighted share of the API traffic.
+type WeightedService struct {
+	Name   string `json:"name" bson:"name"`
+	Port   int    `json:"port" bson:"port"`
+	Weight int    `json:"weight" bson:"weight"`
+}
+
+// Version is a version of an API published side by side with its other versions.
+type Version struct {
+	Name        string      `json:"name" bson:"name"`
+	PathPrefix  string      `json:"pathPrefix" bson:"pathPrefix"`
+	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty" bson:"openApiSpec,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty" bson:"deprecated,omitempty"`
+}
+
 // OpenAPISpec is an OpenAPISpec. It can either be fetched from a URL, or Path/Port from the service
 // or directly in the Schema field.
 type OpenAPISpec struct {
@@ -55,6 +80,31 @@ type OpenAPISpec struct {
 
 	Path string `json:"path,omitempty" bson:"path,omitempty"`
 	Port int    `json:"port,omitempty" bson:"port,omitempty"`
+
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty" bson:"configMapRef,omitempty"`
+	Fallbacks    []OpenAPISpecSource      `json:"fallbacks,omitempty" bson:"fallbacks,omitempty"`
+}
+
+// OpenAPISpecSource is an alternative source from which an OpenAPI spec can be fetched.
+type OpenAPISpecSource struct {
+	URL string `json:"url,omitempty" bson:"url,omitempty"`
+
+	Path string `json:"path,omitempty" bson:"path,omitempty"`
+	Port int    `json:"port,omitempty" bson:"port,omitempty"`
+
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty" bson:"configMapRef,omitempty"`
+}
+
+// OpenAPISpecConfigMapRef references the key of a ConfigMap holding an OpenAPI spec.
+type OpenAPISpecConfigMapRef struct {
+	Name string `json:"name" bson:"name"`
+	Key  string `json:"key" bson:"key"`
+}
+
+// TermsOfService references a terms of service document consumers must accept before getting access to APIs.
+type TermsOfService struct {
+	URL     string `json:"url" bson:"url"`
+	Version string `json:"version" bson:"version"`
 }
 
 // Resource builds the v1alpha1 API resource.
@@ -76,10 +126,7 @@ func (a *API) Resource() (*hubv1alpha1.API, error) {
 				Port: hubv1alpha1.APIServiceBackendPort{
 					Number: int32(a.Service.Port),
 				},
-				OpenAPISpec: hubv1alpha1.OpenAPISpec{
-					URL:  a.Service.OpenAPISpec.URL,
-					Path: a.Service.OpenAPISpec.Path,
-				},
+				OpenAPISpec: a.Service.OpenAPISpec.resource(),
 			},
 		},
 		Status: hubv1alpha1.APIStatus{
@@ -88,12 +135,32 @@ func (a *API) Resource() (*hubv1alpha1.API, error) {
 		},
 	}
 
-	if a.Service.OpenAPISpec.Port != 0 {
-		api.Spec.Service.OpenAPISpec.Port = &hubv1alpha1.APIServiceBackendPort{
-			Number: int32(a.Service.OpenAPISpec.Port),
-		}
+	if a.Service.Weight != nil {
+		weight := *a.Service.Weight
+		api.Spec.Service.Weight = &weight
+	}
+
+	for _, weighted := range a.Service.Weighted {
+		api.Spec.Service.Weighted = append(api.Spec.Service.Weighted, hubv1alpha1.APIWeightedService{
+			Name: weighted.Name,
+			Port: hubv1alpha1.APIServiceBackendPort{
+				Number: int32(weighted.Port),
+			},
+			Weight: weighted.Weight,
+		})
+	}
+
+	for _, version := range a.Versions {
+		api.Spec.Versions = append(api.Spec.Versions, hubv1alpha1.APIVersion{
+			Name:        version.Name,
+			PathPrefix:  version.PathPrefix,
+			OpenAPISpec: version.OpenAPISpec.resource(),
+			Deprecated:  version.Deprecated,
+		})
 	}
 
+	api.Spec.TermsOfService = a.TermsOfService.resource()
+
 	apiHash, err := HashAPI(api)
 	if err != nil {
 		return nil, fmt.Errorf("compute API hash: %w", err)
@@ -105,17 +172,21 @@ func (a *API) Resource() (*hubv1alpha1.API, error) {
 }
 
 type apiHash struct {
-	PathPrefix string                 `json:"pathPrefix,omitempty"`
-	Service    hubv1alpha1.APIService `json:"service"`
-	Labels     sortedMap[string]      `json:"labels,omitempty"`
+	PathPrefix     string                      `json:"pathPrefix,omitempty"`
+	Service        hubv1alpha1.APIService      `json:"service"`
+	Versions       []hubv1alpha1.APIVersion    `json:"versions,omitempty"`
+	TermsOfService *hubv1alpha1.TermsOfService `json:"termsOfService,omitempty"`
+	Labels         sortedMap[string]           `json:"labels,omitempty"`
 }
 
 // HashAPI generates the hash of the API.
 func HashAPI(a *hubv1alpha1.API) (string, error) {
 	ah := apiHash{
-		PathPrefix: a.Spec.PathPrefix,
-		Service:    a.Spec.Service,
-		Labels:     newSortedMap(a.Labels),
+		PathPrefix:     a.Spec.PathPrefix,
+		Service:        a.Spec.Service,
+		Versions:       a.Spec.Versions,
+		TermsOfService: a.Spec.TermsOfService,
+		Labels:         newSortedMap(a.Labels),
 	}
 
 	hash, err := sum(ah)
@@ -125,3 +196,115 @@ func HashAPI(a *hubv1alpha1.API) (string, error) {
 
 	return base64.StdEncoding.EncodeToString(hash), nil
 }
+
+func (s OpenAPISpec) resource() hubv1alpha1.OpenAPISpec {
+	spec := hubv1alpha1.OpenAPISpec{
+		URL:          s.URL,
+		Path:         s.Path,
+		Port:         portResource(s.Port),
+		ConfigMapRef: s.ConfigMapRef.resource(),
+	}
+
+	for _, fallback := range s.Fallbacks {
+		spec.Fallbacks = append(spec.Fallbacks, hubv1alpha1.OpenAPISpecSource{
+			URL:          fallback.URL,
+			Path:         fallback.Path,
+			Port:         portResource(fallback.Port),
+			ConfigMapRef: fallback.ConfigMapRef.resource(),
+		})
+	}
+
+	return spec
+}
+
+func (r *OpenAPISpecConfigMapRef) resource() *hubv1alpha1.OpenAPISpecConfigMapRef {
+	if r == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.OpenAPISpecConfigMapRef{
+		Name: r.Name,
+		Key:  r.Key,
+	}
+}
+
+func portResource(port int) *hubv1alpha1.APIServiceBackendPort {
+	if port == 0 {
+		return nil
+	}
+
+	return &hubv1alpha1.APIServiceBackendPort{Number: int32(port)}
+}
+
+func (t *TermsOfService) resource() *hubv1alpha1.TermsOfService {
+	if t == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.TermsOfService{
+		URL:     t.URL,
+		Version: t.Version,
+	}
+}
+
+// OpenAPISpecURL returns the URL from which the OpenAPI spec of the API can be fetched from the given source.
+// When no URL is configured, the spec is served by the API service itself.
+func OpenAPISpecURL(a *hubv1alpha1.API, openAPISpec hubv1alpha1.OpenAPISpecSource) (*url.URL, error) {
+	svc := a.Spec.Service
+
+	switch {
+	case openAPISpec.URL != "":
+		u, err := url.Parse(openAPISpec.URL)
+		if err != nil {
+			return nil, fmt.Errorf("parse OpenAPI URL %q: %w", openAPISpec.URL, err)
+		}
+		return u, nil
+
+	case svc.Port.Number != 0 || openAPISpec.Port != nil && openAPISpec.Port.Number != 0:
+		protocol := openAPISpec.Protocol
+		if openAPISpec.Protocol == "" {
+			protocol = "http"
+		}
+
+		port := svc.Port.Number
+		if openAPISpec.Port != nil {
+			port = openAPISpec.Port.Number
+		}
+
+		namespace := a.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+
+		return &url.URL{
+			Scheme: protocol,
+			Host:   fmt.Sprint(svc.Name, ".", namespace, ":", port),
+			Path:   openAPISpec.Path,
+		}, nil
+
+	default:
+		return nil, errors.New("no spec endpoint specified")
+	}
+}
+
+// ReadOpenAPISpecConfigMap reads the OpenAPI spec of the API from the ConfigMap referenced by the given source.
+func ReadOpenAPISpecConfigMap(ctx context.Context, configMaps corev1client.ConfigMapsGetter, a *hubv1alpha1.API, ref *hubv1alpha1.OpenAPISpecConfigMapRef) ([]byte, error) {
+	namespace := a.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	cm, err := configMaps.ConfigMaps(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get ConfigMap %s/%s: %w", namespace, ref.Name, err)
+	}
+
+	if rawSpec, ok := cm.Data[ref.Key]; ok {
+		return []byte(rawSpec), nil
+	}
+	if rawSpec, ok := cm.BinaryData[ref.Key]; ok {
+		return rawSpec, nil
+	}
+
+	return nil, fmt.Errorf("key %q not found in ConfigMap %s/%s", ref.Key, namespace, ref.Name)
+}
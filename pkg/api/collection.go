@@ -35,6 +35,8 @@ type Collection struct {
 	PathPrefix  string               `json:"pathPrefix,omitempty"`
 	APISelector metav1.LabelSelector `json:"apiSelector"`
 
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
+
 	Version string `json:"version"`
 
 	CreatedAt time.Time `json:"createdAt"`
@@ -53,8 +55,9 @@ func (c *Collection) Resource() (*hubv1alpha1.APICollection, error) {
 			Labels: c.Labels,
 		},
 		Spec: hubv1alpha1.APICollectionSpec{
-			PathPrefix:  c.PathPrefix,
-			APISelector: c.APISelector,
+			PathPrefix:     c.PathPrefix,
+			APISelector:    c.APISelector,
+			TermsOfService: c.TermsOfService.resource(),
 		},
 		Status: hubv1alpha1.APICollectionStatus{
 			Version:  c.Version,
@@ -80,17 +83,19 @@ func (c *Collection) Resource() (*hubv1alpha1.APICollection, error) {
 }
 
 type collectionHash struct {
-	PathPrefix  string            `json:"pathPrefix,omitempty"`
-	APISelector string            `json:"apiSelector"`
-	Labels      sortedMap[string] `json:"labels,omitempty"`
+	PathPrefix     string                      `json:"pathPrefix,omitempty"`
+	APISelector    string                      `json:"apiSelector"`
+	TermsOfService *hubv1alpha1.TermsOfService `json:"termsOfService,omitempty"`
+	Labels         sortedMap[string]           `json:"labels,omitempty"`
 }
 
 // HashCollection generates the hash of the APICollection.
 func HashCollection(c *hubv1alpha1.APICollection) (string, error) {
 	ch := collectionHash{
-		PathPrefix:  c.Spec.PathPrefix,
-		APISelector: c.Spec.APISelector.String(),
-		Labels:      newSortedMap(c.Labels),
+		PathPrefix:     c.Spec.PathPrefix,
+		APISelector:    c.Spec.APISelector.String(),
+		TermsOfService: c.Spec.TermsOfService,
+		Labels:         newSortedMap(c.Labels),
 	}
 
 	b, err := json.Marshal(ch)
@@ -0,0 +1,175 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubv1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha2"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Handler is an HTTP handler that can be used as a Kubernetes CRD conversion webhook.
+// It converts APIs, APICollections and APIGateways between the hub.traefik.io versions.
+type Handler struct{}
+
+// NewHandler returns a new Handler.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// ServeHTTP implements http.Handler.
+func (h Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var review apiextv1.ConversionReview
+	if err := json.NewDecoder(req.Body).Decode(&review); err != nil {
+		log.Error().Err(err).Msg("Unable to decode conversion request")
+		http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if review.Request == nil {
+		log.Error().Msg("No request found")
+		http.Error(rw, "No request found", http.StatusUnprocessableEntity)
+		return
+	}
+
+	l := log.Logger.With().
+		Str("uid", string(review.Request.UID)).
+		Str("desired_api_version", review.Request.DesiredAPIVersion).
+		Logger()
+	ctx := l.WithContext(req.Context())
+
+	review.Response = &apiextv1.ConversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+
+	for _, object := range review.Request.Objects {
+		converted, err := convert(object.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Unable to convert object")
+
+			review.Response.ConvertedObjects = nil
+			review.Response.Result = metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			}
+			break
+		}
+
+		review.Response.ConvertedObjects = append(review.Response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	review.Request = nil
+
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to encode conversion response")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func convert(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("unmarshal type meta: %w", err)
+	}
+
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	var (
+		converted interface{}
+		err       error
+	)
+	switch {
+	case typeMeta.APIVersion == hubv1alpha1.SchemeGroupVersion.String() && desiredAPIVersion == hubv1alpha2.SchemeGroupVersion.String():
+		converted, err = toV1alpha2(typeMeta.Kind, raw)
+	case typeMeta.APIVersion == hubv1alpha2.SchemeGroupVersion.String() && desiredAPIVersion == hubv1alpha1.SchemeGroupVersion.String():
+		converted, err = toV1alpha1(typeMeta.Kind, raw)
+	default:
+		return nil, fmt.Errorf("unsupported conversion from %q to %q", typeMeta.APIVersion, desiredAPIVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(converted)
+}
+
+func toV1alpha2(kind string, raw []byte) (interface{}, error) {
+	switch kind {
+	case "API":
+		var a hubv1alpha1.API
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("unmarshal API: %w", err)
+		}
+
+		return hubv1alpha2.ConvertAPIFromV1alpha1(&a), nil
+	case "APICollection":
+		var collection hubv1alpha1.APICollection
+		if err := json.Unmarshal(raw, &collection); err != nil {
+			return nil, fmt.Errorf("unmarshal APICollection: %w", err)
+		}
+
+		return hubv1alpha2.ConvertAPICollectionFromV1alpha1(&collection), nil
+	case "APIGateway":
+		var gateway hubv1alpha1.APIGateway
+		if err := json.Unmarshal(raw, &gateway); err != nil {
+			return nil, fmt.Errorf("unmarshal APIGateway: %w", err)
+		}
+
+		return hubv1alpha2.ConvertAPIGatewayFromV1alpha1(&gateway), nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+}
+
+func toV1alpha1(kind string, raw []byte) (interface{}, error) {
+	switch kind {
+	case "API":
+		var a hubv1alpha2.API
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("unmarshal API: %w", err)
+		}
+
+		return hubv1alpha2.ConvertAPIToV1alpha1(&a), nil
+	case "APICollection":
+		var collection hubv1alpha2.APICollection
+		if err := json.Unmarshal(raw, &collection); err != nil {
+			return nil, fmt.Errorf("unmarshal APICollection: %w", err)
+		}
+
+		return hubv1alpha2.ConvertAPICollectionToV1alpha1(&collection), nil
+	case "APIGateway":
+		var gateway hubv1alpha2.APIGateway
+		if err := json.Unmarshal(raw, &gateway); err != nil {
+			return nil, fmt.Errorf("unmarshal APIGateway: %w", err)
+		}
+
+		return hubv1alpha2.ConvertAPIGatewayToV1alpha1(&gateway), nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package conversion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc              string
+		desiredAPIVersion string
+		objects           []string
+		wantStatus        string
+		wantMessage       string
+		wantObjects       []string
+	}{
+		{
+			desc:              "API from v1alpha1 to v1alpha2",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"API","metadata":{"name":"api","namespace":"ns"},"spec":{"pathPrefix":"/api","service":{"name":"svc","port":{"number":80},"openApiSpec":{"path":"/spec.json"}}}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"API","metadata":{"name":"api","namespace":"ns","creationTimestamp":null},"spec":{"pathPrefix":"/api","service":{"name":"svc","port":{"name":"","number":80}},"openApiSpec":{"path":"/spec.json"}},"status":{"syncedAt":null}}`,
+			},
+		},
+		{
+			desc:              "API from v1alpha2 to v1alpha1",
+			desiredAPIVersion: "hub.traefik.io/v1alpha1",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"API","metadata":{"name":"api","namespace":"ns"},"spec":{"pathPrefix":"/api","service":{"name":"svc","port":{"number":80}},"openApiSpec":{"url":"https://example.com/spec.json","fallbacks":[{"configMapRef":{"name":"spec","key":"spec.json"}}]}}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"API","metadata":{"name":"api","namespace":"ns","creationTimestamp":null},"spec":{"pathPrefix":"/api","service":{"name":"svc","port":{"name":"","number":80},"openApiSpec":{"url":"https://example.com/spec.json","fallbacks":[{"configMapRef":{"name":"spec","key":"spec.json"}}]}}},"status":{"syncedAt":null}}`,
+			},
+		},
+		{
+			desc:              "APICollection and APIGateway from v1alpha1 to v1alpha2",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"APICollection","metadata":{"name":"collection"},"spec":{"pathPrefix":"/collection","apiSelector":{"matchLabels":{"area":"stores"}},"termsOfService":{"url":"https://example.com/tos","version":"1"}}}`,
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"APIGateway","metadata":{"name":"gateway"},"spec":{"apiAccesses":["products"]}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APICollection","metadata":{"name":"collection","creationTimestamp":null},"spec":{"pathPrefix":"/collection","apiSelector":{"matchLabels":{"area":"stores"}},"termsOfService":{"url":"https://example.com/tos","version":"1"}},"status":{"syncedAt":null}}`,
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APIGateway","metadata":{"name":"gateway","creationTimestamp":null},"spec":{"apiAccesses":["products"]},"status":{"hubDomain":"","urls":"","syncedAt":null}}`,
+			},
+		},
+		{
+			desc:              "object already in the desired version",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APIGateway","metadata":{"name":"gateway"}}`,
+			},
+			wantStatus: metav1.StatusSuccess,
+			wantObjects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha2","kind":"APIGateway","metadata":{"name":"gateway"}}`,
+			},
+		},
+		{
+			desc:              "unsupported kind",
+			desiredAPIVersion: "hub.traefik.io/v1alpha2",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"APIGateway","metadata":{"name":"gateway"}}`,
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"APIPortal","metadata":{"name":"portal"}}`,
+			},
+			wantStatus:  metav1.StatusFailure,
+			wantMessage: `unsupported kind "APIPortal"`,
+		},
+		{
+			desc:              "unsupported version",
+			desiredAPIVersion: "hub.traefik.io/v1beta1",
+			objects: []string{
+				`{"apiVersion":"hub.traefik.io/v1alpha1","kind":"API","metadata":{"name":"api"}}`,
+			},
+			wantStatus:  metav1.StatusFailure,
+			wantMessage: `unsupported conversion from "hub.traefik.io/v1alpha1" to "hub.traefik.io/v1beta1"`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			review := apiextv1.ConversionReview{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ConversionReview",
+					APIVersion: "apiextensions.k8s.io/v1",
+				},
+				Request: &apiextv1.ConversionRequest{
+					UID:               "id",
+					DesiredAPIVersion: test.desiredAPIVersion,
+				},
+			}
+			for _, object := range test.objects {
+				review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: []byte(object)})
+			}
+
+			b, err := json.Marshal(review)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+
+			NewHandler().ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var got apiextv1.ConversionReview
+			err = json.NewDecoder(rec.Body).Decode(&got)
+			require.NoError(t, err)
+
+			require.NotNil(t, got.Response)
+			assert.Nil(t, got.Request)
+			assert.Equal(t, review.Request.UID, got.Response.UID)
+			assert.Equal(t, test.wantStatus, got.Response.Result.Status)
+			assert.Equal(t, test.wantMessage, got.Response.Result.Message)
+
+			require.Len(t, got.Response.ConvertedObjects, len(test.wantObjects))
+			for i, wantObject := range test.wantObjects {
+				assert.JSONEq(t, wantObject, string(got.Response.ConvertedObjects[i].Raw))
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_noRequest(t *testing.T) {
+	b, err := json.Marshal(apiextv1.ConversionReview{})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+
+	NewHandler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"sort"
+	"strings"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// consumers are the groups and users granted access to an API or an APICollection by the APIAccesses of a gateway.
+// A nil consumers doesn't restrict the access.
+type consumers struct {
+	Groups []string
+	Users  []string
+}
+
+// add grants access to the groups and users of the given APIAccess.
+func (c *consumers) add(access *hubv1alpha1.APIAccess) {
+	c.Groups = mergeSorted(c.Groups, access.Spec.Groups)
+	c.Users = mergeSorted(c.Users, access.Spec.Users)
+}
+
+// allows returns whether the user with the given email, member of the given groups, has access.
+func (c *consumers) allows(email string, groups []string) bool {
+	if c == nil {
+		return true
+	}
+
+	if email != "" {
+		for _, user := range c.Users {
+			if strings.EqualFold(user, email) {
+				return true
+			}
+		}
+	}
+
+	return intersects(c.Groups, groups)
+}
+
+// allowsUser returns whether the given user has access.
+func (c *consumers) allowsUser(u user) bool {
+	return c.allows(u.email, u.groups)
+}
+
+// restricted returns whether the access to some of the APIs or APICollections of the gateway is restricted.
+func (g *gateway) restricted() bool {
+	for _, c := range g.Consumers {
+		if c != nil {
+			return true
+		}
+	}
+	for _, c := range g.Collections {
+		if c.Consumers != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeSorted returns the sorted union of a and b.
+func mergeSorted(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+
+	set := make(map[string]struct{}, len(a)+len(b))
+	for _, s := range a {
+		set[s] = struct{}{}
+	}
+	for _, s := range b {
+		set[s] = struct{}{}
+	}
+
+	res := make([]string, 0, len(set))
+	for s := range set {
+		res = append(res, s)
+	}
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumers_allows(t *testing.T) {
+	c := &consumers{
+		Groups: []string{"consumer", "supplier"},
+		Users:  []string{"jane.doe@example.com"},
+	}
+
+	tests := []struct {
+		desc      string
+		consumers *consumers
+		email     string
+		groups    []string
+		want      bool
+	}{
+		{
+			desc:  "unrestricted",
+			email: "john.doe@example.com",
+			want:  true,
+		},
+		{
+			desc:      "member of a group",
+			consumers: c,
+			email:     "john.doe@example.com",
+			groups:    []string{"admin", "supplier"},
+			want:      true,
+		},
+		{
+			desc:      "granted user",
+			consumers: c,
+			email:     "Jane.Doe@example.com",
+			want:      true,
+		},
+		{
+			desc:      "not a member of any group",
+			consumers: c,
+			email:     "john.doe@example.com",
+			groups:    []string{"admin"},
+		},
+		{
+			desc:      "anonymous user",
+			consumers: &consumers{Users: []string{""}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.consumers.allows(test.email, test.groups))
+		})
+	}
+}
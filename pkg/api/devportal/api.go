@@ -20,6 +20,7 @@ package devportal
 import (
 	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -27,47 +28,147 @@ import (
 	"net/url"
 	"path"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi/v5"
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/diff"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/mock"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
+// specCacheTTL is the duration during which a fetched OpenAPI spec is served from the cache.
+const specCacheTTL = time.Minute
+
 // PortalAPI is a handler that exposes APIPortal information.
 type PortalAPI struct {
 	router     chi.Router
 	httpClient *http.Client
+	ruleset    lint.Ruleset
+	now        func() time.Time
 
-	portal       *portal
+	portal *portal
+	// listAPIsResp is the precomputed list APIs response. It is nil when the access to the APIs is restricted, in
+	// which case the response is built for each user.
 	listAPIsResp []byte
+
+	// specs caches the raw OpenAPI specs fetched for this portal, indexed by URL.
+	specsMu sync.Mutex
+	specs   map[string]cachedSpec
+
+	// history keeps the previous revisions of the fetched OpenAPI specs to compute diffs.
+	history *specHistory
+	// specLocations keeps the paths at which the OpenAPI specs, whose location isn't configured, were discovered.
+	specLocations *specLocations
+	// sdkGenerator generates client SDKs. SDKs can't be downloaded when nil.
+	sdkGenerator *SDKGenerator
+	// configMaps gives access to the ConfigMaps holding OpenAPI specs. Specs can't be read from ConfigMaps when nil.
+	configMaps corev1client.ConfigMapsGetter
+	// usage provides the usage of the APIs made by the portal users. Usage metrics can't be retrieved when nil.
+	usage UsageSource
+	// examples provides the request/response examples captured by the gateway. Examples can't be retrieved when nil.
+	examples ExampleSource
+	// terms records the acceptance of the terms of service of the APIs. Acceptances can't be recorded when nil.
+	terms     TermsRecorder
+	changelog *Changelog
+	// groups holds the groups pushed by the identity provider. Only the groups header is used when nil.
+	groups *GroupDirectory
+	logger zerolog.Logger
+}
+
+type cachedSpec struct {
+	raw       []byte
+	fetchedAt time.Time
 }
 
-// NewPortalAPI creates a new PortalAPI handler.
-func NewPortalAPI(portal *portal) (*PortalAPI, error) {
+// newSpecClient returns the client fetching the OpenAPI specs of the APIs using the given transport, or a default
+// pooled transport when nil.
+func newSpecClient(transport http.RoundTripper) *http.Client {
 	client := retryablehttp.NewClient()
 	client.RetryMax = 4
 	client.Logger = logwrapper.NewRetryableHTTPWrapper(log.Logger.With().
 		Str("component", "portal_api").
 		Logger())
+	if transport != nil {
+		client.HTTPClient.Transport = transport
+	}
 
-	listAPIsResp, err := json.Marshal(buildListResp(portal))
-	if err != nil {
-		return nil, fmt.Errorf("marshal list APIs response: %w", err)
+	return client.StandardClient()
+}
+
+// NewPortalAPI creates a new PortalAPI handler. The OpenAPI specs of the APIs are linted using the given ruleset.
+func NewPortalAPI(portal *portal, ruleset lint.Ruleset) (*PortalAPI, error) {
+	var listAPIsResp []byte
+	if !portal.Gateway.restricted() {
+		var err error
+		listAPIsResp, err = json.Marshal(buildListResp(portal, nil))
+		if err != nil {
+			return nil, fmt.Errorf("marshal list APIs response: %w", err)
+		}
 	}
 
 	p := &PortalAPI{
-		router:       chi.NewRouter(),
-		httpClient:   client.StandardClient(),
-		portal:       portal,
-		listAPIsResp: listAPIsResp,
+		router:        chi.NewRouter(),
+		httpClient:    newSpecClient(nil),
+		ruleset:       ruleset,
+		now:           time.Now,
+		portal:        portal,
+		listAPIsResp:  listAPIsResp,
+		specs:         make(map[string]cachedSpec),
+		history:       newSpecHistory(nil),
+		specLocations: newSpecLocations(),
+		changelog:     newChangelog(nil),
+		logger:        log.Logger,
 	}
 
 	p.router.Get("/apis", p.handleListAPIs)
-	p.router.Get("/apis/{api}", p.handleGetAPISpec)
-	p.router.Get("/collections/{collection}/apis/{api}", p.handleGetCollectionAPISpec)
+	p.router.Get("/changelog", p.handleChangelog)
+	p.router.Get("/apis/{api}", p.handleAPI(p.serveAPISpec))
+	p.router.Get("/apis/{api}/lint", p.handleAPI(p.serveAPILint))
+	p.router.Get("/apis/{api}/diff", p.handleAPI(p.serveAPIDiff))
+	p.router.Get("/apis/{api}/versions/{version}", p.handleAPI(p.serveAPISpec))
+	p.router.Get("/apis/{api}/versions/{version}/lint", p.handleAPI(p.serveAPILint))
+	p.router.Get("/apis/{api}/versions/{version}/diff", p.handleAPI(p.serveAPIDiff))
+	p.router.Get("/collections/{collection}/apis/{api}", p.handleCollectionAPI(p.serveAPISpec))
+	p.router.Get("/collections/{collection}/apis/{api}/lint", p.handleCollectionAPI(p.serveAPILint))
+	p.router.Get("/collections/{collection}/apis/{api}/diff", p.handleCollectionAPI(p.serveAPIDiff))
+	p.router.Get("/collections/{collection}/apis/{api}/versions/{version}", p.handleCollectionAPI(p.serveAPISpec))
+	p.router.Get("/collections/{collection}/apis/{api}/versions/{version}/lint", p.handleCollectionAPI(p.serveAPILint))
+	p.router.Get("/collections/{collection}/apis/{api}/versions/{version}/diff", p.handleCollectionAPI(p.serveAPIDiff))
+	p.router.Get("/apis/{api}/sdk", p.handleAPI(p.serveAPISDK))
+	p.router.Get("/apis/{api}/versions/{version}/sdk", p.handleAPI(p.serveAPISDK))
+	p.router.Get("/collections/{collection}/apis/{api}/sdk", p.handleCollectionAPI(p.serveAPISDK))
+	p.router.Get("/collections/{collection}/apis/{api}/versions/{version}/sdk", p.handleCollectionAPI(p.serveAPISDK))
+	p.router.Get("/apis/{api}/postman", p.handleAPI(p.serveAPIPostman))
+	p.router.Get("/apis/{api}/versions/{version}/postman", p.handleAPI(p.serveAPIPostman))
+	p.router.Get("/collections/{collection}/apis/{api}/postman", p.handleCollectionAPI(p.serveAPIPostman))
+	p.router.Get("/collections/{collection}/apis/{api}/versions/{version}/postman", p.handleCollectionAPI(p.serveAPIPostman))
+	p.router.Get("/apis/{api}/metrics", p.handleAPI(p.serveAPIUsage))
+	p.router.Get("/collections/{collection}/apis/{api}/metrics", p.handleCollectionAPI(p.serveAPIUsage))
+	p.router.Get("/apis/{api}/examples", p.handleAPI(p.serveAPIExamples))
+	p.router.Get("/apis/{api}/versions/{version}/examples", p.handleAPI(p.serveAPIExamples))
+	p.router.Get("/collections/{collection}/apis/{api}/examples", p.handleCollectionAPI(p.serveAPIExamples))
+	p.router.Get("/collections/{collection}/apis/{api}/versions/{version}/examples", p.handleCollectionAPI(p.serveAPIExamples))
+	p.router.Get("/apis/{api}/quota", p.handleAPI(p.serveAPIQuota))
+	p.router.Get("/collections/{collection}/apis/{api}/quota", p.handleCollectionAPI(p.serveAPIQuota))
+	p.router.Get("/apis/{api}/terms", p.handleAPI(p.serveAPITerms))
+	p.router.Post("/apis/{api}/terms/accept", p.handleAPI(p.serveAPIAcceptTerms))
+	p.router.Get("/collections/{collection}/apis/{api}/terms", p.handleCollectionAPI(p.serveAPITerms))
+	p.router.Post("/collections/{collection}/apis/{api}/terms/accept", p.handleCollectionAPI(p.serveAPIAcceptTerms))
+
+	// Mock responses are served for any method at the path of the operations, relatively to the mock path prefix.
+	p.router.Handle("/apis/{api}/mock/*", p.handleAPI(p.serveAPIMock))
+	p.router.Handle("/apis/{api}/versions/{version}/mock/*", p.handleAPI(p.serveAPIMock))
+	p.router.Handle("/collections/{collection}/apis/{api}/mock/*", p.handleCollectionAPI(p.serveAPIMock))
+	p.router.Handle("/collections/{collection}/apis/{api}/versions/{version}/mock/*", p.handleCollectionAPI(p.serveAPIMock))
 
 	return p, nil
 }
@@ -77,65 +178,447 @@ func (p *PortalAPI) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	p.router.ServeHTTP(rw, req)
 }
 
-func (p *PortalAPI) handleListAPIs(rw http.ResponseWriter, _ *http.Request) {
+func (p *PortalAPI) handleListAPIs(rw http.ResponseWriter, r *http.Request) {
+	resp := p.listAPIsResp
+	if resp == nil {
+		var err error
+		u := p.user(r)
+		resp, err = json.Marshal(buildListResp(p.portal, &u))
+		if err != nil {
+			p.logger.Error().Err(err).
+				Str("portal_name", p.portal.Name).
+				Msg("Unable to marshal list APIs response")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
 	rw.Header().Set("Content-Type", "application/json")
 	rw.WriteHeader(http.StatusOK)
 
-	if _, err := rw.Write(p.listAPIsResp); err != nil {
-		log.Error().Err(err).
+	if _, err := rw.Write(resp); err != nil {
+		p.logger.Error().Err(err).
 			Str("portal_name", p.portal.Name).
 			Msg("Write list APIs response")
 	}
 }
 
-func (p *PortalAPI) handleGetAPISpec(rw http.ResponseWriter, r *http.Request) {
-	apiNameNamespace := chi.URLParam(r, "api")
+// apiServeFunc serves a response for the given API version of the given gateway. The collection and version may be nil.
+type apiServeFunc func(rw http.ResponseWriter, r *http.Request, g *gateway, c *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion)
+
+// handleChangelog serves the changelog of the portal, as JSON or as an RSS or Atom feed depending on the "format"
+// query parameter.
+func (p *PortalAPI) handleChangelog(rw http.ResponseWriter, r *http.Request) {
+	logger := p.logger.With().Str("portal_name", p.portal.Name).Logger()
+
+	entries := p.changelog.Entries(p.portal)
+
+	var (
+		contentType string
+		resp        []byte
+		err         error
+	)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		contentType = "application/json"
+		resp, err = json.Marshal(changelogResp{Entries: entries})
+	case "rss":
+		contentType = "application/rss+xml"
+		resp, err = xml.Marshal(newRSSFeed(portalTitle(*p.portal), portalURL(p.portal), entries))
+	case "atom":
+		contentType = "application/atom+xml"
+		resp, err = xml.Marshal(newAtomFeed(portalTitle(*p.portal), portalURL(p.portal), entries))
+	default:
+		logger.Debug().Str("format", format).Msg("Unsupported changelog format")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to marshal changelog")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-	logger := log.With().
-		Str("portal_name", p.portal.Name).
-		Str("api_name", apiNameNamespace).
-		Logger()
+	if contentType != "application/json" {
+		resp = append([]byte(xml.Header), resp...)
+	}
 
-	a, ok := p.portal.Gateway.APIs[apiNameNamespace]
-	if !ok {
-		logger.Debug().Msg("API not found")
+	rw.Header().Set("Content-Type", contentType)
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err = rw.Write(resp); err != nil {
+		logger.Error().Err(err).Msg("Write changelog response")
+	}
+}
+
+// portalURL returns the URL of the given portal. As soon as a CustomDomain is provided on the Portal, the UI is
+// no longer accessible through the HubDomain.
+func portalURL(p *portal) string {
+	if len(p.Status.CustomDomains) > 0 {
+		return "https://" + p.Status.CustomDomains[0] + "/"
+	}
+
+	return "https://" + p.Status.HubDomain + "/"
+}
+
+func (p *PortalAPI) handleAPI(serve apiServeFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		apiNameNamespace := chi.URLParam(r, "api")
+		versionName := chi.URLParam(r, "version")
+
+		logger := p.logger.With().
+			Str("portal_name", p.portal.Name).
+			Str("api_name", apiNameNamespace).
+			Str("api_version", versionName).
+			Logger()
+
+		a, ok := p.portal.Gateway.APIs[apiNameNamespace]
+		if !ok {
+			logger.Debug().Msg("API not found")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if !p.portal.Gateway.Consumers[apiNameNamespace].allowsUser(p.user(r)) {
+			logger.Debug().Msg("User not allowed to access the API")
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		v, ok := findVersion(&a, versionName)
+		if !ok {
+			logger.Debug().Msg("API version not found")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		serve(rw, r.WithContext(logger.WithContext(r.Context())), &p.portal.Gateway, nil, &a, v)
+	}
+}
+
+func (p *PortalAPI) handleCollectionAPI(serve apiServeFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		collectionName := chi.URLParam(r, "collection")
+		apiNameNamespace := chi.URLParam(r, "api")
+		versionName := chi.URLParam(r, "version")
+
+		logger := p.logger.With().
+			Str("portal_name", p.portal.Name).
+			Str("collection_name", collectionName).
+			Str("api_name", apiNameNamespace).
+			Str("api_version", versionName).
+			Logger()
+
+		c, ok := p.portal.Gateway.Collections[collectionName]
+		if !ok {
+			logger.Debug().Msg("APICollection not found")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if !c.Consumers.allowsUser(p.user(r)) {
+			logger.Debug().Msg("User not allowed to access the APICollection")
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		a, ok := c.APIs[apiNameNamespace]
+		if !ok {
+			logger.Debug().Msg("API not found")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		v, ok := findVersion(&a, versionName)
+		if !ok {
+			logger.Debug().Msg("API version not found")
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		serve(rw, r.WithContext(logger.WithContext(r.Context())), &p.portal.Gateway, &c, &a, v)
+	}
+}
+
+// findVersion finds the version of the given API with the given name. It returns a nil version if no name is given.
+func findVersion(a *hubv1alpha1.API, name string) (*hubv1alpha1.APIVersion, bool) {
+	if name == "" {
+		return nil, true
+	}
+
+	for i := range a.Spec.Versions {
+		if a.Spec.Versions[i].Name == name {
+			return &a.Spec.Versions[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (p *PortalAPI) serveAPISpec(rw http.ResponseWriter, r *http.Request, g *gateway, c *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	spec, err := p.getOpenAPISpec(ctx, a, resolveOpenAPISpec(a, v))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	if err = rewriteOpenAPISpec(spec, g, c, a, v); err != nil {
+		logger.Error().Err(err).Msg("Unable to adapt OpenAPI spec server and security configurations")
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	addRateLimitExtension(spec, g, a.Name+"@"+a.Namespace, p.user(r).groups)
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(spec); err != nil {
+		logger.Error().Msg("Unable to serve OpenAPI spec")
+	}
+}
+
+// rewriteOpenAPISpec rewrites the given spec of the given API version for it to be consumed through the given gateway.
+func rewriteOpenAPISpec(spec *openapi3.T, g *gateway, c *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) error {
+	if err := overrideServersAndSecurity(spec, gatewayDomains(g), apiPathPrefix(c, a, v)); err != nil {
+		return err
+	}
+	setSecurity(spec, g.Spec.CredentialLocations)
+
+	if v != nil && v.Deprecated {
+		deprecateOperations(spec)
+	}
+
+	return nil
+}
+
+// apiPathPrefix returns the path prefix under which the given API version is exposed. The collection and version
+// may be nil.
+func apiPathPrefix(c *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) string {
+	var pathPrefix string
+	if c != nil {
+		pathPrefix = c.Spec.PathPrefix
+	}
+	pathPrefix = path.Join(pathPrefix, a.Spec.PathPrefix)
+	if v != nil {
+		pathPrefix = path.Join(pathPrefix, v.PathPrefix)
+	}
+
+	return pathPrefix
+}
+
+// gatewayDomains returns the domains under which the APIs of the given gateway are exposed. As soon as a CustomDomain
+// is provided on the Gateway, the APIs are no longer accessible through the HubDomain.
+func gatewayDomains(g *gateway) []string {
+	if len(g.Status.CustomDomains) > 0 {
+		return g.Status.CustomDomains
+	}
+
+	return []string{g.Status.HubDomain}
+}
+
+// serveAPISDK streams the zip archive of a client SDK generated, in the language given by the "lang" query parameter,
+// from the OpenAPI spec of the given API version as served by the portal.
+func (p *PortalAPI) serveAPISDK(rw http.ResponseWriter, r *http.Request, g *gateway, c *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	if p.sdkGenerator == nil {
 		rw.WriteHeader(http.StatusNotFound)
 		return
 	}
 
-	p.serveAPISpec(logger.WithContext(r.Context()), rw, &p.portal.Gateway, nil, &a)
+	lang := r.URL.Query().Get("lang")
+	if _, ok := sdkGeneratorName(lang); !ok {
+		logger.Debug().Str("lang", lang).Msg("Unsupported SDK language")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := http.NewResponseController(rw).SetWriteDeadline(time.Now().Add(sdkWriteTimeout)); err != nil {
+		logger.Debug().Err(err).Msg("Unable to extend the write deadline of the SDK download")
+	}
+
+	spec, err := p.getOpenAPISpec(ctx, a, resolveOpenAPISpec(a, v))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	if err = rewriteOpenAPISpec(spec, g, c, a, v); err != nil {
+		logger.Error().Err(err).Msg("Unable to adapt OpenAPI spec server and security configurations")
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	sdk, err := p.sdkGenerator.Generate(ctx, lang, spec)
+	if err != nil {
+		logger.Error().Err(err).Str("lang", lang).Msg("Unable to generate SDK")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+	defer func() { _ = sdk.Close() }()
+
+	filename := fmt.Sprintf("%s-%s-sdk.zip", a.Name, lang)
+	if v != nil {
+		filename = fmt.Sprintf("%s-%s-%s-sdk.zip", a.Name, v.Name, lang)
+	}
+
+	rw.Header().Set("Content-Type", "application/zip")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err = io.Copy(rw, sdk); err != nil {
+		logger.Error().Err(err).Msg("Unable to stream SDK")
+	}
 }
 
-func (p *PortalAPI) handleGetCollectionAPISpec(rw http.ResponseWriter, r *http.Request) {
-	collectionName := chi.URLParam(r, "collection")
-	apiNameNamespace := chi.URLParam(r, "api")
+// serveAPIPostman serves a Postman collection built from the OpenAPI spec of the given API version as served by the
+// portal.
+func (p *PortalAPI) serveAPIPostman(rw http.ResponseWriter, r *http.Request, g *gateway, c *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	spec, err := p.getOpenAPISpec(ctx, a, resolveOpenAPISpec(a, v))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
 
-	logger := log.With().
-		Str("portal_name", p.portal.Name).
-		Str("collection_name", collectionName).
-		Str("api_name", apiNameNamespace).
-		Logger()
+		return
+	}
 
-	c, ok := p.portal.Gateway.Collections[collectionName]
-	if !ok {
-		logger.Debug().Msg("APICollection not found")
+	if err = rewriteOpenAPISpec(spec, g, c, a, v); err != nil {
+		logger.Error().Err(err).Msg("Unable to adapt OpenAPI spec server and security configurations")
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	// Specs without servers are served by the gateway under the path prefix of the API.
+	baseURL := "https://" + gatewayDomains(g)[0] + apiPathPrefix(c, a, v)
+	if len(spec.Servers) > 0 && spec.Servers[0].URL != "" {
+		baseURL = spec.Servers[0].URL
+	}
+
+	name := a.Name
+	filename := fmt.Sprintf("%s.postman_collection.json", a.Name)
+	if v != nil {
+		name = a.Name + " " + v.Name
+		filename = fmt.Sprintf("%s-%s.postman_collection.json", a.Name, v.Name)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(newPostmanCollection(name, spec, baseURL)); err != nil {
+		logger.Error().Err(err).Msg("Unable to serve Postman collection")
+	}
+}
+
+// serveAPIExamples serves the request/response examples captured by the gateway for the operations of the given API
+// version, when the gateway captures examples.
+func (p *PortalAPI) serveAPIExamples(rw http.ResponseWriter, r *http.Request, g *gateway, _ *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	if p.examples == nil || g.Spec.ExampleCapture == nil {
 		rw.WriteHeader(http.StatusNotFound)
 		return
 	}
-	a, ok := c.APIs[apiNameNamespace]
-	if !ok {
-		logger.Debug().Msg("API not found")
+
+	query := api.ExampleQuery{
+		Portal: p.portal.Name,
+		API:    a.Name + "@" + a.Namespace,
+	}
+	if v != nil {
+		query.Version = v.Name
+	}
+
+	examples, err := p.examples.ListAPIExamples(ctx, query)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to list API examples")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(buildExamplesResp(examples, g.Spec.ExampleCapture)); err != nil {
+		logger.Error().Err(err).Msg("Write API examples response")
+	}
+}
+
+// serveAPIUsage serves the usage of the given API made with the tokens of the user calling the portal, over the
+// period given by the "period" query parameter.
+func (p *PortalAPI) serveAPIUsage(rw http.ResponseWriter, r *http.Request, _ *gateway, _ *collection, a *hubv1alpha1.API, _ *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	if p.usage == nil {
 		rw.WriteHeader(http.StatusNotFound)
 		return
 	}
 
-	p.serveAPISpec(logger.WithContext(r.Context()), rw, &p.portal.Gateway, &c, &a)
+	email := p.user(r).email
+	if email == "" {
+		logger.Debug().Str("header", p.portal.Gateway.identityHeaders().Email).Msg("Missing user email")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	period, err := parseUsagePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		logger.Debug().Err(err).Msg("Invalid usage period")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	usage, err := p.usage.GetAPIUsage(ctx, api.UsageQuery{
+		Portal: p.portal.Name,
+		API:    a.Name + "@" + a.Namespace,
+		Email:  email,
+		Period: period,
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get API usage")
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	resp, err := json.Marshal(usage)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to marshal API usage")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err = rw.Write(resp); err != nil {
+		logger.Error().Err(err).Msg("Write API usage response")
+	}
 }
 
-func (p *PortalAPI) serveAPISpec(ctx context.Context, rw http.ResponseWriter, g *gateway, c *collection, a *hubv1alpha1.API) {
+// serveAPILint serves the results of the linting of the OpenAPI spec of the given API version, as published by the API.
+func (p *PortalAPI) serveAPILint(rw http.ResponseWriter, r *http.Request, _ *gateway, _ *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
 	logger := log.Ctx(ctx)
 
-	spec, err := p.getOpenAPISpec(ctx, a)
+	spec, err := p.getOpenAPISpec(ctx, a, resolveOpenAPISpec(a, v))
 	if err != nil {
 		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
 		rw.WriteHeader(http.StatusBadGateway)
@@ -143,73 +626,205 @@ func (p *PortalAPI) serveAPISpec(ctx context.Context, rw http.ResponseWriter, g
 		return
 	}
 
-	var pathPrefix string
-	if c != nil {
-		pathPrefix = c.Spec.PathPrefix
+	results := p.ruleset.Lint(spec)
+	if results == nil {
+		results = []lint.Result{}
 	}
-	pathPrefix = path.Join(pathPrefix, a.Spec.PathPrefix)
 
-	// As soon as a CustomDomain is provided on the Gateway, the API is no longer accessible through the HubDomain.
-	domains := g.Status.CustomDomains
-	if len(domains) == 0 {
-		domains = []string{g.Status.HubDomain}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err = json.NewEncoder(rw).Encode(lintResp{Results: results}); err != nil {
+		logger.Error().Err(err).Msg("Unable to serve OpenAPI spec lint results")
 	}
+}
 
-	if err = overrideServersAndSecurity(spec, domains, pathPrefix); err != nil {
-		logger.Error().Err(err).Msg("Unable to adapt OpenAPI spec server and security configurations")
+// serveAPIDiff serves the changes made to the OpenAPI spec of the given API version since the revision given by the
+// "against" query parameter or, by default, since the previous revision fetched by the agent.
+func (p *PortalAPI) serveAPIDiff(rw http.ResponseWriter, r *http.Request, _ *gateway, _ *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	rawSpec, specKey, err := p.fetchOpenAPISpec(ctx, a, resolveOpenAPISpec(a, v))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	rev := api.SpecRevision(rawSpec)
+
+	// The controller only snapshots the OpenAPI spec of the API, not the ones of its versions.
+	snapshotAPI := a
+	if v != nil && !isOpenAPISpecEmpty(v.OpenAPISpec) {
+		snapshotAPI = nil
+	}
+
+	ref := r.URL.Query().Get("against")
+	against, ok := p.history.find(specKey, snapshotAPI, rev, ref)
+	if !ok {
+		logger.Debug().Str("against", ref).Msg("OpenAPI spec revision not found")
+		rw.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	spec, err := openapi3.NewLoader().LoadFromData(rawSpec)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to load OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	againstSpec, err := openapi3.NewLoader().LoadFromData(against.raw)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to load OpenAPI spec snapshot")
 		rw.WriteHeader(http.StatusInternalServerError)
 
 		return
 	}
 
+	report := diff.Compare(againstSpec, spec)
+	if report.Changes == nil {
+		report.Changes = []diff.Change{}
+	}
+
 	rw.Header().Set("Content-Type", "application/json")
 	rw.WriteHeader(http.StatusOK)
 
-	if err = json.NewEncoder(rw).Encode(spec); err != nil {
-		logger.Error().Msg("Unable to serve OpenAPI spec")
+	resp := diffResp{
+		Revision: rev,
+		Against:  against.revision,
+		Report:   report,
+	}
+	if err = json.NewEncoder(rw).Encode(resp); err != nil {
+		logger.Error().Err(err).Msg("Unable to serve OpenAPI spec diff")
 	}
 }
 
-func (p *PortalAPI) getOpenAPISpec(ctx context.Context, a *hubv1alpha1.API) (*openapi3.T, error) {
-	svc := a.Spec.Service
+// serveAPIMock serves a mock response generated from the OpenAPI spec of the given API version, for the operation
+// matching the request path relative to the mock path prefix.
+func (p *PortalAPI) serveAPIMock(rw http.ResponseWriter, r *http.Request, _ *gateway, _ *collection, a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
 
-	var openapiURL *url.URL
-	switch {
-	case svc.OpenAPISpec.URL != "":
-		u, err := url.Parse(svc.OpenAPISpec.URL)
-		if err != nil {
-			return nil, fmt.Errorf("parse OpenAPI URL %q: %w", svc.OpenAPISpec.URL, err)
-		}
-		openapiURL = u
+	spec, err := p.getOpenAPISpec(ctx, a, resolveOpenAPISpec(a, v))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to fetch OpenAPI spec")
+		rw.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	req := r.Clone(ctx)
+	req.URL.Path = "/" + chi.URLParam(r, "*")
+	req.URL.RawPath = ""
+
+	mock.NewHandler(spec).ServeHTTP(rw, req)
+}
+
+// resolveOpenAPISpec returns the OpenAPISpec of the given API version, falling back on the API one.
+func resolveOpenAPISpec(a *hubv1alpha1.API, v *hubv1alpha1.APIVersion) hubv1alpha1.OpenAPISpec {
+	if v != nil && !isOpenAPISpecEmpty(v.OpenAPISpec) {
+		return v.OpenAPISpec
+	}
+
+	return a.Spec.Service.OpenAPISpec
+}
+
+func (p *PortalAPI) getOpenAPISpec(ctx context.Context, a *hubv1alpha1.API, openAPISpec hubv1alpha1.OpenAPISpec) (*openapi3.T, error) {
+	rawSpec, _, err := p.fetchOpenAPISpec(ctx, a, openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new loader must be created each time. LoadFromData mutates the internal state of Loader.
+	// LoadFromURI doesn't take a context, therefore, we must do the call ourselves.
+	spec, err := openapi3.NewLoader().LoadFromData(rawSpec)
+	if err != nil {
+		return nil, fmt.Errorf("load OpenAPI spec: %w", err)
+	}
+
+	return spec, nil
+}
 
-	case svc.Port.Number != 0 || svc.OpenAPISpec.Port != nil && svc.OpenAPISpec.Port.Number != 0:
-		protocol := svc.OpenAPISpec.Protocol
-		if svc.OpenAPISpec.Protocol == "" {
-			protocol = "http"
+// fetchOpenAPISpec fetches the raw OpenAPI spec from the first of its sources to succeed, unless it has been fetched
+// less than specCacheTTL ago. It also returns the key identifying the spec, under which its revisions are tracked.
+func (p *PortalAPI) fetchOpenAPISpec(ctx context.Context, a *hubv1alpha1.API, openAPISpec hubv1alpha1.OpenAPISpec) ([]byte, string, error) {
+	sources := openAPISpec.Sources()
+
+	// The spec is identified by its first valid source, whichever source it's eventually fetched from, for its
+	// revisions to be tracked consistently.
+	var key string
+	for _, source := range sources {
+		sourceKey, err := openAPISpecSourceKey(a, source)
+		if err == nil {
+			key = sourceKey
+			break
 		}
+	}
+	if key == "" {
+		return nil, "", errors.New("no spec endpoint specified")
+	}
+
+	p.specsMu.Lock()
+	cached, ok := p.specs[key]
+	p.specsMu.Unlock()
 
-		port := svc.Port.Number
-		if svc.OpenAPISpec.Port != nil {
-			port = svc.OpenAPISpec.Port.Number
+	if ok && time.Since(cached.fetchedAt) < specCacheTTL {
+		return cached.raw, key, nil
+	}
+
+	var errs []error
+	for i, source := range sources {
+		rawSpec, err := p.fetchOpenAPISpecSource(ctx, a, source)
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
 
-		namespace := a.Namespace
-		if namespace == "" {
-			namespace = "default"
+		if i > 0 {
+			log.Ctx(ctx).Warn().
+				Errs("errors", errs).
+				Int("source", i).
+				Msg("OpenAPI spec fetched from a fallback source")
 		}
 
-		openapiURL = &url.URL{
-			Scheme: protocol,
-			Host:   fmt.Sprint(svc.Name, ".", namespace, ":", port),
-			Path:   svc.OpenAPISpec.Path,
+		p.specsMu.Lock()
+		p.specs[key] = cachedSpec{raw: rawSpec, fetchedAt: time.Now()}
+		p.specsMu.Unlock()
+
+		p.history.record(key, rawSpec)
+
+		return rawSpec, key, nil
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+// fetchOpenAPISpecSource fetches the raw OpenAPI spec of the given API from the given source.
+func (p *PortalAPI) fetchOpenAPISpecSource(ctx context.Context, a *hubv1alpha1.API, source hubv1alpha1.OpenAPISpecSource) ([]byte, error) {
+	if source.ConfigMapRef != nil {
+		if p.configMaps == nil {
+			return nil, errors.New("OpenAPI specs can't be read from ConfigMaps")
 		}
-	default:
-		return nil, errors.New("no spec endpoint specified")
+
+		return api.ReadOpenAPISpecConfigMap(ctx, p.configMaps, a, source.ConfigMapRef)
+	}
+	if api.NeedsOpenAPISpecDiscovery(source) {
+		return p.discoverOpenAPISpec(ctx, a, source)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, openapiURL.String(), http.NoBody)
+	specURL, err := api.OpenAPISpecURL(a, source)
 	if err != nil {
-		return nil, fmt.Errorf("create request %q: %w", openapiURL.String(), err)
+		return nil, fmt.Errorf("get OpenAPI spec URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request %q: %w", specURL, err)
 	}
 
 	req.Header.Add("Accept", "application/json")
@@ -217,22 +832,47 @@ func (p *PortalAPI) getOpenAPISpec(ctx context.Context, a *hubv1alpha1.API) (*op
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("do request %q: %w", openapiURL.String(), err)
+		return nil, fmt.Errorf("do request %q: %w", specURL, err)
 	}
+	defer func() { _ = resp.Body.Close() }()
 
 	rawSpec, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read spec %q: %w", openapiURL.String(), err)
+		return nil, fmt.Errorf("read spec %q: %w", specURL, err)
 	}
 
-	// A new loader must be created each time. LoadFromData mutates the internal state of Loader.
-	// LoadFromURI doesn't take a context, therefore, we must do the call ourselves.
-	spec, err := openapi3.NewLoader().LoadFromData(rawSpec)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch spec %q: unexpected status code %d", specURL, resp.StatusCode)
+	}
+
+	return rawSpec, nil
+}
+
+// openAPISpecSourceKey returns the key identifying the OpenAPI spec of the given API served by the given source.
+func openAPISpecSourceKey(a *hubv1alpha1.API, source hubv1alpha1.OpenAPISpecSource) (string, error) {
+	if source.ConfigMapRef != nil {
+		return fmt.Sprintf("configmap://%s/%s/%s", a.Namespace, source.ConfigMapRef.Name, source.ConfigMapRef.Key), nil
+	}
+
+	specURL, err := api.OpenAPISpecURL(a, source)
 	if err != nil {
-		return nil, fmt.Errorf("load OpenAPI spec: %w", err)
+		return "", err
 	}
 
-	return spec, nil
+	return specURL.String(), nil
+}
+
+func isOpenAPISpecEmpty(spec hubv1alpha1.OpenAPISpec) bool {
+	return spec.URL == "" && spec.Path == "" && spec.Port == nil && spec.ConfigMapRef == nil && len(spec.Fallbacks) == 0
+}
+
+// deprecateOperations flags all the operations of the given spec as deprecated.
+func deprecateOperations(spec *openapi3.T) {
+	for _, pathItem := range spec.Paths {
+		for _, operation := range pathItem.Operations() {
+			operation.Deprecated = true
+		}
+	}
 }
 
 func overrideServersAndSecurity(spec *openapi3.T, domains []string, pathPrefix string) error {
@@ -270,6 +910,50 @@ func overrideServersAndSecurity(spec *openapi3.T, domains []string, pathPrefix s
 	return nil
 }
 
+// Security schemes advertised in the OpenAPI specs for the credential locations allowed by the gateway.
+const (
+	securitySchemeBearer = "bearer_auth"
+	securitySchemeQuery  = "query_auth"
+)
+
+// setSecurity makes the given spec require credentials sent from one of the given locations. The spec is left without
+// security requirements when no locations are given.
+func setSecurity(spec *openapi3.T, locations []string) {
+	if len(locations) == 0 {
+		return
+	}
+
+	if spec.Components == nil {
+		spec.Components = &openapi3.Components{}
+	}
+	if spec.Components.SecuritySchemes == nil {
+		spec.Components.SecuritySchemes = make(openapi3.SecuritySchemes)
+	}
+
+	security := openapi3.SecurityRequirements{}
+	for _, location := range locations {
+		var (
+			name   string
+			scheme *openapi3.SecurityScheme
+		)
+		switch location {
+		case hubv1alpha1.CredentialLocationHeader:
+			name = securitySchemeBearer
+			scheme = &openapi3.SecurityScheme{Type: "http", Scheme: "bearer"}
+		case hubv1alpha1.CredentialLocationQuery:
+			name = securitySchemeQuery
+			scheme = &openapi3.SecurityScheme{Type: "apiKey", In: "query", Name: "api_key"}
+		default:
+			continue
+		}
+
+		spec.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+		security = append(security, openapi3.NewSecurityRequirement().Authenticate(name))
+	}
+
+	spec.Security = security
+}
+
 func overrideServerDomains(servers openapi3.Servers, domains []string, pathPrefix string) (openapi3.Servers, error) {
 	if len(servers) == 0 || servers[0].URL == "" {
 		return servers, nil
@@ -305,14 +989,43 @@ type collectionResp struct {
 }
 
 type apiResp struct {
+	Name       string           `json:"name"`
+	PathPrefix string           `json:"pathPrefix"`
+	SpecLink   string           `json:"specLink"`
+	Versions   []apiVersionResp `json:"versions,omitempty"`
+}
+
+type apiVersionResp struct {
 	Name       string `json:"name"`
 	PathPrefix string `json:"pathPrefix"`
 	SpecLink   string `json:"specLink"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+}
+
+type changelogResp struct {
+	Entries []ChangelogEntry `json:"entries"`
+}
+
+type lintResp struct {
+	Results []lint.Result `json:"results"`
 }
 
-func buildListResp(p *portal) listResp {
+type diffResp struct {
+	Revision string `json:"revision"`
+	Against  string `json:"against"`
+
+	diff.Report
+}
+
+// buildListResp builds the list of the APIs and APICollections of the given portal. When a user is given, only the
+// ones they have access to are listed.
+func buildListResp(p *portal, u *user) listResp {
 	var resp listResp
 	for collectionName, c := range p.Gateway.Collections {
+		if u != nil && !c.Consumers.allowsUser(*u) {
+			continue
+		}
+
 		cr := collectionResp{
 			Name:       collectionName,
 			PathPrefix: c.Spec.PathPrefix,
@@ -320,10 +1033,14 @@ func buildListResp(p *portal) listResp {
 		}
 
 		for apiNameNamespace, a := range c.APIs {
+			pathPrefix := path.Join(cr.PathPrefix, a.Spec.PathPrefix)
+			specLink := fmt.Sprintf("/collections/%s/apis/%s", collectionName, apiNameNamespace)
+
 			cr.APIs = append(cr.APIs, apiResp{
 				Name:       a.Name,
-				PathPrefix: path.Join(cr.PathPrefix, a.Spec.PathPrefix),
-				SpecLink:   fmt.Sprintf("/collections/%s/apis/%s", collectionName, apiNameNamespace),
+				PathPrefix: pathPrefix,
+				SpecLink:   specLink,
+				Versions:   buildVersionsResp(a.Spec.Versions, pathPrefix, specLink),
 			})
 		}
 		sortAPIsResp(cr.APIs)
@@ -333,10 +1050,17 @@ func buildListResp(p *portal) listResp {
 	sortCollectionsResp(resp.Collections)
 
 	for apiNameNamespace, a := range p.Gateway.APIs {
+		if u != nil && !p.Gateway.Consumers[apiNameNamespace].allowsUser(*u) {
+			continue
+		}
+
+		specLink := fmt.Sprintf("/apis/%s", apiNameNamespace)
+
 		resp.APIs = append(resp.APIs, apiResp{
 			Name:       a.Name,
 			PathPrefix: a.Spec.PathPrefix,
-			SpecLink:   fmt.Sprintf("/apis/%s", apiNameNamespace),
+			SpecLink:   specLink,
+			Versions:   buildVersionsResp(a.Spec.Versions, a.Spec.PathPrefix, specLink),
 		})
 	}
 	sortAPIsResp(resp.APIs)
@@ -351,6 +1075,20 @@ func buildListResp(p *portal) listResp {
 	return resp
 }
 
+func buildVersionsResp(versions []hubv1alpha1.APIVersion, apiPathPrefix, apiSpecLink string) []apiVersionResp {
+	var resp []apiVersionResp
+	for _, v := range versions {
+		resp = append(resp, apiVersionResp{
+			Name:       v.Name,
+			PathPrefix: path.Join(apiPathPrefix, v.PathPrefix),
+			SpecLink:   fmt.Sprintf("%s/versions/%s", apiSpecLink, v.Name),
+			Deprecated: v.Deprecated,
+		})
+	}
+
+	return resp
+}
+
 func sortAPIsResp(apis []apiResp) {
 	sort.Slice(apis, func(i, j int) bool {
 		return apis[i].Name < apis[j].Name
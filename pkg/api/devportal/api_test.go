@@ -18,6 +18,7 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package devportal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,12 +27,19 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/diff"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
 )
 
 var testPortal = portal{
@@ -133,6 +141,14 @@ var testPortal = portal{
 							Path: "/spec.json",
 						},
 					},
+					Versions: []hubv1alpha1.APIVersion{
+						{Name: "v1", PathPrefix: "/v1", Deprecated: true},
+						{
+							Name:        "v2",
+							PathPrefix:  "/v2",
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{Path: "/v2/spec.json"},
+						},
+					},
 				},
 			},
 			"metrics@default": {
@@ -166,7 +182,7 @@ var testPortal = portal{
 }
 
 func TestPortalAPI_Router_listAPIs(t *testing.T) {
-	a, err := NewPortalAPI(&testPortal)
+	a, err := NewPortalAPI(&testPortal, lint.DefaultRuleset())
 	require.NoError(t, err)
 
 	srv := httptest.NewServer(a)
@@ -198,14 +214,22 @@ func TestPortalAPI_Router_listAPIs(t *testing.T) {
 			{Name: "health", PathPrefix: "/health", SpecLink: "/apis/health@default"},
 			{Name: "managers", PathPrefix: "/managers", SpecLink: "/apis/managers@people-ns"},
 			{Name: "metrics", PathPrefix: "/metrics", SpecLink: "/apis/metrics@default"},
-			{Name: "notifications", PathPrefix: "/notifications", SpecLink: "/apis/notifications@default"},
+			{
+				Name:       "notifications",
+				PathPrefix: "/notifications",
+				SpecLink:   "/apis/notifications@default",
+				Versions: []apiVersionResp{
+					{Name: "v1", PathPrefix: "/notifications/v1", SpecLink: "/apis/notifications@default/versions/v1", Deprecated: true},
+					{Name: "v2", PathPrefix: "/notifications/v2", SpecLink: "/apis/notifications@default/versions/v2"},
+				},
+			},
 		},
 	}, got)
 }
 
 func TestPortalAPI_Router_listAPIs_noAPIsAndCollections(t *testing.T) {
 	var p portal
-	a, err := NewPortalAPI(&p)
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
 	require.NoError(t, err)
 
 	srv := httptest.NewServer(a)
@@ -227,6 +251,117 @@ func TestPortalAPI_Router_listAPIs_noAPIsAndCollections(t *testing.T) {
 	}`, string(got))
 }
 
+func TestPortalAPI_Router_restrictedAccess(t *testing.T) {
+	p := portal{
+		Gateway: gateway{
+			Collections: map[string]collection{
+				"products": {
+					APICollection: hubv1alpha1.APICollection{
+						ObjectMeta: metav1.ObjectMeta{Name: "products"},
+						Spec:       hubv1alpha1.APICollectionSpec{PathPrefix: "/products"},
+					},
+					APIs: map[string]hubv1alpha1.API{
+						"books@products-ns": {
+							ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "products-ns"},
+							Spec:       hubv1alpha1.APISpec{PathPrefix: "/books"},
+						},
+					},
+					Consumers: &consumers{Groups: []string{"supplier"}},
+				},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"search@default": {
+					ObjectMeta: metav1.ObjectMeta{Name: "search", Namespace: "default"},
+					Spec:       hubv1alpha1.APISpec{PathPrefix: "/search"},
+				},
+			},
+			Consumers: map[string]*consumers{
+				"search@default": {Users: []string{"jane.doe@example.com"}},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(a)
+
+	tests := []struct {
+		desc       string
+		path       string
+		email      string
+		groups     string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "list APIs as a granted user",
+			path:       "/apis",
+			email:      "jane.doe@example.com",
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"collections": [],
+				"apis": [{"name": "search", "pathPrefix": "/search", "specLink": "/apis/search@default"}]
+			}`,
+		},
+		{
+			desc:       "list APIs as a group member",
+			path:       "/apis",
+			email:      "john.doe@example.com",
+			groups:     "supplier",
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"collections": [{
+					"name": "products",
+					"pathPrefix": "/products",
+					"apis": [{"name": "books", "pathPrefix": "/products/books", "specLink": "/collections/products/apis/books@products-ns"}]
+				}],
+				"apis": []
+			}`,
+		},
+		{
+			desc:       "get an API spec as a user without access",
+			path:       "/apis/search@default",
+			email:      "john.doe@example.com",
+			groups:     "supplier",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "get a collection API spec as a user without access",
+			path:       "/collections/products/apis/books@products-ns",
+			email:      "jane.doe@example.com",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+test.path, http.NoBody)
+			require.NoError(t, err)
+			req.Header.Set(apitoken.HeaderEmail, test.email)
+			req.Header.Set(apitoken.HeaderGroups, test.groups)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			require.Equal(t, test.wantStatus, resp.StatusCode)
+
+			if test.wantBody == "" {
+				return
+			}
+
+			got, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.wantBody, string(got))
+		})
+	}
+}
+
 func TestPortalAPI_Router_getCollectionAPISpec(t *testing.T) {
 	tests := []struct {
 		desc       string
@@ -256,7 +391,7 @@ func TestPortalAPI_Router_getCollectionAPISpec(t *testing.T) {
 			desc:       "No OpenAPI spec defined",
 			collection: "products",
 			api:        "toys@products-ns",
-			wantURL:    "http://toys-svc.products-ns:8080/",
+			wantURL:    "http://toys-svc.products-ns:8080/openapi.json",
 		},
 	}
 
@@ -278,7 +413,7 @@ func TestPortalAPI_Router_getCollectionAPISpec(t *testing.T) {
 				}
 			}))
 
-			a, err := NewPortalAPI(&testPortal)
+			a, err := NewPortalAPI(&testPortal, lint.DefaultRuleset())
 			require.NoError(t, err)
 			a.httpClient = buildProxyClient(t, svcSrv.URL)
 
@@ -431,7 +566,7 @@ func TestPortalAPI_Router_getCollectionAPISpec_overrideServerAndAuth(t *testing.
 		test := test
 
 		t.Run(test.desc, func(t *testing.T) {
-			a, err := NewPortalAPI(&test.portal)
+			a, err := NewPortalAPI(&test.portal, lint.DefaultRuleset())
 			require.NoError(t, err)
 			a.httpClient = http.DefaultClient
 
@@ -480,7 +615,17 @@ func TestPortalAPI_Router_getAPISpec(t *testing.T) {
 		{
 			desc:    "No OpenAPI spec defined",
 			api:     "health@default",
-			wantURL: "http://health-svc.default:8080/",
+			wantURL: "http://health-svc.default:8080/openapi.json",
+		},
+		{
+			desc:    "Version without OpenAPI spec defined",
+			api:     "notifications@default/versions/v1",
+			wantURL: "http://notifications-svc.default:8080/spec.json",
+		},
+		{
+			desc:    "Version with its own OpenAPI spec",
+			api:     "notifications@default/versions/v2",
+			wantURL: "http://notifications-svc.default:8080/v2/spec.json",
 		},
 	}
 
@@ -501,7 +646,7 @@ func TestPortalAPI_Router_getAPISpec(t *testing.T) {
 					rw.WriteHeader(http.StatusInternalServerError)
 				}
 			}))
-			a, err := NewPortalAPI(&testPortal)
+			a, err := NewPortalAPI(&testPortal, lint.DefaultRuleset())
 			require.NoError(t, err)
 			a.httpClient = buildProxyClient(t, svcSrv.URL)
 
@@ -523,6 +668,21 @@ func TestPortalAPI_Router_getAPISpec(t *testing.T) {
 	}
 }
 
+func TestPortalAPI_Router_getAPISpec_unknownVersion(t *testing.T) {
+	a, err := NewPortalAPI(&testPortal, lint.DefaultRuleset())
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(a)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/apis/notifications@default/versions/v3", http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
 func TestPortalAPI_Router_getAPISpec_overrideServerAndAuth(t *testing.T) {
 	spec, err := os.ReadFile("./testdata/openapi/spec.json")
 	require.NoError(t, err)
@@ -557,7 +717,7 @@ func TestPortalAPI_Router_getAPISpec_overrideServerAndAuth(t *testing.T) {
 		},
 	}
 
-	a, err := NewPortalAPI(&p)
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
 	require.NoError(t, err)
 	a.httpClient = http.DefaultClient
 
@@ -578,6 +738,1027 @@ func TestPortalAPI_Router_getAPISpec_overrideServerAndAuth(t *testing.T) {
 	assert.JSONEq(t, string(wantSpec), string(got))
 }
 
+func TestPortalAPI_Router_getAPISpec_cached(t *testing.T) {
+	spec, err := os.ReadFile("./testdata/openapi/spec.json")
+	require.NoError(t, err)
+
+	var calls int
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = rw.Write(spec)
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+
+	apiSrv := httptest.NewServer(a)
+
+	for i := 0; i < 3; i++ {
+		req, err := http.NewRequest(http.MethodGet, apiSrv.URL+"/apis/my-api@my-ns", http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	assert.Equal(t, 1, calls)
+
+	// Each portal has its own cache.
+	other, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	other.httpClient = http.DefaultClient
+
+	rw := httptest.NewRecorder()
+	other.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestPortalAPI_Router_getAPISpec_fallbacks(t *testing.T) {
+	spec, err := os.ReadFile("./testdata/openapi/spec.json")
+	require.NoError(t, err)
+
+	var calls []string
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		if r.URL.Path != "/mirror.json" {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = rw.Write(spec)
+	}))
+
+	newAPI := func(name string, fallbacks ...hubv1alpha1.OpenAPISpecSource) hubv1alpha1.API {
+		return hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "my-ns"},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/" + name,
+				Service: hubv1alpha1.APIService{
+					Name: "svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{
+						URL:       svcSrv.URL + "/" + name + ".json",
+						Fallbacks: fallbacks,
+					},
+				},
+			},
+		}
+	}
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"books@my-ns": newAPI("books",
+					hubv1alpha1.OpenAPISpecSource{URL: svcSrv.URL + "/unavailable.json"},
+					hubv1alpha1.OpenAPISpecSource{URL: svcSrv.URL + "/mirror.json"},
+				),
+				"authors@my-ns": newAPI("authors",
+					hubv1alpha1.OpenAPISpecSource{ConfigMapRef: &hubv1alpha1.OpenAPISpecConfigMapRef{Name: "authors-spec", Key: "spec.json"}},
+				),
+				"stores@my-ns": newAPI("stores",
+					hubv1alpha1.OpenAPISpecSource{URL: svcSrv.URL + "/unavailable.json"},
+				),
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+	a.configMaps = kubemock.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "authors-spec", Namespace: "my-ns"},
+		Data:       map[string]string{"spec.json": string(spec)},
+	}).CoreV1()
+
+	// Sources are tried in order until one succeeds.
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/books@my-ns", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, []string{"/books.json", "/unavailable.json", "/mirror.json"}, calls)
+
+	// The spec is cached under its primary source.
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/books@my-ns", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Len(t, calls, 3)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/authors@my-ns", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var got openapi3.T
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Equal(t, "Api product 1", got.Info.Title)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/stores@my-ns", http.NoBody))
+	assert.Equal(t, http.StatusBadGateway, rw.Code)
+}
+
+func TestSetSecurity(t *testing.T) {
+	tests := []struct {
+		desc      string
+		locations []string
+		want      string
+	}{
+		{
+			desc: "no credential locations",
+			want: `{"openapi": "3.0.0", "info": null, "paths": null}`,
+		},
+		{
+			desc:      "header only",
+			locations: []string{hubv1alpha1.CredentialLocationHeader},
+			want: `{
+				"openapi": "3.0.0",
+				"info": null,
+				"paths": null,
+				"components": {
+					"securitySchemes": {
+						"bearer_auth": {"type": "http", "scheme": "bearer"}
+					}
+				},
+				"security": [{"bearer_auth": []}]
+			}`,
+		},
+		{
+			desc:      "header and query",
+			locations: []string{hubv1alpha1.CredentialLocationHeader, hubv1alpha1.CredentialLocationQuery},
+			want: `{
+				"openapi": "3.0.0",
+				"info": null,
+				"paths": null,
+				"components": {
+					"securitySchemes": {
+						"bearer_auth": {"type": "http", "scheme": "bearer"},
+						"query_auth": {"type": "apiKey", "in": "query", "name": "api_key"}
+					}
+				},
+				"security": [{"bearer_auth": []}, {"query_auth": []}]
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			spec := &openapi3.T{OpenAPI: "3.0.0"}
+			setSecurity(spec, test.locations)
+
+			got, err := json.Marshal(spec)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.want, string(got))
+		})
+	}
+}
+
+func TestPortalAPI_Router_lintAPISpec(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "v1"}, "paths": {"/books": {"get": {"responses": {"200": {"description": "Books"}}}}}}`))
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ruleset := lint.Ruleset{Rules: lint.Rules{
+		OperationIDRequired: lint.SeverityError,
+		SemanticVersion:     lint.SeverityWarn,
+	}}
+
+	a, err := NewPortalAPI(&p, ruleset)
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/lint", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.JSONEq(t, `{
+		"results": [
+			{"rule": "semantic-version", "severity": "warn", "path": "info.version", "message": "Version \"v1\" is not a semantic version"},
+			{"rule": "operation-id-required", "severity": "error", "path": "paths./books.GET", "message": "Operation must have an operationId"}
+		]
+	}`, rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/unknown@my-ns/lint", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestPortalAPI_Router_diffAPISpec(t *testing.T) {
+	specs := []string{
+		`{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {"/books": {"get": {"responses": {"200": {"description": "Books"}}}}}}`,
+		`{"openapi": "3.0.0", "info": {"title": "Books", "version": "2.0.0"}, "paths": {"/authors": {"get": {"responses": {"200": {"description": "Authors"}}}}}}`,
+	}
+
+	var calls int
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(specs[calls]))
+		calls++
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+
+	// No previous revision to compare against.
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/diff", http.NoBody))
+	require.Equal(t, http.StatusNotFound, rw.Code)
+
+	// Expire the cache to fetch the new revision of the spec.
+	a.specs = make(map[string]cachedSpec)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/diff", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var got diffResp
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&got))
+
+	assert.Equal(t, api.SpecRevision([]byte(specs[0])), got.Against)
+	assert.Equal(t, api.SpecRevision([]byte(specs[1])), got.Revision)
+	assert.True(t, got.Breaking)
+	assert.Equal(t, []diff.Change{
+		{Kind: diff.KindNonBreaking, Path: "info.version", Message: `Version changed from "1.0.0" to "2.0.0"`},
+		{Kind: diff.KindNonBreaking, Path: "paths./authors", Message: "Path added"},
+		{Kind: diff.KindBreaking, Path: "paths./books", Message: "Path removed"},
+	}, got.Changes)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/diff?against="+got.Revision, http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"revision": %[1]q, "against": %[1]q, "breaking": false, "changes": []}`, got.Revision), rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/diff?against=unknown", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestPortalAPI_Router_diffAPISpec_snapshots(t *testing.T) {
+	spec := `{"openapi": "3.0.0", "info": {"title": "Books", "version": "2.0.0"}, "paths": {}}`
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(spec))
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	snapshotSpec := []byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {}}`)
+	store := snapshotStoreFunc(func(apiName, apiNamespace string) ([]api.SpecSnapshot, error) {
+		if apiName != "my-api" || apiNamespace != "my-ns" {
+			return nil, nil
+		}
+
+		return []api.SpecSnapshot{
+			{Revision: api.SpecRevision(snapshotSpec), Spec: snapshotSpec},
+			{Revision: api.SpecRevision([]byte(spec)), Spec: []byte(spec)},
+		}, nil
+	})
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+	a.history = newSpecHistory(store)
+
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/diff", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"revision": %q,
+		"against": %q,
+		"breaking": false,
+		"changes": [
+			{"kind": "non-breaking", "path": "info.version", "message": "Version changed from \"1.0.0\" to \"2.0.0\""}
+		]
+	}`, api.SpecRevision([]byte(spec)), api.SpecRevision(snapshotSpec)), rw.Body.String())
+}
+
+func TestPortalAPI_Router_mockAPI(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{
+			"openapi": "3.0.0",
+			"info": {"title": "Books", "version": "1.0.0"},
+			"paths": {
+				"/books/{id}": {
+					"get": {
+						"responses": {
+							"200": {
+								"description": "Book",
+								"content": {"application/json": {"schema": {"type": "object", "properties": {"title": {"type": "string"}}}}}
+							}
+						}
+					}
+				}
+			}
+		}`))
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/mock/books/42", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"title": "string"}`, rw.Body.String())
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodDelete, "/apis/my-api@my-ns/mock/books/42", http.NoBody))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+}
+
+func TestPortalAPI_Router_downloadSDK(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "servers": [{"url": "http://books.svc"}], "paths": {}}`))
+	}))
+
+	var gotSpec openapi3.T
+	generatorSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/gen/clients/typescript-fetch":
+			var body struct {
+				Spec openapi3.T `json:"spec"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			gotSpec = body.Spec
+
+			_, _ = rw.Write([]byte(`{"code": "abc", "link": "http://generator/api/gen/download/abc"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/gen/download/abc":
+			_, _ = rw.Write([]byte("zip"))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+
+	// SDK downloads are disabled without generator.
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/sdk?lang=ts", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	a.sdkGenerator, err = NewSDKGenerator(generatorSrv.URL)
+	require.NoError(t, err)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/sdk?lang=java", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/sdk?lang=ts", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.Equal(t, "application/zip", rw.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="my-api-ts-sdk.zip"`, rw.Header().Get("Content-Disposition"))
+	assert.Equal(t, "zip", rw.Body.String())
+
+	// The SDK is generated from the spec as served by the portal.
+	require.Len(t, gotSpec.Servers, 1)
+	assert.Equal(t, "https://majestic-beaver-123.hub-traefik.io/api-prefix", gotSpec.Servers[0].URL)
+}
+
+func TestPortalAPI_Router_downloadSDK_exceedsServerWriteTimeout(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {}}`))
+	}))
+	t.Cleanup(svcSrv.Close)
+
+	generatorSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/gen/clients/go":
+			// Generating the SDK takes longer than the write timeout of the portal server.
+			time.Sleep(300 * time.Millisecond)
+			_, _ = rw.Write([]byte(`{"code": "abc"}`))
+		case "/api/gen/download/abc":
+			_, _ = rw.Write([]byte("zip"))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(generatorSrv.Close)
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+	a.sdkGenerator, err = NewSDKGenerator(generatorSrv.URL)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(a)
+	srv.Config.WriteTimeout = 100 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/apis/my-api@my-ns/sdk?lang=go")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	got, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "zip", string(got))
+}
+
+func TestPortalAPI_Router_exportPostman(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{
+			"openapi": "3.0.0",
+			"info": {"title": "Books", "description": "Books API", "version": "1.0.0"},
+			"servers": [{"url": "http://books.svc/v1"}],
+			"paths": {
+				"/books": {
+					"post": {
+						"summary": "Create a book",
+						"tags": ["books"],
+						"requestBody": {
+							"content": {"application/json": {"schema": {"type": "object", "properties": {"title": {"type": "string"}}}}}
+						},
+						"responses": {"201": {"description": "Created"}}
+					}
+				},
+				"/books/{id}": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string", "example": "42"}}],
+					"get": {
+						"operationId": "getBook",
+						"tags": ["books"],
+						"parameters": [{"name": "fields", "in": "query", "schema": {"type": "string"}}],
+						"responses": {"200": {"description": "Book"}}
+					}
+				},
+				"/health": {
+					"get": {"responses": {"200": {"description": "Healthy"}}}
+				}
+			}
+		}`))
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Spec: hubv1alpha1.APIGatewaySpec{
+					CredentialLocations: []string{hubv1alpha1.CredentialLocationHeader},
+				},
+				Status: hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/api-prefix",
+						Service: hubv1alpha1.APIService{
+							Name:        "svc",
+							Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+							OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/postman", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="my-api.postman_collection.json"`, rw.Header().Get("Content-Disposition"))
+	assert.JSONEq(t, `{
+		"info": {
+			"name": "Books",
+			"description": "Books API",
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+		},
+		"item": [
+			{
+				"name": "books",
+				"item": [
+					{
+						"name": "Create a book",
+						"request": {
+							"method": "POST",
+							"header": [{"key": "Content-Type", "value": "application/json"}],
+							"url": {"raw": "{{baseUrl}}/books", "host": ["{{baseUrl}}"], "path": ["books"]},
+							"body": {"mode": "raw", "raw": "{\n  \"title\": \"string\"\n}", "options": {"raw": {"language": "json"}}}
+						}
+					},
+					{
+						"name": "getBook",
+						"request": {
+							"method": "GET",
+							"header": [],
+							"url": {
+								"raw": "{{baseUrl}}/books/:id",
+								"host": ["{{baseUrl}}"],
+								"path": ["books", ":id"],
+								"query": [{"key": "fields", "value": "", "disabled": true}],
+								"variable": [{"key": "id", "value": "42"}]
+							}
+						}
+					}
+				]
+			},
+			{
+				"name": "GET /health",
+				"request": {
+					"method": "GET",
+					"header": [],
+					"url": {"raw": "{{baseUrl}}/health", "host": ["{{baseUrl}}"], "path": ["health"]}
+				}
+			}
+		],
+		"auth": {
+			"type": "bearer",
+			"bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]
+		},
+		"variable": [
+			{"key": "baseUrl", "value": "https://majestic-beaver-123.hub-traefik.io/api-prefix/v1", "type": "string"},
+			{"key": "token", "value": "", "type": "string"}
+		]
+	}`, rw.Body.String())
+}
+
+func TestPortalAPI_Router_apiUsage(t *testing.T) {
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"}},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+
+	// Usage metrics are disabled without source.
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/metrics", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	var gotQuery api.UsageQuery
+	a.usage = usageSourceFunc(func(_ context.Context, query api.UsageQuery) (api.APIUsage, error) {
+		gotQuery = query
+
+		return api.APIUsage{
+			Total:  api.UsageMetrics{Requests: 10, Errors: 1, ErrorRate: 0.1, Latency: api.LatencyPercentiles{P50: 12, P90: 30, P99: 95.5}},
+			Tokens: []api.TokenUsage{{Token: "ci", UsageMetrics: api.UsageMetrics{Requests: 10, Errors: 1, ErrorRate: 0.1}}},
+		}, nil
+	})
+
+	// The usage is restricted to the tokens of the calling user.
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/metrics", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/metrics?period=1y", http.NoBody)
+	req.Header.Set(apitoken.HeaderEmail, "john@example.com")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/metrics?period=1h", http.NoBody)
+	req.Header.Set(apitoken.HeaderEmail, "john@example.com")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.Equal(t, api.UsageQuery{Portal: "my-portal", API: "my-api@my-ns", Email: "john@example.com", Period: time.Hour}, gotQuery)
+	assert.JSONEq(t, `{
+		"from": "0001-01-01T00:00:00Z",
+		"to": "0001-01-01T00:00:00Z",
+		"total": {"requests": 10, "errors": 1, "errorRate": 0.1, "latency": {"p50": 12, "p90": 30, "p99": 95.5}},
+		"tokens": [
+			{"token": "ci", "requests": 10, "errors": 1, "errorRate": 0.1, "latency": {"p50": 0, "p90": 0, "p99": 0}}
+		]
+	}`, rw.Body.String())
+}
+
+func TestPortalAPI_Router_apiExamples(t *testing.T) {
+	maxBodySize := 24
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						Versions: []hubv1alpha1.APIVersion{{Name: "v1", PathPrefix: "/v1"}},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+
+	var gotQuery api.ExampleQuery
+	a.examples = exampleSourceFunc(func(_ context.Context, query api.ExampleQuery) ([]api.APIExample, error) {
+		gotQuery = query
+
+		capturedAt := time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC)
+		return []api.APIExample{
+			{
+				Method: http.MethodGet,
+				Path:   "/books/{id}",
+				Request: api.ExampleRequest{
+					URL:     "/books/1?api_key=secret&fields=title",
+					Headers: map[string]string{"authorization": "Bearer secret", "X-Tenant": "acme"},
+				},
+				Response:   api.ExampleResponse{StatusCode: http.StatusOK, Body: `{"title":"Dune"}`},
+				CapturedAt: capturedAt,
+			},
+			{
+				Method:     http.MethodGet,
+				Path:       "/books/{id}",
+				Request:    api.ExampleRequest{URL: "/books/2"},
+				Response:   api.ExampleResponse{StatusCode: http.StatusNotFound},
+				CapturedAt: capturedAt.Add(time.Minute),
+			},
+			{
+				Method:     http.MethodGet,
+				Path:       "/books/{id}",
+				Request:    api.ExampleRequest{URL: "/books/3"},
+				Response:   api.ExampleResponse{StatusCode: http.StatusOK},
+				CapturedAt: capturedAt.Add(-time.Minute),
+			},
+			{
+				Method: http.MethodPost,
+				Path:   "/books",
+				Request: api.ExampleRequest{
+					URL:  "/books",
+					Body: `{"title":"Dune","owner":{"api_key":"secret","ssn":"123"}}`,
+				},
+				Response: api.ExampleResponse{
+					StatusCode: http.StatusCreated,
+					Headers:    map[string]string{"Set-Cookie": "session=secret"},
+					Body:       "a plain text body longer than the maximum body size",
+				},
+				CapturedAt: capturedAt,
+			},
+		}, nil
+	})
+
+	// Examples are not served when the gateway doesn't capture them.
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/examples", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	p.Gateway.Spec.ExampleCapture = &hubv1alpha1.APIGatewayExampleCapture{
+		MaxExamplesPerOperation: 2,
+		MaxBodySize:             &maxBodySize,
+		RedactedHeaders:         []string{"x-tenant"},
+		RedactedFields:          []string{"ssn"},
+	}
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/versions/v1/examples", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.Equal(t, api.ExampleQuery{Portal: "my-portal", API: "my-api@my-ns", Version: "v1"}, gotQuery)
+	assert.JSONEq(t, `{
+		"operations": [
+			{
+				"method": "POST",
+				"path": "/books",
+				"examples": [
+					{
+						"method": "POST",
+						"path": "/books",
+						"request": {"url": "/books", "body": "{\"owner\":{\"api_key\":\"RED"},
+						"response": {"statusCode": 201, "headers": {"Set-Cookie": "REDACTED"}, "body": "a plain text body longer"},
+						"capturedAt": "2000-10-30T01:30:00Z"
+					}
+				]
+			},
+			{
+				"method": "GET",
+				"path": "/books/{id}",
+				"examples": [
+					{
+						"method": "GET",
+						"path": "/books/{id}",
+						"request": {"url": "/books/2"},
+						"response": {"statusCode": 404},
+						"capturedAt": "2000-10-30T01:31:00Z"
+					},
+					{
+						"method": "GET",
+						"path": "/books/{id}",
+						"request": {
+							"url": "/books/1?api_key=REDACTED&fields=title",
+							"headers": {"authorization": "REDACTED", "X-Tenant": "REDACTED"}
+						},
+						"response": {"statusCode": 200, "body": "{\"title\":\"Dune\"}"},
+						"capturedAt": "2000-10-30T01:30:00Z"
+					}
+				]
+			}
+		]
+	}`, rw.Body.String())
+
+	// Examples are not served without source.
+	a.examples = nil
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/examples", http.NoBody))
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+}
+
+func TestPortalAPI_Router_apiQuota(t *testing.T) {
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {}}`))
+	}))
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						Service: hubv1alpha1.APIService{OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: svcSrv.URL}},
+					},
+				},
+			},
+			RateLimits: map[string][]hubv1alpha1.APIRateLimit{
+				"my-api@my-ns": {
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "everyone"},
+						Spec:       hubv1alpha1.APIRateLimitSpec{Limit: 10, Period: &metav1.Duration{Duration: time.Hour}},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "partners"},
+						Spec:       hubv1alpha1.APIRateLimitSpec{Groups: []string{"partner"}, Limit: 100},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = http.DefaultClient
+	a.now = func() time.Time { return time.Date(2023, 1, 1, 10, 15, 0, 0, time.UTC) }
+
+	// The quotas are restricted to the calling user.
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/quota", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	// The remaining budget is unknown without usage metrics.
+	req := httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/quota", http.NoBody)
+	req.Header.Set(apitoken.HeaderEmail, "john@example.com")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.JSONEq(t, `{
+		"quotas": [
+			{"name": "everyone", "limit": 10, "period": "1h0m0s", "resetAt": "2023-01-01T11:00:00Z"}
+		]
+	}`, rw.Body.String())
+
+	var gotQueries []api.UsageQuery
+	a.usage = usageSourceFunc(func(_ context.Context, query api.UsageQuery) (api.APIUsage, error) {
+		gotQueries = append(gotQueries, query)
+
+		return api.APIUsage{Total: api.UsageMetrics{Requests: 12}}, nil
+	})
+
+	req = httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/quota", http.NoBody)
+	req.Header.Set(apitoken.HeaderEmail, "john@example.com")
+	req.Header.Set(apitoken.HeaderGroups, "partner")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	assert.JSONEq(t, `{
+		"quotas": [
+			{"name": "everyone", "limit": 10, "period": "1h0m0s", "used": 12, "remaining": 0, "resetAt": "2023-01-01T11:00:00Z"},
+			{"name": "partners", "limit": 100, "period": "1s", "used": 0, "remaining": 100, "resetAt": "2023-01-01T10:15:01Z"}
+		]
+	}`, rw.Body.String())
+
+	// The usage is only queried on the elapsed part of the current windows.
+	assert.Equal(t, []api.UsageQuery{
+		{Portal: "my-portal", API: "my-api@my-ns", Email: "john@example.com", Period: 15 * time.Minute},
+	}, gotQueries)
+
+	// The rate limits applied to the user are listed in the served spec.
+	req = httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns", http.NoBody)
+	req.Header.Set(apitoken.HeaderGroups, "partner")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req)
+	require.Equal(t, http.StatusOK, rw.Code)
+
+	var gotSpec map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &gotSpec))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "everyone", "limit": float64(10), "period": "1h0m0s"},
+		map[string]interface{}{"name": "partners", "limit": float64(100), "period": "1s"},
+	}, gotSpec["x-ratelimit"])
+}
+
+type usageSourceFunc func(ctx context.Context, query api.UsageQuery) (api.APIUsage, error)
+
+func (f usageSourceFunc) GetAPIUsage(ctx context.Context, query api.UsageQuery) (api.APIUsage, error) {
+	return f(ctx, query)
+}
+
+type exampleSourceFunc func(ctx context.Context, query api.ExampleQuery) ([]api.APIExample, error)
+
+func (f exampleSourceFunc) ListAPIExamples(ctx context.Context, query api.ExampleQuery) ([]api.APIExample, error) {
+	return f(ctx, query)
+}
+
+type snapshotStoreFunc func(apiName, apiNamespace string) ([]api.SpecSnapshot, error)
+
+func (f snapshotStoreFunc) Snapshots(apiName, apiNamespace string) ([]api.SpecSnapshot, error) {
+	return f(apiName, apiNamespace)
+}
+
 func buildProxyClient(t *testing.T, proxyURL string) *http.Client {
 	t.Helper()
 
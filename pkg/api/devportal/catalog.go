@@ -0,0 +1,118 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/federation"
+)
+
+// CatalogReporter reports the portals served by the agent, for their catalog to be merged with the ones of the other
+// clusters.
+type CatalogReporter interface {
+	ReportCatalog(ctx context.Context, portals []federation.Portal) error
+}
+
+// Catalog returns the catalog of the portals.
+func (w *Watcher) Catalog() ([]federation.Portal, error) {
+	portals, err := w.getPortals()
+	if err != nil {
+		return nil, fmt.Errorf("get portals: %w", err)
+	}
+
+	return buildCatalog(portals), nil
+}
+
+// reportCatalog reports the catalog of the given portals.
+func (w *Watcher) reportCatalog(ctx context.Context, portals []portal) {
+	ctxReport, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := w.catalogReporter.ReportCatalog(ctxReport, buildCatalog(portals)); err != nil {
+		w.logger.Error().Err(err).Msg("Unable to report catalog")
+	}
+}
+
+// buildCatalog builds the catalog of the given portals. Portals and their APIs are sorted by name.
+func buildCatalog(portals []portal) []federation.Portal {
+	catalog := make([]federation.Portal, 0, len(portals))
+	for i := range portals {
+		p := &portals[i]
+
+		catalogPortal := federation.Portal{
+			Name:        p.Name,
+			Title:       p.Spec.Title,
+			Description: p.Spec.Description,
+			URLs:        p.Status.URLs,
+			APIs:        make([]federation.API, 0),
+		}
+
+		for _, a := range p.Gateway.APIs {
+			a := a
+			catalogPortal.APIs = append(catalogPortal.APIs, catalogAPI(&p.Gateway, nil, &a))
+		}
+
+		for _, c := range p.Gateway.Collections {
+			c := c
+			for _, a := range c.APIs {
+				a := a
+				catalogPortal.APIs = append(catalogPortal.APIs, catalogAPI(&p.Gateway, &c, &a))
+			}
+		}
+
+		sort.Slice(catalogPortal.APIs, func(i, j int) bool {
+			apis := catalogPortal.APIs
+			if apis[i].Collection != apis[j].Collection {
+				return apis[i].Collection < apis[j].Collection
+			}
+			if apis[i].Namespace != apis[j].Namespace {
+				return apis[i].Namespace < apis[j].Namespace
+			}
+			return apis[i].Name < apis[j].Name
+		})
+
+		catalog = append(catalog, catalogPortal)
+	}
+
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Name < catalog[j].Name
+	})
+
+	return catalog
+}
+
+func catalogAPI(g *gateway, c *collection, a *hubv1alpha1.API) federation.API {
+	pathPrefix := apiPathPrefix(c, a, nil)
+
+	api := federation.API{
+		Name:       a.Name,
+		Namespace:  a.Namespace,
+		PathPrefix: pathPrefix,
+		BaseURL:    "https://" + gatewayDomains(g)[0] + pathPrefix,
+	}
+	if c != nil {
+		api.Collection = c.Name
+	}
+
+	return api
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/federation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_buildCatalog(t *testing.T) {
+	portals := []portal{
+		{
+			APIPortal: hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "portal"},
+				Spec:       hubv1alpha1.APIPortalSpec{Title: "Portal", Description: "My portal"},
+				Status:     hubv1alpha1.APIPortalStatus{URLs: "https://portal.example.com"},
+			},
+			Gateway: gateway{
+				APIGateway: hubv1alpha1.APIGateway{
+					Status: hubv1alpha1.APIGatewayStatus{
+						HubDomain:     "brave-lion-123.hub-traefik.io",
+						CustomDomains: []string{"api.example.com"},
+					},
+				},
+				APIs: map[string]hubv1alpha1.API{
+					"books@default": {
+						ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default"},
+						Spec:       hubv1alpha1.APISpec{PathPrefix: "/books"},
+					},
+				},
+				Collections: map[string]collection{
+					"library": {
+						APICollection: hubv1alpha1.APICollection{
+							ObjectMeta: metav1.ObjectMeta{Name: "library"},
+							Spec:       hubv1alpha1.APICollectionSpec{PathPrefix: "/library"},
+						},
+						APIs: map[string]hubv1alpha1.API{
+							"authors@default": {
+								ObjectMeta: metav1.ObjectMeta{Name: "authors", Namespace: "default"},
+								Spec:       hubv1alpha1.APISpec{PathPrefix: "/authors"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	want := []federation.Portal{
+		{
+			Name:        "portal",
+			Title:       "Portal",
+			Description: "My portal",
+			URLs:        "https://portal.example.com",
+			APIs: []federation.API{
+				{
+					Name:       "books",
+					Namespace:  "default",
+					PathPrefix: "/books",
+					BaseURL:    "https://api.example.com/books",
+				},
+				{
+					Name:       "authors",
+					Namespace:  "default",
+					Collection: "library",
+					PathPrefix: "/library/authors",
+					BaseURL:    "https://api.example.com/library/authors",
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, want, buildCatalog(portals))
+}
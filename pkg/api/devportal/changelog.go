@@ -0,0 +1,351 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/diff"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// Changelog entry types, in addition to the EventAPIPublished and EventAPIUnpublished event types.
+const (
+	ChangeAPIVersionAdded   = "api.version_added"
+	ChangeAPIVersionRemoved = "api.version_removed"
+	ChangeAPISpecChanged    = "api.spec_changed"
+	ChangeAPIVersionBumped  = "api.version_bumped"
+)
+
+// maxChangelogEntries is the maximum number of entries of the changelog of a portal.
+const maxChangelogEntries = 100
+
+// ChangelogEntry is a change of the API catalog of a portal.
+type ChangelogEntry struct {
+	Type       string    `json:"type"`
+	Collection string    `json:"collection,omitempty"`
+	API        string    `json:"api"`
+	Version    string    `json:"version,omitempty"`
+	Summary    string    `json:"summary"`
+	Breaking   bool      `json:"breaking,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// id returns a stable identifier of the entry, suitable for feeds.
+func (e ChangelogEntry) id() string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d", e.Type, e.Collection, e.API, e.Version, e.OccurredAt.UnixNano())))
+
+	return hex.EncodeToString(hash[:8])
+}
+
+// Changelog keeps track of the changes of the API catalog of each portal: APIs being published or unpublished and API
+// versions being added or removed, as seen by the dev portal, and OpenAPI spec changes, as snapshotted by the controller.
+type Changelog struct {
+	now   func() time.Time
+	store SnapshotStore
+
+	mu sync.Mutex
+	// published and versions hold the state of each portal, the last time the Changelog was given the portals.
+	published map[string]map[publication]struct{}
+	versions  map[string]map[string]map[string]struct{}
+	entries   map[string][]ChangelogEntry
+
+	// reports caches the diffs between spec revisions, indexed by revision pair.
+	reportsMu sync.Mutex
+	reports   map[string]diff.Report
+}
+
+func newChangelog(store SnapshotStore) *Changelog {
+	return &Changelog{
+		now:     time.Now,
+		store:   store,
+		entries: make(map[string][]ChangelogEntry),
+		reports: make(map[string]diff.Report),
+	}
+}
+
+// record records the changes made to the given portals since the last call.
+// The first call only records the state of the portals, as no change can be detected yet.
+func (c *Changelog) record(portals []portal) {
+	now := c.now()
+
+	published := make(map[string]map[publication]struct{}, len(portals))
+	versions := make(map[string]map[string]map[string]struct{}, len(portals))
+	for _, p := range portals {
+		published[p.Name] = publications(p)
+		versions[p.Name] = apiVersions(p)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.published != nil {
+		entries := make(map[string][]ChangelogEntry, len(portals))
+		for _, p := range portals {
+			recorded := c.entries[p.Name]
+
+			for _, event := range diffPublications(p.Name, c.published[p.Name], published[p.Name], now) {
+				recorded = append(recorded, publicationEntry(event))
+			}
+			recorded = append(recorded, diffVersions(c.versions[p.Name], versions[p.Name], now)...)
+
+			if len(recorded) > maxChangelogEntries {
+				recorded = recorded[len(recorded)-maxChangelogEntries:]
+			}
+			entries[p.Name] = recorded
+		}
+		c.entries = entries
+	}
+
+	c.published = published
+	c.versions = versions
+}
+
+// Entries returns the entries of the changelog of the given portal, from the newest to the oldest.
+func (c *Changelog) Entries(p *portal) []ChangelogEntry {
+	c.mu.Lock()
+	entries := append([]ChangelogEntry{}, c.entries[p.Name]...)
+	c.mu.Unlock()
+
+	entries = append(entries, c.specEntries(p)...)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].OccurredAt.Equal(entries[j].OccurredAt) {
+			return entries[i].OccurredAt.After(entries[j].OccurredAt)
+		}
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type < entries[j].Type
+		}
+		return entries[i].API < entries[j].API
+	})
+
+	if len(entries) > maxChangelogEntries {
+		entries = entries[:maxChangelogEntries]
+	}
+
+	return entries
+}
+
+// specEntries returns the entries of the OpenAPI spec changes of the APIs of the given portal, found in the snapshots.
+func (c *Changelog) specEntries(p *portal) []ChangelogEntry {
+	if c.store == nil {
+		return nil
+	}
+
+	apis := make(map[string]hubv1alpha1.API)
+	for apiNameNamespace, a := range p.Gateway.APIs {
+		apis[apiNameNamespace] = a
+	}
+	for _, coll := range p.Gateway.Collections {
+		for apiNameNamespace, a := range coll.APIs {
+			apis[apiNameNamespace] = a
+		}
+	}
+
+	var entries []ChangelogEntry
+	for apiNameNamespace, a := range apis {
+		snapshots, err := c.store.Snapshots(a.Name, a.Namespace)
+		if err != nil {
+			log.Error().Err(err).
+				Str("api_name", a.Name).
+				Str("api_namespace", a.Namespace).
+				Msg("Unable to get OpenAPI spec snapshots")
+			continue
+		}
+
+		for i := 1; i < len(snapshots); i++ {
+			report, err := c.report(snapshots[i-1].Revision, snapshots[i-1].Spec, snapshots[i].Revision, snapshots[i].Spec)
+			if err != nil {
+				log.Debug().Err(err).
+					Str("api_name", a.Name).
+					Str("api_namespace", a.Namespace).
+					Msg("Unable to compare OpenAPI spec snapshots")
+				continue
+			}
+
+			entries = append(entries, specEntries(apiNameNamespace, report, snapshots[i].Timestamp)...)
+		}
+	}
+
+	return entries
+}
+
+// report returns the diff between the given revisions of a spec.
+func (c *Changelog) report(baseRevision string, base []byte, revision string, raw []byte) (diff.Report, error) {
+	key := baseRevision + ":" + revision
+
+	c.reportsMu.Lock()
+	report, ok := c.reports[key]
+	c.reportsMu.Unlock()
+	if ok {
+		return report, nil
+	}
+
+	baseSpec, err := openapi3.NewLoader().LoadFromData(base)
+	if err != nil {
+		return diff.Report{}, fmt.Errorf("load OpenAPI spec %q: %w", baseRevision, err)
+	}
+	spec, err := openapi3.NewLoader().LoadFromData(raw)
+	if err != nil {
+		return diff.Report{}, fmt.Errorf("load OpenAPI spec %q: %w", revision, err)
+	}
+
+	report = diff.Compare(baseSpec, spec)
+
+	c.reportsMu.Lock()
+	c.reports[key] = report
+	c.reportsMu.Unlock()
+
+	return report, nil
+}
+
+func specEntries(apiNameNamespace string, report diff.Report, occurredAt time.Time) []ChangelogEntry {
+	var breaking int
+	for _, change := range report.Changes {
+		if change.Kind == diff.KindBreaking {
+			breaking++
+		}
+	}
+
+	entries := []ChangelogEntry{{
+		Type:       ChangeAPISpecChanged,
+		API:        apiNameNamespace,
+		Summary:    fmt.Sprintf("API %s spec changed: %d changes, %d breaking", apiNameNamespace, len(report.Changes), breaking),
+		Breaking:   report.Breaking,
+		OccurredAt: occurredAt,
+	}}
+
+	for _, change := range report.Changes {
+		if change.Path != "info.version" {
+			continue
+		}
+
+		entries = append(entries, ChangelogEntry{
+			Type:       ChangeAPIVersionBumped,
+			API:        apiNameNamespace,
+			Summary:    fmt.Sprintf("API %s: %s", apiNameNamespace, change.Message),
+			OccurredAt: occurredAt,
+		})
+	}
+
+	return entries
+}
+
+func publicationEntry(event Event) ChangelogEntry {
+	action := "published"
+	if event.Type == EventAPIUnpublished {
+		action = "unpublished"
+	}
+
+	summary := fmt.Sprintf("API %s %s", event.API, action)
+	if event.Collection != "" {
+		summary = fmt.Sprintf("API %s %s in collection %s", event.API, action, event.Collection)
+	}
+
+	return ChangelogEntry{
+		Type:       event.Type,
+		Collection: event.Collection,
+		API:        event.API,
+		Summary:    summary,
+		OccurredAt: event.OccurredAt,
+	}
+}
+
+// diffVersions returns the entries of the versions added or removed on the APIs published both before and now.
+func diffVersions(previous, current map[string]map[string]struct{}, now time.Time) []ChangelogEntry {
+	var entries []ChangelogEntry
+	for apiNameNamespace, currentVersions := range current {
+		previousVersions, ok := previous[apiNameNamespace]
+		if !ok {
+			continue
+		}
+
+		for version := range currentVersions {
+			if _, ok = previousVersions[version]; !ok {
+				entries = append(entries, ChangelogEntry{
+					Type:       ChangeAPIVersionAdded,
+					API:        apiNameNamespace,
+					Version:    version,
+					Summary:    fmt.Sprintf("API %s version %s added", apiNameNamespace, version),
+					OccurredAt: now,
+				})
+			}
+		}
+		for version := range previousVersions {
+			if _, ok = currentVersions[version]; !ok {
+				entries = append(entries, ChangelogEntry{
+					Type:       ChangeAPIVersionRemoved,
+					API:        apiNameNamespace,
+					Version:    version,
+					Summary:    fmt.Sprintf("API %s version %s removed", apiNameNamespace, version),
+					OccurredAt: now,
+				})
+			}
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].API != entries[j].API {
+			return entries[i].API < entries[j].API
+		}
+		return entries[i].Version < entries[j].Version
+	})
+
+	return entries
+}
+
+// apiVersions returns the versions of each API published on the given portal.
+func apiVersions(p portal) map[string]map[string]struct{} {
+	versions := make(map[string]map[string]struct{})
+
+	addVersions := func(apiNameNamespace string, names []string) {
+		if _, ok := versions[apiNameNamespace]; !ok {
+			versions[apiNameNamespace] = make(map[string]struct{})
+		}
+		for _, name := range names {
+			versions[apiNameNamespace][name] = struct{}{}
+		}
+	}
+
+	for apiNameNamespace, a := range p.Gateway.APIs {
+		addVersions(apiNameNamespace, versionNames(a.Spec.Versions))
+	}
+	for _, coll := range p.Gateway.Collections {
+		for apiNameNamespace, a := range coll.APIs {
+			addVersions(apiNameNamespace, versionNames(a.Spec.Versions))
+		}
+	}
+
+	return versions
+}
+
+func versionNames(versions []hubv1alpha1.APIVersion) []string {
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		names = append(names, v.Name)
+	}
+
+	return names
+}
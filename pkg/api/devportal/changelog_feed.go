@@ -0,0 +1,122 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title    string  `xml:"title"`
+	Category string  `xml:"category"`
+	GUID     rssGUID `xml:"guid"`
+	PubDate  string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title    string       `xml:"title"`
+	ID       string       `xml:"id"`
+	Updated  string       `xml:"updated"`
+	Category atomCategory `xml:"category"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// newRSSFeed builds an RSS 2.0 feed of the given changelog entries of the portal served at the given URL.
+func newRSSFeed(portalTitle, portalURL string, entries []ChangelogEntry) rssFeed {
+	items := make([]rssItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, rssItem{
+			Title:    entry.Summary,
+			Category: entry.Type,
+			GUID:     rssGUID{Value: "urn:hub:changelog:" + entry.id()},
+			PubDate:  entry.OccurredAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       portalTitle + " changelog",
+			Link:        portalURL,
+			Description: "Changes of the APIs published on " + portalTitle,
+			Items:       items,
+		},
+	}
+}
+
+// newAtomFeed builds an Atom feed of the given changelog entries of the portal served at the given URL.
+func newAtomFeed(portalTitle, portalURL string, entries []ChangelogEntry) atomFeed {
+	// The feed is updated when its newest entry occurred, entries being sorted from the newest to the oldest.
+	var updated time.Time
+	if len(entries) > 0 {
+		updated = entries[0].OccurredAt
+	}
+
+	atomEntries := make([]atomEntry, 0, len(entries))
+	for _, entry := range entries {
+		atomEntries = append(atomEntries, atomEntry{
+			Title:    entry.Summary,
+			ID:       "urn:hub:changelog:" + entry.id(),
+			Updated:  entry.OccurredAt.UTC().Format(time.RFC3339),
+			Category: atomCategory{Term: entry.Type},
+		})
+	}
+
+	return atomFeed{
+		Title:   portalTitle + " changelog",
+		ID:      portalURL,
+		Link:    atomLink{Href: portalURL},
+		Updated: updated.UTC().Format(time.RFC3339),
+		Entries: atomEntries,
+	}
+}
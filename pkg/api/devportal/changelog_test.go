@@ -0,0 +1,198 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestChangelog_Entries(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshotAt := now.Add(-time.Hour)
+
+	baseSpec := []byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {"/books": {"get": {"responses": {"200": {"description": "OK"}}}}}}`)
+	spec := []byte(`{"openapi": "3.0.0", "info": {"title": "Books", "version": "2.0.0"}, "paths": {}}`)
+	store := snapshotStoreFunc(func(apiName, apiNamespace string) ([]api.SpecSnapshot, error) {
+		if apiName != "books" || apiNamespace != "default" {
+			return nil, nil
+		}
+
+		return []api.SpecSnapshot{
+			{Revision: api.SpecRevision(baseSpec), Spec: baseSpec},
+			{Revision: api.SpecRevision(spec), Spec: spec, Timestamp: snapshotAt},
+		}, nil
+	})
+
+	changelog := newChangelog(store)
+	changelog.now = func() time.Time { return now }
+
+	// The first call records the state of the portals without any entry.
+	changelog.record([]portal{newChangelogPortal(map[string][]string{
+		"books@default": nil,
+		"users@default": {"v1"},
+	})})
+
+	p := newChangelogPortal(map[string][]string{
+		"books@default":  nil,
+		"users@default":  {"v2"},
+		"orders@default": nil,
+	})
+	changelog.record([]portal{p})
+
+	got := changelog.Entries(&p)
+
+	want := []ChangelogEntry{
+		{Type: EventAPIPublished, API: "orders@default", Summary: "API orders@default published", OccurredAt: now},
+		{Type: ChangeAPIVersionAdded, API: "users@default", Version: "v2", Summary: "API users@default version v2 added", OccurredAt: now},
+		{Type: ChangeAPIVersionRemoved, API: "users@default", Version: "v1", Summary: "API users@default version v1 removed", OccurredAt: now},
+		{Type: ChangeAPISpecChanged, API: "books@default", Summary: "API books@default spec changed: 2 changes, 1 breaking", Breaking: true, OccurredAt: snapshotAt},
+		{Type: ChangeAPIVersionBumped, API: "books@default", Summary: `API books@default: Version changed from "1.0.0" to "2.0.0"`, OccurredAt: snapshotAt},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestPortalAPI_Router_changelog(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p := newChangelogPortal(map[string][]string{"users@default": nil})
+	p.Status.HubDomain = "majestic-beaver-123.hub-traefik.io"
+
+	changelog := newChangelog(nil)
+	changelog.now = func() time.Time { return now }
+	changelog.record([]portal{newChangelogPortal(nil)})
+	changelog.record([]portal{p})
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.changelog = changelog
+
+	tests := []struct {
+		desc            string
+		format          string
+		wantStatusCode  int
+		wantContentType string
+		check           func(t *testing.T, body []byte)
+	}{
+		{
+			desc:            "JSON by default",
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				assert.JSONEq(t, `{
+					"entries": [
+						{"type": "api.published", "api": "users@default", "summary": "API users@default published", "occurredAt": "2023-01-01T00:00:00Z"}
+					]
+				}`, string(body))
+			},
+		},
+		{
+			desc:            "RSS",
+			format:          "rss",
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/rss+xml",
+			check: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				var feed rssFeed
+				require.NoError(t, xml.Unmarshal(body, &feed))
+
+				assert.Equal(t, "https://majestic-beaver-123.hub-traefik.io/", feed.Channel.Link)
+				require.Len(t, feed.Channel.Items, 1)
+				assert.Equal(t, "API users@default published", feed.Channel.Items[0].Title)
+				assert.Equal(t, "Sun, 01 Jan 2023 00:00:00 +0000", feed.Channel.Items[0].PubDate)
+			},
+		},
+		{
+			desc:            "Atom",
+			format:          "atom",
+			wantStatusCode:  http.StatusOK,
+			wantContentType: "application/atom+xml",
+			check: func(t *testing.T, body []byte) {
+				t.Helper()
+
+				var feed atomFeed
+				require.NoError(t, xml.Unmarshal(body, &feed))
+
+				assert.Equal(t, "2023-01-01T00:00:00Z", feed.Updated)
+				require.Len(t, feed.Entries, 1)
+				assert.Equal(t, "API users@default published", feed.Entries[0].Title)
+				assert.Equal(t, "api.published", feed.Entries[0].Category.Term)
+			},
+		},
+		{
+			desc:           "unsupported format",
+			format:         "csv",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rw := httptest.NewRecorder()
+			a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/changelog?format="+test.format, http.NoBody))
+
+			require.Equal(t, test.wantStatusCode, rw.Code)
+			if test.check == nil {
+				return
+			}
+
+			assert.Equal(t, test.wantContentType, rw.Header().Get("Content-Type"))
+			test.check(t, rw.Body.Bytes())
+		})
+	}
+}
+
+func newChangelogPortal(apis map[string][]string) portal {
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-portal"},
+		},
+		Gateway: gateway{
+			APIs:        make(map[string]hubv1alpha1.API),
+			Collections: make(map[string]collection),
+		},
+	}
+
+	for apiNameNamespace, versions := range apis {
+		var a hubv1alpha1.API
+		a.Name, a.Namespace, _ = strings.Cut(apiNameNamespace, "@")
+		for _, version := range versions {
+			a.Spec.Versions = append(a.Spec.Versions, hubv1alpha1.APIVersion{Name: version})
+		}
+		p.Gateway.APIs[apiNameNamespace] = a
+	}
+
+	return p
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// specLocations keeps track of the well-known paths at which the API services were found serving their OpenAPI spec.
+type specLocations struct {
+	mu sync.RWMutex
+	// paths are the discovered paths, indexed by API service base URL.
+	paths map[string]string
+}
+
+func newSpecLocations() *specLocations {
+	return &specLocations{paths: make(map[string]string)}
+}
+
+func (l *specLocations) get(serviceURL string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	path, ok := l.paths[serviceURL]
+
+	return path, ok
+}
+
+func (l *specLocations) set(serviceURL, path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.paths[serviceURL] = path
+}
+
+// discoverOpenAPISpec fetches the raw OpenAPI spec of the given API from the first well-known path of its service
+// serving one. The path previously discovered for the service, if any, is tried first.
+func (p *PortalAPI) discoverOpenAPISpec(ctx context.Context, a *hubv1alpha1.API, source hubv1alpha1.OpenAPISpecSource) ([]byte, error) {
+	serviceURL, err := api.OpenAPISpecURL(a, source)
+	if err != nil {
+		return nil, fmt.Errorf("get OpenAPI spec URL: %w", err)
+	}
+
+	paths := api.WellKnownOpenAPISpecPaths()
+	if known, ok := p.specLocations.get(serviceURL.String()); ok {
+		paths = append([]string{known}, paths...)
+	}
+
+	var errs []error
+	for _, path := range paths {
+		source.Path = path
+
+		rawSpec, err := p.fetchOpenAPISpecSource(ctx, a, source)
+		if err == nil && !api.IsOpenAPISpec(rawSpec) {
+			err = fmt.Errorf("no OpenAPI spec served at %q", path)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		log.Ctx(ctx).Debug().Str("path", path).Msg("OpenAPI spec discovered")
+		p.specLocations.set(serviceURL.String(), path)
+
+		return rawSpec, nil
+	}
+
+	return nil, fmt.Errorf("discover OpenAPI spec: %w", errors.Join(errs...))
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPortalAPI_Router_getAPISpec_discovery(t *testing.T) {
+	spec, err := os.ReadFile("./testdata/openapi/spec.json")
+	require.NoError(t, err)
+
+	var (
+		callsMu sync.Mutex
+		calls   []string
+	)
+	svcSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		callsMu.Lock()
+		calls = append(calls, r.URL.Path)
+		callsMu.Unlock()
+
+		switch r.URL.Path {
+		case "/openapi.json":
+			// Single page applications commonly serve their index on any path.
+			_, _ = rw.Write([]byte("<!DOCTYPE html><html><body>Books</body></html>"))
+		case "/v3/api-docs":
+			_, _ = rw.Write(spec)
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(svcSrv.Close)
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIGateway: hubv1alpha1.APIGateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-gateway"},
+				Status:     hubv1alpha1.APIGatewayStatus{HubDomain: "majestic-beaver-123.hub-traefik.io"},
+			},
+			APIs: map[string]hubv1alpha1.API{
+				"books@my-ns": {
+					ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "my-ns"},
+					Spec: hubv1alpha1.APISpec{
+						PathPrefix: "/books",
+						Service: hubv1alpha1.APIService{
+							Name: "books-svc",
+							Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	a.httpClient = buildProxyClient(t, svcSrv.URL)
+
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/books@my-ns", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, []string{"/openapi.json", "/swagger.json", "/v3/api-docs"}, calls)
+
+	// The discovered location outlives the portal handlers, the spec is directly fetched from it.
+	other, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+	other.httpClient = buildProxyClient(t, svcSrv.URL)
+	other.specLocations = a.specLocations
+
+	calls = nil
+	rw = httptest.NewRecorder()
+	other.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/books@my-ns", http.NoBody))
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, []string{"/v3/api-docs"}, calls)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// Default bounds of the examples served, when not configured on the APIGateway.
+const (
+	defaultMaxExamplesPerOperation = 5
+	defaultMaxExampleBodySize      = 4096
+)
+
+// redactedValue replaces the values redacted from the examples.
+const redactedValue = "REDACTED"
+
+// redactedHeaders returns the headers always redacted from the examples, as they carry credentials or cookies.
+func redactedHeaders() []string {
+	return []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+}
+
+// redactedQueryParams returns the query parameters always redacted from the examples, as they carry credentials.
+func redactedQueryParams() []string {
+	return []string{"api_key", "access_token", "token"}
+}
+
+// redactedFields returns the JSON body fields always redacted from the examples. Field names are compared once
+// normalized, see normalizeFieldName.
+func redactedFields() []string {
+	return []string{"password", "secret", "token", "accesstoken", "refreshtoken", "apikey", "clientsecret", "authorization"}
+}
+
+// ExampleSource provides the request/response examples captured by the gateways for the APIs they expose.
+type ExampleSource interface {
+	ListAPIExamples(ctx context.Context, query api.ExampleQuery) ([]api.APIExample, error)
+}
+
+type examplesResp struct {
+	Operations []operationExamples `json:"operations"`
+}
+
+type operationExamples struct {
+	Method   string           `json:"method"`
+	Path     string           `json:"path"`
+	Examples []api.APIExample `json:"examples"`
+}
+
+// buildExamplesResp groups the given examples by operation, keeping the most recent ones within the bounds configured
+// by the given capture configuration, and scrubs them from credentials and secrets. The gateway is expected to have
+// scrubbed them already, the portal does it again so misbehaving captures never leak to the consumers.
+func buildExamplesResp(examples []api.APIExample, capture *hubv1alpha1.APIGatewayExampleCapture) examplesResp {
+	maxExamples := defaultMaxExamplesPerOperation
+	if capture.MaxExamplesPerOperation > 0 {
+		maxExamples = capture.MaxExamplesPerOperation
+	}
+	maxBodySize := defaultMaxExampleBodySize
+	if capture.MaxBodySize != nil {
+		maxBodySize = *capture.MaxBodySize
+	}
+
+	s := newScrubber(capture.RedactedHeaders, capture.RedactedFields, maxBodySize)
+
+	sort.SliceStable(examples, func(i, j int) bool {
+		return examples[i].CapturedAt.After(examples[j].CapturedAt)
+	})
+
+	resp := examplesResp{Operations: []operationExamples{}}
+	operations := make(map[string]int)
+	for _, example := range examples {
+		key := strings.ToUpper(example.Method) + " " + example.Path
+
+		i, ok := operations[key]
+		if !ok {
+			i = len(resp.Operations)
+			operations[key] = i
+			resp.Operations = append(resp.Operations, operationExamples{
+				Method: strings.ToUpper(example.Method),
+				Path:   example.Path,
+			})
+		}
+
+		if len(resp.Operations[i].Examples) >= maxExamples {
+			continue
+		}
+		resp.Operations[i].Examples = append(resp.Operations[i].Examples, s.scrub(example))
+	}
+
+	sort.Slice(resp.Operations, func(i, j int) bool {
+		if resp.Operations[i].Path != resp.Operations[j].Path {
+			return resp.Operations[i].Path < resp.Operations[j].Path
+		}
+		return resp.Operations[i].Method < resp.Operations[j].Method
+	})
+
+	return resp
+}
+
+// scrubber redacts credentials and secrets from examples and truncates their bodies.
+type scrubber struct {
+	headers     map[string]struct{}
+	fields      map[string]struct{}
+	maxBodySize int
+}
+
+func newScrubber(headers, fields []string, maxBodySize int) scrubber {
+	s := scrubber{
+		headers:     make(map[string]struct{}),
+		fields:      make(map[string]struct{}),
+		maxBodySize: maxBodySize,
+	}
+
+	for _, header := range append(redactedHeaders(), headers...) {
+		s.headers[strings.ToLower(header)] = struct{}{}
+	}
+	for _, field := range append(redactedFields(), fields...) {
+		s.fields[normalizeFieldName(field)] = struct{}{}
+	}
+
+	return s
+}
+
+func (s scrubber) scrub(example api.APIExample) api.APIExample {
+	example.Request.URL = scrubURL(example.Request.URL)
+	example.Request.Headers = s.scrubHeaders(example.Request.Headers)
+	example.Request.Body = s.scrubBody(example.Request.Body)
+	example.Response.Headers = s.scrubHeaders(example.Response.Headers)
+	example.Response.Body = s.scrubBody(example.Response.Body)
+
+	return example
+}
+
+func (s scrubber) scrubHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+
+	scrubbed := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if _, ok := s.headers[strings.ToLower(name)]; ok {
+			value = redactedValue
+		}
+		scrubbed[name] = value
+	}
+
+	return scrubbed
+}
+
+// scrubBody redacts the secret fields of JSON bodies and truncates bodies to the maximum body size. Bodies which
+// aren't JSON are only truncated.
+func (s scrubber) scrubBody(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err == nil {
+		if scrubbed, err := json.Marshal(s.scrubValue(value)); err == nil {
+			body = string(scrubbed)
+		}
+	}
+
+	if len(body) <= s.maxBodySize {
+		return body
+	}
+
+	// Drop the runes cut by the truncation.
+	body = body[:s.maxBodySize]
+	for len(body) > 0 && !utf8.ValidString(body) {
+		body = body[:len(body)-1]
+	}
+
+	return body
+}
+
+func (s scrubber) scrubValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := s.fields[normalizeFieldName(key)]; ok {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = s.scrubValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.scrubValue(item)
+		}
+	}
+
+	return value
+}
+
+// scrubURL redacts the query parameters carrying credentials from the given URL.
+func scrubURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// Unparsable URLs can't be safely served.
+		return ""
+	}
+
+	query := u.Query()
+	var redacted bool
+	for _, param := range redactedQueryParams() {
+		if query.Has(param) {
+			query.Set(param, redactedValue)
+			redacted = true
+		}
+	}
+	if redacted {
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+// normalizeFieldName normalizes the given field name for "api_key", "apiKey" and "Api-Key" to match.
+func normalizeFieldName(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+)
+
+// GroupSource fetches the groups pushed by the identity provider through SCIM.
+type GroupSource interface {
+	GetGroups(ctx context.Context) ([]api.Group, error)
+}
+
+// GroupDirectory holds the groups pushed by the identity provider through SCIM, indexed by member. Members are
+// identified by their display value, which holds the email of the user. Once groups have been pushed, the directory is
+// the only source of groups: users it doesn't know, like the ones removed from every group, have no groups whatever the
+// groups identity header holds. Until then, when groups aren't provisioned through SCIM, the header is used.
+type GroupDirectory struct {
+	source GroupSource
+
+	// members is nil until groups have been pushed.
+	members atomic.Pointer[map[string][]string]
+
+	logger zerolog.Logger
+}
+
+// NewGroupDirectory creates a new GroupDirectory reading the groups from the given source.
+func NewGroupDirectory(source GroupSource, logger zerolog.Logger) *GroupDirectory {
+	return &GroupDirectory{source: source, logger: logger}
+}
+
+// Run fetches the groups every interval until the given context is done.
+func (d *GroupDirectory) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.refresh(ctx); err != nil {
+			d.logger.Error().Err(err).Msg("Unable to refresh the group directory")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *GroupDirectory) refresh(ctx context.Context) error {
+	groups, err := d.source.GetGroups(ctx)
+	if err != nil {
+		return fmt.Errorf("get groups: %w", err)
+	}
+
+	if len(groups) == 0 {
+		d.members.Store(nil)
+		return nil
+	}
+
+	members := make(map[string][]string)
+	for _, group := range groups {
+		for _, member := range group.Members {
+			if member.Display == "" {
+				continue
+			}
+
+			email := strings.ToLower(member.Display)
+			members[email] = append(members[email], group.Name)
+		}
+	}
+	for _, memberGroups := range members {
+		sort.Strings(memberGroups)
+	}
+
+	d.members.Store(&members)
+
+	return nil
+}
+
+// Groups returns the groups of the user with the given email, and whether they are given by the directory, which is
+// the case as soon as groups have been pushed, even if the user isn't a member of any of them.
+func (d *GroupDirectory) Groups(email string) ([]string, bool) {
+	if d == nil || email == "" {
+		return nil, false
+	}
+
+	members := d.members.Load()
+	if members == nil {
+		return nil, false
+	}
+
+	return (*members)[strings.ToLower(email)], true
+}
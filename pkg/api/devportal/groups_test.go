@@ -0,0 +1,118 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+)
+
+type groupSourceFunc func(ctx context.Context) ([]api.Group, error)
+
+func (f groupSourceFunc) GetGroups(ctx context.Context) ([]api.Group, error) {
+	return f(ctx)
+}
+
+func TestGroupDirectory_Groups(t *testing.T) {
+	groups := []api.Group{
+		{
+			Name: "developers",
+			Members: []api.GroupMember{
+				{ID: "1", Display: "John@example.com"},
+				{ID: "2", Display: "jane@example.com"},
+			},
+		},
+		{
+			Name: "admins",
+			Members: []api.GroupMember{
+				{ID: "1", Display: "john@example.com"},
+				{ID: "3"},
+			},
+		},
+		{Name: "empty"},
+	}
+
+	dir := NewGroupDirectory(groupSourceFunc(func(_ context.Context) ([]api.Group, error) {
+		return groups, nil
+	}), zerolog.Nop())
+
+	_, ok := dir.Groups("john@example.com")
+	assert.False(t, ok)
+
+	require.NoError(t, dir.refresh(context.Background()))
+
+	got, ok := dir.Groups("JOHN@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"admins", "developers"}, got)
+
+	got, ok = dir.Groups("jane@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"developers"}, got)
+
+	// Users removed from every group have no groups.
+	got, ok = dir.Groups("bob@example.com")
+	assert.True(t, ok)
+	assert.Empty(t, got)
+
+	_, ok = dir.Groups("")
+	assert.False(t, ok)
+}
+
+func TestGroupDirectory_refresh_keepsGroupsOnError(t *testing.T) {
+	var fail bool
+	dir := NewGroupDirectory(groupSourceFunc(func(_ context.Context) ([]api.Group, error) {
+		if fail {
+			return nil, errors.New("boom")
+		}
+
+		return []api.Group{{Name: "developers", Members: []api.GroupMember{{ID: "1", Display: "john@example.com"}}}}, nil
+	}), zerolog.Nop())
+
+	require.NoError(t, dir.refresh(context.Background()))
+
+	fail = true
+	require.Error(t, dir.refresh(context.Background()))
+
+	got, ok := dir.Groups("john@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"developers"}, got)
+}
+
+func TestGroupDirectory_Groups_noGroups(t *testing.T) {
+	dir := NewGroupDirectory(groupSourceFunc(func(_ context.Context) ([]api.Group, error) {
+		return nil, nil
+	}), zerolog.Nop())
+
+	require.NoError(t, dir.refresh(context.Background()))
+
+	_, ok := dir.Groups("john@example.com")
+	assert.False(t, ok)
+}
+
+func TestGroupDirectory_Groups_nil(t *testing.T) {
+	var dir *GroupDirectory
+
+	_, ok := dir.Groups("john@example.com")
+	assert.False(t, ok)
+}
@@ -23,19 +23,60 @@ import (
 	"sync"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
-// Handler exposes both an API and a UI for a set of APIPortals.
+// Handler exposes both an API and a UI for a set of APIPortals from a single listener.
+// The APIPortal is resolved from the request Host, each APIPortal being served by its own handlers.
 // The handler can be safely updated to support more APIPortals as they come and go.
 type Handler struct {
 	handlerMu sync.RWMutex
 	handler   http.Handler
+
+	ruleset       lint.Ruleset
+	httpClient    *http.Client
+	history       *specHistory
+	specLocations *specLocations
+	configMaps    corev1client.ConfigMapsGetter
+	sdkGenerator  *SDKGenerator
+	usage         UsageSource
+	examples      ExampleSource
+	terms         TermsRecorder
+	changelog     *Changelog
+	identity      *IdentityVerifier
+	groups        *GroupDirectory
+	logger        zerolog.Logger
 }
 
-// NewHandler builds a new instance of Handler.
-func NewHandler() *Handler {
+// NewHandler builds a new instance of Handler. The OpenAPI specs served are linted using the given ruleset and
+// compared against their previous revisions, found in memory or in the given snapshot store, which may be nil.
+// OpenAPI specs referencing ConfigMaps are read using the given getter, which may be nil to disable them.
+// Client SDKs are generated using the given generator, which may be nil to disable SDK downloads. The usage metrics,
+// captured examples and terms of service acceptances of the portal users go through the given platform client, which
+// may be nil to disable them. OpenAPI specs are fetched using the given transport, shared across updates so
+// connections to the pods serving them are reused, or a default pooled transport when nil. The identity headers of the
+// requests are verified against the identity token using the given verifier, which may be nil to trust them as is. The
+// groups of the users are read from the given directory, which may be nil to only rely on the groups header. The
+// portal APIs log using the given logger.
+func NewHandler(ruleset lint.Ruleset, snapshots SnapshotStore, configMaps corev1client.ConfigMapsGetter, sdkGenerator *SDKGenerator, platformClient PlatformClient, transport http.RoundTripper, identity *IdentityVerifier, groups *GroupDirectory, logger zerolog.Logger) *Handler {
 	return &Handler{
-		handler: http.NotFoundHandler(),
+		handler:       http.NotFoundHandler(),
+		ruleset:       ruleset,
+		httpClient:    newSpecClient(transport),
+		history:       newSpecHistory(snapshots),
+		specLocations: newSpecLocations(),
+		configMaps:    configMaps,
+		sdkGenerator:  sdkGenerator,
+		usage:         platformClient,
+		examples:      platformClient,
+		terms:         platformClient,
+		changelog:     newChangelog(snapshots),
+		identity:      identity,
+		groups:        groups,
+		logger:        logger,
 	}
 }
 
@@ -47,31 +88,72 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	handler.ServeHTTP(rw, req)
 }
 
-// Update safely updates the current http.ServeMux with a new one built for serving the given portals.
+// Update safely updates the current handler with a new one built for serving the given portals.
 func (h *Handler) Update(portals []portal) error {
-	router := chi.NewRouter()
+	uiHandler, err := NewPortalUI(portals)
+	if err != nil {
+		return fmt.Errorf("create portal UI handler: %w", err)
+	}
 
+	hosts := make(map[string]http.Handler)
 	for _, p := range portals {
 		p := p
 
-		apiHandler, err := NewPortalAPI(&p)
+		apiHandler, err := NewPortalAPI(&p, h.ruleset)
 		if err != nil {
 			return fmt.Errorf("create portal %q API handler: %w", p.Name, err)
 		}
-
+		// Share the spec client across updates for connections to be reused.
+		apiHandler.httpClient = h.httpClient
+		// Share the history across updates for the spec snapshots to outlive the portal handlers.
+		apiHandler.history = h.history
+		apiHandler.specLocations = h.specLocations
+		apiHandler.configMaps = h.configMaps
+		apiHandler.sdkGenerator = h.sdkGenerator
+		apiHandler.usage = h.usage
+		apiHandler.examples = h.examples
+		apiHandler.terms = h.terms
+		apiHandler.changelog = h.changelog
+		apiHandler.groups = h.groups
+		apiHandler.logger = h.logger
+
+		router := chi.NewRouter()
 		router.Mount("/api/"+p.Name, apiHandler)
-	}
+		// Prevent the APIs of the other portals from being served as UI pages.
+		router.Handle("/api/*", http.NotFoundHandler())
+		router.Mount("/", uiHandler)
+
+		var portalHandler http.Handler = router
+		if h.identity != nil {
+			// Each portal is verified against the identity headers configured on its gateway.
+			portalHandler = h.identity.Wrap(router, p.Gateway.identityHeaders())
+		}
 
-	uiHandler, err := NewPortalUI(portals)
-	if err != nil {
-		return fmt.Errorf("create portal UI handler: %w", err)
+		for _, customDomain := range p.Status.CustomDomains {
+			hosts[customDomain] = portalHandler
+		}
+		hosts[p.Status.HubDomain] = portalHandler
 	}
 
-	router.Mount("/", uiHandler)
-
 	h.handlerMu.Lock()
-	h.handler = router
+	h.handler = hostMux(hosts)
 	h.handlerMu.Unlock()
 
 	return nil
 }
+
+// hostMux routes requests to the handler registered for their Host.
+type hostMux map[string]http.Handler
+
+func (m hostMux) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	host := stripHostPort(req.Host)
+
+	handler, ok := m[host]
+	if !ok {
+		log.Debug().Str("host", host).Msg("APIPortal not found for host")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	handler.ServeHTTP(rw, req)
+}
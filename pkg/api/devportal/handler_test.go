@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	portals := []portal{
+		{
+			APIPortal: hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "external-portal"},
+				Status: hubv1alpha1.APIPortalStatus{
+					HubDomain:     "majestic-beaver-123.hub-traefik.io",
+					CustomDomains: []string{"external.example.com"},
+				},
+			},
+		},
+		{
+			APIPortal: hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "internal-portal"},
+				Status: hubv1alpha1.APIPortalStatus{
+					HubDomain: "majestic-cat-123.hub-traefik.io",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc       string
+		host       string
+		path       string
+		wantStatus int
+	}{
+		{
+			desc:       "portal API through its custom domain",
+			host:       "external.example.com",
+			path:       "/api/external-portal/apis",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "portal API through its hub domain",
+			host:       "majestic-beaver-123.hub-traefik.io:443",
+			path:       "/api/external-portal/apis",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "portal UI",
+			host:       "majestic-cat-123.hub-traefik.io",
+			path:       "/",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "API of another portal",
+			host:       "majestic-cat-123.hub-traefik.io",
+			path:       "/api/external-portal/apis",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "unknown host",
+			host:       "unknown.example.com",
+			path:       "/api/external-portal/apis",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	handler := NewHandler(lint.DefaultRuleset(), nil, nil, nil, nil, nil, nil, nil, zerolog.Nop())
+	err := handler.Update(portals)
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, test.path, http.NoBody)
+			req.Host = test.host
+
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.wantStatus, rw.Code)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// maxSnapshots is the maximum number of snapshots kept in memory for each OpenAPI spec.
+const maxSnapshots = 10
+
+// SnapshotStore gives access to the snapshots of the OpenAPI specs of the APIs taken by the controller.
+type SnapshotStore interface {
+	Snapshots(apiName, apiNamespace string) ([]api.SpecSnapshot, error)
+}
+
+// specHistory keeps the last distinct revisions of the OpenAPI specs fetched by the agent, indexed by URL.
+// Revisions which aren't known in memory are looked up in the snapshots taken by the controller, if any.
+type specHistory struct {
+	mu        sync.Mutex
+	snapshots map[string][]snapshot
+
+	store SnapshotStore
+}
+
+type snapshot struct {
+	revision string
+	raw      []byte
+}
+
+func newSpecHistory(store SnapshotStore) *specHistory {
+	return &specHistory{
+		snapshots: make(map[string][]snapshot),
+		store:     store,
+	}
+}
+
+// record records the given raw spec fetched from the given URL, unless it's the same as the last recorded one.
+// It returns the revision of the spec.
+func (h *specHistory) record(specURL string, raw []byte) string {
+	rev := api.SpecRevision(raw)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshots := h.snapshots[specURL]
+	if len(snapshots) > 0 && snapshots[len(snapshots)-1].revision == rev {
+		return rev
+	}
+
+	snapshots = append(snapshots, snapshot{revision: rev, raw: raw})
+	if len(snapshots) > maxSnapshots {
+		snapshots = snapshots[len(snapshots)-maxSnapshots:]
+	}
+	h.snapshots[specURL] = snapshots
+
+	return rev
+}
+
+// find finds the snapshot to compare the current revision of the spec fetched from the given URL against: the one with
+// the given revision if any, the one preceding the current revision otherwise.
+// The snapshots taken by the controller are only looked up for the given API, which may be nil.
+func (h *specHistory) find(specURL string, a *hubv1alpha1.API, current, rev string) (snapshot, bool) {
+	h.mu.Lock()
+	s, ok := findSnapshot(h.snapshots[specURL], current, rev)
+	h.mu.Unlock()
+
+	if ok || h.store == nil || a == nil {
+		return s, ok
+	}
+
+	specSnapshots, err := h.store.Snapshots(a.Name, a.Namespace)
+	if err != nil {
+		log.Error().Err(err).
+			Str("api_name", a.Name).
+			Str("api_namespace", a.Namespace).
+			Msg("Unable to get OpenAPI spec snapshots")
+		return snapshot{}, false
+	}
+
+	snapshots := make([]snapshot, 0, len(specSnapshots))
+	for _, specSnapshot := range specSnapshots {
+		snapshots = append(snapshots, snapshot{revision: specSnapshot.Revision, raw: specSnapshot.Spec})
+	}
+
+	return findSnapshot(snapshots, current, rev)
+}
+
+// findSnapshot finds, in the given snapshots ordered from the oldest to the newest, the one with the given revision
+// if any, otherwise the one preceding the current revision, or the newest one if the current revision isn't known.
+func findSnapshot(snapshots []snapshot, current, rev string) (snapshot, bool) {
+	if rev != "" {
+		for _, s := range snapshots {
+			if s.revision == rev {
+				return s, true
+			}
+		}
+
+		return snapshot{}, false
+	}
+
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].revision == current {
+			if i == 0 {
+				return snapshot{}, false
+			}
+
+			return snapshots[i-1], true
+		}
+	}
+
+	if len(snapshots) == 0 {
+		return snapshot{}, false
+	}
+
+	return snapshots[len(snapshots)-1], true
+}
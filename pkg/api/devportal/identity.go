@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
+	acpjwt "github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
+)
+
+// IdentityConfig configures the verification of the identity headers.
+type IdentityConfig struct {
+	// JWKsURL is the URL of the key set used to verify the identity token signature.
+	JWKsURL string
+	// TokenHeader is the header holding the identity token.
+	TokenHeader string
+	// Issuer is the expected issuer of the identity token. It isn't checked when empty.
+	Issuer string
+	// Audience is the expected audience of the identity token. It isn't checked when empty.
+	Audience string
+}
+
+// IdentityVerifier verifies that the identity headers of the requests sent to the portal match the claims of the
+// signed identity token issued by the edge auth layer.
+type IdentityVerifier struct {
+	keySet      acpjwt.KeySet
+	tokenHeader string
+	issuer      string
+	audience    string
+}
+
+// NewIdentityVerifier creates a new IdentityVerifier.
+func NewIdentityVerifier(cfg IdentityConfig) (*IdentityVerifier, error) {
+	if cfg.JWKsURL == "" {
+		return nil, errors.New("a JWKs URL is required")
+	}
+	if cfg.TokenHeader == "" {
+		return nil, errors.New("a token header is required")
+	}
+
+	return &IdentityVerifier{
+		keySet:      acpjwt.NewRemoteKeySet(cfg.JWKsURL),
+		tokenHeader: cfg.TokenHeader,
+		issuer:      cfg.Issuer,
+		audience:    cfg.Audience,
+	}, nil
+}
+
+type identityClaims struct {
+	jwt.RegisteredClaims
+
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+}
+
+// Wrap wraps the given handler to reject requests without a valid identity token or whose identity headers, named
+// after the given ones, don't match the token claims.
+func (v *IdentityVerifier) Wrap(next http.Handler, names apitoken.IdentityHeaders) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rawToken := req.Header.Get(v.tokenHeader)
+		if rawToken == "" {
+			log.Debug().Str("header", v.tokenHeader).Msg("Missing identity token")
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := v.parse(req.Context(), rawToken)
+		if err != nil {
+			log.Debug().Err(err).Msg("Invalid identity token")
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err = matchIdentityHeaders(req.Header, names, claims); err != nil {
+			log.Warn().Err(err).Str("email", claims.Email).Msg("Identity headers don't match the identity token")
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func (v *IdentityVerifier) parse(ctx context.Context, rawToken string) (*identityClaims, error) {
+	var claims identityClaims
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}))
+	if _, err := parser.ParseWithClaims(rawToken, &claims, v.keyFunc(ctx)); err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
+	if v.issuer != "" && !claims.VerifyIssuer(v.issuer, true) {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+	if v.audience != "" && !claims.VerifyAudience(v.audience, true) {
+		return nil, fmt.Errorf("unexpected audience %q", claims.Audience)
+	}
+
+	return &claims, nil
+}
+
+func (v *IdentityVerifier) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(tok *jwt.Token) (interface{}, error) {
+		kid, _ := tok.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("missing key ID")
+		}
+
+		k, err := v.keySet.Key(ctx, kid)
+		if err != nil {
+			return nil, fmt.Errorf("search for JSON web key: %w", err)
+		}
+		if k == nil {
+			return nil, fmt.Errorf("no key with id %q found", kid)
+		}
+
+		return k.Key, nil
+	}
+}
+
+// matchIdentityHeaders checks that the identity headers with the given names match the given claims.
+func matchIdentityHeaders(headers http.Header, names apitoken.IdentityHeaders, claims *identityClaims) error {
+	if email := headers.Get(names.Email); email != claims.Email {
+		return fmt.Errorf("%s header %q doesn't match email claim %q", names.Email, email, claims.Email)
+	}
+
+	groups := splitGroups(headers.Get(names.Groups))
+	wantGroups := append([]string(nil), claims.Groups...)
+	sort.Strings(wantGroups)
+
+	if strings.Join(groups, ",") != strings.Join(wantGroups, ",") {
+		return fmt.Errorf("%s header %q doesn't match groups claim %q", names.Groups, groups, claims.Groups)
+	}
+
+	return nil
+}
+
+// user is the user calling the portal.
+type user struct {
+	email  string
+	groups []string
+}
+
+// identityHeaders returns the names of the identity headers set on the requests sent to the portals of the gateway.
+func (g *gateway) identityHeaders() apitoken.IdentityHeaders {
+	return apitoken.GatewayIdentityHeaders(&g.APIGateway)
+}
+
+// user returns the user calling the portal, identified by the identity headers configured on its gateway. Once groups
+// have been pushed to the group directory, the groups are read from it rather than from the groups header.
+func (p *PortalAPI) user(r *http.Request) user {
+	names := p.portal.Gateway.identityHeaders()
+
+	email := r.Header.Get(names.Email)
+	if groups, ok := p.groups.Groups(email); ok {
+		return user{email: email, groups: groups}
+	}
+
+	return user{
+		email:  email,
+		groups: splitGroups(r.Header.Get(names.Groups)),
+	}
+}
+
+// splitGroups splits the given comma separated list of groups and sorts them.
+func splitGroups(groups string) []string {
+	var res []string
+	for _, group := range strings.Split(groups, ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			res = append(res, group)
+		}
+	}
+	sort.Strings(res)
+
+	return res
+}
@@ -0,0 +1,248 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+func TestIdentityVerifier_Wrap(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwksSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		keySet := jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "kid", Algorithm: "RS256", Use: "sig"}},
+		}
+
+		err := json.NewEncoder(rw).Encode(keySet)
+		require.NoError(t, err)
+	}))
+	t.Cleanup(jwksSrv.Close)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sign := func(k *rsa.PrivateKey, claims identityClaims) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "kid"
+
+		signed, err := tok.SignedString(k)
+		require.NoError(t, err)
+
+		return signed
+	}
+
+	validClaims := identityClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "https://hub.traefik.io",
+			Audience:  jwt.ClaimStrings{"portal"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Email:  "john@example.com",
+		Groups: []string{"developers", "admins"},
+	}
+
+	expiredClaims := validClaims
+	expiredClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	otherIssuerClaims := validClaims
+	otherIssuerClaims.Issuer = "https://example.com"
+
+	tests := []struct {
+		desc       string
+		token      string
+		email      string
+		groups     string
+		wantStatus int
+	}{
+		{
+			desc:       "matching headers",
+			token:      sign(key, validClaims),
+			email:      "john@example.com",
+			groups:     "admins, developers",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "missing token",
+			email:      "john@example.com",
+			groups:     "admins,developers",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "token signed with an unknown key",
+			token:      sign(otherKey, validClaims),
+			email:      "john@example.com",
+			groups:     "admins,developers",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "expired token",
+			token:      sign(key, expiredClaims),
+			email:      "john@example.com",
+			groups:     "admins,developers",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "unexpected issuer",
+			token:      sign(key, otherIssuerClaims),
+			email:      "john@example.com",
+			groups:     "admins,developers",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "email mismatch",
+			token:      sign(key, validClaims),
+			email:      "jane@example.com",
+			groups:     "admins,developers",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			desc:       "extra group",
+			token:      sign(key, validClaims),
+			email:      "john@example.com",
+			groups:     "admins,developers,ops",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	verifier, err := NewIdentityVerifier(IdentityConfig{
+		JWKsURL:     jwksSrv.URL,
+		TokenHeader: "Hub-Identity-Token",
+		Issuer:      "https://hub.traefik.io",
+		Audience:    "portal",
+	})
+	require.NoError(t, err)
+
+	names := apitoken.IdentityHeaders{Email: "X-User-Email", Groups: "X-User-Groups"}
+
+	handler := verifier.Wrap(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}), names)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header.Set(names.Email, test.email)
+			req.Header.Set(names.Groups, test.groups)
+			if test.token != "" {
+				req.Header.Set("Hub-Identity-Token", test.token)
+			}
+
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.wantStatus, rw.Code)
+		})
+	}
+}
+
+func TestPortalAPI_user(t *testing.T) {
+	groups := NewGroupDirectory(groupSourceFunc(func(_ context.Context) ([]api.Group, error) {
+		return []api.Group{{Name: "partners", Members: []api.GroupMember{{ID: "1", Display: "bob@example.com"}}}}, nil
+	}), zerolog.Nop())
+	require.NoError(t, groups.refresh(context.Background()))
+
+	tests := []struct {
+		desc    string
+		headers *hubv1alpha1.APIGatewayIdentityHeaders
+		groups  *GroupDirectory
+		req     http.Header
+		want    user
+	}{
+		{
+			desc: "default identity headers",
+			req: http.Header{
+				"Hub-Email":  []string{"john@example.com"},
+				"Hub-Groups": []string{"developers,admins"},
+			},
+			want: user{email: "john@example.com", groups: []string{"admins", "developers"}},
+		},
+		{
+			desc:    "identity headers configured on the gateway",
+			headers: &hubv1alpha1.APIGatewayIdentityHeaders{Email: "x-user-email", Groups: "x-user-groups"},
+			req: http.Header{
+				"Hub-Email":     []string{"jane@example.com"},
+				"Hub-Groups":    []string{"admins"},
+				"X-User-Email":  []string{"john@example.com"},
+				"X-User-Groups": []string{"developers"},
+			},
+			want: user{email: "john@example.com", groups: []string{"developers"}},
+		},
+		{
+			desc:   "user known by the group directory",
+			groups: groups,
+			req: http.Header{
+				"Hub-Email":  []string{"bob@example.com"},
+				"Hub-Groups": []string{"admins"},
+			},
+			want: user{email: "bob@example.com", groups: []string{"partners"}},
+		},
+		{
+			desc:   "user unknown by the group directory",
+			groups: groups,
+			req: http.Header{
+				"Hub-Email":  []string{"john@example.com"},
+				"Hub-Groups": []string{"admins"},
+			},
+			want: user{email: "john@example.com"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := PortalAPI{
+				portal: &portal{
+					Gateway: gateway{
+						APIGateway: hubv1alpha1.APIGateway{
+							Spec: hubv1alpha1.APIGatewaySpec{IdentityHeaders: test.headers},
+						},
+					},
+				},
+				groups: test.groups,
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.Header = test.req
+
+			assert.Equal(t, test.want, p.user(req))
+		})
+	}
+}
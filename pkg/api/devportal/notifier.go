@@ -0,0 +1,234 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	logwrapper "github.com/traefik/hub-agent-kubernetes/pkg/logger"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhooksig"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// Portal event types.
+const (
+	EventAPIPublished   = "api.published"
+	EventAPIUnpublished = "api.unpublished"
+)
+
+// Event is an event notified to the webhook of an APIPortal.
+type Event struct {
+	Type       string    `json:"type"`
+	Portal     string    `json:"portal"`
+	Collection string    `json:"collection,omitempty"`
+	API        string    `json:"api"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// publication identifies an API published on a portal, either directly or through a collection.
+type publication struct {
+	collection string
+	api        string
+}
+
+// Notifier notifies the APIPortal webhooks of the APIs being published or unpublished on their portal.
+type Notifier struct {
+	httpClient *http.Client
+	secrets    corev1client.SecretInterface
+	now        func() time.Time
+
+	// published holds the APIs published on each portal, the last time the Notifier was given the portals.
+	published map[string]map[publication]struct{}
+}
+
+// NewNotifier creates a new Notifier reading the secrets signing the events from the given Secrets.
+func NewNotifier(secrets corev1client.SecretInterface) *Notifier {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 4
+	client.HTTPClient.Timeout = 5 * time.Second
+	client.Logger = logwrapper.NewRetryableHTTPWrapper(log.Logger.With().
+		Str("component", "portal_notifier").
+		Logger())
+
+	return &Notifier{
+		httpClient: client.StandardClient(),
+		secrets:    secrets,
+		now:        time.Now,
+	}
+}
+
+// Notify notifies the webhook of each portal of the APIs published or unpublished since the last call.
+// The first call only records the published APIs, as no change can be detected yet.
+func (n *Notifier) Notify(ctx context.Context, portals []portal) {
+	published := make(map[string]map[publication]struct{}, len(portals))
+	for _, p := range portals {
+		published[p.Name] = publications(p)
+	}
+
+	previous := n.published
+	n.published = published
+
+	if previous == nil {
+		return
+	}
+
+	for _, p := range portals {
+		if p.Spec.Webhook == nil {
+			continue
+		}
+
+		events := diffPublications(p.Name, previous[p.Name], published[p.Name], n.now())
+		if len(events) == 0 {
+			continue
+		}
+
+		go n.send(ctx, p.Name, *p.Spec.Webhook, events)
+	}
+}
+
+// diffPublications returns the events of the APIs published or unpublished on the given portal, occurring at the given time.
+func diffPublications(portalName string, previous, current map[publication]struct{}, now time.Time) []Event {
+	var events []Event
+	for pub := range current {
+		if _, ok := previous[pub]; !ok {
+			events = append(events, newEvent(EventAPIPublished, portalName, pub, now))
+		}
+	}
+	for pub := range previous {
+		if _, ok := current[pub]; !ok {
+			events = append(events, newEvent(EventAPIUnpublished, portalName, pub, now))
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Type != events[j].Type {
+			return events[i].Type < events[j].Type
+		}
+		if events[i].Collection != events[j].Collection {
+			return events[i].Collection < events[j].Collection
+		}
+		return events[i].API < events[j].API
+	})
+
+	return events
+}
+
+func (n *Notifier) send(ctx context.Context, portalName string, webhook hubv1alpha1.APIPortalWebhook, events []Event) {
+	logger := log.With().Str("portal_name", portalName).Logger()
+
+	key, err := n.signingKey(ctx, webhook)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get portal webhook signing secret")
+		return
+	}
+
+	for _, event := range events {
+		if err = n.post(ctx, webhook.URL, key, event); err != nil {
+			logger.Error().Err(err).
+				Str("event_type", event.Type).
+				Str("api_name", event.API).
+				Msg("Unable to notify portal webhook")
+		}
+	}
+}
+
+// signingKey returns the key signing the events sent to the given webhook, read from the Secret it references. Events
+// aren't signed when the webhook doesn't reference any Secret.
+func (n *Notifier) signingKey(ctx context.Context, webhook hubv1alpha1.APIPortalWebhook) ([]byte, error) {
+	ref := webhook.SecretRef
+	if ref == nil {
+		return nil, nil
+	}
+
+	secret, err := n.secrets.Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get secret %q: %w", ref.Name, err)
+	}
+
+	key := secret.Data[ref.Key]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret %q has no %q key", ref.Name, ref.Key)
+	}
+
+	return key, nil
+}
+
+func (n *Notifier) post(ctx context.Context, url string, key []byte, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if key != nil {
+		webhooksig.Sign(req, key, body)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		all, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(all))
+	}
+
+	return nil
+}
+
+func publications(p portal) map[publication]struct{} {
+	pubs := make(map[publication]struct{})
+	for apiNameNamespace := range p.Gateway.APIs {
+		pubs[publication{api: apiNameNamespace}] = struct{}{}
+	}
+	for collectionName, c := range p.Gateway.Collections {
+		for apiNameNamespace := range c.APIs {
+			pubs[publication{collection: collectionName, api: apiNameNamespace}] = struct{}{}
+		}
+	}
+
+	return pubs
+}
+
+func newEvent(typ, portalName string, pub publication, at time.Time) Event {
+	return Event{
+		Type:       typ,
+		Portal:     portalName,
+		Collection: pub.collection,
+		API:        pub.api,
+		OccurredAt: at,
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/webhooksig"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNotifier_Notify(t *testing.T) {
+	type request struct {
+		signature string
+		body      []byte
+	}
+	requests := make(chan request, 10)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		requests <- request{signature: req.Header.Get(webhooksig.Header), body: body}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	secrets := kubemock.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "portal-webhook", Namespace: "agent-ns"},
+		Data:       map[string][]byte{"signing-key": []byte("secret")},
+	}).CoreV1().Secrets("agent-ns")
+
+	notifier := NewNotifier(secrets)
+	notifier.httpClient = http.DefaultClient
+	notifier.now = func() time.Time { return now }
+
+	webhook := &hubv1alpha1.APIPortalWebhook{
+		URL:       srv.URL,
+		SecretRef: &hubv1alpha1.APIPortalWebhookSecretRef{Name: "portal-webhook", Key: "signing-key"},
+	}
+
+	// The first call records the published APIs without notifying.
+	notifier.Notify(ctx, []portal{
+		newNotifiedPortal(webhook, []string{"products@default", "users@default"}, map[string][]string{"stores": {"stores@default"}}),
+		newNotifiedPortal(nil, nil, nil),
+	})
+
+	notifier.Notify(ctx, []portal{
+		newNotifiedPortal(webhook, []string{"products@default", "orders@default"}, map[string][]string{"stores": {"stores@default", "carts@default"}}),
+	})
+
+	wantEvents := []Event{
+		{Type: EventAPIPublished, Portal: "my-portal", API: "orders@default", OccurredAt: now},
+		{Type: EventAPIPublished, Portal: "my-portal", Collection: "stores", API: "carts@default", OccurredAt: now},
+		{Type: EventAPIUnpublished, Portal: "my-portal", API: "users@default", OccurredAt: now},
+	}
+
+	for _, wantEvent := range wantEvents {
+		var req request
+		select {
+		case req = <-requests:
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook not notified")
+		}
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(req.body)
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), req.signature)
+
+		var gotEvent Event
+		err := json.Unmarshal(req.body, &gotEvent)
+		require.NoError(t, err)
+
+		assert.Equal(t, wantEvent, gotEvent)
+	}
+
+	select {
+	case <-requests:
+		t.Fatal("unexpected webhook notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifier_Notify_missingSecret(t *testing.T) {
+	requests := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	notifier := NewNotifier(kubemock.NewSimpleClientset().CoreV1().Secrets("agent-ns"))
+	notifier.httpClient = http.DefaultClient
+
+	webhook := &hubv1alpha1.APIPortalWebhook{
+		URL:       srv.URL,
+		SecretRef: &hubv1alpha1.APIPortalWebhookSecretRef{Name: "portal-webhook", Key: "signing-key"},
+	}
+
+	notifier.Notify(ctx, []portal{newNotifiedPortal(webhook, []string{"products@default"}, nil)})
+	notifier.Notify(ctx, []portal{newNotifiedPortal(webhook, []string{"orders@default"}, nil)})
+
+	// Events aren't sent unsigned when the signing secret can't be read.
+	select {
+	case <-requests:
+		t.Fatal("unexpected webhook notification")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func newNotifiedPortal(webhook *hubv1alpha1.APIPortalWebhook, apis []string, collections map[string][]string) portal {
+	name := "my-portal"
+	if webhook == nil {
+		name = "other-portal"
+	}
+
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       hubv1alpha1.APIPortalSpec{Webhook: webhook},
+		},
+		Gateway: gateway{
+			APIs:        make(map[string]hubv1alpha1.API),
+			Collections: make(map[string]collection),
+		},
+	}
+
+	for _, a := range apis {
+		p.Gateway.APIs[a] = hubv1alpha1.API{}
+	}
+	for collectionName, collectionAPIs := range collections {
+		c := collection{APIs: make(map[string]hubv1alpha1.API)}
+		for _, a := range collectionAPIs {
+			c.APIs[a] = hubv1alpha1.API{}
+		}
+		p.Gateway.Collections[collectionName] = c
+	}
+
+	return p
+}
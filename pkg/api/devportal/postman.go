@@ -0,0 +1,350 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/mock"
+)
+
+// postmanSchema is the schema of the Postman collections served by the portal.
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Variables of the Postman collections, to be set by consumers.
+const (
+	postmanVarBaseURL = "baseUrl"
+	postmanVarToken   = "token"
+	postmanVarAPIKey  = "apiKey"
+)
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// postmanItem is either a request or a folder of requests.
+type postmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []postmanItem   `json:"item,omitempty"`
+	Request     *postmanRequest `json:"request,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string              `json:"mode"`
+	Raw     string              `json:"raw"`
+	Options *postmanBodyOptions `json:"options,omitempty"`
+}
+
+type postmanBodyOptions struct {
+	Raw postmanRawOptions `json:"raw"`
+}
+
+type postmanRawOptions struct {
+	Language string `json:"language"`
+}
+
+type postmanAuth struct {
+	Type   string                `json:"type"`
+	Bearer []postmanAuthVariable `json:"bearer,omitempty"`
+	APIKey []postmanAuthVariable `json:"apikey,omitempty"`
+}
+
+type postmanAuthVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// newPostmanCollection converts the given spec, as served by the portal, into a Postman collection. Requests are
+// grouped in folders by their first tag and target the given base URL, kept in a collection variable along with the
+// placeholders of the credentials required by the spec.
+func newPostmanCollection(name string, spec *openapi3.T, baseURL string) postmanCollection {
+	collection := postmanCollection{
+		Info: postmanInfo{
+			Name:   name,
+			Schema: postmanSchema,
+		},
+		Item: []postmanItem{},
+		Variable: []postmanVariable{
+			{Key: postmanVarBaseURL, Value: baseURL, Type: "string"},
+		},
+	}
+
+	if spec.Info != nil {
+		if spec.Info.Title != "" {
+			collection.Info.Name = spec.Info.Title
+		}
+		collection.Info.Description = spec.Info.Description
+	}
+
+	if auth, variable := newPostmanAuth(spec); auth != nil {
+		collection.Auth = auth
+		collection.Variable = append(collection.Variable, variable)
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	// The operations of each path are exported in this order.
+	methods := []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "TRACE"}
+
+	folders := make(map[string]int)
+	for _, p := range paths {
+		pathItem := spec.Paths[p]
+
+		for _, method := range methods {
+			operation := pathItem.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+
+			item := newPostmanItem(method, p, pathItem, operation)
+			if len(operation.Tags) == 0 {
+				collection.Item = append(collection.Item, item)
+				continue
+			}
+
+			tag := operation.Tags[0]
+			i, ok := folders[tag]
+			if !ok {
+				i = len(collection.Item)
+				folders[tag] = i
+				collection.Item = append(collection.Item, postmanItem{Name: tag})
+			}
+			collection.Item[i].Item = append(collection.Item[i].Item, item)
+		}
+	}
+
+	return collection
+}
+
+// newPostmanAuth returns the authentication of the collection matching the first security requirement of the given
+// spec, along with the variable holding the credentials. It returns a nil auth when the spec requires no credentials.
+func newPostmanAuth(spec *openapi3.T) (*postmanAuth, postmanVariable) {
+	if len(spec.Security) == 0 || spec.Components == nil {
+		return nil, postmanVariable{}
+	}
+
+	for name := range spec.Security[0] {
+		schemeRef := spec.Components.SecuritySchemes[name]
+		if schemeRef == nil || schemeRef.Value == nil {
+			continue
+		}
+		scheme := schemeRef.Value
+
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+			return &postmanAuth{
+				Type: "bearer",
+				Bearer: []postmanAuthVariable{
+					{Key: "token", Value: "{{" + postmanVarToken + "}}", Type: "string"},
+				},
+			}, postmanVariable{Key: postmanVarToken, Type: "string"}
+		case scheme.Type == "apiKey":
+			return &postmanAuth{
+				Type: "apikey",
+				APIKey: []postmanAuthVariable{
+					{Key: "key", Value: scheme.Name, Type: "string"},
+					{Key: "value", Value: "{{" + postmanVarAPIKey + "}}", Type: "string"},
+					{Key: "in", Value: scheme.In, Type: "string"},
+				},
+			}, postmanVariable{Key: postmanVarAPIKey, Type: "string"}
+		}
+	}
+
+	return nil, postmanVariable{}
+}
+
+func newPostmanItem(method, p string, pathItem *openapi3.PathItem, operation *openapi3.Operation) postmanItem {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = method + " " + p
+	}
+
+	request := postmanRequest{
+		Method: method,
+		Header: []postmanKeyValue{},
+		URL: postmanURL{
+			Host: []string{"{{" + postmanVarBaseURL + "}}"},
+		},
+	}
+
+	// Path templates such as "{id}" are written as Postman path variables, such as ":id".
+	for _, segment := range strings.Split(strings.Trim(p, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = ":" + strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}")
+		}
+		request.URL.Path = append(request.URL.Path, segment)
+	}
+
+	for _, param := range mergeParameters(pathItem.Parameters, operation.Parameters) {
+		kv := postmanKeyValue{
+			Key:         param.Name,
+			Value:       parameterExample(param),
+			Description: param.Description,
+		}
+
+		switch param.In {
+		case openapi3.ParameterInPath:
+			request.URL.Variable = append(request.URL.Variable, kv)
+		case openapi3.ParameterInQuery:
+			kv.Disabled = !param.Required
+			request.URL.Query = append(request.URL.Query, kv)
+		case openapi3.ParameterInHeader:
+			kv.Disabled = !param.Required
+			request.Header = append(request.Header, kv)
+		}
+	}
+
+	if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+		if content := operation.RequestBody.Value.Content.Get("application/json"); content != nil {
+			raw, err := json.MarshalIndent(mock.Example(content), "", "  ")
+			if err == nil {
+				request.Header = append(request.Header, postmanKeyValue{Key: "Content-Type", Value: "application/json"})
+				request.Body = &postmanBody{
+					Mode:    "raw",
+					Raw:     string(raw),
+					Options: &postmanBodyOptions{Raw: postmanRawOptions{Language: "json"}},
+				}
+			}
+		}
+	}
+
+	request.URL.Raw = buildPostmanRawURL(request.URL)
+
+	return postmanItem{
+		Name:        name,
+		Description: operation.Description,
+		Request:     &request,
+	}
+}
+
+// mergeParameters returns the parameters of an operation, overriding the ones shared by its path.
+func mergeParameters(pathParams, operationParams openapi3.Parameters) []*openapi3.Parameter {
+	var params []*openapi3.Parameter
+	for _, ref := range operationParams {
+		if ref != nil && ref.Value != nil {
+			params = append(params, ref.Value)
+		}
+	}
+
+	for _, ref := range pathParams {
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+
+		overridden := false
+		for _, param := range params {
+			if param.Name == ref.Value.Name && param.In == ref.Value.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, ref.Value)
+		}
+	}
+
+	return params
+}
+
+// parameterExample returns an example value of the given parameter, empty when none can be given.
+func parameterExample(param *openapi3.Parameter) string {
+	example := param.Example
+	if example == nil && param.Schema != nil && param.Schema.Value != nil {
+		example = param.Schema.Value.Example
+		if example == nil {
+			example = param.Schema.Value.Default
+		}
+	}
+	if example == nil {
+		return ""
+	}
+
+	return fmt.Sprint(example)
+}
+
+func buildPostmanRawURL(u postmanURL) string {
+	raw := strings.Join(u.Host, "")
+	if len(u.Path) > 0 {
+		raw += "/" + strings.Join(u.Path, "/")
+	}
+
+	var query []string
+	for _, kv := range u.Query {
+		if !kv.Disabled {
+			query = append(query, kv.Key+"="+kv.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+
+	return raw
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	hubinformers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+)
+
+// Provider provides the API management resources the portals and their catalog are built from. Providers which aren't
+// backed by Kubernetes informers notify the Watcher of their changes by calling Watcher.Refresh.
+type Provider interface {
+	APIPortals() v1alpha1.APIPortalLister
+	APIGateways() v1alpha1.APIGatewayLister
+	APIs() v1alpha1.APILister
+	APICollections() v1alpha1.APICollectionLister
+	APIAccesses() v1alpha1.APIAccessLister
+	APIRateLimits() v1alpha1.APIRateLimitLister
+}
+
+// KubernetesProvider provides the API management resources of the cluster, watched by informers. The Watcher must be
+// registered as an event handler of these informers to be notified of their changes.
+type KubernetesProvider struct {
+	informers hubinformers.Interface
+}
+
+// NewKubernetesProvider returns a provider of the API management resources watched by the given informers.
+func NewKubernetesProvider(informers hubinformers.Interface) *KubernetesProvider {
+	return &KubernetesProvider{informers: informers}
+}
+
+// APIPortals returns the APIPortal lister.
+func (p *KubernetesProvider) APIPortals() v1alpha1.APIPortalLister {
+	return p.informers.APIPortals().Lister()
+}
+
+// APIGateways returns the APIGateway lister.
+func (p *KubernetesProvider) APIGateways() v1alpha1.APIGatewayLister {
+	return p.informers.APIGateways().Lister()
+}
+
+// APIs returns the API lister.
+func (p *KubernetesProvider) APIs() v1alpha1.APILister {
+	return p.informers.APIs().Lister()
+}
+
+// APICollections returns the APICollection lister.
+func (p *KubernetesProvider) APICollections() v1alpha1.APICollectionLister {
+	return p.informers.APICollections().Lister()
+}
+
+// APIAccesses returns the APIAccess lister.
+func (p *KubernetesProvider) APIAccesses() v1alpha1.APIAccessLister {
+	return p.informers.APIAccesses().Lister()
+}
+
+// APIRateLimits returns the APIRateLimit lister.
+func (p *KubernetesProvider) APIRateLimits() v1alpha1.APIRateLimitLister {
+	return p.informers.APIRateLimits().Lister()
+}
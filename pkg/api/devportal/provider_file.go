@@ -0,0 +1,192 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/manifest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// FileProvider provides the API management resources read from manifest files, which allows publishing APIs that
+// aren't declared in a Kubernetes cluster. Nothing reconciles these resources: their statuses, like the URLs of the
+// portals, are read from the files as well.
+type FileProvider struct {
+	paths []string
+
+	resources atomic.Pointer[fileResources]
+
+	logger zerolog.Logger
+}
+
+// fileResources are the resources read from the manifest files at once.
+type fileResources struct {
+	hash []byte
+
+	portals     v1alpha1.APIPortalLister
+	gateways    v1alpha1.APIGatewayLister
+	apis        v1alpha1.APILister
+	collections v1alpha1.APICollectionLister
+	accesses    v1alpha1.APIAccessLister
+	rateLimits  v1alpha1.APIRateLimitLister
+}
+
+// NewFileProvider returns a provider of the API management resources held by the given manifest files. Directories are
+// walked recursively. The files are read once before returning.
+func NewFileProvider(paths []string, logger zerolog.Logger) (*FileProvider, error) {
+	p := &FileProvider{paths: paths, logger: logger}
+	if _, err := p.load(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Run reads the manifest files again every interval until the given context is done, and calls onChange when the
+// resources they hold changed.
+func (p *FileProvider) Run(ctx context.Context, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := p.load()
+		if err != nil {
+			p.logger.Error().Err(err).Msg("Unable to reload the catalog manifests")
+			continue
+		}
+
+		if changed {
+			onChange()
+		}
+	}
+}
+
+// APIPortals returns the APIPortal lister.
+func (p *FileProvider) APIPortals() v1alpha1.APIPortalLister {
+	return p.resources.Load().portals
+}
+
+// APIGateways returns the APIGateway lister.
+func (p *FileProvider) APIGateways() v1alpha1.APIGatewayLister {
+	return p.resources.Load().gateways
+}
+
+// APIs returns the API lister.
+func (p *FileProvider) APIs() v1alpha1.APILister {
+	return p.resources.Load().apis
+}
+
+// APICollections returns the APICollection lister.
+func (p *FileProvider) APICollections() v1alpha1.APICollectionLister {
+	return p.resources.Load().collections
+}
+
+// APIAccesses returns the APIAccess lister.
+func (p *FileProvider) APIAccesses() v1alpha1.APIAccessLister {
+	return p.resources.Load().accesses
+}
+
+// APIRateLimits returns the APIRateLimit lister.
+func (p *FileProvider) APIRateLimits() v1alpha1.APIRateLimitLister {
+	return p.resources.Load().rateLimits
+}
+
+// load reads the manifest files and returns whether the resources they hold changed since the last time they were
+// read.
+func (p *FileProvider) load() (bool, error) {
+	objects, err := manifest.Load(p.paths)
+	if err != nil {
+		return false, fmt.Errorf("load manifests: %w", err)
+	}
+
+	newIndexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	portals, gateways, apis, collections, accesses, rateLimits := newIndexer(), newIndexer(), newIndexer(), newIndexer(), newIndexer(), newIndexer()
+
+	hash := fnv.New128a()
+	for _, object := range objects {
+		var indexer cache.Indexer
+		switch object.Object.(type) {
+		case *hubv1alpha1.APIPortal:
+			indexer = portals
+		case *hubv1alpha1.APIGateway:
+			indexer = gateways
+		case *hubv1alpha1.API:
+			indexer = apis
+		case *hubv1alpha1.APICollection:
+			indexer = collections
+		case *hubv1alpha1.APIAccess:
+			indexer = accesses
+		case *hubv1alpha1.APIRateLimit:
+			indexer = rateLimits
+		default:
+			continue
+		}
+
+		if object.DecodeErr != nil {
+			p.logger.Warn().
+				Err(object.DecodeErr).
+				Str("source", object.Source).
+				Msg("Catalog manifest resource has unknown or duplicated fields")
+		}
+
+		if err = indexer.Add(object.Object); err != nil {
+			return false, fmt.Errorf("index %s: %w", object.Source, err)
+		}
+
+		b, err := json.Marshal(object.Object)
+		if err != nil {
+			return false, fmt.Errorf("marshal %s: %w", object.Source, err)
+		}
+		hash.Write(b)
+	}
+
+	sum := hash.Sum(nil)
+	if current := p.resources.Load(); current != nil && bytes.Equal(current.hash, sum) {
+		return false, nil
+	}
+
+	p.resources.Store(&fileResources{
+		hash:        sum,
+		portals:     v1alpha1.NewAPIPortalLister(portals),
+		gateways:    v1alpha1.NewAPIGatewayLister(gateways),
+		apis:        v1alpha1.NewAPILister(apis),
+		collections: v1alpha1.NewAPICollectionLister(collections),
+		accesses:    v1alpha1.NewAPIAccessLister(accesses),
+		rateLimits:  v1alpha1.NewAPIRateLimitLister(rateLimits),
+	})
+
+	return true, nil
+}
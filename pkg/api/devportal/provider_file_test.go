@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestFileProvider_Catalog(t *testing.T) {
+	clientSet := hubkubemock.NewSimpleClientset()
+	loadK8sObjects(t, clientSet, "./testdata/manifests/internal-portal.yaml")
+	loadK8sObjects(t, clientSet, "./testdata/manifests/external-portal.yaml")
+
+	wantCatalog, err := NewWatcher(nil, setupProvider(t, clientSet), nil, nil, zerolog.Nop()).Catalog()
+	require.NoError(t, err)
+	require.Len(t, wantCatalog, 2)
+
+	provider, err := NewFileProvider([]string{"./testdata/manifests"}, zerolog.Nop())
+	require.NoError(t, err)
+
+	gotCatalog, err := NewWatcher(nil, provider, nil, nil, zerolog.Nop()).Catalog()
+	require.NoError(t, err)
+
+	assert.Equal(t, wantCatalog, gotCatalog)
+
+	rateLimits, err := provider.APIRateLimits().List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, rateLimits, 1)
+}
+
+func TestFileProvider_Run(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "portal.yaml")
+
+	internal, err := os.ReadFile("./testdata/manifests/internal-portal.yaml")
+	require.NoError(t, err)
+	external, err := os.ReadFile("./testdata/manifests/external-portal.yaml")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(file, internal, 0o600))
+
+	provider, err := NewFileProvider([]string{dir}, zerolog.Nop())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	changed := make(chan struct{}, 10)
+	go provider.Run(ctx, 10*time.Millisecond, func() { changed <- struct{}{} })
+
+	// Nothing changes as long as the files hold the same resources.
+	select {
+	case <-changed:
+		require.FailNow(t, "unexpected change")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(file, append(append(internal, []byte("\n---\n")...), external...), 0o600))
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "change not detected")
+	}
+
+	portals, err := provider.APIPortals().List(labels.Everything())
+	require.NoError(t, err)
+	assert.Len(t, portals, 2)
+}
+
+func TestNewFileProvider_missingFile(t *testing.T) {
+	_, err := NewFileProvider([]string{filepath.Join(t.TempDir(), "missing.yaml")}, zerolog.Nop())
+	assert.Error(t, err)
+}
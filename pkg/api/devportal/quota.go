@@ -0,0 +1,172 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// extensionRateLimit is the OpenAPI extension listing the rate limits applied to the consumer of a spec.
+const extensionRateLimit = "x-ratelimit"
+
+type quotaResp struct {
+	Quotas []quota `json:"quotas"`
+}
+
+// quota is the state of an APIRateLimit for a consumer, in the current window. Used and Remaining are only known when
+// usage metrics are available.
+type quota struct {
+	Name      string    `json:"name"`
+	Limit     int       `json:"limit"`
+	Period    string    `json:"period"`
+	Used      *int64    `json:"used,omitempty"`
+	Remaining *int64    `json:"remaining,omitempty"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+type rateLimitExtension struct {
+	Name   string `json:"name"`
+	Limit  int    `json:"limit"`
+	Period string `json:"period"`
+}
+
+// serveAPIQuota serves the quotas applied to the user calling the portal on the given API, along with the remaining
+// budget of the current windows.
+func (p *PortalAPI) serveAPIQuota(rw http.ResponseWriter, r *http.Request, g *gateway, _ *collection, a *hubv1alpha1.API, _ *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	u := p.user(r)
+	if u.email == "" {
+		logger.Debug().Str("header", g.identityHeaders().Email).Msg("Missing user email")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	apiNameNamespace := a.Name + "@" + a.Namespace
+	now := p.now()
+
+	quotas := make([]quota, 0)
+	for _, rateLimit := range consumerRateLimits(g, apiNameNamespace, u.groups) {
+		period := rateLimit.Spec.PeriodDuration()
+		windowStart := now.Truncate(period)
+
+		q := quota{
+			Name:    rateLimit.Name,
+			Limit:   rateLimit.Spec.Limit,
+			Period:  period.String(),
+			ResetAt: windowStart.Add(period),
+		}
+
+		if p.usage != nil {
+			var used int64
+			if elapsed := now.Sub(windowStart); elapsed > 0 {
+				usage, err := p.usage.GetAPIUsage(ctx, api.UsageQuery{
+					Portal: p.portal.Name,
+					API:    apiNameNamespace,
+					Email:  u.email,
+					Period: elapsed,
+				})
+				if err != nil {
+					logger.Error().Err(err).Str("rate_limit_name", rateLimit.Name).Msg("Unable to get API usage")
+					rw.WriteHeader(http.StatusBadGateway)
+					return
+				}
+
+				used = usage.Total.Requests
+			}
+
+			remaining := int64(rateLimit.Spec.Limit) - used
+			if remaining < 0 {
+				remaining = 0
+			}
+
+			q.Used, q.Remaining = &used, &remaining
+		}
+
+		quotas = append(quotas, q)
+	}
+
+	resp, err := json.Marshal(quotaResp{Quotas: quotas})
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to marshal quotas")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err = rw.Write(resp); err != nil {
+		logger.Error().Err(err).Msg("Write quotas response")
+	}
+}
+
+// addRateLimitExtension lists, in the x-ratelimit extension of the given spec, the rate limits applied to a consumer
+// member of the given groups on the given API.
+func addRateLimitExtension(spec *openapi3.T, g *gateway, apiNameNamespace string, groups []string) {
+	rateLimits := consumerRateLimits(g, apiNameNamespace, groups)
+	if len(rateLimits) == 0 {
+		return
+	}
+
+	extensions := make([]rateLimitExtension, 0, len(rateLimits))
+	for _, rateLimit := range rateLimits {
+		extensions = append(extensions, rateLimitExtension{
+			Name:   rateLimit.Name,
+			Limit:  rateLimit.Spec.Limit,
+			Period: rateLimit.Spec.PeriodDuration().String(),
+		})
+	}
+
+	if spec.Extensions == nil {
+		spec.Extensions = make(map[string]interface{})
+	}
+	spec.Extensions[extensionRateLimit] = extensions
+}
+
+// consumerRateLimits returns the APIRateLimits applied to a consumer member of the given groups on the given API.
+func consumerRateLimits(g *gateway, apiNameNamespace string, groups []string) []hubv1alpha1.APIRateLimit {
+	var rateLimits []hubv1alpha1.APIRateLimit
+	for _, rateLimit := range g.RateLimits[apiNameNamespace] {
+		if len(rateLimit.Spec.Groups) == 0 || intersects(rateLimit.Spec.Groups, groups) {
+			rateLimits = append(rateLimits, rateLimit)
+		}
+	}
+
+	return rateLimits
+}
+
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// sdkGeneratorName returns the openapi-generator generator of the given SDK language, and whether SDKs can be
+// downloaded in this language.
+func sdkGeneratorName(lang string) (string, bool) {
+	switch lang {
+	case "go":
+		return "go", true
+	case "ts":
+		return "typescript-fetch", true
+	case "python":
+		return "python", true
+	default:
+		return "", false
+	}
+}
+
+// sdkWriteTimeout is the maximum duration for generating an SDK and streaming it to the user. It overrides the write
+// timeout of the server, too short for SDK downloads.
+const sdkWriteTimeout = 5 * time.Minute
+
+// errUnsupportedLanguage is returned when generating an SDK in an unsupported language.
+var errUnsupportedLanguage = errors.New("unsupported language")
+
+// SDKGenerator generates client SDKs from OpenAPI specs using an openapi-generator-online server,
+// typically running as a sidecar of the dev portal.
+type SDKGenerator struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+}
+
+// NewSDKGenerator returns a new SDKGenerator calling the openapi-generator-online server at the given URL.
+func NewSDKGenerator(baseURL string) (*SDKGenerator, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse SDK generator URL: %w", err)
+	}
+
+	return &SDKGenerator{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: time.Minute}, // Generating an SDK can take a while.
+	}, nil
+}
+
+type generateReq struct {
+	Spec *openapi3.T `json:"spec"`
+}
+
+type generateResp struct {
+	Code string `json:"code"`
+}
+
+// Generate generates a client SDK in the given language for the given spec. It returns the zip archive of the SDK,
+// which must be closed by the caller.
+func (g *SDKGenerator) Generate(ctx context.Context, lang string, spec *openapi3.T) (io.ReadCloser, error) {
+	generator, ok := sdkGeneratorName(lang)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnsupportedLanguage, lang)
+	}
+
+	body, err := json.Marshal(generateReq{Spec: spec})
+	if err != nil {
+		return nil, fmt.Errorf("marshal generate request: %w", err)
+	}
+
+	genURL := g.baseURL.JoinPath("api", "gen", "clients", generator)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, genURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build generate request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("generate SDK: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("generate SDK: unexpected status code %d", resp.StatusCode)
+	}
+
+	var genResp generateResp
+	if err = json.NewDecoder(resp.Body).Decode(&genResp); err != nil {
+		return nil, fmt.Errorf("decode generate response: %w", err)
+	}
+
+	downloadURL := g.baseURL.JoinPath("api", "gen", "download", genResp.Code)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, downloadURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("build download request: %w", err)
+	}
+
+	downloadResp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download SDK: %w", err)
+	}
+
+	if downloadResp.StatusCode != http.StatusOK {
+		_ = downloadResp.Body.Close()
+		return nil, fmt.Errorf("download SDK: unexpected status code %d", downloadResp.StatusCode)
+	}
+
+	return downloadResp.Body, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// TermsRecorder records the acceptance of the terms of service of the APIs by their consumers. The platform refuses
+// to issue tokens for an API until its current terms of service have been accepted.
+type TermsRecorder interface {
+	GetTermsAcceptance(ctx context.Context, query api.TermsQuery) (*api.TermsAcceptance, error)
+	AcceptTerms(ctx context.Context, acceptance api.TermsAcceptance) (api.TermsAcceptance, error)
+}
+
+// PlatformClient is the client used by the portals to reach the platform.
+type PlatformClient interface {
+	UsageSource
+	ExampleSource
+	TermsRecorder
+}
+
+type termsResp struct {
+	URL        string     `json:"url"`
+	Version    string     `json:"version"`
+	Accepted   *bool      `json:"accepted,omitempty"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+}
+
+type acceptTermsReq struct {
+	Version string `json:"version"`
+}
+
+// findTermsOfService finds the terms of service applying to the given API. When the API is published through a
+// collection, the terms of service of the collection take precedence over the ones of the API.
+func findTermsOfService(c *collection, a *hubv1alpha1.API) *hubv1alpha1.TermsOfService {
+	if c != nil && c.Spec.TermsOfService != nil {
+		return c.Spec.TermsOfService
+	}
+
+	return a.Spec.TermsOfService
+}
+
+// serveAPITerms serves the terms of service of the given API along with whether the user calling the portal accepted
+// their current version.
+func (p *PortalAPI) serveAPITerms(rw http.ResponseWriter, r *http.Request, _ *gateway, c *collection, a *hubv1alpha1.API, _ *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	tos := findTermsOfService(c, a)
+	if tos == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	email := p.user(r).email
+	if email == "" {
+		logger.Debug().Str("header", p.portal.Gateway.identityHeaders().Email).Msg("Missing user email")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	resp := termsResp{
+		URL:     tos.URL,
+		Version: tos.Version,
+	}
+
+	if p.terms != nil {
+		query := api.TermsQuery{
+			Portal: p.portal.Name,
+			API:    a.Name + "@" + a.Namespace,
+			Email:  email,
+		}
+		if c != nil {
+			query.Collection = c.Name
+		}
+
+		acceptance, err := p.terms.GetTermsAcceptance(ctx, query)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to get terms of service acceptance")
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		accepted := acceptance != nil && acceptance.Version == tos.Version
+		resp.Accepted = &accepted
+		if accepted {
+			resp.AcceptedAt = &acceptance.AcceptedAt
+		}
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to marshal terms of service")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err = rw.Write(body); err != nil {
+		logger.Error().Err(err).Msg("Write terms of service response")
+	}
+}
+
+// serveAPIAcceptTerms records the acceptance, by the user calling the portal, of the current version of the terms of
+// service of the given API.
+func (p *PortalAPI) serveAPIAcceptTerms(rw http.ResponseWriter, r *http.Request, _ *gateway, c *collection, a *hubv1alpha1.API, _ *hubv1alpha1.APIVersion) {
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	tos := findTermsOfService(c, a)
+	if p.terms == nil || tos == nil {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	email := p.user(r).email
+	if email == "" {
+		logger.Debug().Str("header", p.portal.Gateway.identityHeaders().Email).Msg("Missing user email")
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req acceptTermsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Debug().Err(err).Msg("Invalid terms of service acceptance request")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Make sure users accept the terms of service they have been presented with.
+	if req.Version != tos.Version {
+		logger.Debug().
+			Str("accepted_version", req.Version).
+			Str("current_version", tos.Version).
+			Msg("Outdated terms of service version")
+		rw.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	acceptance := api.TermsAcceptance{
+		Portal:  p.portal.Name,
+		API:     a.Name + "@" + a.Namespace,
+		Email:   email,
+		URL:     tos.URL,
+		Version: tos.Version,
+	}
+	if c != nil {
+		acceptance.Collection = c.Name
+	}
+
+	accepted, err := p.terms.AcceptTerms(ctx, acceptance)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to record terms of service acceptance")
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	body, err := json.Marshal(accepted)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to marshal terms of service acceptance")
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if _, err = rw.Write(body); err != nil {
+		logger.Error().Err(err).Msg("Write terms of service acceptance response")
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPortalAPI_Router_apiTerms(t *testing.T) {
+	myAPI := hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-api", Namespace: "my-ns"},
+		Spec: hubv1alpha1.APISpec{
+			TermsOfService: &hubv1alpha1.TermsOfService{URL: "https://example.com/api-tos", Version: "2"},
+		},
+	}
+	p := portal{
+		APIPortal: hubv1alpha1.APIPortal{ObjectMeta: metav1.ObjectMeta{Name: "my-portal"}},
+		Gateway: gateway{
+			APIs: map[string]hubv1alpha1.API{
+				"my-api@my-ns":    myAPI,
+				"other-api@my-ns": {ObjectMeta: metav1.ObjectMeta{Name: "other-api", Namespace: "my-ns"}},
+			},
+			Collections: map[string]collection{
+				"my-collection": {
+					APICollection: hubv1alpha1.APICollection{
+						ObjectMeta: metav1.ObjectMeta{Name: "my-collection"},
+						Spec: hubv1alpha1.APICollectionSpec{
+							TermsOfService: &hubv1alpha1.TermsOfService{URL: "https://example.com/collection-tos", Version: "1"},
+						},
+					},
+					APIs: map[string]hubv1alpha1.API{"my-api@my-ns": myAPI},
+				},
+			},
+		},
+	}
+
+	a, err := NewPortalAPI(&p, lint.DefaultRuleset())
+	require.NoError(t, err)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(apitoken.HeaderEmail, "john@example.com")
+
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, req)
+
+		return rw
+	}
+
+	// The terms of service are presented but acceptances can't be recorded without recorder.
+	rw := serve(http.MethodGet, "/apis/my-api@my-ns/terms", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"url": "https://example.com/api-tos", "version": "2"}`, rw.Body.String())
+
+	rw = serve(http.MethodPost, "/apis/my-api@my-ns/terms/accept", `{"version": "2"}`)
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	recorder := newTermsRecorderMock(time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC))
+	a.terms = recorder
+
+	rw = serve(http.MethodGet, "/apis/other-api@my-ns/terms", "")
+	assert.Equal(t, http.StatusNotFound, rw.Code)
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/apis/my-api@my-ns/terms", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+
+	rw = serve(http.MethodGet, "/apis/my-api@my-ns/terms", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"url": "https://example.com/api-tos", "version": "2", "accepted": false}`, rw.Body.String())
+
+	rw = serve(http.MethodPost, "/apis/my-api@my-ns/terms/accept", `{`)
+	assert.Equal(t, http.StatusBadRequest, rw.Code)
+
+	// Only the current version of the terms of service can be accepted.
+	rw = serve(http.MethodPost, "/apis/my-api@my-ns/terms/accept", `{"version": "1"}`)
+	assert.Equal(t, http.StatusConflict, rw.Code)
+
+	rw = serve(http.MethodPost, "/apis/my-api@my-ns/terms/accept", `{"version": "2"}`)
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{
+		"portal": "my-portal",
+		"api": "my-api@my-ns",
+		"email": "john@example.com",
+		"url": "https://example.com/api-tos",
+		"version": "2",
+		"acceptedAt": "2023-01-01T10:00:00Z"
+	}`, rw.Body.String())
+
+	rw = serve(http.MethodGet, "/apis/my-api@my-ns/terms", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{
+		"url": "https://example.com/api-tos",
+		"version": "2",
+		"accepted": true,
+		"acceptedAt": "2023-01-01T10:00:00Z"
+	}`, rw.Body.String())
+
+	// The terms of service of the collection take precedence over the ones of the API. Version "1" of the collection
+	// terms of service has not been accepted yet.
+	rw = serve(http.MethodGet, "/collections/my-collection/apis/my-api@my-ns/terms", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"url": "https://example.com/collection-tos", "version": "1", "accepted": false}`, rw.Body.String())
+
+	rw = serve(http.MethodPost, "/collections/my-collection/apis/my-api@my-ns/terms/accept", `{"version": "1"}`)
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, api.TermsAcceptance{
+		Portal:     "my-portal",
+		API:        "my-api@my-ns",
+		Collection: "my-collection",
+		Email:      "john@example.com",
+		URL:        "https://example.com/collection-tos",
+		Version:    "1",
+		AcceptedAt: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+	}, recorder.acceptances["my-portal/my-collection/my-api@my-ns/john@example.com"])
+
+	// Acceptances are scoped to the collection the API is published through.
+	rw = serve(http.MethodGet, "/apis/my-api@my-ns/terms", "")
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `"accepted":true`)
+}
+
+// termsRecorderMock is an in-memory TermsRecorder keeping the latest acceptance of each user.
+type termsRecorderMock struct {
+	now         time.Time
+	acceptances map[string]api.TermsAcceptance
+}
+
+func newTermsRecorderMock(now time.Time) *termsRecorderMock {
+	return &termsRecorderMock{
+		now:         now,
+		acceptances: make(map[string]api.TermsAcceptance),
+	}
+}
+
+func (m *termsRecorderMock) GetTermsAcceptance(_ context.Context, query api.TermsQuery) (*api.TermsAcceptance, error) {
+	acceptance, ok := m.acceptances[query.Portal+"/"+query.Collection+"/"+query.API+"/"+query.Email]
+	if !ok {
+		return nil, nil
+	}
+
+	return &acceptance, nil
+}
+
+func (m *termsRecorderMock) AcceptTerms(_ context.Context, acceptance api.TermsAcceptance) (api.TermsAcceptance, error) {
+	acceptance.AcceptedAt = m.now
+	m.acceptances[acceptance.Portal+"/"+acceptance.Collection+"/"+acceptance.API+"/"+acceptance.Email] = acceptance
+
+	return acceptance, nil
+}
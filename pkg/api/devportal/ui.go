@@ -19,6 +19,7 @@ package devportal
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"net"
@@ -35,6 +36,7 @@ type PortalUI struct {
 	router chi.Router
 
 	templatedIndexes map[string][]byte
+	configs          map[string][]byte
 }
 
 type portalIndexData struct {
@@ -43,6 +45,20 @@ type portalIndexData struct {
 	Description string
 }
 
+// portalConfig is the configuration of an APIPortal UI, served as config.json.
+type portalConfig struct {
+	Name        string       `json:"name"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Theme       *portalTheme `json:"theme,omitempty"`
+}
+
+type portalTheme struct {
+	LogoURL      string `json:"logoUrl,omitempty"`
+	PrimaryColor string `json:"primaryColor,omitempty"`
+	AccentColor  string `json:"accentColor,omitempty"`
+}
+
 // NewPortalUI creates a new PortalUI handler.
 func NewPortalUI(portals []portal) (*PortalUI, error) {
 	tmpl, err := template.ParseFS(portalui.WebUI, "index.html")
@@ -55,14 +71,21 @@ func NewPortalUI(portals []portal) (*PortalUI, error) {
 		return nil, fmt.Errorf("template portal indexes: %w", err)
 	}
 
+	configs, err := portalConfigs(portals)
+	if err != nil {
+		return nil, fmt.Errorf("build portal configs: %w", err)
+	}
+
 	h := &PortalUI{
 		router:           chi.NewRouter(),
 		templatedIndexes: templatedIndexes,
+		configs:          configs,
 	}
 
 	fileServer := http.FileServer(http.FS(portalui.WebUI))
 	h.router.Handle("/static/*", fileServer)
 	h.router.Handle("/robots.txt", fileServer)
+	h.router.Get("/config.json", h.handleConfig)
 
 	h.router.Get("/*", h.handleIndex)
 
@@ -92,17 +115,29 @@ func (p *PortalUI) handleIndex(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+func (p *PortalUI) handleConfig(rw http.ResponseWriter, req *http.Request) {
+	host := stripHostPort(req.Host)
+	config, ok := p.configs[host]
+	if !ok {
+		log.Debug().Str("host", host).Msg("APIPortal not found for host")
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Content-Type", "application/json")
+
+	if _, err := rw.Write(config); err != nil {
+		log.Error().Err(err).Msg("Unable to serve APIPortal UI config")
+	}
+}
+
 func templatePortalIndexes(indexTemplate *template.Template, portals []portal) (map[string][]byte, error) {
 	indexes := make(map[string][]byte)
 	for _, p := range portals {
-		title := p.Spec.Title
-		if title == "" {
-			title = p.Name
-		}
-
 		data := portalIndexData{
 			Name:        p.Name,
-			Title:       title,
+			Title:       portalTitle(p),
 			Description: p.Spec.Description,
 		}
 
@@ -111,17 +146,57 @@ func templatePortalIndexes(indexTemplate *template.Template, portals []portal) (
 			return nil, fmt.Errorf("template portal %q index: %w", p.Name, err)
 		}
 
-		// As soon as a CustomDomain is provided on the Portal, the UI is no longer accessible through the HubDomain.
-		templated := buff.Bytes()
-		for _, customDomain := range p.Status.CustomDomains {
-			indexes[customDomain] = templated
-		}
-		indexes[p.Status.HubDomain] = templated
+		setForDomains(indexes, p, buff.Bytes())
 	}
 
 	return indexes, nil
 }
 
+func portalConfigs(portals []portal) (map[string][]byte, error) {
+	configs := make(map[string][]byte)
+	for _, p := range portals {
+		config := portalConfig{
+			Name:        p.Name,
+			Title:       portalTitle(p),
+			Description: p.Spec.Description,
+		}
+
+		if theme := p.Spec.Theme; theme != nil {
+			config.Theme = &portalTheme{
+				LogoURL:      theme.LogoURL,
+				PrimaryColor: theme.PrimaryColor,
+				AccentColor:  theme.AccentColor,
+			}
+		}
+
+		b, err := json.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("marshal portal %q config: %w", p.Name, err)
+		}
+
+		setForDomains(configs, p, b)
+	}
+
+	return configs, nil
+}
+
+func portalTitle(p portal) string {
+	if p.Spec.Title == "" {
+		return p.Name
+	}
+
+	return p.Spec.Title
+}
+
+// setForDomains sets the given value for all the domains under which the portal is exposed.
+func setForDomains(m map[string][]byte, p portal, value []byte) {
+	// As soon as a CustomDomain is provided on the Portal, the UI is no longer accessible through the HubDomain.
+	for _, customDomain := range p.Status.CustomDomains {
+		m[customDomain] = value
+	}
+	m[p.Status.HubDomain] = value
+}
+
 // stripHostPort returns host without any trailing ":<port>".
 // https://github.com/golang/go/blob/cdf77c7209a497825b2956ec0360c6e7e4ae0acd/src/net/http/server.go#L2358-L2368
 func stripHostPort(host string) string {
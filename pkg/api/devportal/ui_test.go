@@ -100,3 +100,83 @@ func TestPortalUI_ServeHTTP(t *testing.T) {
 		}
 	}
 }
+
+func TestPortalUI_ServeHTTP_config(t *testing.T) {
+	portals := []portal{
+		{
+			APIPortal: hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "external-portal"},
+				Spec: hubv1alpha1.APIPortalSpec{
+					Title:       "External Portal",
+					Description: "A portal for external partners",
+					Theme: &hubv1alpha1.APIPortalTheme{
+						LogoURL:      "https://example.com/logo.svg",
+						PrimaryColor: "#1a2b3c",
+						AccentColor:  "#fff",
+					},
+				},
+				Status: hubv1alpha1.APIPortalStatus{
+					HubDomain:     "majestic-beaver-123.hub-traefik.io",
+					CustomDomains: []string{"external.example.com"},
+				},
+			},
+		},
+		{
+			APIPortal: hubv1alpha1.APIPortal{
+				ObjectMeta: metav1.ObjectMeta{Name: "internal-portal"},
+				Status: hubv1alpha1.APIPortalStatus{
+					HubDomain: "majestic-cat-123.hub-traefik.io",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		desc       string
+		host       string
+		wantStatus int
+		wantConfig string
+	}{
+		{
+			desc:       "themed portal through its custom domain",
+			host:       "external.example.com",
+			wantStatus: http.StatusOK,
+			wantConfig: `{"name":"external-portal","title":"External Portal","description":"A portal for external partners","theme":{"logoUrl":"https://example.com/logo.svg","primaryColor":"#1a2b3c","accentColor":"#fff"}}`,
+		},
+		{
+			desc:       "portal without theme",
+			host:       "majestic-cat-123.hub-traefik.io:443",
+			wantStatus: http.StatusOK,
+			wantConfig: `{"name":"internal-portal","title":"internal-portal"}`,
+		},
+		{
+			desc:       "unknown host",
+			host:       "unknown.example.com",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	handler, err := NewPortalUI(portals)
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/config.json", http.NoBody)
+			req.Host = test.host
+
+			handler.ServeHTTP(rw, req)
+
+			require.Equal(t, test.wantStatus, rw.Code)
+			if test.wantConfig == "" {
+				return
+			}
+
+			assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.wantConfig, rw.Body.String())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package devportal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+)
+
+// Usage periods accepted by the metrics endpoints.
+const (
+	defaultUsagePeriod = 24 * time.Hour
+	maxUsagePeriod     = 30 * 24 * time.Hour
+)
+
+// UsageSource provides the usage of the APIs made with the tokens of their consumers.
+type UsageSource interface {
+	GetAPIUsage(ctx context.Context, query api.UsageQuery) (api.APIUsage, error)
+}
+
+// parseUsagePeriod parses the given usage period, defaulting to defaultUsagePeriod when empty.
+func parseUsagePeriod(period string) (time.Duration, error) {
+	if period == "" {
+		return defaultUsagePeriod, nil
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil {
+		return 0, fmt.Errorf("parse period: %w", err)
+	}
+	if d <= 0 {
+		return 0, errors.New("period must be positive")
+	}
+	if d > maxUsagePeriod {
+		return 0, fmt.Errorf("period must not exceed %s", maxUsagePeriod)
+	}
+
+	return d, nil
+}
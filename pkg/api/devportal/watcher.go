@@ -20,16 +20,21 @@ package devportal
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
-	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/workqueue"
 )
 
+// refreshKey is the work queue key of the refresh of the portals, which are all refreshed at once.
+const refreshKey = "portals"
+
 type portal struct {
 	hubv1alpha1.APIPortal
 
@@ -41,12 +46,18 @@ type gateway struct {
 
 	Collections map[string]collection
 	APIs        map[string]hubv1alpha1.API
+	// RateLimits are the APIRateLimits applied to the APIs of the gateway, indexed by API.
+	RateLimits map[string][]hubv1alpha1.APIRateLimit
+	// Consumers are the groups and users granted access to the APIs of the gateway, indexed by API.
+	Consumers map[string]*consumers
 }
 
 type collection struct {
 	hubv1alpha1.APICollection
 
 	APIs map[string]hubv1alpha1.API
+	// Consumers are the groups and users granted access to the collection.
+	Consumers *consumers
 }
 
 // UpdatableHandler is an updatable HTTP handler for serving dev portals.
@@ -56,99 +67,104 @@ type UpdatableHandler interface {
 
 // Watcher watches APIPortals resources and builds configurations out of them.
 type Watcher struct {
-	portals     v1alpha1.APIPortalLister
-	gateways    v1alpha1.APIGatewayLister
-	apis        v1alpha1.APILister
-	collections v1alpha1.APICollectionLister
-	accesses    v1alpha1.APIAccessLister
+	provider Provider
 
-	refresh          chan struct{}
-	debounceDelay    time.Duration
-	maxDebounceDelay time.Duration
+	// queue holds the pending refresh of the portals. Refreshes are deduplicated while pending, never run
+	// concurrently, and retried with an exponential backoff when failing.
+	queue         workqueue.RateLimitingInterface
+	debounceDelay time.Duration
 
-	handler UpdatableHandler
+	handler         UpdatableHandler
+	notifier        *Notifier
+	catalogReporter CatalogReporter
+	logger          zerolog.Logger
 }
 
-// NewWatcher returns a new watcher to track API management resources. It calls the given UpdatableHandler when
-// a resource is modified.
-func NewWatcher(handler UpdatableHandler,
-	portals v1alpha1.APIPortalLister,
-	gateways v1alpha1.APIGatewayLister,
-	apis v1alpha1.APILister,
-	collections v1alpha1.APICollectionLister,
-	accesses v1alpha1.APIAccessLister,
-) *Watcher {
+// NewWatcher returns a new watcher to track the API management resources of the given provider. It calls the given
+// UpdatableHandler when a resource is modified and notifies the APIPortal webhooks of the APIs published or
+// unpublished on their portal, reading the secrets signing their events from the given Secrets. The catalog of the
+// portals is reported through the given CatalogReporter, which may be nil. The handler may be nil when the watcher isn't
+// run and only used to get the catalog.
+func NewWatcher(handler UpdatableHandler, provider Provider, secrets corev1client.SecretInterface, catalogReporter CatalogReporter, logger zerolog.Logger) *Watcher {
 	return &Watcher{
-		portals:     portals,
-		gateways:    gateways,
-		apis:        apis,
-		collections: collections,
-		accesses:    accesses,
-
-		refresh:          make(chan struct{}, 1),
-		debounceDelay:    2 * time.Second,
-		maxDebounceDelay: 10 * time.Second,
-
-		handler: handler,
+		provider: provider,
+
+		queue: workqueue.NewNamedRateLimitingQueue(
+			workqueue.NewItemExponentialFailureRateLimiter(time.Second, 5*time.Minute),
+			"devportal",
+		),
+		debounceDelay: 2 * time.Second,
+
+		handler:         handler,
+		notifier:        NewNotifier(secrets),
+		catalogReporter: catalogReporter,
+		logger:          logger,
 	}
 }
 
 // Run starts listening for changes on the cluster.
 func (w *Watcher) Run(ctx context.Context) {
-	refresh := debounce(ctx, w.refresh, w.debounceDelay, w.maxDebounceDelay)
+	go func() {
+		<-ctx.Done()
+		w.queue.ShutDown()
+	}()
 
-	for {
-		select {
-		case <-refresh:
-			portals, err := w.getPortals()
-			if err != nil {
-				log.Error().Err(err).Msg("Unable to get portals")
-				continue
-			}
+	for w.processNextRefresh(ctx) {
+	}
+}
 
-			if err = w.handler.Update(portals); err != nil {
-				log.Error().Err(err).Msg("Unable to update handler")
-				continue
-			}
-		case <-ctx.Done():
-			return
-		}
+// processNextRefresh waits for the next refresh and processes it. It returns false once the queue is shut down.
+func (w *Watcher) processNextRefresh(ctx context.Context) bool {
+	key, shutdown := w.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer w.queue.Done(key)
+
+	if err := w.refresh(ctx); err != nil {
+		w.logger.Error().
+			Err(err).
+			Int("retries", w.queue.NumRequeues(key)).
+			Msg("Unable to refresh portals, retrying")
+
+		w.queue.AddRateLimited(key)
+		return true
 	}
+
+	w.queue.Forget(key)
+
+	return true
 }
 
-// debounce listen for events on the source chan and emit an event on the debounced channel after waiting for
-// the given `delay` duration. Each additional event will wait an additional `delay` duration until it reach
-// the `maxDelay`.
-func debounce(ctx context.Context, sourceCh <-chan struct{}, delay, maxDelay time.Duration) <-chan struct{} {
-	debouncedCh := make(chan struct{})
-	var (
-		delayCh    <-chan time.Time
-		maxDelayCh <-chan time.Time
-	)
+func (w *Watcher) refresh(ctx context.Context) error {
+	portals, err := w.getPortals()
+	if err != nil {
+		return fmt.Errorf("get portals: %w", err)
+	}
 
-	go func() {
-		for {
-			select {
-			case <-sourceCh:
-				delayCh = time.After(delay)
-				if maxDelayCh == nil {
-					maxDelayCh = time.After(maxDelay)
-				}
+	if err = w.handler.Update(portals); err != nil {
+		return fmt.Errorf("update handler: %w", err)
+	}
 
-			case <-delayCh:
-				delayCh, maxDelayCh = nil, nil
-				debouncedCh <- struct{}{}
-			case <-maxDelayCh:
-				delayCh, maxDelayCh = nil, nil
-				debouncedCh <- struct{}{}
+	w.notifier.Notify(ctx, portals)
 
-			case <-ctx.Done():
-				return
-			}
-		}
-	}()
+	if w.catalogReporter != nil {
+		w.reportCatalog(ctx, portals)
+	}
+
+	return nil
+}
+
+// Refresh schedules a refresh of the portals. It is called by the providers which aren't backed by informers when
+// their resources change.
+func (w *Watcher) Refresh() {
+	w.enqueueRefresh()
+}
 
-	return debouncedCh
+// enqueueRefresh schedules a refresh of the portals once the debounce delay elapsed. The events received while the
+// refresh is pending are merged in it, which prevents bursts of changes from causing as many refreshes.
+func (w *Watcher) enqueueRefresh() {
+	w.queue.AddAfter(refreshKey, w.debounceDelay)
 }
 
 // OnAdd implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
@@ -159,24 +175,22 @@ func (w *Watcher) OnAdd(obj interface{}) {
 	case *hubv1alpha1.API:
 	case *hubv1alpha1.APICollection:
 	case *hubv1alpha1.APIAccess:
+	case *hubv1alpha1.APIRateLimit:
 
 	default:
-		log.Error().
+		w.logger.Error().
 			Str("component", "api_portal_watcher").
 			Str("type", fmt.Sprintf("%T", obj)).
 			Msg("Received add event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.enqueueRefresh()
 }
 
 // OnUpdate implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
 func (w *Watcher) OnUpdate(oldObj, newObj interface{}) {
-	logger := log.With().
+	logger := w.logger.With().
 		Str("component", "api_portal_watcher").
 		Str("type", fmt.Sprintf("%T", newObj)).
 		Logger()
@@ -209,15 +223,18 @@ func (w *Watcher) OnUpdate(oldObj, newObj interface{}) {
 			logger.Debug().Msg("No change detected on APIAccess, skipping")
 			return
 		}
+	case *hubv1alpha1.APIRateLimit:
+		// APIRateLimits have no status, their generation only changes with their spec.
+		if oldObj.(*hubv1alpha1.APIRateLimit).Generation == v.Generation {
+			logger.Debug().Msg("No change detected on APIRateLimit, skipping")
+			return
+		}
 	default:
 		logger.Error().Msg("Received update event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.enqueueRefresh()
 }
 
 // OnDelete implements Kubernetes cache.ResourceEventHandler so it can be used as an informer event handler.
@@ -228,28 +245,26 @@ func (w *Watcher) OnDelete(oldObj interface{}) {
 	case *hubv1alpha1.API:
 	case *hubv1alpha1.APICollection:
 	case *hubv1alpha1.APIAccess:
+	case *hubv1alpha1.APIRateLimit:
 
 	default:
-		log.Error().
+		w.logger.Error().
 			Str("component", "api_portal_watcher").
 			Str("type", fmt.Sprintf("%T", oldObj)).
 			Msg("Received delete event of unknown type")
 		return
 	}
 
-	select {
-	case w.refresh <- struct{}{}:
-	default:
-	}
+	w.enqueueRefresh()
 }
 
 func (w *Watcher) getPortals() ([]portal, error) {
-	apiPortals, err := w.portals.List(labels.Everything())
+	apiPortals, err := w.provider.APIPortals().List(labels.Everything())
 	if err != nil {
 		return nil, fmt.Errorf("list APIPortals: %w", err)
 	}
 
-	apiAccesses, err := w.accesses.List(labels.Everything())
+	apiAccesses, err := w.provider.APIAccesses().List(labels.Everything())
 	if err != nil {
 		return nil, fmt.Errorf("list APIAccesses: %w", err)
 	}
@@ -258,14 +273,19 @@ func (w *Watcher) getPortals() ([]portal, error) {
 		apiAccessByName[apiAccess.Name] = apiAccess
 	}
 
+	rateLimits, err := w.findRateLimits()
+	if err != nil {
+		return nil, err
+	}
+
 	var portals []portal
 	for _, apiPortal := range apiPortals {
 		var apiGateway *hubv1alpha1.APIGateway
 
-		apiGateway, err = w.gateways.Get(apiPortal.Spec.APIGateway)
+		apiGateway, err = w.provider.APIGateways().Get(apiPortal.Spec.APIGateway)
 		if err != nil {
 			if kerror.IsNotFound(err) {
-				log.Error().
+				w.logger.Error().
 					Str("portal_name", apiPortal.Name).
 					Str("gateway_name", apiPortal.Spec.APIGateway).
 					Msg("Unable to find APIGateway")
@@ -280,12 +300,14 @@ func (w *Watcher) getPortals() ([]portal, error) {
 			APIGateway:  *apiGateway,
 			Collections: make(map[string]collection),
 			APIs:        make(map[string]hubv1alpha1.API),
+			RateLimits:  make(map[string][]hubv1alpha1.APIRateLimit),
+			Consumers:   make(map[string]*consumers),
 		}
 
 		for _, apiAccessName := range apiGateway.Spec.APIAccesses {
 			apiAccess := apiAccessByName[apiAccessName]
 			if apiAccess == nil {
-				log.Error().
+				w.logger.Error().
 					Str("api_gateway_name", apiPortal.Spec.APIGateway).
 					Str("api_access_name", apiAccessName).
 					Msg("Unable to find APIAccess")
@@ -300,6 +322,11 @@ func (w *Watcher) getPortals() ([]portal, error) {
 
 			for k := range accessAPIs {
 				g.APIs[k] = accessAPIs[k]
+
+				if g.Consumers[k] == nil {
+					g.Consumers[k] = &consumers{}
+				}
+				g.Consumers[k].add(apiAccess)
 			}
 
 			collectionAPIs, err := w.findCollections(apiAccess.Spec.APICollectionSelector)
@@ -307,8 +334,27 @@ func (w *Watcher) getPortals() ([]portal, error) {
 				return nil, fmt.Errorf("find APIAccess %q APICollections: %w", apiAccessName, err)
 			}
 
-			for k := range collectionAPIs {
-				g.Collections[k] = collectionAPIs[k]
+			for k, c := range collectionAPIs {
+				c.Consumers = &consumers{}
+				if existing, ok := g.Collections[k]; ok {
+					c.Consumers = existing.Consumers
+				}
+				c.Consumers.add(apiAccess)
+
+				g.Collections[k] = c
+			}
+		}
+
+		for apiNameNamespace := range g.APIs {
+			if limits, ok := rateLimits[apiNameNamespace]; ok {
+				g.RateLimits[apiNameNamespace] = limits
+			}
+		}
+		for _, c := range g.Collections {
+			for apiNameNamespace := range c.APIs {
+				if limits, ok := rateLimits[apiNameNamespace]; ok {
+					g.RateLimits[apiNameNamespace] = limits
+				}
 			}
 		}
 
@@ -321,6 +367,32 @@ func (w *Watcher) getPortals() ([]portal, error) {
 	return portals, nil
 }
 
+// findRateLimits returns the APIRateLimits applied to each API, indexed by API and sorted by name.
+func (w *Watcher) findRateLimits() (map[string][]hubv1alpha1.APIRateLimit, error) {
+	apiRateLimits, err := w.provider.APIRateLimits().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list APIRateLimits: %w", err)
+	}
+
+	sort.Slice(apiRateLimits, func(i, j int) bool {
+		return apiRateLimits[i].Name < apiRateLimits[j].Name
+	})
+
+	rateLimits := make(map[string][]hubv1alpha1.APIRateLimit)
+	for _, apiRateLimit := range apiRateLimits {
+		apis, err := w.findAPIs(apiRateLimit.Spec.APISelector)
+		if err != nil {
+			return nil, fmt.Errorf("find APIRateLimit %q APIs: %w", apiRateLimit.Name, err)
+		}
+
+		for apiNameNamespace := range apis {
+			rateLimits[apiNameNamespace] = append(rateLimits[apiNameNamespace], *apiRateLimit)
+		}
+	}
+
+	return rateLimits, nil
+}
+
 func (w *Watcher) findAPIs(labelSelector *metav1.LabelSelector) (map[string]hubv1alpha1.API, error) {
 	if labelSelector == nil {
 		return nil, nil
@@ -328,13 +400,13 @@ func (w *Watcher) findAPIs(labelSelector *metav1.LabelSelector) (map[string]hubv
 
 	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
 	if err != nil {
-		log.Error().Err(err).
+		w.logger.Error().Err(err).
 			Str("selector", labelSelector.String()).
 			Msg("Invalid selector")
 		return nil, nil
 	}
 
-	apis, err := w.apis.List(selector)
+	apis, err := w.provider.APIs().List(selector)
 	if err != nil {
 		return nil, fmt.Errorf("list APIs using selector %q: %w", labelSelector.String(), err)
 	}
@@ -359,13 +431,13 @@ func (w *Watcher) findCollections(labelSelector *metav1.LabelSelector) (map[stri
 
 	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
 	if err != nil {
-		log.Error().Err(err).
+		w.logger.Error().Err(err).
 			Str("selector", selector.String()).
 			Msg("Invalid selector")
 		return nil, nil
 	}
 
-	collections, err := w.collections.List(selector)
+	collections, err := w.provider.APICollections().List(selector)
 	if err != nil {
 		return nil, fmt.Errorf("list APICollections using selector %q: %w", labelSelector.String(), err)
 	}
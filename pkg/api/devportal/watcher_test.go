@@ -19,12 +19,14 @@ package devportal
 
 import (
 	"context"
+	"errors"
 	"os"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -32,10 +34,11 @@ import (
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
-	listers "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
 )
 
 func TestWatcher_Run(t *testing.T) {
@@ -44,7 +47,7 @@ func TestWatcher_Run(t *testing.T) {
 	internalObjects := loadK8sObjects(t, clientSet, "./testdata/manifests/internal-portal.yaml")
 	externalObjects := loadK8sObjects(t, clientSet, "./testdata/manifests/external-portal.yaml")
 
-	portals, gateways, apis, collections, accesses := setupInformers(t, clientSet)
+	provider := setupProvider(t, clientSet)
 
 	wantPortals := []portal{
 		{
@@ -58,11 +61,21 @@ func TestWatcher_Run(t *testing.T) {
 							"books@products-ns": externalObjects.APIs["books@products-ns"],
 							"toys@products-ns":  externalObjects.APIs["toys@products-ns"],
 						},
+						Consumers: &consumers{Groups: []string{"supplier"}},
 					},
 				},
 				APIs: map[string]hubv1alpha1.API{
 					"search@default": externalObjects.APIs["search@default"],
 				},
+				RateLimits: map[string][]hubv1alpha1.APIRateLimit{
+					"search@default": {externalObjects.APIRateLimits["search"]},
+				},
+				Consumers: map[string]*consumers{
+					"search@default": {
+						Groups: []string{"consumer"},
+						Users:  []string{"jane.doe@example.com"},
+					},
+				},
 			},
 		},
 		{
@@ -73,6 +86,10 @@ func TestWatcher_Run(t *testing.T) {
 				APIs: map[string]hubv1alpha1.API{
 					"accounting-reports@accounting-ns": internalObjects.APIs["accounting-reports@accounting-ns"],
 				},
+				RateLimits: map[string][]hubv1alpha1.APIRateLimit{},
+				Consumers: map[string]*consumers{
+					"accounting-reports@accounting-ns": {Groups: []string{"accounting-team"}},
+				},
 			},
 		},
 	}
@@ -94,7 +111,7 @@ func TestWatcher_Run(t *testing.T) {
 		}).
 		TypedReturns(nil)
 
-	w := setupWatcher(t, handler, portals, gateways, apis, collections, accesses)
+	w := setupWatcher(t, handler, provider)
 
 	// Simulate k8s resource change.
 	w.OnAdd(&hubv1alpha1.APIGateway{})
@@ -102,9 +119,43 @@ func TestWatcher_Run(t *testing.T) {
 	w.Run(ctx)
 }
 
+func TestWatcher_Run_retriesFailedRefresh(t *testing.T) {
+	clientSet := hubkubemock.NewSimpleClientset()
+	provider := setupProvider(t, clientSet)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var calls int
+	handler := newUpdatableHandlerMock(t)
+	handler.OnUpdateRaw(mock.Anything).
+		Run(func(args mock.Arguments) {
+			calls++
+		}).
+		TypedReturns(errors.New("boom")).Once()
+	handler.OnUpdateRaw(mock.Anything).
+		Run(func(args mock.Arguments) {
+			calls++
+			cancel()
+		}).
+		TypedReturns(nil).Once()
+
+	w := setupWatcher(t, handler, provider)
+	w.queue = workqueue.NewRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Millisecond))
+
+	// Simulate a burst of k8s resource changes, merged in a single refresh.
+	w.OnAdd(&hubv1alpha1.APIGateway{})
+	w.OnAdd(&hubv1alpha1.API{})
+	w.OnDelete(&hubv1alpha1.APIAccess{})
+
+	w.Run(ctx)
+
+	assert.Equal(t, 2, calls)
+}
+
 func TestWatcher_OnAdd(t *testing.T) {
 	clientSet := hubkubemock.NewSimpleClientset()
-	portals, gateways, apis, collections, accesses := setupInformers(t, clientSet)
+	provider := setupProvider(t, clientSet)
 
 	tests := []struct {
 		desc   string
@@ -115,6 +166,7 @@ func TestWatcher_OnAdd(t *testing.T) {
 		{desc: "API", object: &hubv1alpha1.API{}},
 		{desc: "APICollection", object: &hubv1alpha1.APICollection{}},
 		{desc: "APIAccess", object: &hubv1alpha1.APIAccess{}},
+		{desc: "APIRateLimit", object: &hubv1alpha1.APIRateLimit{}},
 	}
 
 	for _, test := range tests {
@@ -132,7 +184,7 @@ func TestWatcher_OnAdd(t *testing.T) {
 				}).
 				TypedReturns(nil)
 
-			w := setupWatcher(t, handler, portals, gateways, apis, collections, accesses)
+			w := setupWatcher(t, handler, provider)
 
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 			defer cancel()
@@ -152,7 +204,7 @@ func TestWatcher_OnAdd(t *testing.T) {
 
 func TestWatcher_OnDelete(t *testing.T) {
 	clientSet := hubkubemock.NewSimpleClientset()
-	portals, gateways, apis, collections, accesses := setupInformers(t, clientSet)
+	provider := setupProvider(t, clientSet)
 
 	tests := []struct {
 		desc   string
@@ -163,6 +215,7 @@ func TestWatcher_OnDelete(t *testing.T) {
 		{desc: "API", object: &hubv1alpha1.API{}},
 		{desc: "APICollection", object: &hubv1alpha1.APICollection{}},
 		{desc: "APIAccess", object: &hubv1alpha1.APIAccess{}},
+		{desc: "APIRateLimit", object: &hubv1alpha1.APIRateLimit{}},
 	}
 
 	for _, test := range tests {
@@ -180,7 +233,7 @@ func TestWatcher_OnDelete(t *testing.T) {
 				}).
 				TypedReturns(nil)
 
-			w := setupWatcher(t, handler, portals, gateways, apis, collections, accesses)
+			w := setupWatcher(t, handler, provider)
 
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 			defer cancel()
@@ -200,7 +253,7 @@ func TestWatcher_OnDelete(t *testing.T) {
 
 func TestWatcher_OnUpdate(t *testing.T) {
 	clientSet := hubkubemock.NewSimpleClientset()
-	portals, gateways, apis, collections, accesses := setupInformers(t, clientSet)
+	provider := setupProvider(t, clientSet)
 
 	tests := []struct {
 		desc       string
@@ -283,6 +336,19 @@ func TestWatcher_OnUpdate(t *testing.T) {
 			newObject:  &hubv1alpha1.APIAccess{Status: hubv1alpha1.APIAccessStatus{Hash: "v2"}},
 			wantUpdate: true,
 		},
+
+		{
+			desc:       "APIRateLimit: same generation",
+			oldObject:  &hubv1alpha1.APIRateLimit{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			newObject:  &hubv1alpha1.APIRateLimit{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			wantUpdate: false,
+		},
+		{
+			desc:       "APIRateLimit: different generation",
+			oldObject:  &hubv1alpha1.APIRateLimit{ObjectMeta: metav1.ObjectMeta{Generation: 1}},
+			newObject:  &hubv1alpha1.APIRateLimit{ObjectMeta: metav1.ObjectMeta{Generation: 2}},
+			wantUpdate: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -301,7 +367,7 @@ func TestWatcher_OnUpdate(t *testing.T) {
 				TypedReturns(nil).
 				Maybe()
 
-			w := setupWatcher(t, handler, portals, gateways, apis, collections, accesses)
+			w := setupWatcher(t, handler, provider)
 
 			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 			defer cancel()
@@ -372,6 +438,7 @@ type k8sObjects struct {
 	APICollections map[string]hubv1alpha1.APICollection
 	APIs           map[string]hubv1alpha1.API
 	APIAccesses    map[string]hubv1alpha1.APIAccess
+	APIRateLimits  map[string]hubv1alpha1.APIRateLimit
 
 	accessor meta.MetadataAccessor
 }
@@ -387,6 +454,7 @@ func newK8sObjects() *k8sObjects {
 		APICollections: make(map[string]hubv1alpha1.APICollection),
 		APIs:           make(map[string]hubv1alpha1.API),
 		APIAccesses:    make(map[string]hubv1alpha1.APIAccess),
+		APIRateLimits:  make(map[string]hubv1alpha1.APIRateLimit),
 	}
 }
 
@@ -411,19 +479,24 @@ func (o *k8sObjects) Add(t *testing.T, object runtime.Object) {
 		o.APIs[name+"@"+namespace] = *object.(*hubv1alpha1.API)
 	case "APIAccess":
 		o.APIAccesses[name] = *object.(*hubv1alpha1.APIAccess)
+	case "APIRateLimit":
+		o.APIRateLimits[name] = *object.(*hubv1alpha1.APIRateLimit)
 	}
 }
 
-func setupInformers(t *testing.T, clientSet *hubkubemock.Clientset) (listers.APIPortalLister, listers.APIGatewayLister, listers.APILister, listers.APICollectionLister, listers.APIAccessLister) {
+func setupProvider(t *testing.T, clientSet *hubkubemock.Clientset) *KubernetesProvider {
 	t.Helper()
 
 	hubInformer := hubinformer.NewSharedInformerFactory(clientSet, 5*time.Minute)
+	provider := NewKubernetesProvider(hubInformer.Hub().V1alpha1())
 
-	portals := hubInformer.Hub().V1alpha1().APIPortals().Lister()
-	gateways := hubInformer.Hub().V1alpha1().APIGateways().Lister()
-	apis := hubInformer.Hub().V1alpha1().APIs().Lister()
-	collections := hubInformer.Hub().V1alpha1().APICollections().Lister()
-	accesses := hubInformer.Hub().V1alpha1().APIAccesses().Lister()
+	// Informers are only started once their listers are requested.
+	provider.APIPortals()
+	provider.APIGateways()
+	provider.APIs()
+	provider.APICollections()
+	provider.APIAccesses()
+	provider.APIRateLimits()
 
 	ctx := context.Background()
 	hubInformer.Start(ctx.Done())
@@ -431,22 +504,14 @@ func setupInformers(t *testing.T, clientSet *hubkubemock.Clientset) (listers.API
 		require.True(t, ok)
 	}
 
-	return portals, gateways, apis, collections, accesses
+	return provider
 }
 
-func setupWatcher(t *testing.T,
-	handler UpdatableHandler,
-	portals listers.APIPortalLister,
-	gateways listers.APIGatewayLister,
-	apis listers.APILister,
-	collections listers.APICollectionLister,
-	accesses listers.APIAccessLister,
-) *Watcher {
+func setupWatcher(t *testing.T, handler UpdatableHandler, provider Provider) *Watcher {
 	t.Helper()
 
-	w := NewWatcher(handler, portals, gateways, apis, collections, accesses)
+	w := NewWatcher(handler, provider, nil, nil, zerolog.Nop())
 	w.debounceDelay = 0
-	w.maxDebounceDelay = 0
 
 	return w
 }
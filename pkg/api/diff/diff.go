@@ -0,0 +1,190 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Kinds of change.
+const (
+	KindBreaking    = "breaking"
+	KindNonBreaking = "non-breaking"
+)
+
+// Change is a difference found between two OpenAPI specs.
+type Change struct {
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Report lists the changes made to an OpenAPI spec.
+type Report struct {
+	Breaking bool     `json:"breaking"`
+	Changes  []Change `json:"changes"`
+}
+
+// Compare reports the changes made on the base spec to obtain the revision spec. A change is breaking when clients
+// built against the base spec may no longer work with the revision. Changes are reported in a deterministic order.
+func Compare(base, revision *openapi3.T) Report {
+	var c comparison
+
+	if base.Info != nil && revision.Info != nil && base.Info.Version != revision.Info.Version {
+		c.add(KindNonBreaking, "info.version", fmt.Sprintf("Version changed from %q to %q", base.Info.Version, revision.Info.Version))
+	}
+
+	for _, p := range unionKeys(base.Paths, revision.Paths) {
+		basePathItem, revisionPathItem := base.Paths[p], revision.Paths[p]
+		path := "paths." + p
+
+		switch {
+		case revisionPathItem == nil:
+			c.add(KindBreaking, path, "Path removed")
+		case basePathItem == nil:
+			c.add(KindNonBreaking, path, "Path added")
+		default:
+			c.comparePathItems(path, basePathItem, revisionPathItem)
+		}
+	}
+
+	return Report{
+		Breaking: c.breaking,
+		Changes:  c.changes,
+	}
+}
+
+type comparison struct {
+	breaking bool
+	changes  []Change
+}
+
+func (c *comparison) add(kind, path, message string) {
+	if kind == KindBreaking {
+		c.breaking = true
+	}
+
+	c.changes = append(c.changes, Change{Kind: kind, Path: path, Message: message})
+}
+
+func (c *comparison) comparePathItems(path string, base, revision *openapi3.PathItem) {
+	baseOperations, revisionOperations := base.Operations(), revision.Operations()
+
+	for _, method := range unionKeys(baseOperations, revisionOperations) {
+		baseOperation, revisionOperation := baseOperations[method], revisionOperations[method]
+		operationPath := path + "." + method
+
+		switch {
+		case revisionOperation == nil:
+			c.add(KindBreaking, operationPath, "Operation removed")
+		case baseOperation == nil:
+			c.add(KindNonBreaking, operationPath, "Operation added")
+		default:
+			c.compareOperations(operationPath,
+				mergeParameters(base.Parameters, baseOperation.Parameters), baseOperation,
+				mergeParameters(revision.Parameters, revisionOperation.Parameters), revisionOperation)
+		}
+	}
+}
+
+func (c *comparison) compareOperations(path string, baseParams map[string]*openapi3.Parameter, base *openapi3.Operation, revisionParams map[string]*openapi3.Parameter, revision *openapi3.Operation) {
+	for _, name := range unionKeys(baseParams, revisionParams) {
+		baseParam, revisionParam := baseParams[name], revisionParams[name]
+		paramPath := path + ".parameters." + name
+
+		switch {
+		case revisionParam == nil:
+			c.add(KindBreaking, paramPath, "Parameter removed")
+		case baseParam == nil && revisionParam.Required:
+			c.add(KindBreaking, paramPath, "Required parameter added")
+		case baseParam == nil:
+			c.add(KindNonBreaking, paramPath, "Optional parameter added")
+		case !baseParam.Required && revisionParam.Required:
+			c.add(KindBreaking, paramPath, "Parameter became required")
+		case baseParam.Required && !revisionParam.Required:
+			c.add(KindNonBreaking, paramPath, "Parameter became optional")
+		}
+	}
+
+	baseBodyRequired, revisionBodyRequired := isRequestBodyRequired(base), isRequestBodyRequired(revision)
+	switch {
+	case !baseBodyRequired && revisionBodyRequired:
+		c.add(KindBreaking, path+".requestBody", "Request body became required")
+	case baseBodyRequired && !revisionBodyRequired:
+		c.add(KindNonBreaking, path+".requestBody", "Request body became optional")
+	}
+
+	for _, status := range unionKeys(base.Responses, revision.Responses) {
+		baseResp, revisionResp := base.Responses[status], revision.Responses[status]
+		responsePath := path + ".responses." + status
+
+		switch {
+		case revisionResp == nil:
+			c.add(KindBreaking, responsePath, "Response removed")
+		case baseResp == nil:
+			c.add(KindNonBreaking, responsePath, "Response added")
+		}
+	}
+
+	if !base.Deprecated && revision.Deprecated {
+		c.add(KindNonBreaking, path, "Operation deprecated")
+	}
+}
+
+// mergeParameters merges the parameters of an operation with the ones of its path item, indexing them by location
+// and name. Operation parameters override path item ones.
+func mergeParameters(pathItemParams, operationParams openapi3.Parameters) map[string]*openapi3.Parameter {
+	params := make(map[string]*openapi3.Parameter)
+	for _, paramRefs := range []openapi3.Parameters{pathItemParams, operationParams} {
+		for _, paramRef := range paramRefs {
+			if paramRef == nil || paramRef.Value == nil {
+				continue
+			}
+
+			params[paramRef.Value.In+"."+paramRef.Value.Name] = paramRef.Value
+		}
+	}
+
+	return params
+}
+
+func isRequestBodyRequired(operation *openapi3.Operation) bool {
+	return operation.RequestBody != nil && operation.RequestBody.Value != nil && operation.RequestBody.Value.Required
+}
+
+// unionKeys returns the sorted union of the keys of the given maps.
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		seen[k] = struct{}{}
+	}
+	for k := range b {
+		seen[k] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
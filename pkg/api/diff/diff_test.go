@@ -0,0 +1,218 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseSpec = `
+openapi: 3.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      parameters:
+        - name: limit
+          in: query
+      responses:
+        "200":
+          description: Books
+    post:
+      requestBody:
+        content:
+          application/json: {}
+      responses:
+        "201":
+          description: Created
+  /books/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+    get:
+      responses:
+        "200":
+          description: Book
+        "404":
+          description: Not found
+`
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		desc     string
+		revision string
+		want     Report
+	}{
+		{
+			desc:     "no changes",
+			revision: baseSpec,
+			want:     Report{},
+		},
+		{
+			desc: "non-breaking changes",
+			revision: `
+openapi: 3.0.0
+info:
+  title: Books
+  version: 1.1.0
+paths:
+  /books:
+    get:
+      deprecated: true
+      parameters:
+        - name: limit
+          in: query
+        - name: offset
+          in: query
+      responses:
+        "200":
+          description: Books
+        "400":
+          description: Bad request
+    post:
+      requestBody:
+        content:
+          application/json: {}
+      responses:
+        "201":
+          description: Created
+  /books/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+    get:
+      responses:
+        "200":
+          description: Book
+        "404":
+          description: Not found
+    delete:
+      responses:
+        "204":
+          description: Deleted
+  /authors:
+    get:
+      responses:
+        "200":
+          description: Authors
+`,
+			want: Report{
+				Changes: []Change{
+					{Kind: KindNonBreaking, Path: "info.version", Message: `Version changed from "1.0.0" to "1.1.0"`},
+					{Kind: KindNonBreaking, Path: "paths./authors", Message: "Path added"},
+					{Kind: KindNonBreaking, Path: "paths./books.GET.parameters.query.offset", Message: "Optional parameter added"},
+					{Kind: KindNonBreaking, Path: "paths./books.GET.responses.400", Message: "Response added"},
+					{Kind: KindNonBreaking, Path: "paths./books.GET", Message: "Operation deprecated"},
+					{Kind: KindNonBreaking, Path: "paths./books/{id}.DELETE", Message: "Operation added"},
+				},
+			},
+		},
+		{
+			desc: "breaking changes",
+			revision: `
+openapi: 3.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+        - name: X-Tenant
+          in: header
+          required: true
+      responses:
+        "200":
+          description: Books
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json: {}
+      responses:
+        "201":
+          description: Created
+  /books/{id}:
+    get:
+      responses:
+        "200":
+          description: Book
+`,
+			want: Report{
+				Breaking: true,
+				Changes: []Change{
+					{Kind: KindBreaking, Path: "paths./books.GET.parameters.header.X-Tenant", Message: "Required parameter added"},
+					{Kind: KindBreaking, Path: "paths./books.GET.parameters.query.limit", Message: "Parameter became required"},
+					{Kind: KindBreaking, Path: "paths./books.POST.requestBody", Message: "Request body became required"},
+					{Kind: KindBreaking, Path: "paths./books/{id}.GET.parameters.path.id", Message: "Parameter removed"},
+					{Kind: KindBreaking, Path: "paths./books/{id}.GET.responses.404", Message: "Response removed"},
+				},
+			},
+		},
+		{
+			desc: "path removed",
+			revision: `
+openapi: 3.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      parameters:
+        - name: limit
+          in: query
+      responses:
+        "200":
+          description: Books
+`,
+			want: Report{
+				Breaking: true,
+				Changes: []Change{
+					{Kind: KindBreaking, Path: "paths./books.POST", Message: "Operation removed"},
+					{Kind: KindBreaking, Path: "paths./books/{id}", Message: "Path removed"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			base, err := openapi3.NewLoader().LoadFromData([]byte(baseSpec))
+			require.NoError(t, err)
+			revision, err := openapi3.NewLoader().LoadFromData([]byte(test.revision))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, Compare(base, revision))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import "time"
+
+// ExampleQuery selects the request/response examples captured for an API.
+type ExampleQuery struct {
+	Portal string
+	// API is the API, formatted as name@namespace.
+	API string
+	// Version is the name of the API version. All the examples of the API are selected when empty.
+	Version string
+}
+
+// APIExample is a request/response pair captured by a gateway for an operation of an API.
+type APIExample struct {
+	// Method and Path identify the operation, Path being the path template of the operation, such as /books/{id}.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	Request    ExampleRequest  `json:"request"`
+	Response   ExampleResponse `json:"response"`
+	CapturedAt time.Time       `json:"capturedAt"`
+}
+
+// ExampleRequest is a captured request.
+type ExampleRequest struct {
+	// URL is the path, along with the query, of the request.
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// ExampleResponse is a captured response.
+type ExampleResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
@@ -35,6 +35,10 @@ type Gateway struct {
 	Labels      map[string]string `json:"labels,omitempty"`
 	Accesses    []string          `json:"accesses,omitempty"`
 
+	CredentialLocations []string         `json:"credentialLocations,omitempty"`
+	ExampleCapture      *ExampleCapture  `json:"exampleCapture,omitempty"`
+	IdentityHeaders     *IdentityHeaders `json:"identityHeaders,omitempty"`
+
 	Version string `json:"version"`
 
 	HubDomain     string         `json:"hubDomain,omitempty"`
@@ -52,8 +56,11 @@ func (g *Gateway) Resource() (*hubv1alpha1.APIGateway, error) {
 	}
 
 	spec := hubv1alpha1.APIGatewaySpec{
-		APIAccesses:   g.Accesses,
-		CustomDomains: customDomains,
+		APIAccesses:         g.Accesses,
+		CustomDomains:       customDomains,
+		CredentialLocations: g.CredentialLocations,
+		ExampleCapture:      g.ExampleCapture.resource(),
+		IdentityHeaders:     g.IdentityHeaders.resource(),
 	}
 
 	var urls []string
@@ -102,6 +109,10 @@ type gatewayHash struct {
 	Accesses      []string          `json:"accesses,omitempty"`
 	HubDomain     string            `json:"hubDomain,omitempty"`
 	CustomDomains []string          `json:"customDomains,omitempty"`
+
+	CredentialLocations []string                               `json:"credentialLocations,omitempty"`
+	ExampleCapture      *hubv1alpha1.APIGatewayExampleCapture  `json:"exampleCapture,omitempty"`
+	IdentityHeaders     *hubv1alpha1.APIGatewayIdentityHeaders `json:"identityHeaders,omitempty"`
 }
 
 // HashGateway generates the hash of the APIGateway.
@@ -111,6 +122,10 @@ func HashGateway(g *hubv1alpha1.APIGateway) (string, error) {
 		Accesses:      g.Spec.APIAccesses,
 		HubDomain:     g.Status.HubDomain,
 		CustomDomains: g.Spec.CustomDomains,
+
+		CredentialLocations: g.Spec.CredentialLocations,
+		ExampleCapture:      g.Spec.ExampleCapture,
+		IdentityHeaders:     g.Spec.IdentityHeaders,
 	}
 
 	h, err := sum(gh)
@@ -120,3 +135,45 @@ func HashGateway(g *hubv1alpha1.APIGateway) (string, error) {
 
 	return base64.StdEncoding.EncodeToString(h), nil
 }
+
+// ExampleCapture configures the capture of request/response examples by a gateway.
+type ExampleCapture struct {
+	SampleRate              int      `json:"sampleRate,omitempty"`
+	MaxExamplesPerOperation int      `json:"maxExamplesPerOperation,omitempty"`
+	MaxBodySize             *int     `json:"maxBodySize,omitempty"`
+	RedactedHeaders         []string `json:"redactedHeaders,omitempty"`
+	RedactedFields          []string `json:"redactedFields,omitempty"`
+}
+
+func (e *ExampleCapture) resource() *hubv1alpha1.APIGatewayExampleCapture {
+	if e == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.APIGatewayExampleCapture{
+		SampleRate:              e.SampleRate,
+		MaxExamplesPerOperation: e.MaxExamplesPerOperation,
+		MaxBodySize:             e.MaxBodySize,
+		RedactedHeaders:         e.RedactedHeaders,
+		RedactedFields:          e.RedactedFields,
+	}
+}
+
+// IdentityHeaders configures the names of the headers holding the identity of the consumers of a gateway.
+type IdentityHeaders struct {
+	User   string `json:"user,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Groups string `json:"groups,omitempty"`
+}
+
+func (i *IdentityHeaders) resource() *hubv1alpha1.APIGatewayIdentityHeaders {
+	if i == nil {
+		return nil
+	}
+
+	return &hubv1alpha1.APIGatewayIdentityHeaders{
+		User:   i.User,
+		Email:  i.Email,
+		Groups: i.Groups,
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import "time"
+
+// Group is a group of users pushed by an identity provider.
+type Group struct {
+	WorkspaceID string `json:"workspaceId"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ExternalID  string `json:"externalId,omitempty"`
+
+	Members []GroupMember `json:"members"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Version   string    `json:"version"`
+}
+
+// GroupMember is a member of a Group.
+type GroupMember struct {
+	ID      string `json:"id"`
+	Display string `json:"display,omitempty"`
+}
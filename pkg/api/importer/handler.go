@@ -0,0 +1,277 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package importer
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/yaml"
+)
+
+// maxIndexSize is the maximum size of an index document.
+const maxIndexSize = 4 << 20
+
+// Import actions.
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionError     = "error"
+)
+
+// Index is a document listing the APIs to import, each one mapping a service to the URL of its OpenAPI spec.
+type Index struct {
+	APIs []Entry `json:"apis"`
+}
+
+// Entry is an API to import.
+type Entry struct {
+	// Name is the name of the API. Defaults to the name of the service.
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace of the API and its service. Defaults to "default".
+	Namespace string `json:"namespace,omitempty"`
+	// PathPrefix is the path prefix of the API. Defaults to "/" followed by the name of the API.
+	PathPrefix string `json:"pathPrefix,omitempty"`
+	// Service is the name of the service serving the API.
+	Service string `json:"service"`
+	// Port is the port number or name of the service.
+	Port intstr.IntOrString `json:"port"`
+	// SpecURL is the URL of the OpenAPI spec of the API.
+	SpecURL string `json:"specUrl,omitempty"`
+}
+
+// Result is the outcome of the import of an API.
+type Result struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Action    string `json:"action"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the outcome of the import of an index.
+type Report struct {
+	DryRun  bool     `json:"dryRun"`
+	Results []Result `json:"results"`
+}
+
+// Handler imports, in batch, the APIs listed in an index document, in JSON or YAML. APIs are created, or updated
+// when they already exist. With the dryRun query parameter, the actions are reported without being applied.
+type Handler struct {
+	hubClientSet hubclientset.Interface
+	token        string
+}
+
+// NewHandler returns a new Handler authenticating callers with the given bearer token.
+func NewHandler(hubClientSet hubclientset.Interface, token string) *Handler {
+	return &Handler{
+		hubClientSet: hubClientSet,
+		token:        token,
+	}
+}
+
+// ServeHTTP serves HTTP requests.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	want := "Bearer " + h.token
+	if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte(want)) != 1 {
+		http.Error(rw, "Invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	var dryRun bool
+	if v := req.URL.Query().Get("dryRun"); v != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("Invalid dryRun parameter %q", v), http.StatusBadRequest)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxIndexSize+1))
+	if err != nil {
+		http.Error(rw, "Unable to read index", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxIndexSize {
+		http.Error(rw, "Index too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	var index Index
+	if err = yaml.UnmarshalStrict(body, &index); err != nil {
+		http.Error(rw, fmt.Sprintf("Invalid index: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	report := Report{DryRun: dryRun}
+	for _, entry := range index.APIs {
+		report.Results = append(report.Results, h.importAPI(req.Context(), entry, dryRun))
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(rw).Encode(report); err != nil {
+		log.Error().Err(err).Msg("Unable to write API import report")
+	}
+}
+
+func (h *Handler) importAPI(ctx context.Context, entry Entry, dryRun bool) Result {
+	api, err := entry.api()
+	if err != nil {
+		name := entry.Name
+		if name == "" {
+			name = entry.Service
+		}
+
+		return Result{Name: name, Namespace: entry.Namespace, Action: ActionError, Error: err.Error()}
+	}
+
+	result := Result{Name: api.Name, Namespace: api.Namespace}
+
+	existing, err := h.hubClientSet.HubV1alpha1().APIs(api.Namespace).Get(ctx, api.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		result.Action = ActionError
+		result.Error = fmt.Sprintf("get API: %s", err)
+
+		return result
+	}
+
+	if kerror.IsNotFound(err) {
+		result.Action = ActionCreate
+		if dryRun {
+			return result
+		}
+
+		if _, err = h.hubClientSet.HubV1alpha1().APIs(api.Namespace).Create(ctx, api, metav1.CreateOptions{}); err != nil {
+			result.Action = ActionError
+			result.Error = fmt.Sprintf("create API: %s", err)
+		}
+
+		return result
+	}
+
+	// Only the imported fields are updated, the rest of the API configuration is kept.
+	updated := existing.DeepCopy()
+	updated.Spec.PathPrefix = api.Spec.PathPrefix
+	updated.Spec.Service.Name = api.Spec.Service.Name
+	updated.Spec.Service.Port = api.Spec.Service.Port
+	if api.Spec.Service.OpenAPISpec.URL != "" {
+		updated.Spec.Service.OpenAPISpec.URL = api.Spec.Service.OpenAPISpec.URL
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, updated.Spec) {
+		result.Action = ActionUnchanged
+		return result
+	}
+
+	result.Action = ActionUpdate
+	if dryRun {
+		return result
+	}
+
+	if _, err = h.hubClientSet.HubV1alpha1().APIs(api.Namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		result.Action = ActionError
+		result.Error = fmt.Sprintf("update API: %s", err)
+	}
+
+	return result
+}
+
+// api builds the API described by the entry.
+func (e Entry) api() (*hubv1alpha1.API, error) {
+	if e.Service == "" {
+		return nil, errors.New("service is required")
+	}
+
+	var port hubv1alpha1.APIServiceBackendPort
+	switch {
+	case e.Port.Type == intstr.String && e.Port.StrVal != "":
+		port.Name = e.Port.StrVal
+	case e.Port.Type == intstr.Int && e.Port.IntVal > 0 && e.Port.IntVal <= 65535:
+		port.Number = e.Port.IntVal
+	default:
+		return nil, fmt.Errorf("invalid port %q", e.Port.String())
+	}
+
+	if e.SpecURL != "" {
+		u, err := url.Parse(e.SpecURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid spec URL %q", e.SpecURL)
+		}
+	}
+
+	name := e.Name
+	if name == "" {
+		name = e.Service
+	}
+
+	namespace := e.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	pathPrefix := e.PathPrefix
+	if pathPrefix == "" {
+		pathPrefix = "/" + name
+	}
+	if !strings.HasPrefix(pathPrefix, "/") {
+		return nil, fmt.Errorf("path prefix %q must start with a /", pathPrefix)
+	}
+
+	return &hubv1alpha1.API{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "API",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: pathPrefix,
+			Service: hubv1alpha1.APIService{
+				Name: e.Service,
+				Port: port,
+				OpenAPISpec: hubv1alpha1.OpenAPISpec{
+					URL: e.SpecURL,
+				},
+			},
+		},
+	}, nil
+}
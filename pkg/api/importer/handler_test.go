@@ -0,0 +1,201 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package importer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testToken = "token"
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	const index = `
+apis:
+  - service: books
+    port: 8080
+    specUrl: https://example.com/books/openapi.json
+  - name: users
+    namespace: accounts
+    pathPrefix: /v2/users
+    service: users-svc
+    port: http
+  - service: orders
+    port: 80
+  - service: invalid
+    port: 80
+    specUrl: ftp://example.com/spec.json
+  - port: 80
+`
+
+	tests := []struct {
+		desc        string
+		path        string
+		token       string
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantBooks   *hubv1alpha1.APISpec
+		wantCreated bool
+	}{
+		{
+			desc:       "invalid token",
+			path:       "/api-import",
+			token:      "invalid",
+			body:       index,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "invalid index",
+			path:       "/api-import",
+			body:       `{"apis": [{"unknown": true}]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "invalid dry run parameter",
+			path:       "/api-import?dryRun=maybe",
+			body:       index,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "dry run",
+			path:       "/api-import?dryRun=true",
+			body:       index,
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"dryRun": true,
+				"results": [
+					{"name": "books", "namespace": "default", "action": "update"},
+					{"name": "users", "namespace": "accounts", "action": "create"},
+					{"name": "orders", "namespace": "default", "action": "unchanged"},
+					{"name": "invalid", "namespace": "", "action": "error", "error": "invalid spec URL \"ftp://example.com/spec.json\""},
+					{"name": "", "namespace": "", "action": "error", "error": "service is required"}
+				]
+			}`,
+			wantBooks: &hubv1alpha1.APISpec{
+				PathPrefix: "/books",
+				Service: hubv1alpha1.APIService{
+					Name: "books",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+				},
+			},
+		},
+		{
+			desc:       "import",
+			path:       "/api-import",
+			body:       index,
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"dryRun": false,
+				"results": [
+					{"name": "books", "namespace": "default", "action": "update"},
+					{"name": "users", "namespace": "accounts", "action": "create"},
+					{"name": "orders", "namespace": "default", "action": "unchanged"},
+					{"name": "invalid", "namespace": "", "action": "error", "error": "invalid spec URL \"ftp://example.com/spec.json\""},
+					{"name": "", "namespace": "", "action": "error", "error": "service is required"}
+				]
+			}`,
+			wantBooks: &hubv1alpha1.APISpec{
+				PathPrefix: "/books",
+				Service: hubv1alpha1.APIService{
+					Name: "books",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 8080},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{
+						URL: "https://example.com/books/openapi.json",
+					},
+				},
+			},
+			wantCreated: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			hubClientSet := hubkubemock.NewSimpleClientset(
+				newAPI("books", "/books", "books", 80),
+				newAPI("orders", "/orders", "orders", 80),
+			)
+
+			token := testToken
+			if test.token != "" {
+				token = test.token
+			}
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "http://example.com"+test.path, strings.NewReader(test.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			NewHandler(hubClientSet, testToken).ServeHTTP(rw, req)
+
+			require.Equal(t, test.wantStatus, rw.Code, rw.Body.String())
+
+			if test.wantBody == "" {
+				return
+			}
+			assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+			assert.JSONEq(t, test.wantBody, rw.Body.String())
+
+			ctx := context.Background()
+			books, err := hubClientSet.HubV1alpha1().APIs("default").Get(ctx, "books", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, *test.wantBooks, books.Spec)
+
+			users, err := hubClientSet.HubV1alpha1().APIs("accounts").Get(ctx, "users", metav1.GetOptions{})
+			if !test.wantCreated {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, hubv1alpha1.APISpec{
+				PathPrefix: "/v2/users",
+				Service: hubv1alpha1.APIService{
+					Name: "users-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Name: "http"},
+				},
+			}, users.Spec)
+		})
+	}
+}
+
+func newAPI(name, pathPrefix, service string, port int32) *hubv1alpha1.API {
+	return &hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: pathPrefix,
+			Service: hubv1alpha1.APIService{
+				Name: service,
+				Port: hubv1alpha1.APIServiceBackendPort{Number: port},
+			},
+		},
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"sigs.k8s.io/yaml"
+)
+
+// Rule names.
+const (
+	RuleOperationIDRequired = "operation-id-required"
+	RuleInlineSchemaMaxSize = "inline-schema-max-size"
+	RuleSemanticVersion     = "semantic-version"
+)
+
+// Severity is the severity of a rule violation.
+type Severity string
+
+// Severities.
+const (
+	SeverityOff   Severity = "off"
+	SeverityWarn  Severity = "warn"
+	SeverityError Severity = "error"
+)
+
+// Ruleset configures the rules evaluated against OpenAPI specs, in the spirit of Spectral rulesets:
+//
+//	rules:
+//	  operation-id-required: error
+//	  inline-schema-max-size:
+//	    severity: warn
+//	    maxBytes: 1024
+//	  semantic-version: warn
+type Ruleset struct {
+	Rules Rules `json:"rules"`
+}
+
+// Rules holds the configuration of each rule. A rule with no severity is disabled.
+type Rules struct {
+	OperationIDRequired Severity                `json:"operation-id-required,omitempty"`
+	InlineSchemaMaxSize InlineSchemaMaxSizeRule `json:"inline-schema-max-size,omitempty"`
+	SemanticVersion     Severity                `json:"semantic-version,omitempty"`
+}
+
+// InlineSchemaMaxSizeRule limits the size of the schemas declared inline rather than referenced from the components.
+type InlineSchemaMaxSizeRule struct {
+	Severity Severity `json:"severity,omitempty"`
+	MaxBytes int      `json:"maxBytes,omitempty"`
+}
+
+// DefaultRuleset returns the ruleset used when none is configured.
+func DefaultRuleset() Ruleset {
+	return Ruleset{
+		Rules: Rules{
+			OperationIDRequired: SeverityWarn,
+			InlineSchemaMaxSize: InlineSchemaMaxSizeRule{Severity: SeverityWarn, MaxBytes: 2048},
+			SemanticVersion:     SeverityWarn,
+		},
+	}
+}
+
+// LoadRuleset loads a YAML or JSON ruleset from the given file.
+func LoadRuleset(path string) (Ruleset, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Ruleset{}, fmt.Errorf("read ruleset: %w", err)
+	}
+
+	var ruleset Ruleset
+	if err = yaml.UnmarshalStrict(b, &ruleset); err != nil {
+		return Ruleset{}, fmt.Errorf("unmarshal ruleset: %w", err)
+	}
+
+	if err = ruleset.validate(); err != nil {
+		return Ruleset{}, fmt.Errorf("invalid ruleset: %w", err)
+	}
+
+	return ruleset, nil
+}
+
+func (r Ruleset) validate() error {
+	severities := map[string]Severity{
+		RuleOperationIDRequired: r.Rules.OperationIDRequired,
+		RuleInlineSchemaMaxSize: r.Rules.InlineSchemaMaxSize.Severity,
+		RuleSemanticVersion:     r.Rules.SemanticVersion,
+	}
+	for rule, severity := range severities {
+		switch severity {
+		case "", SeverityOff, SeverityWarn, SeverityError:
+		default:
+			return fmt.Errorf("rule %q: unknown severity %q", rule, severity)
+		}
+	}
+
+	if enabled(r.Rules.InlineSchemaMaxSize.Severity) && r.Rules.InlineSchemaMaxSize.MaxBytes <= 0 {
+		return fmt.Errorf("rule %q: maxBytes must be positive", RuleInlineSchemaMaxSize)
+	}
+
+	return nil
+}
+
+// Result is a rule violation.
+type Result struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+}
+
+// Lint evaluates the rules of the ruleset against the given spec. Results are reported in a deterministic order.
+func (r Ruleset) Lint(spec *openapi3.T) []Result {
+	var results []Result
+
+	if enabled(r.Rules.SemanticVersion) {
+		results = append(results, checkSemanticVersion(spec, r.Rules.SemanticVersion)...)
+	}
+
+	for _, p := range sortedPaths(spec) {
+		for _, method := range sortedMethods(spec.Paths[p]) {
+			operation := spec.Paths[p].GetOperation(method)
+			path := fmt.Sprintf("paths.%s.%s", p, method)
+
+			if enabled(r.Rules.OperationIDRequired) && operation.OperationID == "" {
+				results = append(results, Result{
+					Rule:     RuleOperationIDRequired,
+					Severity: r.Rules.OperationIDRequired,
+					Path:     path,
+					Message:  "Operation must have an operationId",
+				})
+			}
+
+			if enabled(r.Rules.InlineSchemaMaxSize.Severity) {
+				results = append(results, checkInlineSchemas(operation, path, r.Rules.InlineSchemaMaxSize)...)
+			}
+		}
+	}
+
+	return results
+}
+
+// HasErrors returns whether the given results contain an error.
+func HasErrors(results []Result) bool {
+	for _, result := range results {
+		if result.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// semverRegexp is the regular expression suggested by https://semver.org.
+var semverRegexp = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+func checkSemanticVersion(spec *openapi3.T, severity Severity) []Result {
+	var version string
+	if spec.Info != nil {
+		version = spec.Info.Version
+	}
+
+	if semverRegexp.MatchString(version) {
+		return nil
+	}
+
+	return []Result{{
+		Rule:     RuleSemanticVersion,
+		Severity: severity,
+		Path:     "info.version",
+		Message:  fmt.Sprintf("Version %q is not a semantic version", version),
+	}}
+}
+
+func checkInlineSchemas(operation *openapi3.Operation, path string, rule InlineSchemaMaxSizeRule) []Result {
+	schemas := make(map[string]*openapi3.SchemaRef)
+
+	for i, param := range operation.Parameters {
+		if param.Value != nil && param.Ref == "" {
+			schemas[fmt.Sprintf("%s.parameters.%d.schema", path, i)] = param.Value.Schema
+		}
+	}
+	if operation.RequestBody != nil && operation.RequestBody.Ref == "" && operation.RequestBody.Value != nil {
+		for mediaType, content := range operation.RequestBody.Value.Content {
+			schemas[fmt.Sprintf("%s.requestBody.content.%s.schema", path, mediaType)] = content.Schema
+		}
+	}
+	for status, resp := range operation.Responses {
+		if resp.Ref != "" || resp.Value == nil {
+			continue
+		}
+		for mediaType, content := range resp.Value.Content {
+			schemas[fmt.Sprintf("%s.responses.%s.content.%s.schema", path, status, mediaType)] = content.Schema
+		}
+	}
+
+	var results []Result
+	for schemaPath, schema := range schemas {
+		if schema == nil || schema.Ref != "" || schema.Value == nil {
+			continue
+		}
+
+		b, err := json.Marshal(schema.Value)
+		if err != nil || len(b) <= rule.MaxBytes {
+			continue
+		}
+
+		results = append(results, Result{
+			Rule:     RuleInlineSchemaMaxSize,
+			Severity: rule.Severity,
+			Path:     schemaPath,
+			Message:  fmt.Sprintf("Inline schema is %d bytes long, move it to the components to stay under %d bytes", len(b), rule.MaxBytes),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Path < results[j].Path
+	})
+
+	return results
+}
+
+func enabled(severity Severity) bool {
+	return severity != "" && severity != SeverityOff
+}
+
+func sortedPaths(spec *openapi3.T) []string {
+	paths := make([]string, 0, len(spec.Paths))
+	for p := range spec.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
+func sortedMethods(pathItem *openapi3.PathItem) []string {
+	operations := pathItem.Operations()
+
+	methods := make([]string, 0, len(operations))
+	for method := range operations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleset_Lint(t *testing.T) {
+	tests := []struct {
+		desc        string
+		ruleset     Ruleset
+		wantResults []Result
+	}{
+		{
+			desc:    "default ruleset",
+			ruleset: DefaultRuleset(),
+			wantResults: []Result{
+				{
+					Rule:     RuleSemanticVersion,
+					Severity: SeverityWarn,
+					Path:     "info.version",
+					Message:  `Version "v1" is not a semantic version`,
+				},
+				{
+					Rule:     RuleOperationIDRequired,
+					Severity: SeverityWarn,
+					Path:     "paths./products.POST",
+					Message:  "Operation must have an operationId",
+				},
+			},
+		},
+		{
+			desc: "small inline schemas",
+			ruleset: Ruleset{Rules: Rules{
+				InlineSchemaMaxSize: InlineSchemaMaxSizeRule{Severity: SeverityError, MaxBytes: 64},
+			}},
+			wantResults: []Result{
+				{
+					Rule:     RuleInlineSchemaMaxSize,
+					Severity: SeverityError,
+					Path:     "paths./products.POST.requestBody.content.application/json.schema",
+					Message:  "Inline schema is 205 bytes long, move it to the components to stay under 64 bytes",
+				},
+			},
+		},
+		{
+			desc: "disabled rules",
+			ruleset: Ruleset{Rules: Rules{
+				OperationIDRequired: SeverityOff,
+				SemanticVersion:     SeverityOff,
+			}},
+		},
+	}
+
+	spec, err := openapi3.NewLoader().LoadFromFile(filepath.Join("testdata", "spec.yaml"))
+	require.NoError(t, err)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.wantResults, test.ruleset.Lint(spec))
+		})
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.False(t, HasErrors(nil))
+	assert.False(t, HasErrors([]Result{{Severity: SeverityWarn}}))
+	assert.True(t, HasErrors([]Result{{Severity: SeverityWarn}, {Severity: SeverityError}}))
+}
+
+func TestLoadRuleset(t *testing.T) {
+	ruleset, err := LoadRuleset(filepath.Join("testdata", "ruleset.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, Ruleset{Rules: Rules{
+		OperationIDRequired: SeverityError,
+		InlineSchemaMaxSize: InlineSchemaMaxSizeRule{Severity: SeverityWarn, MaxBytes: 64},
+		SemanticVersion:     SeverityOff,
+	}}, ruleset)
+}
+
+func TestLoadRuleset_invalid(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content string
+	}{
+		{
+			desc:    "unknown rule",
+			content: "rules:\n  unknown: error\n",
+		},
+		{
+			desc:    "unknown severity",
+			content: "rules:\n  operation-id-required: fatal\n",
+		},
+		{
+			desc:    "missing inline schema max size",
+			content: "rules:\n  inline-schema-max-size:\n    severity: warn\n",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "ruleset.yaml")
+			err := os.WriteFile(path, []byte(test.content), 0o600)
+			require.NoError(t, err)
+
+			_, err = LoadRuleset(path)
+			assert.Error(t, err)
+		})
+	}
+}
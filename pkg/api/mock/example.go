@@ -0,0 +1,157 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxDepth is the maximum depth of the examples generated from schemas, preventing recursive schemas from looping.
+const maxDepth = 8
+
+// Example returns an example of the given content. Explicit examples take precedence over the ones generated from
+// the schema.
+func Example(content *openapi3.MediaType) interface{} {
+	if content.Example != nil {
+		return content.Example
+	}
+
+	names := make([]string, 0, len(content.Examples))
+	for name := range content.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if example := content.Examples[name]; example != nil && example.Value != nil && example.Value.Value != nil {
+			return example.Value.Value
+		}
+	}
+
+	return schemaExample(content.Schema, 0)
+}
+
+func schemaExample(schemaRef *openapi3.SchemaRef, depth int) interface{} {
+	if schemaRef == nil || schemaRef.Value == nil || depth > maxDepth {
+		return nil
+	}
+	schema := schemaRef.Value
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		return allOfExample(schema.AllOf, depth)
+	case len(schema.OneOf) > 0:
+		return schemaExample(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return schemaExample(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.Type {
+	case openapi3.TypeObject, "":
+		if schema.Type == "" && len(schema.Properties) == 0 {
+			return nil
+		}
+
+		example := make(map[string]interface{}, len(schema.Properties))
+		for name, property := range schema.Properties {
+			if property == nil || property.Value == nil || property.Value.WriteOnly {
+				continue
+			}
+			example[name] = schemaExample(property, depth+1)
+		}
+
+		return example
+
+	case openapi3.TypeArray:
+		item := schemaExample(schema.Items, depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+
+		return []interface{}{item}
+
+	case openapi3.TypeString:
+		return stringExample(schema.Format)
+
+	case openapi3.TypeInteger:
+		if schema.Min != nil {
+			return int64(*schema.Min)
+		}
+		return 0
+
+	case openapi3.TypeNumber:
+		if schema.Min != nil {
+			return *schema.Min
+		}
+		return 0.0
+
+	case openapi3.TypeBoolean:
+		return true
+
+	default:
+		return nil
+	}
+}
+
+// allOfExample merges the examples of the given schemas, when they are objects.
+func allOfExample(schemas openapi3.SchemaRefs, depth int) interface{} {
+	merged := make(map[string]interface{})
+	for _, schema := range schemas {
+		example, ok := schemaExample(schema, depth+1).(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for k, v := range example {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date":
+		return "2023-01-01"
+	case "date-time":
+		return "2023-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "uri", "url":
+		return "https://example.com"
+	case "ipv4":
+		return "192.0.2.1"
+	case "ipv6":
+		return "2001:db8::1"
+	case "hostname":
+		return "example.com"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/rs/zerolog/log"
+)
+
+// Handler serves mock responses generated from the examples and schemas of an OpenAPI spec.
+// The response code can be chosen using the "Prefer: code=<status>" header.
+type Handler struct {
+	spec *openapi3.T
+}
+
+// NewHandler returns a new Handler mocking the operations of the given spec.
+func NewHandler(spec *openapi3.T) *Handler {
+	return &Handler{spec: spec}
+}
+
+// ServeHTTP serves a mock response for the operation matching the request.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	pathItem, ok := h.findPathItem(req.URL.Path)
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	operation := pathItem.GetOperation(req.Method)
+	if operation == nil {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	status, resp, ok := findResponse(operation, preferredCode(req))
+	if !ok {
+		rw.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	mediaType, content := findContent(resp)
+	if content == nil {
+		rw.WriteHeader(status)
+		return
+	}
+
+	rw.Header().Set("Content-Type", mediaType)
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(Example(content)); err != nil {
+		log.Error().Err(err).Msg("Unable to write mock response")
+	}
+}
+
+// findPathItem finds the path item matching the given path. Paths without parameters take precedence over templated ones.
+func (h *Handler) findPathItem(path string) (*openapi3.PathItem, bool) {
+	if pathItem, ok := h.spec.Paths[path]; ok {
+		return pathItem, true
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	templates := make([]string, 0, len(h.spec.Paths))
+	for template := range h.spec.Paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	for _, template := range templates {
+		if matchTemplate(strings.Split(strings.Trim(template, "/"), "/"), segments) {
+			return h.spec.Paths[template], true
+		}
+	}
+
+	return nil, false
+}
+
+func matchTemplate(template, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+
+		if segment != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// preferredCode returns the response code asked for through the Prefer header, if any.
+func preferredCode(req *http.Request) string {
+	for _, pref := range strings.Split(req.Header.Get("Prefer"), ",") {
+		if code, ok := strings.CutPrefix(strings.TrimSpace(pref), "code="); ok {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// findResponse finds the response to mock: the one with the given code if any, otherwise the successful response
+// with the lowest code, falling back on the default response.
+func findResponse(operation *openapi3.Operation, code string) (int, *openapi3.Response, bool) {
+	if code != "" {
+		status, err := strconv.Atoi(code)
+		resp := operation.Responses[code]
+		if err != nil || resp == nil || resp.Value == nil {
+			return 0, nil, false
+		}
+
+		return status, resp.Value, true
+	}
+
+	codes := make([]string, 0, len(operation.Responses))
+	for c := range operation.Responses {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+
+	for _, c := range codes {
+		status, err := strconv.Atoi(c)
+		if err != nil || status < 200 || status >= 300 || operation.Responses[c].Value == nil {
+			continue
+		}
+
+		return status, operation.Responses[c].Value, true
+	}
+
+	if resp := operation.Responses.Default(); resp != nil && resp.Value != nil {
+		return http.StatusOK, resp.Value, true
+	}
+
+	return 0, nil, false
+}
+
+// findContent finds the content of the response to mock, JSON being preferred.
+func findContent(resp *openapi3.Response) (string, *openapi3.MediaType) {
+	if content := resp.Content.Get("application/json"); content != nil {
+		return "application/json", content
+	}
+
+	mediaTypes := make([]string, 0, len(resp.Content))
+	for mediaType := range resp.Content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		if strings.HasSuffix(mediaType, "json") {
+			return mediaType, resp.Content[mediaType]
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spec = `
+openapi: 3.0.0
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      responses:
+        "200":
+          description: Books
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Book"
+    post:
+      responses:
+        "201":
+          description: Created
+          content:
+            application/json:
+              example: {"id": "42"}
+        "400":
+          description: Bad request
+          content:
+            application/problem+json:
+              examples:
+                invalid:
+                  value: {"title": "Invalid book"}
+  /books/{id}:
+    delete:
+      responses:
+        "204":
+          description: Deleted
+components:
+  schemas:
+    Book:
+      type: object
+      properties:
+        id:
+          type: string
+          format: uuid
+        title:
+          type: string
+          example: The Hobbit
+        genre:
+          type: string
+          enum: [fantasy, thriller]
+        pages:
+          type: integer
+          minimum: 1
+        published:
+          type: boolean
+        author:
+          allOf:
+            - type: object
+              properties:
+                name:
+                  type: string
+            - type: object
+              properties:
+                email:
+                  type: string
+                  format: email
+        password:
+          type: string
+          writeOnly: true
+`
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc            string
+		method          string
+		path            string
+		prefer          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			desc:            "generated from schema",
+			method:          http.MethodGet,
+			path:            "/books",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody: `[{
+				"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+				"title": "The Hobbit",
+				"genre": "fantasy",
+				"pages": 1,
+				"published": true,
+				"author": {"name": "string", "email": "user@example.com"}
+			}]`,
+		},
+		{
+			desc:            "explicit example",
+			method:          http.MethodPost,
+			path:            "/books",
+			wantStatus:      http.StatusCreated,
+			wantContentType: "application/json",
+			wantBody:        `{"id": "42"}`,
+		},
+		{
+			desc:            "preferred code",
+			method:          http.MethodPost,
+			path:            "/books",
+			prefer:          "code=400",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json",
+			wantBody:        `{"title": "Invalid book"}`,
+		},
+		{
+			desc:       "unknown preferred code",
+			method:     http.MethodPost,
+			path:       "/books",
+			prefer:     "code=500",
+			wantStatus: http.StatusNotImplemented,
+		},
+		{
+			desc:       "templated path without content",
+			method:     http.MethodDelete,
+			path:       "/books/42",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			desc:       "unknown path",
+			method:     http.MethodGet,
+			path:       "/authors",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "unknown method",
+			method:     http.MethodPut,
+			path:       "/books/42",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	loaded, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+
+	handler := NewHandler(loaded)
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, test.path, http.NoBody)
+			if test.prefer != "" {
+				req.Header.Set("Prefer", test.prefer)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.wantStatus, rw.Code)
+			assert.Equal(t, test.wantContentType, rw.Header().Get("Content-Type"))
+			if test.wantBody != "" {
+				assert.JSONEq(t, test.wantBody, rw.Body.String())
+			}
+		})
+	}
+}
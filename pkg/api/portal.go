@@ -33,9 +33,11 @@ type Portal struct {
 	ClusterID   string `json:"clusterId"`
 	Name        string `json:"name"`
 
-	Title       string `json:"title,omitempty"`
-	Description string `json:"description,omitempty"`
-	Gateway     string `json:"gateway"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Gateway     string   `json:"gateway"`
+	Theme       *Theme   `json:"theme,omitempty"`
+	Webhook     *Webhook `json:"webhook,omitempty"`
 
 	HubDomain     string         `json:"hubDomain,omitempty"`
 	CustomDomains []CustomDomain `json:"customDomains,omitempty"`
@@ -51,6 +53,26 @@ type CustomDomain struct {
 	Verified bool   `json:"verified"`
 }
 
+// Theme customizes the look of a portal WebUI.
+type Theme struct {
+	LogoURL      string `json:"logoUrl,omitempty"`
+	PrimaryColor string `json:"primaryColor,omitempty"`
+	AccentColor  string `json:"accentColor,omitempty"`
+}
+
+// Webhook configures the webhook notified of the portal events.
+type Webhook struct {
+	URL       string            `json:"url"`
+	SecretRef *WebhookSecretRef `json:"secretRef,omitempty"`
+}
+
+// WebhookSecretRef references the key of the Secret, in the agent namespace, holding the secret signing the portal
+// events.
+type WebhookSecretRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
 // Resource builds the v1alpha1 APIPortal resource.
 func (p *Portal) Resource() (*hubv1alpha1.APIPortal, error) {
 	var customDomains []string
@@ -65,6 +87,21 @@ func (p *Portal) Resource() (*hubv1alpha1.APIPortal, error) {
 		CustomDomains: customDomains,
 	}
 
+	if p.Theme != nil {
+		spec.Theme = &hubv1alpha1.APIPortalTheme{
+			LogoURL:      p.Theme.LogoURL,
+			PrimaryColor: p.Theme.PrimaryColor,
+			AccentColor:  p.Theme.AccentColor,
+		}
+	}
+
+	if p.Webhook != nil {
+		spec.Webhook = &hubv1alpha1.APIPortalWebhook{URL: p.Webhook.URL}
+		if ref := p.Webhook.SecretRef; ref != nil {
+			spec.Webhook.SecretRef = &hubv1alpha1.APIPortalWebhookSecretRef{Name: ref.Name, Key: ref.Key}
+		}
+	}
+
 	var urls []string
 	var verifiedCustomDomains []string
 	for _, customDomain := range p.CustomDomains {
@@ -106,11 +143,13 @@ func (p *Portal) Resource() (*hubv1alpha1.APIPortal, error) {
 }
 
 type portalHash struct {
-	Title         string   `json:"title,omitempty"`
-	Description   string   `json:"description,omitempty"`
-	Gateway       string   `json:"gateway"`
-	HubDomain     string   `json:"hubDomain,omitempty"`
-	CustomDomains []string `json:"customDomains,omitempty"`
+	Title         string                        `json:"title,omitempty"`
+	Description   string                        `json:"description,omitempty"`
+	Gateway       string                        `json:"gateway"`
+	HubDomain     string                        `json:"hubDomain,omitempty"`
+	CustomDomains []string                      `json:"customDomains,omitempty"`
+	Theme         *hubv1alpha1.APIPortalTheme   `json:"theme,omitempty"`
+	Webhook       *hubv1alpha1.APIPortalWebhook `json:"webhook,omitempty"`
 }
 
 // HashPortal generates the hash of the APIPortal.
@@ -121,6 +160,8 @@ func HashPortal(p *hubv1alpha1.APIPortal) (string, error) {
 		Gateway:       p.Spec.APIGateway,
 		HubDomain:     p.Status.HubDomain,
 		CustomDomains: p.Spec.CustomDomains,
+		Theme:         p.Spec.Theme,
+		Webhook:       p.Spec.Webhook,
 	}
 
 	h, err := sum(ph)
@@ -0,0 +1,194 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hublisters "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+// GatewayRender holds the resources generated by the WatcherGateway to expose the APIs of an APIGateway.
+type GatewayRender struct {
+	Gateway string
+	// Objects are the Middlewares, TraefikServices and Ingresses generated for the gateway, namespace by namespace.
+	Objects []runtime.Object
+	// Warnings explain why some APIs may not be routable.
+	Warnings []string
+}
+
+// Renderer renders the resources the WatcherGateway generates for APIGateways, from a given set of API management
+// resources instead of the cluster state. It doesn't create anything, which makes it suitable to debug APIs which
+// aren't routable.
+type Renderer struct {
+	watcher *WatcherGateway
+
+	apis        hublisters.APILister
+	collections hublisters.APICollectionLister
+	accesses    hublisters.APIAccessLister
+}
+
+// NewRenderer returns a Renderer using the given configuration and rendering APIGateways from the given objects.
+// APIs, APICollections and APIAccesses are indexed while other objects are ignored.
+func NewRenderer(config *WatcherGatewayConfig, objects []runtime.Object) (*Renderer, error) {
+	newIndexer := func() cache.Indexer {
+		return cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+	apis, collections, accesses := newIndexer(), newIndexer(), newIndexer()
+
+	for _, obj := range objects {
+		var indexer cache.Indexer
+		switch obj.(type) {
+		case *hubv1alpha1.API:
+			indexer = apis
+		case *hubv1alpha1.APICollection:
+			indexer = collections
+		case *hubv1alpha1.APIAccess:
+			indexer = accesses
+		default:
+			continue
+		}
+
+		if err := indexer.Add(obj); err != nil {
+			return nil, fmt.Errorf("index %T: %w", obj, err)
+		}
+	}
+
+	return &Renderer{
+		watcher:     &WatcherGateway{config: config},
+		apis:        hublisters.NewAPILister(apis),
+		collections: hublisters.NewAPICollectionLister(collections),
+		accesses:    hublisters.NewAPIAccessLister(accesses),
+	}, nil
+}
+
+// Render renders the resources generated for the given gateway.
+func (r *Renderer) Render(gateway *hubv1alpha1.APIGateway) (*GatewayRender, error) {
+	render := &GatewayRender{Gateway: gateway.Name}
+
+	for _, accessName := range gateway.Spec.APIAccesses {
+		if _, err := r.accesses.Get(accessName); kerror.IsNotFound(err) {
+			render.Warnings = append(render.Warnings, fmt.Sprintf("APIAccess %q not found", accessName))
+		}
+	}
+
+	apisByNamespace, err := gatewayAPIsByNamespace(gateway, r.accesses.Get, r.apis, r.collections)
+	if err != nil {
+		return nil, fmt.Errorf("load gateway APIs by namespace: %w", err)
+	}
+
+	if len(apisByNamespace) == 0 {
+		render.Warnings = append(render.Warnings, "no API is selected by the APIAccesses of the gateway")
+	}
+	if gateway.Status.HubDomain == "" {
+		render.Warnings = append(render.Warnings, "no hub domain assigned by the platform: the hub domain Ingresses have no host")
+	}
+	if len(gateway.Spec.CustomDomains) != 0 && len(gateway.Status.CustomDomains) == 0 {
+		render.Warnings = append(render.Warnings, "no custom domain verified by the platform: no custom domains Ingress is generated")
+	}
+
+	namespaces := make([]string, 0, len(apisByNamespace))
+	for namespace := range apisByNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		// Listers return APIs in no particular order, they are sorted for the render to be stable.
+		apis := apisByNamespace[namespace]
+		sort.Slice(apis, func(i, j int) bool {
+			if apis[i].Spec.PathPrefix != apis[j].Spec.PathPrefix {
+				return apis[i].Spec.PathPrefix < apis[j].Spec.PathPrefix
+			}
+			return apis[i].Name < apis[j].Name
+		})
+
+		objects, err := r.renderNamespace(gateway, namespace, apis)
+		if err != nil {
+			return nil, fmt.Errorf("render namespace %q: %w", namespace, err)
+		}
+
+		render.Objects = append(render.Objects, objects...)
+	}
+
+	return render, nil
+}
+
+// renderNamespace renders the resources generated in the given namespace, mirroring WatcherGateway.upsertIngresses.
+func (r *Renderer) renderNamespace(gateway *hubv1alpha1.APIGateway, namespace string, apis []*hubv1alpha1.API) ([]runtime.Object, error) {
+	var objects []runtime.Object
+
+	name, err := getStripPrefixMiddlewareName(gateway.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get stripPrefix middleware name: %w", err)
+	}
+	stripPrefix := newStripPrefixMiddleware(name, namespace, apis)
+	objects = append(objects, &stripPrefix)
+
+	traefikMiddlewareName, err := getTraefikStripPrefixMiddlewareName(namespace, gateway.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get Traefik stripPrefix middleware name: %w", err)
+	}
+
+	if r.watcher.config.AuthServerAddr != "" {
+		name, err = getAPITokenMiddlewareName(gateway.Name)
+		if err != nil {
+			return nil, fmt.Errorf("get API token middleware name: %w", err)
+		}
+		apiToken := newAPITokenMiddleware(name, namespace, r.watcher.config.AuthServerAddr, gateway)
+		objects = append(objects, &apiToken)
+
+		traefikMiddlewareName = fmt.Sprintf("%s-%s@kubernetescrd", namespace, name) + "," + traefikMiddlewareName
+	}
+
+	rendered := make(map[string]struct{})
+	for _, api := range apis {
+		if _, ok := rendered[api.Name]; ok || len(api.Spec.Service.Weighted) == 0 {
+			continue
+		}
+		rendered[api.Name] = struct{}{}
+
+		name, err = getWeightedServiceName(api.Name)
+		if err != nil {
+			return nil, fmt.Errorf("get weighted service name: %w", err)
+		}
+		svc := newWeightedService(name, api)
+		objects = append(objects, &svc)
+	}
+
+	ingress, err := r.watcher.buildHubDomainIngress(namespace, gateway, apis, traefikMiddlewareName)
+	if err != nil {
+		return nil, fmt.Errorf("build ingress for hub domain: %w", err)
+	}
+	objects = append(objects, ingress)
+
+	if len(gateway.Status.CustomDomains) != 0 {
+		ingress, err = r.watcher.buildCustomDomainsIngress(namespace, gateway, apis, traefikMiddlewareName)
+		if err != nil {
+			return nil, fmt.Errorf("build ingress for custom domains: %w", err)
+		}
+		objects = append(objects, ingress)
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	objects := []runtime.Object{
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "products", Labels: map[string]string{"area": "products"}},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/books",
+				Service: hubv1alpha1.APIService{
+					Name: "books-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+					Weighted: []hubv1alpha1.APIWeightedService{
+						{Name: "books-v2-svc", Port: hubv1alpha1.APIServiceBackendPort{Number: 80}, Weight: 10},
+					},
+				},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores", Namespace: "stores", Labels: map[string]string{"team": "stores"}},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/stores",
+				Service: hubv1alpha1.APIService{
+					Name: "stores-svc",
+					Port: hubv1alpha1.APIServiceBackendPort{Name: "http"},
+				},
+			},
+		},
+		&hubv1alpha1.APICollection{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores", Labels: map[string]string{"area": "stores"}},
+			Spec: hubv1alpha1.APICollectionSpec{
+				PathPrefix:  "/v1",
+				APISelector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "stores"}},
+			},
+		},
+		&hubv1alpha1.APIAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "products"},
+			Spec: hubv1alpha1.APIAccessSpec{
+				APISelector: &metav1.LabelSelector{MatchLabels: map[string]string{"area": "products"}},
+			},
+		},
+		&hubv1alpha1.APIAccess{
+			ObjectMeta: metav1.ObjectMeta{Name: "stores"},
+			Spec: hubv1alpha1.APIAccessSpec{
+				APICollectionSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"area": "stores"}},
+			},
+		},
+	}
+
+	renderer, err := NewRenderer(&WatcherGatewayConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikAPIEntryPoint:    "api-entrypoint",
+		TraefikTunnelEntryPoint: "tunnel-entrypoint",
+		AuthServerAddr:          "http://auth-server",
+	}, objects)
+	require.NoError(t, err)
+
+	gateway := &hubv1alpha1.APIGateway{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "hub.traefik.io/v1alpha1", Kind: "APIGateway"},
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway"},
+		Spec: hubv1alpha1.APIGatewaySpec{
+			APIAccesses:   []string{"products", "stores", "unknown"},
+			CustomDomains: []string{"api.example.com"},
+		},
+		Status: hubv1alpha1.APIGatewayStatus{
+			HubDomain: "brave-lion-123.hub-traefik.io",
+		},
+	}
+
+	render, err := renderer.Render(gateway)
+	require.NoError(t, err)
+
+	assert.Equal(t, "gateway", render.Gateway)
+	assert.Equal(t, []string{
+		`APIAccess "unknown" not found`,
+		"no custom domain verified by the platform: no custom domains Ingress is generated",
+	}, render.Warnings)
+
+	type object struct {
+		kind      string
+		namespace string
+		name      string
+	}
+	var got []object
+	for _, obj := range render.Objects {
+		accessor, ok := obj.(metav1.Object)
+		require.True(t, ok)
+
+		got = append(got, object{
+			kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+			namespace: accessor.GetNamespace(),
+			name:      accessor.GetName(),
+		})
+	}
+	assert.Equal(t, []object{
+		{kind: "Middleware", namespace: "products", name: "gateway-3056690829-stripprefix"},
+		{kind: "Middleware", namespace: "products", name: "gateway-3056690829-apitoken"},
+		{kind: "TraefikService", namespace: "products", name: "books-2792366217-weighted"},
+		{kind: "Ingress", namespace: "products", name: "gateway-3056690829-hub"},
+		{kind: "Middleware", namespace: "stores", name: "gateway-3056690829-stripprefix"},
+		{kind: "Middleware", namespace: "stores", name: "gateway-3056690829-apitoken"},
+		{kind: "Ingress", namespace: "stores", name: "gateway-3056690829-hub"},
+	}, got)
+
+	stripPrefix, ok := render.Objects[4].(*traefikv1alpha1.Middleware)
+	require.True(t, ok)
+	assert.Equal(t, []string{"/v1/stores"}, stripPrefix.Spec.StripPrefix.Prefixes)
+
+	ingress, ok := render.Objects[6].(*netv1.Ingress)
+	require.True(t, ok)
+	assert.Equal(t, "stores-gateway-3056690829-apitoken@kubernetescrd,stores-gateway-3056690829-stripprefix@kubernetescrd",
+		ingress.Annotations["traefik.ingress.kubernetes.io/router.middlewares"])
+	require.Len(t, ingress.Spec.Rules, 1)
+	assert.Equal(t, "brave-lion-123.hub-traefik.io", ingress.Spec.Rules[0].Host)
+	assert.Equal(t, "/v1/stores", ingress.Spec.Rules[0].HTTP.Paths[0].Path)
+}
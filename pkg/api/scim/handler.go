@@ -0,0 +1,298 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package scim
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+type groupService interface {
+	CreateGroup(ctx context.Context, req *platform.CreateGroupReq) (*api.Group, error)
+	GetGroups(ctx context.Context) ([]api.Group, error)
+	UpdateGroup(ctx context.Context, id, lastKnownVersion string, req *platform.UpdateGroupReq) (*api.Group, error)
+	DeleteGroup(ctx context.Context, id, lastKnownVersion string) error
+}
+
+// Handler is a SCIM 2.0 service provider allowing identity providers to push groups and their members to the
+// Hub platform. Only the Groups resource type is supported.
+type Handler struct {
+	router   chi.Router
+	platform groupService
+	token    string
+}
+
+// NewHandler returns a new Handler authenticating identity providers with the given bearer token.
+func NewHandler(platform groupService, token string) *Handler {
+	h := &Handler{
+		router:   chi.NewRouter(),
+		platform: platform,
+		token:    token,
+	}
+
+	h.router.Use(h.authenticate)
+	h.router.Get("/Groups", h.handleListGroups)
+	h.router.Post("/Groups", h.handleCreateGroup)
+	h.router.Get("/Groups/{id}", h.handleGetGroup)
+	h.router.Put("/Groups/{id}", h.handleReplaceGroup)
+	h.router.Patch("/Groups/{id}", h.handlePatchGroup)
+	h.router.Delete("/Groups/{id}", h.handleDeleteGroup)
+
+	return h
+}
+
+// ServeHTTP serves HTTP requests.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.router.ServeHTTP(rw, req)
+}
+
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		want := "Bearer " + h.token
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte(want)) != 1 {
+			writeError(rw, http.StatusUnauthorized, "", "Invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+var displayNameFilterRegexp = regexp.MustCompile(`^displayName eq "([^"]*)"$`)
+
+func (h *Handler) handleListGroups(rw http.ResponseWriter, req *http.Request) {
+	var displayName string
+	if filter := req.URL.Query().Get("filter"); filter != "" {
+		matches := displayNameFilterRegexp.FindStringSubmatch(filter)
+		if matches == nil {
+			writeError(rw, http.StatusBadRequest, "invalidFilter", fmt.Sprintf("Unsupported filter %q", filter))
+			return
+		}
+		displayName = matches[1]
+	}
+
+	groups, err := h.platform.GetGroups(req.Context())
+	if err != nil {
+		h.writePlatformError(rw, err, "Unable to list groups")
+		return
+	}
+
+	resp := listResponse{
+		Schemas:    []string{schemaListResponse},
+		StartIndex: 1,
+		Resources:  make([]group, 0, len(groups)),
+	}
+	for i := range groups {
+		if displayName != "" && groups[i].Name != displayName {
+			continue
+		}
+
+		resp.Resources = append(resp.Resources, fromGroup(&groups[i], location(req, groups[i].ID)))
+	}
+	resp.TotalResults = len(resp.Resources)
+	resp.ItemsPerPage = len(resp.Resources)
+
+	writeJSON(rw, http.StatusOK, resp)
+}
+
+func (h *Handler) handleCreateGroup(rw http.ResponseWriter, req *http.Request) {
+	var g group
+	if err := json.NewDecoder(req.Body).Decode(&g); err != nil {
+		writeError(rw, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	if g.DisplayName == "" {
+		writeError(rw, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	created, err := h.platform.CreateGroup(req.Context(), &platform.CreateGroupReq{
+		Name:       g.DisplayName,
+		ExternalID: g.ExternalID,
+		Members:    toMembers(g.Members),
+	})
+	if err != nil {
+		h.writePlatformError(rw, err, "Unable to create group")
+		return
+	}
+
+	loc := location(req, created.ID)
+	rw.Header().Set("Location", loc)
+	writeJSON(rw, http.StatusCreated, fromGroup(created, loc))
+}
+
+func (h *Handler) handleGetGroup(rw http.ResponseWriter, req *http.Request) {
+	g, ok := h.findGroup(rw, req)
+	if !ok {
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, fromGroup(g, location(req, g.ID)))
+}
+
+func (h *Handler) handleReplaceGroup(rw http.ResponseWriter, req *http.Request) {
+	var g group
+	if err := json.NewDecoder(req.Body).Decode(&g); err != nil {
+		writeError(rw, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	existing, ok := h.findGroup(rw, req)
+	if !ok {
+		return
+	}
+
+	h.updateGroup(rw, req, existing, g)
+}
+
+func (h *Handler) handlePatchGroup(rw http.ResponseWriter, req *http.Request) {
+	var patch patchRequest
+	if err := json.NewDecoder(req.Body).Decode(&patch); err != nil {
+		writeError(rw, http.StatusBadRequest, "invalidSyntax", err.Error())
+		return
+	}
+
+	existing, ok := h.findGroup(rw, req)
+	if !ok {
+		return
+	}
+
+	g := fromGroup(existing, "")
+	if err := applyPatch(&g, patch.Operations); err != nil {
+		scimType := "invalidValue"
+		if errors.Is(err, errInvalidPath) {
+			scimType = "invalidPath"
+		}
+
+		writeError(rw, http.StatusBadRequest, scimType, err.Error())
+		return
+	}
+
+	h.updateGroup(rw, req, existing, g)
+}
+
+func (h *Handler) updateGroup(rw http.ResponseWriter, req *http.Request, existing *api.Group, g group) {
+	if g.DisplayName == "" {
+		writeError(rw, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	updated, err := h.platform.UpdateGroup(req.Context(), existing.ID, existing.Version, &platform.UpdateGroupReq{
+		Name:       g.DisplayName,
+		ExternalID: g.ExternalID,
+		Members:    toMembers(g.Members),
+	})
+	if err != nil {
+		h.writePlatformError(rw, err, "Unable to update group")
+		return
+	}
+
+	writeJSON(rw, http.StatusOK, fromGroup(updated, location(req, updated.ID)))
+}
+
+func (h *Handler) handleDeleteGroup(rw http.ResponseWriter, req *http.Request) {
+	g, ok := h.findGroup(rw, req)
+	if !ok {
+		return
+	}
+
+	if err := h.platform.DeleteGroup(req.Context(), g.ID, g.Version); err != nil {
+		h.writePlatformError(rw, err, "Unable to delete group")
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// findGroup finds the group targeted by the request. It writes an error response when the group can't be found.
+func (h *Handler) findGroup(rw http.ResponseWriter, req *http.Request) (*api.Group, bool) {
+	id := chi.URLParam(req, "id")
+
+	groups, err := h.platform.GetGroups(req.Context())
+	if err != nil {
+		h.writePlatformError(rw, err, "Unable to list groups")
+		return nil, false
+	}
+
+	for i := range groups {
+		if groups[i].ID == id {
+			return &groups[i], true
+		}
+	}
+
+	writeError(rw, http.StatusNotFound, "", fmt.Sprintf("Group %q not found", id))
+
+	return nil, false
+}
+
+func (h *Handler) writePlatformError(rw http.ResponseWriter, err error, msg string) {
+	log.Error().Err(err).Msg(msg)
+
+	var apiErr platform.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusConflict:
+			writeError(rw, http.StatusConflict, "uniqueness", apiErr.Message)
+			return
+		case http.StatusNotFound, http.StatusBadRequest:
+			writeError(rw, apiErr.StatusCode, "", apiErr.Message)
+			return
+		}
+	}
+
+	writeError(rw, http.StatusInternalServerError, "", msg)
+}
+
+func location(req *http.Request, id string) string {
+	scheme := "https"
+	if req.TLS == nil {
+		scheme = "http"
+	}
+
+	return fmt.Sprintf("%s://%s/scim/v2/Groups/%s", scheme, req.Host, id)
+}
+
+func writeError(rw http.ResponseWriter, status int, scimType, detail string) {
+	writeJSON(rw, status, errorResponse{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/scim+json")
+	rw.WriteHeader(status)
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Unable to write SCIM response")
+	}
+}
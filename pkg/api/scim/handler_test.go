@@ -0,0 +1,255 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+const testToken = "token"
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantBody   string
+		wantGroups []api.Group
+	}{
+		{
+			desc:       "invalid token",
+			method:     http.MethodGet,
+			path:       "/Groups",
+			token:      "invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:       "list groups filtered by display name",
+			method:     http.MethodGet,
+			path:       `/Groups?filter=displayName%20eq%20%22developers%22`,
+			wantStatus: http.StatusOK,
+			wantBody: `{
+				"schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+				"totalResults": 1,
+				"startIndex": 1,
+				"itemsPerPage": 1,
+				"Resources": [{
+					"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+					"id": "dev",
+					"displayName": "developers",
+					"members": [{"value": "john"}, {"value": "jane"}],
+					"meta": {
+						"resourceType": "Group",
+						"created": "0001-01-01T00:00:00Z",
+						"lastModified": "0001-01-01T00:00:00Z",
+						"version": "W/\"1\"",
+						"location": "http://example.com/scim/v2/Groups/dev"
+					}
+				}]
+			}`,
+		},
+		{
+			desc:       "unsupported filter",
+			method:     http.MethodGet,
+			path:       `/Groups?filter=members%20pr`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "get unknown group",
+			method:     http.MethodGet,
+			path:       "/Groups/unknown",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "create group",
+			method:     http.MethodPost,
+			path:       "/Groups",
+			body:       `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:Group"],"displayName":"admins","externalId":"ext","members":[{"value":"john"}]}`,
+			wantStatus: http.StatusCreated,
+			wantGroups: []api.Group{
+				{ID: "dev", Name: "developers", Members: []api.GroupMember{{ID: "john"}, {ID: "jane"}}, Version: "1"},
+				{ID: "ops", Name: "operators", Members: []api.GroupMember{}, Version: "1"},
+				{ID: "admins", Name: "admins", ExternalID: "ext", Members: []api.GroupMember{{ID: "john"}}, Version: "1"},
+			},
+		},
+		{
+			desc:       "create group without display name",
+			method:     http.MethodPost,
+			path:       "/Groups",
+			body:       `{"members":[]}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "replace group",
+			method:     http.MethodPut,
+			path:       "/Groups/ops",
+			body:       `{"displayName":"operations","members":[{"value":"jane"}]}`,
+			wantStatus: http.StatusOK,
+			wantGroups: []api.Group{
+				{ID: "dev", Name: "developers", Members: []api.GroupMember{{ID: "john"}, {ID: "jane"}}, Version: "1"},
+				{ID: "ops", Name: "operations", Members: []api.GroupMember{{ID: "jane"}}, Version: "2"},
+			},
+		},
+		{
+			desc:   "patch group",
+			method: http.MethodPatch,
+			path:   "/Groups/dev",
+			body: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[
+				{"op":"Add","path":"members","value":[{"value":"jack"},{"value":"john"}]},
+				{"op":"remove","path":"members[value eq \"jane\"]"},
+				{"op":"replace","value":{"displayName":"devs"}}
+			]}`,
+			wantStatus: http.StatusOK,
+			wantGroups: []api.Group{
+				{ID: "dev", Name: "devs", Members: []api.GroupMember{{ID: "john"}, {ID: "jack"}}, Version: "2"},
+				{ID: "ops", Name: "operators", Members: []api.GroupMember{}, Version: "1"},
+			},
+		},
+		{
+			desc:       "patch group with an invalid path",
+			method:     http.MethodPatch,
+			path:       "/Groups/dev",
+			body:       `{"Operations":[{"op":"replace","path":"owner","value":"john"}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody: `{
+				"schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"],
+				"status": "400",
+				"scimType": "invalidPath",
+				"detail": "invalid path \"owner\""
+			}`,
+		},
+		{
+			desc:       "delete group",
+			method:     http.MethodDelete,
+			path:       "/Groups/ops",
+			wantStatus: http.StatusNoContent,
+			wantGroups: []api.Group{
+				{ID: "dev", Name: "developers", Members: []api.GroupMember{{ID: "john"}, {ID: "jane"}}, Version: "1"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			svc := &groupServiceFake{
+				groups: []api.Group{
+					{ID: "dev", Name: "developers", Members: []api.GroupMember{{ID: "john"}, {ID: "jane"}}, Version: "1"},
+					{ID: "ops", Name: "operators", Members: []api.GroupMember{}, Version: "1"},
+				},
+			}
+
+			token := testToken
+			if test.token != "" {
+				token = test.token
+			}
+
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://example.com/scim/v2"+test.path, strings.NewReader(test.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			router := http.NewServeMux()
+			router.Handle("/scim/v2/", http.StripPrefix("/scim/v2", NewHandler(svc, testToken)))
+			router.ServeHTTP(rw, req)
+
+			require.Equal(t, test.wantStatus, rw.Code, rw.Body.String())
+
+			if test.wantBody != "" {
+				assert.Equal(t, "application/scim+json", rw.Header().Get("Content-Type"))
+				assert.JSONEq(t, test.wantBody, rw.Body.String())
+			}
+			if test.wantGroups != nil {
+				assert.Equal(t, test.wantGroups, svc.groups)
+			}
+		})
+	}
+}
+
+type groupServiceFake struct {
+	groups []api.Group
+}
+
+func (f *groupServiceFake) CreateGroup(_ context.Context, req *platform.CreateGroupReq) (*api.Group, error) {
+	g := api.Group{ID: req.Name, Name: req.Name, ExternalID: req.ExternalID, Members: req.Members, Version: "1"}
+	f.groups = append(f.groups, g)
+
+	return &g, nil
+}
+
+func (f *groupServiceFake) GetGroups(_ context.Context) ([]api.Group, error) {
+	b, err := json.Marshal(f.groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []api.Group
+	if err = json.Unmarshal(b, &groups); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (f *groupServiceFake) UpdateGroup(_ context.Context, id, lastKnownVersion string, req *platform.UpdateGroupReq) (*api.Group, error) {
+	for i, g := range f.groups {
+		if g.ID != id {
+			continue
+		}
+		if g.Version != lastKnownVersion {
+			return nil, platform.APIError{StatusCode: http.StatusConflict, Message: "version mismatch"}
+		}
+
+		f.groups[i] = api.Group{ID: id, Name: req.Name, ExternalID: req.ExternalID, Members: req.Members, Version: "2"}
+
+		return &f.groups[i], nil
+	}
+
+	return nil, platform.APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+}
+
+func (f *groupServiceFake) DeleteGroup(_ context.Context, id, lastKnownVersion string) error {
+	for i, g := range f.groups {
+		if g.ID != id {
+			continue
+		}
+		if g.Version != lastKnownVersion {
+			return platform.APIError{StatusCode: http.StatusConflict, Message: "version mismatch"}
+		}
+
+		f.groups = append(f.groups[:i], f.groups[i+1:]...)
+
+		return nil
+	}
+
+	return platform.APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+}
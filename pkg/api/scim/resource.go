@@ -0,0 +1,253 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/api"
+)
+
+// SCIM schemas.
+const (
+	schemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// group is a SCIM Group resource.
+type group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id,omitempty"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []member `json:"members"`
+	Meta        *meta    `json:"meta,omitempty"`
+}
+
+type member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Version      string    `json:"version"`
+	Location     string    `json:"location"`
+}
+
+type listResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []group  `json:"Resources"`
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func fromGroup(g *api.Group, location string) group {
+	members := make([]member, 0, len(g.Members))
+	for _, m := range g.Members {
+		members = append(members, member{Value: m.ID, Display: m.Display})
+	}
+
+	return group{
+		Schemas:     []string{schemaGroup},
+		ID:          g.ID,
+		ExternalID:  g.ExternalID,
+		DisplayName: g.Name,
+		Members:     members,
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      g.CreatedAt,
+			LastModified: g.UpdatedAt,
+			Version:      fmt.Sprintf("W/%q", g.Version),
+			Location:     location,
+		},
+	}
+}
+
+func toMembers(members []member) []api.GroupMember {
+	res := make([]api.GroupMember, 0, len(members))
+	for _, m := range members {
+		res = append(res, api.GroupMember{ID: m.Value, Display: m.Display})
+	}
+
+	return res
+}
+
+var memberFilterRegexp = regexp.MustCompile(`^members\[value eq "([^"]*)"]$`)
+
+var errInvalidPath = errors.New("invalid path")
+
+// applyPatch applies the given PATCH operations to the group, as described in RFC 7644 section 3.5.2.
+// Only the displayName, externalId and members attributes can be patched.
+func applyPatch(g *group, ops []patchOperation) error {
+	for _, op := range ops {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add":
+			err = patchAdd(g, op)
+		case "replace":
+			err = patchReplace(g, op)
+		case "remove":
+			err = patchRemove(g, op)
+		default:
+			err = fmt.Errorf("unsupported operation %q", op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func patchAdd(g *group, op patchOperation) error {
+	if op.Path != "members" {
+		return patchReplace(g, op)
+	}
+
+	var members []member
+	if err := json.Unmarshal(op.Value, &members); err != nil {
+		return fmt.Errorf("unmarshal members: %w", err)
+	}
+
+	for _, m := range members {
+		if !hasMember(g, m.Value) {
+			g.Members = append(g.Members, m)
+		}
+	}
+
+	return nil
+}
+
+func patchReplace(g *group, op patchOperation) error {
+	switch op.Path {
+	case "":
+		var attrs struct {
+			DisplayName *string   `json:"displayName"`
+			ExternalID  *string   `json:"externalId"`
+			Members     *[]member `json:"members"`
+		}
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return fmt.Errorf("unmarshal attributes: %w", err)
+		}
+
+		if attrs.DisplayName != nil {
+			g.DisplayName = *attrs.DisplayName
+		}
+		if attrs.ExternalID != nil {
+			g.ExternalID = *attrs.ExternalID
+		}
+		if attrs.Members != nil {
+			g.Members = *attrs.Members
+		}
+	case "displayName":
+		if err := json.Unmarshal(op.Value, &g.DisplayName); err != nil {
+			return fmt.Errorf("unmarshal displayName: %w", err)
+		}
+	case "externalId":
+		if err := json.Unmarshal(op.Value, &g.ExternalID); err != nil {
+			return fmt.Errorf("unmarshal externalId: %w", err)
+		}
+	case "members":
+		if err := json.Unmarshal(op.Value, &g.Members); err != nil {
+			return fmt.Errorf("unmarshal members: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w %q", errInvalidPath, op.Path)
+	}
+
+	return nil
+}
+
+func patchRemove(g *group, op patchOperation) error {
+	if op.Path == "members" {
+		// Some identity providers give the members to remove as value rather than as a path filter.
+		if len(op.Value) == 0 {
+			g.Members = nil
+			return nil
+		}
+
+		var members []member
+		if err := json.Unmarshal(op.Value, &members); err != nil {
+			return fmt.Errorf("unmarshal members: %w", err)
+		}
+
+		for _, m := range members {
+			removeMember(g, m.Value)
+		}
+
+		return nil
+	}
+
+	matches := memberFilterRegexp.FindStringSubmatch(op.Path)
+	if matches == nil {
+		return fmt.Errorf("%w %q", errInvalidPath, op.Path)
+	}
+
+	removeMember(g, matches[1])
+
+	return nil
+}
+
+func hasMember(g *group, id string) bool {
+	for _, m := range g.Members {
+		if m.Value == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeMember(g *group, id string) {
+	members := g.Members[:0]
+	for _, m := range g.Members {
+		if m.Value != id {
+			members = append(members, m)
+		}
+	}
+	g.Members = members
+}
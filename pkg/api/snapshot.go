@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+)
+
+// Labels and annotations of the ConfigMaps holding the OpenAPI spec snapshots.
+const (
+	LabelSpecSnapshots      = "hub.traefik.io/api-spec-snapshots"
+	annotationSpecSnapshots = "hub.traefik.io/snapshots"
+)
+
+// SpecSnapshot is a snapshot of the OpenAPI spec of an API.
+type SpecSnapshot struct {
+	Revision   string    `json:"revision"`
+	Generation int64     `json:"generation"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Spec is the raw OpenAPI spec. It's stored in the ConfigMap data, indexed by revision.
+	Spec []byte `json:"-"`
+}
+
+// SpecRevision returns the revision of the given raw OpenAPI spec. It's derived from its content.
+func SpecRevision(raw []byte) string {
+	hash := sha256.Sum256(raw)
+
+	return hex.EncodeToString(hash[:6])
+}
+
+// SpecSnapshotsConfigMapName returns the name of the ConfigMap holding the OpenAPI spec snapshots of the given API.
+func SpecSnapshotsConfigMapName(apiName, apiNamespace string) string {
+	return fmt.Sprintf("hub-api-spec.%s.%s", apiName, apiNamespace)
+}
+
+// SpecSnapshotStore reads the OpenAPI spec snapshots taken by the WatcherSnapshot.
+type SpecSnapshotStore struct {
+	configMaps corev1listers.ConfigMapNamespaceLister
+}
+
+// NewSpecSnapshotStore returns a new SpecSnapshotStore reading the snapshots from the given ConfigMaps.
+func NewSpecSnapshotStore(configMaps corev1listers.ConfigMapNamespaceLister) *SpecSnapshotStore {
+	return &SpecSnapshotStore{configMaps: configMaps}
+}
+
+// Snapshots returns the OpenAPI spec snapshots of the given API, from the oldest to the newest.
+func (s *SpecSnapshotStore) Snapshots(apiName, apiNamespace string) ([]SpecSnapshot, error) {
+	cm, err := s.configMaps.Get(SpecSnapshotsConfigMapName(apiName, apiNamespace))
+	if kerror.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get snapshots ConfigMap: %w", err)
+	}
+
+	return readSpecSnapshots(cm)
+}
+
+// readSpecSnapshots reads the snapshots stored in the given ConfigMap.
+func readSpecSnapshots(cm *corev1.ConfigMap) ([]SpecSnapshot, error) {
+	rawIndex, ok := cm.Annotations[annotationSpecSnapshots]
+	if !ok {
+		return nil, nil
+	}
+
+	var snapshots []SpecSnapshot
+	if err := json.Unmarshal([]byte(rawIndex), &snapshots); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshots index: %w", err)
+	}
+
+	for i := range snapshots {
+		snapshots[i].Spec = []byte(cm.Data[snapshots[i].Revision])
+	}
+
+	return snapshots, nil
+}
+
+// writeSpecSnapshots writes the given snapshots in the given ConfigMap, replacing the existing ones.
+func writeSpecSnapshots(cm *corev1.ConfigMap, snapshots []SpecSnapshot) error {
+	rawIndex, err := json.Marshal(snapshots)
+	if err != nil {
+		return fmt.Errorf("marshal snapshots index: %w", err)
+	}
+
+	if cm.Annotations == nil {
+		cm.Annotations = make(map[string]string)
+	}
+	cm.Annotations[annotationSpecSnapshots] = string(rawIndex)
+
+	cm.Data = make(map[string]string, len(snapshots))
+	for _, snapshot := range snapshots {
+		cm.Data[snapshot.Revision] = string(snapshot.Spec)
+	}
+
+	return nil
+}
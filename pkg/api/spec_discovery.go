@@ -0,0 +1,49 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// WellKnownOpenAPISpecPaths returns the paths at which API services commonly serve their OpenAPI spec. They are
+// probed, in order, when the location of the spec isn't configured.
+func WellKnownOpenAPISpecPaths() []string {
+	return []string{"/openapi.json", "/swagger.json", "/v3/api-docs"}
+}
+
+// NeedsOpenAPISpecDiscovery returns whether the given source doesn't tell where the OpenAPI spec is served, in which
+// case it must be discovered on the API service.
+func NeedsOpenAPISpecDiscovery(source hubv1alpha1.OpenAPISpecSource) bool {
+	return source.URL == "" && source.Path == "" && source.ConfigMapRef == nil
+}
+
+// IsOpenAPISpec returns whether the given raw document, in JSON or YAML, looks like an OpenAPI or Swagger spec, as
+// opposed to an HTML page for instance.
+func IsOpenAPISpec(raw []byte) bool {
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Swagger string `json:"swagger"`
+	}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return false
+	}
+
+	return doc.OpenAPI != "" || doc.Swagger != ""
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOpenAPISpec(t *testing.T) {
+	tests := []struct {
+		desc string
+		raw  string
+		want bool
+	}{
+		{
+			desc: "OpenAPI JSON spec",
+			raw:  `{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {}}`,
+			want: true,
+		},
+		{
+			desc: "OpenAPI YAML spec",
+			raw:  "openapi: 3.1.0\ninfo:\n  title: Books\n  version: 1.0.0\n",
+			want: true,
+		},
+		{
+			desc: "Swagger spec",
+			raw:  `{"swagger": "2.0", "info": {"title": "Books", "version": "1.0.0"}}`,
+			want: true,
+		},
+		{
+			desc: "HTML page",
+			raw:  "<!DOCTYPE html><html><body>Welcome</body></html>",
+		},
+		{
+			desc: "JSON document",
+			raw:  `{"status": "ok"}`,
+		},
+		{
+			desc: "empty document",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, IsOpenAPISpec([]byte(test.raw)))
+		})
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import "time"
+
+// TermsQuery identifies the terms of service acceptance of a consumer.
+type TermsQuery struct {
+	Portal string
+	// API is the API, formatted as name@namespace.
+	API string
+	// Collection is the collection the API is published through, if any.
+	Collection string
+	Email      string
+}
+
+// TermsAcceptance is the acceptance, by a consumer, of a version of the terms of service of an API published on a
+// portal. The platform requires it before issuing tokens for that API.
+type TermsAcceptance struct {
+	Portal string `json:"portal"`
+	// API is the API, formatted as name@namespace.
+	API string `json:"api"`
+	// Collection is the collection the API was published through, if any.
+	Collection string `json:"collection,omitempty"`
+	Email      string `json:"email"`
+
+	URL     string `json:"url"`
+	Version string `json:"version"`
+
+	AcceptedAt time.Time `json:"acceptedAt,omitempty"`
+}
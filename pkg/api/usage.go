@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import "time"
+
+// UsageQuery selects the usage data of an API consumer.
+type UsageQuery struct {
+	Portal string
+	// API is the API, formatted as name@namespace.
+	API string
+	// Email is the email of the consumer owning the tokens.
+	Email string
+	// Period is the duration, ending now, over which the usage is aggregated.
+	Period time.Duration
+}
+
+// APIUsage is the usage of an API made with the tokens of a consumer.
+type APIUsage struct {
+	From   time.Time    `json:"from"`
+	To     time.Time    `json:"to"`
+	Total  UsageMetrics `json:"total"`
+	Tokens []TokenUsage `json:"tokens"`
+}
+
+// TokenUsage is the usage of an API made with a token.
+type TokenUsage struct {
+	// Token is the name of the token, never its value.
+	Token string `json:"token"`
+
+	UsageMetrics
+}
+
+// UsageMetrics holds the request counts, error rate and latency percentiles of a set of requests.
+type UsageMetrics struct {
+	Requests  int64              `json:"requests"`
+	Errors    int64              `json:"errors"`
+	ErrorRate float64            `json:"errorRate"`
+	Latency   LatencyPercentiles `json:"latency"`
+}
+
+// LatencyPercentiles holds latency percentiles, in milliseconds.
+type LatencyPercentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+}
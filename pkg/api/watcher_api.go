@@ -19,40 +19,84 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
 )
 
+// specProbeConcurrency is the maximum number of OpenAPI specs fetched at once.
+const specProbeConcurrency = 10
+
+// specProbe is the outcome of fetching the OpenAPI spec of an API at a given generation.
+type specProbe struct {
+	generation int64
+	spec       *openapi3.T
+	err        error
+}
+
 // WatcherAPI watches hub APIs and sync them with the cluster.
 type WatcherAPI struct {
 	apiSyncInterval time.Duration
 
-	platform PlatformClient
+	platform   PlatformClient
+	httpClient *http.Client
+	ruleset    lint.Ruleset
+
+	kubeClientSet clientset.Interface
+	kubeInformer  informers.SharedInformerFactory
 
 	hubClientSet hubclientset.Interface
 	hubInformer  hubinformer.SharedInformerFactory
+
+	specProbesMu sync.Mutex
+	specProbes   map[string]specProbe
 }
 
-// NewWatcherAPI returns a new WatcherAPI.
-func NewWatcherAPI(client PlatformClient, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory, apiSyncInterval time.Duration) *WatcherAPI {
+// NewWatcherAPI returns a new WatcherAPI. The OpenAPI specs of the APIs are linted using the given ruleset.
+func NewWatcherAPI(client PlatformClient, kubeClientSet clientset.Interface, kubeInformer informers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory, apiSyncInterval time.Duration, ruleset lint.Ruleset) *WatcherAPI {
 	return &WatcherAPI{
 		apiSyncInterval: apiSyncInterval,
 		platform:        client,
+		httpClient:      &http.Client{Timeout: 5 * time.Second},
+		ruleset:         ruleset,
+
+		kubeClientSet: kubeClientSet,
+		kubeInformer:  kubeInformer,
 
 		hubClientSet: hubClientSet,
 		hubInformer:  hubInformer,
+
+		specProbes: make(map[string]specProbe),
 	}
 }
 
 // Run runs WatcherAPI.
 func (w *WatcherAPI) Run(ctx context.Context) {
+	// The OpenAPI specs are fetched on their own, as fetching them may take a while and must not delay the sync.
+	go w.runSpecProbes(ctx)
+
 	t := time.NewTicker(w.apiSyncInterval)
 	defer t.Stop()
 
@@ -66,6 +110,10 @@ func (w *WatcherAPI) Run(ctx context.Context) {
 			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
 			w.syncAPIs(ctxSync)
 			cancel()
+
+			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
+			w.syncConditions(ctxSync)
+			cancel()
 		}
 	}
 }
@@ -144,6 +192,7 @@ func (w *WatcherAPI) updateAPI(ctx context.Context, oldAPI *hubv1alpha1.API, new
 
 	obj.ObjectMeta = oldAPI.ObjectMeta
 	obj.ObjectMeta.Labels = newAPI.Labels
+	obj.Status.Conditions = oldAPI.Status.Conditions
 
 	if obj.Status.Version != oldAPI.Status.Version {
 		obj, err = w.hubClientSet.HubV1alpha1().APIs(obj.Namespace).Update(ctx, obj, metav1.UpdateOptions{})
@@ -181,3 +230,334 @@ func (w *WatcherAPI) cleanAPIs(ctx context.Context, apis map[string]*hubv1alpha1
 			Msg("API deleted")
 	}
 }
+
+// syncConditions refreshes the conditions of the APIs, explaining why an API may not be reachable.
+func (w *WatcherAPI) syncConditions(ctx context.Context) {
+	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to obtain APIs")
+		return
+	}
+
+	for _, api := range apis {
+		if err = w.syncAPIConditions(ctx, api.Namespace, api.Name); err != nil {
+			log.Error().Err(err).
+				Str("name", api.Name).
+				Str("namespace", api.Namespace).
+				Msg("Unable to update API conditions")
+		}
+	}
+}
+
+// syncAPIConditions refreshes the conditions of the given API. Only the conditions are written, on the latest version
+// of the API, so the changes made to the API in the meantime are kept.
+func (w *WatcherAPI) syncAPIConditions(ctx context.Context, namespace, name string) error {
+	apis := w.hubClientSet.HubV1alpha1().APIs(namespace)
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		api, err := apis.Get(ctx, name, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("get API: %w", err)
+		}
+
+		conditions := make([]metav1.Condition, len(api.Status.Conditions))
+		copy(conditions, api.Status.Conditions)
+
+		// The spec conditions are left as they are until the spec of the current generation has been fetched.
+		if probe, ok := w.specProbe(api); ok {
+			meta.SetStatusCondition(&conditions, specFetchableCondition(api, probe.err))
+			meta.SetStatusCondition(&conditions, w.specCompliantCondition(api, probe.spec))
+		}
+		meta.SetStatusCondition(&conditions, w.serviceResolvableCondition(api))
+
+		if equality.Semantic.DeepEqual(api.Status.Conditions, conditions) {
+			return nil
+		}
+
+		// The resource version makes the patch fail with a conflict if the API changed since it has been read.
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"resourceVersion": api.ResourceVersion},
+			"status":   map[string]interface{}{"conditions": conditions},
+		})
+		if err != nil {
+			return fmt.Errorf("marshal conditions patch: %w", err)
+		}
+
+		_, err = apis.Patch(ctx, name, ktypes.MergePatchType, patch, metav1.PatchOptions{})
+		if kerror.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	})
+}
+
+func (w *WatcherAPI) runSpecProbes(ctx context.Context) {
+	t := time.NewTicker(w.apiSyncInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-t.C:
+			w.probeSpecs(ctx)
+		}
+	}
+}
+
+// probeSpecs fetches the OpenAPI specs of the APIs, a few at a time, and keeps the outcomes for the next syncs of the
+// conditions.
+func (w *WatcherAPI) probeSpecs(ctx context.Context) {
+	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to obtain APIs")
+		return
+	}
+
+	probes := make(map[string]specProbe, len(apis))
+
+	var (
+		mu    sync.Mutex
+		group errgroup.Group
+	)
+	group.SetLimit(specProbeConcurrency)
+
+	for _, api := range apis {
+		api := api
+
+		group.Go(func() error {
+			spec, fetchErr := w.fetchOpenAPISpec(ctx, api)
+
+			mu.Lock()
+			probes[api.Name+"@"+api.Namespace] = specProbe{generation: api.Generation, spec: spec, err: fetchErr}
+			mu.Unlock()
+
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	w.specProbesMu.Lock()
+	w.specProbes = probes
+	w.specProbesMu.Unlock()
+}
+
+// specProbe returns the outcome of fetching the OpenAPI spec of the current generation of the given API, if any.
+func (w *WatcherAPI) specProbe(api *hubv1alpha1.API) (specProbe, bool) {
+	w.specProbesMu.Lock()
+	defer w.specProbesMu.Unlock()
+
+	probe, ok := w.specProbes[api.Name+"@"+api.Namespace]
+	if !ok || probe.generation != api.Generation {
+		return specProbe{}, false
+	}
+
+	return probe, true
+}
+
+func specFetchableCondition(api *hubv1alpha1.API, fetchErr error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               hubv1alpha1.APIConditionSpecFetchable,
+		ObservedGeneration: api.Generation,
+	}
+
+	if fetchErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SpecNotFetchable"
+		condition.Message = fetchErr.Error()
+
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "SpecFetched"
+	condition.Message = "The OpenAPI spec has been fetched successfully"
+
+	return condition
+}
+
+// specCompliantCondition lints the given spec. The spec is nil when it can't be fetched.
+func (w *WatcherAPI) specCompliantCondition(api *hubv1alpha1.API, spec *openapi3.T) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               hubv1alpha1.APIConditionSpecCompliant,
+		ObservedGeneration: api.Generation,
+	}
+
+	if spec == nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "SpecNotFetchable"
+		condition.Message = "The OpenAPI spec can't be linted as it can't be fetched"
+
+		return condition
+	}
+
+	results := w.ruleset.Lint(spec)
+
+	var errs, warns int
+	for _, result := range results {
+		switch result.Severity {
+		case lint.SeverityError:
+			errs++
+		case lint.SeverityWarn:
+			warns++
+		}
+	}
+
+	if errs > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "LintFailed"
+		condition.Message = fmt.Sprintf("The OpenAPI spec has %d lint errors and %d warnings", errs, warns)
+
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "LintPassed"
+	condition.Message = fmt.Sprintf("The OpenAPI spec has no lint errors and %d warnings", warns)
+
+	return condition
+}
+
+func (w *WatcherAPI) fetchOpenAPISpec(ctx context.Context, api *hubv1alpha1.API) (*openapi3.T, error) {
+	rawSpec, err := fetchRawOpenAPISpec(ctx, w.httpClient, w.kubeClientSet.CoreV1(), api)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := openapi3.NewLoader().LoadFromData(rawSpec)
+	if err != nil {
+		return nil, fmt.Errorf("load OpenAPI spec: %w", err)
+	}
+
+	return spec, nil
+}
+
+// fetchRawOpenAPISpec fetches the OpenAPI spec of the given API from the first of its sources to succeed.
+func fetchRawOpenAPISpec(ctx context.Context, client *http.Client, configMaps corev1client.ConfigMapsGetter, api *hubv1alpha1.API) ([]byte, error) {
+	var errs []error
+	for _, source := range api.Spec.Service.OpenAPISpec.Sources() {
+		rawSpec, err := fetchRawOpenAPISpecSource(ctx, client, configMaps, api, source)
+		if err == nil {
+			return rawSpec, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// fetchRawOpenAPISpecSource fetches the OpenAPI spec of the given API from the given source.
+func fetchRawOpenAPISpecSource(ctx context.Context, client *http.Client, configMaps corev1client.ConfigMapsGetter, api *hubv1alpha1.API, source hubv1alpha1.OpenAPISpecSource) ([]byte, error) {
+	if source.ConfigMapRef != nil {
+		return ReadOpenAPISpecConfigMap(ctx, configMaps, api, source.ConfigMapRef)
+	}
+	if NeedsOpenAPISpecDiscovery(source) {
+		return discoverRawOpenAPISpec(ctx, client, api, source)
+	}
+
+	specURL, err := OpenAPISpecURL(api, source)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, specURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request %q: %w", specURL.String(), err)
+	}
+
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Accept", "application/yaml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch OpenAPI spec %q: %w", specURL.String(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("fetch OpenAPI spec %q: unexpected status code %d", specURL.String(), resp.StatusCode)
+	}
+
+	rawSpec, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read OpenAPI spec %q: %w", specURL.String(), err)
+	}
+
+	return rawSpec, nil
+}
+
+// discoverRawOpenAPISpec fetches the OpenAPI spec of the given API from the first well-known path of its service
+// serving one.
+func discoverRawOpenAPISpec(ctx context.Context, client *http.Client, api *hubv1alpha1.API, source hubv1alpha1.OpenAPISpecSource) ([]byte, error) {
+	var errs []error
+	for _, path := range WellKnownOpenAPISpecPaths() {
+		source.Path = path
+
+		rawSpec, err := fetchRawOpenAPISpecSource(ctx, client, nil, api, source)
+		if err == nil && !IsOpenAPISpec(rawSpec) {
+			err = fmt.Errorf("no OpenAPI spec served at %q", path)
+		}
+		if err == nil {
+			return rawSpec, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("discover OpenAPI spec: %w", errors.Join(errs...))
+}
+
+func (w *WatcherAPI) serviceResolvableCondition(api *hubv1alpha1.API) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               hubv1alpha1.APIConditionServiceResolvable,
+		ObservedGeneration: api.Generation,
+	}
+
+	err := w.resolveService(api.Namespace, api.Spec.Service.Name, api.Spec.Service.Port)
+	for i := 0; err == nil && i < len(api.Spec.Service.Weighted); i++ {
+		weighted := api.Spec.Service.Weighted[i]
+		err = w.resolveService(api.Namespace, weighted.Name, weighted.Port)
+	}
+
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ServiceNotResolvable"
+		condition.Message = err.Error()
+
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "ServicesResolved"
+	condition.Message = "All the referenced services exist and expose the referenced ports"
+
+	return condition
+}
+
+func (w *WatcherAPI) resolveService(namespace, name string, port hubv1alpha1.APIServiceBackendPort) error {
+	svc, err := w.kubeInformer.Core().V1().Services().Lister().Services(namespace).Get(name)
+	if kerror.IsNotFound(err) {
+		return fmt.Errorf("service %s/%s not found", namespace, name)
+	}
+	if err != nil {
+		return fmt.Errorf("get service %s/%s: %w", namespace, name, err)
+	}
+
+	for _, p := range svc.Spec.Ports {
+		if port.Name != "" && p.Name == port.Name || port.Number != 0 && p.Port == port.Number {
+			return nil
+		}
+	}
+
+	if port.Name != "" {
+		return fmt.Errorf("port %q not found on service %s/%s", port.Name, namespace, name)
+	}
+
+	return fmt.Errorf("port %d not found on service %s/%s", port.Number, namespace, name)
+}
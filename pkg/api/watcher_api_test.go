@@ -19,17 +19,24 @@ package api
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/lint"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -94,7 +101,13 @@ func Test_WatcherAPIRun(t *testing.T) {
 			}
 		})
 
-	w := NewWatcherAPI(client, clientSetHub, hubInformer, time.Millisecond)
+	kubeClientSet := kubemock.NewSimpleClientset()
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Core().V1().Services().Informer()
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	w := NewWatcherAPI(client, kubeClientSet, kubeInformer, clientSetHub, hubInformer, time.Millisecond, lint.DefaultRuleset())
 	go w.Run(ctx)
 
 	<-ctx.Done()
@@ -124,3 +137,159 @@ func Test_WatcherAPIRun(t *testing.T) {
 	_, err = clientSetHub.HubV1alpha1().APIs("").Get(ctx, "apiToDelete", metav1.GetOptions{})
 	require.Error(t, err)
 }
+
+func TestWatcherAPI_syncConditions(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	kubeClientSet := kubemock.NewSimpleClientset(
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "http", Port: 80}},
+			},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "books-spec", Namespace: "default"},
+			Data: map[string]string{
+				"openapi.json": `{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {"/books": {"get": {"responses": {"200": {"description": "Books"}}}}}}`,
+			},
+		},
+	)
+	hubClientSet := hubkubemock.NewSimpleClientset(
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", Generation: 2},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/books",
+				Service: hubv1alpha1.APIService{
+					Name: "books",
+					Port: hubv1alpha1.APIServiceBackendPort{Name: "http"},
+					// The spec is read from the ConfigMap when the registry is unavailable.
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{
+						URL: srv.URL + "/openapi.json",
+						Fallbacks: []hubv1alpha1.OpenAPISpecSource{
+							{ConfigMapRef: &hubv1alpha1.OpenAPISpecConfigMapRef{Name: "books-spec", Key: "openapi.json"}},
+						},
+					},
+				},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "authors", Namespace: "default", Generation: 1},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/authors",
+				Service: hubv1alpha1.APIService{
+					Name: "books",
+					Port: hubv1alpha1.APIServiceBackendPort{Number: 80},
+					Weighted: []hubv1alpha1.APIWeightedService{
+						{Name: "authors", Port: hubv1alpha1.APIServiceBackendPort{Number: 80}, Weight: 10},
+					},
+					OpenAPISpec: hubv1alpha1.OpenAPISpec{
+						URL: srv.URL + "/unknown.json",
+					},
+				},
+			},
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Core().V1().Services().Informer()
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 0)
+	hubInformer.Hub().V1alpha1().APIs().Informer()
+
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	w := NewWatcherAPI(newPlatformClientMock(t), kubeClientSet, kubeInformer, hubClientSet, hubInformer, time.Minute, lint.Ruleset{Rules: lint.Rules{OperationIDRequired: lint.SeverityError}})
+	w.probeSpecs(ctx)
+	w.syncConditions(ctx)
+
+	api, err := hubClientSet.HubV1alpha1().APIs("default").Get(ctx, "books", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionSpecFetchable, metav1.ConditionTrue, "SpecFetched", 2)
+	assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionServiceResolvable, metav1.ConditionTrue, "ServicesResolved", 2)
+	cond := assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionSpecCompliant, metav1.ConditionFalse, "LintFailed", 2)
+	assert.Equal(t, "The OpenAPI spec has 1 lint errors and 0 warnings", cond.Message)
+
+	api, err = hubClientSet.HubV1alpha1().APIs("default").Get(ctx, "authors", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	cond = assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionSpecFetchable, metav1.ConditionFalse, "SpecNotFetchable", 1)
+	assert.Contains(t, cond.Message, "unexpected status code 404")
+	cond = assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionServiceResolvable, metav1.ConditionFalse, "ServiceNotResolvable", 1)
+	assert.Equal(t, "service default/authors not found", cond.Message)
+	assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionSpecCompliant, metav1.ConditionUnknown, "SpecNotFetchable", 1)
+}
+
+func TestWatcherAPI_syncConditions_keepsChanges(t *testing.T) {
+	cachedAPIs := []runtime.Object{
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", Generation: 1},
+			Spec: hubv1alpha1.APISpec{
+				PathPrefix: "/old",
+				Service:    hubv1alpha1.APIService{Name: "books", Port: hubv1alpha1.APIServiceBackendPort{Number: 80}},
+			},
+		},
+		&hubv1alpha1.API{
+			ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "default", Generation: 1},
+		},
+	}
+
+	// The API has been updated, and another one deleted, since the informer cache was filled.
+	hubClientSet := hubkubemock.NewSimpleClientset(&hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", Generation: 2, Labels: map[string]string{"foo": "bar"}},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: "/new",
+			Service:    hubv1alpha1.APIService{Name: "books", Port: hubv1alpha1.APIServiceBackendPort{Number: 80}},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeClientSet := kubemock.NewSimpleClientset()
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Core().V1().Services().Informer()
+	hubInformer := hubinformer.NewSharedInformerFactory(hubkubemock.NewSimpleClientset(cachedAPIs...), 0)
+	hubInformer.Hub().V1alpha1().APIs().Informer()
+
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	w := NewWatcherAPI(newPlatformClientMock(t), kubeClientSet, kubeInformer, hubClientSet, hubInformer, time.Minute, lint.DefaultRuleset())
+	w.syncConditions(ctx)
+
+	api, err := hubClientSet.HubV1alpha1().APIs("default").Get(ctx, "books", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/new", api.Spec.PathPrefix)
+	assert.Equal(t, map[string]string{"foo": "bar"}, api.Labels)
+	assertCondition(t, api.Status.Conditions, hubv1alpha1.APIConditionServiceResolvable, metav1.ConditionFalse, "ServiceNotResolvable", 2)
+	// The spec of the API hasn't been fetched yet.
+	assert.Nil(t, meta.FindStatusCondition(api.Status.Conditions, hubv1alpha1.APIConditionSpecFetchable))
+
+	_, err = hubClientSet.HubV1alpha1().APIs("default").Get(ctx, "deleted", metav1.GetOptions{})
+	require.Error(t, err)
+}
+
+func assertCondition(t *testing.T, conditions []metav1.Condition, typ string, status metav1.ConditionStatus, reason string, generation int64) *metav1.Condition {
+	t.Helper()
+
+	cond := meta.FindStatusCondition(conditions, typ)
+	require.NotNil(t, cond, "condition %q not found", typ)
+
+	assert.Equal(t, status, cond.Status)
+	assert.Equal(t, reason, cond.Reason)
+	assert.Equal(t, generation, cond.ObservedGeneration)
+	assert.False(t, cond.LastTransitionTime.IsZero())
+
+	return cond
+}
@@ -0,0 +1,283 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	netv1 "k8s.io/api/networking/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+)
+
+// Annotations driving the discovery of APIs from Ingresses and IngressRoutes.
+const (
+	// AnnotationDiscoverAPI opts an Ingress or an IngressRoute in the API discovery when set to "true".
+	AnnotationDiscoverAPI = "hub.traefik.io/discover-api"
+	// AnnotationDiscoveredAPIName overrides the name of the discovered API, which defaults to the name of the
+	// Ingress or IngressRoute.
+	AnnotationDiscoveredAPIName = "hub.traefik.io/api-name"
+	// AnnotationDiscoveredAPISpecPath sets the path at which the service serves its OpenAPI spec. When not set, the
+	// spec is looked up on the well-known paths.
+	AnnotationDiscoveredAPISpecPath = "hub.traefik.io/api-openapi-spec-path"
+	// AnnotationDiscoveredFrom references the Ingress or IngressRoute an API has been discovered from.
+	AnnotationDiscoveredFrom = "hub.traefik.io/discovered-from"
+)
+
+// pathPrefixRe matches the first PathPrefix matcher of an IngressRoute rule.
+var pathPrefixRe = regexp.MustCompile("PathPrefix\\(\\s*`([^`]+)`")
+
+// WatcherDiscovery discovers APIs from the Ingresses and IngressRoutes opting in the discovery and creates them.
+// Discovered APIs are only created: once they exist, they are managed like any other API and are neither updated
+// nor deleted when the Ingress or IngressRoute they were discovered from changes.
+type WatcherDiscovery struct {
+	discoveryInterval time.Duration
+
+	kubeInformer     informers.SharedInformerFactory
+	hubClientSet     hubclientset.Interface
+	hubInformer      hubinformer.SharedInformerFactory
+	traefikClientSet traefikclientset.TraefikV1alpha1Interface
+}
+
+// NewWatcherDiscovery returns a new WatcherDiscovery. The Traefik client set is optional: IngressRoutes are not
+// discovered without it.
+func NewWatcherDiscovery(kubeInformer informers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory,
+	traefikClientSet traefikclientset.TraefikV1alpha1Interface, discoveryInterval time.Duration,
+) *WatcherDiscovery {
+	return &WatcherDiscovery{
+		discoveryInterval: discoveryInterval,
+		kubeInformer:      kubeInformer,
+		hubClientSet:      hubClientSet,
+		hubInformer:       hubInformer,
+		traefikClientSet:  traefikClientSet,
+	}
+}
+
+// Run runs WatcherDiscovery.
+func (w *WatcherDiscovery) Run(ctx context.Context) {
+	t := time.NewTicker(w.discoveryInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping API discovery watcher")
+			return
+
+		case <-t.C:
+			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
+			w.discover(ctxSync)
+			cancel()
+		}
+	}
+}
+
+func (w *WatcherDiscovery) discover(ctx context.Context) {
+	var apis []*hubv1alpha1.API
+
+	ingresses, err := w.kubeInformer.Networking().V1().Ingresses().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to list Ingresses")
+	}
+	for _, ing := range ingresses {
+		if ing.Annotations[AnnotationDiscoverAPI] != "true" {
+			continue
+		}
+
+		api, ok := discoverIngressAPI(ing)
+		if !ok {
+			log.Warn().
+				Str("name", ing.Name).
+				Str("namespace", ing.Namespace).
+				Msg("Unable to discover an API from the Ingress: no path routing to a service")
+			continue
+		}
+
+		apis = append(apis, api)
+	}
+
+	if w.traefikClientSet != nil {
+		var ingRoutes *traefikv1alpha1.IngressRouteList
+		ingRoutes, err = w.traefikClientSet.IngressRoutes(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			log.Error().Err(err).Msg("Unable to list IngressRoutes")
+		}
+
+		if ingRoutes != nil {
+			for i := range ingRoutes.Items {
+				ingRoute := &ingRoutes.Items[i]
+				if ingRoute.Annotations[AnnotationDiscoverAPI] != "true" {
+					continue
+				}
+
+				api, ok := discoverIngressRouteAPI(ingRoute)
+				if !ok {
+					log.Warn().
+						Str("name", ingRoute.Name).
+						Str("namespace", ingRoute.Namespace).
+						Msg("Unable to discover an API from the IngressRoute: no PathPrefix route to a service")
+					continue
+				}
+
+				apis = append(apis, api)
+			}
+		}
+	}
+
+	for _, api := range apis {
+		if err = w.createAPI(ctx, api); err != nil {
+			log.Error().Err(err).
+				Str("name", api.Name).
+				Str("namespace", api.Namespace).
+				Str("discovered_from", api.Annotations[AnnotationDiscoveredFrom]).
+				Msg("Unable to create discovered API")
+		}
+	}
+}
+
+func (w *WatcherDiscovery) createAPI(ctx context.Context, api *hubv1alpha1.API) error {
+	_, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().APIs(api.Namespace).Get(api.Name)
+	if err == nil {
+		return nil
+	}
+	if !kerror.IsNotFound(err) {
+		return fmt.Errorf("get API: %w", err)
+	}
+
+	_, err = w.hubClientSet.HubV1alpha1().APIs(api.Namespace).Create(ctx, api, metav1.CreateOptions{})
+	if err != nil && !kerror.IsAlreadyExists(err) {
+		return fmt.Errorf("create API: %w", err)
+	}
+
+	log.Info().
+		Str("name", api.Name).
+		Str("namespace", api.Namespace).
+		Str("discovered_from", api.Annotations[AnnotationDiscoveredFrom]).
+		Msg("Discovered API created")
+
+	return nil
+}
+
+// discoverIngressAPI builds the API served by the first path of the Ingress routing to a service.
+func discoverIngressAPI(ing *netv1.Ingress) (*hubv1alpha1.API, bool) {
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				continue
+			}
+			if path.PathType != nil && *path.PathType == netv1.PathTypeExact {
+				continue
+			}
+
+			service := hubv1alpha1.APIService{
+				Name: path.Backend.Service.Name,
+				Port: hubv1alpha1.APIServiceBackendPort{
+					Name:   path.Backend.Service.Port.Name,
+					Number: path.Backend.Service.Port.Number,
+				},
+			}
+
+			return newDiscoveredAPI(ing.ObjectMeta, "Ingress", pathOrRoot(path.Path), service), true
+		}
+	}
+
+	return nil, false
+}
+
+// discoverIngressRouteAPI builds the API served by the first route of the IngressRoute matching a path prefix and
+// routing to a service.
+func discoverIngressRouteAPI(ingRoute *traefikv1alpha1.IngressRoute) (*hubv1alpha1.API, bool) {
+	for _, route := range ingRoute.Spec.Routes {
+		matches := pathPrefixRe.FindStringSubmatch(route.Match)
+		if matches == nil {
+			continue
+		}
+
+		for _, svc := range route.Services {
+			if svc.Kind != "" && svc.Kind != "Service" {
+				continue
+			}
+			// Services from other namespaces can't be referenced by an API.
+			if svc.Namespace != "" && svc.Namespace != ingRoute.Namespace {
+				continue
+			}
+
+			service := hubv1alpha1.APIService{Name: svc.Name}
+			if svc.Port.IntValue() != 0 {
+				service.Port.Number = int32(svc.Port.IntValue())
+			} else {
+				service.Port.Name = svc.Port.String()
+			}
+
+			return newDiscoveredAPI(ingRoute.ObjectMeta, "IngressRoute", matches[1], service), true
+		}
+	}
+
+	return nil, false
+}
+
+func newDiscoveredAPI(source metav1.ObjectMeta, sourceKind, pathPrefix string, service hubv1alpha1.APIService) *hubv1alpha1.API {
+	name := source.Name
+	if apiName := source.Annotations[AnnotationDiscoveredAPIName]; apiName != "" {
+		name = apiName
+	}
+
+	// Without a spec path, the spec is discovered on the well-known paths of the service.
+	service.OpenAPISpec.Path = source.Annotations[AnnotationDiscoveredAPISpecPath]
+
+	return &hubv1alpha1.API{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "API",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: source.Namespace,
+			Annotations: map[string]string{
+				AnnotationDiscoveredFrom: sourceKind + "/" + source.Name,
+			},
+		},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: pathPrefix,
+			Service:    service,
+		},
+	}
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	return path
+}
@@ -0,0 +1,194 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatcherDiscovery_discover(t *testing.T) {
+	pathTypePrefix := netv1.PathTypePrefix
+	pathTypeExact := netv1.PathTypeExact
+
+	ingresses := []*netv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "books",
+				Namespace:   "default",
+				Annotations: map[string]string{AnnotationDiscoverAPI: "true"},
+			},
+			Spec: netv1.IngressSpec{
+				Rules: []netv1.IngressRule{{
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{
+								{
+									Path:     "/health",
+									PathType: &pathTypeExact,
+									Backend:  netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "health"}},
+								},
+								{
+									Path:     "/books",
+									PathType: &pathTypePrefix,
+									Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{
+										Name: "books-svc",
+										Port: netv1.ServiceBackendPort{Number: 8080},
+									}},
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "not-opted-in",
+				Namespace: "default",
+			},
+			Spec: netv1.IngressSpec{
+				Rules: []netv1.IngressRule{{
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{{
+								Path:    "/other",
+								Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "other"}},
+							}},
+						},
+					},
+				}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "existing",
+				Namespace:   "default",
+				Annotations: map[string]string{AnnotationDiscoverAPI: "true"},
+			},
+			Spec: netv1.IngressSpec{
+				Rules: []netv1.IngressRule{{
+					IngressRuleValue: netv1.IngressRuleValue{
+						HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{{
+								Path:    "/new",
+								Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "new"}},
+							}},
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	ingRoute := &traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "authors-route",
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationDiscoverAPI:           "true",
+				AnnotationDiscoveredAPIName:     "authors",
+				AnnotationDiscoveredAPISpecPath: "/docs/openapi.yaml",
+			},
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			Routes: []traefikv1alpha1.Route{{
+				Match: "Host(`api.example.com`) && PathPrefix(`/authors`)",
+				Kind:  "Rule",
+				Services: []traefikv1alpha1.Service{{
+					LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+						Name: "authors-svc",
+						Port: intstr.FromString("http"),
+					},
+				}},
+			}},
+		},
+	}
+
+	existingAPI := &hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: "/existing",
+			Service:    hubv1alpha1.APIService{Name: "existing"},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeClientSet := kubemock.NewSimpleClientset(ingresses[0], ingresses[1], ingresses[2])
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Networking().V1().Ingresses().Informer()
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	hubClientSet := hubkubemock.NewSimpleClientset(existingAPI)
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 0)
+	hubInformer.Hub().V1alpha1().APIs().Informer()
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	traefikClientSet := traefikkubemock.NewSimpleClientset(ingRoute)
+
+	w := NewWatcherDiscovery(kubeInformer, hubClientSet, hubInformer, traefikClientSet.TraefikV1alpha1(), 0)
+	w.discover(ctx)
+
+	apis, err := hubClientSet.HubV1alpha1().APIs("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+
+	got := make(map[string]hubv1alpha1.API)
+	for _, api := range apis.Items {
+		got[api.Name] = api
+	}
+	require.Len(t, got, 3)
+
+	assert.Equal(t, map[string]string{AnnotationDiscoveredFrom: "Ingress/books"}, got["books"].Annotations)
+	assert.Equal(t, hubv1alpha1.APISpec{
+		PathPrefix: "/books",
+		Service: hubv1alpha1.APIService{
+			Name: "books-svc",
+			Port: hubv1alpha1.APIServiceBackendPort{Number: 8080},
+		},
+	}, got["books"].Spec)
+
+	assert.Equal(t, map[string]string{AnnotationDiscoveredFrom: "IngressRoute/authors-route"}, got["authors"].Annotations)
+	assert.Equal(t, hubv1alpha1.APISpec{
+		PathPrefix: "/authors",
+		Service: hubv1alpha1.APIService{
+			Name:        "authors-svc",
+			Port:        hubv1alpha1.APIServiceBackendPort{Name: "http"},
+			OpenAPISpec: hubv1alpha1.OpenAPISpec{Path: "/docs/openapi.yaml"},
+		},
+	}, got["authors"].Spec)
+
+	// Existing APIs are left untouched.
+	assert.Equal(t, existingAPI.Spec, got["existing"].Spec)
+}
@@ -20,6 +20,7 @@ package api
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"path"
 	"sort"
@@ -27,22 +28,32 @@ import (
 	"sync"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	hublisters "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/drift"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/keystore"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
+	"k8s.io/utils/strings/slices"
 )
 
 const (
@@ -56,6 +67,14 @@ type WatcherGatewayConfig struct {
 	AgentNamespace          string
 	TraefikAPIEntryPoint    string
 	TraefikTunnelEntryPoint string
+	// AuthServerAddr is the address of the auth server validating the API tokens of the requests sent to the gateways.
+	// API tokens aren't validated when empty.
+	AuthServerAddr string
+	// KeystoreFormats are the additional keystore formats (pkcs12, jks) written in the certificate Secrets.
+	KeystoreFormats []string
+	// KeystorePasswordSecret is the name of the Secret, in the agent namespace, holding the keystores password under the
+	// "password" key. Keystores are protected with an empty password when not set.
+	KeystorePasswordSecret string
 
 	GatewaySyncInterval time.Duration
 	CertSyncInterval    time.Duration
@@ -78,10 +97,17 @@ type WatcherGateway struct {
 	hubInformer  hubinformer.SharedInformerFactory
 
 	traefikClientSet v1alpha1.TraefikV1alpha1Interface
+
+	acmeLogger zerolog.Logger
 }
 
-// NewWatcherGateway returns a new WatcherGateway.
-func NewWatcherGateway(client PlatformClient, kubeClientSet clientset.Interface, kubeInformer informers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory, traefikClientSet v1alpha1.TraefikV1alpha1Interface, config *WatcherGatewayConfig) *WatcherGateway {
+// NewWatcherGateway returns a new WatcherGateway. API gateways are exposed through the Middlewares, TraefikServices and
+// IngressRoutes they generate, hence the Traefik client set is required.
+func NewWatcherGateway(client PlatformClient, kubeClientSet clientset.Interface, kubeInformer informers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory, traefikClientSet v1alpha1.TraefikV1alpha1Interface, config *WatcherGatewayConfig, acmeLogger zerolog.Logger) (*WatcherGateway, error) {
+	if traefikClientSet == nil {
+		return nil, errors.New("traefik client set is required")
+	}
+
 	return &WatcherGateway{
 		config: config,
 
@@ -94,7 +120,9 @@ func NewWatcherGateway(client PlatformClient, kubeClientSet clientset.Interface,
 		hubInformer:  hubInformer,
 
 		traefikClientSet: traefikClientSet,
-	}
+
+		acmeLogger: acmeLogger,
+	}, nil
 }
 
 // Run runs WatcherGateway.
@@ -105,7 +133,7 @@ func (w *WatcherGateway) Run(ctx context.Context) {
 	certSyncInterval := time.After(w.config.CertSyncInterval)
 	ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
 	if err := w.syncCertificates(ctxSync); err != nil {
-		log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
+		w.acmeLogger.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 		certSyncInterval = time.After(w.config.CertRetryInterval)
 	}
 	w.syncGateways(ctxSync)
@@ -125,7 +153,7 @@ func (w *WatcherGateway) Run(ctx context.Context) {
 		case <-certSyncInterval:
 			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
 			if err := w.syncCertificates(ctxSync); err != nil {
-				log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
+				w.acmeLogger.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 				certSyncInterval = time.After(w.config.CertRetryInterval)
 				cancel()
 				continue
@@ -213,84 +241,108 @@ func (w *WatcherGateway) setupCertificates(ctx context.Context, gateway *hubv1al
 }
 
 func (w *WatcherGateway) upsertSecret(ctx context.Context, cert edgeingress.Certificate, name, namespace string, gateway *hubv1alpha1.APIGateway) error {
-	secret, err := w.kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	keystores, err := w.keystoreConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("get keystore config: %w", err)
+	}
+
+	existingSecret, err := w.kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil && !kerror.IsNotFound(err) {
 		return fmt.Errorf("get secret: %w", err)
 	}
+	found := err == nil
 
-	if kerror.IsNotFound(err) {
-		secret = &corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "core.k8s.io/v1",
-				Kind:       "Secret",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: namespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/managed-by": "traefik-hub",
-				},
-			},
-			Type: corev1.SecretTypeTLS,
-			Data: map[string][]byte{
-				"tls.crt": cert.Certificate,
-				"tls.key": cert.PrivateKey,
-			},
-		}
-		if gateway != nil {
-			secret.OwnerReferences = []metav1.OwnerReference{{
-				APIVersion: "hub.traefik.io/v1alpha1",
-				Kind:       "APIGateway",
-				Name:       gateway.Name,
-				UID:        gateway.UID,
-			}}
-		}
-
-		_, err = w.kubeClientSet.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
-		if err != nil {
-			return fmt.Errorf("create secret: %w", err)
-		}
-
-		log.Debug().
-			Str("name", secret.Name).
-			Str("namespace", secret.Namespace).
-			Msg("Secret created")
-
-		return nil
+	var owners []metav1.OwnerReference
+	if found {
+		owners = existingSecret.OwnerReferences
 	}
-
-	newOwners := secret.OwnerReferences
 	if gateway != nil {
-		newOwners = appendOwnerReference(secret.OwnerReferences, metav1.OwnerReference{
+		owners = appendOwnerReference(owners, metav1.OwnerReference{
 			APIVersion: "hub.traefik.io/v1alpha1",
 			Kind:       "APIGateway",
 			Name:       gateway.Name,
 			UID:        gateway.UID,
 		})
 	}
-	if bytes.Equal(secret.Data["tls.crt"], cert.Certificate) && len(secret.OwnerReferences) == len(newOwners) {
+
+	if found && bytes.Equal(existingSecret.Data["tls.crt"], cert.Certificate) && len(existingSecret.OwnerReferences) == len(owners) &&
+		len(existingSecret.Data) == len(keystores.Formats)+2 && keystores.UpToDate(existingSecret.Data) {
 		return nil
 	}
 
-	secret.Data = map[string][]byte{
-		"tls.crt": cert.Certificate,
-		"tls.key": cert.PrivateKey,
+	data, err := secretData(cert, keystores)
+	if err != nil {
+		return err
 	}
-	secret.OwnerReferences = newOwners
 
-	_, err = w.kubeClientSet.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			// The Secret being shared by the APIGateways, all its owners are applied.
+			OwnerReferences: owners,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: data,
+	}
+
+	_, err = kube.Apply(ctx, w.kubeClientSet.CoreV1().Secrets(namespace).Patch, name, secret)
 	if err != nil {
-		return fmt.Errorf("update secret: %w", err)
+		return fmt.Errorf("apply secret: %w", err)
 	}
 
 	log.Debug().
 		Str("name", secret.Name).
 		Str("namespace", secret.Namespace).
-		Msg("Secret updated")
+		Msg("Secret applied")
 
 	return nil
 }
 
+// keystoreConfig returns the configuration of the keystores written alongside the certificates.
+func (w *WatcherGateway) keystoreConfig(ctx context.Context) (keystore.Config, error) {
+	cfg := keystore.Config{Formats: w.config.KeystoreFormats}
+	if len(cfg.Formats) == 0 || w.config.KeystorePasswordSecret == "" {
+		return cfg, nil
+	}
+
+	secret, err := w.kubeClientSet.CoreV1().Secrets(w.config.AgentNamespace).Get(ctx, w.config.KeystorePasswordSecret, metav1.GetOptions{})
+	if err != nil {
+		return keystore.Config{}, fmt.Errorf("get keystore password secret: %w", err)
+	}
+
+	password, ok := secret.Data["password"]
+	if !ok {
+		return keystore.Config{}, fmt.Errorf("missing password in secret %q", w.config.KeystorePasswordSecret)
+	}
+	cfg.Password = string(password)
+
+	return cfg, nil
+}
+
+// secretData returns the data of the Secret holding the given certificate and its keystores.
+func secretData(cert edgeingress.Certificate, keystores keystore.Config) (map[string][]byte, error) {
+	data, err := keystores.Data(cert.Certificate, cert.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("build keystores: %w", err)
+	}
+	if data == nil {
+		data = make(map[string][]byte, 2)
+	}
+
+	data["tls.crt"] = cert.Certificate
+	data["tls.key"] = cert.PrivateKey
+
+	return data, nil
+}
+
 func (w *WatcherGateway) syncGateways(ctx context.Context) {
 	platformGateways, err := w.platform.GetGateways(ctx)
 	if err != nil {
@@ -361,6 +413,7 @@ func (w *WatcherGateway) updateGateway(ctx context.Context, oldGateway *hubv1alp
 	}
 
 	obj.ObjectMeta = oldGateway.ObjectMeta
+	obj.Status.Conditions = oldGateway.Status.Conditions
 
 	if obj.Status.Version != oldGateway.Status.Version {
 		obj, err = w.hubClientSet.HubV1alpha1().APIGateways().Update(ctx, obj, metav1.UpdateOptions{})
@@ -407,8 +460,16 @@ func (w *WatcherGateway) syncChildResources(ctx context.Context, gateway *hubv1a
 	certificate := w.wildCardCert
 	w.wildCardCertMu.RUnlock()
 
-	if err := w.setupCertificates(ctx, gateway, apisByNamespace, certificate); err != nil {
-		return fmt.Errorf("unable to setup APIGateway certificates: %w", err)
+	certErr := w.setupCertificates(ctx, gateway, apisByNamespace, certificate)
+
+	if err = w.updateConditions(ctx, gateway, certificate, certErr); err != nil {
+		log.Error().Err(err).
+			Str("name", gateway.Name).
+			Msg("Unable to update APIGateway conditions")
+	}
+
+	if certErr != nil {
+		return fmt.Errorf("unable to setup APIGateway certificates: %w", certErr)
 	}
 
 	if err := w.cleanupIngresses(ctx, gateway, apisByNamespace); err != nil {
@@ -422,12 +483,102 @@ func (w *WatcherGateway) syncChildResources(ctx context.Context, gateway *hubv1a
 	return nil
 }
 
+// updateConditions refreshes the DNSReady and CertificateReady conditions of the given gateway.
+func (w *WatcherGateway) updateConditions(ctx context.Context, gateway *hubv1alpha1.APIGateway, certificate edgeingress.Certificate, certErr error) error {
+	updatedGateway := gateway.DeepCopy()
+	meta.SetStatusCondition(&updatedGateway.Status.Conditions, dnsReadyCondition(gateway))
+	meta.SetStatusCondition(&updatedGateway.Status.Conditions, certificateReadyCondition(gateway, certificate, certErr))
+
+	if equality.Semantic.DeepEqual(gateway.Status.Conditions, updatedGateway.Status.Conditions) {
+		return nil
+	}
+
+	_, err := w.hubClientSet.HubV1alpha1().APIGateways().Update(ctx, updatedGateway, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("update APIGateway: %w", err)
+	}
+
+	return nil
+}
+
+func dnsReadyCondition(gateway *hubv1alpha1.APIGateway) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               hubv1alpha1.APIGatewayConditionDNSReady,
+		ObservedGeneration: gateway.Generation,
+	}
+
+	if gateway.Status.HubDomain == "" {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "HubDomainNotAssigned"
+		condition.Message = "No Hub domain has been assigned to the APIGateway yet"
+
+		return condition
+	}
+
+	var unverifiedDomains []string
+	for _, domain := range gateway.Spec.CustomDomains {
+		if !slices.Contains(gateway.Status.CustomDomains, domain) {
+			unverifiedDomains = append(unverifiedDomains, domain)
+		}
+	}
+
+	if len(unverifiedDomains) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "CustomDomainsNotVerified"
+		condition.Message = fmt.Sprintf("Custom domains %s are not verified", strings.Join(unverifiedDomains, ", "))
+
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "DomainsReady"
+	condition.Message = "All the domains are assigned and verified"
+
+	return condition
+}
+
+func certificateReadyCondition(gateway *hubv1alpha1.APIGateway, certificate edgeingress.Certificate, certErr error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               hubv1alpha1.APIGatewayConditionCertificateReady,
+		ObservedGeneration: gateway.Generation,
+	}
+
+	switch {
+	case certErr != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "CertificateSetupFailed"
+		condition.Message = certErr.Error()
+	case len(certificate.Certificate) == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "CertificatePending"
+		condition.Message = "The Hub domain certificate hasn't been issued yet"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CertificatesProvisioned"
+		condition.Message = "The certificates of all the verified domains are provisioned"
+	}
+
+	return condition
+}
+
 func (w *WatcherGateway) apisByNamespace(ctx context.Context, gateway *hubv1alpha1.APIGateway) (map[string][]*hubv1alpha1.API, error) {
+	getAccess := func(name string) (*hubv1alpha1.APIAccess, error) {
+		return w.hubClientSet.HubV1alpha1().APIAccesses().Get(ctx, name, metav1.GetOptions{})
+	}
+
+	return gatewayAPIsByNamespace(gateway, getAccess,
+		w.hubInformer.Hub().V1alpha1().APIs().Lister(),
+		w.hubInformer.Hub().V1alpha1().APICollections().Lister())
+}
+
+// gatewayAPIsByNamespace returns the APIs exposed by the given gateway, grouped by namespace. APIs exposed through a
+// collection have their path prefix prefixed by the one of the collection.
+func gatewayAPIsByNamespace(gateway *hubv1alpha1.APIGateway, getAccess func(name string) (*hubv1alpha1.APIAccess, error), apiLister hublisters.APILister, collectionLister hublisters.APICollectionLister) (map[string][]*hubv1alpha1.API, error) {
 	apisByNamespace := make(map[string][]*hubv1alpha1.API)
 
 	var foundAPIs []*hubv1alpha1.API
 	for _, accessName := range gateway.Spec.APIAccesses {
-		access, err := w.hubClientSet.HubV1alpha1().APIAccesses().Get(ctx, accessName, metav1.GetOptions{})
+		access, err := getAccess(accessName)
 		if err != nil && kerror.IsNotFound(err) {
 			continue
 		}
@@ -436,19 +587,19 @@ func (w *WatcherGateway) apisByNamespace(ctx context.Context, gateway *hubv1alph
 			return nil, fmt.Errorf("get access: %w", err)
 		}
 
-		apis, err := w.findAPIs(access.Spec.APISelector)
+		apis, err := findAPIs(apiLister, access.Spec.APISelector)
 		if err != nil {
 			return nil, fmt.Errorf("find APIs: %w", err)
 		}
 		foundAPIs = append(foundAPIs, apis...)
 
-		collections, err := w.findCollections(access.Spec.APICollectionSelector)
+		collections, err := findCollections(collectionLister, access.Spec.APICollectionSelector)
 		if err != nil {
 			return nil, fmt.Errorf("find collections: %w", err)
 		}
 
 		for _, collection := range collections {
-			collectionAPIs, err := w.findAPIs(&collection.Spec.APISelector)
+			collectionAPIs, err := findAPIs(apiLister, &collection.Spec.APISelector)
 			if err != nil {
 				return nil, fmt.Errorf("find APIs: %w", err)
 			}
@@ -473,7 +624,7 @@ func (w *WatcherGateway) apisByNamespace(ctx context.Context, gateway *hubv1alph
 	return apisByNamespace, nil
 }
 
-func (w *WatcherGateway) findAPIs(selector *metav1.LabelSelector) ([]*hubv1alpha1.API, error) {
+func findAPIs(lister hublisters.APILister, selector *metav1.LabelSelector) ([]*hubv1alpha1.API, error) {
 	if selector == nil {
 		return nil, nil
 	}
@@ -483,7 +634,7 @@ func (w *WatcherGateway) findAPIs(selector *metav1.LabelSelector) ([]*hubv1alpha
 		return nil, fmt.Errorf("convert APIs label selector: %w", err)
 	}
 
-	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(labelSelector)
+	apis, err := lister.List(labelSelector)
 	if err != nil {
 		return nil, fmt.Errorf("list APIs: %w", err)
 	}
@@ -491,7 +642,7 @@ func (w *WatcherGateway) findAPIs(selector *metav1.LabelSelector) ([]*hubv1alpha
 	return apis, nil
 }
 
-func (w *WatcherGateway) findCollections(selector *metav1.LabelSelector) ([]*hubv1alpha1.APICollection, error) {
+func findCollections(lister hublisters.APICollectionLister, selector *metav1.LabelSelector) ([]*hubv1alpha1.APICollection, error) {
 	if selector == nil {
 		return nil, nil
 	}
@@ -501,7 +652,7 @@ func (w *WatcherGateway) findCollections(selector *metav1.LabelSelector) ([]*hub
 		return nil, fmt.Errorf("convert collections label selector: %w", err)
 	}
 
-	collections, err := w.hubInformer.Hub().V1alpha1().APICollections().Lister().List(labelSelector)
+	collections, err := lister.List(labelSelector)
 	if err != nil {
 		return nil, fmt.Errorf("list collections: %w", err)
 	}
@@ -516,6 +667,20 @@ func (w *WatcherGateway) upsertIngresses(ctx context.Context, gateway *hubv1alph
 			return fmt.Errorf("setup stripPrefix middleware: %w", err)
 		}
 
+		if w.config.AuthServerAddr != "" {
+			traefikAPITokenMiddlewareName, err := w.setupAPITokenMiddleware(ctx, gateway, namespace)
+			if err != nil {
+				return fmt.Errorf("setup API token middleware: %w", err)
+			}
+
+			// API tokens are validated before the path prefixes get stripped.
+			traefikMiddlewareName = traefikAPITokenMiddlewareName + "," + traefikMiddlewareName
+		}
+
+		if err = w.syncWeightedServices(ctx, apis); err != nil {
+			return fmt.Errorf("sync weighted services: %w", err)
+		}
+
 		ingress, err := w.buildHubDomainIngress(namespace, gateway, apis, traefikMiddlewareName)
 		if err != nil {
 			return fmt.Errorf("build ingress for hub domain and namespace %q: %w", namespace, err)
@@ -525,6 +690,10 @@ func (w *WatcherGateway) upsertIngresses(ctx context.Context, gateway *hubv1alph
 			return fmt.Errorf("upsert ingress for hub domain and namespace %q: %w", namespace, err)
 		}
 
+		if err = w.syncWeightedIngressRoute(ctx, ingress, apis, traefikMiddlewareName); err != nil {
+			return fmt.Errorf("sync weighted ingress route for hub domain and namespace %q: %w", namespace, err)
+		}
+
 		if len(gateway.Status.CustomDomains) != 0 {
 			ingress, err = w.buildCustomDomainsIngress(namespace, gateway, apis, traefikMiddlewareName)
 			if err != nil {
@@ -534,6 +703,10 @@ func (w *WatcherGateway) upsertIngresses(ctx context.Context, gateway *hubv1alph
 			if err = w.upsertIngress(ctx, ingress); err != nil {
 				return fmt.Errorf("upsert ingress for custom domains and namespace %q: %w", namespace, err)
 			}
+
+			if err = w.syncWeightedIngressRoute(ctx, ingress, apis, traefikMiddlewareName); err != nil {
+				return fmt.Errorf("sync weighted ingress route for custom domains and namespace %q: %w", namespace, err)
+			}
 		}
 	}
 
@@ -546,44 +719,147 @@ func (w *WatcherGateway) setupStripPrefixMiddleware(ctx context.Context, gateway
 		return "", fmt.Errorf("get stripPrefix middleware name: %w", err)
 	}
 
-	existingMiddleware, existingErr := w.traefikClientSet.Middlewares(namespace).Get(ctx, name, metav1.GetOptions{})
-	if existingErr != nil && !kerror.IsNotFound(existingErr) {
-		return "", fmt.Errorf("get middleware: %w", existingErr)
+	existingMiddleware, err := w.traefikClientSet.Middlewares(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return "", fmt.Errorf("get middleware: %w", err)
 	}
+	found := err == nil
 
 	middleware := newStripPrefixMiddleware(name, namespace, apis)
+	if err = drift.Stamp(&middleware.ObjectMeta, middleware.Spec); err != nil {
+		return "", fmt.Errorf("stamp middleware: %w", err)
+	}
 
 	traefikMiddlewareName, err := getTraefikStripPrefixMiddlewareName(namespace, gatewayName)
 	if err != nil {
 		return "", fmt.Errorf("get Traefik stripPrefix middleware name: %w", err)
 	}
 
-	if kerror.IsNotFound(existingErr) {
-		_, err = w.traefikClientSet.Middlewares(namespace).Create(ctx, &middleware, metav1.CreateOptions{})
+	if found && equality.Semantic.DeepEqual(middleware.Spec, existingMiddleware.Spec) && drift.IsStamped(existingMiddleware.ObjectMeta) {
+		return traefikMiddlewareName, nil
+	}
+
+	if _, err = kube.Apply(ctx, w.traefikClientSet.Middlewares(namespace).Patch, name, &middleware); err != nil {
+		return "", fmt.Errorf("apply middleware: %w", err)
+	}
+
+	log.Debug().
+		Str("name", name).
+		Str("namespace", namespace).
+		Msg("Middleware applied")
+
+	return traefikMiddlewareName, nil
+}
+
+// setupAPITokenMiddleware creates or updates the ForwardAuth middleware validating, using the auth server, the API
+// tokens of the requests sent to the given gateway.
+func (w *WatcherGateway) setupAPITokenMiddleware(ctx context.Context, gateway *hubv1alpha1.APIGateway, namespace string) (string, error) {
+	name, err := getAPITokenMiddlewareName(gateway.Name)
+	if err != nil {
+		return "", fmt.Errorf("get API token middleware name: %w", err)
+	}
+
+	existingMiddleware, err := w.traefikClientSet.Middlewares(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return "", fmt.Errorf("get middleware: %w", err)
+	}
+	found := err == nil
+
+	middleware := newAPITokenMiddleware(name, namespace, w.config.AuthServerAddr, gateway)
+	if err = drift.Stamp(&middleware.ObjectMeta, middleware.Spec); err != nil {
+		return "", fmt.Errorf("stamp middleware: %w", err)
+	}
+	traefikMiddlewareName := fmt.Sprintf("%s-%s@kubernetescrd", namespace, name)
+
+	if found && equality.Semantic.DeepEqual(middleware.Spec, existingMiddleware.Spec) && drift.IsStamped(existingMiddleware.ObjectMeta) {
+		return traefikMiddlewareName, nil
+	}
+
+	if _, err = kube.Apply(ctx, w.traefikClientSet.Middlewares(namespace).Patch, name, &middleware); err != nil {
+		return "", fmt.Errorf("apply middleware: %w", err)
+	}
+
+	log.Debug().
+		Str("name", name).
+		Str("namespace", namespace).
+		Msg("Middleware applied")
+
+	return traefikMiddlewareName, nil
+}
+
+// syncWeightedServices applies the TraefikServices load-balancing the traffic of APIs having weighted
+// services, and removes the ones of APIs which no longer have any.
+func (w *WatcherGateway) syncWeightedServices(ctx context.Context, apis []*hubv1alpha1.API) error {
+	synced := make(map[string]struct{})
+	for _, api := range apis {
+		// The same API can be exposed multiple times through collections.
+		if _, ok := synced[api.Name]; ok {
+			continue
+		}
+		synced[api.Name] = struct{}{}
+
+		name, err := getWeightedServiceName(api.Name)
 		if err != nil {
-			return "", fmt.Errorf("create middleware: %w", err)
+			return fmt.Errorf("get weighted service name: %w", err)
+		}
+
+		if len(api.Spec.Service.Weighted) == 0 {
+			err = w.traefikClientSet.TraefikServices(api.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+			if err != nil && !kerror.IsNotFound(err) {
+				return fmt.Errorf("delete TraefikService: %w", err)
+			}
+
+			continue
+		}
+
+		svc := newWeightedService(name, api)
+		if err = drift.Stamp(&svc.ObjectMeta, svc.Spec); err != nil {
+			return fmt.Errorf("stamp TraefikService: %w", err)
+		}
+
+		if _, err = kube.Apply(ctx, w.traefikClientSet.TraefikServices(api.Namespace).Patch, name, &svc); err != nil {
+			return fmt.Errorf("apply TraefikService: %w", err)
 		}
 
 		log.Debug().
 			Str("name", name).
-			Str("namespace", namespace).
-			Msg("Middleware created")
-
-		return traefikMiddlewareName, nil
+			Str("namespace", api.Namespace).
+			Msg("TraefikService applied")
 	}
 
-	if middleware.Spec == existingMiddleware.Spec {
-		return traefikMiddlewareName, nil
+	return nil
+}
+
+// syncWeightedIngressRoute applies the IngressRoute routing the traffic of the APIs having weighted services
+// to their TraefikService, and removes it when none of the given APIs has weighted services. The Kubernetes Ingress
+// provider of Traefik can't reference TraefikServices: the IngressRoute takes precedence over the given Ingress, which
+// keeps routing these APIs to their main service.
+func (w *WatcherGateway) syncWeightedIngressRoute(ctx context.Context, ingress *netv1.Ingress, apis []*hubv1alpha1.API, traefikMiddlewareName string) error {
+	ingRoute := newWeightedIngressRoute(ingress, apis, traefikMiddlewareName)
+	if ingRoute == nil {
+		err := w.traefikClientSet.IngressRoutes(ingress.Namespace).Delete(ctx, ingress.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete IngressRoute: %w", err)
+		}
+
+		return nil
 	}
 
-	existingMiddleware.Spec = middleware.Spec
+	if err := drift.Stamp(&ingRoute.ObjectMeta, ingRoute.Spec); err != nil {
+		return fmt.Errorf("stamp IngressRoute: %w", err)
+	}
 
-	_, err = w.traefikClientSet.Middlewares(existingMiddleware.Namespace).Update(ctx, existingMiddleware, metav1.UpdateOptions{})
+	_, err := kube.Apply(ctx, w.traefikClientSet.IngressRoutes(ingRoute.Namespace).Patch, ingRoute.Name, ingRoute)
 	if err != nil {
-		return "", fmt.Errorf("update middleware: %w", err)
+		return fmt.Errorf("apply IngressRoute: %w", err)
 	}
 
-	return traefikMiddlewareName, nil
+	log.Debug().
+		Str("name", ingRoute.Name).
+		Str("namespace", ingRoute.Namespace).
+		Msg("IngressRoute applied")
+
+	return nil
 }
 
 func (w *WatcherGateway) upsertIngress(ctx context.Context, ingress *netv1.Ingress) error {
@@ -693,6 +969,22 @@ func (w *WatcherGateway) cleanupIngresses(ctx context.Context, gateway *hubv1alp
 				continue
 			}
 
+			w.cleanupAPITokenMiddleware(ctx, gateway.Name, ingress.Namespace)
+
+			err = w.traefikClientSet.
+				IngressRoutes(ingress.Namespace).
+				Delete(ctx, ingress.Name, metav1.DeleteOptions{})
+
+			if err != nil && !kerror.IsNotFound(err) {
+				log.Ctx(ctx).
+					Error().
+					Err(err).
+					Str("gateway_name", gateway.Name).
+					Str("ingress_route_name", ingress.Name).
+					Str("ingress_route_namespace", ingress.Namespace).
+					Msg("Unable to clean APIGateway's child IngressRoute")
+			}
+
 			err = w.kubeClientSet.NetworkingV1().
 				Ingresses(ingress.Namespace).
 				Delete(ctx, ingress.Name, metav1.DeleteOptions{})
@@ -714,6 +1006,27 @@ func (w *WatcherGateway) cleanupIngresses(ctx context.Context, gateway *hubv1alp
 	return nil
 }
 
+// cleanupAPITokenMiddleware removes the API token middleware of the given gateway from the given namespace, if any.
+func (w *WatcherGateway) cleanupAPITokenMiddleware(ctx context.Context, gatewayName, namespace string) {
+	logger := log.Ctx(ctx).With().
+		Str("gateway_name", gatewayName).
+		Str("middleware_namespace", namespace).
+		Logger()
+
+	name, err := getAPITokenMiddlewareName(gatewayName)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get APIGateway's child API token Middleware name")
+		return
+	}
+
+	err = w.traefikClientSet.Middlewares(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		logger.Error().Err(err).
+			Str("middleware_name", name).
+			Msg("Unable to clean APIGateway's child API token Middleware")
+	}
+}
+
 func (w *WatcherGateway) buildHubDomainIngress(namespace string, gateway *hubv1alpha1.APIGateway, apis []*hubv1alpha1.API, traefikMiddlewareName string) (*netv1.Ingress, error) {
 	name, err := getHubDomainIngressName(gateway.Name)
 	if err != nil {
@@ -780,15 +1093,18 @@ func (w *WatcherGateway) buildIngressSpec(domains []string, apis []*hubv1alpha1.
 
 	var paths []netv1.HTTPIngressPath
 	for _, api := range apis {
+		// APIs having weighted services are also routed by the weighted IngressRoute, which takes precedence.
+		backend := netv1.IngressBackend{
+			Service: &netv1.IngressServiceBackend{
+				Name: api.Spec.Service.Name,
+				Port: netv1.ServiceBackendPort(api.Spec.Service.Port),
+			},
+		}
+
 		paths = append(paths, netv1.HTTPIngressPath{
 			PathType: &pathType,
 			Path:     api.Spec.PathPrefix,
-			Backend: netv1.IngressBackend{
-				Service: &netv1.IngressServiceBackend{
-					Name: api.Spec.Service.Name,
-					Port: netv1.ServiceBackendPort(api.Spec.Service.Port),
-				},
-			},
+			Backend:  backend,
 		})
 	}
 
@@ -843,10 +1159,182 @@ func newStripPrefixMiddleware(name, namespace string, apis []*hubv1alpha1.API) t
 	}
 }
 
+// newWeightedIngressRoute returns the IngressRoute routing the traffic of the given APIs having weighted services to
+// their TraefikService, on the hosts, entry point and with the middlewares of the given Ingress. It returns nil when
+// none of the APIs has weighted services.
+func newWeightedIngressRoute(ingress *netv1.Ingress, apis []*hubv1alpha1.API, traefikMiddlewareName string) *traefikv1alpha1.IngressRoute {
+	// Middlewares are referenced with their provider, as done by the Ingress annotation.
+	var middlewares []traefikv1alpha1.MiddlewareRef
+	for _, name := range strings.Split(traefikMiddlewareName, ",") {
+		middlewares = append(middlewares, traefikv1alpha1.MiddlewareRef{Name: name})
+	}
+
+	var routes []traefikv1alpha1.Route
+	for _, api := range apis {
+		if len(api.Spec.Service.Weighted) == 0 {
+			continue
+		}
+
+		// The weighted service name has already been computed successfully when syncing weighted services.
+		name, _ := getWeightedServiceName(api.Name)
+
+		for _, rule := range ingress.Spec.Rules {
+			match := fmt.Sprintf("Host(`%s`) && PathPrefix(`%s`)", rule.Host, api.Spec.PathPrefix)
+
+			routes = append(routes, traefikv1alpha1.Route{
+				Match: match,
+				Kind:  "Rule",
+				// The Ingress router of the API has the same rule, hence the same default priority.
+				Priority:    len(match) + 1,
+				Middlewares: middlewares,
+				Services: []traefikv1alpha1.Service{
+					{
+						LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+							Name: name,
+							Kind: "TraefikService",
+						},
+					},
+				},
+			})
+		}
+	}
+
+	if len(routes) == 0 {
+		return nil
+	}
+
+	// The labels are copied, as the IngressRoute is stamped for drift detection.
+	labels := make(map[string]string, len(ingress.Labels))
+	for key, value := range ingress.Labels {
+		labels[key] = value
+	}
+
+	return &traefikv1alpha1.IngressRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "IngressRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            ingress.Name,
+			Namespace:       ingress.Namespace,
+			Labels:          labels,
+			OwnerReferences: ingress.OwnerReferences,
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			EntryPoints: []string{ingress.Annotations["traefik.ingress.kubernetes.io/router.entrypoints"]},
+			Routes:      routes,
+			TLS: &traefikv1alpha1.TLS{
+				SecretName: ingress.Spec.TLS[0].SecretName,
+			},
+		},
+	}
+}
+
+func newWeightedService(name string, api *hubv1alpha1.API) traefikv1alpha1.TraefikService {
+	services := []traefikv1alpha1.Service{
+		{
+			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+				Name:   api.Spec.Service.Name,
+				Kind:   "Service",
+				Port:   toIntOrString(api.Spec.Service.Port),
+				Weight: api.Spec.Service.Weight,
+			},
+		},
+	}
+
+	for _, weighted := range api.Spec.Service.Weighted {
+		weight := weighted.Weight
+		services = append(services, traefikv1alpha1.Service{
+			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+				Name:   weighted.Name,
+				Kind:   "Service",
+				Port:   toIntOrString(weighted.Port),
+				Weight: &weight,
+			},
+		})
+	}
+
+	return traefikv1alpha1.TraefikService{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TraefikService",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: api.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			// Set OwnerReference allow us to delete the TraefikService along with its API.
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "hub.traefik.io/v1alpha1",
+					Kind:       "API",
+					Name:       api.Name,
+					UID:        api.UID,
+				},
+			},
+		},
+		Spec: traefikv1alpha1.ServiceSpec{
+			Weighted: &traefikv1alpha1.WeightedRoundRobin{
+				Services: services,
+			},
+		},
+	}
+}
+
+func toIntOrString(port hubv1alpha1.APIServiceBackendPort) intstr.IntOrString {
+	if port.Number != 0 {
+		return intstr.FromInt(int(port.Number))
+	}
+
+	return intstr.FromString(port.Name)
+}
+
+// getWeightedServiceName compute the name of the TraefikService load-balancing the traffic of an API.
+// The name follow this format: {api-name}-{hash(api-name)}-weighted
+// This hash is here to reduce the chance of getting a collision on an existing TraefikService.
+func getWeightedServiceName(apiName string) (string, error) {
+	h, err := hash(apiName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-weighted", apiName, h), nil
+}
+
 // getStripPrefixMiddlewareName compute the name of the stripPrefix middleware.
 // The name follow this format: {{gateway-name}-hash({gateway-name})-stripprefix}
 // This hash is here to reduce the chance of getting a collision on an existing secret while staying under
 // the limit of 63 characters.
+func newAPITokenMiddleware(name, namespace, authServerAddr string, gateway *hubv1alpha1.APIGateway) traefikv1alpha1.Middleware {
+	return traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Middleware",
+			APIVersion: "traefik.containo.us/v1alpha1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: traefikv1alpha1.MiddlewareSpec{
+			ForwardAuth: &traefikv1alpha1.ForwardAuth{
+				Address:             strings.TrimSuffix(authServerAddr, "/") + apitoken.PathPrefix + "/" + gateway.Name,
+				AuthResponseHeaders: apitoken.GatewayIdentityHeaders(gateway).Names(),
+			},
+		},
+	}
+}
+
+func getAPITokenMiddlewareName(gatewayName string) (string, error) {
+	h, err := hash(gatewayName)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s-%d-apitoken", gatewayName, h), nil
+}
+
 func getStripPrefixMiddlewareName(gatewayName string) (string, error) {
 	h, err := hash(gatewayName)
 	if err != nil {
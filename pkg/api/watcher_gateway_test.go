@@ -19,10 +19,18 @@ package api
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -32,6 +40,8 @@ import (
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/keystore"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
 	"golang.org/x/exp/slices"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
@@ -56,6 +66,7 @@ func Test_WatcherGatewayRun(t *testing.T) {
 	tests := []struct {
 		desc             string
 		platformGateways []Gateway
+		authServerAddr   string
 
 		clusterGateways    string
 		clusterAccesses    string
@@ -65,10 +76,12 @@ func Test_WatcherGatewayRun(t *testing.T) {
 		clusterSecrets     string
 		clusterMiddlewares string
 
-		wantGateways    string
-		wantIngresses   string
-		wantSecrets     string
-		wantMiddlewares string
+		wantGateways        string
+		wantIngresses       string
+		wantSecrets         string
+		wantMiddlewares     string
+		wantTraefikServices string
+		wantIngressRoutes   string
 	}{
 		{
 			desc: "new gateway present on the platform needs to be created on the cluster",
@@ -147,6 +160,57 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			wantSecrets:        "testdata/remove-api-from-gateway/want.secrets.yaml",
 			wantMiddlewares:    "testdata/remove-api-from-gateway/want.middlewares.yaml",
 		},
+		{
+			desc: "API with weighted services is exposed through a TraefikService",
+			platformGateways: []Gateway{
+				{
+					Name:      "new-gateway",
+					Labels:    map[string]string{"area": "stores"},
+					Accesses:  []string{"products"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+					CustomDomains: []CustomDomain{
+						{Name: "api.hello.example.com", Verified: true},
+						{Name: "api.welcome.example.com", Verified: true},
+						{Name: "not-verified.example.com", Verified: false},
+					},
+				},
+			},
+			clusterAccesses:     "testdata/weighted-api/accesses.yaml",
+			clusterCollections:  "testdata/weighted-api/collections.yaml",
+			clusterAPIs:         "testdata/weighted-api/apis.yaml",
+			wantGateways:        "testdata/weighted-api/want.gateways.yaml",
+			wantIngresses:       "testdata/weighted-api/want.ingresses.yaml",
+			wantSecrets:         "testdata/weighted-api/want.secrets.yaml",
+			wantMiddlewares:     "testdata/weighted-api/want.middlewares.yaml",
+			wantTraefikServices: "testdata/weighted-api/want.traefikservices.yaml",
+			wantIngressRoutes:   "testdata/weighted-api/want.ingressroutes.yaml",
+		},
+		{
+			desc: "API tokens are validated by the auth server",
+			platformGateways: []Gateway{
+				{
+					Name:      "new-gateway",
+					Labels:    map[string]string{"area": "stores"},
+					Accesses:  []string{"products"},
+					Version:   "version-1",
+					HubDomain: "brave-lion-123.hub-traefik.io",
+					CustomDomains: []CustomDomain{
+						{Name: "api.hello.example.com", Verified: true},
+						{Name: "api.welcome.example.com", Verified: true},
+						{Name: "not-verified.example.com", Verified: false},
+					},
+				},
+			},
+			authServerAddr:     "http://hub-agent-auth-server.hub.svc.cluster.local",
+			clusterAccesses:    "testdata/api-token-gateway/accesses.yaml",
+			clusterCollections: "testdata/api-token-gateway/collections.yaml",
+			clusterAPIs:        "testdata/api-token-gateway/apis.yaml",
+			wantGateways:       "testdata/api-token-gateway/want.gateways.yaml",
+			wantIngresses:      "testdata/api-token-gateway/want.ingresses.yaml",
+			wantSecrets:        "testdata/api-token-gateway/want.secrets.yaml",
+			wantMiddlewares:    "testdata/api-token-gateway/want.middlewares.yaml",
+		},
 		{
 			desc:             "deleted gateway on the platform needs to be deleted on the cluster",
 			platformGateways: []Gateway{},
@@ -164,6 +228,8 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			wantIngresses := loadFixtures[netv1.Ingress](t, test.wantIngresses)
 			wantSecrets := loadFixtures[corev1.Secret](t, test.wantSecrets)
 			wantMiddlewares := loadFixtures[traefikv1alpha1.Middleware](t, test.wantMiddlewares)
+			wantTraefikServices := loadFixtures[traefikv1alpha1.TraefikService](t, test.wantTraefikServices)
+			wantIngressRoutes := loadFixtures[traefikv1alpha1.IngressRoute](t, test.wantIngressRoutes)
 
 			clusterGateways := loadFixtures[hubv1alpha1.APIGateway](t, test.clusterGateways)
 			clusterAccesses := loadFixtures[hubv1alpha1.APIAccess](t, test.clusterAccesses)
@@ -202,8 +268,10 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			}
 
 			kubeClientSet := kubemock.NewSimpleClientset(kubeObjects...)
+			kubetest.AddApplyReactor(kubeClientSet)
 			hubClientSet := hubkubemock.NewSimpleClientset(hubObjects...)
 			traefikClientSet := traefikkubemock.NewSimpleClientset(traefikObjects...)
+			kubetest.AddApplyReactor(traefikClientSet)
 
 			ctx, cancel := context.WithCancel(context.Background())
 
@@ -256,15 +324,17 @@ func Test_WatcherGatewayRun(t *testing.T) {
 					}, nil)
 			}
 
-			w := NewWatcherGateway(client, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet.TraefikV1alpha1(), &WatcherGatewayConfig{
+			w, err := NewWatcherGateway(client, kubeClientSet, kubeInformer, hubClientSet, hubInformer, traefikClientSet.TraefikV1alpha1(), &WatcherGatewayConfig{
 				IngressClassName:        "ingress-class",
 				AgentNamespace:          "agent-ns",
 				TraefikAPIEntryPoint:    "api-entrypoint",
 				TraefikTunnelEntryPoint: "tunnel-entrypoint",
+				AuthServerAddr:          test.authServerAddr,
 				GatewaySyncInterval:     time.Millisecond,
 				CertSyncInterval:        time.Millisecond,
 				CertRetryInterval:       time.Millisecond,
-			})
+			}, zerolog.Nop())
+			require.NoError(t, err)
 
 			stop := make(chan struct{})
 			go func() {
@@ -278,10 +348,88 @@ func Test_WatcherGatewayRun(t *testing.T) {
 			assertSecretsMatches(t, kubeClientSet, namespaces, wantSecrets)
 			assertIngressesMatches(t, kubeClientSet, namespaces, wantIngresses)
 			assertMiddlewaresMatches(t, traefikClientSet, namespaces, wantMiddlewares)
+			assertTraefikServicesMatches(t, traefikClientSet, namespaces, wantTraefikServices)
+			assertIngressRoutesMatches(t, traefikClientSet, namespaces, wantIngressRoutes)
 		})
 	}
 }
 
+func Test_WatcherGatewayUpsertSecret_keystores(t *testing.T) {
+	cert := generateCertificate(t)
+
+	kubeClientSet := kubemock.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore-password", Namespace: "agent-ns"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	})
+	kubetest.AddApplyReactor(kubeClientSet)
+
+	w, err := NewWatcherGateway(nil, kubeClientSet, nil, nil, nil, traefikkubemock.NewSimpleClientset().TraefikV1alpha1(), &WatcherGatewayConfig{
+		AgentNamespace:         "agent-ns",
+		KeystoreFormats:        []string{keystore.FormatPKCS12, keystore.FormatJKS},
+		KeystorePasswordSecret: "keystore-password",
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	err = w.upsertSecret(ctx, cert, "hub-certificate", "default", nil)
+	require.NoError(t, err)
+
+	secret, err := kubeClientSet.CoreV1().Secrets("default").Get(ctx, "hub-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, cert.Certificate, secret.Data["tls.crt"])
+	assert.Equal(t, cert.PrivateKey, secret.Data["tls.key"])
+	assert.NotEmpty(t, secret.Data[keystore.SecretKeyPKCS12])
+	assert.NotEmpty(t, secret.Data[keystore.SecretKeyJKS])
+
+	// Keystores are salted: an up-to-date Secret must not be rewritten.
+	err = w.upsertSecret(ctx, cert, "hub-certificate", "default", nil)
+	require.NoError(t, err)
+
+	got, err := kubeClientSet.CoreV1().Secrets("default").Get(ctx, "hub-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, secret.Data, got.Data)
+
+	// Keystores are rewritten when the password changes.
+	_, err = kubeClientSet.CoreV1().Secrets("agent-ns").Update(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore-password", Namespace: "agent-ns"},
+		Data:       map[string][]byte{"password": []byte("other")},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = w.upsertSecret(ctx, cert, "hub-certificate", "default", nil)
+	require.NoError(t, err)
+
+	got, err = kubeClientSet.CoreV1().Secrets("default").Get(ctx, "hub-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, secret.Data[keystore.SecretKeyJKS], got.Data[keystore.SecretKeyJKS])
+	assert.True(t, keystore.Config{Formats: w.config.KeystoreFormats, Password: "other"}.UpToDate(got.Data))
+}
+
+func generateCertificate(t *testing.T) edgeingress.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.hub.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return edgeingress.Certificate{
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		PrivateKey:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
 func assertGatewaysMatches(t *testing.T, hubClientSet *hubkubemock.Clientset, want []hubv1alpha1.APIGateway) {
 	t.Helper()
 
@@ -298,6 +446,9 @@ func assertGatewaysMatches(t *testing.T, hubClientSet *hubkubemock.Clientset, wa
 	var gateways []hubv1alpha1.APIGateway
 	for _, gateway := range gatewayList.Items {
 		gateway.Status.SyncedAt = metav1.Time{}
+		for i := range gateway.Status.Conditions {
+			gateway.Status.Conditions[i].LastTransitionTime = metav1.Time{}
+		}
 
 		gateways = append(gateways, gateway)
 	}
@@ -387,3 +538,53 @@ func assertMiddlewaresMatches(t *testing.T, traefikClientSet *traefikkubemock.Cl
 
 	assert.Equal(t, want, middlewares)
 }
+
+func assertTraefikServicesMatches(t *testing.T, traefikClientSet *traefikkubemock.Clientset, namespaces []string, want []traefikv1alpha1.TraefikService) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Name < want[j].Name
+	})
+
+	var services []traefikv1alpha1.TraefikService
+	for _, namespace := range namespaces {
+		namespaceServiceList, err := traefikClientSet.TraefikV1alpha1().TraefikServices(namespace).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+
+		services = append(services, namespaceServiceList.Items...)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	assert.Equal(t, want, services)
+}
+
+func assertIngressRoutesMatches(t *testing.T, traefikClientSet *traefikkubemock.Clientset, namespaces []string, want []traefikv1alpha1.IngressRoute) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Name < want[j].Name
+	})
+
+	var ingRoutes []traefikv1alpha1.IngressRoute
+	for _, namespace := range namespaces {
+		namespaceIngRouteList, err := traefikClientSet.TraefikV1alpha1().IngressRoutes(namespace).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+
+		ingRoutes = append(ingRoutes, namespaceIngRouteList.Items...)
+	}
+
+	sort.Slice(ingRoutes, func(i, j int) bool {
+		return ingRoutes[i].Name < ingRoutes[j].Name
+	})
+
+	assert.Equal(t, want, ingRoutes)
+}
@@ -24,11 +24,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
@@ -78,10 +80,12 @@ type WatcherPortal struct {
 
 	hubClientSet hubclientset.Interface
 	hubInformer  hubinformer.SharedInformerFactory
+
+	acmeLogger zerolog.Logger
 }
 
 // NewWatcherPortal returns a new WatcherPortal.
-func NewWatcherPortal(client PlatformClient, kubeClientSet clientset.Interface, kubeInformer informers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory, config *WatcherPortalConfig) *WatcherPortal {
+func NewWatcherPortal(client PlatformClient, kubeClientSet clientset.Interface, kubeInformer informers.SharedInformerFactory, hubClientSet hubclientset.Interface, hubInformer hubinformer.SharedInformerFactory, config *WatcherPortalConfig, acmeLogger zerolog.Logger) *WatcherPortal {
 	return &WatcherPortal{
 		config: config,
 
@@ -92,6 +96,8 @@ func NewWatcherPortal(client PlatformClient, kubeClientSet clientset.Interface,
 
 		hubClientSet: hubClientSet,
 		hubInformer:  hubInformer,
+
+		acmeLogger: acmeLogger,
 	}
 }
 
@@ -119,7 +125,7 @@ func (w *WatcherPortal) Run(ctx context.Context) {
 		case <-certSyncInterval:
 			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
 			if err := w.syncCertificates(ctxSync); err != nil {
-				log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
+				w.acmeLogger.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 				certSyncInterval = time.After(w.config.CertRetryInterval)
 				cancel()
 				continue
@@ -489,39 +495,17 @@ func (w *WatcherPortal) buildIngress(portal *hubv1alpha1.APIPortal, ingressName,
 }
 
 func (w *WatcherPortal) upsertSecret(ctx context.Context, cert edgeingress.Certificate, portal *hubv1alpha1.APIPortal, name string) error {
-	existingSecret, err := w.kubeClientSet.CoreV1().Secrets(w.config.AgentNamespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil && !kerror.IsNotFound(err) {
-		return fmt.Errorf("get secret: %w", err)
-	}
-
 	secret := w.buildSecret(cert, portal, name)
-	if kerror.IsNotFound(err) {
-		_, err = w.kubeClientSet.CoreV1().Secrets(w.config.AgentNamespace).Create(ctx, secret, metav1.CreateOptions{})
-		if err != nil {
-			return fmt.Errorf("create secret: %w", err)
-		}
-
-		log.Debug().
-			Str("name", secret.Name).
-			Str("namespace", secret.Namespace).
-			Msg("Portal certificate Secret created")
-
-		return nil
-	}
-
-	existingSecret.Data = secret.Data
-	existingSecret.ObjectMeta.Annotations = secret.ObjectMeta.Annotations
-	existingSecret.ObjectMeta.Labels = secret.ObjectMeta.Labels
 
-	_, err = w.kubeClientSet.CoreV1().Secrets(w.config.AgentNamespace).Update(ctx, existingSecret, metav1.UpdateOptions{})
+	_, err := kube.Apply(ctx, w.kubeClientSet.CoreV1().Secrets(w.config.AgentNamespace).Patch, name, secret)
 	if err != nil {
-		return fmt.Errorf("update secret: %w", err)
+		return fmt.Errorf("apply secret: %w", err)
 	}
 
 	log.Debug().
-		Str("name", existingSecret.Name).
-		Str("namespace", existingSecret.Namespace).
-		Msg("Portal certificate Secret updated")
+		Str("name", secret.Name).
+		Str("namespace", secret.Namespace).
+		Msg("Portal certificate Secret applied")
 
 	return nil
 }
@@ -529,7 +513,7 @@ func (w *WatcherPortal) upsertSecret(ctx context.Context, cert edgeingress.Certi
 func (w *WatcherPortal) buildSecret(cert edgeingress.Certificate, portal *hubv1alpha1.APIPortal, name string) *corev1.Secret {
 	return &corev1.Secret{
 		TypeMeta: metav1.TypeMeta{
-			APIVersion: "core.k8s.io/v1",
+			APIVersion: "v1",
 			Kind:       "Secret",
 		},
 		ObjectMeta: metav1.ObjectMeta{
@@ -25,6 +25,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -32,6 +33,7 @@ import (
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -145,6 +147,7 @@ func Test_WatcherRun(t *testing.T) {
 			}
 
 			kubeClientSet := kubemock.NewSimpleClientset(kubeObjects...)
+			kubetest.AddApplyReactor(kubeClientSet)
 			hubClientSet := hubkubemock.NewSimpleClientset(hubObjects...)
 
 			ctx, cancel := context.WithCancel(context.Background())
@@ -199,7 +202,7 @@ func Test_WatcherRun(t *testing.T) {
 				PortalSyncInterval:      time.Millisecond,
 				CertSyncInterval:        time.Millisecond,
 				CertRetryInterval:       time.Millisecond,
-			})
+			}, zerolog.Nop())
 
 			stop := make(chan struct{})
 			go func() {
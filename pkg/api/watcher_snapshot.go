@@ -0,0 +1,226 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	// maxSpecSnapshots is the maximum number of snapshots kept for each API.
+	maxSpecSnapshots = 10
+	// maxSpecSnapshotsSize is the maximum size of the snapshots kept for each API, ConfigMaps being limited to 1MiB.
+	maxSpecSnapshotsSize = 900 * 1024
+)
+
+// WatcherSnapshot periodically snapshots the OpenAPI specs of the APIs into ConfigMaps, keeping a history of their
+// last revisions. It reports specs changing without their API being updated.
+type WatcherSnapshot struct {
+	syncInterval time.Duration
+	namespace    string
+
+	httpClient *http.Client
+	now        func() time.Time
+
+	kubeClientSet clientset.Interface
+	hubInformer   hubinformer.SharedInformerFactory
+}
+
+// NewWatcherSnapshot returns a new WatcherSnapshot storing the snapshots in ConfigMaps of the given namespace.
+func NewWatcherSnapshot(kubeClientSet clientset.Interface, hubInformer hubinformer.SharedInformerFactory, namespace string, syncInterval time.Duration) *WatcherSnapshot {
+	return &WatcherSnapshot{
+		syncInterval:  syncInterval,
+		namespace:     namespace,
+		httpClient:    &http.Client{Timeout: 5 * time.Second},
+		now:           time.Now,
+		kubeClientSet: kubeClientSet,
+		hubInformer:   hubInformer,
+	}
+}
+
+// Run runs WatcherSnapshot.
+func (w *WatcherSnapshot) Run(ctx context.Context) {
+	t := time.NewTicker(w.syncInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping API spec snapshot watcher")
+			return
+
+		case <-t.C:
+			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
+			w.syncSnapshots(ctxSync)
+			cancel()
+		}
+	}
+}
+
+func (w *WatcherSnapshot) syncSnapshots(ctx context.Context) {
+	apis, err := w.hubInformer.Hub().V1alpha1().APIs().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to obtain APIs")
+		return
+	}
+
+	configMapNames := make(map[string]struct{})
+	for _, api := range apis {
+		configMapNames[SpecSnapshotsConfigMapName(api.Name, api.Namespace)] = struct{}{}
+
+		if err = w.snapshot(ctx, api); err != nil {
+			log.Error().Err(err).
+				Str("name", api.Name).
+				Str("namespace", api.Namespace).
+				Msg("Unable to snapshot OpenAPI spec")
+		}
+	}
+
+	w.cleanSnapshots(ctx, configMapNames)
+}
+
+func (w *WatcherSnapshot) snapshot(ctx context.Context, api *hubv1alpha1.API) error {
+	rawSpec, err := fetchRawOpenAPISpec(ctx, w.httpClient, w.kubeClientSet.CoreV1(), api)
+	if err != nil {
+		return err
+	}
+
+	name := SpecSnapshotsConfigMapName(api.Name, api.Namespace)
+
+	cm, err := w.kubeClientSet.CoreV1().ConfigMaps(w.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get snapshots ConfigMap: %w", err)
+	}
+
+	exists := err == nil
+	if !exists {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: w.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "traefik-hub",
+					LabelSpecSnapshots:             "true",
+				},
+			},
+		}
+	}
+
+	snapshots, err := readSpecSnapshots(cm)
+	if err != nil {
+		return fmt.Errorf("read snapshots: %w", err)
+	}
+
+	revision := SpecRevision(rawSpec)
+	if len(snapshots) > 0 {
+		last := snapshots[len(snapshots)-1]
+		if last.Revision == revision {
+			return nil
+		}
+
+		if last.Generation == api.Generation {
+			log.Warn().
+				Str("name", api.Name).
+				Str("namespace", api.Namespace).
+				Str("previous_revision", last.Revision).
+				Str("revision", revision).
+				Msg("OpenAPI spec changed while the API didn't")
+		}
+	}
+
+	snapshots = trimSpecSnapshots(append(snapshots, SpecSnapshot{
+		Revision:   revision,
+		Generation: api.Generation,
+		Timestamp:  w.now().UTC().Truncate(time.Second),
+		Spec:       rawSpec,
+	}))
+
+	if err = writeSpecSnapshots(cm, snapshots); err != nil {
+		return fmt.Errorf("write snapshots: %w", err)
+	}
+
+	if !exists {
+		if _, err = w.kubeClientSet.CoreV1().ConfigMaps(w.namespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create snapshots ConfigMap: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, err = w.kubeClientSet.CoreV1().ConfigMaps(w.namespace).Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update snapshots ConfigMap: %w", err)
+	}
+
+	return nil
+}
+
+// trimSpecSnapshots drops the oldest snapshots to stay within maxSpecSnapshots and maxSpecSnapshotsSize.
+// The newest snapshot is always kept.
+func trimSpecSnapshots(snapshots []SpecSnapshot) []SpecSnapshot {
+	if len(snapshots) > maxSpecSnapshots {
+		snapshots = snapshots[len(snapshots)-maxSpecSnapshots:]
+	}
+
+	var size int
+	for _, snapshot := range snapshots {
+		size += len(snapshot.Spec)
+	}
+
+	for len(snapshots) > 1 && size > maxSpecSnapshotsSize {
+		size -= len(snapshots[0].Spec)
+		snapshots = snapshots[1:]
+	}
+
+	return snapshots
+}
+
+// cleanSnapshots deletes the snapshots ConfigMaps which are not in the given set.
+func (w *WatcherSnapshot) cleanSnapshots(ctx context.Context, keep map[string]struct{}) {
+	cms, err := w.kubeClientSet.CoreV1().ConfigMaps(w.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelSpecSnapshots + "=true",
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to list snapshots ConfigMaps")
+		return
+	}
+
+	for _, cm := range cms.Items {
+		if _, ok := keep[cm.Name]; ok {
+			continue
+		}
+
+		err = w.kubeClientSet.CoreV1().ConfigMaps(w.namespace).Delete(ctx, cm.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			log.Error().Err(err).
+				Str("name", cm.Name).
+				Msg("Unable to delete snapshots ConfigMap")
+		}
+	}
+}
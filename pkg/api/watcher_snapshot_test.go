@@ -0,0 +1,146 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWatcherSnapshot_syncSnapshots(t *testing.T) {
+	spec := `{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.0.0"}, "paths": {}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(spec))
+	}))
+	t.Cleanup(srv.Close)
+
+	kubeClientSet := kubemock.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SpecSnapshotsConfigMapName("removed", "default"),
+			Namespace: "agent-ns",
+			Labels:    map[string]string{LabelSpecSnapshots: "true"},
+		},
+	})
+	hubClientSet := hubkubemock.NewSimpleClientset(&hubv1alpha1.API{
+		ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "default", Generation: 1},
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: "/books",
+			Service: hubv1alpha1.APIService{
+				Name:        "books",
+				Port:        hubv1alpha1.APIServiceBackendPort{Number: 80},
+				OpenAPISpec: hubv1alpha1.OpenAPISpec{URL: srv.URL + "/openapi.json"},
+			},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 0)
+	hubInformer.Hub().V1alpha1().APIs().Informer()
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	w := NewWatcherSnapshot(kubeClientSet, hubInformer, "agent-ns", time.Minute)
+	w.now = func() time.Time { return now }
+
+	w.syncSnapshots(ctx)
+
+	_, err := kubeClientSet.CoreV1().ConfigMaps("agent-ns").Get(ctx, SpecSnapshotsConfigMapName("removed", "default"), metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+
+	// Syncing an unchanged spec doesn't take a new snapshot.
+	w.syncSnapshots(ctx)
+
+	oldSpec := spec
+	spec = `{"openapi": "3.0.0", "info": {"title": "Books", "version": "1.1.0"}, "paths": {}}`
+	now = now.Add(time.Hour)
+
+	w.syncSnapshots(ctx)
+
+	cm, err := kubeClientSet.CoreV1().ConfigMaps("agent-ns").Get(ctx, SpecSnapshotsConfigMapName("books", "default"), metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "true", cm.Labels[LabelSpecSnapshots])
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, indexer.Add(cm))
+
+	store := NewSpecSnapshotStore(corev1listers.NewConfigMapLister(indexer).ConfigMaps("agent-ns"))
+
+	got, err := store.Snapshots("books", "default")
+	require.NoError(t, err)
+
+	assert.Equal(t, []SpecSnapshot{
+		{
+			Revision:   SpecRevision([]byte(oldSpec)),
+			Generation: 1,
+			Timestamp:  time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC),
+			Spec:       []byte(oldSpec),
+		},
+		{
+			Revision:   SpecRevision([]byte(spec)),
+			Generation: 1,
+			Timestamp:  time.Date(2023, 5, 1, 11, 0, 0, 0, time.UTC),
+			Spec:       []byte(spec),
+		},
+	}, got)
+
+	got, err = store.Snapshots("unknown", "default")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestTrimSpecSnapshots(t *testing.T) {
+	var snapshots []SpecSnapshot
+	for i := 0; i < maxSpecSnapshots+2; i++ {
+		snapshots = append(snapshots, SpecSnapshot{Generation: int64(i), Spec: []byte("{}")})
+	}
+
+	got := trimSpecSnapshots(snapshots)
+	require.Len(t, got, maxSpecSnapshots)
+	assert.Equal(t, int64(2), got[0].Generation)
+
+	got = trimSpecSnapshots([]SpecSnapshot{
+		{Generation: 1, Spec: make([]byte, maxSpecSnapshotsSize/2)},
+		{Generation: 2, Spec: make([]byte, maxSpecSnapshotsSize/2)},
+		{Generation: 3, Spec: make([]byte, maxSpecSnapshotsSize/2)},
+	})
+	require.Len(t, got, 2)
+	assert.Equal(t, int64(2), got[0].Generation)
+
+	got = trimSpecSnapshots([]SpecSnapshot{{Generation: 1, Spec: make([]byte, maxSpecSnapshotsSize+1)}})
+	assert.Len(t, got, 1)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package capability probes, once at startup, the features of the cluster the subsystems of the agent depend on.
+package capability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/rs/zerolog/log"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
+	"golang.org/x/exp/slices"
+	netv1 "k8s.io/api/networking/v1"
+	netv1beta1 "k8s.io/api/networking/v1beta1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// minKubernetesVersion is the oldest Kubernetes version supported by the agent.
+const minKubernetesVersion = "1.14"
+
+// Capabilities are the features of the cluster the subsystems of the agent depend on.
+type Capabilities struct {
+	// KubernetesVersion is the git version of the Kubernetes API server.
+	KubernetesVersion string
+	// NetV1Ingresses reports whether Ingresses are watched through the networking.k8s.io/v1 API rather than the
+	// networking.k8s.io/v1beta1 one.
+	NetV1Ingresses bool
+	// NetV1IngressClasses reports whether IngressClasses are watched through the networking.k8s.io/v1 API.
+	NetV1IngressClasses bool
+	// NetV1Beta1IngressClasses reports whether IngressClasses are watched through the networking.k8s.io/v1beta1 API,
+	// the networking.k8s.io/v1 one not serving them.
+	NetV1Beta1IngressClasses bool
+	// TraefikMiddlewares reports whether the Traefik Middleware CRD is installed.
+	TraefikMiddlewares bool
+	// TraefikRoutes reports whether the Traefik IngressRoute, TraefikService and TLSOption CRDs are installed.
+	TraefikRoutes bool
+	// APIManagement reports whether the Hub API management CRDs are installed.
+	APIManagement bool
+	// OpenShiftRoutes reports whether the cluster serves the OpenShift Route API.
+	OpenShiftRoutes bool
+	// IngressControllers are the controllers of the IngressClasses of the cluster.
+	IngressControllers []string
+}
+
+// Probe probes the capabilities of the cluster. It fails when the Kubernetes version isn't supported by the agent.
+func Probe(ctx context.Context, clientSet clientset.Interface) (Capabilities, error) {
+	serverVersion, err := clientSet.Discovery().ServerVersion()
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("get server version: %w", err)
+	}
+
+	serverSemVer, err := version.NewSemver(serverVersion.GitVersion)
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("parse server version: %w", err)
+	}
+
+	if serverSemVer.Core().LessThan(version.Must(version.NewVersion(minKubernetesVersion))) {
+		return Capabilities{}, fmt.Errorf("unsupported version: %s", serverSemVer)
+	}
+
+	caps := Capabilities{KubernetesVersion: serverVersion.GitVersion}
+
+	if err = negotiateIngressAPIs(clientSet.Discovery(), &caps); err != nil {
+		return Capabilities{}, err
+	}
+
+	traefikKinds, err := kinds(clientSet.Discovery(), traefikv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("list Traefik resources: %w", err)
+	}
+	caps.TraefikMiddlewares = traefikKinds["Middleware"]
+	caps.TraefikRoutes = traefikKinds["IngressRoute"] && traefikKinds["TraefikService"] && traefikKinds["TLSOption"]
+
+	hubKinds, err := kinds(clientSet.Discovery(), hubv1alpha1.SchemeGroupVersion.String())
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("list Hub resources: %w", err)
+	}
+	caps.APIManagement = hubKinds["APIPortal"]
+
+	routeKinds, err := kinds(clientSet.Discovery(), openshift.RouteGroupVersion.String())
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("list OpenShift resources: %w", err)
+	}
+	caps.OpenShiftRoutes = routeKinds[openshift.RouteKind.Kind]
+
+	caps.IngressControllers, err = ingressControllers(ctx, clientSet, caps)
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("list ingress controllers: %w", err)
+	}
+
+	return caps, nil
+}
+
+// Disabled returns the subsystems disabled because a capability is missing, along with the reason why they are.
+func (c Capabilities) Disabled() map[string]string {
+	disabled := make(map[string]string)
+
+	if !c.TraefikMiddlewares {
+		reason := "the Traefik Middleware CustomResourceDefinition is not installed"
+		disabled["Traefik Ingress and IngressRoute ACPs"] = reason
+		disabled["API gateways"] = reason
+		disabled["Middleware drift detection"] = reason
+	}
+	if !c.TraefikRoutes {
+		disabled["IngressRoute and TraefikService topology"] = "the Traefik IngressRoute, TraefikService and TLSOption CustomResourceDefinitions are not installed"
+	}
+	if !c.APIManagement {
+		disabled["API management"] = "the Hub API management CustomResourceDefinitions are not installed"
+	}
+
+	return disabled
+}
+
+// Log logs a report of the capabilities and the subsystems disabled because of a missing one. The agent must be
+// restarted for the subsystems to be enabled once the missing capabilities are installed.
+func (c Capabilities) Log() {
+	disabled := c.Disabled()
+
+	subsystems := make([]string, 0, len(disabled))
+	for subsystem, reason := range disabled {
+		subsystems = append(subsystems, subsystem+": "+reason)
+	}
+	sort.Strings(subsystems)
+
+	log.Info().
+		Str("kubernetes_version", c.KubernetesVersion).
+		Bool("traefik_middlewares", c.TraefikMiddlewares).
+		Bool("traefik_routes", c.TraefikRoutes).
+		Bool("api_management", c.APIManagement).
+		Bool("openshift_routes", c.OpenShiftRoutes).
+		Strs("ingress_controllers", c.IngressControllers).
+		Strs("disabled", subsystems).
+		Msg("Cluster capabilities probed, restart the agent to enable the disabled subsystems once their requirements are installed")
+}
+
+// negotiateIngressAPIs selects the versions of the networking.k8s.io API through which Ingresses and IngressClasses
+// are watched, preferring networking.k8s.io/v1 when served. The versions are deduced from the Kubernetes version when
+// the networking.k8s.io API isn't discoverable.
+func negotiateIngressAPIs(client discovery.DiscoveryInterface, caps *Capabilities) error {
+	v1Kinds, err := kinds(client, netv1.SchemeGroupVersion.String())
+	if err != nil {
+		return fmt.Errorf("list networking v1 resources: %w", err)
+	}
+
+	v1beta1Kinds, err := kinds(client, netv1beta1.SchemeGroupVersion.String())
+	if err != nil {
+		return fmt.Errorf("list networking v1beta1 resources: %w", err)
+	}
+
+	if v1Kinds == nil && v1beta1Kinds == nil {
+		caps.NetV1Ingresses = kubevers.SupportsNetV1Ingresses(caps.KubernetesVersion)
+		caps.NetV1IngressClasses = kubevers.SupportsNetV1IngressClasses(caps.KubernetesVersion)
+		caps.NetV1Beta1IngressClasses = !caps.NetV1IngressClasses && kubevers.SupportsNetV1Beta1IngressClasses(caps.KubernetesVersion)
+		return nil
+	}
+
+	if !v1Kinds["Ingress"] && !v1beta1Kinds["Ingress"] {
+		return fmt.Errorf("unsupported cluster %s: Ingresses are served by neither the %s nor the %s API",
+			caps.KubernetesVersion, netv1.SchemeGroupVersion, netv1beta1.SchemeGroupVersion)
+	}
+
+	caps.NetV1Ingresses = v1Kinds["Ingress"]
+	caps.NetV1IngressClasses = v1Kinds["IngressClass"]
+	caps.NetV1Beta1IngressClasses = !caps.NetV1IngressClasses && v1beta1Kinds["IngressClass"]
+
+	return nil
+}
+
+// kinds returns the kinds of the resources served for the given group version, none if it isn't served.
+func kinds(client discovery.DiscoveryInterface, groupVersion string) (map[string]bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		if kerror.IsNotFound(err) ||
+			// Because the fake client doesn't return the right error type.
+			strings.HasSuffix(err.Error(), " not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	kinds := make(map[string]bool)
+	for _, resource := range resources.APIResources {
+		kinds[resource.Kind] = true
+	}
+
+	return kinds, nil
+}
+
+// ingressControllers returns the sorted controllers of the IngressClasses of the cluster.
+func ingressControllers(ctx context.Context, clientSet clientset.Interface, caps Capabilities) ([]string, error) {
+	var controllers []string
+	switch {
+	case caps.NetV1IngressClasses:
+		classes, err := clientSet.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, class := range classes.Items {
+			controllers = append(controllers, class.Spec.Controller)
+		}
+
+	case caps.NetV1Beta1IngressClasses:
+		classes, err := clientSet.NetworkingV1beta1().IngressClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, class := range classes.Items {
+			controllers = append(controllers, class.Spec.Controller)
+		}
+	}
+
+	sort.Strings(controllers)
+
+	return slices.Compact(controllers), nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package capability
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProbe_handlesUnsupportedVersions(t *testing.T) {
+	tests := []struct {
+		desc          string
+		serverVersion string
+		wantErr       assert.ErrorAssertionFunc
+	}{
+		{
+			desc:    "Empty",
+			wantErr: assert.Error,
+		},
+		{
+			desc:          "Malformed version",
+			serverVersion: "foobar",
+			wantErr:       assert.Error,
+		},
+		{
+			desc:          "Unsupported version",
+			serverVersion: "v1.13",
+			wantErr:       assert.Error,
+		},
+		{
+			desc:          "Supported version",
+			serverVersion: "v1.16",
+			wantErr:       assert.NoError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			kubeClient := kubemock.NewSimpleClientset()
+
+			fakeDiscovery, ok := kubeClient.Discovery().(*fakediscovery.FakeDiscovery)
+			require.True(t, ok, "couldn't convert Discovery() to *FakeDiscovery")
+
+			fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: test.serverVersion}
+
+			_, err := Probe(context.Background(), kubeClient)
+			test.wantErr(t, err)
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	kubeClient := kubemock.NewSimpleClientset(
+		&netv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "traefik"},
+			Spec:       netv1.IngressClassSpec{Controller: "traefik.io/ingress-controller"},
+		},
+		&netv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "traefik-internal"},
+			Spec:       netv1.IngressClassSpec{Controller: "traefik.io/ingress-controller"},
+		},
+		&netv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+			Spec:       netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+		},
+	)
+	kubeClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "traefik.containo.us/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Kind: "Middleware"},
+				{Kind: "IngressRoute"},
+				{Kind: "TraefikService"},
+			},
+		},
+		{
+			GroupVersion: "hub.traefik.io/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Kind: "AccessControlPolicy"},
+				{Kind: "APIPortal"},
+			},
+		},
+		{
+			GroupVersion: "route.openshift.io/v1",
+			APIResources: []metav1.APIResource{{Kind: "Route"}},
+		},
+	}
+
+	fakeDiscovery, ok := kubeClient.Discovery().(*fakediscovery.FakeDiscovery)
+	require.True(t, ok)
+	fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: "v1.22.1"}
+
+	got, err := Probe(context.Background(), kubeClient)
+	require.NoError(t, err)
+
+	assert.Equal(t, Capabilities{
+		KubernetesVersion:   "v1.22.1",
+		NetV1Ingresses:      true,
+		NetV1IngressClasses: true,
+		TraefikMiddlewares:  true,
+		TraefikRoutes:       false,
+		APIManagement:       true,
+		OpenShiftRoutes:     true,
+		IngressControllers:  []string{"k8s.io/ingress-nginx", "traefik.io/ingress-controller"},
+	}, got)
+
+	assert.Equal(t, map[string]string{
+		"IngressRoute and TraefikService topology": "the Traefik IngressRoute, TraefikService and TLSOption CustomResourceDefinitions are not installed",
+	}, got.Disabled())
+}
+
+func TestProbe_negotiatesIngressAPIs(t *testing.T) {
+	netV1 := &metav1.APIResourceList{
+		GroupVersion: "networking.k8s.io/v1",
+		APIResources: []metav1.APIResource{{Kind: "NetworkPolicy"}, {Kind: "Ingress"}, {Kind: "IngressClass"}},
+	}
+	netV1Beta1 := &metav1.APIResourceList{
+		GroupVersion: "networking.k8s.io/v1beta1",
+		APIResources: []metav1.APIResource{{Kind: "Ingress"}, {Kind: "IngressClass"}},
+	}
+
+	tests := []struct {
+		desc          string
+		serverVersion string
+		resources     []*metav1.APIResourceList
+		want          Capabilities
+		wantErr       string
+	}{
+		{
+			desc:          "networking v1beta1 Ingresses only",
+			serverVersion: "v1.16.15",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "networking.k8s.io/v1beta1",
+				APIResources: []metav1.APIResource{{Kind: "Ingress"}},
+			}},
+			want: Capabilities{KubernetesVersion: "v1.16.15"},
+		},
+		{
+			desc:          "networking v1beta1 Ingresses and IngressClasses",
+			serverVersion: "v1.18.20",
+			resources:     []*metav1.APIResourceList{netV1Beta1},
+			want:          Capabilities{KubernetesVersion: "v1.18.20", NetV1Beta1IngressClasses: true},
+		},
+		{
+			desc:          "networking v1 and v1beta1 Ingresses",
+			serverVersion: "v1.21.14",
+			resources:     []*metav1.APIResourceList{netV1, netV1Beta1},
+			want:          Capabilities{KubernetesVersion: "v1.21.14", NetV1Ingresses: true, NetV1IngressClasses: true},
+		},
+		{
+			desc:          "networking v1 Ingresses only",
+			serverVersion: "v1.25.16-eks-8cb36c9",
+			resources:     []*metav1.APIResourceList{netV1},
+			want:          Capabilities{KubernetesVersion: "v1.25.16-eks-8cb36c9", NetV1Ingresses: true, NetV1IngressClasses: true},
+		},
+		{
+			desc:          "networking v1 Ingresses on a recent cluster",
+			serverVersion: "v1.30.2+k3s1",
+			resources:     []*metav1.APIResourceList{netV1},
+			want:          Capabilities{KubernetesVersion: "v1.30.2+k3s1", NetV1Ingresses: true, NetV1IngressClasses: true},
+		},
+		{
+			desc:          "networking API not discoverable",
+			serverVersion: "v1.19.0-gke.1",
+			want:          Capabilities{KubernetesVersion: "v1.19.0-gke.1", NetV1Ingresses: true, NetV1IngressClasses: true},
+		},
+		{
+			desc:          "Ingresses not served",
+			serverVersion: "v1.29.0",
+			resources: []*metav1.APIResourceList{{
+				GroupVersion: "networking.k8s.io/v1",
+				APIResources: []metav1.APIResource{{Kind: "NetworkPolicy"}},
+			}},
+			wantErr: "Ingresses are served by neither the networking.k8s.io/v1 nor the networking.k8s.io/v1beta1 API",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			kubeClient := kubemock.NewSimpleClientset()
+			kubeClient.Resources = test.resources
+
+			fakeDiscovery, ok := kubeClient.Discovery().(*fakediscovery.FakeDiscovery)
+			require.True(t, ok)
+			fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: test.serverVersion}
+
+			got, err := Probe(context.Background(), kubeClient)
+			if test.wantErr != "" {
+				assert.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCapabilities_Disabled(t *testing.T) {
+	caps := Capabilities{KubernetesVersion: "v1.22.1"}
+
+	assert.Equal(t, []string{
+		"API gateways",
+		"API management",
+		"IngressRoute and TraefikService topology",
+		"Middleware drift detection",
+		"Traefik Ingress and IngressRoute ACPs",
+	}, keys(caps.Disabled()))
+}
+
+func keys(m map[string]string) []string {
+	var res []string
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+
+	return res
+}
@@ -53,6 +53,21 @@ type AccessControlPolicySpec struct {
 	OIDC       *AccessControlPolicyOIDC       `json:"oidc,omitempty"`
 	OIDCGoogle *AccessControlPolicyOIDCGoogle `json:"oidcGoogle,omitempty"`
 	OAuthIntro *AccessControlOAuthIntro       `json:"oAuthIntro,omitempty"`
+	GeoIP      *AccessControlPolicyGeoIP      `json:"geoIp,omitempty"`
+
+	// ForwardAuth configures the Traefik ForwardAuth middlewares calling the policy.
+	// +optional
+	ForwardAuth *AccessControlPolicyForwardAuth `json:"forwardAuth,omitempty"`
+
+	// DenyAll makes the policy reject all requests, whatever their credentials.
+	// It is an emergency cutoff for the routes using the policy which doesn't require removing their annotations.
+	// +optional
+	DenyAll *AccessControlPolicyDenyAll `json:"denyAll,omitempty"`
+
+	// ClientIP configures how the IP of the clients is extracted from the X-Forwarded-For header, in access logs and
+	// authorization rules. It should be set when several proxies are in front of the ingress controller.
+	// +optional
+	ClientIP *AccessControlPolicyClientIP `json:"clientIp,omitempty"`
 }
 
 // Hash return AccessControlPolicySpec hash.
@@ -68,6 +83,56 @@ func (a AccessControlPolicySpec) Hash() (string, error) {
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
+// AccessControlPolicyForwardAuth configures the Traefik ForwardAuth middlewares calling an access control policy.
+type AccessControlPolicyForwardAuth struct {
+	// TrustForwardHeader makes the middlewares trust the X-Forwarded-* headers of the requests.
+	TrustForwardHeader bool `json:"trustForwardHeader,omitempty"`
+	// AuthRequestHeaders are the request headers forwarded to the policy. All headers are forwarded when empty.
+	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty"`
+	// TLS configures TLS communication with the agent.
+	// +optional
+	TLS *AccessControlPolicyForwardAuthTLS `json:"tls,omitempty"`
+}
+
+// AccessControlPolicyDenyAll configures the response sent to requests rejected by a deny all policy.
+type AccessControlPolicyDenyAll struct {
+	// StatusCode is the status code of the responses. Defaults to 503.
+	// +optional
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	StatusCode int `json:"statusCode,omitempty"`
+	// Message is the body of the responses.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AccessControlPolicyClientIP configures how the IP of the clients of an access control policy is extracted.
+// Depth, TrustedIPs and UseLeftmost are mutually exclusive. When none of them is set, the right-most IP of the
+// X-Forwarded-For header, appended by the ingress controller, is used.
+type AccessControlPolicyClientIP struct {
+	// TrustedIPs are the IPs and CIDRs of the proxies in front of the ingress controller. The client IP is the
+	// right-most IP of the X-Forwarded-For header which is not trusted.
+	// +optional
+	TrustedIPs []string `json:"trustedIps,omitempty"`
+	// Depth is the position of the client IP in the X-Forwarded-For header, starting from the right.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Depth int `json:"depth,omitempty"`
+	// UseLeftmost uses the left-most IP of the X-Forwarded-For header. Clients can forge it, so it must only be set
+	// when every proxy in front of the ingress controller overwrites the header.
+	// +optional
+	UseLeftmost bool `json:"useLeftmost,omitempty"`
+}
+
+// AccessControlPolicyForwardAuthTLS configures TLS communication between the ForwardAuth middlewares and the agent.
+// Secrets are looked up in the namespace of the middlewares, which is the one of the protected resources.
+type AccessControlPolicyForwardAuthTLS struct {
+	// CASecret is the name of the Secret holding the CA used to verify the certificate of the agent.
+	CASecret string `json:"caSecret,omitempty"`
+	// CertSecret is the name of the Secret holding the client certificate presented to the agent.
+	CertSecret string `json:"certSecret,omitempty"`
+}
+
 // AccessControlPolicyJWT configures a JWT access control policy.
 type AccessControlPolicyJWT struct {
 	SigningSecret              string            `json:"signingSecret,omitempty"`
@@ -79,14 +144,103 @@ type AccessControlPolicyJWT struct {
 	ForwardHeaders             map[string]string `json:"forwardHeaders,omitempty"`
 	TokenQueryKey              string            `json:"tokenQueryKey,omitempty"`
 	Claims                     string            `json:"claims,omitempty"`
+	// Issuers are the issuers whose JWTs are accepted, each one with its own keys. Keys must be configured on the
+	// issuers, instead of on the policy, when issuers are set.
+	// +optional
+	Issuers []AccessControlPolicyJWTIssuer `json:"issuers,omitempty"`
+	// Leeway is the tolerated clock skew between the issuers and the agent when validating the exp, nbf and iat
+	// claims.
+	// +optional
+	Leeway *metav1.Duration `json:"leeway,omitempty"`
+	// MaxTokenAge is the maximum age of the JWTs, computed from their iat claim, which is required when set.
+	// +optional
+	MaxTokenAge *metav1.Duration `json:"maxTokenAge,omitempty"`
+	// InternalToken configures the internal JWT minted once a JWT validated and forwarded to the backends in place
+	// of it, for backends to never see end-user tokens.
+	// +optional
+	InternalToken *AccessControlPolicyJWTInternalToken `json:"internalToken,omitempty"`
+	// +optional
+	Authorization *AccessControlPolicyAuthorization `json:"authorization,omitempty"`
+}
+
+// AccessControlPolicyJWTIssuer configures an issuer whose JWTs are accepted by a JWT access control policy.
+// JWTs are matched to their issuer using their iss claim.
+type AccessControlPolicyJWTIssuer struct {
+	// Issuer is the value of the iss claim of the JWTs of this issuer.
+	Issuer                     string `json:"issuer"`
+	SigningSecret              string `json:"signingSecret,omitempty"`
+	SigningSecretBase64Encoded bool   `json:"signingSecretBase64Encoded,omitempty"`
+	PublicKey                  string `json:"publicKey,omitempty"`
+	JWKsFile                   string `json:"jwksFile,omitempty"`
+	JWKsURL                    string `json:"jwksUrl,omitempty"`
+	// Audiences are the audiences JWTs must be issued for. At least one of them must be in the aud claim.
+	// The audience isn't checked when empty.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+	// ClaimMappings maps claim names to the claims of the JWTs of this issuer holding them, nested claims being
+	// addressed using dots. Mapped claims can be used in the claims, authorization and forward headers of the policy
+	// whatever the issuer.
+	// +optional
+	ClaimMappings map[string]string `json:"claimMappings,omitempty"`
+}
+
+// AccessControlPolicyJWTInternalToken configures the internal JWT minted by a JWT access control policy.
+type AccessControlPolicyJWTInternalToken struct {
+	// SigningSecret is the secret used to sign internal JWTs with HS256. Either it or a private key must be set.
+	// +optional
+	SigningSecret string `json:"signingSecret,omitempty"`
+	// +optional
+	SigningSecretBase64Encoded bool `json:"signingSecretBase64Encoded,omitempty"`
+	// PrivateKey is the PEM encoded RSA or ECDSA private key used to sign internal JWTs.
+	// +optional
+	PrivateKey string `json:"privateKey,omitempty"`
+	// KeyID is set as the kid header of internal JWTs.
+	// +optional
+	KeyID string `json:"keyId,omitempty"`
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+	// +optional
+	Audience string `json:"audience,omitempty"`
+	// TTL is the lifetime of internal JWTs, which never outlive the JWT they are minted from. Defaults to 5m.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+	// Claims maps claim names of internal JWTs to the claims of the validated JWT holding their value, nested claims
+	// being addressed using dots. The sub claim is always copied.
+	// +optional
+	Claims map[string]string `json:"claims,omitempty"`
+	// Header is the header internal JWTs are forwarded in. Defaults to the Authorization header, using the Bearer
+	// scheme. The Authorization header is always stripped.
+	// +optional
+	Header string `json:"header,omitempty"`
 }
 
 // AccessControlPolicyBasicAuth holds the HTTP basic authentication configuration.
 type AccessControlPolicyBasicAuth struct {
-	Users                    []string `json:"users,omitempty"`
-	Realm                    string   `json:"realm,omitempty"`
-	StripAuthorizationHeader bool     `json:"stripAuthorizationHeader,omitempty"`
-	ForwardUsernameHeader    string   `json:"forwardUsernameHeader,omitempty"`
+	Users []string `json:"users,omitempty"`
+	// UsersSecret references a Secret holding users, in the same format as Users, one per line under its users key.
+	// +optional
+	UsersSecret *corev1.SecretReference `json:"usersSecret,omitempty"`
+	// UserLookup configures an external HTTP service users not found in Users and the UsersSecret are authenticated
+	// against.
+	// +optional
+	UserLookup               *AccessControlPolicyBasicAuthUserLookup `json:"userLookup,omitempty"`
+	Realm                    string                                  `json:"realm,omitempty"`
+	StripAuthorizationHeader bool                                    `json:"stripAuthorizationHeader,omitempty"`
+	ForwardUsernameHeader    string                                  `json:"forwardUsernameHeader,omitempty"`
+}
+
+// AccessControlPolicyBasicAuthUserLookup configures an external HTTP service users are authenticated against.
+type AccessControlPolicyBasicAuthUserLookup struct {
+	// URL is the URL credentials are posted to, as a JSON object with a username and a password field. The service
+	// must reply with a 200 when the credentials are valid, a 401 or a 403 when they are not, and a 404 when the user
+	// is unknown.
+	URL string `json:"url"`
+	// CacheTTL is how long lookup results are cached. Defaults to 1m.
+	// +optional
+	CacheTTL *metav1.Duration `json:"cacheTtl,omitempty"`
+	// Timeout is the timeout of lookups. Defaults to 5s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // AccessControlPolicyAPIKey configure an APIKey control policy.
@@ -130,6 +284,8 @@ type AccessControlPolicyOIDC struct {
 	Scopes         []string          `json:"scopes,omitempty"`
 	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
 	Claims         string            `json:"claims,omitempty"`
+	// +optional
+	Authorization *AccessControlPolicyAuthorization `json:"authorization,omitempty"`
 }
 
 // AccessControlPolicyOIDCGoogle holds the Google OIDC authentication configuration.
@@ -149,6 +305,56 @@ type AccessControlPolicyOIDCGoogle struct {
 	// Emails are the allowed emails to connect.
 	// +kubebuilder:validation:MinItems:=1
 	Emails []string `json:"emails,omitempty"`
+	// +optional
+	Authorization *AccessControlPolicyAuthorization `json:"authorization,omitempty"`
+}
+
+// AccessControlPolicyGeoIP allows or denies requests based on the country and the autonomous system of their client
+// IP, looked up in MaxMind DB databases. Requests must not be denied and must match every allow list set. Requests
+// whose location is unknown are only allowed when no allow list is set.
+type AccessControlPolicyGeoIP struct {
+	// Databases are the MaxMind DB databases the client IPs are looked up in, using the GeoIP2 Country and ASN schemas.
+	// Each field is read from the first database holding it, which allows combining Country and ASN databases.
+	// +kubebuilder:validation:MinItems:=1
+	Databases []AccessControlPolicyGeoIPDatabase `json:"databases"`
+	// AllowedCountries are the ISO 3166-1 alpha-2 codes of the countries allowed.
+	// +optional
+	AllowedCountries []string `json:"allowedCountries,omitempty"`
+	// DeniedCountries are the ISO 3166-1 alpha-2 codes of the countries denied.
+	// +optional
+	DeniedCountries []string `json:"deniedCountries,omitempty"`
+	// AllowedASNs are the numbers of the autonomous systems allowed.
+	// +optional
+	AllowedASNs []uint `json:"allowedAsns,omitempty"`
+	// DeniedASNs are the numbers of the autonomous systems denied.
+	// +optional
+	DeniedASNs []uint `json:"deniedAsns,omitempty"`
+	// ForwardHeaders are the headers the location of allowed requests is forwarded in, indexed by header name.
+	// Supported values are "country", "asn" and "asOrganization".
+	// +optional
+	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
+}
+
+// AccessControlPolicyGeoIPDatabase references a MaxMind DB database. Exactly one of Path or Secret must be set.
+type AccessControlPolicyGeoIPDatabase struct {
+	// Path is the path of the database on the agent file system.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Secret references a Secret holding the database under its database key.
+	// Secrets are limited to 1MiB, larger databases must be mounted on the agent.
+	// +optional
+	Secret *corev1.SecretReference `json:"secret,omitempty"`
+}
+
+// AccessControlPolicyAuthorization configures the authorization stage of an access control policy.
+// It is evaluated once the request has been authenticated.
+type AccessControlPolicyAuthorization struct {
+	// Rules are CEL expressions evaluated over the `claims`, `headers`, `method`, `path` and `clientIp`
+	// of the request.
+	// The request is allowed as soon as one of the rules evaluates to true. For example:
+	//     method == "GET" && "developers" in claims.groups
+	// +kubebuilder:validation:MinItems:=1
+	Rules []string `json:"rules,omitempty"`
 }
 
 // StateCookie holds state cookie configuration.
@@ -176,6 +382,8 @@ type AccessControlOAuthIntro struct {
 	TokenSource    TokenSource       `json:"tokenSource"`
 	Claims         string            `json:"claims,omitempty"`
 	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
+	// +optional
+	Authorization *AccessControlPolicyAuthorization `json:"authorization,omitempty"`
 }
 
 // AccessControlOAuthIntroClientConfig configures the OAuth 2.0 client for issuing token introspection requests.
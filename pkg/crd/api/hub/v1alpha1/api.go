@@ -23,6 +23,7 @@ import (
 
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:storageversion
 
 // API defines an API exposed within a portal.
 // +kubebuilder:printcolumn:name="PathPrefix",type=string,JSONPath=`.spec.pathPrefix`
@@ -44,6 +45,34 @@ type API struct {
 type APISpec struct {
 	PathPrefix string     `json:"pathPrefix"`
 	Service    APIService `json:"service"`
+	// Versions are the versions of the API published side by side.
+	// +optional
+	Versions []APIVersion `json:"versions,omitempty"`
+	// TermsOfService are the terms of service consumers must accept before getting access to the API.
+	// +optional
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
+}
+
+// TermsOfService references a terms of service document.
+type TermsOfService struct {
+	// URL is the URL of the document.
+	URL string `json:"url"`
+	// Version is the version of the document. Consumers must accept each new version.
+	Version string `json:"version"`
+}
+
+// APIVersion is a version of an API. It is reachable under the API path prefix and exposes its own OpenAPI spec.
+type APIVersion struct {
+	// Name is the name of the version (e.g. v1).
+	Name string `json:"name"`
+	// PathPrefix is the path prefix of the version, relative to the API path prefix.
+	PathPrefix string `json:"pathPrefix"`
+	// OpenAPISpec is the OpenAPI spec of the version. If not set, the API one is used.
+	// +optional
+	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty"`
+	// Deprecated marks the version as deprecated.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
 }
 
 // APIService configures the service to exposed on the edge.
@@ -51,8 +80,23 @@ type APIService struct {
 	Name string `json:"name"`
 	// port of the referenced service. A port name or port number
 	// is required for an APIServiceBackendPort.
-	Port        APIServiceBackendPort `json:"port"`
-	OpenAPISpec OpenAPISpec           `json:"openApiSpec,omitempty"`
+	Port APIServiceBackendPort `json:"port"`
+	// Weight is the share of the traffic sent to this service when Weighted services are defined.
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+	// Weighted are additional services among which the API traffic is load-balanced according to their weight.
+	// It enables progressive rollouts, like canary releases, of a published API.
+	// +optional
+	Weighted    []APIWeightedService `json:"weighted,omitempty"`
+	OpenAPISpec OpenAPISpec          `json:"openApiSpec,omitempty"`
+}
+
+// APIWeightedService is a service receiving a weighted share of the API traffic.
+type APIWeightedService struct {
+	Name string                `json:"name"`
+	Port APIServiceBackendPort `json:"port"`
+	// +kubebuilder:validation:Minimum=0
+	Weight int `json:"weight"`
 }
 
 // APIServiceBackendPort is the service port being referenced.
@@ -69,7 +113,8 @@ type APIServiceBackendPort struct {
 	Number int32 `json:"number"`
 }
 
-// OpenAPISpec defines the OpenAPI spec of an API.
+// OpenAPISpec defines the OpenAPI spec of an API. When neither a URL, a path nor a ConfigMap is given, the spec is
+// discovered on well-known paths of the API service.
 type OpenAPISpec struct {
 	// +optional
 	URL string `json:"url,omitempty"`
@@ -79,6 +124,46 @@ type OpenAPISpec struct {
 	Port *APIServiceBackendPort `json:"port,omitempty"`
 	// +optional
 	Protocol string `json:"protocol,omitempty"`
+	// ConfigMapRef references the key of a ConfigMap, in the namespace of the API, holding the OpenAPI spec.
+	// +optional
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty"`
+	// Fallbacks are alternative sources of the OpenAPI spec, tried in order when it can't be fetched from the
+	// previous ones.
+	// +optional
+	Fallbacks []OpenAPISpecSource `json:"fallbacks,omitempty"`
+}
+
+// Sources returns the sources of the OpenAPI spec, in the order they must be tried.
+func (s OpenAPISpec) Sources() []OpenAPISpecSource {
+	sources := []OpenAPISpecSource{{
+		URL:          s.URL,
+		Path:         s.Path,
+		Port:         s.Port,
+		Protocol:     s.Protocol,
+		ConfigMapRef: s.ConfigMapRef,
+	}}
+
+	return append(sources, s.Fallbacks...)
+}
+
+// OpenAPISpecSource is a source from which an OpenAPI spec can be fetched.
+type OpenAPISpecSource struct {
+	// +optional
+	URL string `json:"url,omitempty"`
+	// +optional
+	Path string `json:"path,omitempty"`
+	// +optional
+	Port *APIServiceBackendPort `json:"port,omitempty"`
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+	// +optional
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty"`
+}
+
+// OpenAPISpecConfigMapRef references the key of a ConfigMap holding an OpenAPI spec.
+type OpenAPISpecConfigMapRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 // APIStatus is the status of an API.
@@ -87,8 +172,23 @@ type APIStatus struct {
 	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
 	// Hash is a hash representing the API.
 	Hash string `json:"hash,omitempty"`
+	// Conditions are the latest observations of the API state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// API condition types.
+const (
+	// APIConditionSpecFetchable indicates whether the OpenAPI spec of the API can be fetched.
+	APIConditionSpecFetchable = "SpecFetchable"
+	// APIConditionServiceResolvable indicates whether the services referenced by the API exist and expose the referenced ports.
+	APIConditionServiceResolvable = "ServiceResolvable"
+	// APIConditionSpecCompliant indicates whether the OpenAPI spec of the API complies with the lint ruleset.
+	APIConditionSpecCompliant = "SpecCompliant"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // APIList defines a list of APIs.
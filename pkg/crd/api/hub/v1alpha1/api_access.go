@@ -25,7 +25,7 @@ import (
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
-// APIAccess defines which group of consumers can access APIs and APICollections.
+// APIAccess defines which groups of consumers and which users can access APIs and APICollections.
 // +kubebuilder:resource:scope=Cluster
 type APIAccess struct {
 	metav1.TypeMeta `json:",inline"`
@@ -41,7 +41,10 @@ type APIAccess struct {
 
 // APIAccessSpec configures an APIAccess.
 type APIAccessSpec struct {
-	Groups                []string              `json:"groups"`
+	Groups []string `json:"groups"`
+	// Users are the emails of the users granted access, in addition to the members of the groups.
+	// +optional
+	Users                 []string              `json:"users,omitempty"`
 	APISelector           *metav1.LabelSelector `json:"apiSelector,omitempty"`
 	APICollectionSelector *metav1.LabelSelector `json:"apiCollectionSelector,omitempty"`
 }
@@ -24,6 +24,7 @@ import (
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:storageversion
 
 // APICollection defines a collection of APIs exposed within an APIPortal.
 // +kubebuilder:printcolumn:name="PathPrefix",type=string,JSONPath=`.spec.pathPrefix`
@@ -50,6 +51,10 @@ type APICollectionSpec struct {
 	// This field is NOT optional and follows standard label selector semantics.
 	// An empty APISelector matches any API.
 	APISelector metav1.LabelSelector `json:"apiSelector"`
+	// TermsOfService are the terms of service consumers must accept before getting access to the APIs of the
+	// collection. They take precedence over the terms of service of the APIs.
+	// +optional
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
 }
 
 // APICollectionStatus is the status of an APICollection.
@@ -24,6 +24,7 @@ import (
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:storageversion
 
 // APIGateway defines a gateway that exposes APIs.
 // +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`
@@ -48,8 +49,75 @@ type APIGatewaySpec struct {
 	// CustomDomains are the custom domains under which the gateway will be exposed.
 	// +optional
 	CustomDomains []string `json:"customDomains,omitempty"`
+	// CredentialLocations are the locations from which consumers are allowed to send their credentials: "header" for
+	// the Authorization header and "query" for the api_key query parameter. Credentials sent in the query may leak
+	// into access logs. All locations are allowed when not set.
+	// +optional
+	// +kubebuilder:validation:items:Enum=header;query
+	CredentialLocations []string `json:"credentialLocations,omitempty"`
+	// ExampleCapture enables the capture of anonymized request/response pairs, exposed as examples on the portals.
+	// Examples are not captured when not set.
+	// +optional
+	ExampleCapture *APIGatewayExampleCapture `json:"exampleCapture,omitempty"`
+	// IdentityHeaders configures the names of the headers holding the identity of the consumers, forwarded to the
+	// APIs once their API token validated, for APIs with existing header conventions to rely on them.
+	// +optional
+	IdentityHeaders *APIGatewayIdentityHeaders `json:"identityHeaders,omitempty"`
 }
 
+// APIGatewayIdentityHeaders configures the names of the headers holding the identity of the consumers of an
+// APIGateway.
+type APIGatewayIdentityHeaders struct {
+	// User is the header holding the user of the consumer, identified by their email. It isn't forwarded when not
+	// set.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	User string `json:"user,omitempty"`
+	// Email is the header holding the email of the consumer. Defaults to Hub-Email.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	Email string `json:"email,omitempty"`
+	// Groups is the header holding the comma separated groups of the consumer. Defaults to Hub-Groups.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	Groups string `json:"groups,omitempty"`
+}
+
+// APIGatewayExampleCapture configures the capture of request/response examples by an APIGateway. Credentials, cookies
+// and common secret fields are always redacted from the captured examples.
+type APIGatewayExampleCapture struct {
+	// SampleRate is the percentage of the requests captured. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SampleRate int `json:"sampleRate,omitempty"`
+	// MaxExamplesPerOperation is the number of examples kept for each operation, older ones being discarded.
+	// Defaults to 5.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	MaxExamplesPerOperation int `json:"maxExamplesPerOperation,omitempty"`
+	// MaxBodySize is the size, in bytes, above which captured bodies are truncated. Defaults to 4096.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65536
+	MaxBodySize *int `json:"maxBodySize,omitempty"`
+	// RedactedHeaders are additional headers whose values are redacted from the captured examples.
+	// +optional
+	RedactedHeaders []string `json:"redactedHeaders,omitempty"`
+	// RedactedFields are additional JSON body fields whose values are redacted from the captured examples.
+	// +optional
+	RedactedFields []string `json:"redactedFields,omitempty"`
+}
+
+// Credential locations of an APIGateway.
+const (
+	// CredentialLocationHeader allows consumers to send their credentials in the Authorization header.
+	CredentialLocationHeader = "header"
+	// CredentialLocationQuery allows consumers to send their credentials in the api_key query parameter.
+	CredentialLocationQuery = "query"
+)
+
 // APIGatewayStatus is the status of an APIGateway.
 type APIGatewayStatus struct {
 	Version  string      `json:"version,omitempty"`
@@ -68,8 +136,22 @@ type APIGatewayStatus struct {
 
 	// Hash is a hash representing the APIPortal.
 	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APIGateway state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// APIGateway condition types.
+const (
+	// APIGatewayConditionDNSReady indicates whether all the domains of the APIGateway are assigned and verified.
+	APIGatewayConditionDNSReady = "DNSReady"
+	// APIGatewayConditionCertificateReady indicates whether the certificates of the APIGateway domains are provisioned.
+	APIGatewayConditionCertificateReady = "CertificateReady"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // APIGatewayList defines a list of APIGateway.
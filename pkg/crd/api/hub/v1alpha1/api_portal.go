@@ -51,6 +51,45 @@ type APIPortalSpec struct {
 	// CustomDomains are the custom domains under which the portal will be exposed.
 	// +optional
 	CustomDomains []string `json:"customDomains,omitempty"`
+	// Theme customizes the look of the portal WebUI.
+	// +optional
+	Theme *APIPortalTheme `json:"theme,omitempty"`
+	// Webhook is notified of the portal events.
+	// +optional
+	Webhook *APIPortalWebhook `json:"webhook,omitempty"`
+}
+
+// APIPortalTheme customizes the look of an APIPortal WebUI.
+type APIPortalTheme struct {
+	// LogoURL is the URL of the logo displayed in the portal header.
+	// +optional
+	LogoURL string `json:"logoUrl,omitempty"`
+	// PrimaryColor is the main color of the portal, as an hexadecimal RGB color.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`
+	PrimaryColor string `json:"primaryColor,omitempty"`
+	// AccentColor is the color used to highlight elements of the portal, as an hexadecimal RGB color.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`
+	AccentColor string `json:"accentColor,omitempty"`
+}
+
+// APIPortalWebhook configures the webhook notified of the events of an APIPortal.
+type APIPortalWebhook struct {
+	// URL is the URL to which the events are posted.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// SecretRef references the key of the Secret, in the agent namespace, holding the secret used to sign the events
+	// using HMAC-SHA256. The signature is sent in the X-Hub-Signature-256 header.
+	// +optional
+	SecretRef *APIPortalWebhookSecretRef `json:"secretRef,omitempty"`
+}
+
+// APIPortalWebhookSecretRef references the key of a Secret, in the agent namespace, holding the secret used to sign
+// the events of an APIPortal.
+type APIPortalWebhookSecretRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 // APIPortalStatus is the status of an APIPortal.
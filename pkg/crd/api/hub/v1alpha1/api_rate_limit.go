@@ -0,0 +1,74 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIRateLimit defines a quota applied to each consumer of a set of APIs.
+// +kubebuilder:resource:scope=Cluster
+type APIRateLimit struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec APIRateLimitSpec `json:"spec,omitempty"`
+}
+
+// APIRateLimitSpec configures an APIRateLimit.
+type APIRateLimitSpec struct {
+	// Groups are the consumer groups the quota applies to. The quota applies to every consumer when empty.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+	// APISelector selects the APIs the quota applies to.
+	APISelector *metav1.LabelSelector `json:"apiSelector,omitempty"`
+	// Limit is the number of requests a consumer can send to each API during a period.
+	// +kubebuilder:validation:Minimum=1
+	Limit int `json:"limit"`
+	// Period is the duration of the windows the quota is reset at the end of. Defaults to 1s.
+	// +optional
+	Period *metav1.Duration `json:"period,omitempty"`
+}
+
+// PeriodDuration returns the duration of the quota windows.
+func (s APIRateLimitSpec) PeriodDuration() time.Duration {
+	if s.Period == nil || s.Period.Duration <= 0 {
+		return time.Second
+	}
+
+	return s.Period.Duration
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIRateLimitList defines a list of APIRateLimits.
+type APIRateLimitList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []APIRateLimit `json:"items"`
+}
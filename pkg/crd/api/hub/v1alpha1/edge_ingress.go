@@ -33,6 +33,7 @@ import (
 // +kubebuilder:printcolumn:name="Service",type=string,JSONPath=`.spec.service.name`
 // +kubebuilder:printcolumn:name="Port",type=string,JSONPath=`.spec.service.port`
 // +kubebuilder:printcolumn:name="ACP",type=string,JSONPath=`.spec.acp.name`,priority=1
+// +kubebuilder:printcolumn:name="Active",type=string,JSONPath=`.spec.blueGreen.active`,priority=1
 // +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`
 // +kubebuilder:printcolumn:name="Connection",type=string,JSONPath=`.status.connection`
 type EdgeIngress struct {
@@ -54,6 +55,32 @@ type EdgeIngressSpec struct {
 	ACP     *EdgeIngressACP    `json:"acp,omitempty"`
 	// CustomDomains are the custom domains for accessing the exposed service.
 	CustomDomains []string `json:"customDomains,omitempty"`
+	// Mirror configures a service to which a percentage of the incoming traffic is copied.
+	// +optional
+	Mirror *EdgeIngressMirror `json:"mirror,omitempty"`
+	// Headers configures the headers injected in the requests forwarded to the service and in its responses.
+	// +optional
+	Headers *EdgeIngressHeaders `json:"headers,omitempty"`
+	// IPAllowList configures the source IPs allowed to reach the service.
+	// +optional
+	IPAllowList *EdgeIngressIPAllowList `json:"ipAllowList,omitempty"`
+	// BlueGreen configures two releases of the service between which the traffic can be switched. When set, the
+	// traffic is sent to the active release instead of the Service.
+	// +optional
+	BlueGreen *EdgeIngressBlueGreen `json:"blueGreen,omitempty"`
+}
+
+// ActiveService returns the service receiving the traffic of the edge ingress.
+func (in *EdgeIngressSpec) ActiveService() EdgeIngressService {
+	if in.BlueGreen == nil {
+		return in.Service
+	}
+
+	if in.BlueGreen.Active == BlueGreenGreen {
+		return in.BlueGreen.Green
+	}
+
+	return in.BlueGreen.Blue
 }
 
 // Hash generates the hash of the spec.
@@ -75,6 +102,52 @@ type EdgeIngressService struct {
 	Port int    `json:"port"`
 }
 
+// EdgeIngressMirror configures the service receiving a copy of the traffic.
+// Responses from the mirror are discarded.
+type EdgeIngressMirror struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+	// Percent is the percentage of requests copied to the mirror.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	Percent int `json:"percent"`
+}
+
+// BlueGreenRelease is a release of a blue/green deployment.
+type BlueGreenRelease string
+
+// Blue/green releases.
+const (
+	BlueGreenBlue  BlueGreenRelease = "blue"
+	BlueGreenGreen BlueGreenRelease = "green"
+)
+
+// EdgeIngressBlueGreen configures the two releases of a blue/green deployment.
+type EdgeIngressBlueGreen struct {
+	Blue  EdgeIngressService `json:"blue"`
+	Green EdgeIngressService `json:"green"`
+	// Active is the release receiving the traffic.
+	// +kubebuilder:validation:Enum=blue;green
+	Active BlueGreenRelease `json:"active"`
+}
+
+// EdgeIngressHeaders configures the headers injected by the edge ingress.
+type EdgeIngressHeaders struct {
+	// Request are the headers set on the requests forwarded to the service. An empty value removes the header.
+	// +optional
+	Request map[string]string `json:"request,omitempty"`
+	// Response are the headers set on the responses returned by the service. An empty value removes the header.
+	// +optional
+	Response map[string]string `json:"response,omitempty"`
+}
+
+// EdgeIngressIPAllowList configures the source IPs allowed to reach the edge ingress.
+type EdgeIngressIPAllowList struct {
+	// SourceRange is the list of IPs or CIDRs allowed to reach the service.
+	// +kubebuilder:validation:MinItems=1
+	SourceRange []string `json:"sourceRange"`
+}
+
 // EdgeIngressACP configures the ACP to use on the Ingress.
 type EdgeIngressACP struct {
 	Name string `json:"name"`
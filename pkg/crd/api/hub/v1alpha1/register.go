@@ -60,6 +60,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&APICollectionList{},
 		&APIAccess{},
 		&APIAccessList{},
+		&APIRateLimit{},
+		&APIRateLimitList{},
 	)
 
 	metav1.AddToGroupVersion(
@@ -124,6 +124,11 @@ func (in *APIAccessSpec) DeepCopyInto(out *APIAccessSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.APISelector != nil {
 		in, out := &in.APISelector, &out.APISelector
 		*out = new(v1.LabelSelector)
@@ -229,6 +234,11 @@ func (in *APICollectionList) DeepCopyObject() runtime.Object {
 func (in *APICollectionSpec) DeepCopyInto(out *APICollectionSpec) {
 	*out = *in
 	in.APISelector.DeepCopyInto(&out.APISelector)
+	if in.TermsOfService != nil {
+		in, out := &in.TermsOfService, &out.TermsOfService
+		*out = new(TermsOfService)
+		**out = **in
+	}
 	return
 }
 
@@ -287,6 +297,53 @@ func (in *APIGateway) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIGatewayExampleCapture) DeepCopyInto(out *APIGatewayExampleCapture) {
+	*out = *in
+	if in.MaxBodySize != nil {
+		in, out := &in.MaxBodySize, &out.MaxBodySize
+		*out = new(int)
+		**out = **in
+	}
+	if in.RedactedHeaders != nil {
+		in, out := &in.RedactedHeaders, &out.RedactedHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RedactedFields != nil {
+		in, out := &in.RedactedFields, &out.RedactedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIGatewayExampleCapture.
+func (in *APIGatewayExampleCapture) DeepCopy() *APIGatewayExampleCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(APIGatewayExampleCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIGatewayIdentityHeaders) DeepCopyInto(out *APIGatewayIdentityHeaders) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIGatewayIdentityHeaders.
+func (in *APIGatewayIdentityHeaders) DeepCopy() *APIGatewayIdentityHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(APIGatewayIdentityHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIGatewayList) DeepCopyInto(out *APIGatewayList) {
 	*out = *in
@@ -333,6 +390,21 @@ func (in *APIGatewaySpec) DeepCopyInto(out *APIGatewaySpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.CredentialLocations != nil {
+		in, out := &in.CredentialLocations, &out.CredentialLocations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExampleCapture != nil {
+		in, out := &in.ExampleCapture, &out.ExampleCapture
+		*out = new(APIGatewayExampleCapture)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdentityHeaders != nil {
+		in, out := &in.IdentityHeaders, &out.IdentityHeaders
+		*out = new(APIGatewayIdentityHeaders)
+		**out = **in
+	}
 	return
 }
 
@@ -355,6 +427,13 @@ func (in *APIGatewayStatus) DeepCopyInto(out *APIGatewayStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -470,6 +549,16 @@ func (in *APIPortalSpec) DeepCopyInto(out *APIPortalSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Theme != nil {
+		in, out := &in.Theme, &out.Theme
+		*out = new(APIPortalTheme)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(APIPortalWebhook)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -505,10 +594,164 @@ func (in *APIPortalStatus) DeepCopy() *APIPortalStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIPortalTheme) DeepCopyInto(out *APIPortalTheme) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIPortalTheme.
+func (in *APIPortalTheme) DeepCopy() *APIPortalTheme {
+	if in == nil {
+		return nil
+	}
+	out := new(APIPortalTheme)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIPortalWebhook) DeepCopyInto(out *APIPortalWebhook) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(APIPortalWebhookSecretRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIPortalWebhook.
+func (in *APIPortalWebhook) DeepCopy() *APIPortalWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(APIPortalWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIPortalWebhookSecretRef) DeepCopyInto(out *APIPortalWebhookSecretRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIPortalWebhookSecretRef.
+func (in *APIPortalWebhookSecretRef) DeepCopy() *APIPortalWebhookSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(APIPortalWebhookSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIRateLimit) DeepCopyInto(out *APIRateLimit) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimit.
+func (in *APIRateLimit) DeepCopy() *APIRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(APIRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIRateLimit) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIRateLimitList) DeepCopyInto(out *APIRateLimitList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]APIRateLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimitList.
+func (in *APIRateLimitList) DeepCopy() *APIRateLimitList {
+	if in == nil {
+		return nil
+	}
+	out := new(APIRateLimitList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *APIRateLimitList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIRateLimitSpec) DeepCopyInto(out *APIRateLimitSpec) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.APISelector != nil {
+		in, out := &in.APISelector, &out.APISelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIRateLimitSpec.
+func (in *APIRateLimitSpec) DeepCopy() *APIRateLimitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(APIRateLimitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIService) DeepCopyInto(out *APIService) {
 	*out = *in
 	out.Port = in.Port
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	if in.Weighted != nil {
+		in, out := &in.Weighted, &out.Weighted
+		*out = make([]APIWeightedService, len(*in))
+		copy(*out, *in)
+	}
 	in.OpenAPISpec.DeepCopyInto(&out.OpenAPISpec)
 	return
 }
@@ -543,6 +786,18 @@ func (in *APIServiceBackendPort) DeepCopy() *APIServiceBackendPort {
 func (in *APISpec) DeepCopyInto(out *APISpec) {
 	*out = *in
 	in.Service.DeepCopyInto(&out.Service)
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]APIVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TermsOfService != nil {
+		in, out := &in.TermsOfService, &out.TermsOfService
+		*out = new(TermsOfService)
+		**out = **in
+	}
 	return
 }
 
@@ -560,6 +815,13 @@ func (in *APISpec) DeepCopy() *APISpec {
 func (in *APIStatus) DeepCopyInto(out *APIStatus) {
 	*out = *in
 	in.SyncedAt.DeepCopyInto(&out.SyncedAt)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -573,6 +835,40 @@ func (in *APIStatus) DeepCopy() *APIStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIVersion) DeepCopyInto(out *APIVersion) {
+	*out = *in
+	in.OpenAPISpec.DeepCopyInto(&out.OpenAPISpec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIVersion.
+func (in *APIVersion) DeepCopy() *APIVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(APIVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIWeightedService) DeepCopyInto(out *APIWeightedService) {
+	*out = *in
+	out.Port = in.Port
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIWeightedService.
+func (in *APIWeightedService) DeepCopy() *APIWeightedService {
+	if in == nil {
+		return nil
+	}
+	out := new(APIWeightedService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AccessControlOAuthIntro) DeepCopyInto(out *AccessControlOAuthIntro) {
 	*out = *in
@@ -585,6 +881,11 @@ func (in *AccessControlOAuthIntro) DeepCopyInto(out *AccessControlOAuthIntro) {
 			(*out)[key] = val
 		}
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AccessControlPolicyAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -722,6 +1023,27 @@ func (in *AccessControlPolicyAPIKeyKey) DeepCopy() *AccessControlPolicyAPIKeyKey
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyAuthorization) DeepCopyInto(out *AccessControlPolicyAuthorization) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyAuthorization.
+func (in *AccessControlPolicyAuthorization) DeepCopy() *AccessControlPolicyAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AccessControlPolicyBasicAuth) DeepCopyInto(out *AccessControlPolicyBasicAuth) {
 	*out = *in
@@ -730,6 +1052,16 @@ func (in *AccessControlPolicyBasicAuth) DeepCopyInto(out *AccessControlPolicyBas
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.UsersSecret != nil {
+		in, out := &in.UsersSecret, &out.UsersSecret
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	if in.UserLookup != nil {
+		in, out := &in.UserLookup, &out.UserLookup
+		*out = new(AccessControlPolicyBasicAuthUserLookup)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -744,55 +1076,314 @@ func (in *AccessControlPolicyBasicAuth) DeepCopy() *AccessControlPolicyBasicAuth
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyJWT) DeepCopyInto(out *AccessControlPolicyJWT) {
+func (in *AccessControlPolicyBasicAuthUserLookup) DeepCopyInto(out *AccessControlPolicyBasicAuthUserLookup) {
 	*out = *in
-	if in.ForwardHeaders != nil {
-		in, out := &in.ForwardHeaders, &out.ForwardHeaders
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.CacheTTL != nil {
+		in, out := &in.CacheTTL, &out.CacheTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyJWT.
-func (in *AccessControlPolicyJWT) DeepCopy() *AccessControlPolicyJWT {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyBasicAuthUserLookup.
+func (in *AccessControlPolicyBasicAuthUserLookup) DeepCopy() *AccessControlPolicyBasicAuthUserLookup {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyJWT)
+	out := new(AccessControlPolicyBasicAuthUserLookup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *AccessControlPolicyList) DeepCopyInto(out *AccessControlPolicyList) {
+func (in *AccessControlPolicyClientIP) DeepCopyInto(out *AccessControlPolicyClientIP) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]AccessControlPolicy, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.TrustedIPs != nil {
+		in, out := &in.TrustedIPs, &out.TrustedIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyList.
-func (in *AccessControlPolicyList) DeepCopy() *AccessControlPolicyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyClientIP.
+func (in *AccessControlPolicyClientIP) DeepCopy() *AccessControlPolicyClientIP {
 	if in == nil {
 		return nil
 	}
-	out := new(AccessControlPolicyList)
+	out := new(AccessControlPolicyClientIP)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *AccessControlPolicyList) DeepCopyObject() runtime.Object {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyDenyAll) DeepCopyInto(out *AccessControlPolicyDenyAll) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyDenyAll.
+func (in *AccessControlPolicyDenyAll) DeepCopy() *AccessControlPolicyDenyAll {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyDenyAll)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyForwardAuth) DeepCopyInto(out *AccessControlPolicyForwardAuth) {
+	*out = *in
+	if in.AuthRequestHeaders != nil {
+		in, out := &in.AuthRequestHeaders, &out.AuthRequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(AccessControlPolicyForwardAuthTLS)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyForwardAuth.
+func (in *AccessControlPolicyForwardAuth) DeepCopy() *AccessControlPolicyForwardAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyForwardAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyForwardAuthTLS) DeepCopyInto(out *AccessControlPolicyForwardAuthTLS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyForwardAuthTLS.
+func (in *AccessControlPolicyForwardAuthTLS) DeepCopy() *AccessControlPolicyForwardAuthTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyForwardAuthTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyGeoIP) DeepCopyInto(out *AccessControlPolicyGeoIP) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]AccessControlPolicyGeoIPDatabase, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedCountries != nil {
+		in, out := &in.AllowedCountries, &out.AllowedCountries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedCountries != nil {
+		in, out := &in.DeniedCountries, &out.DeniedCountries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedASNs != nil {
+		in, out := &in.AllowedASNs, &out.AllowedASNs
+		*out = make([]uint, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedASNs != nil {
+		in, out := &in.DeniedASNs, &out.DeniedASNs
+		*out = make([]uint, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyGeoIP.
+func (in *AccessControlPolicyGeoIP) DeepCopy() *AccessControlPolicyGeoIP {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyGeoIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyGeoIPDatabase) DeepCopyInto(out *AccessControlPolicyGeoIPDatabase) {
+	*out = *in
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyGeoIPDatabase.
+func (in *AccessControlPolicyGeoIPDatabase) DeepCopy() *AccessControlPolicyGeoIPDatabase {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyGeoIPDatabase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyJWT) DeepCopyInto(out *AccessControlPolicyJWT) {
+	*out = *in
+	if in.ForwardHeaders != nil {
+		in, out := &in.ForwardHeaders, &out.ForwardHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Issuers != nil {
+		in, out := &in.Issuers, &out.Issuers
+		*out = make([]AccessControlPolicyJWTIssuer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Leeway != nil {
+		in, out := &in.Leeway, &out.Leeway
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxTokenAge != nil {
+		in, out := &in.MaxTokenAge, &out.MaxTokenAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.InternalToken != nil {
+		in, out := &in.InternalToken, &out.InternalToken
+		*out = new(AccessControlPolicyJWTInternalToken)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AccessControlPolicyAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyJWT.
+func (in *AccessControlPolicyJWT) DeepCopy() *AccessControlPolicyJWT {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyJWT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyJWTInternalToken) DeepCopyInto(out *AccessControlPolicyJWTInternalToken) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyJWTInternalToken.
+func (in *AccessControlPolicyJWTInternalToken) DeepCopy() *AccessControlPolicyJWTInternalToken {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyJWTInternalToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyJWTIssuer) DeepCopyInto(out *AccessControlPolicyJWTIssuer) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClaimMappings != nil {
+		in, out := &in.ClaimMappings, &out.ClaimMappings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyJWTIssuer.
+func (in *AccessControlPolicyJWTIssuer) DeepCopy() *AccessControlPolicyJWTIssuer {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyJWTIssuer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AccessControlPolicyList) DeepCopyInto(out *AccessControlPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AccessControlPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AccessControlPolicyList.
+func (in *AccessControlPolicyList) DeepCopy() *AccessControlPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(AccessControlPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AccessControlPolicyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -836,6 +1427,11 @@ func (in *AccessControlPolicyOIDC) DeepCopyInto(out *AccessControlPolicyOIDC) {
 			(*out)[key] = val
 		}
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AccessControlPolicyAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -886,6 +1482,11 @@ func (in *AccessControlPolicyOIDCGoogle) DeepCopyInto(out *AccessControlPolicyOI
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(AccessControlPolicyAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -932,6 +1533,26 @@ func (in *AccessControlPolicySpec) DeepCopyInto(out *AccessControlPolicySpec) {
 		*out = new(AccessControlOAuthIntro)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GeoIP != nil {
+		in, out := &in.GeoIP, &out.GeoIP
+		*out = new(AccessControlPolicyGeoIP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForwardAuth != nil {
+		in, out := &in.ForwardAuth, &out.ForwardAuth
+		*out = new(AccessControlPolicyForwardAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DenyAll != nil {
+		in, out := &in.DenyAll, &out.DenyAll
+		*out = new(AccessControlPolicyDenyAll)
+		**out = **in
+	}
+	if in.ClientIP != nil {
+		in, out := &in.ClientIP, &out.ClientIP
+		*out = new(AccessControlPolicyClientIP)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1006,6 +1627,75 @@ func (in *EdgeIngressACP) DeepCopy() *EdgeIngressACP {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressBlueGreen) DeepCopyInto(out *EdgeIngressBlueGreen) {
+	*out = *in
+	out.Blue = in.Blue
+	out.Green = in.Green
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressBlueGreen.
+func (in *EdgeIngressBlueGreen) DeepCopy() *EdgeIngressBlueGreen {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressBlueGreen)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressHeaders) DeepCopyInto(out *EdgeIngressHeaders) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressHeaders.
+func (in *EdgeIngressHeaders) DeepCopy() *EdgeIngressHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressIPAllowList) DeepCopyInto(out *EdgeIngressIPAllowList) {
+	*out = *in
+	if in.SourceRange != nil {
+		in, out := &in.SourceRange, &out.SourceRange
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressIPAllowList.
+func (in *EdgeIngressIPAllowList) DeepCopy() *EdgeIngressIPAllowList {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressIPAllowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgeIngressList) DeepCopyInto(out *EdgeIngressList) {
 	*out = *in
@@ -1039,6 +1729,22 @@ func (in *EdgeIngressList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EdgeIngressMirror) DeepCopyInto(out *EdgeIngressMirror) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EdgeIngressMirror.
+func (in *EdgeIngressMirror) DeepCopy() *EdgeIngressMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(EdgeIngressMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EdgeIngressService) DeepCopyInto(out *EdgeIngressService) {
 	*out = *in
@@ -1069,6 +1775,26 @@ func (in *EdgeIngressSpec) DeepCopyInto(out *EdgeIngressSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Mirror != nil {
+		in, out := &in.Mirror, &out.Mirror
+		*out = new(EdgeIngressMirror)
+		**out = **in
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(EdgeIngressHeaders)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPAllowList != nil {
+		in, out := &in.IPAllowList, &out.IPAllowList
+		*out = new(EdgeIngressIPAllowList)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(EdgeIngressBlueGreen)
+		**out = **in
+	}
 	return
 }
 
@@ -1225,6 +1951,18 @@ func (in *OpenAPISpec) DeepCopyInto(out *OpenAPISpec) {
 		*out = new(APIServiceBackendPort)
 		**out = **in
 	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(OpenAPISpecConfigMapRef)
+		**out = **in
+	}
+	if in.Fallbacks != nil {
+		in, out := &in.Fallbacks, &out.Fallbacks
+		*out = make([]OpenAPISpecSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1238,6 +1976,48 @@ func (in *OpenAPISpec) DeepCopy() *OpenAPISpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenAPISpecConfigMapRef) DeepCopyInto(out *OpenAPISpecConfigMapRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenAPISpecConfigMapRef.
+func (in *OpenAPISpecConfigMapRef) DeepCopy() *OpenAPISpecConfigMapRef {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenAPISpecConfigMapRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpenAPISpecSource) DeepCopyInto(out *OpenAPISpecSource) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(APIServiceBackendPort)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(OpenAPISpecConfigMapRef)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenAPISpecSource.
+func (in *OpenAPISpecSource) DeepCopy() *OpenAPISpecSource {
+	if in == nil {
+		return nil
+	}
+	out := new(OpenAPISpecSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Session) DeepCopyInto(out *Session) {
 	*out = *in
@@ -1275,6 +2055,22 @@ func (in *StateCookie) DeepCopy() *StateCookie {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TermsOfService) DeepCopyInto(out *TermsOfService) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TermsOfService.
+func (in *TermsOfService) DeepCopy() *TermsOfService {
+	if in == nil {
+		return nil
+	}
+	out := new(TermsOfService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TokenSource) DeepCopyInto(out *TokenSource) {
 	*out = *in
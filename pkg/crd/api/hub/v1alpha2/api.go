@@ -0,0 +1,193 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// API defines an API exposed within a portal.
+// +kubebuilder:printcolumn:name="PathPrefix",type=string,JSONPath=`.spec.pathPrefix`
+// +kubebuilder:printcolumn:name="ServiceName",type=string,JSONPath=`.spec.service.name`
+// +kubebuilder:printcolumn:name="ServicePort",type=string,JSONPath=`.spec.service.port.number`
+type API struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec APISpec `json:"spec,omitempty"`
+
+	// The current status of this API.
+	// +optional
+	Status APIStatus `json:"status,omitempty"`
+}
+
+// APISpec configures an API.
+type APISpec struct {
+	PathPrefix string     `json:"pathPrefix"`
+	Service    APIService `json:"service"`
+	// OpenAPISpec defines where the OpenAPI spec of the API is served.
+	// It was previously part of the service configuration.
+	// +optional
+	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty"`
+	// Versions are the versions of the API published side by side.
+	// +optional
+	Versions []APIVersion `json:"versions,omitempty"`
+	// TermsOfService are the terms of service consumers must accept before getting access to the API.
+	// +optional
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
+}
+
+// TermsOfService references a terms of service document.
+type TermsOfService struct {
+	// URL is the URL of the document.
+	URL string `json:"url"`
+	// Version is the version of the document. Consumers must accept each new version.
+	Version string `json:"version"`
+}
+
+// APIVersion is a version of an API. It is reachable under the API path prefix and exposes its own OpenAPI spec.
+type APIVersion struct {
+	// Name is the name of the version (e.g. v1).
+	Name string `json:"name"`
+	// PathPrefix is the path prefix of the version, relative to the API path prefix.
+	PathPrefix string `json:"pathPrefix"`
+	// OpenAPISpec is the OpenAPI spec of the version. If not set, the API one is used.
+	// +optional
+	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty"`
+	// Deprecated marks the version as deprecated.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// APIService configures the service to exposed on the edge.
+type APIService struct {
+	Name string `json:"name"`
+	// port of the referenced service. A port name or port number
+	// is required for an APIServiceBackendPort.
+	Port APIServiceBackendPort `json:"port"`
+	// Weight is the share of the traffic sent to this service when Weighted services are defined.
+	// +optional
+	Weight *int `json:"weight,omitempty"`
+	// Weighted are additional services among which the API traffic is load-balanced according to their weight.
+	// It enables progressive rollouts, like canary releases, of a published API.
+	// +optional
+	Weighted []APIWeightedService `json:"weighted,omitempty"`
+}
+
+// APIWeightedService is a service receiving a weighted share of the API traffic.
+type APIWeightedService struct {
+	Name string                `json:"name"`
+	Port APIServiceBackendPort `json:"port"`
+	// +kubebuilder:validation:Minimum=0
+	Weight int `json:"weight"`
+}
+
+// APIServiceBackendPort is the service port being referenced.
+type APIServiceBackendPort struct {
+	// name is the name of the port on the Service.
+	// This must be an IANA_SVC_NAME (following RFC6335).
+	// This is a mutually exclusive setting with "Number".
+	// +optional
+	Name string `json:"name"`
+
+	// number is the numerical port number (e.g. 80) on the Service.
+	// This is a mutually exclusive setting with "Name".
+	// +optional
+	Number int32 `json:"number"`
+}
+
+// OpenAPISpec defines the OpenAPI spec of an API. When neither a URL, a path nor a ConfigMap is given, the spec is
+// discovered on well-known paths of the API service.
+type OpenAPISpec struct {
+	// +optional
+	URL string `json:"url,omitempty"`
+	// +optional
+	Path string `json:"path,omitempty"`
+	// +optional
+	Port *APIServiceBackendPort `json:"port,omitempty"`
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+	// ConfigMapRef references the key of a ConfigMap, in the namespace of the API, holding the OpenAPI spec.
+	// +optional
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty"`
+	// Fallbacks are alternative sources of the OpenAPI spec, tried in order when it can't be fetched from the
+	// previous ones.
+	// +optional
+	Fallbacks []OpenAPISpecSource `json:"fallbacks,omitempty"`
+}
+
+// Sources returns the sources of the OpenAPI spec, in the order they must be tried.
+func (s OpenAPISpec) Sources() []OpenAPISpecSource {
+	sources := []OpenAPISpecSource{{
+		URL:          s.URL,
+		Path:         s.Path,
+		Port:         s.Port,
+		Protocol:     s.Protocol,
+		ConfigMapRef: s.ConfigMapRef,
+	}}
+
+	return append(sources, s.Fallbacks...)
+}
+
+// OpenAPISpecSource is a source from which an OpenAPI spec can be fetched.
+type OpenAPISpecSource struct {
+	// +optional
+	URL string `json:"url,omitempty"`
+	// +optional
+	Path string `json:"path,omitempty"`
+	// +optional
+	Port *APIServiceBackendPort `json:"port,omitempty"`
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+	// +optional
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty"`
+}
+
+// OpenAPISpecConfigMapRef references the key of a ConfigMap holding an OpenAPI spec.
+type OpenAPISpecConfigMapRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// APIStatus is the status of an API.
+type APIStatus struct {
+	Version  string      `json:"version,omitempty"`
+	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
+	// Hash is a hash representing the API.
+	Hash string `json:"hash,omitempty"`
+	// Conditions are the latest observations of the API state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIList defines a list of APIs.
+type APIList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []API `json:"items"`
+}
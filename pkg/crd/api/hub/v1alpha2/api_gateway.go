@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIGateway defines a gateway that exposes APIs.
+// +kubebuilder:printcolumn:name="URLs",type=string,JSONPath=`.status.urls`
+// +kubebuilder:resource:scope=Cluster
+type APIGateway struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// The desired behavior of this APIGateway.
+	Spec APIGatewaySpec `json:"spec,omitempty"`
+
+	// The current status of this APIGateway.
+	// +optional
+	Status APIGatewayStatus `json:"status,omitempty"`
+}
+
+// APIGatewaySpec configures an APIGateway.
+type APIGatewaySpec struct {
+	// +optional
+	APIAccesses []string `json:"apiAccesses,omitempty"`
+	// CustomDomains are the custom domains under which the gateway will be exposed.
+	// +optional
+	CustomDomains []string `json:"customDomains,omitempty"`
+	// CredentialLocations are the locations from which consumers are allowed to send their credentials: "header" for
+	// the Authorization header and "query" for the api_key query parameter. Credentials sent in the query may leak
+	// into access logs. All locations are allowed when not set.
+	// +optional
+	// +kubebuilder:validation:items:Enum=header;query
+	CredentialLocations []string `json:"credentialLocations,omitempty"`
+	// ExampleCapture enables the capture of anonymized request/response pairs, exposed as examples on the portals.
+	// Examples are not captured when not set.
+	// +optional
+	ExampleCapture *APIGatewayExampleCapture `json:"exampleCapture,omitempty"`
+	// IdentityHeaders configures the names of the headers holding the identity of the consumers, forwarded to the
+	// APIs once their API token validated, for APIs with existing header conventions to rely on them.
+	// +optional
+	IdentityHeaders *APIGatewayIdentityHeaders `json:"identityHeaders,omitempty"`
+}
+
+// APIGatewayIdentityHeaders configures the names of the headers holding the identity of the consumers of an
+// APIGateway.
+type APIGatewayIdentityHeaders struct {
+	// User is the header holding the user of the consumer, identified by their email. It isn't forwarded when not
+	// set.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	User string `json:"user,omitempty"`
+	// Email is the header holding the email of the consumer. Defaults to Hub-Email.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	Email string `json:"email,omitempty"`
+	// Groups is the header holding the comma separated groups of the consumer. Defaults to Hub-Groups.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9-]+$`
+	Groups string `json:"groups,omitempty"`
+}
+
+// APIGatewayExampleCapture configures the capture of request/response examples by an APIGateway. Credentials, cookies
+// and common secret fields are always redacted from the captured examples.
+type APIGatewayExampleCapture struct {
+	// SampleRate is the percentage of the requests captured. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SampleRate int `json:"sampleRate,omitempty"`
+	// MaxExamplesPerOperation is the number of examples kept for each operation, older ones being discarded.
+	// Defaults to 5.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	MaxExamplesPerOperation int `json:"maxExamplesPerOperation,omitempty"`
+	// MaxBodySize is the size, in bytes, above which captured bodies are truncated. Defaults to 4096.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65536
+	MaxBodySize *int `json:"maxBodySize,omitempty"`
+	// RedactedHeaders are additional headers whose values are redacted from the captured examples.
+	// +optional
+	RedactedHeaders []string `json:"redactedHeaders,omitempty"`
+	// RedactedFields are additional JSON body fields whose values are redacted from the captured examples.
+	// +optional
+	RedactedFields []string `json:"redactedFields,omitempty"`
+}
+
+// APIGatewayStatus is the status of an APIGateway.
+type APIGatewayStatus struct {
+	Version  string      `json:"version,omitempty"`
+	SyncedAt metav1.Time `json:"syncedAt,omitempty"`
+
+	// URLs are the URLs for accessing the APIGateway.
+	URLs string `json:"urls"`
+
+	// HubDomain is the hub generated domain of the APIGateway.
+	// +optional
+	HubDomain string `json:"hubDomain"`
+
+	// CustomDomains are the custom domains for accessing the exposed APIGateway.
+	// +optional
+	CustomDomains []string `json:"customDomains,omitempty"`
+
+	// Hash is a hash representing the APIPortal.
+	Hash string `json:"hash,omitempty"`
+
+	// Conditions are the latest observations of the APIGateway state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// APIGatewayList defines a list of APIGateway.
+type APIGatewayList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []APIGateway `json:"items"`
+}
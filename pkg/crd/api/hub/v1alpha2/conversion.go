@@ -0,0 +1,228 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha2
+
+import (
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+)
+
+// ConvertAPIToV1alpha1 converts the given API into its v1alpha1 representation.
+func ConvertAPIToV1alpha1(in *API) *hubv1alpha1.API {
+	in = in.DeepCopy()
+
+	out := &hubv1alpha1.API{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: in.ObjectMeta,
+		Spec: hubv1alpha1.APISpec{
+			PathPrefix: in.Spec.PathPrefix,
+			Service: hubv1alpha1.APIService{
+				Name:        in.Spec.Service.Name,
+				Port:        hubv1alpha1.APIServiceBackendPort(in.Spec.Service.Port),
+				Weight:      in.Spec.Service.Weight,
+				OpenAPISpec: convertOpenAPISpecToV1alpha1(in.Spec.OpenAPISpec),
+			},
+			TermsOfService: (*hubv1alpha1.TermsOfService)(in.Spec.TermsOfService),
+		},
+		Status: hubv1alpha1.APIStatus(in.Status),
+	}
+	out.APIVersion = hubv1alpha1.SchemeGroupVersion.String()
+
+	for _, weighted := range in.Spec.Service.Weighted {
+		out.Spec.Service.Weighted = append(out.Spec.Service.Weighted, hubv1alpha1.APIWeightedService{
+			Name:   weighted.Name,
+			Port:   hubv1alpha1.APIServiceBackendPort(weighted.Port),
+			Weight: weighted.Weight,
+		})
+	}
+
+	for _, version := range in.Spec.Versions {
+		out.Spec.Versions = append(out.Spec.Versions, hubv1alpha1.APIVersion{
+			Name:        version.Name,
+			PathPrefix:  version.PathPrefix,
+			OpenAPISpec: convertOpenAPISpecToV1alpha1(version.OpenAPISpec),
+			Deprecated:  version.Deprecated,
+		})
+	}
+
+	return out
+}
+
+// ConvertAPIFromV1alpha1 converts the given v1alpha1 API into its v1alpha2 representation.
+func ConvertAPIFromV1alpha1(in *hubv1alpha1.API) *API {
+	in = in.DeepCopy()
+
+	out := &API{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: in.ObjectMeta,
+		Spec: APISpec{
+			PathPrefix: in.Spec.PathPrefix,
+			Service: APIService{
+				Name:   in.Spec.Service.Name,
+				Port:   APIServiceBackendPort(in.Spec.Service.Port),
+				Weight: in.Spec.Service.Weight,
+			},
+			OpenAPISpec:    convertOpenAPISpecFromV1alpha1(in.Spec.Service.OpenAPISpec),
+			TermsOfService: (*TermsOfService)(in.Spec.TermsOfService),
+		},
+		Status: APIStatus(in.Status),
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+
+	for _, weighted := range in.Spec.Service.Weighted {
+		out.Spec.Service.Weighted = append(out.Spec.Service.Weighted, APIWeightedService{
+			Name:   weighted.Name,
+			Port:   APIServiceBackendPort(weighted.Port),
+			Weight: weighted.Weight,
+		})
+	}
+
+	for _, version := range in.Spec.Versions {
+		out.Spec.Versions = append(out.Spec.Versions, APIVersion{
+			Name:        version.Name,
+			PathPrefix:  version.PathPrefix,
+			OpenAPISpec: convertOpenAPISpecFromV1alpha1(version.OpenAPISpec),
+			Deprecated:  version.Deprecated,
+		})
+	}
+
+	return out
+}
+
+// ConvertAPICollectionToV1alpha1 converts the given APICollection into its v1alpha1 representation.
+func ConvertAPICollectionToV1alpha1(in *APICollection) *hubv1alpha1.APICollection {
+	in = in.DeepCopy()
+
+	out := &hubv1alpha1.APICollection{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: in.ObjectMeta,
+		Spec: hubv1alpha1.APICollectionSpec{
+			PathPrefix:     in.Spec.PathPrefix,
+			APISelector:    in.Spec.APISelector,
+			TermsOfService: (*hubv1alpha1.TermsOfService)(in.Spec.TermsOfService),
+		},
+		Status: hubv1alpha1.APICollectionStatus(in.Status),
+	}
+	out.APIVersion = hubv1alpha1.SchemeGroupVersion.String()
+
+	return out
+}
+
+// ConvertAPICollectionFromV1alpha1 converts the given v1alpha1 APICollection into its v1alpha2 representation.
+func ConvertAPICollectionFromV1alpha1(in *hubv1alpha1.APICollection) *APICollection {
+	in = in.DeepCopy()
+
+	out := &APICollection{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: in.ObjectMeta,
+		Spec: APICollectionSpec{
+			PathPrefix:     in.Spec.PathPrefix,
+			APISelector:    in.Spec.APISelector,
+			TermsOfService: (*TermsOfService)(in.Spec.TermsOfService),
+		},
+		Status: APICollectionStatus(in.Status),
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+
+	return out
+}
+
+// ConvertAPIGatewayToV1alpha1 converts the given APIGateway into its v1alpha1 representation.
+func ConvertAPIGatewayToV1alpha1(in *APIGateway) *hubv1alpha1.APIGateway {
+	in = in.DeepCopy()
+
+	out := &hubv1alpha1.APIGateway{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: in.ObjectMeta,
+		Spec: hubv1alpha1.APIGatewaySpec{
+			APIAccesses:         in.Spec.APIAccesses,
+			CustomDomains:       in.Spec.CustomDomains,
+			CredentialLocations: in.Spec.CredentialLocations,
+			ExampleCapture:      (*hubv1alpha1.APIGatewayExampleCapture)(in.Spec.ExampleCapture),
+			IdentityHeaders:     (*hubv1alpha1.APIGatewayIdentityHeaders)(in.Spec.IdentityHeaders),
+		},
+		Status: hubv1alpha1.APIGatewayStatus(in.Status),
+	}
+	out.APIVersion = hubv1alpha1.SchemeGroupVersion.String()
+
+	return out
+}
+
+// ConvertAPIGatewayFromV1alpha1 converts the given v1alpha1 APIGateway into its v1alpha2 representation.
+func ConvertAPIGatewayFromV1alpha1(in *hubv1alpha1.APIGateway) *APIGateway {
+	in = in.DeepCopy()
+
+	out := &APIGateway{
+		TypeMeta:   in.TypeMeta,
+		ObjectMeta: in.ObjectMeta,
+		Spec: APIGatewaySpec{
+			APIAccesses:         in.Spec.APIAccesses,
+			CustomDomains:       in.Spec.CustomDomains,
+			CredentialLocations: in.Spec.CredentialLocations,
+			ExampleCapture:      (*APIGatewayExampleCapture)(in.Spec.ExampleCapture),
+			IdentityHeaders:     (*APIGatewayIdentityHeaders)(in.Spec.IdentityHeaders),
+		},
+		Status: APIGatewayStatus(in.Status),
+	}
+	out.APIVersion = SchemeGroupVersion.String()
+
+	return out
+}
+
+func convertOpenAPISpecToV1alpha1(in OpenAPISpec) hubv1alpha1.OpenAPISpec {
+	out := hubv1alpha1.OpenAPISpec{
+		URL:          in.URL,
+		Path:         in.Path,
+		Port:         (*hubv1alpha1.APIServiceBackendPort)(in.Port),
+		Protocol:     in.Protocol,
+		ConfigMapRef: (*hubv1alpha1.OpenAPISpecConfigMapRef)(in.ConfigMapRef),
+	}
+
+	for _, fallback := range in.Fallbacks {
+		out.Fallbacks = append(out.Fallbacks, hubv1alpha1.OpenAPISpecSource{
+			URL:          fallback.URL,
+			Path:         fallback.Path,
+			Port:         (*hubv1alpha1.APIServiceBackendPort)(fallback.Port),
+			Protocol:     fallback.Protocol,
+			ConfigMapRef: (*hubv1alpha1.OpenAPISpecConfigMapRef)(fallback.ConfigMapRef),
+		})
+	}
+
+	return out
+}
+
+func convertOpenAPISpecFromV1alpha1(in hubv1alpha1.OpenAPISpec) OpenAPISpec {
+	out := OpenAPISpec{
+		URL:          in.URL,
+		Path:         in.Path,
+		Port:         (*APIServiceBackendPort)(in.Port),
+		Protocol:     in.Protocol,
+		ConfigMapRef: (*OpenAPISpecConfigMapRef)(in.ConfigMapRef),
+	}
+
+	for _, fallback := range in.Fallbacks {
+		out.Fallbacks = append(out.Fallbacks, OpenAPISpecSource{
+			URL:          fallback.URL,
+			Path:         fallback.Path,
+			Port:         (*APIServiceBackendPort)(fallback.Port),
+			Protocol:     fallback.Protocol,
+			ConfigMapRef: (*OpenAPISpecConfigMapRef)(fallback.ConfigMapRef),
+		})
+	}
+
+	return out
+}
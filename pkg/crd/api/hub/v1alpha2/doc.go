@@ -0,0 +1,4 @@
+// +k8s:deepcopy-gen=package
+// +groupName=hub.traefik.io
+
+package v1alpha2
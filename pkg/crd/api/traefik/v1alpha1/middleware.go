@@ -40,6 +40,32 @@ type MiddlewareSpec struct {
 	StripPrefix      *StripPrefix      `json:"stripPrefix,omitempty"`
 	StripPrefixRegex *StripPrefixRegex `json:"stripPrefixRegex,omitempty"`
 	AddPrefix        *AddPrefix        `json:"addPrefix,omitempty"`
+	Headers          *Headers          `json:"headers,omitempty"`
+	IPWhiteList      *IPWhiteList      `json:"ipWhiteList,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Headers holds the custom headers configuration.
+type Headers struct {
+	CustomRequestHeaders  map[string]string `json:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders map[string]string `json:"customResponseHeaders,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// IPWhiteList holds the IP whitelist configuration.
+type IPWhiteList struct {
+	SourceRange []string    `json:"sourceRange,omitempty"`
+	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// IPStrategy holds the IP strategy configuration used to determine the client IP.
+type IPStrategy struct {
+	Depth       int      `json:"depth,omitempty"`
+	ExcludedIPs []string `json:"excludedIPs,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
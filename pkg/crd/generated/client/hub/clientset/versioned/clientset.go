@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/typed/hub/v1alpha1"
+	hubv1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/typed/hub/v1alpha2"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -30,6 +31,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	HubV1alpha1() hubv1alpha1.HubV1alpha1Interface
+	HubV1alpha2() hubv1alpha2.HubV1alpha2Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -37,6 +39,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	hubV1alpha1 *hubv1alpha1.HubV1alpha1Client
+	hubV1alpha2 *hubv1alpha2.HubV1alpha2Client
 }
 
 // HubV1alpha1 retrieves the HubV1alpha1Client
@@ -44,6 +47,11 @@ func (c *Clientset) HubV1alpha1() hubv1alpha1.HubV1alpha1Interface {
 	return c.hubV1alpha1
 }
 
+// HubV1alpha2 retrieves the HubV1alpha2Client
+func (c *Clientset) HubV1alpha2() hubv1alpha2.HubV1alpha2Interface {
+	return c.hubV1alpha2
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -69,6 +77,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.hubV1alpha2, err = hubv1alpha2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -82,6 +94,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.hubV1alpha1 = hubv1alpha1.NewForConfigOrDie(c)
+	cs.hubV1alpha2 = hubv1alpha2.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -91,6 +104,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.hubV1alpha1 = hubv1alpha1.New(c)
+	cs.hubV1alpha2 = hubv1alpha2.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
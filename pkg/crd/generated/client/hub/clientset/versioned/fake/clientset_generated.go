@@ -22,6 +22,8 @@ import (
 	clientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/typed/hub/v1alpha1"
 	fakehubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/typed/hub/v1alpha1/fake"
+	hubv1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/typed/hub/v1alpha2"
+	fakehubv1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/typed/hub/v1alpha2/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -80,3 +82,8 @@ var _ clientset.Interface = &Clientset{}
 func (c *Clientset) HubV1alpha1() hubv1alpha1.HubV1alpha1Interface {
 	return &fakehubv1alpha1.FakeHubV1alpha1{Fake: &c.Fake}
 }
+
+// HubV1alpha2 retrieves the HubV1alpha2Client
+func (c *Clientset) HubV1alpha2() hubv1alpha2.HubV1alpha2Interface {
+	return &fakehubv1alpha2.FakeHubV1alpha2{Fake: &c.Fake}
+}
@@ -48,6 +48,10 @@ func (c *FakeHubV1alpha1) APIPortals() v1alpha1.APIPortalInterface {
 	return &FakeAPIPortals{c}
 }
 
+func (c *FakeHubV1alpha1) APIRateLimits() v1alpha1.APIRateLimitInterface {
+	return &FakeAPIRateLimits{c}
+}
+
 func (c *FakeHubV1alpha1) AccessControlPolicies() v1alpha1.AccessControlPolicyInterface {
 	return &FakeAccessControlPolicies{c}
 }
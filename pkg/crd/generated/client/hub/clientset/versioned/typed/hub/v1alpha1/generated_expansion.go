@@ -28,6 +28,8 @@ type APIGatewayExpansion interface{}
 
 type APIPortalExpansion interface{}
 
+type APIRateLimitExpansion interface{}
+
 type AccessControlPolicyExpansion interface{}
 
 type EdgeIngressExpansion interface{}
@@ -31,6 +31,7 @@ type HubV1alpha1Interface interface {
 	APICollectionsGetter
 	APIGatewaysGetter
 	APIPortalsGetter
+	APIRateLimitsGetter
 	AccessControlPoliciesGetter
 	EdgeIngressesGetter
 	IngressClassesGetter
@@ -61,6 +62,10 @@ func (c *HubV1alpha1Client) APIPortals() APIPortalInterface {
 	return newAPIPortals(c)
 }
 
+func (c *HubV1alpha1Client) APIRateLimits() APIRateLimitInterface {
+	return newAPIRateLimits(c)
+}
+
 func (c *HubV1alpha1Client) AccessControlPolicies() AccessControlPolicyInterface {
 	return newAccessControlPolicies(c)
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	v1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -63,6 +64,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIGateways().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("apiportals"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIPortals().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("apiratelimits"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().APIRateLimits().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("accesscontrolpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().AccessControlPolicies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("edgeingresses"):
@@ -70,6 +73,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("ingressclasses"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha1().IngressClasses().Informer()}, nil
 
+		// Group=hub.traefik.io, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("apis"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha2().APIs().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("apicollections"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha2().APICollections().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("apigateways"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Hub().V1alpha2().APIGateways().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
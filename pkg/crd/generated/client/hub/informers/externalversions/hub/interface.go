@@ -20,6 +20,7 @@ package hub
 
 import (
 	v1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions/hub/v1alpha1"
+	v1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions/hub/v1alpha2"
 	internalinterfaces "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions/internalinterfaces"
 )
 
@@ -27,6 +28,8 @@ import (
 type Interface interface {
 	// V1alpha1 provides access to shared informers for resources in V1alpha1.
 	V1alpha1() v1alpha1.Interface
+	// V1alpha2 provides access to shared informers for resources in V1alpha2.
+	V1alpha2() v1alpha2.Interface
 }
 
 type group struct {
@@ -44,3 +47,8 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 func (g *group) V1alpha1() v1alpha1.Interface {
 	return v1alpha1.New(g.factory, g.namespace, g.tweakListOptions)
 }
+
+// V1alpha2 returns a new v1alpha2.Interface.
+func (g *group) V1alpha2() v1alpha2.Interface {
+	return v1alpha2.New(g.factory, g.namespace, g.tweakListOptions)
+}
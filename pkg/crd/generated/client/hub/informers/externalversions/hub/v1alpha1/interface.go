@@ -34,6 +34,8 @@ type Interface interface {
 	APIGateways() APIGatewayInformer
 	// APIPortals returns a APIPortalInformer.
 	APIPortals() APIPortalInformer
+	// APIRateLimits returns a APIRateLimitInformer.
+	APIRateLimits() APIRateLimitInformer
 	// AccessControlPolicies returns a AccessControlPolicyInformer.
 	AccessControlPolicies() AccessControlPolicyInformer
 	// EdgeIngresses returns a EdgeIngressInformer.
@@ -78,6 +80,11 @@ func (v *version) APIPortals() APIPortalInformer {
 	return &aPIPortalInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// APIRateLimits returns a APIRateLimitInformer.
+func (v *version) APIRateLimits() APIRateLimitInformer {
+	return &aPIRateLimitInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // AccessControlPolicies returns a AccessControlPolicyInformer.
 func (v *version) AccessControlPolicies() AccessControlPolicyInformer {
 	return &accessControlPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	v1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AccessControlPoliciesBySecretIndex is the name of the index referencing AccessControlPolicies by the secrets they use.
+// IndexAccessControlPoliciesBySecret must be registered under this name on the AccessControlPolicy informer to use
+// AccessControlPolicyLister.ListBySecret.
+const AccessControlPoliciesBySecretIndex = "accessControlPoliciesBySecret"
+
+// IndexAccessControlPoliciesBySecret indexes AccessControlPolicies by the "namespace/name" of the secrets they use.
+func IndexAccessControlPoliciesBySecret(obj interface{}) ([]string, error) {
+	policy, ok := obj.(*v1alpha1.AccessControlPolicy)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T", obj)
+	}
+
+	var refs []*corev1.SecretReference
+	switch {
+	case policy.Spec.BasicAuth != nil:
+		refs = append(refs, policy.Spec.BasicAuth.UsersSecret)
+	case policy.Spec.OIDC != nil:
+		refs = append(refs, policy.Spec.OIDC.Secret)
+	case policy.Spec.OIDCGoogle != nil:
+		refs = append(refs, policy.Spec.OIDCGoogle.Secret)
+	case policy.Spec.OAuthIntro != nil:
+		refs = append(refs, &policy.Spec.OAuthIntro.ClientConfig.Auth.Secret)
+	case policy.Spec.GeoIP != nil:
+		for _, db := range policy.Spec.GeoIP.Databases {
+			refs = append(refs, db.Secret)
+		}
+	}
+
+	var keys []string
+	for _, ref := range refs {
+		if ref != nil {
+			keys = append(keys, ref.Namespace+"/"+ref.Name)
+		}
+	}
+
+	return keys, nil
+}
+
+// AccessControlPolicyListerExpansion allows custom methods to be added to
+// AccessControlPolicyLister.
+type AccessControlPolicyListerExpansion interface {
+	// ListBySecret lists the AccessControlPolicies using the given secret.
+	// Objects returned here must be treated as read-only.
+	ListBySecret(namespace, name string) ([]*v1alpha1.AccessControlPolicy, error)
+}
+
+// ListBySecret lists the AccessControlPolicies using the given secret.
+func (s *accessControlPolicyLister) ListBySecret(namespace, name string) ([]*v1alpha1.AccessControlPolicy, error) {
+	objs, err := s.indexer.ByIndex(AccessControlPoliciesBySecretIndex, namespace+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*v1alpha1.AccessControlPolicy, 0, len(objs))
+	for _, obj := range objs {
+		policies = append(policies, obj.(*v1alpha1.AccessControlPolicy))
+	}
+
+	return policies, nil
+}
@@ -42,9 +42,9 @@ type APIGatewayListerExpansion interface{}
 // APIPortalLister.
 type APIPortalListerExpansion interface{}
 
-// AccessControlPolicyListerExpansion allows custom methods to be added to
-// AccessControlPolicyLister.
-type AccessControlPolicyListerExpansion interface{}
+// APIRateLimitListerExpansion allows custom methods to be added to
+// APIRateLimitLister.
+type APIRateLimitListerExpansion interface{}
 
 // EdgeIngressListerExpansion allows custom methods to be added to
 // EdgeIngressLister.
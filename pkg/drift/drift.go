@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package drift detects the manual edits of the Traefik Middlewares, IngressRoutes and TraefikServices generated by the
+// agent, so that an edited forward-auth middleware or route can't silently weaken the protection of the routes.
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotations recording the spec a resource has been generated with.
+const (
+	// AnnotationSpecHash holds the hex encoded SHA-256 hash of the JSON encoded spec a resource has been generated
+	// with.
+	AnnotationSpecHash = "hub.traefik.io/spec-hash"
+	// AnnotationGeneratedSpec holds the JSON encoded spec a resource has been generated with, allowing to repair it.
+	AnnotationGeneratedSpec = "hub.traefik.io/generated-spec"
+)
+
+const (
+	labelManagedBy = "app.kubernetes.io/managed-by"
+	managedBy      = "traefik-hub"
+)
+
+// Stamp marks the resource having the given metadata as managed by the agent and generated with the given spec.
+func Stamp(meta *metav1.ObjectMeta, spec interface{}) error {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("marshal spec: %w", err)
+	}
+
+	if meta.Labels == nil {
+		meta.Labels = make(map[string]string)
+	}
+	meta.Labels[labelManagedBy] = managedBy
+
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[AnnotationSpecHash] = hash(raw)
+	meta.Annotations[AnnotationGeneratedSpec] = string(raw)
+
+	return nil
+}
+
+// IsStamped returns whether the resource having the given metadata has been stamped.
+func IsStamped(meta metav1.ObjectMeta) bool {
+	_, ok := meta.Annotations[AnnotationSpecHash]
+	return ok
+}
+
+// Drifted returns whether the given spec differs from the one the resource having the given metadata has been
+// generated with. Resources which aren't stamped never drift.
+func Drifted(meta metav1.ObjectMeta, spec interface{}) (bool, error) {
+	want, ok := meta.Annotations[AnnotationSpecHash]
+	if !ok {
+		return false, nil
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return false, fmt.Errorf("marshal spec: %w", err)
+	}
+
+	return hash(raw) != want, nil
+}
+
+// GeneratedSpec decodes, into spec, the spec the resource having the given metadata has been generated with. It fails
+// if the generated spec doesn't match the spec hash, for instance because it was edited as well.
+func GeneratedSpec(meta metav1.ObjectMeta, spec interface{}) error {
+	raw := meta.Annotations[AnnotationGeneratedSpec]
+	if raw == "" {
+		return fmt.Errorf("missing %q annotation", AnnotationGeneratedSpec)
+	}
+
+	if hash([]byte(raw)) != meta.Annotations[AnnotationSpecHash] {
+		return fmt.Errorf("%q annotation doesn't match the %q annotation", AnnotationGeneratedSpec, AnnotationSpecHash)
+	}
+
+	if err := json.Unmarshal([]byte(raw), spec); err != nil {
+		return fmt.Errorf("unmarshal generated spec: %w", err)
+	}
+
+	return nil
+}
+
+func hash(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStamp(t *testing.T) {
+	spec := traefikv1alpha1.MiddlewareSpec{
+		ForwardAuth: &traefikv1alpha1.ForwardAuth{Address: "http://auth/jwt"},
+	}
+
+	meta := metav1.ObjectMeta{
+		Name:        "jwt",
+		Labels:      map[string]string{"app": "whoami"},
+		Annotations: map[string]string{"note": "kept"},
+	}
+	require.NoError(t, Stamp(&meta, spec))
+
+	assert.Equal(t, map[string]string{
+		"app":                          "whoami",
+		"app.kubernetes.io/managed-by": "traefik-hub",
+	}, meta.Labels)
+	assert.Equal(t, map[string]string{
+		"note":                  "kept",
+		AnnotationGeneratedSpec: `{"forwardAuth":{"address":"http://auth/jwt"}}`,
+		AnnotationSpecHash:      "e9cad5dbe9a630af1331d0ee7227709de8e6d7da3180de10939b7bb133507647",
+	}, meta.Annotations)
+	assert.True(t, IsStamped(meta))
+}
+
+func TestDrifted(t *testing.T) {
+	spec := traefikv1alpha1.MiddlewareSpec{
+		ForwardAuth: &traefikv1alpha1.ForwardAuth{Address: "http://auth/jwt"},
+	}
+
+	var meta metav1.ObjectMeta
+	drifted, err := Drifted(meta, spec)
+	require.NoError(t, err)
+	assert.False(t, drifted, "unstamped resources never drift")
+
+	require.NoError(t, Stamp(&meta, spec))
+
+	drifted, err = Drifted(meta, spec)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+
+	edited := traefikv1alpha1.MiddlewareSpec{
+		ForwardAuth: &traefikv1alpha1.ForwardAuth{Address: "http://auth/jwt", TrustForwardHeader: true},
+	}
+	drifted, err = Drifted(meta, edited)
+	require.NoError(t, err)
+	assert.True(t, drifted)
+}
+
+func TestGeneratedSpec(t *testing.T) {
+	spec := traefikv1alpha1.MiddlewareSpec{
+		StripPrefix: &traefikv1alpha1.StripPrefix{Prefixes: []string{"/api"}},
+	}
+
+	var meta metav1.ObjectMeta
+	require.NoError(t, Stamp(&meta, spec))
+
+	var got traefikv1alpha1.MiddlewareSpec
+	require.NoError(t, GeneratedSpec(meta, &got))
+	assert.Equal(t, spec, got)
+
+	meta.Annotations[AnnotationGeneratedSpec] = `{"stripPrefix":{"prefixes":["/"]}}`
+	assert.Error(t, GeneratedSpec(meta, &got))
+
+	delete(meta.Annotations, AnnotationGeneratedSpec)
+	assert.Error(t, GeneratedSpec(meta, &got))
+}
@@ -0,0 +1,300 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	eventsv1client "k8s.io/client-go/kubernetes/typed/events/v1"
+)
+
+// Reasons of the drift Events.
+const (
+	ReasonDriftDetected = "DriftDetected"
+	ReasonDriftRepaired = "DriftRepaired"
+)
+
+const reportingController = "hub.traefik.io/hub-agent-kubernetes"
+
+// Watcher periodically checks the Middlewares, IngressRoutes and TraefikServices generated by the agent and records a
+// Warning Event on the ones whose spec was edited since they were generated. When repairing, the generated spec of the
+// drifted resources is restored. These Traefik resources having no status, drift is only reported with Events.
+type Watcher struct {
+	interval time.Duration
+	repair   bool
+	routes   bool
+
+	traefikClientSet traefikclientset.TraefikV1alpha1Interface
+	events           eventsv1client.EventsGetter
+	instance         string
+
+	now func() time.Time
+}
+
+// NewWatcher returns a new Watcher checking the generated resources every interval. The IngressRoutes and
+// TraefikServices are only checked when routes is set, as their CRDs may not be installed. The instance identifies the
+// agent replica reporting the Events.
+func NewWatcher(interval time.Duration, repair, routes bool, traefikClientSet traefikclientset.TraefikV1alpha1Interface,
+	events eventsv1client.EventsGetter, instance string,
+) *Watcher {
+	return &Watcher{
+		interval:         interval,
+		repair:           repair,
+		routes:           routes,
+		traefikClientSet: traefikClientSet,
+		events:           events,
+		instance:         instance,
+		now:              time.Now,
+	}
+}
+
+// Run runs the Watcher.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping drift watcher")
+			return
+
+		case <-t.C:
+			if err := w.check(ctx); err != nil {
+				log.Error().Err(err).Msg("Unable to check generated resources drift")
+			}
+		}
+	}
+}
+
+// generated is a resource generated by the agent.
+type generated struct {
+	kind string
+	meta metav1.ObjectMeta
+	spec interface{}
+	// repair restores the spec the resource has been generated with.
+	repair func(ctx context.Context) error
+}
+
+func (w *Watcher) check(ctx context.Context) error {
+	resources, err := w.listGenerated(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, res := range resources {
+		logger := log.With().
+			Str("kind", res.kind).
+			Str("name", res.meta.Name).
+			Str("namespace", res.meta.Namespace).
+			Logger()
+
+		drifted, err := Drifted(res.meta, res.spec)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to check resource drift")
+			continue
+		}
+		if !drifted {
+			continue
+		}
+
+		logger.Warn().Msg("Resource edited since it was generated")
+		w.record(ctx, res, ReasonDriftDetected, corev1.EventTypeWarning,
+			res.kind+" spec edited since it was generated by the agent")
+
+		if !w.repair {
+			continue
+		}
+
+		if err = res.repair(ctx); err != nil {
+			logger.Error().Err(err).Msg("Unable to repair resource")
+			continue
+		}
+
+		logger.Info().Msg("Resource repaired")
+		w.record(ctx, res, ReasonDriftRepaired, corev1.EventTypeNormal,
+			res.kind+" spec restored to the one generated by the agent")
+	}
+
+	return nil
+}
+
+// listGenerated lists the resources generated by the agent.
+func (w *Watcher) listGenerated(ctx context.Context) ([]generated, error) {
+	opts := metav1.ListOptions{LabelSelector: labelManagedBy + "=" + managedBy}
+
+	middlewareList, err := w.traefikClientSet.Middlewares(metav1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list middlewares: %w", err)
+	}
+
+	var resources []generated
+	for i := range middlewareList.Items {
+		middleware := &middlewareList.Items[i]
+
+		resources = append(resources, generated{
+			kind: "Middleware",
+			meta: middleware.ObjectMeta,
+			spec: middleware.Spec,
+			repair: func(ctx context.Context) error {
+				repaired := &traefikv1alpha1.Middleware{TypeMeta: typeMeta("Middleware"), ObjectMeta: repairedMeta(middleware.ObjectMeta)}
+				if err := GeneratedSpec(middleware.ObjectMeta, &repaired.Spec); err != nil {
+					return fmt.Errorf("get generated spec: %w", err)
+				}
+
+				return repair(ctx, w.traefikClientSet.Middlewares(middleware.Namespace).Patch, repaired, &repaired.ObjectMeta, repaired.Spec)
+			},
+		})
+	}
+
+	if !w.routes {
+		return resources, nil
+	}
+
+	ingRouteList, err := w.traefikClientSet.IngressRoutes(metav1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list ingress routes: %w", err)
+	}
+
+	for i := range ingRouteList.Items {
+		ingRoute := &ingRouteList.Items[i]
+
+		resources = append(resources, generated{
+			kind: "IngressRoute",
+			meta: ingRoute.ObjectMeta,
+			spec: ingRoute.Spec,
+			repair: func(ctx context.Context) error {
+				repaired := &traefikv1alpha1.IngressRoute{TypeMeta: typeMeta("IngressRoute"), ObjectMeta: repairedMeta(ingRoute.ObjectMeta)}
+				if err := GeneratedSpec(ingRoute.ObjectMeta, &repaired.Spec); err != nil {
+					return fmt.Errorf("get generated spec: %w", err)
+				}
+
+				return repair(ctx, w.traefikClientSet.IngressRoutes(ingRoute.Namespace).Patch, repaired, &repaired.ObjectMeta, repaired.Spec)
+			},
+		})
+	}
+
+	svcList, err := w.traefikClientSet.TraefikServices(metav1.NamespaceAll).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list traefik services: %w", err)
+	}
+
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+
+		resources = append(resources, generated{
+			kind: "TraefikService",
+			meta: svc.ObjectMeta,
+			spec: svc.Spec,
+			repair: func(ctx context.Context) error {
+				repaired := &traefikv1alpha1.TraefikService{TypeMeta: typeMeta("TraefikService"), ObjectMeta: repairedMeta(svc.ObjectMeta)}
+				if err := GeneratedSpec(svc.ObjectMeta, &repaired.Spec); err != nil {
+					return fmt.Errorf("get generated spec: %w", err)
+				}
+
+				return repair(ctx, w.traefikClientSet.TraefikServices(svc.Namespace).Patch, repaired, &repaired.ObjectMeta, repaired.Spec)
+			},
+		})
+	}
+
+	return resources, nil
+}
+
+func typeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{
+		APIVersion: traefikv1alpha1.SchemeGroupVersion.String(),
+		Kind:       kind,
+	}
+}
+
+// repairedMeta returns the metadata the given drifted resource is applied with when repairing it. Its labels,
+// annotations and owner references are kept, as the fields the agent no longer applies are removed.
+func repairedMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	repaired := metav1.ObjectMeta{
+		Name:            meta.Name,
+		Namespace:       meta.Namespace,
+		Labels:          make(map[string]string, len(meta.Labels)),
+		Annotations:     make(map[string]string, len(meta.Annotations)),
+		OwnerReferences: meta.OwnerReferences,
+	}
+	for key, value := range meta.Labels {
+		repaired.Labels[key] = value
+	}
+	for key, value := range meta.Annotations {
+		repaired.Annotations[key] = value
+	}
+
+	return repaired
+}
+
+// repair applies the given object, having the given metadata, with the given generated spec.
+func repair[T runtime.Object](ctx context.Context, patch kube.Patcher[T], obj runtime.Object, meta *metav1.ObjectMeta, spec interface{}) error {
+	if err := Stamp(meta, spec); err != nil {
+		return fmt.Errorf("stamp resource: %w", err)
+	}
+
+	if _, err := kube.Apply(ctx, patch, meta.Name, obj); err != nil {
+		return fmt.Errorf("apply resource: %w", err)
+	}
+
+	return nil
+}
+
+// record records an Event regarding the given resource. Failures are logged as they must not prevent the other
+// resources from being checked.
+func (w *Watcher) record(ctx context.Context, res generated, reason, typ, note string) {
+	now := w.now()
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", res.meta.Name, now.UnixNano()),
+			Namespace: res.meta.Namespace,
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: reportingController,
+		ReportingInstance:   w.instance,
+		Action:              "Check",
+		Reason:              reason,
+		Type:                typ,
+		Regarding: corev1.ObjectReference{
+			APIVersion: traefikv1alpha1.SchemeGroupVersion.String(),
+			Kind:       res.kind,
+			Namespace:  res.meta.Namespace,
+			Name:       res.meta.Name,
+			UID:        res.meta.UID,
+		},
+		Note: note,
+	}
+
+	if _, err := w.events.Events(event.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		log.Error().Err(err).
+			Str("kind", res.kind).
+			Str("name", res.meta.Name).
+			Str("namespace", res.meta.Namespace).
+			Msg("Unable to create drift event")
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package drift
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatcher_check(t *testing.T) {
+	tests := []struct {
+		desc       string
+		repair     bool
+		edit       bool
+		wantSpec   traefikv1alpha1.MiddlewareSpec
+		wantEvents []string
+	}{
+		{
+			desc:     "untouched middleware",
+			wantSpec: generatedSpec(),
+		},
+		{
+			desc:       "edited middleware is reported",
+			edit:       true,
+			wantSpec:   editedSpec(),
+			wantEvents: []string{ReasonDriftDetected},
+		},
+		{
+			desc:       "edited middleware is repaired",
+			repair:     true,
+			edit:       true,
+			wantSpec:   generatedSpec(),
+			wantEvents: []string{ReasonDriftDetected, ReasonDriftRepaired},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			middleware := &traefikv1alpha1.Middleware{
+				ObjectMeta: metav1.ObjectMeta{Name: "jwt", Namespace: "ns"},
+				Spec:       generatedSpec(),
+			}
+			require.NoError(t, Stamp(&middleware.ObjectMeta, middleware.Spec))
+			if test.edit {
+				middleware.Spec = editedSpec()
+			}
+
+			// Middlewares which are not managed by the agent are ignored.
+			unmanaged := &traefikv1alpha1.Middleware{
+				ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "ns"},
+				Spec:       editedSpec(),
+			}
+
+			traefikClientSet := traefikkubemock.NewSimpleClientset(middleware, unmanaged)
+			kubeClientSet := kubemock.NewSimpleClientset()
+
+			w := NewWatcher(time.Minute, test.repair, false, traefikClientSet.TraefikV1alpha1(), kubeClientSet.EventsV1(), "agent-0")
+
+			ctx := context.Background()
+			require.NoError(t, w.check(ctx))
+
+			got, err := traefikClientSet.TraefikV1alpha1().Middlewares("ns").Get(ctx, "jwt", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, test.wantSpec, got.Spec)
+
+			events, err := kubeClientSet.EventsV1().Events("ns").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+
+			var gotEvents []string
+			for _, event := range events.Items {
+				assert.Equal(t, corev1.ObjectReference{
+					APIVersion: "traefik.containo.us/v1alpha1",
+					Kind:       "Middleware",
+					Namespace:  "ns",
+					Name:       "jwt",
+				}, event.Regarding)
+				assert.Equal(t, "agent-0", event.ReportingInstance)
+
+				gotEvents = append(gotEvents, event.Reason)
+			}
+			assert.ElementsMatch(t, test.wantEvents, gotEvents)
+		})
+	}
+}
+
+func TestWatcher_check_routes(t *testing.T) {
+	ownerRefs := []metav1.OwnerReference{{APIVersion: "hub.traefik.io/v1alpha1", Kind: "EdgeIngress", Name: "whoami"}}
+
+	generatedRoute := traefikv1alpha1.IngressRouteSpec{
+		EntryPoints: []string{"traefikhub-tunl"},
+		Routes: []traefikv1alpha1.Route{
+			{
+				Match:       "Host(`whoami.hub-traefik.io`)",
+				Kind:        "Rule",
+				Middlewares: []traefikv1alpha1.MiddlewareRef{{Name: "zz-jwt", Namespace: "ns"}},
+				Services: []traefikv1alpha1.Service{
+					{LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{Name: "whoami-mirroring", Kind: "TraefikService"}},
+				},
+			},
+		},
+	}
+	ingRoute := &traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "whoami",
+			Namespace:       "ns",
+			Annotations:     map[string]string{"hub.traefik.io/access-control-policy": "jwt"},
+			OwnerReferences: ownerRefs,
+		},
+		Spec: generatedRoute,
+	}
+	require.NoError(t, Stamp(&ingRoute.ObjectMeta, ingRoute.Spec))
+	// The ACP middleware has been removed from the route.
+	ingRoute.Spec = *generatedRoute.DeepCopy()
+	ingRoute.Spec.Routes[0].Middlewares = nil
+
+	svc := &traefikv1alpha1.TraefikService{
+		ObjectMeta: metav1.ObjectMeta{Name: "whoami-mirroring", Namespace: "ns", OwnerReferences: ownerRefs},
+		Spec: traefikv1alpha1.ServiceSpec{
+			Mirroring: &traefikv1alpha1.Mirroring{
+				LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{Name: "whoami", Kind: "Service"},
+			},
+		},
+	}
+	require.NoError(t, Stamp(&svc.ObjectMeta, svc.Spec))
+
+	traefikClientSet := traefikkubemock.NewSimpleClientset(ingRoute, svc)
+	kubetest.AddApplyReactor(traefikClientSet)
+	kubeClientSet := kubemock.NewSimpleClientset()
+
+	w := NewWatcher(time.Minute, true, true, traefikClientSet.TraefikV1alpha1(), kubeClientSet.EventsV1(), "agent-0")
+
+	ctx := context.Background()
+	require.NoError(t, w.check(ctx))
+
+	got, err := traefikClientSet.TraefikV1alpha1().IngressRoutes("ns").Get(ctx, "whoami", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, generatedRoute, got.Spec)
+	assert.Equal(t, ownerRefs, got.OwnerReferences)
+	assert.Equal(t, "jwt", got.Annotations["hub.traefik.io/access-control-policy"])
+
+	events, err := kubeClientSet.EventsV1().Events("ns").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+
+	var gotEvents []string
+	for _, event := range events.Items {
+		// The TraefikService hasn't drifted.
+		assert.Equal(t, "IngressRoute", event.Regarding.Kind)
+		assert.Equal(t, "whoami", event.Regarding.Name)
+
+		gotEvents = append(gotEvents, event.Reason)
+	}
+	assert.ElementsMatch(t, []string{ReasonDriftDetected, ReasonDriftRepaired}, gotEvents)
+}
+
+func generatedSpec() traefikv1alpha1.MiddlewareSpec {
+	return traefikv1alpha1.MiddlewareSpec{
+		ForwardAuth: &traefikv1alpha1.ForwardAuth{
+			Address:             "http://hub-agent-auth-server.hub.svc.cluster.local/jwt@ns",
+			AuthResponseHeaders: []string{"Authorization"},
+		},
+	}
+}
+
+func editedSpec() traefikv1alpha1.MiddlewareSpec {
+	return traefikv1alpha1.MiddlewareSpec{
+		ForwardAuth: &traefikv1alpha1.ForwardAuth{
+			Address:             "http://hub-agent-auth-server.hub.svc.cluster.local/allow-all",
+			AuthResponseHeaders: []string{"Authorization"},
+		},
+	}
+}
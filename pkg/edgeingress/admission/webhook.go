@@ -20,7 +20,9 @@ package admission
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
@@ -125,6 +127,16 @@ func (h Handler) review(ctx context.Context, req *admv1.AdmissionRequest) ([]byt
 		}
 	}
 
+	if newEdgeIng != nil {
+		if err = validateIPAllowList(newEdgeIng.Spec.IPAllowList); err != nil {
+			return nil, fmt.Errorf("invalid IP allow list: %w", err)
+		}
+
+		if err = validateBlueGreen(newEdgeIng.Spec.BlueGreen); err != nil {
+			return nil, fmt.Errorf("invalid blue/green deployment: %w", err)
+		}
+	}
+
 	switch req.Operation {
 	case admv1.Create:
 		return h.reviewCreateOperation(ctx, newEdgeIng)
@@ -152,6 +164,25 @@ func (h Handler) reviewCreateOperation(ctx context.Context, edgeIng *hubv1alpha1
 	if edgeIng.Spec.ACP != nil {
 		createReq.ACP = &platform.ACP{Name: edgeIng.Spec.ACP.Name}
 	}
+	if edgeIng.Spec.Mirror != nil {
+		createReq.Mirror = &platform.Mirror{
+			Name:    edgeIng.Spec.Mirror.Name,
+			Port:    edgeIng.Spec.Mirror.Port,
+			Percent: edgeIng.Spec.Mirror.Percent,
+		}
+	}
+	if edgeIng.Spec.Headers != nil {
+		createReq.Headers = &platform.Headers{
+			Request:  edgeIng.Spec.Headers.Request,
+			Response: edgeIng.Spec.Headers.Response,
+		}
+	}
+	if edgeIng.Spec.IPAllowList != nil {
+		createReq.IPAllowList = &platform.IPAllowList{SourceRange: edgeIng.Spec.IPAllowList.SourceRange}
+	}
+	if edgeIng.Spec.BlueGreen != nil {
+		createReq.BlueGreen = newPlatformBlueGreen(edgeIng.Spec.BlueGreen)
+	}
 
 	createdEdgeIng, err := h.backend.CreateEdgeIngress(ctx, createReq)
 	if err != nil {
@@ -176,6 +207,25 @@ func (h Handler) reviewUpdateOperation(ctx context.Context, oldEdgeIng, newEdgeI
 			Name: newEdgeIng.Spec.ACP.Name,
 		}
 	}
+	if newEdgeIng.Spec.Mirror != nil {
+		updateReq.Mirror = &platform.Mirror{
+			Name:    newEdgeIng.Spec.Mirror.Name,
+			Port:    newEdgeIng.Spec.Mirror.Port,
+			Percent: newEdgeIng.Spec.Mirror.Percent,
+		}
+	}
+	if newEdgeIng.Spec.Headers != nil {
+		updateReq.Headers = &platform.Headers{
+			Request:  newEdgeIng.Spec.Headers.Request,
+			Response: newEdgeIng.Spec.Headers.Response,
+		}
+	}
+	if newEdgeIng.Spec.IPAllowList != nil {
+		updateReq.IPAllowList = &platform.IPAllowList{SourceRange: newEdgeIng.Spec.IPAllowList.SourceRange}
+	}
+	if newEdgeIng.Spec.BlueGreen != nil {
+		updateReq.BlueGreen = newPlatformBlueGreen(newEdgeIng.Spec.BlueGreen)
+	}
 
 	updatedEdgeIng, err := h.backend.UpdateEdgeIngress(ctx, oldEdgeIng.Namespace, oldEdgeIng.Name, oldEdgeIng.Status.Version, updateReq)
 	if err != nil {
@@ -254,3 +304,57 @@ func setReviewResponse(ar *admv1.AdmissionReview, patch []byte) {
 func isEdgeIngressRequest(kind metav1.GroupVersionKind) bool {
 	return kind.Kind == "EdgeIngress" && kind.Group == "hub.traefik.io" && kind.Version == "v1alpha1"
 }
+
+// validateIPAllowList makes sure the allow list only contains valid IPs or CIDRs.
+func validateIPAllowList(allowList *hubv1alpha1.EdgeIngressIPAllowList) error {
+	if allowList == nil {
+		return nil
+	}
+
+	if len(allowList.SourceRange) == 0 {
+		return errors.New("at least one source range is required")
+	}
+
+	for _, sourceRange := range allowList.SourceRange {
+		if net.ParseIP(sourceRange) != nil {
+			continue
+		}
+
+		if _, _, err := net.ParseCIDR(sourceRange); err != nil {
+			return fmt.Errorf("%q is neither an IP nor a CIDR", sourceRange)
+		}
+	}
+
+	return nil
+}
+
+// validateBlueGreen makes sure both releases of the blue/green deployment are defined and one of them is active.
+func validateBlueGreen(blueGreen *hubv1alpha1.EdgeIngressBlueGreen) error {
+	if blueGreen == nil {
+		return nil
+	}
+
+	if blueGreen.Active != hubv1alpha1.BlueGreenBlue && blueGreen.Active != hubv1alpha1.BlueGreenGreen {
+		return fmt.Errorf("active release must be %q or %q, got %q", hubv1alpha1.BlueGreenBlue, hubv1alpha1.BlueGreenGreen, blueGreen.Active)
+	}
+
+	if blueGreen.Blue.Name == "" || blueGreen.Green.Name == "" {
+		return errors.New("blue and green services are required")
+	}
+
+	return nil
+}
+
+func newPlatformBlueGreen(blueGreen *hubv1alpha1.EdgeIngressBlueGreen) *platform.BlueGreen {
+	return &platform.BlueGreen{
+		Blue: platform.Service{
+			Name: blueGreen.Blue.Name,
+			Port: blueGreen.Blue.Port,
+		},
+		Green: platform.Service{
+			Name: blueGreen.Green.Name,
+			Port: blueGreen.Green.Port,
+		},
+		Active: string(blueGreen.Active),
+	}
+}
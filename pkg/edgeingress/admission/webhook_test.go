@@ -208,6 +208,68 @@ func TestHandler_ServeHTTP_createOperationConflict(t *testing.T) {
 	assert.Equal(t, &wantResp, gotAr.Response)
 }
 
+func TestHandler_ServeHTTP_createOperationInvalidIPAllowList(t *testing.T) {
+	admissionRev := admv1.AdmissionReview{
+		Request: &admv1.AdmissionRequest{
+			UID: "id",
+			Kind: metav1.GroupVersionKind{
+				Group:   "hub.traefik.io",
+				Version: "v1alpha1",
+				Kind:    "EdgeIngress",
+			},
+			Name:      "edge-ingress",
+			Namespace: "default",
+			Operation: admv1.Create,
+			Object: runtime.RawExtension{
+				Raw: mustMarshal(t, hubv1alpha1.EdgeIngress{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "EdgeIngress",
+						APIVersion: "hub.traefik.io/v1alpha1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "edge-ingress",
+						Namespace: "default",
+					},
+					Spec: hubv1alpha1.EdgeIngressSpec{
+						Service: hubv1alpha1.EdgeIngressService{
+							Name: "whoami",
+							Port: 8081,
+						},
+						IPAllowList: &hubv1alpha1.EdgeIngressIPAllowList{
+							SourceRange: []string{"10.0.0.0/8", "10.0.0.0/33"},
+						},
+					},
+				}),
+			},
+		},
+		Response: &admv1.AdmissionResponse{},
+	}
+
+	h := NewHandler(newBackendMock(t))
+
+	b := mustMarshal(t, admissionRev)
+	rec := httptest.NewRecorder()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/", bytes.NewBuffer(b))
+	require.NoError(t, err)
+
+	h.ServeHTTP(rec, req)
+
+	var gotAr admv1.AdmissionReview
+	err = json.NewDecoder(rec.Body).Decode(&gotAr)
+	require.NoError(t, err)
+
+	wantResp := admv1.AdmissionResponse{
+		UID:     "id",
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  "Failure",
+			Message: `invalid IP allow list: "10.0.0.0/33" is neither an IP nor a CIDR`,
+		},
+	}
+
+	assert.Equal(t, &wantResp, gotAr.Response)
+}
+
 func TestHandler_ServeHTTP_updateOperation(t *testing.T) {
 	now := metav1.Now()
 
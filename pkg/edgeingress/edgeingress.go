@@ -45,6 +45,11 @@ type EdgeIngress struct {
 	Version string  `json:"version"`
 	Service Service `json:"service"`
 	ACP     *ACP    `json:"acp,omitempty"`
+	Mirror  *Mirror `json:"mirror,omitempty"`
+
+	Headers     *Headers     `json:"headers,omitempty"`
+	IPAllowList *IPAllowList `json:"ipAllowList,omitempty"`
+	BlueGreen   *BlueGreen   `json:"blueGreen,omitempty"`
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
@@ -62,6 +67,31 @@ type Service struct {
 	Port int    `json:"port"`
 }
 
+// Mirror is a service receiving a copy of the edge ingress traffic.
+type Mirror struct {
+	Name    string `json:"name"`
+	Port    int    `json:"port"`
+	Percent int    `json:"percent"`
+}
+
+// Headers are the headers injected by the edge ingress.
+type Headers struct {
+	Request  map[string]string `json:"request,omitempty"`
+	Response map[string]string `json:"response,omitempty"`
+}
+
+// IPAllowList are the source IPs allowed to reach the edge ingress.
+type IPAllowList struct {
+	SourceRange []string `json:"sourceRange"`
+}
+
+// BlueGreen are the two releases of a blue/green deployment exposed by the edge ingress.
+type BlueGreen struct {
+	Blue   Service `json:"blue"`
+	Green  Service `json:"green"`
+	Active string  `json:"active"`
+}
+
 // ACP is an ACP used by the edge ingress.
 type ACP struct {
 	Name string `json:"name"`
@@ -88,6 +118,41 @@ func (e *EdgeIngress) Resource() (*hubv1alpha1.EdgeIngress, error) {
 		}
 	}
 
+	if e.Mirror != nil {
+		spec.Mirror = &hubv1alpha1.EdgeIngressMirror{
+			Name:    e.Mirror.Name,
+			Port:    e.Mirror.Port,
+			Percent: e.Mirror.Percent,
+		}
+	}
+
+	if e.Headers != nil {
+		spec.Headers = &hubv1alpha1.EdgeIngressHeaders{
+			Request:  e.Headers.Request,
+			Response: e.Headers.Response,
+		}
+	}
+
+	if e.IPAllowList != nil {
+		spec.IPAllowList = &hubv1alpha1.EdgeIngressIPAllowList{
+			SourceRange: e.IPAllowList.SourceRange,
+		}
+	}
+
+	if e.BlueGreen != nil {
+		spec.BlueGreen = &hubv1alpha1.EdgeIngressBlueGreen{
+			Blue: hubv1alpha1.EdgeIngressService{
+				Name: e.BlueGreen.Blue.Name,
+				Port: e.BlueGreen.Blue.Port,
+			},
+			Green: hubv1alpha1.EdgeIngressService{
+				Name: e.BlueGreen.Green.Name,
+				Port: e.BlueGreen.Green.Port,
+			},
+			Active: hubv1alpha1.BlueGreenRelease(e.BlueGreen.Active),
+		}
+	}
+
 	specHash, err := spec.Hash()
 	if err != nil {
 		return nil, fmt.Errorf("compute spec hash: %w", err)
@@ -20,11 +20,13 @@ package edgeingress
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/admission/reviewer"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
@@ -32,11 +34,14 @@ import (
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	"github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/drift"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
 	netv1 "k8s.io/api/networking/v1"
 	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
 )
@@ -77,10 +82,12 @@ type Watcher struct {
 	hubInformer      hubinformer.SharedInformerFactory
 	clientSet        clientset.Interface
 	traefikClientSet v1alpha1.TraefikV1alpha1Interface
+
+	acmeLogger zerolog.Logger
 }
 
 // NewWatcher returns a new Watcher.
-func NewWatcher(client PlatformClient, hubClientSet hubclientset.Interface, clientSet clientset.Interface, traefikClientSet v1alpha1.TraefikV1alpha1Interface, hubInformer hubinformer.SharedInformerFactory, config WatcherConfig) (*Watcher, error) {
+func NewWatcher(client PlatformClient, hubClientSet hubclientset.Interface, clientSet clientset.Interface, traefikClientSet v1alpha1.TraefikV1alpha1Interface, hubInformer hubinformer.SharedInformerFactory, config WatcherConfig, acmeLogger zerolog.Logger) (*Watcher, error) {
 	return &Watcher{
 		config: config,
 
@@ -89,6 +96,8 @@ func NewWatcher(client PlatformClient, hubClientSet hubclientset.Interface, clie
 		hubInformer:      hubInformer,
 		clientSet:        clientSet,
 		traefikClientSet: traefikClientSet,
+
+		acmeLogger: acmeLogger,
 	}, nil
 }
 
@@ -100,7 +109,7 @@ func (w *Watcher) Run(ctx context.Context) {
 	certSyncInterval := time.After(w.config.CertSyncInterval)
 	ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
 	if err := w.syncCertificates(ctxSync); err != nil {
-		log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
+		w.acmeLogger.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 		certSyncInterval = time.After(w.config.CertRetryInterval)
 	}
 	w.syncEdgeIngresses(ctxSync)
@@ -120,7 +129,7 @@ func (w *Watcher) Run(ctx context.Context) {
 		case <-certSyncInterval:
 			ctxSync, cancel = context.WithTimeout(ctx, 20*time.Second)
 			if err := w.syncCertificates(ctxSync); err != nil {
-				log.Error().Err(err).Msg("Unable to synchronize certificates with platform")
+				w.acmeLogger.Error().Err(err).Msg("Unable to synchronize certificates with platform")
 				certSyncInterval = time.After(w.config.CertRetryInterval)
 				cancel()
 				continue
@@ -248,8 +257,16 @@ func (w *Watcher) syncChildAndUpdateConnectionStatus(ctx context.Context, edgeIn
 		return fmt.Errorf("unable to setup secrets: %w", err)
 	}
 
-	if err := w.upsertIngress(ctx, edgeIngress, customDomainsName); err != nil {
-		return fmt.Errorf("upsert ingress: %w", err)
+	if err := w.syncMirroringService(ctx, edgeIngress); err != nil {
+		return fmt.Errorf("sync mirroring service: %w", err)
+	}
+
+	if err := w.syncMiddlewares(ctx, edgeIngress); err != nil {
+		return fmt.Errorf("sync middlewares: %w", err)
+	}
+
+	if err := w.syncRouting(ctx, edgeIngress, customDomainsName); err != nil {
+		return err
 	}
 
 	if err := w.setEdgeIngressConnectionStatusUP(ctx, edgeIngress); err != nil {
@@ -280,6 +297,57 @@ func (w *Watcher) setupCertificates(ctx context.Context, edgeIngress *hubv1alpha
 	return nil
 }
 
+// syncRouting routes the EdgeIngress traffic through an Ingress or, when mirroring is enabled, through an
+// IngressRoute: the Kubernetes Ingress provider of Traefik can't reference the mirroring TraefikService.
+func (w *Watcher) syncRouting(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) error {
+	if edgeIng.Spec.Mirror != nil {
+		if err := w.upsertIngressRoute(ctx, edgeIng, customDomains); err != nil {
+			return fmt.Errorf("upsert ingress route: %w", err)
+		}
+
+		err := w.clientSet.NetworkingV1().Ingresses(edgeIng.Namespace).Delete(ctx, edgeIng.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete ingress: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := w.upsertIngress(ctx, edgeIng, customDomains); err != nil {
+		return fmt.Errorf("upsert ingress: %w", err)
+	}
+
+	if w.traefikClientSet == nil {
+		return nil
+	}
+
+	err := w.traefikClientSet.IngressRoutes(edgeIng.Namespace).Delete(ctx, edgeIng.Name, metav1.DeleteOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("delete ingress route: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Watcher) upsertIngressRoute(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) error {
+	ingRoute := buildIngressRoute(edgeIng, w.config.TraefikTunnelEntryPoint, customDomains)
+	if err := drift.Stamp(&ingRoute.ObjectMeta, ingRoute.Spec); err != nil {
+		return fmt.Errorf("stamp IngressRoute: %w", err)
+	}
+
+	_, err := kube.Apply(ctx, w.traefikClientSet.IngressRoutes(edgeIng.Namespace).Patch, ingRoute.Name, ingRoute)
+	if err != nil {
+		return fmt.Errorf("apply IngressRoute: %w", err)
+	}
+
+	log.Debug().
+		Str("name", ingRoute.Name).
+		Str("namespace", ingRoute.Namespace).
+		Msg("IngressRoute applied")
+
+	return nil
+}
+
 func (w *Watcher) upsertIngress(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, customDomains []string) error {
 	ing, err := w.clientSet.NetworkingV1().Ingresses(edgeIng.Namespace).Get(ctx, edgeIng.Name, metav1.GetOptions{})
 	if err != nil && !kerror.IsNotFound(err) {
@@ -315,12 +383,126 @@ func (w *Watcher) upsertIngress(ctx context.Context, edgeIng *hubv1alpha1.EdgeIn
 	return nil
 }
 
+// syncMirroringService applies the TraefikService mirroring the EdgeIngress traffic when a mirror is
+// configured, and removes it otherwise.
+func (w *Watcher) syncMirroringService(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress) error {
+	if edgeIng.Spec.Mirror == nil {
+		if w.traefikClientSet == nil {
+			return nil
+		}
+
+		err := w.traefikClientSet.TraefikServices(edgeIng.Namespace).Delete(ctx, getMirroringServiceName(edgeIng.Name), metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete TraefikService: %w", err)
+		}
+
+		return nil
+	}
+
+	if w.traefikClientSet == nil {
+		return errors.New("traffic mirroring requires the Traefik CRDs to be installed")
+	}
+
+	svc := buildMirroringService(edgeIng)
+	if err := drift.Stamp(&svc.ObjectMeta, svc.Spec); err != nil {
+		return fmt.Errorf("stamp TraefikService: %w", err)
+	}
+
+	_, err := kube.Apply(ctx, w.traefikClientSet.TraefikServices(edgeIng.Namespace).Patch, svc.Name, svc)
+	if err != nil {
+		return fmt.Errorf("apply TraefikService: %w", err)
+	}
+
+	log.Debug().
+		Str("name", svc.Name).
+		Str("namespace", svc.Namespace).
+		Msg("TraefikService applied")
+
+	return nil
+}
+
+// syncMiddlewares creates, updates or removes the Middlewares injecting the EdgeIngress headers and filtering its
+// source IPs.
+func (w *Watcher) syncMiddlewares(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress) error {
+	var ipAllowList *traefikv1alpha1.MiddlewareSpec
+	if edgeIng.Spec.IPAllowList != nil {
+		ipAllowList = &traefikv1alpha1.MiddlewareSpec{
+			IPWhiteList: &traefikv1alpha1.IPWhiteList{
+				SourceRange: edgeIng.Spec.IPAllowList.SourceRange,
+				// Requests reach Traefik through the tunnel: the client IP is the one added by the broker to the
+				// X-Forwarded-For header.
+				IPStrategy: &traefikv1alpha1.IPStrategy{Depth: 1},
+			},
+		}
+	}
+	if err := w.syncMiddleware(ctx, edgeIng, getIPAllowListMiddlewareName(edgeIng.Name), ipAllowList); err != nil {
+		return fmt.Errorf("sync IP allow list middleware: %w", err)
+	}
+
+	var headers *traefikv1alpha1.MiddlewareSpec
+	if edgeIng.Spec.Headers != nil {
+		headers = &traefikv1alpha1.MiddlewareSpec{
+			Headers: &traefikv1alpha1.Headers{
+				CustomRequestHeaders:  edgeIng.Spec.Headers.Request,
+				CustomResponseHeaders: edgeIng.Spec.Headers.Response,
+			},
+		}
+	}
+	if err := w.syncMiddleware(ctx, edgeIng, getHeadersMiddlewareName(edgeIng.Name), headers); err != nil {
+		return fmt.Errorf("sync headers middleware: %w", err)
+	}
+
+	return nil
+}
+
+// syncMiddleware creates or updates the named Middleware owned by the EdgeIngress with the given spec, and removes
+// it when the spec is nil.
+func (w *Watcher) syncMiddleware(ctx context.Context, edgeIng *hubv1alpha1.EdgeIngress, name string, spec *traefikv1alpha1.MiddlewareSpec) error {
+	if spec == nil {
+		if w.traefikClientSet == nil {
+			return nil
+		}
+
+		err := w.traefikClientSet.Middlewares(edgeIng.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			return fmt.Errorf("delete middleware: %w", err)
+		}
+
+		return nil
+	}
+
+	if w.traefikClientSet == nil {
+		return errors.New("headers and IP allow lists require the Traefik CRDs to be installed")
+	}
+
+	middleware := buildMiddleware(edgeIng, name, *spec)
+	if err := drift.Stamp(&middleware.ObjectMeta, middleware.Spec); err != nil {
+		return fmt.Errorf("stamp middleware: %w", err)
+	}
+
+	_, err := kube.Apply(ctx, w.traefikClientSet.Middlewares(edgeIng.Namespace).Patch, name, middleware)
+	if err != nil {
+		return fmt.Errorf("apply middleware: %w", err)
+	}
+
+	log.Debug().
+		Str("name", middleware.Name).
+		Str("namespace", middleware.Namespace).
+		Msg("Middleware applied")
+
+	return nil
+}
+
 func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 	if w.traefikClientSet == nil {
 		return nil
 	}
 
 	stripPrefix := &traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "Middleware",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "strip-prefix-catch-all",
 			Namespace: w.config.AgentNamespace,
@@ -331,6 +513,10 @@ func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 	}
 
 	addPrefix := &traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "Middleware",
+		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "add-prefix-catch-all",
 			Namespace: w.config.AgentNamespace,
@@ -340,14 +526,14 @@ func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 		},
 	}
 
-	_, err := w.traefikClientSet.Middlewares(w.config.AgentNamespace).Create(ctx, stripPrefix, metav1.CreateOptions{})
-	if err != nil && !kerror.IsAlreadyExists(err) {
-		return err
+	_, err := kube.Apply(ctx, w.traefikClientSet.Middlewares(w.config.AgentNamespace).Patch, stripPrefix.Name, stripPrefix)
+	if err != nil {
+		return fmt.Errorf("apply strip prefix middleware: %w", err)
 	}
 
-	_, err = w.traefikClientSet.Middlewares(w.config.AgentNamespace).Create(ctx, addPrefix, metav1.CreateOptions{})
-	if err != nil && !kerror.IsAlreadyExists(err) {
-		return err
+	_, err = kube.Apply(ctx, w.traefikClientSet.Middlewares(w.config.AgentNamespace).Patch, addPrefix.Name, addPrefix)
+	if err != nil {
+		return fmt.Errorf("apply add prefix middleware: %w", err)
 	}
 
 	middlewares := fmt.Sprintf("%s-strip-prefix-catch-all@kubernetescrd,%s-add-prefix-catch-all@kubernetescrd",
@@ -406,80 +592,60 @@ func (w *Watcher) createIngressCatchAll(ctx context.Context) error {
 }
 
 func (w *Watcher) upsertSecret(ctx context.Context, cert Certificate, name, namespace string, edgeIngress *hubv1alpha1.EdgeIngress) error {
-	secret, err := w.clientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	existingSecret, err := w.clientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil && !kerror.IsNotFound(err) {
 		return fmt.Errorf("get secret: %w", err)
 	}
 
-	if kerror.IsNotFound(err) {
-		secret = &corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: "core.k8s.io/v1",
-				Kind:       "Secret",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      name,
-				Namespace: namespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/managed-by": "traefik-hub",
-				},
-			},
-			Type: corev1.SecretTypeTLS,
-			Data: map[string][]byte{
-				"tls.crt": cert.Certificate,
-				"tls.key": cert.PrivateKey,
-			},
-		}
-		if edgeIngress != nil {
-			secret.OwnerReferences = []metav1.OwnerReference{{
-				APIVersion: "hub.traefik.io/v1alpha1",
-				Kind:       "EdgeIngress",
-				Name:       edgeIngress.Name,
-				UID:        edgeIngress.UID,
-			}}
-		}
-
-		_, err = w.clientSet.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
-		if err != nil {
-			return fmt.Errorf("create secret: %w", err)
-		}
+	found := err == nil
 
-		log.Debug().
-			Str("name", secret.Name).
-			Str("namespace", secret.Namespace).
-			Msg("Secret created")
-
-		return nil
+	var owners []metav1.OwnerReference
+	if found {
+		owners = existingSecret.OwnerReferences
 	}
-
-	newOwners := secret.OwnerReferences
 	if edgeIngress != nil {
-		newOwners = appendOwnerReference(secret.OwnerReferences, metav1.OwnerReference{
+		owners = appendOwnerReference(owners, metav1.OwnerReference{
 			APIVersion: "hub.traefik.io/v1alpha1",
 			Kind:       "EdgeIngress",
 			Name:       edgeIngress.Name,
 			UID:        edgeIngress.UID,
 		})
 	}
-	if bytes.Equal(secret.Data["tls.crt"], cert.Certificate) && len(secret.OwnerReferences) == len(newOwners) {
+
+	if found && bytes.Equal(existingSecret.Data["tls.crt"], cert.Certificate) && len(existingSecret.OwnerReferences) == len(owners) {
 		return nil
 	}
 
-	secret.Data = map[string][]byte{
-		"tls.crt": cert.Certificate,
-		"tls.key": cert.PrivateKey,
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			// The Secret being shared by the EdgeIngresses, all its owners are applied.
+			OwnerReferences: owners,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": cert.Certificate,
+			"tls.key": cert.PrivateKey,
+		},
 	}
-	secret.OwnerReferences = newOwners
 
-	_, err = w.clientSet.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
+	_, err = kube.Apply(ctx, w.clientSet.CoreV1().Secrets(namespace).Patch, name, secret)
 	if err != nil {
-		return fmt.Errorf("update secret: %w", err)
+		return fmt.Errorf("apply secret: %w", err)
 	}
 
 	log.Debug().
 		Str("name", secret.Name).
 		Str("namespace", secret.Namespace).
-		Msg("Secret updated")
+		Msg("Secret applied")
 
 	return nil
 }
@@ -585,6 +751,18 @@ func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressC
 		annotations[reviewer.AnnotationHubAuth] = edgeIng.Spec.ACP.Name
 	}
 
+	// The ACP middleware, if any, is appended to this list by the ACP admission webhook.
+	var middlewares []string
+	if edgeIng.Spec.IPAllowList != nil {
+		middlewares = append(middlewares, getTraefikMiddlewareName(edgeIng.Namespace, getIPAllowListMiddlewareName(edgeIng.Name)))
+	}
+	if edgeIng.Spec.Headers != nil {
+		middlewares = append(middlewares, getTraefikMiddlewareName(edgeIng.Namespace, getHeadersMiddlewareName(edgeIng.Name)))
+	}
+	if len(middlewares) > 0 {
+		annotations["traefik.ingress.kubernetes.io/router.middlewares"] = strings.Join(middlewares, ",")
+	}
+
 	ing.ObjectMeta = metav1.ObjectMeta{
 		Name:        edgeIng.Name,
 		Namespace:   edgeIng.Namespace,
@@ -603,6 +781,18 @@ func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressC
 		},
 	}
 
+	// With a blue/green deployment, switching the active release only changes the backend of the Ingress which
+	// Traefik applies instantly.
+	service := edgeIng.Spec.ActiveService()
+	backend := netv1.IngressBackend{
+		Service: &netv1.IngressServiceBackend{
+			Name: service.Name,
+			Port: netv1.ServiceBackendPort{
+				Number: int32(service.Port),
+			},
+		},
+	}
+
 	// No secret is needed for TLS because we will use the wildcard certificate configured in the catch-all ingress.
 	pathType := netv1.PathTypePrefix
 	IngressRule := netv1.IngressRuleValue{
@@ -611,14 +801,7 @@ func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressC
 				{
 					Path:     "/",
 					PathType: &pathType,
-					Backend: netv1.IngressBackend{
-						Service: &netv1.IngressServiceBackend{
-							Name: edgeIng.Spec.Service.Name,
-							Port: netv1.ServiceBackendPort{
-								Number: int32(edgeIng.Spec.Service.Port),
-							},
-						},
-					},
+					Backend:  backend,
 				},
 			},
 		},
@@ -657,3 +840,170 @@ func buildIngress(edgeIng *hubv1alpha1.EdgeIngress, ing *netv1.Ingress, ingressC
 
 	return ing
 }
+
+// buildIngressRoute builds the IngressRoute routing the traffic of the EdgeIngress to its mirroring TraefikService.
+func buildIngressRoute(edgeIng *hubv1alpha1.EdgeIngress, entryPoint string, customDomains []string) *traefikv1alpha1.IngressRoute {
+	annotations := map[string]string{}
+
+	var middlewares []traefikv1alpha1.MiddlewareRef
+	if edgeIng.Spec.IPAllowList != nil {
+		middlewares = append(middlewares, traefikv1alpha1.MiddlewareRef{Name: getIPAllowListMiddlewareName(edgeIng.Name)})
+	}
+	if edgeIng.Spec.Headers != nil {
+		middlewares = append(middlewares, traefikv1alpha1.MiddlewareRef{Name: getHeadersMiddlewareName(edgeIng.Name)})
+	}
+
+	if edgeIng.Spec.ACP != nil && edgeIng.Spec.ACP.Name != "" {
+		annotations[reviewer.AnnotationHubAuth] = edgeIng.Spec.ACP.Name
+
+		// The ACP middleware is referenced as the ACP admission webhook does, for the generated spec, checked for
+		// drift, to be the one stored. The webhook still sets up the middleware itself.
+		middlewares = append(middlewares, traefikv1alpha1.MiddlewareRef{
+			Name:      reviewer.MiddlewareName(edgeIng.Spec.ACP.Name),
+			Namespace: edgeIng.Namespace,
+		})
+	}
+
+	hosts := make([]string, 0, len(customDomains)+1)
+	for _, host := range append([]string{edgeIng.Status.Domain}, customDomains...) {
+		hosts = append(hosts, "Host(`"+host+"`)")
+	}
+
+	// The wildcard certificate is served for the Hub domain by the catch-all ingress, only the certificate of the
+	// custom domains, if any, must be referenced.
+	tlsSecretName := secretName
+	if len(customDomains) > 0 {
+		tlsSecretName = secretCustomDomainsName + "-" + edgeIng.Name
+	}
+
+	return &traefikv1alpha1.IngressRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "IngressRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        edgeIng.Name,
+			Namespace:   edgeIng.Namespace,
+			Annotations: annotations,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "hub.traefik.io/v1alpha1",
+					Kind:       "EdgeIngress",
+					Name:       edgeIng.Name,
+					UID:        edgeIng.UID,
+				},
+			},
+		},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			EntryPoints: []string{entryPoint},
+			Routes: []traefikv1alpha1.Route{
+				{
+					Match:       strings.Join(hosts, " || "),
+					Kind:        "Rule",
+					Middlewares: middlewares,
+					Services: []traefikv1alpha1.Service{
+						{
+							LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+								Name: getMirroringServiceName(edgeIng.Name),
+								Kind: "TraefikService",
+							},
+						},
+					},
+				},
+			},
+			TLS: &traefikv1alpha1.TLS{
+				SecretName: tlsSecretName,
+			},
+		},
+	}
+}
+
+func buildMirroringService(edgeIng *hubv1alpha1.EdgeIngress) *traefikv1alpha1.TraefikService {
+	service := edgeIng.Spec.ActiveService()
+
+	return &traefikv1alpha1.TraefikService{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "TraefikService",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getMirroringServiceName(edgeIng.Name),
+			Namespace: edgeIng.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "hub.traefik.io/v1alpha1",
+					Kind:       "EdgeIngress",
+					Name:       edgeIng.Name,
+					UID:        edgeIng.UID,
+				},
+			},
+		},
+		Spec: traefikv1alpha1.ServiceSpec{
+			Mirroring: &traefikv1alpha1.Mirroring{
+				LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+					Name: service.Name,
+					Kind: "Service",
+					Port: intstr.FromInt(service.Port),
+				},
+				Mirrors: []traefikv1alpha1.MirrorService{
+					{
+						LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+							Name: edgeIng.Spec.Mirror.Name,
+							Kind: "Service",
+							Port: intstr.FromInt(edgeIng.Spec.Mirror.Port),
+						},
+						Percent: edgeIng.Spec.Mirror.Percent,
+					},
+				},
+			},
+		},
+	}
+}
+
+func getMirroringServiceName(edgeIngName string) string {
+	return edgeIngName + "-mirroring"
+}
+
+func buildMiddleware(edgeIng *hubv1alpha1.EdgeIngress, name string, spec traefikv1alpha1.MiddlewareSpec) *traefikv1alpha1.Middleware {
+	return &traefikv1alpha1.Middleware{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "traefik.containo.us/v1alpha1",
+			Kind:       "Middleware",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: edgeIng.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "traefik-hub",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "hub.traefik.io/v1alpha1",
+					Kind:       "EdgeIngress",
+					Name:       edgeIng.Name,
+					UID:        edgeIng.UID,
+				},
+			},
+		},
+		Spec: spec,
+	}
+}
+
+func getHeadersMiddlewareName(edgeIngName string) string {
+	return edgeIngName + "-headers"
+}
+
+func getIPAllowListMiddlewareName(edgeIngName string) string {
+	return edgeIngName + "-ip-allowlist"
+}
+
+// getTraefikMiddlewareName returns the name under which Traefik references a Middleware from the kubernetescrd provider.
+func getTraefikMiddlewareName(namespace, name string) string {
+	return namespace + "-" + name + "@kubernetescrd"
+}
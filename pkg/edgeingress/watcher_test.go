@@ -22,16 +22,22 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/drift"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
 	netv1 "k8s.io/api/networking/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	kubemock "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/utils/pointer"
@@ -87,6 +93,7 @@ var toDelete = hubv1alpha1.EdgeIngress{
 func Test_WatcherRun(t *testing.T) {
 	clientSetHub := hubkubemock.NewSimpleClientset([]runtime.Object{&toUpdate, &toDelete}...)
 	clientSet := kubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(clientSet)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	hubInformer := hubinformer.NewSharedInformerFactory(clientSetHub, 0)
@@ -137,6 +144,7 @@ func Test_WatcherRun(t *testing.T) {
 		})
 
 	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
 
 	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
 		IngressClassName:        "traefik-hub",
@@ -145,7 +153,7 @@ func Test_WatcherRun(t *testing.T) {
 		EdgeIngressSyncInterval: time.Millisecond,
 		CertRetryInterval:       time.Millisecond,
 		CertSyncInterval:        time.Millisecond,
-	})
+	}, zerolog.Nop())
 
 	require.NoError(t, err)
 
@@ -253,6 +261,7 @@ func Test_WatcherRun(t *testing.T) {
 func Test_WatcherRun_appends_owner_reference(t *testing.T) {
 	clientSetHub := hubkubemock.NewSimpleClientset()
 	clientSet := kubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(clientSet)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	hubInformer := hubinformer.NewSharedInformerFactory(clientSetHub, 0)
@@ -298,6 +307,7 @@ func Test_WatcherRun_appends_owner_reference(t *testing.T) {
 		})
 
 	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
 
 	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
 		IngressClassName:        "traefik-hub",
@@ -306,7 +316,7 @@ func Test_WatcherRun_appends_owner_reference(t *testing.T) {
 		EdgeIngressSyncInterval: time.Millisecond,
 		CertRetryInterval:       time.Millisecond,
 		CertSyncInterval:        time.Millisecond,
-	})
+	}, zerolog.Nop())
 
 	require.NoError(t, err)
 
@@ -343,6 +353,7 @@ func Test_WatcherRun_appends_owner_reference(t *testing.T) {
 func Test_WatcherRun_handle_custom_domains(t *testing.T) {
 	clientSetHub := hubkubemock.NewSimpleClientset(&toUpdate)
 	clientSet := kubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(clientSet)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	hubInformer := hubinformer.NewSharedInformerFactory(clientSetHub, 0)
@@ -396,6 +407,7 @@ func Test_WatcherRun_handle_custom_domains(t *testing.T) {
 		})
 
 	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
 
 	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
 		IngressClassName:        "traefik-hub",
@@ -404,7 +416,7 @@ func Test_WatcherRun_handle_custom_domains(t *testing.T) {
 		EdgeIngressSyncInterval: time.Millisecond,
 		CertRetryInterval:       time.Millisecond,
 		CertSyncInterval:        time.Millisecond,
-	})
+	}, zerolog.Nop())
 	require.NoError(t, err)
 
 	stop := make(chan struct{})
@@ -543,9 +555,322 @@ func Test_WatcherRun_handle_custom_domains(t *testing.T) {
 	}, ing.Spec)
 }
 
+func Test_WatcherRun_handle_mirror(t *testing.T) {
+	clientSetHub := hubkubemock.NewSimpleClientset()
+	clientSet := kubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(clientSet)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformer.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	edgeIngresses := []EdgeIngress{
+		{
+			Name:      "toCreate",
+			Namespace: "default",
+			Domain:    "majestic-beaver-123.hub-traefik.io",
+			Version:   "version-1",
+			Service:   Service{Name: "service-1", Port: 8080},
+			Mirror:    &Mirror{Name: "service-1-staging", Port: 8081, Percent: 20},
+			ACP:       &ACP{Name: "acp-name"},
+		},
+	}
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns(edgeIngresses, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	ctx = context.Background()
+	edgeIng, err := clientSetHub.HubV1alpha1().EdgeIngresses("default").Get(ctx, "toCreate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, &hubv1alpha1.EdgeIngressMirror{Name: "service-1-staging", Port: 8081, Percent: 20}, edgeIng.Spec.Mirror)
+
+	svc, err := traefikClientSet.TraefikV1alpha1().TraefikServices("default").Get(ctx, "toCreate-mirroring", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []metav1.OwnerReference{
+		{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "EdgeIngress",
+			Name:       edgeIng.Name,
+			UID:        edgeIng.UID,
+		},
+	}, svc.OwnerReferences)
+	assert.Equal(t, traefikv1alpha1.ServiceSpec{
+		Mirroring: &traefikv1alpha1.Mirroring{
+			LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+				Name: "service-1",
+				Kind: "Service",
+				Port: intstr.FromInt(8080),
+			},
+			Mirrors: []traefikv1alpha1.MirrorService{
+				{
+					LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+						Name: "service-1-staging",
+						Kind: "Service",
+						Port: intstr.FromInt(8081),
+					},
+					Percent: 20,
+				},
+			},
+		},
+	}, svc.Spec)
+	assertStamped(t, svc.ObjectMeta, svc.Spec)
+
+	// The Ingress provider of Traefik can't reference a TraefikService, the traffic is routed by an IngressRoute.
+	_, err = clientSet.NetworkingV1().Ingresses("default").Get(ctx, "toCreate", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+
+	ingRoute, err := traefikClientSet.TraefikV1alpha1().IngressRoutes("default").Get(ctx, "toCreate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, traefikv1alpha1.IngressRouteSpec{
+		EntryPoints: []string{"traefikhub-tunl"},
+		Routes: []traefikv1alpha1.Route{
+			{
+				Match: "Host(`majestic-beaver-123.hub-traefik.io`)",
+				Kind:  "Rule",
+				// The ACP middleware is referenced as the ACP admission webhook does.
+				Middlewares: []traefikv1alpha1.MiddlewareRef{{Name: "zz-acp-name", Namespace: "default"}},
+				Services: []traefikv1alpha1.Service{
+					{
+						LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{
+							Name: "toCreate-mirroring",
+							Kind: "TraefikService",
+						},
+					},
+				},
+			},
+		},
+		TLS: &traefikv1alpha1.TLS{SecretName: secretName},
+	}, ingRoute.Spec)
+	assertStamped(t, ingRoute.ObjectMeta, ingRoute.Spec)
+}
+
+// assertStamped asserts the resource having the given metadata and spec is checked for drift, and hasn't drifted.
+func assertStamped(t *testing.T, meta metav1.ObjectMeta, spec interface{}) {
+	t.Helper()
+
+	assert.True(t, drift.IsStamped(meta))
+
+	drifted, err := drift.Drifted(meta, spec)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+}
+
+func Test_WatcherRun_handle_headers_and_ip_allow_list(t *testing.T) {
+	clientSetHub := hubkubemock.NewSimpleClientset()
+	clientSet := kubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(clientSet)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hubInformer := hubinformer.NewSharedInformerFactory(clientSetHub, 0)
+
+	edgeIngressInformer := hubInformer.Hub().V1alpha1().EdgeIngresses().Informer()
+
+	hubInformer.Start(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), edgeIngressInformer.HasSynced)
+
+	edgeIngresses := []EdgeIngress{
+		{
+			Name:      "toCreate",
+			Namespace: "default",
+			Domain:    "majestic-beaver-123.hub-traefik.io",
+			Version:   "version-1",
+			Service:   Service{Name: "service-1", Port: 8080},
+			Headers: &Headers{
+				Request:  map[string]string{"X-Env": "production"},
+				Response: map[string]string{"Server": ""},
+			},
+			IPAllowList: &IPAllowList{SourceRange: []string{"10.0.0.0/8", "192.168.1.1"}},
+		},
+	}
+
+	client := newPlatformClientMock(t)
+	client.OnGetWildcardCertificate().TypedReturns(Certificate{
+		Certificate: []byte("cert"),
+		PrivateKey:  []byte("private"),
+	}, nil)
+
+	var callCount int
+	client.OnGetEdgeIngresses().
+		TypedReturns(edgeIngresses, nil).
+		Run(func(_ mock.Arguments) {
+			callCount++
+			if callCount > 1 {
+				cancel()
+			}
+		})
+
+	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
+
+	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
+		IngressClassName:        "traefik-hub",
+		TraefikTunnelEntryPoint: "traefikhub-tunl",
+		AgentNamespace:          "hub-agent",
+		EdgeIngressSyncInterval: time.Millisecond,
+		CertRetryInterval:       time.Millisecond,
+		CertSyncInterval:        time.Millisecond,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(stop)
+	}()
+
+	<-stop
+
+	ctx = context.Background()
+	edgeIng, err := clientSetHub.HubV1alpha1().EdgeIngresses("default").Get(ctx, "toCreate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, &hubv1alpha1.EdgeIngressHeaders{
+		Request:  map[string]string{"X-Env": "production"},
+		Response: map[string]string{"Server": ""},
+	}, edgeIng.Spec.Headers)
+	assert.Equal(t, &hubv1alpha1.EdgeIngressIPAllowList{SourceRange: []string{"10.0.0.0/8", "192.168.1.1"}}, edgeIng.Spec.IPAllowList)
+
+	ipAllowList, err := traefikClientSet.TraefikV1alpha1().Middlewares("default").Get(ctx, "toCreate-ip-allowlist", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []metav1.OwnerReference{
+		{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "EdgeIngress",
+			Name:       edgeIng.Name,
+			UID:        edgeIng.UID,
+		},
+	}, ipAllowList.OwnerReferences)
+	assert.Equal(t, traefikv1alpha1.MiddlewareSpec{
+		IPWhiteList: &traefikv1alpha1.IPWhiteList{
+			SourceRange: []string{"10.0.0.0/8", "192.168.1.1"},
+			IPStrategy:  &traefikv1alpha1.IPStrategy{Depth: 1},
+		},
+	}, ipAllowList.Spec)
+
+	headers, err := traefikClientSet.TraefikV1alpha1().Middlewares("default").Get(ctx, "toCreate-headers", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, traefikv1alpha1.MiddlewareSpec{
+		Headers: &traefikv1alpha1.Headers{
+			CustomRequestHeaders:  map[string]string{"X-Env": "production"},
+			CustomResponseHeaders: map[string]string{"Server": ""},
+		},
+	}, headers.Spec)
+
+	ing, err := clientSet.NetworkingV1().Ingresses("default").Get(ctx, "toCreate", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "default-toCreate-ip-allowlist@kubernetescrd,default-toCreate-headers@kubernetescrd",
+		ing.Annotations["traefik.ingress.kubernetes.io/router.middlewares"])
+}
+
+func Test_buildIngress_blueGreen(t *testing.T) {
+	tests := []struct {
+		desc        string
+		blueGreen   *hubv1alpha1.EdgeIngressBlueGreen
+		wantService string
+		wantPort    int32
+	}{
+		{
+			desc:        "no blue/green deployment",
+			wantService: "service",
+			wantPort:    80,
+		},
+		{
+			desc: "blue release active",
+			blueGreen: &hubv1alpha1.EdgeIngressBlueGreen{
+				Blue:   hubv1alpha1.EdgeIngressService{Name: "service-blue", Port: 8080},
+				Green:  hubv1alpha1.EdgeIngressService{Name: "service-green", Port: 8081},
+				Active: hubv1alpha1.BlueGreenBlue,
+			},
+			wantService: "service-blue",
+			wantPort:    8080,
+		},
+		{
+			desc: "green release active",
+			blueGreen: &hubv1alpha1.EdgeIngressBlueGreen{
+				Blue:   hubv1alpha1.EdgeIngressService{Name: "service-blue", Port: 8080},
+				Green:  hubv1alpha1.EdgeIngressService{Name: "service-green", Port: 8081},
+				Active: hubv1alpha1.BlueGreenGreen,
+			},
+			wantService: "service-green",
+			wantPort:    8081,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			edgeIng := &hubv1alpha1.EdgeIngress{
+				ObjectMeta: metav1.ObjectMeta{Name: "edge-ingress", Namespace: "default"},
+				Spec: hubv1alpha1.EdgeIngressSpec{
+					Service:   hubv1alpha1.EdgeIngressService{Name: "service", Port: 80},
+					BlueGreen: test.blueGreen,
+				},
+				Status: hubv1alpha1.EdgeIngressStatus{Domain: "majestic-beaver-123.hub-traefik.io"},
+			}
+
+			ing := buildIngress(edgeIng, &netv1.Ingress{}, "traefik-hub", "traefikhub-tunl", nil)
+
+			require.Len(t, ing.Spec.Rules, 1)
+			assert.Equal(t, netv1.IngressBackend{
+				Service: &netv1.IngressServiceBackend{
+					Name: test.wantService,
+					Port: netv1.ServiceBackendPort{Number: test.wantPort},
+				},
+			}, ing.Spec.Rules[0].HTTP.Paths[0].Backend)
+		})
+	}
+}
+
 func Test_WatcherRun_sync_certificates(t *testing.T) {
 	clientSetHub := hubkubemock.NewSimpleClientset()
 	clientSet := kubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(clientSet)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	hubInformer := hubinformer.NewSharedInformerFactory(clientSetHub, 0)
@@ -627,6 +952,7 @@ func Test_WatcherRun_sync_certificates(t *testing.T) {
 		TypedReturns(edgeIngresses, nil).Parent
 
 	traefikClientSet := traefikkubemock.NewSimpleClientset()
+	kubetest.AddApplyReactor(traefikClientSet)
 
 	w, err := NewWatcher(client, clientSetHub, clientSet, traefikClientSet.TraefikV1alpha1(), hubInformer, WatcherConfig{
 		IngressClassName:        "traefik-hub",
@@ -635,7 +961,7 @@ func Test_WatcherRun_sync_certificates(t *testing.T) {
 		EdgeIngressSyncInterval: time.Millisecond,
 		CertRetryInterval:       time.Millisecond,
 		CertSyncInterval:        time.Millisecond,
-	})
+	}, zerolog.Nop())
 
 	require.NoError(t, err)
 
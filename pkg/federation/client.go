@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package federation
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	federationv1 "github.com/traefik/hub-agent-kubernetes/pkg/proto/federation/v1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// ClientConfig configures a federation client.
+type ClientConfig struct {
+	// Addr is the address of the federation server.
+	Addr string
+	// Token authenticates the agent, and identifies its cluster, on the federation server.
+	Token string
+	// Insecure disables TLS. It must only be used when the connection to the federation server is otherwise secured.
+	Insecure bool
+	// ResendInterval is the interval at which the last reports are sent again, for the federation server to get them
+	// back after a restart and not to consider the cluster gone.
+	ResendInterval time.Duration
+}
+
+// Client reports the topology and the portal catalog of the cluster to a federation server. Reports equal to the last
+// one sent are only sent again periodically.
+type Client struct {
+	conn           *grpc.ClientConn
+	client         federationv1.FederationClient
+	resendInterval time.Duration
+
+	lastMu       sync.Mutex
+	lastTopology *federationv1.ReportTopologyRequest
+	lastCatalog  *federationv1.ReportCatalogRequest
+}
+
+// NewClient creates a new Client. The connection to the federation server is established lazily.
+func NewClient(cfg ClientConfig, opts ...grpc.DialOption) (*Client, error) {
+	transportCreds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if cfg.Insecure {
+		transportCreds = insecure.NewCredentials()
+	}
+
+	opts = append(opts,
+		grpc.WithTransportCredentials(transportCreds),
+		grpc.WithPerRPCCredentials(tokenCredentials{token: cfg.Token, secure: !cfg.Insecure}),
+	)
+
+	conn, err := grpc.Dial(cfg.Addr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial federation server: %w", err)
+	}
+
+	return &Client{
+		conn:           conn,
+		client:         federationv1.NewFederationClient(conn),
+		resendInterval: cfg.ResendInterval,
+	}, nil
+}
+
+// ReportTopology reports the topology of the cluster.
+func (c *Client) ReportTopology(ctx context.Context, topology *state.Cluster) error {
+	pbTopology, err := TopologyToProto(topology)
+	if err != nil {
+		return err
+	}
+
+	req := &federationv1.ReportTopologyRequest{Topology: pbTopology}
+
+	c.lastMu.Lock()
+	unchanged := proto.Equal(c.lastTopology, req)
+	c.lastMu.Unlock()
+
+	if unchanged {
+		return nil
+	}
+
+	if _, err = c.client.ReportTopology(ctx, req); err != nil {
+		return fmt.Errorf("send topology report: %w", err)
+	}
+
+	c.lastMu.Lock()
+	c.lastTopology = req
+	c.lastMu.Unlock()
+
+	return nil
+}
+
+// ReportCatalog reports the portals of the cluster.
+func (c *Client) ReportCatalog(ctx context.Context, portals []Portal) error {
+	req := &federationv1.ReportCatalogRequest{Portals: portalsToProto(portals)}
+
+	c.lastMu.Lock()
+	unchanged := proto.Equal(c.lastCatalog, req)
+	c.lastMu.Unlock()
+
+	if unchanged {
+		return nil
+	}
+
+	if _, err := c.client.ReportCatalog(ctx, req); err != nil {
+		return fmt.Errorf("send catalog report: %w", err)
+	}
+
+	c.lastMu.Lock()
+	c.lastCatalog = req
+	c.lastMu.Unlock()
+
+	return nil
+}
+
+// Run sends again the last reports periodically, until the given context is canceled.
+func (c *Client) Run(ctx context.Context) {
+	t := time.NewTicker(c.resendInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping federation client")
+			return
+
+		case <-t.C:
+			c.lastMu.Lock()
+			lastTopology, lastCatalog := c.lastTopology, c.lastCatalog
+			c.lastMu.Unlock()
+
+			if lastTopology != nil {
+				ctxSend, cancel := context.WithTimeout(ctx, 10*time.Second)
+				if _, err := c.client.ReportTopology(ctxSend, lastTopology); err != nil {
+					log.Error().Err(err).Msg("Unable to send topology report to the federation server")
+				}
+				cancel()
+			}
+
+			if lastCatalog != nil {
+				ctxSend, cancel := context.WithTimeout(ctx, 10*time.Second)
+				if _, err := c.client.ReportCatalog(ctxSend, lastCatalog); err != nil {
+					log.Error().Err(err).Msg("Unable to send catalog report to the federation server")
+				}
+				cancel()
+			}
+		}
+	}
+}
+
+// Close closes the connection to the federation server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// tokenCredentials authenticates the requests with a bearer token.
+type tokenCredentials struct {
+	token  string
+	secure bool
+}
+
+func (c tokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package federation lets the agents of several clusters report their topology and portal catalog to a federation
+// server, which merges the catalogs of all the clusters.
+//
+// Agents and the federation server communicate over the traefik.hub.federation.v1.Federation gRPC service, described
+// in pkg/proto/federation/v1/federation.proto.
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	federationv1 "github.com/traefik/hub-agent-kubernetes/pkg/proto/federation/v1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Portal is a portal of a cluster, with the APIs published on it.
+type Portal struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	URLs        string `json:"urls,omitempty"`
+	APIs        []API  `json:"apis"`
+}
+
+// API is an API published on a portal.
+type API struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	Collection string `json:"collection,omitempty"`
+	PathPrefix string `json:"pathPrefix"`
+	BaseURL    string `json:"baseUrl"`
+}
+
+// ClusterPortal is a portal of the merged catalog, with the cluster it comes from.
+type ClusterPortal struct {
+	Portal
+
+	Cluster    string    `json:"cluster"`
+	ReportedAt time.Time `json:"reportedAt"`
+}
+
+func portalsToProto(portals []Portal) []*federationv1.Portal {
+	pbPortals := make([]*federationv1.Portal, 0, len(portals))
+	for _, portal := range portals {
+		pbPortal := &federationv1.Portal{
+			Name:        portal.Name,
+			Title:       portal.Title,
+			Description: portal.Description,
+			Urls:        portal.URLs,
+		}
+		for _, api := range portal.APIs {
+			pbPortal.Apis = append(pbPortal.Apis, &federationv1.API{
+				Name:       api.Name,
+				Namespace:  api.Namespace,
+				Collection: api.Collection,
+				PathPrefix: api.PathPrefix,
+				BaseUrl:    api.BaseURL,
+			})
+		}
+
+		pbPortals = append(pbPortals, pbPortal)
+	}
+
+	return pbPortals
+}
+
+func portalsFromProto(pbPortals []*federationv1.Portal) []Portal {
+	portals := make([]Portal, 0, len(pbPortals))
+	for _, pbPortal := range pbPortals {
+		portal := Portal{
+			Name:        pbPortal.GetName(),
+			Title:       pbPortal.GetTitle(),
+			Description: pbPortal.GetDescription(),
+			URLs:        pbPortal.GetUrls(),
+		}
+		for _, pbAPI := range pbPortal.GetApis() {
+			portal.APIs = append(portal.APIs, API{
+				Name:       pbAPI.GetName(),
+				Namespace:  pbAPI.GetNamespace(),
+				Collection: pbAPI.GetCollection(),
+				PathPrefix: pbAPI.GetPathPrefix(),
+				BaseURL:    pbAPI.GetBaseUrl(),
+			})
+		}
+
+		portals = append(portals, portal)
+	}
+
+	return portals
+}
+
+// TopologyToProto returns the JSON representation of the given topology as a protobuf Struct.
+func TopologyToProto(topology *state.Cluster) (*structpb.Struct, error) {
+	data, err := json.Marshal(topology)
+	if err != nil {
+		return nil, fmt.Errorf("marshal topology: %w", err)
+	}
+
+	var pbTopology structpb.Struct
+	if err = protojson.Unmarshal(data, &pbTopology); err != nil {
+		return nil, fmt.Errorf("unmarshal topology struct: %w", err)
+	}
+
+	return &pbTopology, nil
+}
+
+// TopologyFromProto returns the topology represented by the given protobuf Struct.
+func TopologyFromProto(pbTopology *structpb.Struct) (*state.Cluster, error) {
+	data, err := protojson.Marshal(pbTopology)
+	if err != nil {
+		return nil, fmt.Errorf("marshal topology struct: %w", err)
+	}
+
+	var topology state.Cluster
+	if err = json.Unmarshal(data, &topology); err != nil {
+		return nil, fmt.Errorf("unmarshal topology: %w", err)
+	}
+
+	return &topology, nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestFederation(t *testing.T) {
+	server := NewServer(ServerConfig{
+		Tokens: map[string]string{
+			"eu": "eu-token",
+			"us": "us-token",
+		},
+		Expiration: time.Minute,
+	})
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.now = func() time.Time { return now }
+
+	dial := startServer(t, server)
+
+	euClient := newTestClient(t, dial, "eu-token")
+	usClient := newTestClient(t, dial, "us-token")
+
+	ctx := context.Background()
+
+	topology := &state.Cluster{
+		Services: map[string]*state.Service{"whoami@default": {Name: "whoami", Namespace: "default"}},
+	}
+	err := euClient.ReportTopology(ctx, topology)
+	require.NoError(t, err)
+
+	euPortal := Portal{
+		Name: "portal",
+		URLs: "https://portal.eu.example.com",
+		APIs: []API{{Name: "books", Namespace: "default", PathPrefix: "/books", BaseURL: "https://api.eu.example.com/books"}},
+	}
+	err = euClient.ReportCatalog(ctx, []Portal{euPortal})
+	require.NoError(t, err)
+
+	usPortal := Portal{
+		Name: "portal",
+		URLs: "https://portal.us.example.com",
+		APIs: []API{{Name: "authors", Namespace: "default", PathPrefix: "/authors", BaseURL: "https://api.us.example.com/authors"}},
+	}
+	err = usClient.ReportCatalog(ctx, []Portal{usPortal})
+	require.NoError(t, err)
+
+	assert.Equal(t, []ClusterPortal{
+		{Portal: euPortal, Cluster: "eu", ReportedAt: now},
+		{Portal: usPortal, Cluster: "us", ReportedAt: now},
+	}, server.Catalog())
+
+	got, ok := server.Topology("eu")
+	require.True(t, ok)
+	assert.Equal(t, topology, got)
+
+	_, ok = server.Topology("us")
+	assert.False(t, ok)
+
+	// The merged catalog is served over HTTP.
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/catalog", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var catalog struct {
+		Portals []ClusterPortal `json:"portals"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&catalog))
+	assert.Len(t, catalog.Portals, 2)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/clusters/eu/topology", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/clusters/us/topology", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Reports of the clusters which stopped reporting expire.
+	now = now.Add(2 * time.Minute)
+	err = usClient.ReportCatalog(ctx, []Portal{usPortal, {Name: "other"}})
+	require.NoError(t, err)
+
+	catalogPortals := server.Catalog()
+	require.Len(t, catalogPortals, 2)
+	assert.Equal(t, "us", catalogPortals[0].Cluster)
+	assert.Equal(t, "us", catalogPortals[1].Cluster)
+
+	_, ok = server.Topology("eu")
+	assert.False(t, ok)
+}
+
+func TestFederation_unauthenticated(t *testing.T) {
+	server := NewServer(ServerConfig{Tokens: map[string]string{"eu": "eu-token"}})
+	dial := startServer(t, server)
+
+	for _, token := range []string{"", "invalid"} {
+		client := newTestClient(t, dial, token)
+
+		err := client.ReportCatalog(context.Background(), []Portal{{Name: "portal"}})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+
+	assert.Empty(t, server.Catalog())
+}
+
+func TestClient_report_unchanged(t *testing.T) {
+	server := NewServer(ServerConfig{Tokens: map[string]string{"eu": "eu-token"}})
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.now = func() time.Time { return now }
+
+	client := newTestClient(t, startServer(t, server), "eu-token")
+	client.resendInterval = time.Millisecond
+
+	ctx := context.Background()
+	err := client.ReportCatalog(ctx, []Portal{{Name: "portal"}})
+	require.NoError(t, err)
+
+	// Unchanged reports are not sent again.
+	now = now.Add(time.Minute)
+	err = client.ReportCatalog(ctx, []Portal{{Name: "portal"}})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Minute), server.Catalog()[0].ReportedAt)
+
+	// Unless periodically.
+	runCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	go client.Run(runCtx)
+
+	assert.Eventually(t, func() bool {
+		return server.Catalog()[0].ReportedAt.Equal(now)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func startServer(t *testing.T, server *Server) func(context.Context, string) (net.Conn, error) {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := server.NewGRPCServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+}
+
+func newTestClient(t *testing.T, dial func(context.Context, string) (net.Conn, error), token string) *Client {
+	t.Helper()
+
+	client, err := NewClient(ClientConfig{
+		Addr:           "bufnet",
+		Token:          token,
+		Insecure:       true,
+		ResendInterval: time.Minute,
+	}, grpc.WithContextDialer(dial))
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
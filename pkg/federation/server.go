@@ -0,0 +1,258 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package federation
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	federationv1 "github.com/traefik/hub-agent-kubernetes/pkg/proto/federation/v1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServerConfig configures a federation server.
+type ServerConfig struct {
+	// Tokens are the tokens authenticating the agents, indexed by the name of their cluster.
+	Tokens map[string]string
+	// Expiration is the duration after which the reports of a cluster which stopped reporting are dropped. Reports
+	// never expire when zero.
+	Expiration time.Duration
+}
+
+// clusterReports holds the last reports of a cluster.
+type clusterReports struct {
+	topology           *state.Cluster
+	topologyReportedAt time.Time
+
+	portals           []Portal
+	catalogReportedAt time.Time
+}
+
+// Server receives the topology and catalog reports of the agents and merges the catalogs of their clusters.
+type Server struct {
+	federationv1.UnimplementedFederationServer
+
+	tokens     map[string]string
+	expiration time.Duration
+	now        func() time.Time
+
+	clustersMu sync.RWMutex
+	clusters   map[string]*clusterReports
+}
+
+// NewServer creates a new Server.
+func NewServer(cfg ServerConfig) *Server {
+	return &Server{
+		tokens:     cfg.Tokens,
+		expiration: cfg.Expiration,
+		now:        time.Now,
+		clusters:   make(map[string]*clusterReports),
+	}
+}
+
+// NewGRPCServer returns a gRPC server serving the federation service with the given options.
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.UnaryInterceptor(s.authenticate))
+
+	srv := grpc.NewServer(opts...)
+	federationv1.RegisterFederationServer(srv, s)
+
+	return srv
+}
+
+// ReportTopology stores the topology reported by the agent of a cluster.
+func (s *Server) ReportTopology(ctx context.Context, req *federationv1.ReportTopologyRequest) (*federationv1.ReportTopologyResponse, error) {
+	cluster := clusterFromContext(ctx)
+
+	topology, err := TopologyFromProto(req.GetTopology())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid topology: %v", err)
+	}
+
+	s.clustersMu.Lock()
+	defer s.clustersMu.Unlock()
+
+	reports := s.clusterReports(cluster)
+	reports.topology = topology
+	reports.topologyReportedAt = s.now()
+
+	log.Debug().Str("cluster", cluster).Msg("Topology reported")
+
+	return &federationv1.ReportTopologyResponse{}, nil
+}
+
+// ReportCatalog stores the portals reported by the agent of a cluster.
+func (s *Server) ReportCatalog(ctx context.Context, req *federationv1.ReportCatalogRequest) (*federationv1.ReportCatalogResponse, error) {
+	cluster := clusterFromContext(ctx)
+	portals := portalsFromProto(req.GetPortals())
+
+	s.clustersMu.Lock()
+	defer s.clustersMu.Unlock()
+
+	reports := s.clusterReports(cluster)
+	reports.portals = portals
+	reports.catalogReportedAt = s.now()
+
+	log.Debug().Str("cluster", cluster).Int("portals", len(portals)).Msg("Catalog reported")
+
+	return &federationv1.ReportCatalogResponse{}, nil
+}
+
+// Catalog returns the portals of all the clusters, sorted by cluster and name.
+func (s *Server) Catalog() []ClusterPortal {
+	s.clustersMu.RLock()
+	defer s.clustersMu.RUnlock()
+
+	portals := make([]ClusterPortal, 0)
+	for cluster, reports := range s.clusters {
+		if s.expired(reports.catalogReportedAt) {
+			continue
+		}
+
+		for _, portal := range reports.portals {
+			portals = append(portals, ClusterPortal{
+				Portal:     portal,
+				Cluster:    cluster,
+				ReportedAt: reports.catalogReportedAt,
+			})
+		}
+	}
+
+	sort.Slice(portals, func(i, j int) bool {
+		if portals[i].Cluster != portals[j].Cluster {
+			return portals[i].Cluster < portals[j].Cluster
+		}
+		return portals[i].Name < portals[j].Name
+	})
+
+	return portals
+}
+
+// Topology returns the last topology reported by the given cluster.
+func (s *Server) Topology(cluster string) (*state.Cluster, bool) {
+	s.clustersMu.RLock()
+	defer s.clustersMu.RUnlock()
+
+	reports, ok := s.clusters[cluster]
+	if !ok || reports.topology == nil || s.expired(reports.topologyReportedAt) {
+		return nil, false
+	}
+
+	return reports.topology, true
+}
+
+// Handler returns an HTTP handler serving the merged catalog on "/catalog" and the topology of the clusters on
+// "/clusters/{cluster}/topology".
+func (s *Server) Handler() http.Handler {
+	router := chi.NewRouter()
+
+	router.Get("/catalog", func(rw http.ResponseWriter, req *http.Request) {
+		writeJSON(rw, struct {
+			Portals []ClusterPortal `json:"portals"`
+		}{Portals: s.Catalog()})
+	})
+
+	router.Get("/clusters/{cluster}/topology", func(rw http.ResponseWriter, req *http.Request) {
+		topology, ok := s.Topology(chi.URLParam(req, "cluster"))
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		writeJSON(rw, topology)
+	})
+
+	return router
+}
+
+// clusterReports returns the reports of the given cluster, creating them if needed. It must be called with the
+// clusters lock held.
+func (s *Server) clusterReports(cluster string) *clusterReports {
+	reports, ok := s.clusters[cluster]
+	if !ok {
+		reports = &clusterReports{}
+		s.clusters[cluster] = reports
+	}
+
+	return reports
+}
+
+func (s *Server) expired(reportedAt time.Time) bool {
+	return s.expiration > 0 && s.now().Sub(reportedAt) > s.expiration
+}
+
+type clusterKey struct{}
+
+// authenticate is a gRPC interceptor authenticating the agents from the bearer token of their requests. The cluster
+// of the agent is stored in the request context.
+func (s *Server) authenticate(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token = strings.TrimPrefix(values[0], "Bearer ")
+	}
+
+	cluster, ok := s.clusterForToken(token)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return handler(context.WithValue(ctx, clusterKey{}, cluster), req)
+}
+
+func (s *Server) clusterForToken(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+
+	var found string
+	for cluster, clusterToken := range s.tokens {
+		// Every token is compared for the time taken not to depend on the given token.
+		if subtle.ConstantTimeCompare([]byte(token), []byte(clusterToken)) == 1 {
+			found = cluster
+		}
+	}
+
+	return found, found != ""
+}
+
+func clusterFromContext(ctx context.Context) string {
+	cluster, _ := ctx.Value(clusterKey{}).(string)
+	return cluster
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Unable to write response")
+	}
+}
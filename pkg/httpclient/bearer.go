@@ -0,0 +1,78 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TokenFunc returns the token authenticating a request.
+type TokenFunc func(ctx context.Context) (string, error)
+
+// BearerTransport authenticates the requests sent to a single host with the tokens returned by a TokenFunc. Requests
+// sent to other hosts are left untouched, so the tokens never leak.
+type BearerTransport struct {
+	base           http.RoundTripper
+	host           string
+	token          TokenFunc
+	onUnauthorized func()
+}
+
+// NewBearerTransport returns a BearerTransport authenticating the requests sent to the given host, and sending them
+// with the given transport, or with http.DefaultTransport when nil. When not nil, onUnauthorized is called each time
+// the host rejects a token, so a new one can be obtained for the next requests.
+func NewBearerTransport(base http.RoundTripper, host string, token TokenFunc, onUnauthorized func()) *BearerTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &BearerTransport{
+		base:           base,
+		host:           host,
+		token:          token,
+		onUnauthorized: onUnauthorized,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+
+	token, err := t.token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("get token: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && t.onUnauthorized != nil {
+		t.onUnauthorized()
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearerTransport(t *testing.T) {
+	var rejected bool
+	target := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		if rejected {
+			rw.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(target.Close)
+
+	other := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer other", req.Header.Get("Authorization"))
+	}))
+	t.Cleanup(other.Close)
+
+	targetURL, err := url.Parse(target.URL)
+	require.NoError(t, err)
+
+	var unauthorized int
+	token := func(_ context.Context) (string, error) {
+		return "token", nil
+	}
+	// The transport of the test server is used, since the default one caches the proxy environment variables.
+	client := &http.Client{Transport: NewBearerTransport(target.Client().Transport, targetURL.Host, token, func() { unauthorized++ })}
+
+	send := func(url string) int {
+		t.Helper()
+
+		req, errReq := http.NewRequest(http.MethodGet, url, http.NoBody)
+		require.NoError(t, errReq)
+		req.Header.Set("Authorization", "Bearer other")
+
+		resp, errDo := client.Do(req)
+		require.NoError(t, errDo)
+		require.NoError(t, resp.Body.Close())
+
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, send(target.URL))
+	assert.Equal(t, http.StatusOK, send(other.URL))
+	assert.Equal(t, 0, unauthorized)
+
+	rejected = true
+	assert.Equal(t, http.StatusUnauthorized, send(target.URL))
+	assert.Equal(t, 1, unauthorized)
+}
+
+func TestBearerTransport_tokenError(t *testing.T) {
+	token := func(_ context.Context) (string, error) {
+		return "", errors.New("boom")
+	}
+	client := &http.Client{Transport: NewBearerTransport(nil, "platform.hub.traefik.io", token, nil)}
+
+	req, err := http.NewRequest(http.MethodGet, "https://platform.hub.traefik.io/agent/config", http.NoBody)
+	require.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.ErrorContains(t, err, "get token: boom")
+}
@@ -23,6 +23,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -53,3 +54,59 @@ func Test_newHTTPClient_WithProxyEnvVars(t *testing.T) {
 
 	assert.Equal(t, []byte(`PROXIED`), body)
 }
+
+func TestNewTransport(t *testing.T) {
+	var protos []string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		protos = append(protos, req.Proto)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	for _, http2 := range []bool{true, false} {
+		transport, err := NewTransport(TransportConfig{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     time.Minute,
+			DialTimeout:         time.Second,
+			HTTP2:               http2,
+		})
+		require.NoError(t, err)
+
+		transport.TLSClientConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+
+		resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	assert.Equal(t, []string{"HTTP/2.0", "HTTP/1.1"}, protos)
+}
+
+func TestNewTransport_invalidConfig(t *testing.T) {
+	valid := TransportConfig{MaxIdleConns: 100, MaxIdleConnsPerHost: 10, IdleConnTimeout: time.Minute, DialTimeout: time.Second}
+
+	tests := []struct {
+		desc   string
+		update func(cfg *TransportConfig)
+	}{
+		{desc: "negative max idle connections", update: func(cfg *TransportConfig) { cfg.MaxIdleConns = -1 }},
+		{desc: "no idle connection per host", update: func(cfg *TransportConfig) { cfg.MaxIdleConnsPerHost = 0 }},
+		{desc: "negative idle connection timeout", update: func(cfg *TransportConfig) { cfg.IdleConnTimeout = -time.Second }},
+		{desc: "no dial timeout", update: func(cfg *TransportConfig) { cfg.DialTimeout = 0 }},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := valid
+			test.update(&cfg)
+
+			_, err := NewTransport(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package httpclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig configures the connection pooling of a transport reaching many upstream hosts, such as the pods
+// serving OpenAPI specs or metrics.
+type TransportConfig struct {
+	// MaxIdleConns is the maximum number of idle connections kept across all hosts. Zero means no limit.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept for each host.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the duration after which idle connections are closed. Zero means no limit.
+	IdleConnTimeout time.Duration
+	// DialTimeout is the maximum duration for establishing a connection.
+	DialTimeout time.Duration
+	// HTTP2 enables HTTP/2 on TLS connections.
+	HTTP2 bool
+}
+
+// NewTransport returns a new transport pooling its connections as configured. It is meant to be shared by all the
+// clients reaching the same set of hosts, so connections are reused across them.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	if cfg.MaxIdleConns < 0 {
+		return nil, errors.New("max idle connections must not be negative")
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		return nil, errors.New("max idle connections per host must be positive")
+	}
+	if cfg.IdleConnTimeout < 0 {
+		return nil, errors.New("idle connection timeout must not be negative")
+	}
+	if cfg.DialTimeout <= 0 {
+		return nil, errors.New("dial timeout must be positive")
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	// HTTP/2 is only negotiated by transports with a custom dialer when ForceAttemptHTTP2 is set.
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     cfg.HTTP2,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}, nil
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // Required by the JKS format.
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf16"
+)
+
+// JKS format constants, as defined by the sun.security.provider.JavaKeyStore implementation.
+const (
+	jksMagic           = 0xfeedfeed
+	jksVersion         = 2
+	jksPrivateKeyTag   = 1
+	jksDigestWhitening = "Mighty Aphrodite"
+	jksSaltLen         = sha1.Size
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// encodeJKS encodes a JKS keystore holding a single private key entry with the given certificate chain.
+func encodeJKS(rand io.Reader, privateKey interface{}, chain []*x509.Certificate, password string, now time.Time) ([]byte, error) {
+	key, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	passwd := jksPassword(password)
+
+	protectedKey, err := protectJKSKey(rand, key, passwd)
+	if err != nil {
+		return nil, fmt.Errorf("protect private key: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeUint32(&buf, jksMagic)
+	writeUint32(&buf, jksVersion)
+	writeUint32(&buf, 1)
+
+	writeUint32(&buf, jksPrivateKeyTag)
+	if err = writeUTF(&buf, alias); err != nil {
+		return nil, err
+	}
+	writeUint64(&buf, uint64(now.UnixMilli()))
+	writeUint32(&buf, uint32(len(protectedKey)))
+	buf.Write(protectedKey)
+
+	writeUint32(&buf, uint32(len(chain)))
+	for _, cert := range chain {
+		if err = writeUTF(&buf, "X.509"); err != nil {
+			return nil, err
+		}
+		writeUint32(&buf, uint32(len(cert.Raw)))
+		buf.Write(cert.Raw)
+	}
+
+	buf.Write(jksDigest(passwd, buf.Bytes()))
+
+	return buf.Bytes(), nil
+}
+
+// verifyJKS checks the integrity of the given JKS keystore with the given password.
+func verifyJKS(data []byte, password string) error {
+	if len(data) < sha1.Size+12 || binary.BigEndian.Uint32(data) != jksMagic {
+		return errInvalidKeystore
+	}
+
+	body, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if subtle.ConstantTimeCompare(jksDigest(jksPassword(password), body), digest) != 1 {
+		return fmt.Errorf("%w: password verification failed", errInvalidKeystore)
+	}
+
+	return nil
+}
+
+// protectJKSKey encrypts the given PKCS#8 encoded private key using the algorithm of the
+// sun.security.provider.KeyProtector: the key is XORed with a SHA-1 based key stream derived from the password and a
+// random salt, and followed by a SHA-1 checksum of the password and the key.
+func protectJKSKey(rand io.Reader, key, passwd []byte) ([]byte, error) {
+	salt := make([]byte, jksSaltLen)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	encrypted := make([]byte, 0, jksSaltLen+len(key)+sha1.Size)
+	encrypted = append(encrypted, salt...)
+	encrypted = append(encrypted, xorJKSKeyStream(key, passwd, salt)...)
+
+	checksum := sha1.Sum(append(append([]byte{}, passwd...), key...)) //nolint:gosec // Required by the JKS format.
+	encrypted = append(encrypted, checksum[:]...)
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			// Identifies the proprietary algorithm used by JKS keystores to protect private keys.
+			Algorithm:  asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1},
+			Parameters: asn1.NullRawValue,
+		},
+		EncryptedData: encrypted,
+	})
+}
+
+// xorJKSKeyStream XORs the given data with the key stream of the JKS key protector.
+func xorJKSKeyStream(data, passwd, salt []byte) []byte {
+	out := make([]byte, len(data))
+
+	digest := salt
+	for i := 0; i < len(data); i += sha1.Size {
+		sum := sha1.Sum(append(append([]byte{}, passwd...), digest...)) //nolint:gosec // Required by the JKS format.
+		digest = sum[:]
+
+		for j := 0; j < sha1.Size && i+j < len(data); j++ {
+			out[i+j] = data[i+j] ^ digest[j]
+		}
+	}
+
+	return out
+}
+
+// jksDigest computes the integrity digest of a JKS keystore.
+func jksDigest(passwd, body []byte) []byte {
+	h := sha1.New() //nolint:gosec // Required by the JKS format.
+	h.Write(passwd)
+	h.Write([]byte(jksDigestWhitening))
+	h.Write(body)
+
+	return h.Sum(nil)
+}
+
+// jksPassword encodes the given password as Java does, as UTF-16 big endian code units.
+func jksPassword(password string) []byte {
+	units := utf16.Encode([]rune(password))
+
+	passwd := make([]byte, 0, 2*len(units))
+	for _, unit := range units {
+		passwd = append(passwd, byte(unit>>8), byte(unit))
+	}
+
+	return passwd
+}
+
+func writeUint32(buf *bytes.Buffer, v uint32) {
+	_ = binary.Write(buf, binary.BigEndian, v)
+}
+
+func writeUint64(buf *bytes.Buffer, v uint64) {
+	_ = binary.Write(buf, binary.BigEndian, v)
+}
+
+// writeUTF writes the given ASCII string as Java's DataOutput.writeUTF does.
+func writeUTF(buf *bytes.Buffer, s string) error {
+	for _, c := range []byte(s) {
+		if c == 0 || c > 0x7f {
+			return fmt.Errorf("unsupported non ASCII string %q", s)
+		}
+	}
+
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package keystore encodes TLS certificates in the keystore formats used by Java workloads.
+package keystore
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Supported keystore formats.
+const (
+	FormatPKCS12 = "pkcs12"
+	FormatJKS    = "jks"
+)
+
+// Secret keys the keystores are stored under, as used by cert-manager.
+const (
+	SecretKeyPKCS12 = "keystore.p12"
+	SecretKeyJKS    = "keystore.jks"
+)
+
+// alias is the alias of the certificate entry in JKS keystores.
+const alias = "certificate"
+
+// Config configures the keystores written alongside a certificate.
+type Config struct {
+	// Formats are the formats of the keystores.
+	Formats []string
+	// Password protects the keystores and their private key.
+	Password string
+}
+
+// ValidateFormats returns an error if one of the given formats is not supported.
+func ValidateFormats(formats []string) error {
+	for _, format := range formats {
+		if format != FormatPKCS12 && format != FormatJKS {
+			return fmt.Errorf("unsupported keystore format %q", format)
+		}
+	}
+
+	return nil
+}
+
+// Data returns the keystores of the given PEM encoded certificate chain and private key, indexed by Secret key.
+func (c Config) Data(certPEM, keyPEM []byte) (map[string][]byte, error) {
+	if len(c.Formats) == 0 {
+		return nil, nil
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parse key pair: %w", err)
+	}
+
+	chain := make([]*x509.Certificate, 0, len(pair.Certificate))
+	for _, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate: %w", err)
+		}
+
+		chain = append(chain, cert)
+	}
+
+	data := make(map[string][]byte, len(c.Formats))
+	for _, format := range c.Formats {
+		switch format {
+		case FormatPKCS12:
+			// The legacy encryption is the one supported by most Java versions. Keystores are protected by the Secret
+			// they are stored in.
+			data[SecretKeyPKCS12], err = pkcs12.LegacyDES.Encode(pair.PrivateKey, chain[0], chain[1:], c.Password)
+			if err != nil {
+				return nil, fmt.Errorf("encode PKCS#12 keystore: %w", err)
+			}
+
+		case FormatJKS:
+			data[SecretKeyJKS], err = encodeJKS(rand.Reader, pair.PrivateKey, chain, c.Password, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("encode JKS keystore: %w", err)
+			}
+
+		default:
+			return nil, fmt.Errorf("unsupported keystore format %q", format)
+		}
+	}
+
+	return data, nil
+}
+
+// UpToDate returns whether the given Secret data holds the keystores of all the formats, protected by the password.
+// Keystores are salted and can't be compared with newly encoded ones.
+func (c Config) UpToDate(data map[string][]byte) bool {
+	for _, format := range c.Formats {
+		switch format {
+		case FormatPKCS12:
+			if _, _, _, err := pkcs12.DecodeChain(data[SecretKeyPKCS12], c.Password); err != nil {
+				return false
+			}
+
+		case FormatJKS:
+			if err := verifyJKS(data[SecretKeyJKS], c.Password); err != nil {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+var errInvalidKeystore = errors.New("invalid keystore")
@@ -0,0 +1,162 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // Required by the JKS format.
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestConfig_Data(t *testing.T) {
+	certPEM, keyPEM, key := generateCertificate(t)
+
+	cfg := Config{Formats: []string{FormatPKCS12, FormatJKS}, Password: "secret"}
+
+	data, err := cfg.Data(certPEM, keyPEM)
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+
+	gotKey, gotCert, _, err := pkcs12.DecodeChain(data[SecretKeyPKCS12], "secret")
+	require.NoError(t, err)
+	assert.Equal(t, key, gotKey)
+	assert.Equal(t, "test", gotCert.Subject.CommonName)
+
+	gotKey, gotChain := decodeJKS(t, data[SecretKeyJKS], "secret")
+	assert.Equal(t, key, gotKey)
+	require.Len(t, gotChain, 1)
+	assert.Equal(t, "test", gotChain[0].Subject.CommonName)
+
+	assert.True(t, cfg.UpToDate(data))
+	assert.False(t, Config{Formats: cfg.Formats, Password: "other"}.UpToDate(data))
+	assert.False(t, cfg.UpToDate(map[string][]byte{SecretKeyPKCS12: data[SecretKeyPKCS12]}))
+}
+
+func TestConfig_Data_noFormats(t *testing.T) {
+	certPEM, keyPEM, _ := generateCertificate(t)
+
+	data, err := Config{}.Data(certPEM, keyPEM)
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	assert.True(t, Config{}.UpToDate(nil))
+}
+
+func TestValidateFormats(t *testing.T) {
+	assert.NoError(t, ValidateFormats([]string{FormatPKCS12, FormatJKS}))
+	assert.Error(t, ValidateFormats([]string{"pem"}))
+}
+
+// decodeJKS decodes a JKS keystore holding a single private key entry, checking its integrity.
+func decodeJKS(t *testing.T, data []byte, password string) (interface{}, []*x509.Certificate) {
+	t.Helper()
+
+	require.NoError(t, verifyJKS(data, password))
+
+	r := bytes.NewReader(data[:len(data)-sha1.Size])
+	readUint32 := func() uint32 {
+		var v uint32
+		require.NoError(t, binary.Read(r, binary.BigEndian, &v))
+		return v
+	}
+	readBytes := func(n int) []byte {
+		b := make([]byte, n)
+		_, err := r.Read(b)
+		require.NoError(t, err)
+		return b
+	}
+	readUTF := func() string {
+		var n uint16
+		require.NoError(t, binary.Read(r, binary.BigEndian, &n))
+		return string(readBytes(int(n)))
+	}
+
+	assert.Equal(t, uint32(jksMagic), readUint32())
+	assert.Equal(t, uint32(jksVersion), readUint32())
+	assert.Equal(t, uint32(1), readUint32())
+	assert.Equal(t, uint32(jksPrivateKeyTag), readUint32())
+	assert.Equal(t, alias, readUTF())
+	readBytes(8)
+
+	var info encryptedPrivateKeyInfo
+	_, err := asn1.Unmarshal(readBytes(int(readUint32())), &info)
+	require.NoError(t, err)
+	assert.True(t, info.Algorithm.Algorithm.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}))
+
+	passwd := jksPassword(password)
+	encrypted := info.EncryptedData
+	salt := encrypted[:jksSaltLen]
+	checksum := encrypted[len(encrypted)-sha1.Size:]
+	plainKey := xorJKSKeyStream(encrypted[jksSaltLen:len(encrypted)-sha1.Size], passwd, salt)
+
+	sum := sha1.Sum(append(append([]byte{}, passwd...), plainKey...)) //nolint:gosec // Required by the JKS format.
+	assert.Equal(t, checksum, sum[:])
+
+	key, err := x509.ParsePKCS8PrivateKey(plainKey)
+	require.NoError(t, err)
+
+	var chain []*x509.Certificate
+	for i := readUint32(); i > 0; i-- {
+		assert.Equal(t, "X.509", readUTF())
+
+		cert, err := x509.ParseCertificate(readBytes(int(readUint32())))
+		require.NoError(t, err)
+
+		chain = append(chain, cert)
+	}
+	assert.Zero(t, r.Len())
+
+	return key, chain
+}
+
+func generateCertificate(t *testing.T) (certPEM, keyPEM []byte, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, key
+}
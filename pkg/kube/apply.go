@@ -0,0 +1,165 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
+)
+
+// FieldManager is the field manager the agent applies the resources it generates with.
+const FieldManager = "traefik-hub-agent"
+
+// legacyFieldManager is the field manager the API server derived from the user agent when the agent wrote the
+// resources it generates with Create and Update calls.
+const legacyFieldManager = "hub-agent-kubernetes"
+
+// Patcher patches the named resource, as the Patch method of the typed Kubernetes clients does.
+type Patcher[T runtime.Object] func(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (T, error)
+
+// Apply applies the given object, which must have its TypeMeta set, using server-side apply. The resource is created
+// when missing, and the agent only owns the fields set on the object: its status, the metadata set by the API server,
+// null values and empty objects and lists are not sent. When some of these fields are owned by another field manager,
+// the conflict is logged and their ownership is taken, the agent being the source of truth of the resources it
+// generates.
+//
+// The fields of resources previously written with Update calls are moved to the agent field manager, for the ones the
+// agent no longer sets to be removed.
+func Apply[T runtime.Object](ctx context.Context, patch Patcher[T], name string, obj runtime.Object) (T, error) {
+	var zero T
+
+	data, err := applyPatch(obj)
+	if err != nil {
+		return zero, err
+	}
+
+	res, err := apply(ctx, patch, name, data)
+	if err != nil {
+		return zero, err
+	}
+
+	upgradePatch, err := csaupgrade.UpgradeManagedFieldsPatch(res, sets.New(legacyFieldManager), FieldManager)
+	if err != nil {
+		return zero, fmt.Errorf("upgrade managed fields: %w", err)
+	}
+	if upgradePatch == nil {
+		return res, nil
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("name", name).
+		Msg("Moving the fields of the resource written with Update to the server-side apply field manager")
+
+	if _, err = patch(ctx, name, types.JSONPatchType, upgradePatch, metav1.PatchOptions{}); err != nil {
+		return zero, fmt.Errorf("patch managed fields: %w", err)
+	}
+
+	// Apply again, for the fields formerly owned by the Update field manager and no longer set to be removed.
+	return apply(ctx, patch, name, data)
+}
+
+func apply[T runtime.Object](ctx context.Context, patch Patcher[T], name string, data []byte) (T, error) {
+	res, err := patch(ctx, name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager})
+	if err == nil || !kerror.IsConflict(err) {
+		return res, err
+	}
+
+	log.Ctx(ctx).Warn().
+		Err(err).
+		Str("name", name).
+		Msg("Fields of the applied resource are managed by another field manager, forcing the apply")
+
+	force := true
+	return patch(ctx, name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: FieldManager, Force: &force})
+}
+
+// applyPatch returns the server-side apply patch of the given object, holding only the fields owned by the agent.
+func applyPatch(obj runtime.Object) ([]byte, error) {
+	// The object is converted the way the typed clients encode it, rather than with the unstructured converter, which
+	// doesn't inline the embedded structs lacking an inline JSON tag, like the ones of some Traefik types.
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("marshal object: %w", err)
+	}
+
+	var content map[string]interface{}
+	if err = json.Unmarshal(raw, &content); err != nil {
+		return nil, fmt.Errorf("convert object: %w", err)
+	}
+
+	delete(content, "status")
+
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		owned := make(map[string]interface{})
+		for _, field := range []string{"name", "namespace", "labels", "annotations", "ownerReferences"} {
+			if value, exists := metadata[field]; exists {
+				owned[field] = value
+			}
+		}
+		content["metadata"] = owned
+	}
+
+	data, err := json.Marshal(prune(content))
+	if err != nil {
+		return nil, fmt.Errorf("marshal object: %w", err)
+	}
+
+	return data, nil
+}
+
+// prune removes the null values, and the objects and lists left empty, from the given value.
+func prune(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field = prune(field); field == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = field
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, item := range v {
+			// Empty objects are kept in lists, for the other items to keep their position.
+			if pruned := prune(item); pruned != nil {
+				v[i] = pruned
+			}
+		}
+		return v
+
+	default:
+		return v
+	}
+}
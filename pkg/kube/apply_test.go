@@ -0,0 +1,180 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestApply(t *testing.T) {
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+
+	var calls []metav1.PatchOptions
+	patch := func(_ context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, _ ...string) (*corev1.Secret, error) {
+		assert.Equal(t, "secret", name)
+		assert.Equal(t, types.ApplyPatchType, pt)
+		assert.JSONEq(t, `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"secret","namespace":"ns"},"data":{"key":"dmFsdWU="}}`, string(data))
+
+		calls = append(calls, opts)
+		if opts.Force == nil {
+			return nil, kerror.NewConflict(schema.GroupResource{Resource: "secrets"}, name, nil)
+		}
+
+		return secret, nil
+	}
+
+	got, err := Apply(context.Background(), patch, "secret", secret)
+	require.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	force := true
+	assert.Equal(t, []metav1.PatchOptions{
+		{FieldManager: FieldManager},
+		{FieldManager: FieldManager, Force: &force},
+	}, calls)
+}
+
+func TestApply_error(t *testing.T) {
+	patch := func(_ context.Context, name string, _ types.PatchType, _ []byte, _ metav1.PatchOptions, _ ...string) (*corev1.Secret, error) {
+		return nil, kerror.NewForbidden(schema.GroupResource{Resource: "secrets"}, name, nil)
+	}
+
+	_, err := Apply(context.Background(), patch, "secret", &corev1.Secret{})
+	assert.True(t, kerror.IsForbidden(err))
+}
+
+func TestApply_ownedFieldsOnly(t *testing.T) {
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "secret",
+			Namespace:       "ns",
+			Labels:          map[string]string{"app": "hub"},
+			ResourceVersion: "42",
+			UID:             "uid",
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "other"}},
+		},
+		Data:       map[string][]byte{"key": []byte("value")},
+		StringData: map[string]string{},
+	}
+
+	patch := func(_ context.Context, _ string, _ types.PatchType, data []byte, _ metav1.PatchOptions, _ ...string) (*corev1.Secret, error) {
+		assert.JSONEq(t, `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"secret","namespace":"ns","labels":{"app":"hub"}},"data":{"key":"dmFsdWU="}}`, string(data))
+
+		return &corev1.Secret{}, nil
+	}
+
+	_, err := Apply(context.Background(), patch, "secret", secret)
+	require.NoError(t, err)
+}
+
+func TestApply_embeddedStructs(t *testing.T) {
+	svc := &traefikv1alpha1.TraefikService{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "traefik.containo.us/v1alpha1", Kind: "TraefikService"},
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "ns"},
+		Spec: traefikv1alpha1.ServiceSpec{
+			Mirroring: &traefikv1alpha1.Mirroring{
+				LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{Name: "main", Kind: "Service"},
+				Mirrors: []traefikv1alpha1.MirrorService{
+					{LoadBalancerSpec: traefikv1alpha1.LoadBalancerSpec{Name: "mirror", Kind: "Service"}, Percent: 10},
+				},
+			},
+		},
+	}
+
+	patch := func(_ context.Context, _ string, _ types.PatchType, data []byte, _ metav1.PatchOptions, _ ...string) (*traefikv1alpha1.TraefikService, error) {
+		// The embedded structs are inlined, as done by the typed clients.
+		assert.JSONEq(t, `{"kind":"TraefikService","apiVersion":"traefik.containo.us/v1alpha1","metadata":{"name":"svc","namespace":"ns"},"spec":{"mirroring":{"name":"main","kind":"Service","port":0,"mirrors":[{"name":"mirror","kind":"Service","port":0,"percent":10}]}}}`, string(data))
+
+		return &traefikv1alpha1.TraefikService{}, nil
+	}
+
+	_, err := Apply(context.Background(), patch, "svc", svc)
+	require.NoError(t, err)
+}
+
+func TestApply_upgradeManagedFields(t *testing.T) {
+	secret := &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "ns"},
+		Data:       map[string][]byte{"key": []byte("value")},
+	}
+
+	applied := secret.DeepCopy()
+	applied.ResourceVersion = "42"
+	applied.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager:    legacyFieldManager,
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{".":{},"f:key":{},"f:removed":{}}}`)},
+		},
+		{
+			Manager:    FieldManager,
+			Operation:  metav1.ManagedFieldsOperationApply,
+			APIVersion: "v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:key":{}}}`)},
+		},
+	}
+
+	var patchTypes []types.PatchType
+	patch := func(_ context.Context, _ string, pt types.PatchType, data []byte, _ metav1.PatchOptions, _ ...string) (*corev1.Secret, error) {
+		patchTypes = append(patchTypes, pt)
+
+		if pt == types.JSONPatchType {
+			var ops []struct {
+				Path  string          `json:"path"`
+				Value json.RawMessage `json:"value"`
+			}
+			require.NoError(t, json.Unmarshal(data, &ops))
+			require.NotEmpty(t, ops)
+			assert.Equal(t, "/metadata/managedFields", ops[0].Path)
+
+			var managedFields []metav1.ManagedFieldsEntry
+			require.NoError(t, json.Unmarshal(ops[0].Value, &managedFields))
+			require.Len(t, managedFields, 1)
+			assert.Equal(t, FieldManager, managedFields[0].Manager)
+
+			applied.ManagedFields = managedFields
+		}
+
+		return applied, nil
+	}
+
+	_, err := Apply(context.Background(), patch, "secret", secret)
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.PatchType{types.ApplyPatchType, types.JSONPatchType, types.ApplyPatchType}, patchTypes)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package kube
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// SecretCertificate provides the TLS certificate held by a Secret of type kubernetes.io/tls. The Secret is looked up
+// from a lister on every TLS handshake, so certificate rotations are picked up without restarting the server.
+type SecretCertificate struct {
+	secrets corelisters.SecretNamespaceLister
+	name    string
+
+	mu              sync.Mutex
+	resourceVersion string
+	cert            *tls.Certificate
+}
+
+// NewSecretCertificate creates a new SecretCertificate for the Secret with the given name.
+func NewSecretCertificate(secrets corelisters.SecretNamespaceLister, name string) *SecretCertificate {
+	return &SecretCertificate{
+		secrets: secrets,
+		name:    name,
+	}
+}
+
+// GetCertificate returns the certificate held by the Secret. It is meant to be used as tls.Config.GetCertificate.
+func (c *SecretCertificate) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	secret, err := c.secrets.Get(c.name)
+	if err != nil {
+		return nil, fmt.Errorf("get secret %q: %w", c.name, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The key pair is only parsed again when the Secret changes.
+	if c.cert != nil && c.resourceVersion == secret.ResourceVersion {
+		return c.cert, nil
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("parse key pair of secret %q: %w", c.name, err)
+	}
+
+	c.cert = &cert
+	c.resourceVersion = secret.ResourceVersion
+
+	return c.cert, nil
+}
+
+// FileCertificate provides the TLS certificate held by a pair of PEM files. The files are checked on every TLS
+// handshake, so certificate rotations are picked up without restarting the server.
+type FileCertificate struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	modTime [2]time.Time
+	cert    *tls.Certificate
+}
+
+// NewFileCertificate creates a new FileCertificate for the given certificate and private key files.
+func NewFileCertificate(certFile, keyFile string) *FileCertificate {
+	return &FileCertificate{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+}
+
+// GetCertificate returns the certificate held by the files. It is meant to be used as tls.Config.GetCertificate.
+func (c *FileCertificate) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	var modTime [2]time.Time
+	for i, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("stat %q: %w", file, err)
+		}
+		modTime[i] = info.ModTime()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The key pair is only parsed again when one of the files changes.
+	if c.cert != nil && c.modTime == modTime {
+		return c.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load key pair %q and %q: %w", c.certFile, c.keyFile, err)
+	}
+
+	c.cert = &cert
+	c.modTime = modTime
+
+	return c.cert, nil
+}
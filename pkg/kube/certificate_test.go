@@ -0,0 +1,159 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package kube
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestSecretCertificate_GetCertificate(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	secrets := corelisters.NewSecretLister(indexer).Secrets("hub")
+
+	c := NewSecretCertificate(secrets, "hub-agent-tls")
+
+	_, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	require.Error(t, err)
+
+	certPEM, keyPEM := generateCertificate(t, "auth-server")
+	require.NoError(t, indexer.Add(newTLSSecret("1", certPEM, keyPEM)))
+
+	gotCert, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "auth-server", leafCommonName(t, gotCert))
+
+	// The certificate is reloaded once the Secret is updated.
+	certPEM, keyPEM = generateCertificate(t, "dev-portal")
+	require.NoError(t, indexer.Update(newTLSSecret("2", certPEM, keyPEM)))
+
+	gotCert, err = c.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "dev-portal", leafCommonName(t, gotCert))
+
+	require.NoError(t, indexer.Update(newTLSSecret("3", []byte("invalid"), keyPEM)))
+
+	_, err = c.GetCertificate(&tls.ClientHelloInfo{})
+	require.Error(t, err)
+}
+
+func TestFileCertificate_GetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	c := NewFileCertificate(certFile, keyFile)
+
+	_, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	require.Error(t, err)
+
+	certPEM, keyPEM := generateCertificate(t, "auth-server")
+	writeKeyPair(t, certFile, keyFile, certPEM, keyPEM, time.Now().Add(-time.Minute))
+
+	gotCert, err := c.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "auth-server", leafCommonName(t, gotCert))
+
+	// The certificate is reloaded once the files are updated.
+	certPEM, keyPEM = generateCertificate(t, "dev-portal")
+	writeKeyPair(t, certFile, keyFile, certPEM, keyPEM, time.Now())
+
+	gotCert, err = c.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, "dev-portal", leafCommonName(t, gotCert))
+
+	writeKeyPair(t, certFile, keyFile, []byte("invalid"), keyPEM, time.Now().Add(time.Minute))
+
+	_, err = c.GetCertificate(&tls.ClientHelloInfo{})
+	require.Error(t, err)
+}
+
+// writeKeyPair writes the given key pair to the given files and sets their modification time, so updates are detected
+// regardless of the resolution of the file system timestamps.
+func writeKeyPair(t *testing.T, certFile, keyFile string, certPEM, keyPEM []byte, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func newTLSSecret(resourceVersion string, certPEM, keyPEM []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "hub-agent-tls",
+			Namespace:       "hub",
+			ResourceVersion: resourceVersion,
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+}
+
+func generateCertificate(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM
+}
+
+func leafCommonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package kubetest provides helpers for testing with the fake Kubernetes client sets.
+package kubetest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	hubscheme "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/scheme"
+	traefikscheme "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/scheme"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// FakeClientSet is a fake client set, such as the ones generated by client-gen.
+type FakeClientSet interface {
+	Tracker() ktesting.ObjectTracker
+	PrependReactor(verb, resource string, reaction ktesting.ReactionFunc)
+}
+
+// AddApplyReactor makes the given fake client set handle server-side apply patches: the fake client sets only apply
+// them, as strategic merge patches, on existing resources. Missing resources are created, and existing ones are
+// JSON merge patched, the lists of the applied object replacing the existing ones as they would for the resources
+// fully owned by the agent.
+func AddApplyReactor(clientSet FakeClientSet) {
+	tracker := clientSet.Tracker()
+	scheme := newScheme()
+
+	clientSet.PrependReactor("patch", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(ktesting.PatchAction)
+		if !ok || patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(patchAction.GetPatch(), &typeMeta); err != nil {
+			return true, nil, fmt.Errorf("unmarshal type meta: %w", err)
+		}
+
+		obj, err := scheme.New(typeMeta.GroupVersionKind())
+		if err != nil {
+			return true, nil, fmt.Errorf("new %s: %w", typeMeta.GroupVersionKind(), err)
+		}
+
+		gvr, ns, name := action.GetResource(), action.GetNamespace(), patchAction.GetName()
+
+		existing, err := tracker.Get(gvr, ns, name)
+		switch {
+		case kerror.IsNotFound(err):
+			if err = json.Unmarshal(patchAction.GetPatch(), obj); err != nil {
+				return true, nil, fmt.Errorf("unmarshal object: %w", err)
+			}
+
+			if err = tracker.Create(gvr, obj, ns); err != nil {
+				return true, nil, err
+			}
+
+			return true, obj, nil
+
+		case err != nil:
+			return true, nil, err
+		}
+
+		raw, err := json.Marshal(existing)
+		if err != nil {
+			return true, nil, fmt.Errorf("marshal existing object: %w", err)
+		}
+
+		patched, err := jsonpatch.MergePatch(raw, patchAction.GetPatch())
+		if err != nil {
+			return true, nil, fmt.Errorf("merge patch: %w", err)
+		}
+
+		if err = json.Unmarshal(patched, obj); err != nil {
+			return true, nil, fmt.Errorf("unmarshal object: %w", err)
+		}
+
+		if err = tracker.Update(gvr, obj, ns); err != nil {
+			return true, nil, err
+		}
+
+		return true, obj, nil
+	})
+}
+
+// newScheme returns a scheme knowing the Kubernetes, Hub and Traefik types, to decode the applied objects.
+func newScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{kscheme.AddToScheme, hubscheme.AddToScheme, traefikscheme.AddToScheme} {
+		if err := addToScheme(s); err != nil {
+			panic(err)
+		}
+	}
+
+	return s
+}
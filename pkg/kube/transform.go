@@ -0,0 +1,59 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package kube
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+)
+
+// StripObject is a cache.TransformFunc removing, from the objects about to be cached by an informer, the metadata the
+// agent never reads: the managed fields and the last applied configuration annotation. Both can be as large as the
+// object itself, which adds up on clusters holding tens of thousands of objects.
+// Objects are modified in place: they come straight from the decoded List and Watch responses and are not shared yet.
+func StripObject(obj interface{}) (interface{}, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		// Tombstones of deleted objects are not accessible, they are left untouched.
+		return obj, nil
+	}
+
+	accessor.SetManagedFields(nil)
+
+	annotations := accessor.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+		accessor.SetAnnotations(annotations)
+	}
+
+	return obj, nil
+}
+
+// SetTransform sets the given transform function on the given informers. It must be called before starting them.
+func SetTransform(transform cache.TransformFunc, informers ...cache.SharedIndexInformer) error {
+	for _, informer := range informers {
+		if err := informer.SetTransform(transform); err != nil {
+			return fmt.Errorf("set transform: %w", err)
+		}
+	}
+
+	return nil
+}
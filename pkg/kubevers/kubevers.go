@@ -42,8 +42,13 @@ func SupportsIngressClasses(ver string) bool {
 }
 
 func atLeast(ver, minVer string) bool {
-	kubeVersion := version.Must(version.NewSemver(ver))
+	kubeVersion, err := version.NewSemver(ver)
+	if err != nil {
+		return false
+	}
 	minVersion := version.Must(version.NewSemver(minVer))
 
-	return kubeVersion.GreaterThanOrEqual(minVersion)
+	// Managed distributions suffix the version of the API server, like v1.25.0-eks-1 or v1.27.0+k3s1, which are not
+	// prior to the version they're built on.
+	return kubeVersion.Core().GreaterThanOrEqual(minVersion)
 }
@@ -0,0 +1,188 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Config holds the leader election configuration.
+type Config struct {
+	LeaseName      string
+	LeaseNamespace string
+	Identity       string
+
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+type task struct {
+	ctx context.Context
+	run func(ctx context.Context)
+}
+
+// Elector elects a leader among the agent replicas using a Kubernetes lease and runs the registered tasks
+// only while the current replica is the leader.
+type Elector struct {
+	elector *leaderelection.LeaderElector
+
+	mu        sync.Mutex
+	leaderCtx context.Context
+	tasks     []task
+	running   sync.WaitGroup
+}
+
+// NewElector returns an Elector competing for the configured lease.
+func NewElector(client clientset.Interface, cfg Config) (*Elector, error) {
+	e := &Elector{}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      cfg.LeaseName,
+			Namespace: cfg.LeaseNamespace,
+		},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: cfg.Identity},
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   cfg.LeaseDuration,
+		RenewDeadline:   cfg.RenewDeadline,
+		RetryPeriod:     cfg.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            cfg.LeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: e.lead,
+			OnStoppedLeading: func() {
+				log.Info().Str("identity", cfg.Identity).Msg("Stopped leading")
+			},
+			OnNewLeader: func(identity string) {
+				log.Info().Str("leader", identity).Msg("New leader elected")
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create leader elector: %w", err)
+	}
+	e.elector = elector
+
+	return e, nil
+}
+
+// NewAlwaysLeaderElector returns an Elector which considers the current replica as the leader. It must be used when
+// the agent runs as a single replica.
+func NewAlwaysLeaderElector() *Elector {
+	return &Elector{}
+}
+
+// Run campaigns for the leadership until the given context is done. When the leadership is lost, the registered
+// tasks are stopped and the Elector campaigns again. Once the context is done, it waits for the running tasks to
+// complete. This is a blocking method.
+func (e *Elector) Run(ctx context.Context) {
+	defer e.running.Wait()
+
+	if e.elector == nil {
+		e.lead(ctx)
+		<-ctx.Done()
+		return
+	}
+
+	for ctx.Err() == nil {
+		e.elector.Run(ctx)
+	}
+}
+
+// Go registers a task to run while the current replica is the leader. The task is started right away if the
+// replica is already the leader, and started again each time the leadership is acquired until the given context
+// is done. The context given to the task is cancelled when the leadership is lost.
+func (e *Elector) Go(ctx context.Context, run func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t := task{ctx: ctx, run: run}
+	e.tasks = append(pending(e.tasks), t)
+
+	if e.leaderCtx != nil && e.leaderCtx.Err() == nil {
+		e.start(e.leaderCtx, t)
+	}
+}
+
+// IsLeader reports whether the current replica is the leader.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.leaderCtx != nil && e.leaderCtx.Err() == nil
+}
+
+func (e *Elector) lead(leaderCtx context.Context) {
+	log.Info().Msg("Started leading")
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.leaderCtx = leaderCtx
+	e.tasks = pending(e.tasks)
+
+	for _, t := range e.tasks {
+		e.start(leaderCtx, t)
+	}
+}
+
+// pending filters out the tasks whose context is done.
+func pending(tasks []task) []task {
+	var res []task
+	for _, t := range tasks {
+		if t.ctx.Err() == nil {
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+func (e *Elector) start(leaderCtx context.Context, t task) {
+	ctx, cancel := context.WithCancel(t.ctx)
+
+	go func() {
+		select {
+		case <-leaderCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	e.running.Add(1)
+	go func() {
+		defer e.running.Done()
+		defer cancel()
+
+		t.run(ctx)
+	}()
+}
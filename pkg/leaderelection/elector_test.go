@@ -0,0 +1,168 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestElector_alwaysLeader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	elector := NewAlwaysLeaderElector()
+
+	before := make(chan struct{})
+	elector.Go(ctx, func(ctx context.Context) {
+		close(before)
+	})
+
+	go elector.Run(ctx)
+
+	waitFor(t, before)
+
+	after := make(chan struct{})
+	elector.Go(ctx, func(ctx context.Context) {
+		close(after)
+	})
+
+	waitFor(t, after)
+}
+
+func TestElector_taskStoppedWithItsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	elector := NewAlwaysLeaderElector()
+	go elector.Run(ctx)
+
+	taskCtx, cancelTask := context.WithCancel(ctx)
+
+	started, stopped := make(chan struct{}), make(chan struct{})
+	elector.Go(taskCtx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	waitFor(t, started)
+	cancelTask()
+
+	waitFor(t, stopped)
+}
+
+func TestElector_Run_waitsForRunningTasks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	elector := NewAlwaysLeaderElector()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	elector.Go(ctx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		<-release
+	})
+
+	runDone := make(chan struct{})
+	go func() {
+		elector.Run(ctx)
+		close(runDone)
+	}()
+
+	waitFor(t, started)
+	cancel()
+
+	select {
+	case <-runDone:
+		t.Fatal("run returned before the task completed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+
+	waitFor(t, runDone)
+}
+
+func TestElector_leadershipHandover(t *testing.T) {
+	client := kubemock.NewSimpleClientset()
+
+	newElector := func(identity string) *Elector {
+		elector, err := NewElector(client, Config{
+			LeaseName:      "hub-agent-controller",
+			LeaseNamespace: "hub",
+			Identity:       identity,
+			LeaseDuration:  2 * time.Second,
+			RenewDeadline:  time.Second,
+			RetryPeriod:    100 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		return elector
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	t.Cleanup(cancel1)
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	t.Cleanup(cancel2)
+
+	elector1 := newElector("replica-1")
+	started1, stopped1 := make(chan struct{}), make(chan struct{})
+	elector1.Go(ctx1, func(ctx context.Context) {
+		close(started1)
+		<-ctx.Done()
+		close(stopped1)
+	})
+
+	go elector1.Run(ctx1)
+	waitFor(t, started1)
+
+	elector2 := newElector("replica-2")
+	started2 := make(chan struct{})
+	elector2.Go(ctx2, func(ctx context.Context) {
+		close(started2)
+	})
+
+	go elector2.Run(ctx2)
+
+	select {
+	case <-started2:
+		t.Fatal("task started on a replica which is not the leader")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	// Stopping the first replica releases the lease, allowing the second one to take the leadership.
+	cancel1()
+
+	waitFor(t, stopped1)
+	waitFor(t, started2)
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package localapi exposes the state of the agent through the traefik.hub.agent.v1.Agent gRPC service, described in
+// pkg/proto/agent/v1/agent.proto, for in-cluster tools to query it without scraping internal HTTP endpoints.
+package localapi
+
+import (
+	"net/http"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/federation"
+	agentv1 "github.com/traefik/hub-agent-kubernetes/pkg/proto/agent/v1"
+)
+
+func portalsToProto(portals []federation.Portal) []*agentv1.Portal {
+	pbPortals := make([]*agentv1.Portal, 0, len(portals))
+	for _, portal := range portals {
+		pbPortal := &agentv1.Portal{
+			Name:        portal.Name,
+			Title:       portal.Title,
+			Description: portal.Description,
+			Urls:        portal.URLs,
+		}
+		for _, api := range portal.APIs {
+			pbPortal.Apis = append(pbPortal.Apis, &agentv1.API{
+				Name:       api.Name,
+				Namespace:  api.Namespace,
+				Collection: api.Collection,
+				PathPrefix: api.PathPrefix,
+				BaseUrl:    api.BaseURL,
+			})
+		}
+
+		pbPortals = append(pbPortals, pbPortal)
+	}
+
+	return pbPortals
+}
+
+func headersToProto(header http.Header) map[string]*agentv1.HeaderValues {
+	if len(header) == 0 {
+		return nil
+	}
+
+	pbHeaders := make(map[string]*agentv1.HeaderValues, len(header))
+	for name, values := range header {
+		pbHeaders[name] = &agentv1.HeaderValues{Values: values}
+	}
+
+	return pbHeaders
+}
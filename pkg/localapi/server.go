@@ -0,0 +1,256 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package localapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	hubv1alpha1lister "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/listers/hub/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/federation"
+	agentv1 "github.com/traefik/hub-agent-kubernetes/pkg/proto/agent/v1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxBodySize is the maximum size of the response body returned by an AccessControlPolicy test.
+const maxBodySize = 4096
+
+// CatalogSource gives the catalog of the portals of the cluster.
+type CatalogSource interface {
+	Catalog() ([]federation.Portal, error)
+}
+
+// TopologySource gives the last topology of the cluster.
+type TopologySource interface {
+	Topology() (*state.Cluster, bool)
+}
+
+// Server serves the agent gRPC service.
+type Server struct {
+	agentv1.UnimplementedAgentServer
+
+	catalog  CatalogSource
+	topology TopologySource
+	policies hubv1alpha1lister.AccessControlPolicyLister
+	secrets  acp.SecretGetter
+}
+
+// NewServer creates a new Server. The catalog source may be nil when API management isn't available.
+func NewServer(catalog CatalogSource, topology TopologySource, policies hubv1alpha1lister.AccessControlPolicyLister, secrets acp.SecretGetter) *Server {
+	return &Server{
+		catalog:  catalog,
+		topology: topology,
+		policies: policies,
+		secrets:  secrets,
+	}
+}
+
+// NewGRPCServer returns a gRPC server serving the agent service with the given options. The server reflection service
+// is registered as well, for generic clients such as grpcurl to discover the agent service.
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	agentv1.RegisterAgentServer(srv, s)
+	reflection.Register(srv)
+
+	return srv
+}
+
+// GetCatalog returns the catalog of the portals of the cluster.
+func (s *Server) GetCatalog(_ context.Context, _ *agentv1.GetCatalogRequest) (*agentv1.GetCatalogResponse, error) {
+	if s.catalog == nil {
+		return nil, status.Error(codes.Unimplemented, "API management is not available")
+	}
+
+	portals, err := s.catalog.Catalog()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "get catalog: %v", err)
+	}
+
+	return &agentv1.GetCatalogResponse{Portals: portalsToProto(portals)}, nil
+}
+
+// GetTopology returns the last topology of the cluster.
+func (s *Server) GetTopology(_ context.Context, _ *agentv1.GetTopologyRequest) (*agentv1.GetTopologyResponse, error) {
+	topology, ok := s.topology.Topology()
+	if !ok {
+		return nil, status.Error(codes.Unavailable, "topology not available yet")
+	}
+
+	pbTopology, err := federation.TopologyToProto(topology)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "convert topology: %v", err)
+	}
+
+	return &agentv1.GetTopologyResponse{Topology: pbTopology}, nil
+}
+
+// TestAccessControlPolicy returns the decision of an AccessControlPolicy on a request, as taken by the auth server.
+// Tests are neither logged nor counted as authentication failures.
+func (s *Server) TestAccessControlPolicy(ctx context.Context, req *agentv1.TestAccessControlPolicyRequest) (*agentv1.TestAccessControlPolicyResponse, error) {
+	policy, err := s.policies.Get(req.Policy)
+	if err != nil {
+		if kerror.IsNotFound(err) {
+			return nil, status.Errorf(codes.NotFound, "AccessControlPolicy %q not found", req.Policy)
+		}
+		return nil, status.Errorf(codes.Internal, "get AccessControlPolicy: %v", err)
+	}
+
+	cfg, err := acp.ConfigFromPolicyWithSecret(policy, s.secrets)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "build AccessControlPolicy configuration: %v", err)
+	}
+
+	handler, err := auth.NewPolicyHandler(ctx, policy.Name, cfg)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "build AccessControlPolicy handler: %v", err)
+	}
+
+	authReq, err := newForwardAuthRequest(ctx, req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+
+	rec := newResponseRecorder()
+	handler.ServeHTTP(rec, authReq)
+
+	resp := &agentv1.TestAccessControlPolicyResponse{
+		Allowed:    rec.statusCode >= 200 && rec.statusCode < 300,
+		StatusCode: int32(rec.statusCode),
+		Headers:    headersToProto(rec.header),
+	}
+	if !resp.Allowed {
+		resp.Body = rec.body.String()
+	}
+
+	return resp, nil
+}
+
+// newForwardAuthRequest builds the request sent by Traefik to the auth server to authorize the given request.
+func newForwardAuthRequest(ctx context.Context, req *agentv1.TestAccessControlPolicyRequest) (*http.Request, error) {
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("URL %q must be absolute", req.Url)
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	authReq, err := http.NewRequestWithContext(ctx, http.MethodGet, "/"+req.Policy, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range req.Headers {
+		for _, value := range values.GetValues() {
+			authReq.Header.Add(name, value)
+		}
+	}
+	authReq.Header.Set("X-Forwarded-Method", method)
+	authReq.Header.Set("X-Forwarded-Proto", u.Scheme)
+	authReq.Header.Set("X-Forwarded-Host", u.Host)
+	authReq.Header.Set("X-Forwarded-Uri", u.RequestURI())
+
+	if req.ClientIp != "" {
+		if net.ParseIP(req.ClientIp) == nil {
+			return nil, fmt.Errorf("invalid client IP %q", req.ClientIp)
+		}
+
+		authReq.Header.Set("X-Forwarded-For", req.ClientIp)
+		authReq.RemoteAddr = net.JoinHostPort(req.ClientIp, "0")
+	}
+
+	return authReq, nil
+}
+
+// responseRecorder records the response of an AccessControlPolicy handler.
+type responseRecorder struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+
+	r.statusCode = statusCode
+	r.wroteHeader = true
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+
+	if remaining := maxBodySize - r.body.Len(); remaining > 0 {
+		if len(p) > remaining {
+			r.body.Write(p[:remaining])
+		} else {
+			r.body.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+// TopologySnapshot holds the last topology of the cluster. Its Update method is meant to be registered as a topology
+// watcher listener.
+type TopologySnapshot struct {
+	mu       sync.RWMutex
+	topology *state.Cluster
+}
+
+// Update updates the snapshot with the given topology.
+func (s *TopologySnapshot) Update(_ context.Context, topology *state.Cluster) {
+	s.mu.Lock()
+	s.topology = topology
+	s.mu.Unlock()
+}
+
+// Topology returns the last topology of the cluster, false if no topology has been given yet.
+func (s *TopologySnapshot) Topology() (*state.Cluster, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.topology, s.topology != nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package localapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
+	"github.com/traefik/hub-agent-kubernetes/pkg/federation"
+	agentv1 "github.com/traefik/hub-agent-kubernetes/pkg/proto/agent/v1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+type catalogSourceMock []federation.Portal
+
+func (m catalogSourceMock) Catalog() ([]federation.Portal, error) {
+	return m, nil
+}
+
+func TestServer(t *testing.T) {
+	policy := &hubv1alpha1.AccessControlPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic"},
+		Spec: hubv1alpha1.AccessControlPolicySpec{
+			BasicAuth: &hubv1alpha1.AccessControlPolicyBasicAuth{
+				Users:                 []string{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+				ForwardUsernameHeader: "User",
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubClientSet := hubkubemock.NewSimpleClientset(policy)
+	hubInformer := hubinformer.NewSharedInformerFactory(hubClientSet, 0)
+	policies := hubInformer.Hub().V1alpha1().AccessControlPolicies().Lister()
+	hubInformer.Start(ctx.Done())
+	hubInformer.WaitForCacheSync(ctx.Done())
+
+	catalog := catalogSourceMock{{Name: "portal", APIs: []federation.API{{Name: "books", Namespace: "default"}}}}
+	topology := &TopologySnapshot{}
+	secrets := acp.NewKubeSecretClientGetter(kubemock.NewSimpleClientset().CoreV1())
+
+	client := agentv1.NewAgentClient(startServer(t, NewServer(catalog, topology, policies, secrets)))
+
+	catalogResp, err := client.GetCatalog(ctx, &agentv1.GetCatalogRequest{})
+	require.NoError(t, err)
+	require.Len(t, catalogResp.Portals, 1)
+	assert.Equal(t, "portal", catalogResp.Portals[0].Name)
+	require.Len(t, catalogResp.Portals[0].Apis, 1)
+	assert.Equal(t, "books", catalogResp.Portals[0].Apis[0].Name)
+
+	_, err = client.GetTopology(ctx, &agentv1.GetTopologyRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	cluster := &state.Cluster{Services: map[string]*state.Service{"whoami@default": {Name: "whoami", Namespace: "default"}}}
+	topology.Update(ctx, cluster)
+
+	topologyResp, err := client.GetTopology(ctx, &agentv1.GetTopologyRequest{})
+	require.NoError(t, err)
+	gotCluster, err := federation.TopologyFromProto(topologyResp.Topology)
+	require.NoError(t, err)
+	assert.Equal(t, cluster, gotCluster)
+
+	testResp, err := client.TestAccessControlPolicy(ctx, &agentv1.TestAccessControlPolicyRequest{
+		Policy:  "basic",
+		Url:     "https://api.example.com/books",
+		Headers: map[string]*agentv1.HeaderValues{"Authorization": {Values: []string{"Basic dGVzdDp0ZXN0"}}},
+	})
+	require.NoError(t, err)
+	assert.True(t, testResp.Allowed)
+	assert.Equal(t, int32(http.StatusOK), testResp.StatusCode)
+	require.Contains(t, testResp.Headers, "User")
+	assert.Equal(t, []string{"test"}, testResp.Headers["User"].Values)
+
+	testResp, err = client.TestAccessControlPolicy(ctx, &agentv1.TestAccessControlPolicyRequest{
+		Policy:  "basic",
+		Url:     "https://api.example.com/books",
+		Headers: map[string]*agentv1.HeaderValues{"Authorization": {Values: []string{"Basic dGVzdDpiYWQ="}}},
+	})
+	require.NoError(t, err)
+	assert.False(t, testResp.Allowed)
+	assert.Equal(t, int32(http.StatusUnauthorized), testResp.StatusCode)
+
+	_, err = client.TestAccessControlPolicy(ctx, &agentv1.TestAccessControlPolicyRequest{
+		Policy: "unknown",
+		Url:    "https://api.example.com/books",
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.TestAccessControlPolicy(ctx, &agentv1.TestAccessControlPolicyRequest{
+		Policy: "basic",
+		Url:    "/books",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_GetCatalog_unavailable(t *testing.T) {
+	client := agentv1.NewAgentClient(startServer(t, NewServer(nil, &TopologySnapshot{}, nil, nil)))
+
+	_, err := client.GetCatalog(context.Background(), &agentv1.GetCatalogRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestServer_reflection(t *testing.T) {
+	conn := startServer(t, NewServer(nil, &TopologySnapshot{}, nil, nil))
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	assert.Contains(t, services, agentv1.Agent_ServiceDesc.ServiceName)
+}
+
+func startServer(t *testing.T, server *Server) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	srv := server.NewGRPCServer()
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
@@ -0,0 +1,220 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Components whose log level can be set independently of the global one.
+const (
+	ComponentACP       = "acp"
+	ComponentDevPortal = "devportal"
+	ComponentACME      = "acme"
+	ComponentTopology  = "topology"
+	ComponentMetrics   = "metrics"
+)
+
+// componentNames returns the names of the components.
+func componentNames() []string {
+	return []string{ComponentACP, ComponentDevPortal, ComponentACME, ComponentTopology, ComponentMetrics}
+}
+
+// Loggers holds the global logger and the loggers of the components, created once and passed down to them. The events
+// of a component are filtered using the level of the component, if any, or the global level otherwise.
+type Loggers struct {
+	levels *levelRegistry
+
+	global     zerolog.Logger
+	components map[string]zerolog.Logger
+}
+
+// newLoggers creates the loggers deriving from the given root logger, which must not filter events by level. Events
+// are filtered using the given global level until component levels are set.
+func newLoggers(root zerolog.Logger, base zerolog.Level) *Loggers {
+	levels := &levelRegistry{
+		base:       base,
+		components: make(map[string]zerolog.Level),
+	}
+
+	components := make(map[string]zerolog.Logger)
+	for _, name := range componentNames() {
+		components[name] = root.With().Str("subsystem", name).Logger().Hook(levelHook{levels: levels, component: name})
+	}
+
+	return &Loggers{
+		levels:     levels,
+		global:     root.Hook(levelHook{levels: levels}),
+		components: components,
+	}
+}
+
+// Component returns the logger of the given component, or the global logger if the component is unknown.
+func (l *Loggers) Component(name string) zerolog.Logger {
+	if logger, ok := l.components[name]; ok {
+		return logger
+	}
+
+	return l.global
+}
+
+// SetComponentLevels sets the log levels of the components, replacing the previous ones. Components without level use
+// the global level.
+func (l *Loggers) SetComponentLevels(componentLevels map[string]string) error {
+	parsed := make(map[string]zerolog.Level, len(componentLevels))
+	for component, level := range componentLevels {
+		if _, ok := l.components[component]; !ok {
+			return fmt.Errorf("unknown component %q: must be one of %s", component, strings.Join(componentNames(), ", "))
+		}
+
+		lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+		if err != nil {
+			return fmt.Errorf("invalid level for component %q: %w", component, err)
+		}
+		parsed[component] = lvl
+	}
+
+	l.levels.setComponents(parsed)
+
+	return nil
+}
+
+// levelsResp is the response of the level handler.
+type levelsResp struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+// LevelHandler returns an HTTP handler exposing the log levels. A PUT request replaces the component levels with the
+// ones of its body, a JSON object mapping component names to levels.
+func (l *Loggers) LevelHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var componentLevels map[string]string
+			if err := json.NewDecoder(req.Body).Decode(&componentLevels); err != nil {
+				http.Error(rw, fmt.Sprintf("decode levels: %s", err), http.StatusBadRequest)
+				return
+			}
+
+			if err := l.SetComponentLevels(componentLevels); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			l.global.Info().Interface("components", componentLevels).Msg("Component log levels updated")
+		default:
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		base, componentLevels := l.levels.get()
+
+		resp := levelsResp{
+			Level:      base.String(),
+			Components: make(map[string]string, len(componentLevels)),
+		}
+		for component, level := range componentLevels {
+			resp.Components[component] = level.String()
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(rw).Encode(resp); err != nil {
+			l.global.Error().Err(err).Msg("Unable to write log levels")
+		}
+	})
+}
+
+// levelHook discards the events below the level of its component, or the global level when it has no component.
+type levelHook struct {
+	levels    *levelRegistry
+	component string
+}
+
+// Run implements zerolog.Hook.
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level < h.levels.level(h.component) {
+		e.Discard()
+	}
+}
+
+// levelRegistry holds the global and component log levels.
+type levelRegistry struct {
+	mu         sync.RWMutex
+	base       zerolog.Level
+	components map[string]zerolog.Level
+}
+
+func (r *levelRegistry) level(component string) zerolog.Level {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if l, ok := r.components[component]; ok {
+		return l
+	}
+
+	return r.base
+}
+
+func (r *levelRegistry) get() (zerolog.Level, map[string]zerolog.Level) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	componentLevels := make(map[string]zerolog.Level, len(r.components))
+	for component, level := range r.components {
+		componentLevels[component] = level
+	}
+
+	return r.base, componentLevels
+}
+
+func (r *levelRegistry) setBase(level zerolog.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.base = level
+	r.updateGlobalLevel()
+}
+
+func (r *levelRegistry) setComponents(componentLevels map[string]zerolog.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.components = componentLevels
+	r.updateGlobalLevel()
+}
+
+// updateGlobalLevel sets the zerolog global level to the most verbose level in use, so events are only filtered by
+// the level hooks. It must be called with the lock held.
+func (r *levelRegistry) updateGlobalLevel() {
+	minLevel := r.base
+	for _, level := range r.components {
+		if level < minLevel {
+			minLevel = level
+		}
+	}
+
+	zerolog.SetGlobalLevel(minLevel)
+}
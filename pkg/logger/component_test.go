@@ -0,0 +1,102 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponent(t *testing.T) {
+	var buf bytes.Buffer
+	loggers := setupTestLoggers(t, &buf)
+
+	require.NoError(t, loggers.SetComponentLevels(map[string]string{ComponentACP: "debug", ComponentMetrics: "error"}))
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+
+	acp := loggers.Component(ComponentACP)
+	topology := loggers.Component(ComponentTopology)
+	metrics := loggers.Component(ComponentMetrics)
+
+	acp.Debug().Msg("acp debug")
+	topology.Debug().Msg("topology debug")
+	topology.Info().Msg("topology info")
+	metrics.Warn().Msg("metrics warn")
+	loggers.global.Debug().Msg("global debug")
+
+	logs := buf.String()
+	assert.Contains(t, logs, "acp debug")
+	assert.Contains(t, logs, `"subsystem":"acp"`)
+	assert.Contains(t, logs, "topology info")
+	assert.NotContains(t, logs, "topology debug")
+	assert.NotContains(t, logs, "metrics warn")
+	assert.NotContains(t, logs, "global debug")
+
+	require.NoError(t, loggers.SetComponentLevels(nil))
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+
+	// Component loggers created before the levels changed follow them.
+	buf.Reset()
+	acp.Debug().Msg("acp debug")
+	assert.Empty(t, buf.String())
+
+	require.Error(t, loggers.SetComponentLevels(map[string]string{"unknown": "debug"}))
+	require.Error(t, loggers.SetComponentLevels(map[string]string{ComponentACP: "verbose"}))
+}
+
+func TestLevelHandler(t *testing.T) {
+	handler := setupTestLoggers(t, &bytes.Buffer{}).LevelHandler()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"devportal":"trace"}`))
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info","components":{"devportal":"trace"}}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"unknown":"trace"}`))
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"info","components":{"devportal":"trace"}}`, rec.Body.String())
+}
+
+func setupTestLoggers(t *testing.T, buf *bytes.Buffer) *Loggers {
+	t.Helper()
+
+	previousLevel := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(previousLevel) })
+
+	loggers := newLoggers(zerolog.New(buf), zerolog.InfoLevel)
+	loggers.levels.setBase(zerolog.InfoLevel)
+
+	return loggers
+}
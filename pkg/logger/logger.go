@@ -27,8 +27,8 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// Setup configures the logger.
-func Setup(level, format string) {
+// Setup configures the global logger and returns the loggers of the components.
+func Setup(level, format string) *Loggers {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 
 	logLevel := zerolog.InfoLevel
@@ -60,10 +60,14 @@ func Setup(level, format string) {
 		logCtx = logCtx.Caller()
 	}
 
-	log.Logger = logCtx.Logger()
+	loggers := newLoggers(logCtx.Logger(), logLevel)
+
+	log.Logger = loggers.global
 	zerolog.DefaultContextLogger = &log.Logger
 
-	zerolog.SetGlobalLevel(logLevel)
+	loggers.levels.setBase(logLevel)
 
 	log.Trace().Str("level", logLevel.String()).Msg("Log level set")
+
+	return loggers
 }
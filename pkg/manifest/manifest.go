@@ -0,0 +1,185 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	hubv1alpha2 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Object is a resource read from a manifest file.
+type Object struct {
+	// Source locates the resource, as the path of its file followed by the index of its document.
+	Source string
+	Kind   schema.GroupVersionKind
+	Object runtime.Object
+	// DecodeErr reports the fields of the resource which are unknown or duplicated.
+	DecodeErr error
+}
+
+// Load reads the resources of the given manifest files. Directories are walked recursively and their YAML and JSON
+// files are read. Resources the agent doesn't know about are ignored. Resources of the hub.traefik.io/v1alpha2 API
+// version are converted to v1alpha1, the version the agent works with.
+func Load(paths []string) ([]Object, error) {
+	decoder, err := newDecoder()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range paths {
+		var found []string
+		found, err = listFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+
+	var objects []Object
+	for _, file := range files {
+		var fileObjects []Object
+		fileObjects, err = loadFile(decoder, file)
+		if err != nil {
+			return nil, fmt.Errorf("load %q: %w", file, err)
+		}
+		objects = append(objects, fileObjects...)
+	}
+
+	return objects, nil
+}
+
+func newDecoder() (runtime.Decoder, error) {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		corev1.AddToScheme,
+		hubv1alpha1.AddToScheme,
+		hubv1alpha2.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return nil, fmt.Errorf("build scheme: %w", err)
+		}
+	}
+
+	// Strict decoding reports unknown fields, which are silently pruned by the API server.
+	return serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDeserializer(), nil
+}
+
+func listFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat %q: %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			if !d.IsDir() {
+				files = append(files, p)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %q: %w", path, err)
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func loadFile(decoder runtime.Decoder, file string) ([]Object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var objects []Object
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(f))
+	for i := 0; ; i++ {
+		var doc []byte
+		doc, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read document %d: %w", i, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj, gvk, err := decoder.Decode(doc, nil, nil)
+		var decodeErr error
+		switch {
+		case runtime.IsNotRegisteredError(err) || runtime.IsMissingKind(err):
+			continue
+		case runtime.IsStrictDecodingError(err):
+			decodeErr = err
+		case err != nil:
+			return nil, fmt.Errorf("decode document %d: %w", i, err)
+		}
+
+		objects = append(objects, Object{
+			Source:    fmt.Sprintf("%s#%d", file, i),
+			Kind:      *gvk,
+			Object:    toV1alpha1(obj),
+			DecodeErr: decodeErr,
+		})
+	}
+}
+
+func toV1alpha1(obj runtime.Object) runtime.Object {
+	switch o := obj.(type) {
+	case *hubv1alpha2.API:
+		return hubv1alpha2.ConvertAPIToV1alpha1(o)
+	case *hubv1alpha2.APICollection:
+		return hubv1alpha2.ConvertAPICollectionToV1alpha1(o)
+	case *hubv1alpha2.APIGateway:
+		return hubv1alpha2.ConvertAPIGatewayToV1alpha1(o)
+	default:
+		return obj
+	}
+}
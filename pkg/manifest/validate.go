@@ -0,0 +1,143 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package manifest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/auth"
+	"github.com/traefik/hub-agent-kubernetes/pkg/api/admission/validation"
+	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// errSecretNotProvided is returned when a policy references a Secret which isn't part of the validated resources.
+var errSecretNotProvided = errors.New("secret not provided")
+
+// Result is the outcome of the validation of a resource.
+type Result struct {
+	Object
+
+	// Errors are the reasons why the resource would be rejected or couldn't be served.
+	Errors []string
+	// Skipped explains why the resource couldn't be validated, if so.
+	Skipped string
+}
+
+// Valid returns whether the resource is valid.
+func (r Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Validate validates the AccessControlPolicies and API management resources among the given objects, with the same
+// rules as the admission webhooks of the agent. Resources are validated against each other instead of the cluster
+// state: overlapping path prefixes are looked for among the given resources, Services referenced by APIs are checked
+// only when Services are given, and the Secrets referenced by policies must be given for the policies to be checked.
+func Validate(objects []Object) ([]Result, error) {
+	runtimeObjects := make([]runtime.Object, 0, len(objects))
+	secrets := make(secretGetter)
+	for _, object := range objects {
+		runtimeObjects = append(runtimeObjects, object.Object)
+
+		if secret, ok := object.Object.(*corev1.Secret); ok {
+			secrets.add(secret)
+		}
+	}
+
+	apiHandler, err := validation.NewStaticHandler(runtimeObjects)
+	if err != nil {
+		return nil, fmt.Errorf("create API validation handler: %w", err)
+	}
+
+	var results []Result
+	for _, object := range objects {
+		result := Result{Object: object}
+
+		switch obj := object.Object.(type) {
+		case *hubv1alpha1.AccessControlPolicy:
+			result.Errors, result.Skipped = validatePolicy(obj, secrets)
+		case *hubv1alpha1.API, *hubv1alpha1.APICollection, *hubv1alpha1.APIPortal, *hubv1alpha1.APIGateway:
+			errs, err := apiHandler.Validate(obj)
+			if err != nil {
+				return nil, fmt.Errorf("validate %s: %w", object.Source, err)
+			}
+			for _, e := range errs {
+				result.Errors = append(result.Errors, e.Error())
+			}
+		default:
+			continue
+		}
+
+		if object.DecodeErr != nil {
+			result.Errors = append([]string{object.DecodeErr.Error()}, result.Errors...)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func validatePolicy(policy *hubv1alpha1.AccessControlPolicy, secrets acp.SecretGetter) (errs []string, skipped string) {
+	cfg, err := acp.ConfigFromPolicyWithSecret(policy, secrets)
+	if errors.Is(err, errSecretNotProvided) {
+		return nil, err.Error()
+	}
+	if err != nil {
+		return []string{err.Error()}, ""
+	}
+
+	if err = auth.ValidatePolicy(policy.Name, cfg); err != nil {
+		return []string{err.Error()}, ""
+	}
+
+	return nil, ""
+}
+
+// secretGetter resolves the Secret references of policies with the given Secrets.
+type secretGetter map[string]map[string][]byte
+
+func (g secretGetter) add(secret *corev1.Secret) {
+	data := make(map[string][]byte, len(secret.Data)+len(secret.StringData))
+	for key, value := range secret.Data {
+		data[key] = value
+	}
+	for key, value := range secret.StringData {
+		data[key] = []byte(value)
+	}
+
+	g[secret.Namespace+"/"+secret.Name] = data
+}
+
+// GetValue implements acp.SecretGetter.
+func (g secretGetter) GetValue(secret *corev1.SecretReference, key string) ([]byte, error) {
+	data, ok := g[secret.Namespace+"/"+secret.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", errSecretNotProvided, secret.Namespace, secret.Name)
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("no key %q in secret %q in namespace %q", key, secret.Name, secret.Namespace)
+	}
+
+	return value, nil
+}
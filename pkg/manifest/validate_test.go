@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package manifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	objects, err := Load([]string{"testdata/manifests"})
+	require.NoError(t, err)
+
+	// The Service and the APIAccess of apis.yaml and the ConfigMap of policies.yml are loaded but not validated.
+	assert.Len(t, objects, 10)
+
+	results, err := Validate(objects)
+	require.NoError(t, err)
+
+	type result struct {
+		source  string
+		kind    string
+		errors  []string
+		skipped string
+	}
+	var got []result
+	for _, r := range results {
+		got = append(got, result{source: r.Source, kind: r.Kind.Kind, errors: r.Errors, skipped: r.Skipped})
+	}
+
+	want := []result{
+		{
+			source: "testdata/manifests/acps/policies.yml#0",
+			kind:   "AccessControlPolicy",
+		},
+		{
+			source: "testdata/manifests/acps/policies.yml#1",
+			kind:   "AccessControlPolicy",
+			errors: []string{"at least one key must be defined"},
+		},
+		{
+			source:  "testdata/manifests/acps/policies.yml#2",
+			kind:    "AccessControlPolicy",
+			skipped: "getting client secret: secret not provided: hub-agent/oidc",
+		},
+		{
+			source: "testdata/manifests/acps/policies.yml#3",
+			kind:   "AccessControlPolicy",
+			errors: []string{`strict decoding error: unknown field "spec.basicAuth.typo"`},
+		},
+		{
+			source: "testdata/manifests/apis.yaml#1",
+			kind:   "API",
+			errors: []string{`spec.pathPrefix: Invalid value: "/books": path prefix "/books" overlaps with API "stores@default" on APIGateway "gateway"`},
+		},
+		{
+			source: "testdata/manifests/apis.yaml#2",
+			kind:   "API",
+			errors: []string{`spec.service.port.name: Not found: "grpc"`},
+		},
+		{
+			source: "testdata/manifests/apis.yaml#4",
+			kind:   "APIGateway",
+		},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestLoad_unknownPath(t *testing.T) {
+	_, err := Load([]string{"testdata/unknown"})
+	assert.Error(t, err)
+}
@@ -17,6 +17,11 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 
 package metrics
 
+import (
+	"math"
+	"sort"
+)
+
 // DataPoints contains a slice of data points.
 type DataPoints []DataPoint
 
@@ -41,6 +46,9 @@ func (p DataPoints) Aggregate() DataPoint {
 		newPnt.RequestClientErrs += pnt.RequestClientErrs
 		newPnt.ResponseTimeSum += pnt.ResponseTimeSum
 		newPnt.ResponseTimeCount += pnt.ResponseTimeCount
+
+		// Percentiles can't be aggregated, the highest one is kept as an upper bound.
+		newPnt.P95ResponseTime = math.Max(newPnt.P95ResponseTime, pnt.P95ResponseTime)
 	}
 
 	if newPnt.Seconds > 0 {
@@ -68,6 +76,85 @@ type DataPointGroup struct {
 	DataPoints  []DataPoint `avro:"data_points"`
 }
 
+// OtherGroup is the name of the group into which TopGroups rolls the data points of the groups it doesn't keep.
+const OtherGroup = "other"
+
+// TopGroups keeps the n groups with the most requests and rolls the others into a single group, whose edge ingress,
+// ingress and service are OtherGroup, bounding the number of groups sent for clusters with many ingresses and
+// services. Groups are returned unchanged when n isn't positive or when there are at most n+1 groups.
+func TopGroups(groups []DataPointGroup, n int) []DataPointGroup {
+	if n <= 0 || len(groups) <= n+1 {
+		return groups
+	}
+
+	requests := make([]int64, len(groups))
+	for i, group := range groups {
+		for _, pnt := range group.DataPoints {
+			requests[i] += pnt.Requests
+		}
+	}
+
+	idx := make([]int, len(groups))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return requests[idx[i]] > requests[idx[j]]
+	})
+
+	top := make([]DataPointGroup, 0, n+1)
+	for _, i := range idx[:n] {
+		top = append(top, groups[i])
+	}
+
+	other := DataPointGroup{EdgeIngress: OtherGroup, Ingress: OtherGroup, Service: OtherGroup}
+	for _, i := range idx[n:] {
+		for _, pnt := range groups[i].DataPoints {
+			j, otherPnt := DataPoints(other.DataPoints).Get(pnt.Timestamp)
+			if j == -1 {
+				other.DataPoints = append(other.DataPoints, pnt)
+				continue
+			}
+
+			other.DataPoints[j] = mergeDataPoints(otherPnt, pnt)
+		}
+	}
+	sort.Slice(other.DataPoints, func(i, j int) bool {
+		return other.DataPoints[i].Timestamp < other.DataPoints[j].Timestamp
+	})
+
+	return append(top, other)
+}
+
+// mergeDataPoints merges two data points of different groups covering the same period.
+func mergeDataPoints(a, b DataPoint) DataPoint {
+	pnt := DataPoint{
+		Timestamp:         a.Timestamp,
+		Seconds:           a.Seconds,
+		Requests:          a.Requests + b.Requests,
+		RequestErrs:       a.RequestErrs + b.RequestErrs,
+		RequestClientErrs: a.RequestClientErrs + b.RequestClientErrs,
+		ResponseTimeSum:   a.ResponseTimeSum + b.ResponseTimeSum,
+		ResponseTimeCount: a.ResponseTimeCount + b.ResponseTimeCount,
+		P95ResponseTime:   math.Max(a.P95ResponseTime, b.P95ResponseTime),
+	}
+
+	if pnt.Seconds > 0 {
+		pnt.ReqPerS = float64(pnt.Requests) / float64(pnt.Seconds)
+		pnt.RequestErrPerS = float64(pnt.RequestErrs) / float64(pnt.Seconds)
+		pnt.RequestClientErrPerS = float64(pnt.RequestClientErrs) / float64(pnt.Seconds)
+	}
+	if pnt.ResponseTimeCount > 0 {
+		pnt.AvgResponseTime = pnt.ResponseTimeSum / float64(pnt.ResponseTimeCount)
+	}
+	if pnt.Requests > 0 {
+		pnt.RequestErrPercent = float64(pnt.RequestErrs) / float64(pnt.Requests)
+		pnt.RequestClientErrPercent = float64(pnt.RequestClientErrs) / float64(pnt.Requests)
+	}
+
+	return pnt
+}
+
 // DataPoint contains fully aggregated metrics.
 type DataPoint struct {
 	Timestamp int64 `avro:"timestamp"`
@@ -85,6 +172,10 @@ type DataPoint struct {
 	RequestClientErrs int64   `avro:"request_client_errors"`
 	ResponseTimeSum   float64 `avro:"response_time_sum"`
 	ResponseTimeCount int64   `avro:"response_time_count"`
+
+	// P95ResponseTime is the 95th percentile of the response time. It is only used locally, for alerting, and isn't
+	// sent to the platform.
+	P95ResponseTime float64 `avro:"-"`
 }
 
 // SetKey contains the primary key of a metric set.
@@ -114,6 +205,12 @@ func (s MetricSet) RelativeTo(o MetricSet) MetricSet {
 	if !o.RequestDuration.Relative {
 		s.RequestDuration.Sum -= o.RequestDuration.Sum
 		s.RequestDuration.Count -= o.RequestDuration.Count
+
+		buckets := make(map[float64]int64, len(s.RequestDuration.Buckets))
+		for upperBound, count := range s.RequestDuration.Buckets {
+			buckets[upperBound] = count - o.RequestDuration.Buckets[upperBound]
+		}
+		s.RequestDuration.Buckets = buckets
 	}
 	return s
 }
@@ -141,6 +238,7 @@ func (s MetricSet) ToDataPoint(secs int64) DataPoint {
 		RequestClientErrs:       s.RequestClientErrors,
 		ResponseTimeSum:         s.RequestDuration.Sum,
 		ResponseTimeCount:       s.RequestDuration.Count,
+		P95ResponseTime:         s.RequestDuration.Quantile(0.95),
 	}
 }
 
@@ -149,6 +247,48 @@ type ServiceHistogram struct {
 	Relative bool
 	Sum      float64
 	Count    int64
+	// Buckets holds the cumulative count of observations by bucket upper bound.
+	Buckets map[float64]int64
+}
+
+// Quantile estimates the q-quantile of the observations from the histogram buckets, interpolating linearly within
+// the bucket holding it as Prometheus does. It returns 0 if there are no buckets or no observations.
+func (h ServiceHistogram) Quantile(q float64) float64 {
+	upperBounds := make([]float64, 0, len(h.Buckets))
+	for upperBound := range h.Buckets {
+		upperBounds = append(upperBounds, upperBound)
+	}
+	sort.Float64s(upperBounds)
+
+	if len(upperBounds) == 0 {
+		return 0
+	}
+
+	total := h.Buckets[upperBounds[len(upperBounds)-1]]
+	if total <= 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+
+	var lowerBound float64
+	var lowerCount int64
+	for _, upperBound := range upperBounds {
+		count := h.Buckets[upperBound]
+		if float64(count) < rank {
+			lowerBound, lowerCount = upperBound, count
+			continue
+		}
+
+		// The quantile falls in the +Inf bucket, the highest finite upper bound is the best estimate.
+		if math.IsInf(upperBound, 1) {
+			return lowerBound
+		}
+
+		return lowerBound + (upperBound-lowerBound)*(rank-float64(lowerCount))/float64(count-lowerCount)
+	}
+
+	return lowerBound
 }
 
 // Aggregate aggregates metrics into a service metric set.
@@ -181,6 +321,12 @@ func Aggregate(m []Metric) map[SetKey]MetricSet {
 			dur.Sum += val.Sum
 			dur.Count += int64(val.Count)
 			dur.Relative = val.Relative
+			if len(val.Buckets) > 0 && dur.Buckets == nil {
+				dur.Buckets = make(map[float64]int64, len(val.Buckets))
+			}
+			for upperBound, count := range val.Buckets {
+				dur.Buckets[upperBound] += int64(count)
+			}
 			svc.RequestDuration = dur
 		}
 
@@ -18,6 +18,7 @@ along with this program. If not, see <https://www.gnu.org/licenses/>.
 package metrics_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -166,3 +167,120 @@ func TestAggregator_Aggregate(t *testing.T) {
 		},
 	})
 }
+
+func TestServiceHistogram_Quantile(t *testing.T) {
+	tests := []struct {
+		desc    string
+		buckets map[float64]int64
+		q       float64
+		want    float64
+	}{
+		{
+			desc: "no buckets",
+			q:    0.95,
+		},
+		{
+			desc:    "no observations",
+			buckets: map[float64]int64{0.1: 0, math.Inf(1): 0},
+			q:       0.95,
+		},
+		{
+			desc:    "interpolated within the first bucket",
+			buckets: map[float64]int64{0.1: 100, 0.5: 100, math.Inf(1): 100},
+			q:       0.5,
+			want:    0.05,
+		},
+		{
+			desc:    "interpolated within a bucket",
+			buckets: map[float64]int64{0.1: 50, 0.5: 100, math.Inf(1): 100},
+			q:       0.95,
+			want:    0.46,
+		},
+		{
+			desc:    "in the +Inf bucket",
+			buckets: map[float64]int64{0.1: 50, 0.5: 90, math.Inf(1): 100},
+			q:       0.95,
+			want:    0.5,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			h := metrics.ServiceHistogram{Buckets: test.buckets}
+
+			assert.InDelta(t, test.want, h.Quantile(test.q), 1e-9)
+		})
+	}
+}
+
+func TestMetricSet_ToDataPoint_p95ResponseTime(t *testing.T) {
+	prev := metrics.MetricSet{
+		Requests:        100,
+		RequestDuration: metrics.ServiceHistogram{Sum: 10, Count: 100, Buckets: map[float64]int64{0.1: 100, 1: 100, math.Inf(1): 100}},
+	}
+	curr := metrics.MetricSet{
+		Requests:        200,
+		RequestDuration: metrics.ServiceHistogram{Sum: 60, Count: 200, Buckets: map[float64]int64{0.1: 100, 1: 200, math.Inf(1): 200}},
+	}
+
+	// All the requests of the period took between 0.1s and 1s.
+	got := curr.RelativeTo(prev).ToDataPoint(60)
+
+	assert.InDelta(t, 0.955, got.P95ResponseTime, 1e-9)
+}
+
+func TestTopGroups(t *testing.T) {
+	groups := []metrics.DataPointGroup{
+		{EdgeIngress: "quiet@default", DataPoints: metrics.DataPoints{
+			{Timestamp: 60, Seconds: 60, Requests: 60, RequestErrs: 6, ResponseTimeSum: 6, ResponseTimeCount: 60},
+		}},
+		{Ingress: "busy@default.ingress.networking.k8s.io", DataPoints: metrics.DataPoints{
+			{Timestamp: 60, Seconds: 60, Requests: 600},
+			{Timestamp: 120, Seconds: 60, Requests: 600},
+		}},
+		{Service: "idle@default", DataPoints: metrics.DataPoints{
+			{Timestamp: 120, Seconds: 60, Requests: 60, RequestClientErrs: 30, ResponseTimeSum: 2, ResponseTimeCount: 60},
+			{Timestamp: 60, Seconds: 60, Requests: 120, RequestErrs: 6, ResponseTimeSum: 6, ResponseTimeCount: 120},
+		}},
+	}
+
+	got := metrics.TopGroups(groups, 1)
+
+	want := []metrics.DataPointGroup{
+		groups[1],
+		{
+			EdgeIngress: metrics.OtherGroup,
+			Ingress:     metrics.OtherGroup,
+			Service:     metrics.OtherGroup,
+			DataPoints: metrics.DataPoints{
+				{
+					Timestamp:         60,
+					Seconds:           60,
+					Requests:          180,
+					RequestErrs:       12,
+					ResponseTimeSum:   12,
+					ResponseTimeCount: 180,
+					ReqPerS:           3,
+					RequestErrPerS:    0.2,
+					RequestErrPercent: float64(12) / 180,
+					AvgResponseTime:   float64(12) / 180,
+				},
+				{
+					Timestamp:         120,
+					Seconds:           60,
+					Requests:          60,
+					RequestClientErrs: 30,
+					ResponseTimeSum:   2,
+					ResponseTimeCount: 60,
+				},
+			},
+		},
+	}
+	assert.Equal(t, want, got)
+
+	assert.Equal(t, groups, metrics.TopGroups(groups, 2))
+	assert.Equal(t, groups, metrics.TopGroups(groups, 0))
+}
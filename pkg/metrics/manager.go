@@ -24,7 +24,8 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
 )
 
@@ -32,34 +33,52 @@ const scrapeInterval = time.Minute
 
 // Manager orchestrates metrics scraping and sending.
 type Manager struct {
-	store      *Store
-	client     *Client
-	traefikURL string
-	scraper    *Scraper
+	store   *Store
+	client  *Client
+	targets []scrapeTarget
+	scraper *Scraper
 
 	sendMu     sync.Mutex
 	sendIntvl  time.Duration
 	sendTables []string
+	topGroups  int
 
 	state atomic.Value
+
+	tracker *status.Tracker
+	logger  zerolog.Logger
 }
 
-// NewManager returns a manager.
-func NewManager(client *Client, traefikURL string, store *Store, scraper *Scraper) *Manager {
+// NewManager returns a manager logging with the given metrics component logger.
+func NewManager(client *Client, traefikURL string, store *Store, scraper *Scraper, logger zerolog.Logger) *Manager {
 	var st atomic.Value
 	st.Store(&state.Cluster{})
 
 	return &Manager{
 		store:      store,
 		client:     client,
-		traefikURL: traefikURL,
+		targets:    []scrapeTarget{{parser: ParserTraefik, url: traefikURL}},
 		scraper:    scraper,
 		sendIntvl:  time.Minute,
 		sendTables: []string{"1m", "10m", "1h", "1d"},
 		state:      st,
+		tracker:    status.NewTracker(),
+		logger:     logger,
 	}
 }
 
+// scrapeTarget is a target scraped by the manager with a given parser.
+type scrapeTarget struct {
+	parser string
+	url    string
+}
+
+// AddTarget adds a target, such as the Prometheus federating the metrics of service mesh sidecars, whose metrics are
+// scraped along with the Traefik ones using the given parser. It must be called before running the manager.
+func (m *Manager) AddTarget(parser, url string) {
+	m.targets = append(m.targets, scrapeTarget{parser: parser, url: url})
+}
+
 // SetConfig updates the configuration of the metrics manager.
 func (m *Manager) SetConfig(sendInterval time.Duration, sendTables []string) {
 	m.sendMu.Lock()
@@ -69,6 +88,27 @@ func (m *Manager) SetConfig(sendInterval time.Duration, sendTables []string) {
 	m.sendTables = sendTables
 }
 
+// Status reports the metrics push as down when the metrics couldn't be sent to the platform for three send intervals.
+func (m *Manager) Status(_ context.Context) status.Report {
+	return m.tracker.Report(3 * m.getSendInterval())
+}
+
+// SetTopGroups sets the number of ingress and service groups whose data points are sent as is, the data points of the
+// other groups being rolled into a single group. All groups are sent as is when n is zero.
+func (m *Manager) SetTopGroups(n int) {
+	m.sendMu.Lock()
+	defer m.sendMu.Unlock()
+
+	m.topGroups = n
+}
+
+func (m *Manager) getTopGroups() int {
+	m.sendMu.Lock()
+	defer m.sendMu.Unlock()
+
+	return m.topGroups
+}
+
 // TopologyStateChanged is called every time the topology state changes.
 func (m *Manager) TopologyStateChanged(_ context.Context, cluster *state.Cluster) {
 	if cluster == nil {
@@ -106,8 +146,10 @@ func (m *Manager) runSender(ctx context.Context) {
 			return
 
 		case <-time.After(m.getSendInterval()):
-			if err := m.send(ctx, m.getSendTables()); err != nil {
-				log.Error().Err(err).Msg("Unable to send metrics")
+			err := m.send(ctx, m.getSendTables())
+			m.tracker.Record(err)
+			if err != nil {
+				m.logger.Error().Err(err).Msg("Unable to send metrics")
 			}
 		}
 	}
@@ -149,6 +191,12 @@ func (m *Manager) send(ctx context.Context, tbls []string) error {
 		return nil
 	}
 
+	if n := m.getTopGroups(); n > 0 {
+		for tbl, groups := range toSend {
+			toSend[tbl] = TopGroups(groups, n)
+		}
+	}
+
 	if err := m.client.Send(ctx, toSend); err != nil {
 		return err
 	}
@@ -162,16 +210,13 @@ func (m *Manager) send(ctx context.Context, tbls []string) error {
 }
 
 func (m *Manager) startScraper(ctx context.Context) {
-	mtrcs, err := m.scraper.Scrape(ctx, ParserTraefik, m.traefikURL, ScrapeState{
-		Ingresses: m.getIngresses(),
-	})
-	if err != nil {
-		log.Error().Err(err).Msg("Unable to scrape metrics")
-		return
+	// Data points are computed relatively to the previous metrics of each target. A target failing to be scraped keeps
+	// its previous metrics as reference until the next successful scrape.
+	refs := make([]map[SetKey]MetricSet, len(m.targets))
+	for i, target := range m.targets {
+		refs[i], _ = m.scrape(ctx, target)
 	}
 
-	ref := Aggregate(mtrcs)
-
 	tick := time.NewTicker(scrapeInterval)
 	defer tick.Stop()
 
@@ -182,36 +227,54 @@ func (m *Manager) startScraper(ctx context.Context) {
 			return
 
 		case <-tick.C:
-			mtrcs, err = m.scraper.Scrape(ctx, ParserTraefik, m.traefikURL, ScrapeState{
-				Ingresses: m.getIngresses(),
-			})
-			if err != nil {
-				log.Error().Err(err).Msg("Unable to scrape metrics")
-				return
-			}
+			ts := time.Now().UTC().Truncate(time.Minute).Unix()
 
-			mtrcSet := Aggregate(mtrcs)
+			// Targets report disjoint keys: Traefik metrics are keyed by ingress while service mesh metrics are keyed
+			// by Service.
+			pnts := make(map[SetKey]DataPoint)
+			for i, target := range m.targets {
+				mtrcSet, ok := m.scrape(ctx, target)
+				if !ok {
+					continue
+				}
 
-			ts := time.Now().UTC().Truncate(time.Minute).Unix()
+				if refs[i] != nil {
+					for key, mtrc := range mtrcSet {
+						mtrc = mtrc.RelativeTo(refs[i][key])
 
-			pnts := make(map[SetKey]DataPoint, len(mtrcSet))
-			for key, mtrc := range mtrcSet {
-				mtrc = mtrc.RelativeTo(ref[key])
+						pnt := mtrc.ToDataPoint(scrapeSec)
+						pnt.Timestamp = ts
+						pnt.Seconds = scrapeSec
 
-				pnt := mtrc.ToDataPoint(scrapeSec)
-				pnt.Timestamp = ts
-				pnt.Seconds = scrapeSec
+						pnts[key] = pnt
+					}
+				}
 
-				pnts[key] = pnt
+				refs[i] = mtrcSet
 			}
 
 			m.store.Insert(pnts)
-
-			ref = mtrcSet
 		}
 	}
 }
 
+// scrape scrapes the given target and aggregates its metrics. It returns false if the target couldn't be scraped.
+func (m *Manager) scrape(ctx context.Context, target scrapeTarget) (map[SetKey]MetricSet, bool) {
+	mtrcs, err := m.scraper.Scrape(ctx, target.parser, target.url, ScrapeState{
+		Ingresses: m.getIngresses(),
+		Services:  m.getServices(),
+	})
+	if err != nil {
+		m.logger.Error().
+			Err(err).
+			Str("parser", target.parser).
+			Msg("Unable to scrape metrics")
+		return nil, false
+	}
+
+	return Aggregate(mtrcs), true
+}
+
 func (m *Manager) getIngresses() map[string]struct{} {
 	cluster := m.state.Load().(*state.Cluster)
 
@@ -222,3 +285,14 @@ func (m *Manager) getIngresses() map[string]struct{} {
 
 	return ingresses
 }
+
+func (m *Manager) getServices() map[string]struct{} {
+	cluster := m.state.Load().(*state.Cluster)
+
+	services := make(map[string]struct{}, len(cluster.Services))
+	for name := range cluster.Services {
+		services[name] = struct{}{}
+	}
+
+	return services
+}
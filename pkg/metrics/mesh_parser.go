@@ -0,0 +1,158 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	dto "github.com/prometheus/client_model/go"
+)
+
+// IstioParser parses the standard metrics reported by the Envoy sidecars of Istio into a common form. Only the
+// metrics reported by the destination sidecars are kept, so each request is counted once, on the backend side.
+type IstioParser struct{}
+
+// NewIstioParser returns an Istio metrics parser.
+func NewIstioParser() IstioParser {
+	return IstioParser{}
+}
+
+// Parse parses metrics into a common form.
+func (p IstioParser) Parse(m *dto.MetricFamily, state ScrapeState) []Metric {
+	if m == nil || m.Name == nil {
+		return nil
+	}
+
+	var metrics []Metric
+	for _, metric := range m.Metric {
+		if reporter := getLabel(metric.Label, "reporter"); reporter != "" && reporter != "destination" {
+			continue
+		}
+
+		service := meshService(metric.Label, "destination_service_name", "destination_service_namespace", state)
+		if service == "" {
+			continue
+		}
+
+		switch *m.Name {
+		case "istio_request_duration_milliseconds":
+			metrics = append(metrics, parseMeshDuration(metric, service)...)
+
+		case "istio_requests_total":
+			metrics = append(metrics, parseMeshRequests(metric, service, "response_code")...)
+		}
+	}
+
+	return metrics
+}
+
+// LinkerdParser parses the metrics reported by the Linkerd proxies into a common form. Only the outbound metrics are
+// kept, as they are the ones labeled with the destination Service.
+type LinkerdParser struct{}
+
+// NewLinkerdParser returns a Linkerd metrics parser.
+func NewLinkerdParser() LinkerdParser {
+	return LinkerdParser{}
+}
+
+// Parse parses metrics into a common form.
+func (p LinkerdParser) Parse(m *dto.MetricFamily, state ScrapeState) []Metric {
+	if m == nil || m.Name == nil {
+		return nil
+	}
+
+	var metrics []Metric
+	for _, metric := range m.Metric {
+		if getLabel(metric.Label, "direction") != "outbound" {
+			continue
+		}
+
+		service := meshService(metric.Label, "dst_service", "dst_namespace", state)
+		if service == "" {
+			continue
+		}
+
+		switch *m.Name {
+		case "response_latency_ms":
+			metrics = append(metrics, parseMeshDuration(metric, service)...)
+
+		case "response_total":
+			metrics = append(metrics, parseMeshRequests(metric, service, "status_code")...)
+		}
+	}
+
+	return metrics
+}
+
+// meshService returns the key of the Service the given labels refer to, or an empty string if it isn't known.
+func meshService(lbls []*dto.LabelPair, nameLabel, namespaceLabel string, state ScrapeState) string {
+	name, namespace := getLabel(lbls, nameLabel), getLabel(lbls, namespaceLabel)
+	if name == "" || namespace == "" {
+		return ""
+	}
+
+	key := name + "@" + namespace
+	if _, ok := state.Services[key]; !ok {
+		return ""
+	}
+
+	return key
+}
+
+// parseMeshDuration parses a request duration histogram, in milliseconds, of the given Service.
+func parseMeshDuration(metric *dto.Metric, service string) []Metric {
+	hist := HistogramFromMetric(metric)
+	if hist == nil {
+		return nil
+	}
+
+	hist.Name = MetricRequestDuration
+	hist.Service = service
+	hist.Sum /= 1000
+
+	buckets := make(map[float64]uint64, len(hist.Buckets))
+	for upperBound, count := range hist.Buckets {
+		buckets[upperBound/1000] = count
+	}
+	hist.Buckets = buckets
+
+	return []Metric{hist}
+}
+
+// parseMeshRequests parses a request counter of the given Service, along with the error counter matching its status
+// code label.
+func parseMeshRequests(metric *dto.Metric, service, statusLabel string) []Metric {
+	counter := CounterFromMetric(metric)
+	if counter == 0 {
+		return nil
+	}
+
+	metrics := []Metric{&Counter{
+		Name:    MetricRequests,
+		Service: service,
+		Value:   counter,
+	}}
+
+	if metricErrorName := getMetricErrorName(metric.Label, statusLabel); metricErrorName != "" {
+		metrics = append(metrics, &Counter{
+			Name:    metricErrorName,
+			Service: service,
+			Value:   counter,
+		})
+	}
+
+	return metrics
+}
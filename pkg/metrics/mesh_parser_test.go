@@ -0,0 +1,89 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+)
+
+func TestScraper_ScrapeMesh(t *testing.T) {
+	tests := []struct {
+		desc    string
+		parser  string
+		metrics string
+		want    []metrics.Metric
+	}{
+		{
+			desc:    "Istio",
+			parser:  metrics.ParserIstio,
+			metrics: "testdata/istio-metrics.txt",
+			want: []metrics.Metric{
+				&metrics.Counter{Name: metrics.MetricRequests, Service: "books@default", Value: 40},
+				&metrics.Counter{Name: metrics.MetricRequests, Service: "books@default", Value: 3},
+				&metrics.Counter{Name: metrics.MetricRequestErrors, Service: "books@default", Value: 3},
+				&metrics.Counter{Name: metrics.MetricRequests, Service: "books@default", Value: 2},
+				&metrics.Counter{Name: metrics.MetricRequestClientErrors, Service: "books@default", Value: 2},
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, Service: "books@default", Sum: 2, Count: 40,
+					Buckets: map[float64]uint64{0.1: 40, math.Inf(1): 40},
+				},
+			},
+		},
+		{
+			desc:    "Linkerd",
+			parser:  metrics.ParserLinkerd,
+			metrics: "testdata/linkerd-metrics.txt",
+			want: []metrics.Metric{
+				&metrics.Counter{Name: metrics.MetricRequests, Service: "books@default", Value: 40},
+				&metrics.Counter{Name: metrics.MetricRequests, Service: "books@default", Value: 3},
+				&metrics.Counter{Name: metrics.MetricRequestErrors, Service: "books@default", Value: 3},
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, Service: "books@default", Sum: 2, Count: 40,
+					Buckets: map[float64]uint64{0.1: 40, math.Inf(1): 40},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srvURL := startServer(t, test.metrics)
+			state := metrics.ScrapeState{
+				Services: map[string]struct{}{"books@default": {}},
+			}
+
+			got, err := metrics.NewScraper(http.DefaultClient, nil).Scrape(context.Background(), test.parser, srvURL, state)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.want, got)
+
+			// The parser is detected from the metric families.
+			got, err = metrics.NewScraper(http.DefaultClient, nil).Scrape(context.Background(), metrics.ParserAuto, srvURL, state)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/yaml"
+)
+
+// Relabel actions.
+const (
+	// RelabelDrop drops the matching series.
+	RelabelDrop = "drop"
+	// RelabelKeep drops the series of the matching families which don't match.
+	RelabelKeep = "keep"
+	// RelabelLabelDrop drops the labels of the matching series whose name matches.
+	RelabelLabelDrop = "labeldrop"
+)
+
+// RelabelConfig configures the rules applied, in order, to the scraped metric series before they are parsed, in the
+// spirit of the Prometheus metric relabeling. It allows excluding high cardinality series:
+//
+//	rules:
+//	  - action: drop
+//	    family: traefik_service_.*
+//	  - action: labeldrop
+//	    family: istio_.*
+//	    label: request_path|destination_version
+//	  - action: drop
+//	    family: response_.*
+//	    labels:
+//	      dst_namespace: kube-system
+type RelabelConfig struct {
+	Rules []RelabelRule `json:"rules"`
+}
+
+// RelabelRule is a rule applied to the scraped metric series.
+type RelabelRule struct {
+	Action string `json:"action"`
+	// Family is a regular expression the name of the metric families must fully match. All families match when empty.
+	Family string `json:"family,omitempty"`
+	// Labels are regular expressions the values of the labels of the series must fully match, missing labels having
+	// an empty value.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Label is a regular expression the name of the labels dropped by the labeldrop action must fully match.
+	Label string `json:"label,omitempty"`
+}
+
+// LoadRelabelConfig loads a YAML or JSON relabel configuration from the given file.
+func LoadRelabelConfig(path string) (RelabelConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return RelabelConfig{}, fmt.Errorf("read relabel config: %w", err)
+	}
+
+	var cfg RelabelConfig
+	if err = yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return RelabelConfig{}, fmt.Errorf("unmarshal relabel config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Relabeler applies relabel rules to metric families.
+type Relabeler struct {
+	rules []relabelRule
+}
+
+type relabelRule struct {
+	action string
+	family *regexp.Regexp
+	labels map[string]*regexp.Regexp
+	label  *regexp.Regexp
+}
+
+// NewRelabeler returns a new Relabeler applying the rules of the given configuration.
+func NewRelabeler(cfg RelabelConfig) (*Relabeler, error) {
+	rules := make([]relabelRule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		rule, err := newRelabelRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return &Relabeler{rules: rules}, nil
+}
+
+func newRelabelRule(r RelabelRule) (relabelRule, error) {
+	switch r.Action {
+	case RelabelDrop, RelabelKeep:
+		if r.Label != "" {
+			return relabelRule{}, fmt.Errorf("label is only supported by the %s action", RelabelLabelDrop)
+		}
+
+	case RelabelLabelDrop:
+		if r.Label == "" {
+			return relabelRule{}, fmt.Errorf("label is required by the %s action", RelabelLabelDrop)
+		}
+
+	default:
+		return relabelRule{}, fmt.Errorf("unknown action %q", r.Action)
+	}
+
+	rule := relabelRule{action: r.Action}
+
+	var err error
+	if r.Family != "" {
+		if rule.family, err = compileAnchored(r.Family); err != nil {
+			return relabelRule{}, fmt.Errorf("compile family: %w", err)
+		}
+	}
+
+	if r.Label != "" {
+		if rule.label, err = compileAnchored(r.Label); err != nil {
+			return relabelRule{}, fmt.Errorf("compile label: %w", err)
+		}
+	}
+
+	rule.labels = make(map[string]*regexp.Regexp, len(r.Labels))
+	for name, expr := range r.Labels {
+		if rule.labels[name], err = compileAnchored(expr); err != nil {
+			return relabelRule{}, fmt.Errorf("compile label %q: %w", name, err)
+		}
+	}
+
+	return rule, nil
+}
+
+// Apply applies the rules to the given metric families and returns the remaining ones. Families left without any
+// series are removed.
+func (r *Relabeler) Apply(families []*dto.MetricFamily) []*dto.MetricFamily {
+	if r == nil || len(r.rules) == 0 {
+		return families
+	}
+
+	kept := families[:0]
+	for _, family := range families {
+		for _, rule := range r.rules {
+			if rule.family != nil && !rule.family.MatchString(family.GetName()) {
+				continue
+			}
+
+			family.Metric = rule.apply(family.Metric)
+		}
+
+		if len(family.Metric) > 0 {
+			kept = append(kept, family)
+		}
+	}
+
+	return kept
+}
+
+func (r relabelRule) apply(series []*dto.Metric) []*dto.Metric {
+	kept := series[:0]
+	for _, s := range series {
+		matches := r.matches(s)
+
+		switch r.action {
+		case RelabelDrop:
+			if matches {
+				continue
+			}
+
+		case RelabelKeep:
+			if !matches {
+				continue
+			}
+
+		case RelabelLabelDrop:
+			if matches {
+				s.Label = r.dropLabels(s.Label)
+			}
+		}
+
+		kept = append(kept, s)
+	}
+
+	return kept
+}
+
+func (r relabelRule) matches(series *dto.Metric) bool {
+	for name, expr := range r.labels {
+		if !expr.MatchString(getLabel(series.Label, name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r relabelRule) dropLabels(lbls []*dto.LabelPair) []*dto.LabelPair {
+	kept := lbls[:0]
+	for _, l := range lbls {
+		if r.label.MatchString(l.GetName()) {
+			continue
+		}
+
+		kept = append(kept, l)
+	}
+
+	return kept
+}
+
+// compileAnchored compiles the given regular expression so it must fully match.
+func compileAnchored(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
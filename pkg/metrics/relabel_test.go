@@ -0,0 +1,114 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package metrics_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/metrics"
+)
+
+func TestScraper_ScrapeRelabeled(t *testing.T) {
+	cfg, err := metrics.LoadRelabelConfig("testdata/relabel.yaml")
+	require.NoError(t, err)
+
+	relabeler, err := metrics.NewRelabeler(cfg)
+	require.NoError(t, err)
+
+	srvURL := startServer(t, "testdata/istio-metrics.txt")
+	s := metrics.NewScraper(http.DefaultClient, relabeler)
+
+	got, err := s.Scrape(context.Background(), metrics.ParserIstio, srvURL, metrics.ScrapeState{
+		Services: map[string]struct{}{"books@default": {}},
+	})
+	require.NoError(t, err)
+
+	// Without the response_code label, no error counter is produced.
+	want := []metrics.Metric{
+		&metrics.Counter{Name: metrics.MetricRequests, Service: "books@default", Value: 40},
+		&metrics.Histogram{Name: metrics.MetricRequestDuration, Service: "books@default", Sum: 2, Count: 40,
+			Buckets: map[float64]uint64{0.1: 40, math.Inf(1): 40},
+		},
+	}
+	assert.ElementsMatch(t, want, got)
+}
+
+func TestRelabeler_Apply(t *testing.T) {
+	relabeler, err := metrics.NewRelabeler(metrics.RelabelConfig{
+		Rules: []metrics.RelabelRule{
+			{Action: metrics.RelabelDrop, Family: "go_.*"},
+			{Action: metrics.RelabelLabelDrop, Label: "path"},
+		},
+	})
+	require.NoError(t, err)
+
+	families := []*dto.MetricFamily{
+		family("go_goroutines", map[string]string{}),
+		family("traefik_service_requests_total", map[string]string{"service": "books", "path": "/books/1"}),
+	}
+
+	got := relabeler.Apply(families)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "traefik_service_requests_total", got[0].GetName())
+	require.Len(t, got[0].Metric, 1)
+	require.Len(t, got[0].Metric[0].Label, 1)
+	assert.Equal(t, "service", got[0].Metric[0].Label[0].GetName())
+}
+
+func TestNewRelabeler_invalidConfig(t *testing.T) {
+	tests := []struct {
+		desc string
+		rule metrics.RelabelRule
+	}{
+		{desc: "unknown action", rule: metrics.RelabelRule{Action: "replace"}},
+		{desc: "labeldrop without label", rule: metrics.RelabelRule{Action: metrics.RelabelLabelDrop}},
+		{desc: "drop with label", rule: metrics.RelabelRule{Action: metrics.RelabelDrop, Label: "path"}},
+		{desc: "invalid family", rule: metrics.RelabelRule{Action: metrics.RelabelDrop, Family: "("}},
+		{desc: "invalid label value", rule: metrics.RelabelRule{Action: metrics.RelabelKeep, Labels: map[string]string{"path": "("}}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := metrics.NewRelabeler(metrics.RelabelConfig{Rules: []metrics.RelabelRule{test.rule}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func family(name string, lbls map[string]string) *dto.MetricFamily {
+	metric := &dto.Metric{Counter: &dto.Counter{Value: ptr(1.0)}}
+	for k, v := range lbls {
+		metric.Label = append(metric.Label, &dto.LabelPair{Name: ptr(k), Value: ptr(v)})
+	}
+
+	return &dto.MetricFamily{Name: ptr(name), Metric: []*dto.Metric{metric}}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"sync"
 
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/expfmt"
@@ -33,8 +35,22 @@ import (
 // This should match the topology types.
 const (
 	ParserTraefik = "traefik"
+	ParserIstio   = "istio"
+	ParserLinkerd = "linkerd"
 )
 
+// ParserAuto detects the parser of a target from the names of the metric families of its first scrape.
+const ParserAuto = "auto"
+
+// parserFamilyPrefixes returns the prefixes of the metric families exposed by the targets of each parser.
+func parserFamilyPrefixes() map[string]string {
+	return map[string]string{
+		ParserTraefik: "traefik_",
+		ParserIstio:   "istio_",
+		ParserLinkerd: "response_latency_ms",
+	}
+}
+
 // Metric names.
 const (
 	MetricRequestDuration     = "request_duration"
@@ -94,6 +110,8 @@ type Histogram struct {
 	Service     string
 	Sum         float64
 	Count       uint64
+	// Buckets holds the cumulative count of observations by bucket upper bound.
+	Buckets map[float64]uint64
 }
 
 // HistogramFromMetric returns a histogram metric from a prometheus
@@ -104,9 +122,15 @@ func HistogramFromMetric(m *dto.Metric) *Histogram {
 		return nil
 	}
 
+	buckets := make(map[float64]uint64, len(hist.GetBucket()))
+	for _, bucket := range hist.GetBucket() {
+		buckets[bucket.GetUpperBound()] = bucket.GetCumulativeCount()
+	}
+
 	return &Histogram{
-		Sum:   hist.GetSampleSum(),
-		Count: hist.GetSampleCount(),
+		Sum:     hist.GetSampleSum(),
+		Count:   hist.GetSampleCount(),
+		Buckets: buckets,
 	}
 }
 
@@ -128,6 +152,8 @@ func (h Histogram) ServiceName() string {
 // ScrapeState contains the state used while scraping.
 type ScrapeState struct {
 	Ingresses map[string]struct{}
+	// Services are the known Services, keyed by name@namespace. Service mesh metrics are only kept for them.
+	Services map[string]struct{}
 }
 
 // Parser represents a platform-specific metrics parser.
@@ -137,31 +163,39 @@ type Parser interface {
 
 // Scraper scrapes metrics from Prometheus.
 type Scraper struct {
-	client *http.Client
+	client    *http.Client
+	relabeler *Relabeler
+
+	parsers map[string]Parser
 
-	traefikParser TraefikParser
+	detectedMu sync.Mutex
+	detected   map[string]string
 }
 
-// NewScraper returns a scraper instance with parser p.
-func NewScraper(c *http.Client) *Scraper {
+// NewScraper returns a scraper instance with parser p. The scraped metrics are relabeled by the given Relabeler, if
+// any, before being parsed.
+func NewScraper(c *http.Client, relabeler *Relabeler) *Scraper {
 	return &Scraper{
-		client:        c,
-		traefikParser: NewTraefikParser(),
+		client:    c,
+		relabeler: relabeler,
+		parsers: map[string]Parser{
+			ParserTraefik: NewTraefikParser(),
+			ParserIstio:   NewIstioParser(),
+			ParserLinkerd: NewLinkerdParser(),
+		},
+		detected: make(map[string]string),
 	}
 }
 
-// Scrape returns metrics scraped from all targets.
+// Scrape returns metrics scraped from all targets. When the parser is ParserAuto, the parser of the target is detected
+// on its first scrape and remembered until its metric families don't match it anymore.
 func (s *Scraper) Scrape(ctx context.Context, parser, target string, state ScrapeState) ([]Metric, error) {
 	// This is a naive approach and should be dealt with
 	// as an iterator later to control the amount of RAM
 	// used while scraping many targets with many services.
 	// e.g. 100 pods * 4000 services * 4 metrics = bad news bears (1.6 million)
 
-	var p Parser
-	switch parser {
-	case ParserTraefik:
-		p = s.traefikParser
-	default:
+	if _, ok := s.parsers[parser]; !ok && parser != ParserAuto {
 		return nil, fmt.Errorf("invalid parser %q", parser)
 	}
 
@@ -169,6 +203,15 @@ func (s *Scraper) Scrape(ctx context.Context, parser, target string, state Scrap
 	if err != nil {
 		return nil, fmt.Errorf("unable to get metrics from target %s", target)
 	}
+	raw = s.relabeler.Apply(raw)
+
+	if parser == ParserAuto {
+		parser, err = s.detectParser(target, raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	p := s.parsers[parser]
 
 	var m []Metric
 	for _, v := range raw {
@@ -178,6 +221,48 @@ func (s *Scraper) Scrape(ctx context.Context, parser, target string, state Scrap
 	return m, nil
 }
 
+// detectParser returns the parser of the given target, detecting it from the given metric families if the parser
+// previously detected doesn't match them.
+func (s *Scraper) detectParser(target string, families []*dto.MetricFamily) (string, error) {
+	s.detectedMu.Lock()
+	defer s.detectedMu.Unlock()
+
+	prefixes := parserFamilyPrefixes()
+	if parser, ok := s.detected[target]; ok && countFamilies(families, prefixes[parser]) > 0 {
+		return parser, nil
+	}
+
+	// Targets also expose the metrics of their runtime, so the parser matching the most families is picked.
+	var parser string
+	var matched int
+	for name, prefix := range prefixes {
+		if n := countFamilies(families, prefix); n > matched || (n == matched && n > 0 && name < parser) {
+			parser, matched = name, n
+		}
+	}
+
+	if parser == "" {
+		delete(s.detected, target)
+		return "", fmt.Errorf("unable to detect the parser of target %s: no known metric family", target)
+	}
+
+	s.detected[target] = parser
+
+	return parser, nil
+}
+
+// countFamilies returns the number of metric families whose name starts with the given prefix.
+func countFamilies(families []*dto.MetricFamily, prefix string) int {
+	var n int
+	for _, family := range families {
+		if strings.HasPrefix(family.GetName(), prefix) {
+			n++
+		}
+	}
+
+	return n
+}
+
 func (s *Scraper) scrapeMetrics(ctx context.Context, target string) ([]*dto.MetricFamily, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, http.NoBody)
 	if err != nil {
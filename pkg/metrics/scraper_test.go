@@ -19,6 +19,7 @@ package metrics_test
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -41,7 +42,9 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 			desc:    "Traefik v2.8+",
 			metrics: "testdata/traefik-v2-8-metrics.txt",
 			want: []metrics.Metric{
-				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1},
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1,
+					Buckets: map[float64]uint64{0.1: 1, 0.3: 1, 1.2: 1, 5: 1, math.Inf(1): 1},
+				},
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "myIngress@default", Value: 2},
 				// edge cases, TLS/middleware enable on entrypoint
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "app-obe@whoami", Value: 38},
@@ -51,7 +54,9 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 			desc:    "Traefik older versions",
 			metrics: "testdata/traefik-metrics.txt",
 			want: []metrics.Metric{
-				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1},
+				&metrics.Histogram{Name: metrics.MetricRequestDuration, EdgeIngress: "myIngress@default", Sum: 0.0137623, Count: 1,
+					Buckets: map[float64]uint64{0.1: 1, 0.3: 1, 1.2: 1, 5: 1, math.Inf(1): 1},
+				},
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "myIngress@default", Value: 2},
 				// edge cases, TLS/middleware enable on entrypoint
 				&metrics.Counter{Name: metrics.MetricRequests, EdgeIngress: "app-obe@whoami", Value: 38},
@@ -66,7 +71,7 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 			t.Parallel()
 
 			srvURL := startServer(t, test.metrics)
-			s := metrics.NewScraper(http.DefaultClient)
+			s := metrics.NewScraper(http.DefaultClient, nil)
 
 			got, err := s.Scrape(context.Background(), metrics.ParserTraefik, srvURL, metrics.ScrapeState{
 				Ingresses: map[string]struct{}{
@@ -81,6 +86,35 @@ func TestScraper_ScrapeTraefik(t *testing.T) {
 	}
 }
 
+func TestScraper_ScrapeAuto(t *testing.T) {
+	s := metrics.NewScraper(http.DefaultClient, nil)
+	state := metrics.ScrapeState{
+		Ingresses: map[string]struct{}{
+			"myIngress@default.ingress.networking.k8s.io": {},
+			"app-obe@whoami.ingress.networking.k8s.io":    {},
+		},
+	}
+
+	traefikURL := startServer(t, "testdata/traefik-v2-8-metrics.txt")
+
+	got, err := s.Scrape(context.Background(), metrics.ParserAuto, traefikURL, state)
+	require.NoError(t, err)
+
+	want, err := s.Scrape(context.Background(), metrics.ParserTraefik, traefikURL, state)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, got)
+	assert.ElementsMatch(t, want, got)
+
+	nginxURL := startServer(t, "testdata/nginx-metrics.txt")
+
+	_, err = s.Scrape(context.Background(), metrics.ParserAuto, nginxURL, state)
+	assert.Error(t, err)
+
+	_, err = s.Scrape(context.Background(), "unknown", traefikURL, state)
+	assert.Error(t, err)
+}
+
 func startServer(t *testing.T, file string) string {
 	t.Helper()
 
@@ -0,0 +1,105 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package openshift holds the subset of the OpenShift Route API the agent reads. The OpenShift client is not a
+// dependency of the agent: Routes are fetched as unstructured objects and converted into these types.
+package openshift
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// GroupName is the group of the OpenShift Route API.
+const GroupName = "route.openshift.io"
+
+// Route identifiers.
+var (
+	RouteGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1"}
+	RouteResource     = RouteGroupVersion.WithResource("routes")
+	RouteKind         = RouteGroupVersion.WithKind("Route")
+)
+
+// Route exposes a Service on a host through the OpenShift router.
+type Route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec RouteSpec `json:"spec"`
+}
+
+// RouteSpec describes the host and path a Route exposes and the Services it targets.
+type RouteSpec struct {
+	Host              string                 `json:"host,omitempty"`
+	Path              string                 `json:"path,omitempty"`
+	To                RouteTargetReference   `json:"to"`
+	AlternateBackends []RouteTargetReference `json:"alternateBackends,omitempty"`
+	Port              *RoutePort             `json:"port,omitempty"`
+	TLS               *TLSConfig             `json:"tls,omitempty"`
+}
+
+// RouteTargetReference references a Service, in the namespace of the Route, targeted by a Route.
+type RouteTargetReference struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Weight *int32 `json:"weight,omitempty"`
+}
+
+// RoutePort is the port of the targeted Services a Route forwards to.
+type RoutePort struct {
+	TargetPort intstr.IntOrString `json:"targetPort"`
+}
+
+// TLSConfig describes how a Route terminates TLS. The certificates and keys it may embed are deliberately left out.
+type TLSConfig struct {
+	Termination                   string `json:"termination"`
+	InsecureEdgeTerminationPolicy string `json:"insecureEdgeTerminationPolicy,omitempty"`
+}
+
+// Services returns the names of the Services targeted by the Route, in the Route namespace.
+func (r *Route) Services() []string {
+	var names []string
+	for _, ref := range append([]RouteTargetReference{r.Spec.To}, r.Spec.AlternateBackends...) {
+		if ref.Name == "" || (ref.Kind != "" && ref.Kind != "Service") {
+			continue
+		}
+
+		names = append(names, ref.Name)
+	}
+
+	return names
+}
+
+// FromUnstructured converts an unstructured object into a Route.
+func FromUnstructured(obj interface{}) (*Route, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+
+	var route Route
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &route); err != nil {
+		return nil, fmt.Errorf("convert unstructured route %s/%s: %w", u.GetNamespace(), u.GetName(), err)
+	}
+
+	return &route, nil
+}
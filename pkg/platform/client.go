@@ -34,6 +34,8 @@ import (
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/bruteforce"
 	"github.com/traefik/hub-agent-kubernetes/pkg/api"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	"github.com/traefik/hub-agent-kubernetes/pkg/edgeingress"
@@ -55,11 +57,15 @@ func (a APIError) Error() string {
 
 // CreateEdgeIngressReq is the request for creating an edge ingress.
 type CreateEdgeIngressReq struct {
-	Name          string   `json:"name"`
-	Namespace     string   `json:"namespace"`
-	Service       Service  `json:"service"`
-	ACP           *ACP     `json:"acp,omitempty"`
-	CustomDomains []string `json:"customDomains,omitempty"`
+	Name          string       `json:"name"`
+	Namespace     string       `json:"namespace"`
+	Service       Service      `json:"service"`
+	ACP           *ACP         `json:"acp,omitempty"`
+	Mirror        *Mirror      `json:"mirror,omitempty"`
+	Headers       *Headers     `json:"headers,omitempty"`
+	IPAllowList   *IPAllowList `json:"ipAllowList,omitempty"`
+	BlueGreen     *BlueGreen   `json:"blueGreen,omitempty"`
+	CustomDomains []string     `json:"customDomains,omitempty"`
 }
 
 // Service defines the service being exposed by the edge ingress.
@@ -68,6 +74,31 @@ type Service struct {
 	Port int    `json:"port"`
 }
 
+// Mirror defines the service receiving a copy of the edge ingress traffic.
+type Mirror struct {
+	Name    string `json:"name"`
+	Port    int    `json:"port"`
+	Percent int    `json:"percent"`
+}
+
+// Headers defines the headers injected by the edge ingress.
+type Headers struct {
+	Request  map[string]string `json:"request,omitempty"`
+	Response map[string]string `json:"response,omitempty"`
+}
+
+// IPAllowList defines the source IPs allowed to reach the edge ingress.
+type IPAllowList struct {
+	SourceRange []string `json:"sourceRange"`
+}
+
+// BlueGreen defines the two releases of a blue/green deployment exposed by the edge ingress.
+type BlueGreen struct {
+	Blue   Service `json:"blue"`
+	Green  Service `json:"green"`
+	Active string  `json:"active"`
+}
+
 // ACP defines the ACP attached to the edge ingress.
 type ACP struct {
 	Name string `json:"name"`
@@ -77,6 +108,8 @@ type ACP struct {
 type Config struct {
 	Metrics  MetricsConfig `json:"metrics"`
 	Features []string      `json:"features"`
+	// LogLevels are the log levels of the agent components, by component name.
+	LogLevels map[string]string `json:"logLevels,omitempty"`
 }
 
 // MetricsConfig holds the metrics part of the offer config.
@@ -87,9 +120,13 @@ type MetricsConfig struct {
 
 // UpdateEdgeIngressReq is a request for updating an edge ingress.
 type UpdateEdgeIngressReq struct {
-	Service       Service  `json:"service"`
-	ACP           *ACP     `json:"acp,omitempty"`
-	CustomDomains []string `json:"customDomains,omitempty"`
+	Service       Service      `json:"service"`
+	ACP           *ACP         `json:"acp,omitempty"`
+	Mirror        *Mirror      `json:"mirror,omitempty"`
+	Headers       *Headers     `json:"headers,omitempty"`
+	IPAllowList   *IPAllowList `json:"ipAllowList,omitempty"`
+	BlueGreen     *BlueGreen   `json:"blueGreen,omitempty"`
+	CustomDomains []string     `json:"customDomains,omitempty"`
 }
 
 // CreatePortalReq is the request for creating a portal.
@@ -116,6 +153,10 @@ type CreateGatewayReq struct {
 	Labels        map[string]string `json:"labels"`
 	Accesses      []string          `json:"accesses"`
 	CustomDomains []string          `json:"customDomains"`
+
+	CredentialLocations []string         `json:"credentialLocations,omitempty"`
+	ExampleCapture      *ExampleCapture  `json:"exampleCapture,omitempty"`
+	IdentityHeaders     *IdentityHeaders `json:"identityHeaders,omitempty"`
 }
 
 // UpdateGatewayReq is a request for updating a gateway.
@@ -123,6 +164,26 @@ type UpdateGatewayReq struct {
 	Labels        map[string]string `json:"labels"`
 	Accesses      []string          `json:"accesses"`
 	CustomDomains []string          `json:"customDomains"`
+
+	CredentialLocations []string         `json:"credentialLocations,omitempty"`
+	ExampleCapture      *ExampleCapture  `json:"exampleCapture,omitempty"`
+	IdentityHeaders     *IdentityHeaders `json:"identityHeaders,omitempty"`
+}
+
+// ExampleCapture configures the capture of request/response examples by a gateway.
+type ExampleCapture struct {
+	SampleRate              int      `json:"sampleRate,omitempty"`
+	MaxExamplesPerOperation int      `json:"maxExamplesPerOperation,omitempty"`
+	MaxBodySize             *int     `json:"maxBodySize,omitempty"`
+	RedactedHeaders         []string `json:"redactedHeaders,omitempty"`
+	RedactedFields          []string `json:"redactedFields,omitempty"`
+}
+
+// IdentityHeaders configures the names of the headers holding the identity of the consumers of a gateway.
+type IdentityHeaders struct {
+	User   string `json:"user,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Groups string `json:"groups,omitempty"`
 }
 
 // CreateAPIReq is the request for creating an API.
@@ -132,46 +193,95 @@ type CreateAPIReq struct {
 
 	Labels map[string]string `json:"labels,omitempty"`
 
-	PathPrefix string     `json:"pathPrefix"`
-	Service    APIService `json:"service"`
+	PathPrefix     string          `json:"pathPrefix"`
+	Service        APIService      `json:"service"`
+	Versions       []APIVersion    `json:"versions,omitempty"`
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
 }
 
 // UpdateAPIReq is a request for updating an API.
 type UpdateAPIReq struct {
 	Labels map[string]string `json:"labels,omitempty"`
 
-	PathPrefix string     `json:"pathPrefix"`
-	Service    APIService `json:"service"`
+	PathPrefix     string          `json:"pathPrefix"`
+	Service        APIService      `json:"service"`
+	Versions       []APIVersion    `json:"versions,omitempty"`
+	TermsOfService *TermsOfService `json:"termsOfService,omitempty"`
+}
+
+// TermsOfService references a terms of service document.
+type TermsOfService struct {
+	URL     string `json:"url"`
+	Version string `json:"version"`
 }
 
 // APIService is a service used in API struct.
 type APIService struct {
+	Name        string               `json:"name"`
+	Port        int                  `json:"port"`
+	Weight      *int                 `json:"weight,omitempty"`
+	Weighted    []APIWeightedService `json:"weighted,omitempty"`
+	OpenAPISpec OpenAPISpec          `json:"openApiSpec"`
+}
+
+// APIVersion is a version of an API.
+type APIVersion struct {
 	Name        string      `json:"name"`
-	Port        int         `json:"port"`
-	OpenAPISpec OpenAPISpec `json:"openApiSpec"`
+	PathPrefix  string      `json:"pathPrefix"`
+	OpenAPISpec OpenAPISpec `json:"openApiSpec,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
 }
 
-// OpenAPISpec is an OpenAPISpec. It can either be fetched from a URL, or Path/Port from the service.
+// APIWeightedService is a service receiving a weighted share of the API traffic.
+type APIWeightedService struct {
+	Name   string `json:"name"`
+	Port   int    `json:"port"`
+	Weight int    `json:"weight"`
+}
+
+// OpenAPISpec is an OpenAPISpec. It can either be fetched from a URL, or Path/Port from the service, or read from a
+// ConfigMap. Fallbacks are tried in order when it can't be fetched.
 type OpenAPISpec struct {
 	URL string `json:"url,omitempty"`
 
 	Path string `json:"path,omitempty"`
 	Port int    `json:"port,omitempty"`
+
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty"`
+	Fallbacks    []OpenAPISpecSource      `json:"fallbacks,omitempty"`
+}
+
+// OpenAPISpecSource is an alternative source from which an OpenAPI spec can be fetched.
+type OpenAPISpecSource struct {
+	URL string `json:"url,omitempty"`
+
+	Path string `json:"path,omitempty"`
+	Port int    `json:"port,omitempty"`
+
+	ConfigMapRef *OpenAPISpecConfigMapRef `json:"configMapRef,omitempty"`
+}
+
+// OpenAPISpecConfigMapRef references the key of a ConfigMap holding an OpenAPI spec.
+type OpenAPISpecConfigMapRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 // CreateCollectionReq is the request for creating a collection.
 type CreateCollectionReq struct {
-	Name        string               `json:"name"`
-	Labels      map[string]string    `json:"labels,omitempty"`
-	PathPrefix  string               `json:"pathPrefix,omitempty"`
-	APISelector metav1.LabelSelector `json:"apiSelector,omitempty"`
+	Name           string               `json:"name"`
+	Labels         map[string]string    `json:"labels,omitempty"`
+	PathPrefix     string               `json:"pathPrefix,omitempty"`
+	APISelector    metav1.LabelSelector `json:"apiSelector,omitempty"`
+	TermsOfService *TermsOfService      `json:"termsOfService,omitempty"`
 }
 
 // UpdateCollectionReq is a request for updating a collection.
 type UpdateCollectionReq struct {
-	Labels      map[string]string    `json:"labels,omitempty"`
-	PathPrefix  string               `json:"pathPrefix,omitempty"`
-	APISelector metav1.LabelSelector `json:"apiSelector,omitempty"`
+	Labels         map[string]string    `json:"labels,omitempty"`
+	PathPrefix     string               `json:"pathPrefix,omitempty"`
+	APISelector    metav1.LabelSelector `json:"apiSelector,omitempty"`
+	TermsOfService *TermsOfService      `json:"termsOfService,omitempty"`
 }
 
 // CreateAccessReq is the request for creating an API access.
@@ -181,6 +291,7 @@ type CreateAccessReq struct {
 	Labels map[string]string `json:"labels,omitempty"`
 
 	Groups                []string              `json:"groups"`
+	Users                 []string              `json:"users,omitempty"`
 	APISelector           *metav1.LabelSelector `json:"apiSelector,omitempty"`
 	APICollectionSelector *metav1.LabelSelector `json:"apiCollectionSelector,omitempty"`
 }
@@ -190,10 +301,25 @@ type UpdateAccessReq struct {
 	Labels map[string]string `json:"labels,omitempty"`
 
 	Groups                []string              `json:"groups"`
+	Users                 []string              `json:"users,omitempty"`
 	APISelector           *metav1.LabelSelector `json:"apiSelector,omitempty"`
 	APICollectionSelector *metav1.LabelSelector `json:"apiCollectionSelector,omitempty"`
 }
 
+// CreateGroupReq is the request for creating a group.
+type CreateGroupReq struct {
+	Name       string            `json:"name"`
+	ExternalID string            `json:"externalId,omitempty"`
+	Members    []api.GroupMember `json:"members"`
+}
+
+// UpdateGroupReq is a request for updating a group.
+type UpdateGroupReq struct {
+	Name       string            `json:"name"`
+	ExternalID string            `json:"externalId,omitempty"`
+	Members    []api.GroupMember `json:"members"`
+}
+
 // Command defines patch operation to apply on the cluster.
 type Command struct {
 	ID        string          `json:"id"`
@@ -250,6 +376,36 @@ type linkClusterReq struct {
 	Version  string `json:"version"`
 }
 
+type pingReq struct {
+	Tunnels []TunnelStats `json:"tunnels,omitempty"`
+	Usage   *FeatureUsage `json:"usage,omitempty"`
+}
+
+// TunnelStats holds the statistics of an edge tunnel reported along with the heartbeat.
+type TunnelStats struct {
+	TunnelID      string `json:"tunnelId"`
+	BytesReceived uint64 `json:"bytesReceived"`
+	BytesSent     uint64 `json:"bytesSent"`
+	ActiveStreams int64  `json:"activeStreams"`
+	Reconnects    uint64 `json:"reconnects"`
+	RTTMillis     int64  `json:"rttMillis"`
+}
+
+// FeatureUsage is the anonymized usage of the agent features reported along with the heartbeat. It only holds counts,
+// never the names or the configuration of the resources.
+type FeatureUsage struct {
+	// ACPs is the number of ACPs by method, like "jwt" or "oidc".
+	ACPs          map[string]int `json:"acps"`
+	EdgeIngresses int            `json:"edgeIngresses"`
+	APIs          int            `json:"apis"`
+	// PublishedAPIs is the number of APIs exposed by at least one APIGateway.
+	PublishedAPIs int `json:"publishedApis"`
+	APIGateways   int `json:"apiGateways"`
+	APIPortals    int `json:"apiPortals"`
+	// ManagedCertificates is the number of TLS Secrets managed by the agent.
+	ManagedCertificates int `json:"managedCertificates"`
+}
+
 type linkClusterResp struct {
 	ClusterID string `json:"clusterId"`
 }
@@ -263,6 +419,15 @@ type patchResp struct {
 	Version int64 `json:"version"`
 }
 
+type checkTopologyReq struct {
+	Checksum string `json:"checksum"`
+	Version  int64  `json:"version"`
+}
+
+type checkTopologyResp struct {
+	Resync bool `json:"resync"`
+}
+
 // Client allows interacting with the cluster service.
 type Client struct {
 	baseURL    *url.URL
@@ -288,6 +453,12 @@ func NewClient(baseURL, token string) (*Client, error) {
 	}, nil
 }
 
+// WrapTransport wraps the transport of the client, for instance to authenticate the requests with tokens obtained at
+// runtime rather than with the static token.
+func (c *Client) WrapTransport(wrap func(rt http.RoundTripper) http.RoundTripper) {
+	c.httpClient.Transport = wrap(c.httpClient.Transport)
+}
+
 // Link links the agent to the given Kubernetes ID.
 func (c *Client) Link(ctx context.Context, kubeID string) (string, error) {
 	body, err := json.Marshal(linkClusterReq{KubeID: kubeID, Platform: "kubernetes", Version: version.Version()})
@@ -377,16 +548,44 @@ func (c *Client) GetConfig(ctx context.Context) (Config, error) {
 
 // Ping sends a ping to the platform to inform that the agent is alive.
 func (c *Client) Ping(ctx context.Context) error {
+	return c.ping(ctx, http.NoBody)
+}
+
+// PingWithTunnelStats sends a ping to the platform to inform that the agent is alive, along with the statistics of
+// the edge tunnels it runs.
+func (c *Client) PingWithTunnelStats(ctx context.Context, tunnels []TunnelStats) error {
+	body, err := json.Marshal(pingReq{Tunnels: tunnels})
+	if err != nil {
+		return fmt.Errorf("marshal ping request: %w", err)
+	}
+
+	return c.ping(ctx, bytes.NewReader(body))
+}
+
+// PingWithUsage sends a ping to the platform to inform that the agent is alive, along with the usage of its features.
+func (c *Client) PingWithUsage(ctx context.Context, usage FeatureUsage) error {
+	body, err := json.Marshal(pingReq{Usage: &usage})
+	if err != nil {
+		return fmt.Errorf("marshal ping request: %w", err)
+	}
+
+	return c.ping(ctx, bytes.NewReader(body))
+}
+
+func (c *Client) ping(ctx context.Context, body io.Reader) error {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "ping"))
 	if err != nil {
 		return fmt.Errorf("parse endpoint: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL.String(), http.NoBody)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL.String(), body)
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
 	}
 
+	if body != http.NoBody {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
 	version.SetUserAgent(req)
 
@@ -841,6 +1040,55 @@ func (c *Client) DeleteAccess(ctx context.Context, name, lastKnownVersion string
 	return nil
 }
 
+// CreateGroup creates a group.
+func (c *Client) CreateGroup(ctx context.Context, createReq *CreateGroupReq) (*api.Group, error) {
+	body, err := json.Marshal(createReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal group request: %w", err)
+	}
+
+	var g api.Group
+	if err = c.createResource(ctx, "groups", body, &g); err != nil {
+		return nil, fmt.Errorf("create group: %w", err)
+	}
+
+	return &g, nil
+}
+
+// GetGroups fetches the groups pushed by the identity provider.
+func (c *Client) GetGroups(ctx context.Context) ([]api.Group, error) {
+	var groups []api.Group
+	if err := c.listResource(ctx, "groups", &groups); err != nil {
+		return nil, fmt.Errorf("list groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// UpdateGroup updates a group.
+func (c *Client) UpdateGroup(ctx context.Context, id, lastKnownVersion string, updateReq *UpdateGroupReq) (*api.Group, error) {
+	body, err := json.Marshal(updateReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal group request: %w", err)
+	}
+
+	var g api.Group
+	if err = c.updateResource(ctx, "groups", id, lastKnownVersion, body, &g); err != nil {
+		return nil, fmt.Errorf("update group: %w", err)
+	}
+
+	return &g, nil
+}
+
+// DeleteGroup deletes a group.
+func (c *Client) DeleteGroup(ctx context.Context, id, lastKnownVersion string) error {
+	if err := c.deleteResource(ctx, "groups", id, lastKnownVersion); err != nil {
+		return fmt.Errorf("delete group: %w", err)
+	}
+
+	return nil
+}
+
 // GetWildcardCertificate gets a certificate for the workspace.
 func (c *Client) GetWildcardCertificate(ctx context.Context) (edgeingress.Certificate, error) {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "wildcard-certificate"))
@@ -1015,6 +1263,58 @@ func (c *Client) PatchTopology(ctx context.Context, patch []byte, lastKnownVersi
 	return body.Version, nil
 }
 
+// CheckTopology sends to the platform the checksum of the topology the agent last synchronized, the SHA-256 of its JSON
+// encoding, along with its version. It returns whether the platform holds a different topology, in which case the
+// agent must resynchronize it. Platforms not supporting this handshake never ask for a resynchronization.
+func (c *Client) CheckTopology(ctx context.Context, checksum string, lastKnownVersion int64) (bool, error) {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "topology", "checksum"))
+	if err != nil {
+		return false, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	body, err := json.Marshal(checkTopologyReq{Checksum: checksum, Version: lastKnownVersion})
+	if err != nil {
+		return false, fmt.Errorf("marshal check topology request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return false, apiErr
+	}
+
+	var r checkTopologyResp
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return false, fmt.Errorf("decode check topology resp: %w", err)
+	}
+
+	return r.Resync, nil
+}
+
 // ListPendingCommands fetches the commands to apply on the cluster.
 func (c *Client) ListPendingCommands(ctx context.Context) ([]Command, error) {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "commands"))
@@ -1054,6 +1354,227 @@ func (c *Client) ListPendingCommands(ctx context.Context) ([]Command, error) {
 	return commands, nil
 }
 
+// GetAPIUsage fetches the usage of an API made with the tokens of a consumer.
+func (c *Client) GetAPIUsage(ctx context.Context, query api.UsageQuery) (api.APIUsage, error) {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "portals", query.Portal, "apis", query.API, "usage"))
+	if err != nil {
+		return api.APIUsage{}, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("email", query.Email)
+	params.Set("period", query.Period.String())
+	baseURL.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.String(), http.NoBody)
+	if err != nil {
+		return api.APIUsage{}, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return api.APIUsage{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return api.APIUsage{}, apiErr
+	}
+
+	var usage api.APIUsage
+	if err = json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return api.APIUsage{}, fmt.Errorf("decode API usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// ListAPIExamples lists the request/response examples captured, by the gateway of a portal, for an API.
+func (c *Client) ListAPIExamples(ctx context.Context, query api.ExampleQuery) ([]api.APIExample, error) {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "portals", query.Portal, "apis", query.API, "examples"))
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	if query.Version != "" {
+		params := url.Values{}
+		params.Set("version", query.Version)
+		baseURL.RawQuery = params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return nil, apiErr
+	}
+
+	var examples []api.APIExample
+	if err = json.NewDecoder(resp.Body).Decode(&examples); err != nil {
+		return nil, fmt.Errorf("decode API examples: %w", err)
+	}
+
+	return examples, nil
+}
+
+// GetTermsAcceptance fetches the latest acceptance, by a consumer, of the terms of service of an API published on a
+// portal. It returns nil when the consumer never accepted them.
+func (c *Client) GetTermsAcceptance(ctx context.Context, query api.TermsQuery) (*api.TermsAcceptance, error) {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "portals", query.Portal, "apis", query.API, "terms-acceptances"))
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("email", query.Email)
+	if query.Collection != "" {
+		params.Set("collection", query.Collection)
+	}
+	baseURL.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return nil, apiErr
+	}
+
+	var acceptance api.TermsAcceptance
+	if err = json.NewDecoder(resp.Body).Decode(&acceptance); err != nil {
+		return nil, fmt.Errorf("decode terms acceptance: %w", err)
+	}
+
+	return &acceptance, nil
+}
+
+// AcceptTerms records the acceptance, by a consumer, of the terms of service of an API published on a portal.
+func (c *Client) AcceptTerms(ctx context.Context, acceptance api.TermsAcceptance) (api.TermsAcceptance, error) {
+	body, err := json.Marshal(acceptance)
+	if err != nil {
+		return api.TermsAcceptance{}, fmt.Errorf("marshal terms acceptance: %w", err)
+	}
+
+	var accepted api.TermsAcceptance
+	apiPath := path.Join("portals", acceptance.Portal, "apis", acceptance.API, "terms-acceptances")
+	if err = c.createResource(ctx, apiPath, body, &accepted); err != nil {
+		return api.TermsAcceptance{}, err
+	}
+
+	return accepted, nil
+}
+
+// ValidateAPIToken returns the consumer owning the given API token if it grants access to the given gateway, nil
+// otherwise.
+func (c *Client) ValidateAPIToken(ctx context.Context, gateway, token string) (*apitoken.Consumer, error) {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "api-gateways", gateway, "api-tokens", "validate"))
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	body, err := json.Marshal(struct {
+		Token string `json:"token"`
+	}{Token: token})
+	if err != nil {
+		return nil, fmt.Errorf("marshal API token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	version.SetUserAgent(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return nil, apiErr
+	}
+
+	var consumer apitoken.Consumer
+	if err = json.NewDecoder(resp.Body).Decode(&consumer); err != nil {
+		return nil, fmt.Errorf("decode API token consumer: %w", err)
+	}
+
+	return &consumer, nil
+}
+
+// GetAPITokenRevocations returns the API tokens which have been revoked or suspended.
+func (c *Client) GetAPITokenRevocations(ctx context.Context) ([]apitoken.Revocation, error) {
+	var revocations []apitoken.Revocation
+	if err := c.listResource(ctx, "api-tokens/revocations", &revocations); err != nil {
+		return nil, fmt.Errorf("list API token revocations: %w", err)
+	}
+
+	return revocations, nil
+}
+
 // SubmitCommandReports submits the given command execution reports.
 func (c *Client) SubmitCommandReports(ctx context.Context, reports []CommandExecutionReport) error {
 	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "command-reports"))
@@ -1093,6 +1614,83 @@ func (c *Client) SubmitCommandReports(ctx context.Context, reports []CommandExec
 	return nil
 }
 
+// SendBruteForceEvents sends the clients blocked by the brute force protection of the auth server.
+func (c *Client) SendBruteForceEvents(ctx context.Context, events []bruteforce.Event) error {
+	baseURL, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "brute-force-events"))
+	if err != nil {
+		return fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("marshal brute force events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		all, _ := io.ReadAll(resp.Body)
+
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(all, &apiErr); err != nil {
+			apiErr.Message = string(all)
+		}
+
+		return apiErr
+	}
+
+	return nil
+}
+
+// TokenInfo holds the expiry of the platform token of the agent.
+type TokenInfo struct {
+	// ExpiresAt is zero when the token never expires.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// IssuedToken is a platform token issued to the agent.
+type IssuedToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// GetTokenInfo gets the expiry of the token the agent uses.
+func (c *Client) GetTokenInfo(ctx context.Context) (TokenInfo, error) {
+	var info TokenInfo
+	if err := c.listResource(ctx, "token", &info); err != nil {
+		return TokenInfo{}, fmt.Errorf("get token info: %w", err)
+	}
+
+	return info, nil
+}
+
+// RenewToken issues a new token to the agent, to be used in place of the current one.
+// The current token remains valid until its expiry.
+func (c *Client) RenewToken(ctx context.Context) (IssuedToken, error) {
+	var issued IssuedToken
+	if err := c.createResource(ctx, "token/renew", nil, &issued); err != nil {
+		return IssuedToken{}, fmt.Errorf("renew token: %w", err)
+	}
+
+	if issued.Token == "" {
+		return IssuedToken{}, errors.New("renew token: empty token issued")
+	}
+
+	return issued, nil
+}
+
 func newGzippedRequestWithContext(ctx context.Context, verb, u string, body []byte) (*http.Request, error) {
 	var compressedBody bytes.Buffer
 
@@ -33,6 +33,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/apitoken"
+	"github.com/traefik/hub-agent-kubernetes/pkg/acp/bruteforce"
 	"github.com/traefik/hub-agent-kubernetes/pkg/acp/jwt"
 	"github.com/traefik/hub-agent-kubernetes/pkg/api"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
@@ -251,6 +253,105 @@ func TestClient_Ping(t *testing.T) {
 	}
 }
 
+func TestClient_PingWithTunnelStats(t *testing.T) {
+	var callCount int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(rw http.ResponseWriter, req *http.Request) {
+		callCount++
+
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"tunnels":[{"tunnelId":"tunnel-id","bytesReceived":42,"bytesSent":1024,"activeStreams":3,"reconnects":2,"rttMillis":150}]}`, string(body))
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	err = c.PingWithTunnelStats(context.Background(), []TunnelStats{
+		{
+			TunnelID:      "tunnel-id",
+			BytesReceived: 42,
+			BytesSent:     1024,
+			ActiveStreams: 3,
+			Reconnects:    2,
+			RTTMillis:     150,
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, callCount)
+}
+
+func TestClient_PingWithUsage(t *testing.T) {
+	var callCount int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ping", func(rw http.ResponseWriter, req *http.Request) {
+		callCount++
+
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"usage":{"acps":{"jwt":2,"oidc":1},"edgeIngresses":3,"apis":5,"publishedApis":4,"apiGateways":1,"apiPortals":1,"managedCertificates":2}}`, string(body))
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	err = c.PingWithUsage(context.Background(), FeatureUsage{
+		ACPs:                map[string]int{"jwt": 2, "oidc": 1},
+		EdgeIngresses:       3,
+		APIs:                5,
+		PublishedAPIs:       4,
+		APIGateways:         1,
+		APIPortals:          1,
+		ManagedCertificates: 2,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, callCount)
+}
+
 func TestClient_ListVerifiedDomains(t *testing.T) {
 	tests := []struct {
 		desc             string
@@ -1994,6 +2095,88 @@ func TestClient_PatchTopology(t *testing.T) {
 	}
 }
 
+func TestClient_CheckTopology(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		resp       string
+		wantResync bool
+		wantErr    bool
+	}{
+		{
+			desc:       "same topology",
+			statusCode: http.StatusOK,
+			resp:       `{"resync": false}`,
+		},
+		{
+			desc:       "diverged topology",
+			statusCode: http.StatusOK,
+			resp:       `{"resync": true}`,
+			wantResync: true,
+		},
+		{
+			desc:       "handshake not supported",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "unexpected error",
+			statusCode: http.StatusInternalServerError,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/topology/checksum", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodPost {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer 456" {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				var body checkTopologyReq
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Checksum != "abc" || body.Version != 3 {
+					http.Error(rw, "invalid body", http.StatusBadRequest)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+				_, _ = rw.Write([]byte(test.resp))
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, "456")
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			gotResync, err := c.CheckTopology(context.Background(), "abc", 3)
+			if test.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 1, callCount)
+			assert.Equal(t, test.wantResync, gotResync)
+		})
+	}
+}
+
 func TestClient_SetVersionStatus(t *testing.T) {
 	tests := []struct {
 		desc             string
@@ -2148,63 +2331,50 @@ func TestClient_ListPendingCommands(t *testing.T) {
 	}
 }
 
-type reportErrorData struct {
-	Value int `json:"value"`
-}
-
-func TestClient_SendCommandReports(t *testing.T) {
+func TestClient_GetAPIUsage(t *testing.T) {
 	tests := []struct {
-		desc           string
-		statusCode     int
-		commandReports []CommandExecutionReport
-		wantBody       []byte
-		wantErr        error
+		desc       string
+		statusCode int
+		body       []byte
+		wantUsage  api.APIUsage
+		wantErr    error
 	}{
 		{
-			desc:       "send command reports succeed",
+			desc:       "get API usage succeed",
 			statusCode: http.StatusOK,
-			commandReports: []CommandExecutionReport{
-				{
-					ID:     "cmd-1",
-					Status: "success",
-				},
-				{
-					ID:     "cmd-2",
-					Status: "failure",
-					Error: &CommandExecutionReportError{
-						Type: "something-went-wrong",
-						Data: &reportErrorData{Value: 42},
-					},
+			body: []byte(`{
+				"from": "2000-10-30T01:30:00Z",
+				"to": "2000-10-31T01:30:00Z",
+				"total": {"requests": 10, "errors": 1, "errorRate": 0.1, "latency": {"p50": 12, "p90": 30, "p99": 95.5}},
+				"tokens": [
+					{"token": "ci", "requests": 10, "errors": 1, "errorRate": 0.1, "latency": {"p50": 12, "p90": 30, "p99": 95.5}}
+				]
+			}`),
+			wantUsage: api.APIUsage{
+				From: time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC),
+				To:   time.Date(2000, time.October, 31, 1, 30, 0, 0, time.UTC),
+				Total: api.UsageMetrics{
+					Requests:  10,
+					Errors:    1,
+					ErrorRate: 0.1,
+					Latency:   api.LatencyPercentiles{P50: 12, P90: 30, P99: 95.5},
 				},
-				{
-					ID:     "cmd-3",
-					Status: "failure",
-					Error: &CommandExecutionReportError{
-						Type: "something-went-wrong",
+				Tokens: []api.TokenUsage{
+					{
+						Token: "ci",
+						UsageMetrics: api.UsageMetrics{
+							Requests:  10,
+							Errors:    1,
+							ErrorRate: 0.1,
+							Latency:   api.LatencyPercentiles{P50: 12, P90: 30, P99: 95.5},
+						},
 					},
 				},
-				{
-					ID:     "cmd-4",
-					Status: "failure",
-				},
 			},
-			wantBody: []byte(`[
-				{"id":"cmd-1","status":"success"},
-				{"id":"cmd-2","status":"failure","error":{"type":"something-went-wrong","data":{"value":42}}},
-				{"id":"cmd-3","status":"failure","error":{"type":"something-went-wrong"}},
-				{"id":"cmd-4","status":"failure"}
-			]`),
 		},
 		{
-			desc:       "send command reports unexpected error",
+			desc:       "get API usage unexpected error",
 			statusCode: http.StatusTeapot,
-			commandReports: []CommandExecutionReport{
-				{
-					ID:     "cmd-1",
-					Status: "success",
-				},
-			},
-			wantBody: []byte(`[{"id":"cmd-1","status":"success"}]`),
 			wantErr: &APIError{
 				StatusCode: http.StatusTeapot,
 				Message:    "error",
@@ -2221,10 +2391,10 @@ func TestClient_SendCommandReports(t *testing.T) {
 			var callCount int
 
 			mux := http.NewServeMux()
-			mux.HandleFunc("/command-reports", func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/portals/my-portal/apis/my-api@my-ns/usage", func(rw http.ResponseWriter, req *http.Request) {
 				callCount++
 
-				if req.Method != http.MethodPost {
+				if req.Method != http.MethodGet {
 					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
 					return
 				}
@@ -2234,18 +2404,13 @@ func TestClient_SendCommandReports(t *testing.T) {
 					return
 				}
 
-				gotBody, err := io.ReadAll(req.Body)
-				if err != nil {
-					http.Error(rw, "Read body", http.StatusBadRequest)
-					return
-				}
-
-				if !assert.JSONEq(t, string(test.wantBody), string(gotBody)) {
-					http.Error(rw, "Invalid body", http.StatusBadRequest)
+				if req.URL.Query().Get("email") != "john@example.com" || req.URL.Query().Get("period") != "24h0m0s" {
+					http.Error(rw, "Invalid query", http.StatusBadRequest)
 					return
 				}
 
 				rw.WriteHeader(test.statusCode)
+				_, _ = rw.Write(test.body)
 			})
 
 			srv := httptest.NewServer(mux)
@@ -2255,7 +2420,12 @@ func TestClient_SendCommandReports(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			err = c.SubmitCommandReports(context.Background(), test.commandReports)
+			gotUsage, err := c.GetAPIUsage(context.Background(), api.UsageQuery{
+				Portal: "my-portal",
+				API:    "my-api@my-ns",
+				Email:  "john@example.com",
+				Period: 24 * time.Hour,
+			})
 			if test.wantErr != nil {
 				require.ErrorAs(t, err, test.wantErr)
 			} else {
@@ -2263,68 +2433,727 @@ func TestClient_SendCommandReports(t *testing.T) {
 			}
 
 			assert.Equal(t, 1, callCount)
+			assert.Equal(t, test.wantUsage, gotUsage)
 		})
 	}
 }
 
-func TestClient_GetAPIs(t *testing.T) {
-	wantAPIs := []api.API{
+func TestClient_ListAPIExamples(t *testing.T) {
+	tests := []struct {
+		desc         string
+		statusCode   int
+		body         []byte
+		wantExamples []api.APIExample
+		wantErr      error
+	}{
 		{
-			Name:       "name",
-			Namespace:  "ns",
-			PathPrefix: "prefix",
-			Service: api.Service{
-				Name: "service",
-				Port: 80,
+			desc:       "list API examples succeed",
+			statusCode: http.StatusOK,
+			body: []byte(`[{
+				"method": "GET",
+				"path": "/books/{id}",
+				"request": {"url": "/books/42", "headers": {"Accept": "application/json"}},
+				"response": {"statusCode": 200, "body": "{\"title\":\"Dune\"}"},
+				"capturedAt": "2000-10-30T01:30:00Z"
+			}]`),
+			wantExamples: []api.APIExample{
+				{
+					Method: http.MethodGet,
+					Path:   "/books/{id}",
+					Request: api.ExampleRequest{
+						URL:     "/books/42",
+						Headers: map[string]string{"Accept": "application/json"},
+					},
+					Response: api.ExampleResponse{
+						StatusCode: http.StatusOK,
+						Body:       `{"title":"Dune"}`,
+					},
+					CapturedAt: time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			desc:       "list API examples unexpected error",
+			statusCode: http.StatusTeapot,
+			wantErr: &APIError{
+				StatusCode: http.StatusTeapot,
+				Message:    "error",
 			},
-			Version: "version-1",
 		},
 	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/apis", func(rw http.ResponseWriter, req *http.Request) {
-		if req.Method != http.MethodGet {
-			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
-			return
-		}
-
-		if req.Header.Get("Authorization") != "Bearer "+testToken {
-			http.Error(rw, "Invalid token", http.StatusUnauthorized)
-			return
-		}
+	for _, test := range tests {
+		test := test
 
-		rw.WriteHeader(http.StatusOK)
-		err := json.NewEncoder(rw).Encode(wantAPIs)
-		require.NoError(t, err)
-	})
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
 
-	srv := httptest.NewServer(mux)
+			var callCount int
 
-	t.Cleanup(srv.Close)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/portals/my-portal/apis/my-api@my-ns/examples", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
 
-	c, err := NewClient(srv.URL, testToken)
-	require.NoError(t, err)
-	c.httpClient = srv.Client()
+				if req.Method != http.MethodGet {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
 
-	gotAPIs, err := c.GetAPIs(context.Background())
-	require.NoError(t, err)
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				if req.URL.Query().Get("version") != "v1" {
+					http.Error(rw, "Invalid query", http.StatusBadRequest)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+				_, _ = rw.Write(test.body)
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			gotExamples, err := c.ListAPIExamples(context.Background(), api.ExampleQuery{
+				Portal:  "my-portal",
+				API:     "my-api@my-ns",
+				Version: "v1",
+			})
+			if test.wantErr != nil {
+				require.ErrorAs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 1, callCount)
+			assert.Equal(t, test.wantExamples, gotExamples)
+		})
+	}
+}
+
+func TestClient_GetTermsAcceptance(t *testing.T) {
+	tests := []struct {
+		desc           string
+		statusCode     int
+		body           []byte
+		wantAcceptance *api.TermsAcceptance
+		wantErr        error
+	}{
+		{
+			desc:       "get terms acceptance succeed",
+			statusCode: http.StatusOK,
+			body: []byte(`{
+				"portal": "my-portal",
+				"api": "my-api@my-ns",
+				"collection": "my-collection",
+				"email": "john@example.com",
+				"url": "https://example.com/tos",
+				"version": "1",
+				"acceptedAt": "2000-10-30T01:30:00Z"
+			}`),
+			wantAcceptance: &api.TermsAcceptance{
+				Portal:     "my-portal",
+				API:        "my-api@my-ns",
+				Collection: "my-collection",
+				Email:      "john@example.com",
+				URL:        "https://example.com/tos",
+				Version:    "1",
+				AcceptedAt: time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc:       "terms never accepted",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "get terms acceptance unexpected error",
+			statusCode: http.StatusTeapot,
+			wantErr: &APIError{
+				StatusCode: http.StatusTeapot,
+				Message:    "error",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/portals/my-portal/apis/my-api@my-ns/terms-acceptances", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodGet {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				if req.URL.Query().Get("email") != "john@example.com" || req.URL.Query().Get("collection") != "my-collection" {
+					http.Error(rw, "Invalid query", http.StatusBadRequest)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+				_, _ = rw.Write(test.body)
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			gotAcceptance, err := c.GetTermsAcceptance(context.Background(), api.TermsQuery{
+				Portal:     "my-portal",
+				API:        "my-api@my-ns",
+				Collection: "my-collection",
+				Email:      "john@example.com",
+			})
+			if test.wantErr != nil {
+				require.ErrorAs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 1, callCount)
+			assert.Equal(t, test.wantAcceptance, gotAcceptance)
+		})
+	}
+}
+
+func TestClient_AcceptTerms(t *testing.T) {
+	var callCount int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/portals/my-portal/apis/my-api@my-ns/terms-acceptances", func(rw http.ResponseWriter, req *http.Request) {
+		callCount++
+
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		var acceptance api.TermsAcceptance
+		if err := json.NewDecoder(req.Body).Decode(&acceptance); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		acceptance.AcceptedAt = time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC)
+
+		rw.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(rw).Encode(acceptance)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	acceptance := api.TermsAcceptance{
+		Portal:  "my-portal",
+		API:     "my-api@my-ns",
+		Email:   "john@example.com",
+		URL:     "https://example.com/tos",
+		Version: "1",
+	}
+	got, err := c.AcceptTerms(context.Background(), acceptance)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, callCount)
+
+	acceptance.AcceptedAt = time.Date(2000, time.October, 30, 1, 30, 0, 0, time.UTC)
+	assert.Equal(t, acceptance, got)
+}
+
+func TestClient_ValidateAPIToken(t *testing.T) {
+	tests := []struct {
+		desc         string
+		statusCode   int
+		body         []byte
+		wantConsumer *apitoken.Consumer
+		wantErr      error
+	}{
+		{
+			desc:       "valid token",
+			statusCode: http.StatusOK,
+			body:       []byte(`{"email": "john@example.com", "groups": ["consumer", "supplier"]}`),
+			wantConsumer: &apitoken.Consumer{
+				Email:  "john@example.com",
+				Groups: []string{"consumer", "supplier"},
+			},
+		},
+		{
+			desc:       "unknown token",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			desc:       "validate token unexpected error",
+			statusCode: http.StatusTeapot,
+			wantErr: &APIError{
+				StatusCode: http.StatusTeapot,
+				Message:    "error",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api-gateways/my-gateway/api-tokens/validate", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodPost {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				var body struct {
+					Token string `json:"token"`
+				}
+				if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Token != "api-token" {
+					http.Error(rw, "Invalid body", http.StatusBadRequest)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+				_, _ = rw.Write(test.body)
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			gotConsumer, err := c.ValidateAPIToken(context.Background(), "my-gateway", "api-token")
+			if test.wantErr != nil {
+				require.ErrorAs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 1, callCount)
+			assert.Equal(t, test.wantConsumer, gotConsumer)
+		})
+	}
+}
+
+func TestClient_GetAPITokenRevocations(t *testing.T) {
+	wantRevocations := []apitoken.Revocation{
+		{TokenHash: "hash-1", Reason: "revoked"},
+		{TokenHash: "hash-2", Reason: "suspended"},
+	}
+
+	var callCount int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api-tokens/revocations", func(rw http.ResponseWriter, req *http.Request) {
+		callCount++
+
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(rw).Encode(wantRevocations)
+		require.NoError(t, err)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	gotRevocations, err := c.GetAPITokenRevocations(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, 1, callCount)
+	assert.Equal(t, wantRevocations, gotRevocations)
+}
+
+type reportErrorData struct {
+	Value int `json:"value"`
+}
+
+func TestClient_SendCommandReports(t *testing.T) {
+	tests := []struct {
+		desc           string
+		statusCode     int
+		commandReports []CommandExecutionReport
+		wantBody       []byte
+		wantErr        error
+	}{
+		{
+			desc:       "send command reports succeed",
+			statusCode: http.StatusOK,
+			commandReports: []CommandExecutionReport{
+				{
+					ID:     "cmd-1",
+					Status: "success",
+				},
+				{
+					ID:     "cmd-2",
+					Status: "failure",
+					Error: &CommandExecutionReportError{
+						Type: "something-went-wrong",
+						Data: &reportErrorData{Value: 42},
+					},
+				},
+				{
+					ID:     "cmd-3",
+					Status: "failure",
+					Error: &CommandExecutionReportError{
+						Type: "something-went-wrong",
+					},
+				},
+				{
+					ID:     "cmd-4",
+					Status: "failure",
+				},
+			},
+			wantBody: []byte(`[
+				{"id":"cmd-1","status":"success"},
+				{"id":"cmd-2","status":"failure","error":{"type":"something-went-wrong","data":{"value":42}}},
+				{"id":"cmd-3","status":"failure","error":{"type":"something-went-wrong"}},
+				{"id":"cmd-4","status":"failure"}
+			]`),
+		},
+		{
+			desc:       "send command reports unexpected error",
+			statusCode: http.StatusTeapot,
+			commandReports: []CommandExecutionReport{
+				{
+					ID:     "cmd-1",
+					Status: "success",
+				},
+			},
+			wantBody: []byte(`[{"id":"cmd-1","status":"success"}]`),
+			wantErr: &APIError{
+				StatusCode: http.StatusTeapot,
+				Message:    "error",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/command-reports", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodPost {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				gotBody, err := io.ReadAll(req.Body)
+				if err != nil {
+					http.Error(rw, "Read body", http.StatusBadRequest)
+					return
+				}
+
+				if !assert.JSONEq(t, string(test.wantBody), string(gotBody)) {
+					http.Error(rw, "Invalid body", http.StatusBadRequest)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			err = c.SubmitCommandReports(context.Background(), test.commandReports)
+			if test.wantErr != nil {
+				require.ErrorAs(t, err, test.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, 1, callCount)
+		})
+	}
+}
+
+func TestClient_SendBruteForceEvents(t *testing.T) {
+	blockedAt := time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC)
+	events := []bruteforce.Event{
+		{
+			ACP:          "my-acp",
+			ClientIP:     "1.1.1.1",
+			Failures:     5,
+			BlockedAt:    blockedAt,
+			BlockedUntil: blockedAt.Add(5 * time.Minute),
+		},
+	}
+
+	var callCount int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/brute-force-events", func(rw http.ResponseWriter, req *http.Request) {
+		callCount++
+
+		if req.Method != http.MethodPost {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		gotBody, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(rw, "Read body", http.StatusBadRequest)
+			return
+		}
+
+		wantBody := `[{
+			"acp": "my-acp",
+			"clientIp": "1.1.1.1",
+			"failures": 5,
+			"blockedAt": "2023-07-01T10:00:00Z",
+			"blockedUntil": "2023-07-01T10:05:00Z"
+		}]`
+		if !assert.JSONEq(t, wantBody, string(gotBody)) {
+			http.Error(rw, "Invalid body", http.StatusBadRequest)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	err = c.SendBruteForceEvents(context.Background(), events)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, callCount)
+}
+
+func TestClient_GetAPIs(t *testing.T) {
+	wantAPIs := []api.API{
+		{
+			Name:       "name",
+			Namespace:  "ns",
+			PathPrefix: "prefix",
+			Service: api.Service{
+				Name: "service",
+				Port: 80,
+			},
+			Version: "version-1",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/apis", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(rw).Encode(wantAPIs)
+		require.NoError(t, err)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	gotAPIs, err := c.GetAPIs(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, wantAPIs, gotAPIs)
+}
+
+func TestClient_CreateAPI(t *testing.T) {
+	tests := []struct {
+		desc             string
+		req              *CreateAPIReq
+		api              *api.API
+		returnStatusCode int
+		wantErr          assert.ErrorAssertionFunc
+	}{
+		{
+			desc: "create API",
+			req: &CreateAPIReq{
+				Name:       "name",
+				Namespace:  "ns",
+				Labels:     map[string]string{"a": "b"},
+				PathPrefix: "prefix",
+				Service: APIService{
+					Name: "svc",
+					Port: 80,
+				},
+			},
+			returnStatusCode: http.StatusCreated,
+			wantErr:          assert.NoError,
+			api: &api.API{
+				Name:      "name",
+				Namespace: "ns",
+				Labels: map[string]string{
+					"a": "b",
+				},
+				PathPrefix: "prefix",
+				Service: api.Service{
+					Name: "svc",
+					Port: 80,
+				},
+				Version: "version-1",
+			},
+		},
+		{
+			desc: "error",
+			req: &CreateAPIReq{
+				Name:       "name",
+				Namespace:  "ns",
+				Labels:     map[string]string{"a": "b"},
+				PathPrefix: "prefix",
+				Service: APIService{
+					Name: "svc",
+					Port: 80,
+				},
+			},
+			returnStatusCode: http.StatusConflict,
+			wantErr:          assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotReq CreateAPIReq
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/apis", func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPost {
+					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				err := json.NewDecoder(req.Body).Decode(&gotReq)
+				require.NoError(t, err)
+
+				rw.WriteHeader(test.returnStatusCode)
+				if test.returnStatusCode == http.StatusConflict {
+					return
+				}
 
-	assert.Equal(t, wantAPIs, gotAPIs)
+				err = json.NewEncoder(rw).Encode(test.api)
+				require.NoError(t, err)
+			})
+
+			srv := httptest.NewServer(mux)
+
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			createdAPI, err := c.CreateAPI(context.Background(), test.req)
+			test.wantErr(t, err)
+
+			assert.Equal(t, *test.req, gotReq)
+			assert.Equal(t, test.api, createdAPI)
+		})
+	}
 }
 
-func TestClient_CreateAPI(t *testing.T) {
+func TestClient_UpdateAPI(t *testing.T) {
 	tests := []struct {
 		desc             string
-		req              *CreateAPIReq
+		req              *UpdateAPIReq
 		api              *api.API
 		returnStatusCode int
 		wantErr          assert.ErrorAssertionFunc
 	}{
 		{
-			desc: "create API",
-			req: &CreateAPIReq{
-				Name:       "name",
-				Namespace:  "ns",
+			desc: "update API",
+			req: &UpdateAPIReq{
 				Labels:     map[string]string{"a": "b"},
 				PathPrefix: "prefix",
 				Service: APIService{
@@ -2332,7 +3161,7 @@ func TestClient_CreateAPI(t *testing.T) {
 					Port: 80,
 				},
 			},
-			returnStatusCode: http.StatusCreated,
+			returnStatusCode: http.StatusOK,
 			wantErr:          assert.NoError,
 			api: &api.API{
 				Name:      "name",
@@ -2349,10 +3178,8 @@ func TestClient_CreateAPI(t *testing.T) {
 			},
 		},
 		{
-			desc: "error",
-			req: &CreateAPIReq{
-				Name:       "name",
-				Namespace:  "ns",
+			desc: "conflict",
+			req: &UpdateAPIReq{
 				Labels:     map[string]string{"a": "b"},
 				PathPrefix: "prefix",
 				Service: APIService{
@@ -2370,10 +3197,225 @@ func TestClient_CreateAPI(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			var gotReq CreateAPIReq
+			var gotReq UpdateAPIReq
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/apis/name@ns", func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodPut {
+					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				if req.Header.Get("Last-Known-Version") != "oldVersionAPI" {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				err := json.NewDecoder(req.Body).Decode(&gotReq)
+				require.NoError(t, err)
+
+				rw.WriteHeader(test.returnStatusCode)
+				if test.returnStatusCode == http.StatusConflict {
+					return
+				}
+
+				err = json.NewEncoder(rw).Encode(test.api)
+				require.NoError(t, err)
+			})
+
+			srv := httptest.NewServer(mux)
+
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			updatedAPI, err := c.UpdateAPI(context.Background(), "ns", "name", "oldVersionAPI", test.req)
+			test.wantErr(t, err)
+
+			assert.Equal(t, *test.req, gotReq)
+			assert.Equal(t, test.api, updatedAPI)
+		})
+	}
+}
+
+func TestClient_DeleteAPI(t *testing.T) {
+	tests := []struct {
+		desc             string
+		name             string
+		namespace        string
+		returnStatusCode int
+		wantErr          assert.ErrorAssertionFunc
+	}{
+		{
+			desc:             "delete API",
+			name:             "name",
+			namespace:        "ns",
+			returnStatusCode: http.StatusNoContent,
+			wantErr:          assert.NoError,
+		},
+		{
+			desc:             "error",
+			name:             "name",
+			namespace:        "ns",
+			returnStatusCode: http.StatusConflict,
+			wantErr:          assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+			mux := http.NewServeMux()
+			mux.HandleFunc("/apis/"+test.name+"@"+test.namespace, func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodDelete {
+					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				if req.Header.Get("Last-Known-Version") != "oldVersion" {
+					http.Error(rw, "Invalid token", http.StatusInternalServerError)
+					return
+				}
+
+				rw.WriteHeader(test.returnStatusCode)
+			})
+
+			srv := httptest.NewServer(mux)
+
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			err = c.DeleteAPI(context.Background(), test.namespace, test.name, "oldVersion")
+			test.wantErr(t, err)
+
+			require.Equal(t, 1, callCount)
+		})
+	}
+}
+
+func TestClient_GetAccesses(t *testing.T) {
+	wantAccesses := []api.Access{
+		{
+			Name:   "name",
+			Groups: []string{"group"},
+			APISelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"key": "value"},
+			},
+			APICollectionSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"key": "value"},
+			},
+			Version: "version-1",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/accesses", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(rw).Encode(wantAccesses)
+		require.NoError(t, err)
+	})
+
+	srv := httptest.NewServer(mux)
+
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	gotAccesses, err := c.GetAccesses(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, wantAccesses, gotAccesses)
+}
+
+func TestClient_CreateAccess(t *testing.T) {
+	tests := []struct {
+		desc             string
+		req              *CreateAccessReq
+		access           *api.Access
+		returnStatusCode int
+		wantErr          assert.ErrorAssertionFunc
+	}{
+		{
+			desc: "create Access",
+			req: &CreateAccessReq{
+				Name:   "name",
+				Labels: map[string]string{"a": "b"},
+				Groups: []string{"group"},
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+				APICollectionSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+			},
+			returnStatusCode: http.StatusCreated,
+			wantErr:          assert.NoError,
+			access: &api.Access{
+				Name: "name",
+				Labels: map[string]string{
+					"a": "b",
+				},
+				Groups: []string{"group"},
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+				APICollectionSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+				Version: "version-1",
+			},
+		},
+		{
+			desc: "error",
+			req: &CreateAccessReq{
+				Name:   "name",
+				Labels: map[string]string{"a": "b"},
+			},
+			returnStatusCode: http.StatusConflict,
+			wantErr:          assert.Error,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotReq CreateAccessReq
 
 			mux := http.NewServeMux()
-			mux.HandleFunc("/apis", func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/accesses", func(rw http.ResponseWriter, req *http.Request) {
 				if req.Method != http.MethodPost {
 					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
 					return
@@ -2392,7 +3434,7 @@ func TestClient_CreateAPI(t *testing.T) {
 					return
 				}
 
-				err = json.NewEncoder(rw).Encode(test.api)
+				err = json.NewEncoder(rw).Encode(test.access)
 				require.NoError(t, err)
 			})
 
@@ -2404,58 +3446,56 @@ func TestClient_CreateAPI(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			createdAPI, err := c.CreateAPI(context.Background(), test.req)
+			createdAccess, err := c.CreateAccess(context.Background(), test.req)
 			test.wantErr(t, err)
 
 			assert.Equal(t, *test.req, gotReq)
-			assert.Equal(t, test.api, createdAPI)
+			assert.Equal(t, test.access, createdAccess)
 		})
 	}
 }
 
-func TestClient_UpdateAPI(t *testing.T) {
+func TestClient_UpdateAccess(t *testing.T) {
 	tests := []struct {
 		desc             string
-		req              *UpdateAPIReq
-		api              *api.API
+		req              *UpdateAccessReq
+		access           *api.Access
 		returnStatusCode int
 		wantErr          assert.ErrorAssertionFunc
 	}{
 		{
-			desc: "update API",
-			req: &UpdateAPIReq{
-				Labels:     map[string]string{"a": "b"},
-				PathPrefix: "prefix",
-				Service: APIService{
-					Name: "svc",
-					Port: 80,
+			desc: "update access",
+			req: &UpdateAccessReq{
+				Labels: map[string]string{"a": "b"},
+				Groups: []string{"group"},
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+				APICollectionSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
 				},
 			},
 			returnStatusCode: http.StatusOK,
 			wantErr:          assert.NoError,
-			api: &api.API{
-				Name:      "name",
-				Namespace: "ns",
+			access: &api.Access{
+				Name: "name",
 				Labels: map[string]string{
 					"a": "b",
 				},
-				PathPrefix: "prefix",
-				Service: api.Service{
-					Name: "svc",
-					Port: 80,
+				Groups: []string{"group"},
+				APISelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
+				},
+				APICollectionSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"key": "value"},
 				},
 				Version: "version-1",
 			},
 		},
 		{
 			desc: "conflict",
-			req: &UpdateAPIReq{
-				Labels:     map[string]string{"a": "b"},
-				PathPrefix: "prefix",
-				Service: APIService{
-					Name: "svc",
-					Port: 80,
-				},
+			req: &UpdateAccessReq{
+				Labels: map[string]string{"a": "b"},
 			},
 			returnStatusCode: http.StatusConflict,
 			wantErr:          assert.Error,
@@ -2467,10 +3507,10 @@ func TestClient_UpdateAPI(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			var gotReq UpdateAPIReq
+			var gotReq UpdateAccessReq
 
 			mux := http.NewServeMux()
-			mux.HandleFunc("/apis/name@ns", func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/accesses/name", func(rw http.ResponseWriter, req *http.Request) {
 				if req.Method != http.MethodPut {
 					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
 					return
@@ -2481,7 +3521,7 @@ func TestClient_UpdateAPI(t *testing.T) {
 					return
 				}
 
-				if req.Header.Get("Last-Known-Version") != "oldVersionAPI" {
+				if req.Header.Get("Last-Known-Version") != "oldAccessVersion" {
 					http.Error(rw, "Invalid token", http.StatusUnauthorized)
 					return
 				}
@@ -2494,7 +3534,7 @@ func TestClient_UpdateAPI(t *testing.T) {
 					return
 				}
 
-				err = json.NewEncoder(rw).Encode(test.api)
+				err = json.NewEncoder(rw).Encode(test.access)
 				require.NoError(t, err)
 			})
 
@@ -2506,34 +3546,31 @@ func TestClient_UpdateAPI(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			updatedAPI, err := c.UpdateAPI(context.Background(), "ns", "name", "oldVersionAPI", test.req)
+			updatedAccess, err := c.UpdateAccess(context.Background(), "name", "oldAccessVersion", test.req)
 			test.wantErr(t, err)
 
 			assert.Equal(t, *test.req, gotReq)
-			assert.Equal(t, test.api, updatedAPI)
+			assert.Equal(t, test.access, updatedAccess)
 		})
 	}
 }
 
-func TestClient_DeleteAPI(t *testing.T) {
+func TestClient_DeleteAccess(t *testing.T) {
 	tests := []struct {
 		desc             string
 		name             string
-		namespace        string
 		returnStatusCode int
 		wantErr          assert.ErrorAssertionFunc
 	}{
 		{
-			desc:             "delete API",
+			desc:             "delete access",
 			name:             "name",
-			namespace:        "ns",
 			returnStatusCode: http.StatusNoContent,
 			wantErr:          assert.NoError,
 		},
 		{
 			desc:             "error",
 			name:             "name",
-			namespace:        "ns",
 			returnStatusCode: http.StatusConflict,
 			wantErr:          assert.Error,
 		},
@@ -2546,7 +3583,7 @@ func TestClient_DeleteAPI(t *testing.T) {
 
 			var callCount int
 			mux := http.NewServeMux()
-			mux.HandleFunc("/apis/"+test.name+"@"+test.namespace, func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/accesses/"+test.name, func(rw http.ResponseWriter, req *http.Request) {
 				callCount++
 
 				if req.Method != http.MethodDelete {
@@ -2558,7 +3595,7 @@ func TestClient_DeleteAPI(t *testing.T) {
 					http.Error(rw, "Invalid token", http.StatusUnauthorized)
 					return
 				}
-				if req.Header.Get("Last-Known-Version") != "oldVersion" {
+				if req.Header.Get("Last-Known-Version") != "oldAccessVersion" {
 					http.Error(rw, "Invalid token", http.StatusInternalServerError)
 					return
 				}
@@ -2574,7 +3611,7 @@ func TestClient_DeleteAPI(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			err = c.DeleteAPI(context.Background(), test.namespace, test.name, "oldVersion")
+			err = c.DeleteAccess(context.Background(), test.name, "oldAccessVersion")
 			test.wantErr(t, err)
 
 			require.Equal(t, 1, callCount)
@@ -2582,23 +3619,19 @@ func TestClient_DeleteAPI(t *testing.T) {
 	}
 }
 
-func TestClient_GetAccesses(t *testing.T) {
-	wantAccesses := []api.Access{
+func TestClient_GetGroups(t *testing.T) {
+	wantGroups := []api.Group{
 		{
-			Name:   "name",
-			Groups: []string{"group"},
-			APISelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"key": "value"},
-			},
-			APICollectionSelector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"key": "value"},
-			},
-			Version: "version-1",
+			ID:         "group-id",
+			Name:       "developers",
+			ExternalID: "external-id",
+			Members:    []api.GroupMember{{ID: "user-id", Display: "john@example.com"}},
+			Version:    "version-1",
 		},
 	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/accesses", func(rw http.ResponseWriter, req *http.Request) {
+	mux.HandleFunc("/groups", func(rw http.ResponseWriter, req *http.Request) {
 		if req.Method != http.MethodGet {
 			http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
 			return
@@ -2610,7 +3643,7 @@ func TestClient_GetAccesses(t *testing.T) {
 		}
 
 		rw.WriteHeader(http.StatusOK)
-		err := json.NewEncoder(rw).Encode(wantAccesses)
+		err := json.NewEncoder(rw).Encode(wantGroups)
 		require.NoError(t, err)
 	})
 
@@ -2622,55 +3655,42 @@ func TestClient_GetAccesses(t *testing.T) {
 	require.NoError(t, err)
 	c.httpClient = srv.Client()
 
-	gotAccesses, err := c.GetAccesses(context.Background())
+	gotGroups, err := c.GetGroups(context.Background())
 	require.NoError(t, err)
 
-	assert.Equal(t, wantAccesses, gotAccesses)
+	assert.Equal(t, wantGroups, gotGroups)
 }
 
-func TestClient_CreateAccess(t *testing.T) {
+func TestClient_CreateGroup(t *testing.T) {
 	tests := []struct {
 		desc             string
-		req              *CreateAccessReq
-		access           *api.Access
+		req              *CreateGroupReq
+		group            *api.Group
 		returnStatusCode int
 		wantErr          assert.ErrorAssertionFunc
 	}{
 		{
-			desc: "create Access",
-			req: &CreateAccessReq{
-				Name:   "name",
-				Labels: map[string]string{"a": "b"},
-				Groups: []string{"group"},
-				APISelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
-				APICollectionSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
+			desc: "create group",
+			req: &CreateGroupReq{
+				Name:       "developers",
+				ExternalID: "external-id",
+				Members:    []api.GroupMember{{ID: "user-id", Display: "john@example.com"}},
 			},
 			returnStatusCode: http.StatusCreated,
 			wantErr:          assert.NoError,
-			access: &api.Access{
-				Name: "name",
-				Labels: map[string]string{
-					"a": "b",
-				},
-				Groups: []string{"group"},
-				APISelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
-				APICollectionSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
-				Version: "version-1",
+			group: &api.Group{
+				ID:         "group-id",
+				Name:       "developers",
+				ExternalID: "external-id",
+				Members:    []api.GroupMember{{ID: "user-id", Display: "john@example.com"}},
+				Version:    "version-1",
 			},
 		},
 		{
 			desc: "error",
-			req: &CreateAccessReq{
-				Name:   "name",
-				Labels: map[string]string{"a": "b"},
+			req: &CreateGroupReq{
+				Name:    "developers",
+				Members: []api.GroupMember{},
 			},
 			returnStatusCode: http.StatusConflict,
 			wantErr:          assert.Error,
@@ -2682,10 +3702,10 @@ func TestClient_CreateAccess(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			var gotReq CreateAccessReq
+			var gotReq CreateGroupReq
 
 			mux := http.NewServeMux()
-			mux.HandleFunc("/accesses", func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/groups", func(rw http.ResponseWriter, req *http.Request) {
 				if req.Method != http.MethodPost {
 					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
 					return
@@ -2704,7 +3724,7 @@ func TestClient_CreateAccess(t *testing.T) {
 					return
 				}
 
-				err = json.NewEncoder(rw).Encode(test.access)
+				err = json.NewEncoder(rw).Encode(test.group)
 				require.NoError(t, err)
 			})
 
@@ -2716,56 +3736,43 @@ func TestClient_CreateAccess(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			createdAccess, err := c.CreateAccess(context.Background(), test.req)
+			createdGroup, err := c.CreateGroup(context.Background(), test.req)
 			test.wantErr(t, err)
 
 			assert.Equal(t, *test.req, gotReq)
-			assert.Equal(t, test.access, createdAccess)
+			assert.Equal(t, test.group, createdGroup)
 		})
 	}
 }
 
-func TestClient_UpdateAccess(t *testing.T) {
+func TestClient_UpdateGroup(t *testing.T) {
 	tests := []struct {
 		desc             string
-		req              *UpdateAccessReq
-		access           *api.Access
+		req              *UpdateGroupReq
+		group            *api.Group
 		returnStatusCode int
 		wantErr          assert.ErrorAssertionFunc
 	}{
 		{
-			desc: "update access",
-			req: &UpdateAccessReq{
-				Labels: map[string]string{"a": "b"},
-				Groups: []string{"group"},
-				APISelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
-				APICollectionSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
+			desc: "update group",
+			req: &UpdateGroupReq{
+				Name:    "developers",
+				Members: []api.GroupMember{{ID: "user-id"}},
 			},
 			returnStatusCode: http.StatusOK,
 			wantErr:          assert.NoError,
-			access: &api.Access{
-				Name: "name",
-				Labels: map[string]string{
-					"a": "b",
-				},
-				Groups: []string{"group"},
-				APISelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
-				APICollectionSelector: &metav1.LabelSelector{
-					MatchLabels: map[string]string{"key": "value"},
-				},
-				Version: "version-1",
+			group: &api.Group{
+				ID:      "group-id",
+				Name:    "developers",
+				Members: []api.GroupMember{{ID: "user-id"}},
+				Version: "version-2",
 			},
 		},
 		{
 			desc: "conflict",
-			req: &UpdateAccessReq{
-				Labels: map[string]string{"a": "b"},
+			req: &UpdateGroupReq{
+				Name:    "developers",
+				Members: []api.GroupMember{},
 			},
 			returnStatusCode: http.StatusConflict,
 			wantErr:          assert.Error,
@@ -2777,10 +3784,10 @@ func TestClient_UpdateAccess(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			var gotReq UpdateAccessReq
+			var gotReq UpdateGroupReq
 
 			mux := http.NewServeMux()
-			mux.HandleFunc("/accesses/name", func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/groups/group-id", func(rw http.ResponseWriter, req *http.Request) {
 				if req.Method != http.MethodPut {
 					http.Error(rw, fmt.Sprintf("unexpected method: %s", req.Method), http.StatusMethodNotAllowed)
 					return
@@ -2791,8 +3798,8 @@ func TestClient_UpdateAccess(t *testing.T) {
 					return
 				}
 
-				if req.Header.Get("Last-Known-Version") != "oldAccessVersion" {
-					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+				if req.Header.Get("Last-Known-Version") != "version-1" {
+					http.Error(rw, "Invalid version", http.StatusConflict)
 					return
 				}
 
@@ -2804,7 +3811,7 @@ func TestClient_UpdateAccess(t *testing.T) {
 					return
 				}
 
-				err = json.NewEncoder(rw).Encode(test.access)
+				err = json.NewEncoder(rw).Encode(test.group)
 				require.NoError(t, err)
 			})
 
@@ -2816,31 +3823,28 @@ func TestClient_UpdateAccess(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			updatedAccess, err := c.UpdateAccess(context.Background(), "name", "oldAccessVersion", test.req)
+			updatedGroup, err := c.UpdateGroup(context.Background(), "group-id", "version-1", test.req)
 			test.wantErr(t, err)
 
 			assert.Equal(t, *test.req, gotReq)
-			assert.Equal(t, test.access, updatedAccess)
+			assert.Equal(t, test.group, updatedGroup)
 		})
 	}
 }
 
-func TestClient_DeleteAccess(t *testing.T) {
+func TestClient_DeleteGroup(t *testing.T) {
 	tests := []struct {
 		desc             string
-		name             string
 		returnStatusCode int
 		wantErr          assert.ErrorAssertionFunc
 	}{
 		{
-			desc:             "delete access",
-			name:             "name",
+			desc:             "delete group",
 			returnStatusCode: http.StatusNoContent,
 			wantErr:          assert.NoError,
 		},
 		{
 			desc:             "error",
-			name:             "name",
 			returnStatusCode: http.StatusConflict,
 			wantErr:          assert.Error,
 		},
@@ -2853,7 +3857,7 @@ func TestClient_DeleteAccess(t *testing.T) {
 
 			var callCount int
 			mux := http.NewServeMux()
-			mux.HandleFunc("/accesses/"+test.name, func(rw http.ResponseWriter, req *http.Request) {
+			mux.HandleFunc("/groups/group-id", func(rw http.ResponseWriter, req *http.Request) {
 				callCount++
 
 				if req.Method != http.MethodDelete {
@@ -2865,8 +3869,8 @@ func TestClient_DeleteAccess(t *testing.T) {
 					http.Error(rw, "Invalid token", http.StatusUnauthorized)
 					return
 				}
-				if req.Header.Get("Last-Known-Version") != "oldAccessVersion" {
-					http.Error(rw, "Invalid token", http.StatusInternalServerError)
+				if req.Header.Get("Last-Known-Version") != "version-1" {
+					http.Error(rw, "Invalid version", http.StatusInternalServerError)
 					return
 				}
 
@@ -2881,10 +3885,125 @@ func TestClient_DeleteAccess(t *testing.T) {
 			require.NoError(t, err)
 			c.httpClient = srv.Client()
 
-			err = c.DeleteAccess(context.Background(), test.name, "oldAccessVersion")
+			err = c.DeleteGroup(context.Background(), "group-id", "version-1")
 			test.wantErr(t, err)
 
 			require.Equal(t, 1, callCount)
 		})
 	}
 }
+
+func TestClient_GetTokenInfo(t *testing.T) {
+	expiresAt := time.Date(2026, 10, 20, 10, 0, 0, 0, time.UTC)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(rw, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(TokenInfo{ExpiresAt: expiresAt})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewClient(srv.URL, testToken)
+	require.NoError(t, err)
+	c.httpClient = srv.Client()
+
+	info, err := c.GetTokenInfo(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, expiresAt, info.ExpiresAt)
+}
+
+func TestClient_RenewToken(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		issued     IssuedToken
+		wantToken  IssuedToken
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			desc:       "renew token succeed",
+			statusCode: http.StatusCreated,
+			issued: IssuedToken{
+				Token:     "new-token",
+				ExpiresAt: time.Date(2026, 11, 20, 10, 0, 0, 0, time.UTC),
+			},
+			wantToken: IssuedToken{
+				Token:     "new-token",
+				ExpiresAt: time.Date(2026, 11, 20, 10, 0, 0, 0, time.UTC),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			desc:       "empty token issued",
+			statusCode: http.StatusCreated,
+			wantErr:    assert.Error,
+		},
+		{
+			desc:       "renewal refused",
+			statusCode: http.StatusForbidden,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				var apiErr APIError
+				return assert.ErrorAs(t, err, &apiErr) && assert.Equal(t, http.StatusForbidden, apiErr.StatusCode)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var callCount int
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/token/renew", func(rw http.ResponseWriter, req *http.Request) {
+				callCount++
+
+				if req.Method != http.MethodPost {
+					http.Error(rw, fmt.Sprintf("unsupported method: %s", req.Method), http.StatusMethodNotAllowed)
+					return
+				}
+
+				if req.Header.Get("Authorization") != "Bearer "+testToken {
+					http.Error(rw, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				rw.WriteHeader(test.statusCode)
+
+				if test.statusCode == http.StatusCreated {
+					_ = json.NewEncoder(rw).Encode(test.issued)
+					return
+				}
+
+				_ = json.NewEncoder(rw).Encode(APIError{StatusCode: test.statusCode, Message: "error"})
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			c, err := NewClient(srv.URL, testToken)
+			require.NoError(t, err)
+			c.httpClient = srv.Client()
+
+			issued, err := c.RenewToken(context.Background())
+			test.wantErr(t, err)
+
+			assert.Equal(t, 1, callCount)
+			assert.Equal(t, test.wantToken, issued)
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
 )
 
 // ConfigWatcher watches hub agent configuration.
@@ -35,6 +36,8 @@ type ConfigWatcher struct {
 
 	listenersMu sync.RWMutex
 	listeners   []func(cfg Config)
+
+	tracker *status.Tracker
 }
 
 // NewConfigWatcher return a new ConfigWatcher.
@@ -42,6 +45,7 @@ func NewConfigWatcher(interval time.Duration, c *Client) *ConfigWatcher {
 	return &ConfigWatcher{
 		client:   c,
 		interval: interval,
+		tracker:  status.NewTracker(),
 	}
 }
 
@@ -55,13 +59,20 @@ func (w *ConfigWatcher) Run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-t.C:
-			if err := w.reload(ctx); err != nil {
+			err := w.reload(ctx)
+			w.tracker.Record(err)
+			if err != nil {
 				log.Error().Err(err).Msg("Unable to reload hub-agent-kubernetes configuration")
 			}
 		}
 	}
 }
 
+// Status reports the link with the platform as down when the configuration couldn't be reloaded for three intervals.
+func (w *ConfigWatcher) Status(_ context.Context) status.Report {
+	return w.tracker.Report(3 * w.interval)
+}
+
 // AddListener adds a listeners to the ConfigWatcher.
 func (w *ConfigWatcher) AddListener(listener func(cfg Config)) {
 	w.listenersMu.Lock()
@@ -0,0 +1,170 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package preflight
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/rbac"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+)
+
+// ResolveHost returns a check verifying the given host can be resolved.
+func ResolveHost(resolver *net.Resolver, host string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return fmt.Errorf("resolve %s: %w", host, err)
+		}
+		if len(addrs) == 0 {
+			return fmt.Errorf("resolve %s: no addresses", host)
+		}
+
+		return nil
+	}
+}
+
+// Dial returns a check verifying a TCP connection can be opened to the given address, which fails when the egress
+// traffic is blocked.
+func Dial(addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return fmt.Errorf("connect to %s: %w", addr, err)
+		}
+
+		return conn.Close()
+	}
+}
+
+// PlatformToken returns a check verifying the platform accepts the token of the given client.
+func PlatformToken(client *platform.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		_, err := client.GetConfig(ctx)
+
+		var apiErr platform.APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+			return errors.New("token rejected by the platform")
+		}
+		if err != nil {
+			return fmt.Errorf("get agent configuration: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// Kinds returns a check verifying the API server serves the given kinds of each group version, meaning their
+// CustomResourceDefinitions are installed.
+func Kinds(client discovery.DiscoveryInterface, kinds map[string][]string) func(ctx context.Context) error {
+	return func(_ context.Context) error {
+		var missing []string
+		for groupVersion, want := range kinds {
+			served := make(map[string]bool)
+
+			resources, err := client.ServerResourcesForGroupVersion(groupVersion)
+			switch {
+			case err == nil:
+				for _, resource := range resources.APIResources {
+					served[resource.Kind] = true
+				}
+			// Because the fake client doesn't return the right error type.
+			case kerror.IsNotFound(err) || strings.HasSuffix(err.Error(), " not found"):
+			default:
+				return fmt.Errorf("list %s resources: %w", groupVersion, err)
+			}
+
+			for _, kind := range want {
+				if !served[kind] {
+					missing = append(missing, kind+"."+groupVersion)
+				}
+			}
+		}
+
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("missing CustomResourceDefinitions: %s", strings.Join(missing, ", "))
+		}
+
+		return nil
+	}
+}
+
+// RBAC returns a check verifying the agent is granted the rules of the given checker.
+func RBAC(checker *rbac.Checker) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if err := checker.Check(ctx); err != nil {
+			return err
+		}
+
+		status := checker.Status()
+		if len(status.Missing) == 0 {
+			return nil
+		}
+
+		missing := make([]string, 0, len(status.Missing))
+		for _, rule := range status.Missing {
+			permission := rule.Verb + " " + rule.Resource
+			if rule.Group != "" {
+				permission += "." + rule.Group
+			}
+			if rule.Namespace != "" {
+				permission += " in " + rule.Namespace
+			}
+
+			missing = append(missing, permission+" ("+rule.Feature+")")
+		}
+
+		return fmt.Errorf("missing permissions: %s", strings.Join(missing, ", "))
+	}
+}
+
+// Reachable returns a check verifying the HTTPS server at the given URL responds. The certificate of the server isn't
+// verified: the check only ensures the server can be reached, like the admission webhooks through their Service.
+func Reachable(url string) func(ctx context.Context) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			// The API server verifies the webhook certificate against the CA bundle of the webhook configurations.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // Only the reachability is checked.
+		},
+	}
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return fmt.Errorf("build request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("reach %s: %w", url, err)
+		}
+
+		return resp.Body.Close()
+	}
+}
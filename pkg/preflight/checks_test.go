@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package preflight
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/rbac"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestDial(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+
+	assert.NoError(t, Dial(addr)(context.Background()))
+
+	require.NoError(t, listener.Close())
+
+	assert.Error(t, Dial(addr)(context.Background()))
+}
+
+func TestPlatformToken(t *testing.T) {
+	tests := []struct {
+		desc       string
+		statusCode int
+		wantErr    string
+	}{
+		{
+			desc:       "valid token",
+			statusCode: http.StatusOK,
+		},
+		{
+			desc:       "rejected token",
+			statusCode: http.StatusUnauthorized,
+			wantErr:    "token rejected by the platform",
+		},
+		{
+			desc:       "not found",
+			statusCode: http.StatusNotFound,
+			wantErr:    "get agent configuration: failed with code 404: Not Found",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "/config", req.URL.Path)
+				assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+				rw.WriteHeader(test.statusCode)
+				if test.statusCode == http.StatusOK {
+					_, _ = rw.Write([]byte("{}"))
+					return
+				}
+				_, _ = rw.Write([]byte(`{"error":"` + http.StatusText(test.statusCode) + `"}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			client, err := platform.NewClient(srv.URL, "token")
+			require.NoError(t, err)
+
+			err = PlatformToken(client)(context.Background())
+			if test.wantErr != "" {
+				assert.EqualError(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestKinds(t *testing.T) {
+	kubeClient := kubemock.NewSimpleClientset()
+	fakeDiscovery, ok := kubeClient.Discovery().(*fakediscovery.FakeDiscovery)
+	require.True(t, ok, "couldn't convert Discovery() to *FakeDiscovery")
+
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "hub.traefik.io/v1alpha1",
+			APIResources: []metav1.APIResource{
+				{Kind: "AccessControlPolicy"},
+				{Kind: "IngressClass"},
+			},
+		},
+	}
+
+	check := Kinds(fakeDiscovery, map[string][]string{
+		"hub.traefik.io/v1alpha1": {"AccessControlPolicy", "EdgeIngress", "IngressClass"},
+		"example.com/v1":          {"Example"},
+	})
+
+	err := check(context.Background())
+	assert.EqualError(t, err, "missing CustomResourceDefinitions: EdgeIngress.hub.traefik.io/v1alpha1, Example.example.com/v1")
+}
+
+func TestRBAC(t *testing.T) {
+	client := kubemock.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Verb != "update"
+
+		return true, review, nil
+	})
+
+	var rules []rbac.Rule
+	rules = append(rules, rbac.Rules("core", "", "secrets", "hub", "get", "update")...)
+	rules = append(rules, rbac.Rules("edge-ingresses", "hub.traefik.io", "edgeingresses", "", "list", "update")...)
+
+	err := RBAC(rbac.NewChecker(client, rules))(context.Background())
+
+	assert.EqualError(t, err, "missing permissions: update secrets in hub (core), update edgeingresses.hub.traefik.io (edge-ingresses)")
+}
+
+func TestReachable(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+
+	assert.NoError(t, Reachable(srv.URL)(context.Background()))
+
+	srv.Close()
+
+	assert.Error(t, Reachable(srv.URL)(context.Background()))
+}
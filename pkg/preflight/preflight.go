@@ -0,0 +1,112 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package preflight verifies the requirements of the agent, reporting the common installation failures up front rather
+// than through the logs of the subsystems they break.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// checkTimeout is the time given to each check to complete.
+const checkTimeout = 10 * time.Second
+
+// Status is the outcome of a check.
+type Status string
+
+// Outcomes of a check.
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+)
+
+// Check verifies a requirement of the agent.
+type Check struct {
+	Name string
+	// Optional reports the requirement as not mandatory: the agent runs without it, with some features disabled.
+	// Failures of optional checks are reported as warnings.
+	Optional bool
+	// Run returns an error explaining why the requirement isn't met.
+	Run func(ctx context.Context) error
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name    string
+	Status  Status
+	Message string
+}
+
+// Run runs the given checks in order and returns their results.
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, check := range checks {
+		results = append(results, run(ctx, check))
+	}
+
+	return results
+}
+
+func run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	result := Result{Name: check.Name, Status: StatusPass}
+	if err := check.Run(ctx); err != nil {
+		result.Status = StatusFail
+		if check.Optional {
+			result.Status = StatusWarn
+		}
+		result.Message = err.Error()
+	}
+
+	return result
+}
+
+// Failed reports whether a mandatory check failed.
+func Failed(results []Result) bool {
+	for _, result := range results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteReport writes the given results as a table.
+func WriteReport(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, result := range results {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Status, result.Name, result.Message); err != nil {
+			return fmt.Errorf("write result: %w", err)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("flush report: %w", err)
+	}
+
+	return nil
+}
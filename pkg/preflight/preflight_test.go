@@ -0,0 +1,65 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	checks := []Check{
+		{Name: "pass", Run: func(context.Context) error { return nil }},
+		{Name: "fail", Run: func(context.Context) error { return errors.New("boom") }},
+		{Name: "warn", Optional: true, Run: func(context.Context) error { return errors.New("disabled") }},
+	}
+
+	results := Run(context.Background(), checks)
+
+	assert.Equal(t, []Result{
+		{Name: "pass", Status: StatusPass},
+		{Name: "fail", Status: StatusFail, Message: "boom"},
+		{Name: "warn", Status: StatusWarn, Message: "disabled"},
+	}, results)
+	assert.True(t, Failed(results))
+	assert.False(t, Failed([]Result{results[0], results[2]}))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, results))
+
+	assert.Equal(t, "PASS  pass  \nFAIL  fail  boom\nWARN  warn  disabled\n", buf.String())
+}
+
+func TestRun_timeout(t *testing.T) {
+	results := Run(context.Background(), []Check{{
+		Name: "deadline",
+		Run: func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+
+			return nil
+		},
+	}})
+
+	assert.Equal(t, []Result{{Name: "deadline", Status: StatusPass}}, results)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package rbac checks that the agent ServiceAccount is granted the permissions the agent needs.
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// Rule is a permission required by a feature of the agent.
+type Rule struct {
+	Feature   string `json:"feature"`
+	Group     string `json:"group"`
+	Resource  string `json:"resource"`
+	Verb      string `json:"verb"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Rules returns the rules required to run the given verbs on the given resource.
+func Rules(feature, group, resource, namespace string, verbs ...string) []Rule {
+	rules := make([]Rule, 0, len(verbs))
+	for _, verb := range verbs {
+		rules = append(rules, Rule{
+			Feature:   feature,
+			Group:     group,
+			Resource:  resource,
+			Verb:      verb,
+			Namespace: namespace,
+		})
+	}
+
+	return rules
+}
+
+// Status is the result of the last permission check.
+type Status struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Missing   []Rule    `json:"missing"`
+}
+
+// Checker periodically checks, using SelfSubjectAccessReviews, that the agent is granted the given rules. Missing rules
+// are logged and reported by its status endpoint, so features don't silently fail.
+type Checker struct {
+	client clientset.Interface
+	rules  []Rule
+	now    func() time.Time
+
+	statusMu sync.RWMutex
+	status   *Status
+}
+
+// NewChecker creates a new Checker.
+func NewChecker(client clientset.Interface, rules []Rule) *Checker {
+	return &Checker{
+		client: client,
+		rules:  rules,
+		now:    time.Now,
+	}
+}
+
+// Run checks the rules at the given interval until the given context is canceled.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if err := c.Check(ctx); err != nil {
+			log.Error().Err(err).Msg("Unable to check RBAC permissions")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Check checks the rules and updates the status. Rules which are missing but weren't at the previous check are logged.
+func (c *Checker) Check(ctx context.Context) error {
+	status := &Status{
+		CheckedAt: c.now(),
+		Missing:   []Rule{},
+	}
+
+	for _, rule := range c.rules {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: rule.Namespace,
+					Verb:      rule.Verb,
+					Group:     rule.Group,
+					Resource:  rule.Resource,
+				},
+			},
+		}
+
+		review, err := c.client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("review access to %s/%s: %w", rule.Group, rule.Resource, err)
+		}
+
+		if !review.Status.Allowed {
+			status.Missing = append(status.Missing, rule)
+		}
+	}
+
+	c.statusMu.Lock()
+	previous := c.status
+	c.status = status
+	c.statusMu.Unlock()
+
+	for _, rule := range status.Missing {
+		if previous != nil && contains(previous.Missing, rule) {
+			continue
+		}
+
+		log.Warn().
+			Str("feature", rule.Feature).
+			Str("group", rule.Group).
+			Str("resource", rule.Resource).
+			Str("verb", rule.Verb).
+			Str("namespace", rule.Namespace).
+			Msg("Missing RBAC permission, the feature may not work")
+	}
+
+	return nil
+}
+
+// Status returns the status of the last check, nil if no check has been done yet.
+func (c *Checker) Status() *Status {
+	c.statusMu.RLock()
+	defer c.statusMu.RUnlock()
+
+	return c.status
+}
+
+// ServeHTTP serves the status of the last check. It responds with a 503 status code when rules are missing or if no
+// check has been done yet.
+func (c *Checker) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	status := c.Status()
+
+	if status == nil {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if len(status.Missing) > 0 {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(rw).Encode(status); err != nil {
+		log.Error().Err(err).Msg("Unable to write RBAC status")
+	}
+}
+
+func contains(rules []Rule, rule Rule) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestChecker(t *testing.T) {
+	client := kubemock.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+
+		review.Status.Allowed = attrs.Resource != "apiportals" && attrs.Verb != "delete"
+
+		return true, review, nil
+	})
+
+	var rules []Rule
+	rules = append(rules, Rules("core", "", "secrets", "hub", "get", "update")...)
+	rules = append(rules, Rules("api-management", "hub.traefik.io", "apiportals", "", "list")...)
+	rules = append(rules, Rules("acp", "traefik.containo.us", "middlewares", "", "create", "delete")...)
+
+	c := NewChecker(client, rules)
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rbac", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	require.NoError(t, c.Check(context.Background()))
+
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rbac", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var status Status
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	assert.Equal(t, []Rule{
+		{Feature: "api-management", Group: "hub.traefik.io", Resource: "apiportals", Verb: "list"},
+		{Feature: "acp", Group: "traefik.containo.us", Resource: "middlewares", Verb: "delete"},
+	}, status.Missing)
+}
+
+func TestChecker_allGranted(t *testing.T) {
+	client := kubemock.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = true
+
+		return true, review, nil
+	})
+
+	c := NewChecker(client, Rules("core", "", "services", "", "list", "watch"))
+	require.NoError(t, c.Check(context.Background()))
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rbac", http.NoBody))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var status Status
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	assert.Empty(t, status.Missing)
+}
@@ -0,0 +1,394 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package replication replicates the certificates managed by the agent in the namespaces using them.
+package replication
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/typed/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// Annotations driving the replication of certificates.
+const (
+	// AnnotationReplicateCertificate opts a certificate Secret managed by the agent in the replication when set to
+	// "true".
+	AnnotationReplicateCertificate = "hub.traefik.io/replicate-certificate"
+	// AnnotationReplicatedFrom references the Secret, as "namespace/name", a replica has been copied from.
+	AnnotationReplicatedFrom = "hub.traefik.io/replicated-from"
+)
+
+const (
+	labelManagedBy = "app.kubernetes.io/managed-by"
+	managedBy      = "traefik-hub"
+)
+
+var (
+	// hostMatcherRe matches the Host matchers of an IngressRoute rule.
+	hostMatcherRe = regexp.MustCompile("Host\\(([^)]*)\\)")
+	// hostRe matches the hosts of a Host matcher.
+	hostRe = regexp.MustCompile("`([^`]+)`")
+)
+
+// Watcher replicates the certificate Secrets managed by the agent and opting in the replication in the namespaces
+// having Ingresses or IngressRoutes referencing, for one of the certificate domains, a Secret with the same name.
+// Replicas are kept in sync with their source when the certificate is renewed, and deleted once they are no longer
+// referenced. Secrets which aren't replicas are never overwritten.
+type Watcher struct {
+	interval time.Duration
+
+	kubeClientSet    clientset.Interface
+	kubeInformer     informers.SharedInformerFactory
+	traefikClientSet traefikclientset.TraefikV1alpha1Interface
+}
+
+// NewWatcher returns a new Watcher. The Traefik client set is optional: IngressRoutes are not considered without it.
+func NewWatcher(interval time.Duration, kubeClientSet clientset.Interface, kubeInformer informers.SharedInformerFactory,
+	traefikClientSet traefikclientset.TraefikV1alpha1Interface,
+) *Watcher {
+	return &Watcher{
+		interval:         interval,
+		kubeClientSet:    kubeClientSet,
+		kubeInformer:     kubeInformer,
+		traefikClientSet: traefikClientSet,
+	}
+}
+
+// Run runs the Watcher.
+func (w *Watcher) Run(ctx context.Context) {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Stopping certificate replication watcher")
+			return
+
+		case <-t.C:
+			ctxSync, cancel := context.WithTimeout(ctx, 20*time.Second)
+			if err := w.replicate(ctxSync); err != nil {
+				log.Error().Err(err).Msg("Unable to replicate certificates")
+			}
+			cancel()
+		}
+	}
+}
+
+// tlsRef is a reference, from an Ingress or an IngressRoute, to a TLS Secret serving the given hosts.
+type tlsRef struct {
+	namespace  string
+	secretName string
+	hosts      []string
+}
+
+func (w *Watcher) replicate(ctx context.Context) error {
+	secretList, err := w.kubeClientSet.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: labelManagedBy + "=" + managedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("list secrets: %w", err)
+	}
+
+	sources, replicas := splitSecrets(secretList.Items)
+	refs := w.tlsRefs(ctx)
+
+	// Replicas are indexed by their "namespace/name" key.
+	want := make(map[string]*corev1.Secret)
+	for _, source := range sources {
+		domains, err := certificateDomains(source.Data[corev1.TLSCertKey])
+		if err != nil {
+			log.Error().Err(err).
+				Str("name", source.Name).
+				Str("namespace", source.Namespace).
+				Msg("Unable to read replicated certificate")
+			continue
+		}
+
+		for _, ref := range refs {
+			if ref.namespace == source.Namespace || ref.secretName != source.Name || !matchAny(ref.hosts, domains) {
+				continue
+			}
+
+			key := ref.namespace + "/" + ref.secretName
+			if _, ok := want[key]; ok {
+				continue
+			}
+
+			want[key] = newReplica(source, ref.namespace)
+		}
+	}
+
+	for _, key := range sortedKeys(want) {
+		replica := want[key]
+		if err = w.upsertReplica(ctx, replica); err != nil {
+			log.Error().Err(err).
+				Str("name", replica.Name).
+				Str("namespace", replica.Namespace).
+				Str("replicated_from", replica.Annotations[AnnotationReplicatedFrom]).
+				Msg("Unable to replicate certificate")
+		}
+	}
+
+	for _, replica := range replicas {
+		if _, ok := want[replica.Namespace+"/"+replica.Name]; ok {
+			continue
+		}
+
+		err = w.kubeClientSet.CoreV1().Secrets(replica.Namespace).Delete(ctx, replica.Name, metav1.DeleteOptions{})
+		if err != nil && !kerror.IsNotFound(err) {
+			log.Error().Err(err).
+				Str("name", replica.Name).
+				Str("namespace", replica.Namespace).
+				Msg("Unable to delete certificate replica")
+			continue
+		}
+
+		log.Debug().
+			Str("name", replica.Name).
+			Str("namespace", replica.Namespace).
+			Msg("Certificate replica deleted")
+	}
+
+	return nil
+}
+
+func (w *Watcher) upsertReplica(ctx context.Context, replica *corev1.Secret) error {
+	secret, err := w.kubeClientSet.CoreV1().Secrets(replica.Namespace).Get(ctx, replica.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get secret: %w", err)
+	}
+
+	if err == nil {
+		if secret.Annotations[AnnotationReplicatedFrom] != replica.Annotations[AnnotationReplicatedFrom] {
+			return fmt.Errorf("secret %s/%s already exists and is not a replica", secret.Namespace, secret.Name)
+		}
+
+		if dataEqual(secret.Data, replica.Data) {
+			return nil
+		}
+	}
+
+	_, err = kube.Apply(ctx, w.kubeClientSet.CoreV1().Secrets(replica.Namespace).Patch, replica.Name, replica)
+	if err != nil {
+		return fmt.Errorf("apply secret: %w", err)
+	}
+
+	log.Debug().
+		Str("name", replica.Name).
+		Str("namespace", replica.Namespace).
+		Msg("Certificate replica applied")
+
+	return nil
+}
+
+// tlsRefs returns the TLS Secrets referenced by the Ingresses and IngressRoutes of the cluster.
+func (w *Watcher) tlsRefs(ctx context.Context) []tlsRef {
+	var refs []tlsRef
+
+	ingresses, err := w.kubeInformer.Networking().V1().Ingresses().Lister().List(labels.Everything())
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to list Ingresses")
+	}
+	for _, ing := range ingresses {
+		for _, tls := range ing.Spec.TLS {
+			if tls.SecretName == "" {
+				continue
+			}
+
+			refs = append(refs, tlsRef{namespace: ing.Namespace, secretName: tls.SecretName, hosts: tls.Hosts})
+		}
+	}
+
+	if w.traefikClientSet == nil {
+		return refs
+	}
+
+	var ingRoutes *traefikv1alpha1.IngressRouteList
+	ingRoutes, err = w.traefikClientSet.IngressRoutes(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Error().Err(err).Msg("Unable to list IngressRoutes")
+		return refs
+	}
+
+	for _, ingRoute := range ingRoutes.Items {
+		if ingRoute.Spec.TLS == nil || ingRoute.Spec.TLS.SecretName == "" {
+			continue
+		}
+
+		var hosts []string
+		for _, route := range ingRoute.Spec.Routes {
+			hosts = append(hosts, ruleHosts(route.Match)...)
+		}
+
+		refs = append(refs, tlsRef{namespace: ingRoute.Namespace, secretName: ingRoute.Spec.TLS.SecretName, hosts: hosts})
+	}
+
+	return refs
+}
+
+// splitSecrets splits the given managed Secrets into the ones opting in the replication and the replicas.
+func splitSecrets(secrets []corev1.Secret) (sources, replicas []*corev1.Secret) {
+	for i := range secrets {
+		secret := &secrets[i]
+
+		switch {
+		case secret.Annotations[AnnotationReplicatedFrom] != "":
+			replicas = append(replicas, secret)
+		case secret.Annotations[AnnotationReplicateCertificate] == "true" && secret.Type == corev1.SecretTypeTLS:
+			sources = append(sources, secret)
+		}
+	}
+
+	// Sources are sorted so that a Secret referenced by several of them is always replicated from the same one.
+	sort.Slice(sources, func(i, j int) bool {
+		if sources[i].Namespace != sources[j].Namespace {
+			return sources[i].Namespace < sources[j].Namespace
+		}
+		return sources[i].Name < sources[j].Name
+	})
+
+	return sources, replicas
+}
+
+func newReplica(source *corev1.Secret, namespace string) *corev1.Secret {
+	data := make(map[string][]byte, len(source.Data))
+	for k, v := range source.Data {
+		data[k] = v
+	}
+
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				labelManagedBy: managedBy,
+			},
+			Annotations: map[string]string{
+				AnnotationReplicatedFrom: source.Namespace + "/" + source.Name,
+			},
+		},
+		Type: source.Type,
+		Data: data,
+	}
+}
+
+// certificateDomains returns the domains of the leaf certificate of the given PEM encoded chain.
+func certificateDomains(certPEM []byte) ([]string, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	return cert.DNSNames, nil
+}
+
+// ruleHosts returns the hosts of the Host matchers of the given IngressRoute rule.
+func ruleHosts(rule string) []string {
+	var hosts []string
+	for _, matcher := range hostMatcherRe.FindAllStringSubmatch(rule, -1) {
+		for _, host := range hostRe.FindAllStringSubmatch(matcher[1], -1) {
+			hosts = append(hosts, host[1])
+		}
+	}
+
+	return hosts
+}
+
+// matchAny returns whether one of the hosts is covered by one of the certificate domains.
+func matchAny(hosts, domains []string) bool {
+	for _, host := range hosts {
+		for _, domain := range domains {
+			if matchDomain(host, domain) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchDomain returns whether the host is covered by the certificate domain, which may be a wildcard domain.
+func matchDomain(host, domain string) bool {
+	host = strings.ToLower(host)
+	domain = strings.ToLower(domain)
+
+	if host == domain {
+		return true
+	}
+
+	suffix, ok := strings.CutPrefix(domain, "*")
+	if !ok {
+		return false
+	}
+
+	label, ok := strings.CutSuffix(host, suffix)
+
+	return ok && label != "" && !strings.Contains(label, ".")
+}
+
+func dataEqual(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !bytes.Equal(v, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedKeys(m map[string]*corev1.Secret) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,206 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package replication
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube/kubetest"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWatcher_replicate(t *testing.T) {
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "hub-certificate",
+			Namespace:   "agent-ns",
+			Labels:      map[string]string{labelManagedBy: managedBy},
+			Annotations: map[string]string{AnnotationReplicateCertificate: "true"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       generateCertificate(t, "*.example.com"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+	userSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-certificate", Namespace: "user"},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("user")},
+	}
+	staleReplica := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "hub-certificate",
+			Namespace:   "stale",
+			Labels:      map[string]string{labelManagedBy: managedBy},
+			Annotations: map[string]string{AnnotationReplicatedFrom: "agent-ns/hub-certificate"},
+		},
+	}
+
+	kubeClientSet := kubemock.NewSimpleClientset(
+		source, userSecret, staleReplica,
+		newIngress("apps", "hub-certificate", "foo.example.com"),
+		newIngress("user", "hub-certificate", "bar.example.com"),
+		newIngress("other-domain", "hub-certificate", "foo.other.com"),
+		newIngress("other-secret", "other-certificate", "foo.example.com"),
+		newIngress("nested", "hub-certificate", "foo.bar.example.com"),
+	)
+	kubetest.AddApplyReactor(kubeClientSet)
+	traefikClientSet := traefikkubemock.NewSimpleClientset(&traefikv1alpha1.IngressRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "books", Namespace: "books"},
+		Spec: traefikv1alpha1.IngressRouteSpec{
+			Routes: []traefikv1alpha1.Route{{Match: "Host(`books.example.com`) && PathPrefix(`/books`)"}},
+			TLS:    &traefikv1alpha1.TLS{SecretName: "hub-certificate"},
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	kubeInformer := informers.NewSharedInformerFactory(kubeClientSet, 0)
+	kubeInformer.Networking().V1().Ingresses().Informer()
+	kubeInformer.Start(ctx.Done())
+	kubeInformer.WaitForCacheSync(ctx.Done())
+
+	w := NewWatcher(time.Minute, kubeClientSet, kubeInformer, traefikClientSet.TraefikV1alpha1())
+
+	err := w.replicate(ctx)
+	require.NoError(t, err)
+
+	for _, namespace := range []string{"apps", "books"} {
+		replica, err := kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, "hub-certificate", metav1.GetOptions{})
+		require.NoError(t, err, namespace)
+
+		assert.Equal(t, source.Data, replica.Data)
+		assert.Equal(t, corev1.SecretTypeTLS, replica.Type)
+		assert.Equal(t, "agent-ns/hub-certificate", replica.Annotations[AnnotationReplicatedFrom])
+	}
+
+	for _, namespace := range []string{"other-domain", "other-secret", "nested", "stale"} {
+		_, err = kubeClientSet.CoreV1().Secrets(namespace).Get(ctx, "hub-certificate", metav1.GetOptions{})
+		assert.True(t, kerror.IsNotFound(err), namespace)
+	}
+
+	// Secrets which aren't replicas are left untouched.
+	got, err := kubeClientSet.CoreV1().Secrets("user").Get(ctx, "hub-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, userSecret.Data, got.Data)
+
+	// Replicas are updated when the certificate is renewed.
+	source.Data = map[string][]byte{
+		corev1.TLSCertKey:       generateCertificate(t, "*.example.com"),
+		corev1.TLSPrivateKeyKey: []byte("renewed-key"),
+	}
+	_, err = kubeClientSet.CoreV1().Secrets("agent-ns").Update(ctx, source, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = w.replicate(ctx)
+	require.NoError(t, err)
+
+	got, err = kubeClientSet.CoreV1().Secrets("apps").Get(ctx, "hub-certificate", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, source.Data, got.Data)
+
+	// Replicas are deleted when the source no longer opts in the replication.
+	source.Annotations = nil
+	_, err = kubeClientSet.CoreV1().Secrets("agent-ns").Update(ctx, source, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	err = w.replicate(ctx)
+	require.NoError(t, err)
+
+	_, err = kubeClientSet.CoreV1().Secrets("apps").Get(ctx, "hub-certificate", metav1.GetOptions{})
+	assert.True(t, kerror.IsNotFound(err))
+}
+
+func Test_matchDomain(t *testing.T) {
+	tests := []struct {
+		host   string
+		domain string
+		want   bool
+	}{
+		{host: "example.com", domain: "example.com", want: true},
+		{host: "Example.COM", domain: "example.com", want: true},
+		{host: "foo.example.com", domain: "*.example.com", want: true},
+		{host: "*.example.com", domain: "*.example.com", want: true},
+		{host: "example.com", domain: "*.example.com"},
+		{host: "foo.bar.example.com", domain: "*.example.com"},
+		{host: "foo.example.com", domain: "example.com"},
+		{host: "fooexample.com", domain: "*.example.com"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.host+"_"+test.domain, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, matchDomain(test.host, test.domain))
+		})
+	}
+}
+
+func Test_ruleHosts(t *testing.T) {
+	got := ruleHosts("(Host(`a.example.com`, `b.example.com`) || Host(`c.example.com`)) && HostRegexp(`{any:.+}`)")
+
+	assert.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, got)
+}
+
+func newIngress(namespace, secretName string, hosts ...string) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: namespace},
+		Spec: netv1.IngressSpec{
+			TLS: []netv1.IngressTLS{{Hosts: hosts, SecretName: secretName}},
+		},
+	}
+}
+
+func generateCertificate(t *testing.T, domains ...string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
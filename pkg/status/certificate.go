@@ -0,0 +1,64 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+// certificateExpiryWarning is the time before the expiry of a certificate from which it is reported as degraded.
+const certificateExpiryWarning = 7 * 24 * time.Hour
+
+// CertificateCheck returns a check reporting the certificate returned by the given function as down when it can't be
+// loaded or expired, and as degraded when it expires in less than a week.
+func CertificateCheck(getCertificate func() (*tls.Certificate, error)) Check {
+	return func(_ context.Context) Report {
+		cert, err := getCertificate()
+		if err != nil {
+			return Report{State: StateDown, Message: err.Error()}
+		}
+		if cert == nil || len(cert.Certificate) == 0 {
+			return Report{State: StateDown, Message: "No certificate"}
+		}
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return Report{State: StateDown, Message: fmt.Sprintf("parse certificate: %v", err)}
+		}
+
+		report := Report{
+			State:   StateOK,
+			Details: map[string]string{"notAfter": leaf.NotAfter.UTC().Format(time.RFC3339)},
+		}
+
+		switch remaining := time.Until(leaf.NotAfter); {
+		case remaining <= 0:
+			report.State = StateDown
+			report.Message = "Certificate expired"
+		case remaining < certificateExpiryWarning:
+			report.State = StateDegraded
+			report.Message = fmt.Sprintf("Certificate expires in %s", remaining.Round(time.Minute))
+		}
+
+		return report
+	}
+}
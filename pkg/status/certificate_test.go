@@ -0,0 +1,100 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateCheck(t *testing.T) {
+	tests := []struct {
+		desc      string
+		notAfter  time.Time
+		wantState State
+	}{
+		{
+			desc:      "valid",
+			notAfter:  time.Now().Add(30 * 24 * time.Hour),
+			wantState: StateOK,
+		},
+		{
+			desc:      "expires soon",
+			notAfter:  time.Now().Add(24 * time.Hour),
+			wantState: StateDegraded,
+		},
+		{
+			desc:      "expired",
+			notAfter:  time.Now().Add(-time.Hour),
+			wantState: StateDown,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert := newCertificate(t, test.notAfter)
+
+			got := CertificateCheck(func() (*tls.Certificate, error) {
+				return cert, nil
+			})(context.Background())
+
+			assert.Equal(t, test.wantState, got.State)
+			assert.Equal(t, test.notAfter.UTC().Format(time.RFC3339), got.Details["notAfter"])
+		})
+	}
+}
+
+func TestCertificateCheck_error(t *testing.T) {
+	got := CertificateCheck(func() (*tls.Certificate, error) {
+		return nil, errors.New("boom")
+	})(context.Background())
+
+	assert.Equal(t, Report{State: StateDown, Message: "boom"}, got)
+}
+
+func newCertificate(t *testing.T, notAfter time.Time) *tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// watchErrorRetention is the time during which the informers are reported as degraded after a watch error.
+const watchErrorRetention = 5 * time.Minute
+
+// InformerTracker tracks whether the caches of informers are synced and the errors they get while watching their
+// resources.
+type InformerTracker struct {
+	mu         sync.Mutex
+	synced     []cache.InformerSynced
+	lastErr    error
+	lastErrAt  time.Time
+	errorCount int
+
+	now func() time.Time
+}
+
+// NewInformerTracker returns a new InformerTracker.
+func NewInformerTracker() *InformerTracker {
+	return &InformerTracker{now: time.Now}
+}
+
+// Track tracks the given informers. It must be called before the informers are started.
+func (t *InformerTracker) Track(informers ...cache.SharedIndexInformer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, informer := range informers {
+		if err := informer.SetWatchErrorHandler(t.watchErrorHandler()); err != nil {
+			return fmt.Errorf("set watch error handler: %w", err)
+		}
+
+		t.synced = append(t.synced, informer.HasSynced)
+	}
+
+	return nil
+}
+
+func (t *InformerTracker) watchErrorHandler() cache.WatchErrorHandler {
+	return func(r *cache.Reflector, err error) {
+		// Keep logging the watch errors the way the informers do by default.
+		cache.DefaultWatchErrorHandler(r, err)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.lastErr = err
+		t.lastErrAt = t.now()
+		t.errorCount++
+	}
+}
+
+// Check reports the informers as down while their caches aren't synced, and as degraded if they got a watch error
+// recently.
+func (t *InformerTracker) Check(_ context.Context) Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var notSynced int
+	for _, synced := range t.synced {
+		if !synced() {
+			notSynced++
+		}
+	}
+
+	report := Report{
+		State: StateOK,
+		Details: map[string]string{
+			"informers":   fmt.Sprint(len(t.synced)),
+			"watchErrors": fmt.Sprint(t.errorCount),
+		},
+	}
+
+	switch {
+	case notSynced > 0:
+		report.State = StateDown
+		report.Message = fmt.Sprintf("%d informer caches not synced", notSynced)
+	case t.lastErr != nil && t.now().Sub(t.lastErrAt) < watchErrorRetention:
+		report.State = StateDegraded
+		report.Message = t.lastErr.Error()
+	}
+
+	return report
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RemoteCheck returns a check reporting the status served, by a Handler, at the given URL. It is meant to aggregate the
+// status of the agent components running in their own process, like the tunnel. Since these components are deployed
+// on their own, they are reported as degraded, rather than down, when unhealthy or unreachable: they can't make the
+// current process unready.
+func RemoteCheck(client *http.Client, url string) Check {
+	return func(ctx context.Context) Report {
+		resp, err := fetchStatus(ctx, client, url)
+		if err != nil {
+			return Report{State: StateDegraded, Message: err.Error()}
+		}
+
+		report := Report{State: resp.State}
+		for name, subsystem := range resp.Subsystems {
+			if subsystem.State == StateOK {
+				continue
+			}
+
+			if report.Details == nil {
+				report.Details = make(map[string]string)
+			}
+			report.Details[name] = string(subsystem.State) + ": " + subsystem.Message
+		}
+
+		if report.State == StateDown {
+			report.State = StateDegraded
+		}
+
+		return report
+	}
+}
+
+func fetchStatus(ctx context.Context, client *http.Client, url string) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get status: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var status Response
+	if err = json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decode status, got status code %d: %w", resp.StatusCode, err)
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package status aggregates the health of the subsystems of the agent, to be reported as JSON by support tooling and
+// used as a readiness gate.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// State is the state of a subsystem.
+type State string
+
+// States of the subsystems, from the healthiest to the unhealthiest.
+const (
+	StateOK       State = "ok"
+	StateDegraded State = "degraded"
+	StateDown     State = "down"
+)
+
+// checkTimeout is the time given to all the checks to report the state of their subsystem.
+const checkTimeout = 5 * time.Second
+
+// Report is the state of a subsystem.
+type Report struct {
+	State State `json:"state"`
+	// Message explains the state when the subsystem isn't healthy.
+	Message string `json:"message,omitempty"`
+	// LastSuccess is the last time the subsystem successfully performed its periodic work, if any.
+	LastSuccess *time.Time        `json:"lastSuccess,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// Check reports the state of a subsystem.
+type Check func(ctx context.Context) Report
+
+// LeaderOnly returns a check reporting the given subsystem as healthy when the current replica isn't the leader, since
+// the subsystem only runs on the leader.
+func LeaderOnly(isLeader func() bool, check Check) Check {
+	return func(ctx context.Context) Report {
+		if !isLeader() {
+			return Report{State: StateOK, Message: "Runs on the leader replica"}
+		}
+
+		return check(ctx)
+	}
+}
+
+// Response is the aggregated status of the agent.
+type Response struct {
+	// State is the state of the unhealthiest subsystem.
+	State      State             `json:"state"`
+	Subsystems map[string]Report `json:"subsystems"`
+}
+
+// Handler serves the aggregated status of the registered subsystems. It responds with a 503 status code when a
+// subsystem is down, so it can be used as a readiness gate.
+type Handler struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewHandler returns a new Handler.
+func NewHandler() *Handler {
+	return &Handler{checks: make(map[string]Check)}
+}
+
+// Register registers the check of the subsystem with the given name, replacing the one already registered, if any.
+func (h *Handler) Register(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks[name] = check
+}
+
+// Status runs the registered checks and returns the aggregated status.
+func (h *Handler) Status(ctx context.Context) Response {
+	h.mu.RLock()
+	checks := make(map[string]Check, len(h.checks))
+	names := make([]string, 0, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+		names = append(names, name)
+	}
+	h.mu.RUnlock()
+
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	resp := Response{State: StateOK, Subsystems: make(map[string]Report, len(names))}
+	for _, name := range names {
+		report := checks[name](ctx)
+		resp.Subsystems[name] = report
+
+		if severity(report.State) > severity(resp.State) {
+			resp.State = report.State
+		}
+	}
+
+	return resp
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := h.Status(req.Context())
+
+	rw.Header().Set("Content-Type", "application/json")
+	if resp.State == StateDown {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(rw).Encode(resp); err != nil {
+		log.Error().Err(err).Msg("Unable to write status response")
+	}
+}
+
+func severity(state State) int {
+	switch state {
+	case StateOK:
+		return 0
+	case StateDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		desc       string
+		checks     map[string]Check
+		wantStatus int
+		wantState  State
+	}{
+		{
+			desc:       "no subsystems",
+			wantStatus: http.StatusOK,
+			wantState:  StateOK,
+		},
+		{
+			desc: "all subsystems healthy",
+			checks: map[string]Check{
+				"a": stateCheck(StateOK),
+				"b": stateCheck(StateOK),
+			},
+			wantStatus: http.StatusOK,
+			wantState:  StateOK,
+		},
+		{
+			desc: "degraded subsystem",
+			checks: map[string]Check{
+				"a": stateCheck(StateOK),
+				"b": stateCheck(StateDegraded),
+			},
+			wantStatus: http.StatusOK,
+			wantState:  StateDegraded,
+		},
+		{
+			desc: "down subsystem",
+			checks: map[string]Check{
+				"a": stateCheck(StateDown),
+				"b": stateCheck(StateDegraded),
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantState:  StateDown,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewHandler()
+			for name, check := range test.checks {
+				handler.Register(name, check)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", http.NoBody))
+
+			assert.Equal(t, test.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var got Response
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+
+			assert.Equal(t, test.wantState, got.State)
+			require.Len(t, got.Subsystems, len(test.checks))
+			for name, check := range test.checks {
+				assert.Equal(t, check(context.Background()), got.Subsystems[name])
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP_methodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/status", http.NoBody))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestLeaderOnly(t *testing.T) {
+	var leader bool
+	check := LeaderOnly(func() bool { return leader }, stateCheck(StateDown))
+
+	assert.Equal(t, StateOK, check(context.Background()).State)
+
+	leader = true
+	assert.Equal(t, StateDown, check(context.Background()).State)
+}
+
+func TestRemoteCheck(t *testing.T) {
+	tests := []struct {
+		desc   string
+		remote Check
+		want   Report
+	}{
+		{
+			desc:   "healthy",
+			remote: stateCheck(StateOK),
+			want:   Report{State: StateOK},
+		},
+		{
+			desc:   "degraded",
+			remote: stateCheck(StateDegraded),
+			want: Report{
+				State:   StateDegraded,
+				Details: map[string]string{"remote": "degraded: degraded"},
+			},
+		},
+		{
+			desc:   "down is reported as degraded",
+			remote: stateCheck(StateDown),
+			want: Report{
+				State:   StateDegraded,
+				Details: map[string]string{"remote": "down: down"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			remote := NewHandler()
+			remote.Register("remote", test.remote)
+
+			srv := httptest.NewServer(remote)
+			t.Cleanup(srv.Close)
+
+			got := RemoteCheck(srv.Client(), srv.URL)(context.Background())
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRemoteCheck_unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	got := RemoteCheck(http.DefaultClient, srv.URL)(context.Background())
+
+	assert.Equal(t, StateDegraded, got.State)
+	assert.NotEmpty(t, got.Message)
+}
+
+func stateCheck(state State) Check {
+	return func(_ context.Context) Report {
+		if state == StateOK {
+			return Report{State: state}
+		}
+
+		return Report{State: state, Message: string(state)}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tracker records the outcome of the periodic work of a subsystem, like pushing data to the platform. A nil Tracker
+// records nothing, so subsystems can record their outcomes whether or not they are tracked.
+type Tracker struct {
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastErr     error
+
+	now func() time.Time
+}
+
+// NewTracker returns a new Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{now: time.Now}
+}
+
+// Record records the outcome of the periodic work, which succeeded when the given error is nil.
+func (t *Tracker) Record(err error) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err != nil {
+		t.lastFailure = t.now()
+		t.lastErr = err
+		return
+	}
+
+	t.lastSuccess = t.now()
+}
+
+// Report returns the state of the subsystem, which is expected to succeed at least once every maxAge. The subsystem is
+// down when it never succeeded or didn't succeed for more than maxAge, and degraded when its last attempt failed.
+func (t *Tracker) Report(maxAge time.Duration) Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var report Report
+	if !t.lastSuccess.IsZero() {
+		lastSuccess := t.lastSuccess
+		report.LastSuccess = &lastSuccess
+	}
+
+	switch {
+	case t.lastSuccess.IsZero() && t.lastErr == nil:
+		report.State = StateDegraded
+		report.Message = "Not run yet"
+	case t.lastSuccess.IsZero():
+		report.State = StateDown
+		report.Message = t.lastErr.Error()
+	case t.now().Sub(t.lastSuccess) > maxAge:
+		report.State = StateDown
+		report.Message = fmt.Sprintf("No success for more than %s", maxAge)
+		if t.lastErr != nil && t.lastFailure.After(t.lastSuccess) {
+			report.Message += ": " + t.lastErr.Error()
+		}
+	case t.lastFailure.After(t.lastSuccess):
+		report.State = StateDegraded
+		report.Message = t.lastErr.Error()
+	default:
+		report.State = StateOK
+	}
+
+	return report
+}
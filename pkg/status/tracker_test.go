@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_Report(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc        string
+		record      func(tracker *Tracker, now *time.Time)
+		wantState   State
+		wantMessage string
+		wantSuccess bool
+	}{
+		{
+			desc:        "not run yet",
+			record:      func(*Tracker, *time.Time) {},
+			wantState:   StateDegraded,
+			wantMessage: "Not run yet",
+		},
+		{
+			desc: "never succeeded",
+			record: func(tracker *Tracker, _ *time.Time) {
+				tracker.Record(errors.New("boom"))
+			},
+			wantState:   StateDown,
+			wantMessage: "boom",
+		},
+		{
+			desc: "recent success",
+			record: func(tracker *Tracker, now *time.Time) {
+				tracker.Record(nil)
+				*now = now.Add(time.Minute)
+			},
+			wantState:   StateOK,
+			wantSuccess: true,
+		},
+		{
+			desc: "failure after a recent success",
+			record: func(tracker *Tracker, now *time.Time) {
+				tracker.Record(nil)
+				*now = now.Add(time.Minute)
+				tracker.Record(errors.New("boom"))
+			},
+			wantState:   StateDegraded,
+			wantMessage: "boom",
+			wantSuccess: true,
+		},
+		{
+			desc: "success after a failure",
+			record: func(tracker *Tracker, now *time.Time) {
+				tracker.Record(errors.New("boom"))
+				*now = now.Add(time.Minute)
+				tracker.Record(nil)
+			},
+			wantState:   StateOK,
+			wantSuccess: true,
+		},
+		{
+			desc: "old success",
+			record: func(tracker *Tracker, now *time.Time) {
+				tracker.Record(nil)
+				*now = now.Add(time.Hour)
+			},
+			wantState:   StateDown,
+			wantMessage: "No success for more than 10m0s",
+			wantSuccess: true,
+		},
+		{
+			desc: "failures after an old success",
+			record: func(tracker *Tracker, now *time.Time) {
+				tracker.Record(nil)
+				*now = now.Add(time.Hour)
+				tracker.Record(errors.New("boom"))
+			},
+			wantState:   StateDown,
+			wantMessage: "No success for more than 10m0s: boom",
+			wantSuccess: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			now := start
+			tracker := NewTracker()
+			tracker.now = func() time.Time { return now }
+
+			test.record(tracker, &now)
+
+			got := tracker.Report(10 * time.Minute)
+
+			assert.Equal(t, test.wantState, got.State)
+			assert.Equal(t, test.wantMessage, got.Message)
+			assert.Equal(t, test.wantSuccess, got.LastSuccess != nil)
+		})
+	}
+}
+
+func TestTracker_Record_nil(t *testing.T) {
+	var tracker *Tracker
+
+	assert.NotPanics(t, func() { tracker.Record(nil) })
+}
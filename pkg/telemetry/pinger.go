@@ -0,0 +1,50 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package telemetry
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+)
+
+// Pinger pings the platform with the usage of the agent features.
+type Pinger struct {
+	platform  *platform.Client
+	collector *Collector
+}
+
+// NewPinger returns a new Pinger.
+func NewPinger(client *platform.Client, collector *Collector) *Pinger {
+	return &Pinger{platform: client, collector: collector}
+}
+
+// Ping pings the platform with the usage of the agent features. The usage is left out when it can't be collected,
+// so the heartbeat doesn't depend on it.
+func (p *Pinger) Ping(ctx context.Context) error {
+	usage, ok, err := p.collector.Collect(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to collect the feature usage")
+	}
+	if err != nil || !ok {
+		return p.platform.Ping(ctx)
+	}
+
+	return p.platform.PingWithUsage(ctx, usage)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package telemetry reports the anonymized usage of the agent features to the platform, so it can give upgrade and
+// configuration advice per cluster.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/metadata"
+)
+
+// managedSecretSelector selects the Secrets created by the agent.
+const managedSecretSelector = "app.kubernetes.io/managed-by=traefik-hub"
+
+// Collector collects the usage of the agent features from the topology and from the certificates managed by the
+// agent.
+type Collector struct {
+	metadata metadata.Interface
+	cluster  atomic.Pointer[state.Cluster]
+}
+
+// NewCollector returns a new Collector. The certificates are listed with the given client, which only gets their
+// metadata.
+func NewCollector(metadataClient metadata.Interface) *Collector {
+	return &Collector{metadata: metadataClient}
+}
+
+// TopologyStateChanged is called every time the topology state changes.
+func (c *Collector) TopologyStateChanged(_ context.Context, cluster *state.Cluster) {
+	if cluster == nil {
+		return
+	}
+
+	c.cluster.Store(cluster)
+}
+
+// Collect returns the usage of the agent features. It returns false when the topology hasn't been fetched yet.
+func (c *Collector) Collect(ctx context.Context) (platform.FeatureUsage, bool, error) {
+	cluster := c.cluster.Load()
+	if cluster == nil {
+		return platform.FeatureUsage{}, false, nil
+	}
+
+	usage := Usage(cluster)
+
+	secrets, err := c.metadata.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).List(ctx, metav1.ListOptions{
+		LabelSelector: managedSecretSelector,
+		FieldSelector: "type=" + string(corev1.SecretTypeTLS),
+	})
+	if err != nil {
+		return platform.FeatureUsage{}, false, fmt.Errorf("list managed certificates: %w", err)
+	}
+	usage.ManagedCertificates = len(secrets.Items)
+
+	return usage, true, nil
+}
+
+// Usage returns the usage of the agent features found in the given topology.
+func Usage(cluster *state.Cluster) platform.FeatureUsage {
+	usage := platform.FeatureUsage{
+		ACPs:          make(map[string]int),
+		EdgeIngresses: len(cluster.EdgeIngresses),
+		APIs:          len(cluster.APIs),
+		PublishedAPIs: len(publishedAPIs(cluster)),
+		APIGateways:   len(cluster.APIGateways),
+		APIPortals:    len(cluster.APIPortals),
+	}
+
+	for _, policy := range cluster.AccessControlPolicies {
+		usage.ACPs[policy.Method]++
+	}
+
+	return usage
+}
+
+// publishedAPIs returns the keys of the APIs exposed by at least one APIGateway, through the APIAccesses it
+// references.
+func publishedAPIs(cluster *state.Cluster) map[string]struct{} {
+	published := make(map[string]struct{})
+
+	for _, gateway := range cluster.APIGateways {
+		for _, accessName := range gateway.APIAccesses {
+			access, ok := cluster.APIAccesses[accessName]
+			if !ok {
+				continue
+			}
+
+			for key, api := range cluster.APIs {
+				if accessSelects(cluster, access, api) {
+					published[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return published
+}
+
+func accessSelects(cluster *state.Cluster, access *state.APIAccess, api *state.API) bool {
+	if selects(access.APISelector, api.Labels) {
+		return true
+	}
+
+	if access.APICollectionSelector == nil {
+		return false
+	}
+
+	for _, collection := range cluster.APICollections {
+		if selects(access.APICollectionSelector, collection.Labels) && selects(&collection.APISelector, api.Labels) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selects reports whether the given selector matches the given labels. A nil or an invalid selector matches nothing.
+func selects(selector *metav1.LabelSelector, lbls map[string]string) bool {
+	if selector == nil {
+		return false
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return s.Matches(labels.Set(lbls))
+}
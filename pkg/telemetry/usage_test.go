@@ -0,0 +1,139 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestUsage(t *testing.T) {
+	cluster := &state.Cluster{
+		AccessControlPolicies: map[string]*state.AccessControlPolicy{
+			"jwt-1":  {Name: "jwt-1", Method: "jwt"},
+			"jwt-2":  {Name: "jwt-2", Method: "jwt"},
+			"oidc-1": {Name: "oidc-1", Method: "oidc"},
+		},
+		EdgeIngresses: map[string]*state.EdgeIngress{
+			"edge@default": {Name: "edge", Namespace: "default"},
+		},
+		APIs: map[string]*state.API{
+			"books@default":    {Name: "books", Namespace: "default", Labels: map[string]string{"area": "library"}},
+			"authors@default":  {Name: "authors", Namespace: "default", Labels: map[string]string{"product": "store"}},
+			"payments@default": {Name: "payments", Namespace: "default", Labels: map[string]string{"area": "bank"}},
+			"internal@default": {Name: "internal", Namespace: "default"},
+		},
+		APICollections: map[string]*state.APICollection{
+			"store": {
+				Name:        "store",
+				Labels:      map[string]string{"collection": "store"},
+				APISelector: metav1.LabelSelector{MatchLabels: map[string]string{"product": "store"}},
+			},
+		},
+		APIAccesses: map[string]*state.APIAccess{
+			"library": {
+				Name:        "library",
+				APISelector: &metav1.LabelSelector{MatchLabels: map[string]string{"area": "library"}},
+			},
+			"store": {
+				Name:                  "store",
+				APICollectionSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"collection": "store"}},
+			},
+			"bank": {
+				Name:        "bank",
+				APISelector: &metav1.LabelSelector{MatchLabels: map[string]string{"area": "bank"}},
+			},
+		},
+		APIGateways: map[string]*state.APIGateway{
+			"gateway": {Name: "gateway", APIAccesses: []string{"library", "store", "unknown"}},
+		},
+		APIPortals: map[string]*state.APIPortal{
+			"portal": {Name: "portal", APIGateway: "gateway"},
+		},
+	}
+
+	got := Usage(cluster)
+
+	assert.Equal(t, platform.FeatureUsage{
+		ACPs:          map[string]int{"jwt": 2, "oidc": 1},
+		EdgeIngresses: 1,
+		APIs:          4,
+		PublishedAPIs: 2,
+		APIGateways:   1,
+		APIPortals:    1,
+	}, got)
+}
+
+func TestCollector_Collect(t *testing.T) {
+	scheme := metadatafake.NewTestScheme()
+	require.NoError(t, metav1.AddMetaToScheme(scheme))
+
+	client := metadatafake.NewSimpleMetadataClient(scheme,
+		newSecretMetadata("hub-certificate", map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"}),
+		newSecretMetadata("hub-certificate-custom-domains-edge", map[string]string{"app.kubernetes.io/managed-by": "traefik-hub"}),
+		newSecretMetadata("user-certificate", nil),
+	)
+
+	var fieldSelector string
+	client.PrependReactor("list", "secrets", func(action ktesting.Action) (bool, runtime.Object, error) {
+		fieldSelector = action.(ktesting.ListAction).GetListRestrictions().Fields.String()
+		return false, nil, nil
+	})
+
+	collector := NewCollector(client)
+
+	_, ok, err := collector.Collect(context.Background())
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	collector.TopologyStateChanged(context.Background(), &state.Cluster{
+		AccessControlPolicies: map[string]*state.AccessControlPolicy{
+			"basic": {Name: "basic", Method: "basicAuth"},
+		},
+	})
+
+	got, ok, err := collector.Collect(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Equal(t, platform.FeatureUsage{
+		ACPs:                map[string]int{"basicAuth": 1},
+		ManagedCertificates: 2,
+	}, got)
+	assert.Equal(t, "type=kubernetes.io/tls", fieldSelector)
+}
+
+func newSecretMetadata(name string, labels map[string]string) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels,
+		},
+	}
+}
@@ -0,0 +1,344 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package tokenrotation renews the platform token of the agent before it expires and persists it in a Secret, so the
+// agent never has to be onboarded again because its token expired.
+package tokenrotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	defaultSecretKey   = "token"
+	defaultRenewBefore = 24 * time.Hour
+
+	checkInterval  = time.Hour
+	reloadInterval = time.Minute
+	minRetryDelay  = time.Minute
+	maxRetryDelay  = time.Hour
+)
+
+// Platform can get the expiry of the token of the agent and issue a new one.
+type Platform interface {
+	GetTokenInfo(ctx context.Context) (platform.TokenInfo, error)
+	RenewToken(ctx context.Context) (platform.IssuedToken, error)
+}
+
+// Config configures the Manager.
+type Config struct {
+	// SecretNamespace and SecretName locate the Secret in which the renewed token is persisted.
+	SecretNamespace string
+	SecretName      string
+	// SecretKey is the key of the token in the Secret. Defaults to "token".
+	SecretKey string
+	// RenewBefore is the time before the expiry of the token from which it is renewed. Defaults to 24h.
+	RenewBefore time.Duration
+}
+
+// Manager renews the platform token of the agent before it expires. The renewed token is persisted in a Secret, from
+// which all the replicas and components of the agent, and the agent itself when it restarts, pick it up.
+type Manager struct {
+	platform   Platform
+	kubeClient kubernetes.Interface
+	cfg        Config
+	host       string
+	now        func() time.Time
+
+	mu        sync.RWMutex
+	token     string
+	expiresAt time.Time
+	// unpersisted is true when the current token has been issued but not persisted in the Secret yet.
+	unpersisted bool
+
+	retryDelay time.Duration
+}
+
+// NewManager returns a Manager renewing the given token on the platform at the given URL.
+func NewManager(platformURL, token string, p Platform, kubeClient kubernetes.Interface, cfg Config) (*Manager, error) {
+	baseURL, err := url.ParseRequestURI(platformURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse platform url: %w", err)
+	}
+
+	if cfg.SecretNamespace == "" || cfg.SecretName == "" {
+		return nil, errors.New("secret namespace and name are required")
+	}
+	if cfg.SecretKey == "" {
+		cfg.SecretKey = defaultSecretKey
+	}
+	if cfg.RenewBefore <= 0 {
+		cfg.RenewBefore = defaultRenewBefore
+	}
+
+	return &Manager{
+		platform:   p,
+		kubeClient: kubeClient,
+		cfg:        cfg,
+		host:       baseURL.Host,
+		now:        time.Now,
+		token:      token,
+	}, nil
+}
+
+// Token returns the current platform token.
+func (m *Manager) Token() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.token
+}
+
+// Load adopts the token persisted in the Secret, if any. It takes precedence over the token the agent started with,
+// which may have been renewed since.
+func (m *Manager) Load(ctx context.Context) error {
+	secret, err := m.kubeClient.CoreV1().Secrets(m.cfg.SecretNamespace).Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+	if err != nil {
+		if kerror.IsNotFound(err) {
+			return nil
+		}
+
+		return fmt.Errorf("get secret: %w", err)
+	}
+
+	token := string(secret.Data[m.cfg.SecretKey])
+	if token == "" {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The token issued to this replica takes precedence until it is persisted.
+	if m.unpersisted || token == m.token {
+		return nil
+	}
+
+	m.token = token
+	m.expiresAt = time.Time{}
+
+	log.Ctx(ctx).Info().Msg("Platform token reloaded from secret")
+
+	return nil
+}
+
+// Watch periodically adopts the token persisted in the Secret, which is renewed by the leader. This is a blocking
+// method.
+func (m *Manager) Watch(ctx context.Context) {
+	t := time.NewTicker(reloadInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if err := m.Load(ctx); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Unable to reload platform token")
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Run renews the token before it expires. It must only run on the leader. This is a blocking method.
+func (m *Manager) Run(ctx context.Context) {
+	for {
+		t := time.NewTimer(m.rotate(ctx))
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
+
+// rotate renews the token if it expires soon and returns the delay before the next rotation attempt.
+func (m *Manager) rotate(ctx context.Context) time.Duration {
+	logger := log.Ctx(ctx)
+
+	m.mu.RLock()
+	unpersisted := m.unpersisted
+	m.mu.RUnlock()
+
+	if unpersisted {
+		if err := m.persist(ctx); err != nil {
+			logger.Error().Err(err).Msg("Unable to persist renewed platform token")
+			return m.backoff()
+		}
+	}
+
+	expiresAt, err := m.expiry(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get platform token expiry")
+		return m.backoff()
+	}
+
+	// A zero expiry means the token never expires.
+	if expiresAt.IsZero() {
+		m.retryDelay = 0
+		return checkInterval
+	}
+
+	renewAt := expiresAt.Add(-m.cfg.RenewBefore)
+	if wait := renewAt.Sub(m.now()); wait > 0 {
+		m.retryDelay = 0
+		if wait > checkInterval {
+			return checkInterval
+		}
+
+		return wait
+	}
+
+	issued, err := m.platform.RenewToken(ctx)
+	if err != nil {
+		logger.Error().Err(err).Time("expires_at", expiresAt).Msg("Unable to renew platform token")
+		return m.backoff()
+	}
+
+	// The renewed token is used right away, even if it cannot be persisted yet: the current one expires soon.
+	m.mu.Lock()
+	m.token = issued.Token
+	m.expiresAt = issued.ExpiresAt
+	m.unpersisted = true
+	m.mu.Unlock()
+
+	logger.Info().Time("expires_at", issued.ExpiresAt).Msg("Platform token renewed")
+
+	if err = m.persist(ctx); err != nil {
+		logger.Error().Err(err).Msg("Unable to persist renewed platform token")
+		return m.backoff()
+	}
+
+	m.retryDelay = 0
+
+	return 0
+}
+
+// expiry returns the expiry of the current token, asking the platform when it is unknown.
+func (m *Manager) expiry(ctx context.Context) (time.Time, error) {
+	m.mu.RLock()
+	expiresAt := m.expiresAt
+	m.mu.RUnlock()
+
+	if !expiresAt.IsZero() {
+		return expiresAt, nil
+	}
+
+	info, err := m.platform.GetTokenInfo(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	m.mu.Lock()
+	m.expiresAt = info.ExpiresAt
+	m.mu.Unlock()
+
+	return info.ExpiresAt, nil
+}
+
+// persist stores the current token in the Secret, keeping its other keys.
+func (m *Manager) persist(ctx context.Context) error {
+	token := m.Token()
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secrets := m.kubeClient.CoreV1().Secrets(m.cfg.SecretNamespace)
+
+		secret, err := secrets.Get(ctx, m.cfg.SecretName, metav1.GetOptions{})
+		if kerror.IsNotFound(err) {
+			_, err = secrets.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      m.cfg.SecretName,
+					Namespace: m.cfg.SecretNamespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/managed-by": "traefik-hub",
+					},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{m.cfg.SecretKey: []byte(token)},
+			}, metav1.CreateOptions{})
+
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[m.cfg.SecretKey] = []byte(token)
+
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("persist token in secret %s/%s: %w", m.cfg.SecretNamespace, m.cfg.SecretName, err)
+	}
+
+	m.mu.Lock()
+	if m.token == token {
+		m.unpersisted = false
+	}
+	m.mu.Unlock()
+
+	return nil
+}
+
+// backoff returns the delay before retrying a failed rotation, doubling it on each consecutive failure.
+func (m *Manager) backoff() time.Duration {
+	m.retryDelay *= 2
+	switch {
+	case m.retryDelay < minRetryDelay:
+		m.retryDelay = minRetryDelay
+	case m.retryDelay > maxRetryDelay:
+		m.retryDelay = maxRetryDelay
+	}
+
+	return m.retryDelay
+}
+
+// Wrap returns a transport authenticating the requests sent to the platform with the current token. Requests sent to
+// other hosts are left untouched, so the token never leaks. The given transport is returned as is when the Manager is
+// nil, which is the case when the token rotation is disabled.
+func (m *Manager) Wrap(rt http.RoundTripper) http.RoundTripper {
+	if m == nil {
+		return rt
+	}
+
+	return httpclient.NewBearerTransport(rt, m.host, func(context.Context) (string, error) {
+		return m.Token(), nil
+	}, nil)
+}
@@ -0,0 +1,207 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tokenrotation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/platform"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+type platformMock struct {
+	info       platform.TokenInfo
+	issued     platform.IssuedToken
+	renewErr   error
+	renewCalls int
+}
+
+func (p *platformMock) GetTokenInfo(_ context.Context) (platform.TokenInfo, error) {
+	return p.info, nil
+}
+
+func (p *platformMock) RenewToken(_ context.Context) (platform.IssuedToken, error) {
+	p.renewCalls++
+	return p.issued, p.renewErr
+}
+
+var now = time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+
+func newTestManager(t *testing.T, p Platform, objects ...runtime.Object) (*Manager, *kubemock.Clientset) {
+	t.Helper()
+
+	kubeClient := kubemock.NewSimpleClientset(objects...)
+
+	m, err := NewManager("https://platform.hub.traefik.io/agent", "token", p, kubeClient, Config{
+		SecretNamespace: "hub-agent",
+		SecretName:      "hub-agent-token",
+	})
+	require.NoError(t, err)
+
+	m.now = func() time.Time { return now }
+
+	return m, kubeClient
+}
+
+func TestManager_rotate_notExpiringSoon(t *testing.T) {
+	p := &platformMock{info: platform.TokenInfo{ExpiresAt: now.Add(24*time.Hour + 10*time.Minute)}}
+	m, _ := newTestManager(t, p)
+
+	assert.Equal(t, 10*time.Minute, m.rotate(context.Background()))
+	assert.Equal(t, 0, p.renewCalls)
+	assert.Equal(t, "token", m.Token())
+
+	p.info.ExpiresAt = time.Time{}
+	m.expiresAt = time.Time{}
+
+	assert.Equal(t, checkInterval, m.rotate(context.Background()))
+	assert.Equal(t, 0, p.renewCalls)
+}
+
+func TestManager_rotate_renew(t *testing.T) {
+	p := &platformMock{
+		info:   platform.TokenInfo{ExpiresAt: now.Add(time.Hour)},
+		issued: platform.IssuedToken{Token: "renewed", ExpiresAt: now.Add(30 * 24 * time.Hour)},
+	}
+	m, kubeClient := newTestManager(t, p, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-agent-token", Namespace: "hub-agent"},
+		Data:       map[string][]byte{"other": []byte("value")},
+	})
+
+	assert.Equal(t, time.Duration(0), m.rotate(context.Background()))
+	assert.Equal(t, 1, p.renewCalls)
+	assert.Equal(t, "renewed", m.Token())
+
+	secret, err := kubeClient.CoreV1().Secrets("hub-agent").Get(context.Background(), "hub-agent-token", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"other": []byte("value"), "token": []byte("renewed")}, secret.Data)
+
+	// The renewed token is checked again at the latest an hour later.
+	assert.Equal(t, checkInterval, m.rotate(context.Background()))
+	assert.Equal(t, 1, p.renewCalls)
+}
+
+func TestManager_rotate_renewFailure(t *testing.T) {
+	p := &platformMock{
+		info:     platform.TokenInfo{ExpiresAt: now.Add(time.Hour)},
+		renewErr: errors.New("boom"),
+	}
+	m, _ := newTestManager(t, p)
+
+	assert.Equal(t, time.Minute, m.rotate(context.Background()))
+	assert.Equal(t, 2*time.Minute, m.rotate(context.Background()))
+	assert.Equal(t, "token", m.Token())
+
+	p.renewErr = nil
+	p.issued = platform.IssuedToken{Token: "renewed", ExpiresAt: now.Add(30 * 24 * time.Hour)}
+
+	assert.Equal(t, time.Duration(0), m.rotate(context.Background()))
+	assert.Equal(t, "renewed", m.Token())
+	assert.Equal(t, time.Duration(0), m.retryDelay)
+}
+
+func TestManager_rotate_persistFailure(t *testing.T) {
+	p := &platformMock{
+		info:   platform.TokenInfo{ExpiresAt: now.Add(time.Hour)},
+		issued: platform.IssuedToken{Token: "renewed", ExpiresAt: now.Add(30 * 24 * time.Hour)},
+	}
+	m, kubeClient := newTestManager(t, p)
+
+	kubeClient.PrependReactor("create", "secrets", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("boom")
+	})
+
+	// The renewed token is used even though it could not be persisted.
+	assert.Equal(t, time.Minute, m.rotate(context.Background()))
+	assert.Equal(t, "renewed", m.Token())
+
+	// The token persisted by another replica must not override the unpersisted one.
+	require.NoError(t, m.Load(context.Background()))
+	assert.Equal(t, "renewed", m.Token())
+
+	kubeClient.ReactionChain = kubeClient.ReactionChain[1:]
+
+	assert.Equal(t, checkInterval, m.rotate(context.Background()))
+	assert.Equal(t, 1, p.renewCalls)
+
+	secret, err := kubeClient.CoreV1().Secrets("hub-agent").Get(context.Background(), "hub-agent-token", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("renewed"), secret.Data["token"])
+}
+
+func TestManager_Load(t *testing.T) {
+	m, kubeClient := newTestManager(t, &platformMock{})
+
+	require.NoError(t, m.Load(context.Background()))
+	assert.Equal(t, "token", m.Token())
+
+	_, err := kubeClient.CoreV1().Secrets("hub-agent").Create(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-agent-token", Namespace: "hub-agent"},
+		Data:       map[string][]byte{"token": []byte("renewed")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Load(context.Background()))
+	assert.Equal(t, "renewed", m.Token())
+}
+
+func TestManager_Wrap(t *testing.T) {
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotAuth = append(gotAuth, req.Header.Get("Authorization"))
+	}))
+	t.Cleanup(srv.Close)
+
+	m, err := NewManager(srv.URL+"/agent", "token", &platformMock{}, kubemock.NewSimpleClientset(), Config{
+		SecretNamespace: "hub-agent",
+		SecretName:      "hub-agent-token",
+	})
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: m.Wrap(nil)}
+
+	resp, err := client.Get(srv.URL + "/agent/config")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	m.token = "renewed"
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/agent/config", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer stale")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, []string{"Bearer token", "Bearer renewed"}, gotAuth)
+
+	var nilManager *Manager
+	assert.Equal(t, http.DefaultTransport, nilManager.Wrap(http.DefaultTransport))
+}
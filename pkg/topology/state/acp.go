@@ -74,6 +74,10 @@ func (f *Fetcher) getAccessControlPolicies() (map[string]*AccessControlPolicy, e
 			acp.Method = "oAuthIntro"
 			acp.OAuthIntro = makeAccessControlPolicyOAuthIntro(policy.Spec.OAuthIntro)
 
+		case policy.Spec.GeoIP != nil:
+			acp.Method = "geoIp"
+			acp.GeoIP = makeAccessControlPolicyGeoIP(policy.Spec.GeoIP)
+
 		default:
 			continue
 		}
@@ -234,6 +238,30 @@ func makeAccessControlPolicyOAuthIntro(cfg *hubv1alpha1.AccessControlOAuthIntro)
 	return policy
 }
 
+func makeAccessControlPolicyGeoIP(cfg *hubv1alpha1.AccessControlPolicyGeoIP) *AccessControlPolicyGeoIP {
+	policy := &AccessControlPolicyGeoIP{
+		AllowedCountries: cfg.AllowedCountries,
+		DeniedCountries:  cfg.DeniedCountries,
+		AllowedASNs:      cfg.AllowedASNs,
+		DeniedASNs:       cfg.DeniedASNs,
+		ForwardHeaders:   cfg.ForwardHeaders,
+	}
+
+	for _, db := range cfg.Databases {
+		database := GeoIPDatabase{Path: db.Path}
+		if db.Secret != nil {
+			database.Secret = &SecretReference{
+				Name:      db.Secret.Name,
+				Namespace: db.Secret.Namespace,
+			}
+		}
+
+		policy.Databases = append(policy.Databases, database)
+	}
+
+	return policy
+}
+
 func redactPasswords(rawUsers []string) string {
 	var users []string
 
@@ -20,15 +20,18 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubemock "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
 )
 
 func TestFetcher_GetAPIs(t *testing.T) {
@@ -53,7 +56,7 @@ func TestFetcher_GetAPIs(t *testing.T) {
 	objects := loadK8sObjects(t, "fixtures/api/api.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.getAPIs()
@@ -84,7 +87,7 @@ func TestFetcher_getAPICollections(t *testing.T) {
 	objects := loadK8sObjects(t, "fixtures/api/api_collection.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.getAPICollections()
@@ -115,7 +118,7 @@ func TestFetcher_GetAPIAccesses(t *testing.T) {
 	objects := loadK8sObjects(t, "fixtures/api/access.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.getAPIAccesses()
@@ -138,7 +141,7 @@ func TestFetcher_GetAPIPortals(t *testing.T) {
 	objects := loadK8sObjects(t, "fixtures/api/portal.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.getAPIPortals()
@@ -164,7 +167,7 @@ func TestFetcher_GetAPIGateways(t *testing.T) {
 	objects := loadK8sObjects(t, "fixtures/api/gateway.yml")
 	kubeClient, traefikClient, hubClient := setupClientSets(t, objects)
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.getAPIGateways()
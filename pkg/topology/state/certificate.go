@@ -0,0 +1,120 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetIngressCertificates returns the leaf certificates of the TLS secrets referenced by the Ingresses and
+// IngressRoutes of the given name. The ingress is identified by its `name@namespace` name, optionally followed by its
+// `.kind.group`.
+func (f *Fetcher) GetIngressCertificates(ctx context.Context, ingress string) ([]*x509.Certificate, error) {
+	ingress, _, _ = strings.Cut(ingress, ".")
+	name, namespace, ok := strings.Cut(ingress, "@")
+	if !ok {
+		return nil, fmt.Errorf("invalid ingress name %q", ingress)
+	}
+
+	secretNames, err := f.getIngressSecretNames(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for _, secretName := range secretNames {
+		secret, err := f.clientSet.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			if kerror.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("get secret %s/%s: %w", secretName, namespace, err)
+		}
+
+		cert, err := parseLeafCertificate(secret.Data[corev1.TLSCertKey])
+		if err != nil {
+			return nil, fmt.Errorf("parse certificate of secret %s/%s: %w", secretName, namespace, err)
+		}
+		if cert != nil {
+			certs = append(certs, cert)
+		}
+	}
+
+	return certs, nil
+}
+
+func (f *Fetcher) getIngressSecretNames(name, namespace string) ([]string, error) {
+	ingresses, err := f.fetchIngresses()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+
+	var secretNames []string
+	addSecretName := func(secretName string) {
+		if _, ok := seen[secretName]; secretName == "" || ok {
+			return
+		}
+		seen[secretName] = struct{}{}
+		secretNames = append(secretNames, secretName)
+	}
+
+	for _, ing := range ingresses {
+		if ing.Name != name || ing.Namespace != namespace {
+			continue
+		}
+
+		for _, tls := range ing.Spec.TLS {
+			addSecretName(tls.SecretName)
+		}
+	}
+
+	ingressRoute, err := f.traefik.Traefik().V1alpha1().IngressRoutes().Lister().IngressRoutes(namespace).Get(name)
+	if err != nil && !kerror.IsNotFound(err) {
+		return nil, fmt.Errorf("get ingress route %s/%s: %w", name, namespace, err)
+	}
+	if ingressRoute != nil && ingressRoute.Spec.TLS != nil {
+		addSecretName(ingressRoute.Spec.TLS.SecretName)
+	}
+
+	return secretNames, nil
+}
+
+// parseLeafCertificate parses the first certificate of the given PEM encoded chain. It returns nil if there are none.
+func parseLeafCertificate(chain []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, chain = pem.Decode(chain)
+		if block == nil {
+			return nil, nil
+		}
+
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
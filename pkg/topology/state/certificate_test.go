@@ -0,0 +1,106 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+func TestFetcher_GetIngressCertificates(t *testing.T) {
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	kubeClient := kubemock.NewSimpleClientset(
+		&netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "myns"},
+			Spec: netv1.IngressSpec{
+				TLS: []netv1.IngressTLS{
+					{Hosts: []string{"a.example.com"}, SecretName: "web-tls"},
+					{Hosts: []string{"b.example.com"}, SecretName: "web-tls"},
+					{Hosts: []string{"c.example.com"}, SecretName: "missing-tls"},
+				},
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "myns"},
+			Type:       corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey: generateCertificate(t, notAfter),
+			},
+		},
+	)
+	traefikClient := traefikkubemock.NewSimpleClientset()
+	hubClient := hubkubemock.NewSimpleClientset()
+
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
+	require.NoError(t, err)
+
+	certs, err := f.GetIngressCertificates(context.Background(), "web@myns")
+	require.NoError(t, err)
+	require.Len(t, certs, 1)
+	assert.Equal(t, notAfter, certs[0].NotAfter)
+
+	certs, err = f.GetIngressCertificates(context.Background(), "web@myns.ingress.networking.k8s.io")
+	require.NoError(t, err)
+	assert.Len(t, certs, 1)
+
+	certs, err = f.GetIngressCertificates(context.Background(), "unknown@myns")
+	require.NoError(t, err)
+	assert.Empty(t, certs)
+
+	_, err = f.GetIngressCertificates(context.Background(), "web")
+	assert.Error(t, err)
+}
+
+func generateCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "a.example.com"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -29,6 +29,7 @@ import (
 type Cluster struct {
 	Ingresses             map[string]*Ingress             `json:"ingresses"`
 	IngressRoutes         map[string]*IngressRoute        `json:"ingressRoutes"`
+	OpenShiftRoutes       map[string]*OpenShiftRoute      `json:"openShiftRoutes,omitempty"`
 	Services              map[string]*Service             `json:"services"`
 	AccessControlPolicies map[string]*AccessControlPolicy `json:"accessControlPolicies"`
 	EdgeIngresses         map[string]*EdgeIngress         `json:"edgeIngresses"`
@@ -37,6 +38,7 @@ type Cluster struct {
 	APICollections        map[string]*APICollection       `json:"apiCollections"`
 	APIPortals            map[string]*APIPortal           `json:"apiPortals"`
 	APIGateways           map[string]*APIGateway          `json:"apiGateways"`
+	Events                map[string]*Event               `json:"events,omitempty"`
 }
 
 // ResourceMeta represents the metadata which identify a Kubernetes resource.
@@ -118,6 +120,17 @@ type RouteService struct {
 	PortNumber int32  `json:"portNumber,omitempty"`
 }
 
+// OpenShiftRoute describes an OpenShift Route.
+type OpenShiftRoute struct {
+	ResourceMeta
+	IngressMeta
+
+	Host        string   `json:"host,omitempty"`
+	Path        string   `json:"path,omitempty"`
+	Termination string   `json:"termination,omitempty"`
+	Services    []string `json:"services,omitempty"`
+}
+
 // AccessControlPolicy describes an Access Control Policy configured within a cluster.
 type AccessControlPolicy struct {
 	Name       string                         `json:"name"`
@@ -128,6 +141,7 @@ type AccessControlPolicy struct {
 	OIDC       *AccessControlPolicyOIDC       `json:"oidc,omitempty"`
 	OIDCGoogle *AccessControlPolicyOIDCGoogle `json:"oidcGoogle,omitempty"`
 	OAuthIntro *AccessControlPolicyOAuthIntro `json:"oAuthIntro,omitempty"`
+	GeoIP      *AccessControlPolicyGeoIP      `json:"geoIp,omitempty"`
 }
 
 // AccessControlPolicyJWT describes the settings for JWT authentication within an access control policy.
@@ -229,6 +243,22 @@ type AccessControlPolicyOAuthIntro struct {
 	ForwardHeaders map[string]string `json:"forwardHeaders,omitempty"`
 }
 
+// AccessControlPolicyGeoIP holds the GeoIP configuration.
+type AccessControlPolicyGeoIP struct {
+	Databases        []GeoIPDatabase   `json:"databases,omitempty"`
+	AllowedCountries []string          `json:"allowedCountries,omitempty"`
+	DeniedCountries  []string          `json:"deniedCountries,omitempty"`
+	AllowedASNs      []uint            `json:"allowedAsns,omitempty"`
+	DeniedASNs       []uint            `json:"deniedAsns,omitempty"`
+	ForwardHeaders   map[string]string `json:"forwardHeaders,omitempty"`
+}
+
+// GeoIPDatabase references a MaxMind DB database.
+type GeoIPDatabase struct {
+	Path   string           `json:"path,omitempty"`
+	Secret *SecretReference `json:"secret,omitempty"`
+}
+
 // ClientConfig configures the HTTP client of the OAuth 2.0 Token Introspection ACP handler.
 type ClientConfig struct {
 	httpclient.Config
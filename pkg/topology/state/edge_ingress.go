@@ -37,13 +37,15 @@ func (f *Fetcher) getEdgeIngresses() (map[string]*EdgeIngress, error) {
 			acp = &EdgeIngressACP{Name: edgeIngress.Spec.ACP.Name}
 		}
 
+		service := edgeIngress.Spec.ActiveService()
+
 		result[objectKey(edgeIngress.Name, edgeIngress.Namespace)] = &EdgeIngress{
 			Name:      edgeIngress.Name,
 			Namespace: edgeIngress.Namespace,
 			Status:    status,
 			Service: EdgeIngressService{
-				Name: edgeIngress.Spec.Service.Name,
-				Port: edgeIngress.Spec.Service.Port,
+				Name: service.Name,
+				Port: service.Port,
 			},
 			ACP: acp,
 		}
@@ -20,14 +20,17 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
 	kubemock "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	metadatafake "k8s.io/client-go/metadata/fake"
 )
 
 func TestFetcher_GetEdgeIngresses(t *testing.T) {
@@ -116,7 +119,7 @@ func TestFetcher_GetEdgeIngresses(t *testing.T) {
 			traefikClient := traefikkubemock.NewSimpleClientset()
 			hubClient := hubkubemock.NewSimpleClientset(objects...)
 
-			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+			f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 			require.NoError(t, err)
 
 			got, err := f.getEdgeIngresses()
@@ -0,0 +1,161 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+)
+
+const (
+	// maxEvents is the maximum number of Events reported in the cluster state, the most recent ones being kept.
+	maxEvents = 100
+	// maxEventMessageLength is the maximum length of the Event messages reported in the cluster state.
+	maxEventMessageLength = 512
+)
+
+// Event summarizes a Warning Event about an object of the cluster.
+type Event struct {
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace,omitempty"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// WatchWarningEvents makes the fetcher report, in the cluster state, the Warning Events about the objects of the
+// namespaces holding Services, Ingresses or IngressRoutes, and about the Hub resources. It must be called before
+// fetching the state.
+func (f *Fetcher) WatchWarningEvents(ctx context.Context) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(f.clientSet, f.resync,
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String()
+		}),
+	)
+	if err := kube.SetTransform(kube.StripObject, factory.Core().V1().Events().Informer()); err != nil {
+		return fmt.Errorf("events informer: %w", err)
+	}
+	if f.informers != nil {
+		if err := f.informers.Track(factory.Core().V1().Events().Informer()); err != nil {
+			return fmt.Errorf("track events informer: %w", err)
+		}
+	}
+
+	factory.Start(ctx.Done())
+	for typ, ok := range factory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("timed out waiting for Event caches to sync %s", typ)
+		}
+	}
+
+	f.events = factory
+
+	return nil
+}
+
+func (f *Fetcher) getEvents(cluster *Cluster) (map[string]*Event, error) {
+	if f.events == nil {
+		return nil, nil
+	}
+
+	events, err := f.events.Core().V1().Events().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make(map[string]struct{})
+	for _, svc := range cluster.Services {
+		namespaces[svc.Namespace] = struct{}{}
+	}
+	for _, ing := range cluster.Ingresses {
+		namespaces[ing.Namespace] = struct{}{}
+	}
+	for _, route := range cluster.IngressRoutes {
+		namespaces[route.Namespace] = struct{}{}
+	}
+
+	var kept []*corev1.Event
+	for _, event := range events {
+		// Events are filtered on their type by the informer, this is only a safeguard.
+		if event.Type != corev1.EventTypeWarning {
+			continue
+		}
+
+		obj := event.InvolvedObject
+
+		_, managedNamespace := namespaces[obj.Namespace]
+		if !managedNamespace && !strings.HasPrefix(obj.APIVersion, "hub.traefik.io/") {
+			continue
+		}
+
+		kept = append(kept, event)
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		return eventLastSeen(kept[i]).After(eventLastSeen(kept[j]))
+	})
+	if len(kept) > maxEvents {
+		kept = kept[:maxEvents]
+	}
+
+	evts := make(map[string]*Event, len(kept))
+	for _, event := range kept {
+		message := event.Message
+		if len(message) > maxEventMessageLength {
+			message = message[:maxEventMessageLength]
+		}
+
+		evts[objectKey(event.Name, event.Namespace)] = &Event{
+			Kind:      event.InvolvedObject.Kind,
+			Name:      event.InvolvedObject.Name,
+			Namespace: event.InvolvedObject.Namespace,
+			Reason:    event.Reason,
+			Message:   message,
+			Count:     event.Count,
+			LastSeen:  eventLastSeen(event).UTC(),
+		}
+	}
+
+	return evts, nil
+}
+
+// eventLastSeen returns the last time the given Event occurred.
+func eventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
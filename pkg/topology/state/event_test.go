@@ -0,0 +1,135 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+func TestFetcher_WatchWarningEvents(t *testing.T) {
+	lastSeen := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	kubeClient := kubemock.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "myns"}},
+		newEvent("pod-failed", "myns", corev1.EventTypeWarning, corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       "whoami-1",
+			Namespace:  "myns",
+		}, strings.Repeat("a", 600), lastSeen),
+		newEvent("pod-started", "myns", corev1.EventTypeNormal, corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       "whoami-1",
+			Namespace:  "myns",
+		}, "Started container", lastSeen),
+		newEvent("other-failed", "other", corev1.EventTypeWarning, corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       "unrelated",
+			Namespace:  "other",
+		}, "Back-off restarting failed container", lastSeen),
+		newEvent("portal-failed", "default", corev1.EventTypeWarning, corev1.ObjectReference{
+			APIVersion: "hub.traefik.io/v1alpha1",
+			Kind:       "APIPortal",
+			Name:       "my-portal",
+		}, "Invalid custom domain", lastSeen.Add(time.Minute)),
+	)
+	traefikClient := traefikkubemock.NewSimpleClientset()
+	hubClient := hubkubemock.NewSimpleClientset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	f, err := NewFetcher(ctx, capability.Capabilities{KubernetesVersion: "v1.20", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
+	require.NoError(t, err)
+
+	err = f.WatchWarningEvents(ctx)
+	require.NoError(t, err)
+
+	got, err := f.FetchState()
+	require.NoError(t, err)
+
+	want := map[string]*Event{
+		"pod-failed@myns": {
+			Kind:      "Pod",
+			Name:      "whoami-1",
+			Namespace: "myns",
+			Reason:    "Failed",
+			Message:   strings.Repeat("a", maxEventMessageLength),
+			Count:     3,
+			LastSeen:  lastSeen,
+		},
+		"portal-failed@default": {
+			Kind:     "APIPortal",
+			Name:     "my-portal",
+			Reason:   "Failed",
+			Message:  "Invalid custom domain",
+			Count:    3,
+			LastSeen: lastSeen.Add(time.Minute),
+		},
+	}
+	assert.Equal(t, want, got.Events)
+}
+
+func TestFetcher_FetchState_eventsNotWatched(t *testing.T) {
+	kubeClient := kubemock.NewSimpleClientset(
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "whoami", Namespace: "myns"}},
+		newEvent("pod-failed", "myns", corev1.EventTypeWarning, corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      "whoami-1",
+			Namespace: "myns",
+		}, "Back-off restarting failed container", time.Now()),
+	)
+	traefikClient := traefikkubemock.NewSimpleClientset()
+	hubClient := hubkubemock.NewSimpleClientset()
+
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
+	require.NoError(t, err)
+
+	got, err := f.FetchState()
+	require.NoError(t, err)
+
+	assert.Empty(t, got.Events)
+}
+
+func newEvent(name, ns, typ string, obj corev1.ObjectReference, message string, lastSeen time.Time) runtime.Object {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: ns},
+		InvolvedObject: obj,
+		Type:           typ,
+		Reason:         "Failed",
+		Message:        message,
+		Count:          3,
+		LastTimestamp:  metav1.NewTime(lastSeen),
+	}
+}
@@ -23,98 +23,116 @@ import (
 	"strings"
 	"time"
 
-	"github.com/hashicorp/go-version"
-	"github.com/rs/zerolog/log"
-	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned"
 	hubinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/informers/externalversions"
 	traefikclientset "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned"
 	traefikinformer "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/informers/externalversions"
-	"github.com/traefik/hub-agent-kubernetes/pkg/kubevers"
-	kerror "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/client-go/discovery"
+	"github.com/traefik/hub-agent-kubernetes/pkg/kube"
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Fetcher fetches Kubernetes resources and converts them into a filtered and simplified state.
 type Fetcher struct {
-	serverVersion string
-
-	k8s       informers.SharedInformerFactory
-	hub       hubinformer.SharedInformerFactory
-	traefik   traefikinformer.SharedInformerFactory
+	// netV1Ingresses reports whether Ingresses are watched through the networking.k8s.io/v1 API.
+	netV1Ingresses bool
+	resync         time.Duration
+
+	k8s     informers.SharedInformerFactory
+	hub     hubinformer.SharedInformerFactory
+	traefik traefikinformer.SharedInformerFactory
+	// metadata only caches the metadata of the objects whose spec and status are never read, like Pods.
+	metadata metadatainformer.SharedInformerFactory
+	// dynamic is only set when the cluster serves OpenShift Routes.
+	dynamic   dynamicinformer.DynamicSharedInformerFactory
 	clientSet clientset.Interface
+	// events is only set when Warning Events are watched.
+	events informers.SharedInformerFactory
+
+	// informers tracks the informers, when not nil.
+	informers *status.InformerTracker
 }
 
-// NewFetcher creates a new Fetcher.
-func NewFetcher(ctx context.Context, clientSet clientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface) (*Fetcher, error) {
-	serverVersion, err := clientSet.Discovery().ServerVersion()
-	if err != nil {
-		return nil, fmt.Errorf("get server version: %w", err)
-	}
+// NewFetcher creates a new Fetcher watching the resources available according to the given cluster capabilities. The
+// dynamic client set is only used to watch OpenShift Routes, and can be nil when the cluster doesn't serve them. The
+// informers are tracked by the given tracker, unless nil.
+func NewFetcher(ctx context.Context, caps capability.Capabilities, clientSet clientset.Interface, metadataClientSet metadata.Interface, dynamicClientSet dynamic.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface, resync time.Duration, tracker *status.InformerTracker) (*Fetcher, error) {
+	kubernetesFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, resync)
 
-	serverSemVer, err := version.NewVersion(serverVersion.GitVersion)
-	if err != nil {
-		return nil, fmt.Errorf("parse server version: %w", err)
-	}
+	// Pods are only listed by labels to get the logs of Services: caching their metadata is enough.
+	metadataFactory := metadatainformer.NewSharedInformerFactory(metadataClientSet, resync)
 
-	if serverSemVer.LessThan(version.Must(version.NewVersion("1.14"))) {
-		return nil, fmt.Errorf("unsupported version: %s", serverSemVer)
+	cached := []cache.SharedIndexInformer{
+		metadataFactory.ForResource(corev1.SchemeGroupVersion.WithResource("pods")).Informer(),
+		kubernetesFactory.Core().V1().Services().Informer(),
 	}
 
-	return watchAll(ctx, clientSet, traefikClientSet, hubClientSet, serverVersion.GitVersion)
-}
-
-func watchAll(ctx context.Context, clientSet clientset.Interface, traefikClientSet traefikclientset.Interface, hubClientSet hubclientset.Interface, serverVersion string) (*Fetcher, error) {
-	kubernetesFactory := informers.NewSharedInformerFactoryWithOptions(clientSet, 5*time.Minute)
-
-	kubernetesFactory.Core().V1().Pods().Informer()
-	kubernetesFactory.Core().V1().Services().Informer()
-
-	if kubevers.SupportsNetV1IngressClasses(serverVersion) {
-		kubernetesFactory.Networking().V1().IngressClasses().Informer()
-	} else if kubevers.SupportsNetV1Beta1IngressClasses(serverVersion) {
-		kubernetesFactory.Networking().V1beta1().IngressClasses().Informer()
+	if caps.NetV1IngressClasses {
+		cached = append(cached, kubernetesFactory.Networking().V1().IngressClasses().Informer())
+	} else if caps.NetV1Beta1IngressClasses {
+		cached = append(cached, kubernetesFactory.Networking().V1beta1().IngressClasses().Informer())
 	}
 
-	if kubevers.SupportsNetV1Ingresses(serverVersion) {
-		kubernetesFactory.Networking().V1().Ingresses().Informer()
+	if caps.NetV1Ingresses {
+		cached = append(cached, kubernetesFactory.Networking().V1().Ingresses().Informer())
 	} else {
 		// Since we only support Kubernetes v1.14 and up, we always have at least net v1beta1 Ingresses.
-		kubernetesFactory.Networking().V1beta1().Ingresses().Informer()
+		cached = append(cached, kubernetesFactory.Networking().V1beta1().Ingresses().Informer())
 	}
 
-	traefikFactory := traefikinformer.NewSharedInformerFactoryWithOptions(traefikClientSet, 5*time.Minute)
+	traefikFactory := traefikinformer.NewSharedInformerFactoryWithOptions(traefikClientSet, resync)
 
-	hasTraefikCRDs, err := hasTraefikCRDs(clientSet.Discovery())
-	if err != nil {
-		return nil, fmt.Errorf("check presence of Traefik IngressRoute, TraefikService and TLSOption CRD: %w", err)
+	if caps.TraefikRoutes {
+		cached = append(cached,
+			traefikFactory.Traefik().V1alpha1().IngressRoutes().Informer(),
+			traefikFactory.Traefik().V1alpha1().TraefikServices().Informer(),
+		)
 	}
 
-	if hasTraefikCRDs {
-		traefikFactory.Traefik().V1alpha1().IngressRoutes().Informer()
-		traefikFactory.Traefik().V1alpha1().TraefikServices().Informer()
-	} else {
-		msg := "The agent has been installed in a cluster where the Traefik Proxy CustomResourceDefinitions are not installed. " +
-			"If you want to install these CustomResourceDefinitions and take advantage of them in Traefik Hub, " +
-			"the agent needs to be restarted in order to load them. " +
-			"Run 'kubectl -n hub-agent delete pod -l app=hub-agent,component=controller'"
-		log.Info().Msg(msg)
+	// OpenShift Routes are watched as unstructured objects as the agent doesn't depend on the OpenShift client.
+	var dynamicFactory dynamicinformer.DynamicSharedInformerFactory
+	if caps.OpenShiftRoutes {
+		dynamicFactory = dynamicinformer.NewDynamicSharedInformerFactory(dynamicClientSet, resync)
+		cached = append(cached, dynamicFactory.ForResource(openshift.RouteResource).Informer())
+	}
+
+	hubFactory := hubinformer.NewSharedInformerFactoryWithOptions(hubClientSet, resync)
+	cached = append(cached,
+		hubFactory.Hub().V1alpha1().AccessControlPolicies().Informer(),
+		hubFactory.Hub().V1alpha1().EdgeIngresses().Informer(),
+		hubFactory.Hub().V1alpha1().APIs().Informer(),
+		hubFactory.Hub().V1alpha1().APIAccesses().Informer(),
+		hubFactory.Hub().V1alpha1().APICollections().Informer(),
+		hubFactory.Hub().V1alpha1().APIPortals().Informer(),
+		hubFactory.Hub().V1alpha1().APIGateways().Informer(),
+	)
+
+	if err := kube.SetTransform(kube.StripObject, cached...); err != nil {
+		return nil, fmt.Errorf("informers: %w", err)
 	}
 
-	hubFactory := hubinformer.NewSharedInformerFactoryWithOptions(hubClientSet, 5*time.Minute)
-	hubFactory.Hub().V1alpha1().AccessControlPolicies().Informer()
-	hubFactory.Hub().V1alpha1().EdgeIngresses().Informer()
-	hubFactory.Hub().V1alpha1().APIs().Informer()
-	hubFactory.Hub().V1alpha1().APIAccesses().Informer()
-	hubFactory.Hub().V1alpha1().APICollections().Informer()
-	hubFactory.Hub().V1alpha1().APIPortals().Informer()
-	hubFactory.Hub().V1alpha1().APIGateways().Informer()
+	if tracker != nil {
+		if err := tracker.Track(cached...); err != nil {
+			return nil, fmt.Errorf("track informers: %w", err)
+		}
+	}
 
 	kubernetesFactory.Start(ctx.Done())
+	metadataFactory.Start(ctx.Done())
 	hubFactory.Start(ctx.Done())
 	traefikFactory.Start(ctx.Done())
+	if dynamicFactory != nil {
+		dynamicFactory.Start(ctx.Done())
+	}
 
 	for typ, ok := range kubernetesFactory.WaitForCacheSync(ctx.Done()) {
 		if !ok {
@@ -122,6 +140,12 @@ func watchAll(ctx context.Context, clientSet clientset.Interface, traefikClientS
 		}
 	}
 
+	for gvr, ok := range metadataFactory.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return nil, fmt.Errorf("timed out waiting for k8s object metadata caches to sync %s", gvr)
+		}
+	}
+
 	for typ, ok := range hubFactory.WaitForCacheSync(ctx.Done()) {
 		if !ok {
 			return nil, fmt.Errorf("timed out waiting for Traefik Hub CRD caches to sync %s", typ)
@@ -134,12 +158,24 @@ func watchAll(ctx context.Context, clientSet clientset.Interface, traefikClientS
 		}
 	}
 
+	if dynamicFactory != nil {
+		for gvr, ok := range dynamicFactory.WaitForCacheSync(ctx.Done()) {
+			if !ok {
+				return nil, fmt.Errorf("timed out waiting for OpenShift caches to sync %s", gvr)
+			}
+		}
+	}
+
 	return &Fetcher{
-		serverVersion: serverVersion,
-		k8s:           kubernetesFactory,
-		hub:           hubFactory,
-		traefik:       traefikFactory,
-		clientSet:     clientSet,
+		netV1Ingresses: caps.NetV1Ingresses,
+		resync:         resync,
+		k8s:            kubernetesFactory,
+		hub:            hubFactory,
+		traefik:        traefikFactory,
+		metadata:       metadataFactory,
+		dynamic:        dynamicFactory,
+		clientSet:      clientSet,
+		informers:      tracker,
 	}, nil
 }
 
@@ -164,6 +200,11 @@ func (f *Fetcher) FetchState() (*Cluster, error) {
 		return nil, err
 	}
 
+	cluster.OpenShiftRoutes, err = f.getOpenShiftRoutes()
+	if err != nil {
+		return nil, err
+	}
+
 	cluster.AccessControlPolicies, err = f.getAccessControlPolicies()
 	if err != nil {
 		return nil, err
@@ -199,35 +240,12 @@ func (f *Fetcher) FetchState() (*Cluster, error) {
 		return nil, err
 	}
 
-	return &cluster, nil
-}
-
-func hasTraefikCRDs(clientSet discovery.DiscoveryInterface) (bool, error) {
-	crdList, err := clientSet.ServerResourcesForGroupVersion(traefikv1alpha1.SchemeGroupVersion.String())
+	cluster.Events, err = f.getEvents(&cluster)
 	if err != nil {
-		if kerror.IsNotFound(err) ||
-			// because the fake client doesn't return the right error type.
-			strings.HasSuffix(err.Error(), " not found") {
-			return false, nil
-		}
-		return false, err
-	}
-
-	for _, kind := range []string{ResourceKindIngressRoute, ResourceKindTraefikService, ResourceKindTLSOption} {
-		var exists bool
-		for _, resource := range crdList.APIResources {
-			if resource.Kind == kind {
-				exists = true
-				break
-			}
-		}
-
-		if !exists {
-			return false, nil
-		}
+		return nil, err
 	}
 
-	return true, nil
+	return &cluster, nil
 }
 
 func objectKey(name, ns string) string {
@@ -20,11 +20,14 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
 	netv1 "k8s.io/api/networking/v1"
 	netv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,55 +35,9 @@ import (
 	"k8s.io/apimachinery/pkg/version"
 	fakediscovery "k8s.io/client-go/discovery/fake"
 	kubemock "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
 )
 
-func TestNewFetcher_handlesUnsupportedVersions(t *testing.T) {
-	tests := []struct {
-		desc          string
-		serverVersion string
-		wantErr       assert.ErrorAssertionFunc
-	}{
-		{
-			desc:    "Empty",
-			wantErr: assert.Error,
-		},
-		{
-			desc:          "Malformed version",
-			serverVersion: "foobar",
-			wantErr:       assert.Error,
-		},
-		{
-			desc:          "Unsupported version",
-			serverVersion: "v1.13",
-			wantErr:       assert.Error,
-		},
-		{
-			desc:          "Supported version",
-			serverVersion: "v1.16",
-			wantErr:       assert.NoError,
-		},
-	}
-
-	for _, test := range tests {
-		test := test
-		t.Run(test.desc, func(t *testing.T) {
-			t.Parallel()
-
-			kubeClient := kubemock.NewSimpleClientset()
-			traefikClient := traefikkubemock.NewSimpleClientset()
-			hubClient := hubkubemock.NewSimpleClientset()
-
-			fakeDiscovery, ok := kubeClient.Discovery().(*fakediscovery.FakeDiscovery)
-			require.True(t, ok, "couldn't convert Discovery() to *FakeDiscovery")
-
-			fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: test.serverVersion}
-
-			_, err := NewFetcher(context.Background(), kubeClient, traefikClient, hubClient)
-			test.wantErr(t, err)
-		})
-	}
-}
-
 func TestNewFetcher_handlesAllIngressAPIVersions(t *testing.T) {
 	tests := []struct {
 		desc          string
@@ -161,6 +118,34 @@ func TestNewFetcher_handlesAllIngressAPIVersions(t *testing.T) {
 					IngressMeta: IngressMeta{},
 				},
 			},
+		}, {
+			desc:          "v1.25.3-eks-4a1f2b3",
+			serverVersion: "v1.25.3-eks-4a1f2b3",
+			want: map[string]*Ingress{
+				"myIngress_netv1@myns.ingress.networking.k8s.io": {
+					ResourceMeta: ResourceMeta{
+						Kind:      "Ingress",
+						Group:     "networking.k8s.io",
+						Name:      "myIngress_netv1",
+						Namespace: "myns",
+					},
+					IngressMeta: IngressMeta{},
+				},
+			},
+		}, {
+			desc:          "v1.29.1+k3s2",
+			serverVersion: "v1.29.1+k3s2",
+			want: map[string]*Ingress{
+				"myIngress_netv1@myns.ingress.networking.k8s.io": {
+					ResourceMeta: ResourceMeta{
+						Kind:      "Ingress",
+						Group:     "networking.k8s.io",
+						Name:      "myIngress_netv1",
+						Namespace: "myns",
+					},
+					IngressMeta: IngressMeta{},
+				},
+			},
 		},
 	}
 
@@ -193,7 +178,10 @@ func TestNewFetcher_handlesAllIngressAPIVersions(t *testing.T) {
 
 			fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: test.serverVersion}
 
-			f, err := NewFetcher(context.Background(), kubeClient, traefikClient, hubClient)
+			caps, err := capability.Probe(context.Background(), kubeClient)
+			require.NoError(t, err)
+
+			f, err := NewFetcher(context.Background(), caps, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 			require.NoError(t, err)
 
 			got, err := f.getIngresses()
@@ -203,3 +191,25 @@ func TestNewFetcher_handlesAllIngressAPIVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestNewFetcher_tracksInformers(t *testing.T) {
+	kubeClient := kubemock.NewSimpleClientset()
+	traefikClient := traefikkubemock.NewSimpleClientset()
+	hubClient := hubkubemock.NewSimpleClientset()
+
+	tracker := status.NewInformerTracker()
+
+	caps := capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}
+	f, err := NewFetcher(context.Background(), caps, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, tracker)
+	require.NoError(t, err)
+
+	report := tracker.Check(context.Background())
+	assert.Equal(t, status.StateOK, report.State)
+	assert.Equal(t, "11", report.Details["informers"])
+
+	err = f.WatchWarningEvents(context.Background())
+	require.NoError(t, err)
+
+	report = tracker.Check(context.Background())
+	assert.Equal(t, "12", report.Details["informers"])
+}
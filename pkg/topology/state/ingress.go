@@ -57,9 +57,8 @@ func (f *Fetcher) getIngresses() (map[string]*Ingress, error) {
 }
 
 func (f *Fetcher) fetchIngresses() ([]*netv1.Ingress, error) {
-	ingresses, err := f.k8s.Networking().V1().Ingresses().Lister().List(labels.Everything())
-	if err != nil {
-		return nil, err
+	if f.netV1Ingresses {
+		return f.k8s.Networking().V1().Ingresses().Lister().List(labels.Everything())
 	}
 
 	v1beta1Ingresses, err := f.k8s.Networking().V1beta1().Ingresses().Lister().List(labels.Everything())
@@ -67,6 +66,7 @@ func (f *Fetcher) fetchIngresses() ([]*netv1.Ingress, error) {
 		return nil, err
 	}
 
+	var ingresses []*netv1.Ingress
 	for _, ingress := range v1beta1Ingresses {
 		ing, err := toNetworkingV1(ingress)
 		if err != nil {
@@ -20,16 +20,18 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/hub/v1alpha1"
 	traefikv1alpha1 "github.com/traefik/hub-agent-kubernetes/pkg/crd/api/traefik/v1alpha1"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubemock "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	metadatafake "k8s.io/client-go/metadata/fake"
 )
 
 // Mandatory to be able to parse traefik.containo.us/v1alpha1 resources.
@@ -270,20 +272,11 @@ func TestFetcher_GetIngressRoutes(t *testing.T) {
 			objects := loadK8sObjects(t, "fixtures/ingress-route/"+test.fixture)
 
 			kubeClient := kubemock.NewSimpleClientset()
-			// Faking having Traefik CRDs installed on cluster.
-			kubeClient.Resources = append(kubeClient.Resources, &metav1.APIResourceList{
-				GroupVersion: traefikv1alpha1.SchemeGroupVersion.String(),
-				APIResources: []metav1.APIResource{
-					{Kind: ResourceKindIngressRoute},
-					{Kind: ResourceKindTraefikService},
-					{Kind: ResourceKindTLSOption},
-				},
-			})
 
 			traefikClient := traefikkubemock.NewSimpleClientset(objects...)
 			hubClient := hubkubemock.NewSimpleClientset()
 
-			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+			f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true, TraefikRoutes: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 			require.NoError(t, err)
 
 			got, err := f.getIngressRoutes()
@@ -22,9 +22,11 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
 	netv1 "k8s.io/api/networking/v1"
@@ -32,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	kubemock "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	metadatafake "k8s.io/client-go/metadata/fake"
 )
 
 func TestFetcher_GetIngresses(t *testing.T) {
@@ -91,7 +94,7 @@ func TestFetcher_GetIngresses(t *testing.T) {
 	traefikClient := traefikkubemock.NewSimpleClientset()
 	hubClient := hubkubemock.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.getIngresses()
@@ -172,7 +175,7 @@ func TestFetcher_FetchIngresses(t *testing.T) {
 	traefikClient := traefikkubemock.NewSimpleClientset()
 	hubClient := hubkubemock.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.18")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.18", NetV1Beta1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.fetchIngresses()
@@ -0,0 +1,70 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func (f *Fetcher) getOpenShiftRoutes() (map[string]*OpenShiftRoute, error) {
+	if f.dynamic == nil {
+		return nil, nil
+	}
+
+	objects, err := f.dynamic.ForResource(openshift.RouteResource).Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*OpenShiftRoute)
+	for _, obj := range objects {
+		route, err := openshift.FromUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+
+		var services []string
+		for _, name := range route.Services() {
+			services = append(services, objectKey(name, route.Namespace))
+		}
+
+		r := &OpenShiftRoute{
+			ResourceMeta: ResourceMeta{
+				Kind:      openshift.RouteKind.Kind,
+				Group:     openshift.GroupName,
+				Name:      route.Name,
+				Namespace: route.Namespace,
+			},
+			IngressMeta: IngressMeta{
+				Annotations: sanitizeAnnotations(route.Annotations),
+				Labels:      route.Labels,
+			},
+			Host:     route.Spec.Host,
+			Path:     route.Spec.Path,
+			Services: services,
+		}
+		if route.Spec.TLS != nil {
+			r.Termination = route.Spec.TLS.Termination
+		}
+
+		result[ingressKey(r.ResourceMeta)] = r
+	}
+
+	return result, nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package state
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
+	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
+	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
+	"github.com/traefik/hub-agent-kubernetes/pkg/openshift"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+func TestFetcher_GetOpenShiftRoutes(t *testing.T) {
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "route.openshift.io/v1",
+		"kind":       "Route",
+		"metadata": map[string]interface{}{
+			"name":      "whoami",
+			"namespace": "myns",
+			"annotations": map[string]interface{}{
+				"hub.traefik.io/access-control-policy":             "my-acp",
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			},
+			"labels": map[string]interface{}{"app": "whoami"},
+		},
+		"spec": map[string]interface{}{
+			"host": "whoami.apps.example.com",
+			"path": "/api",
+			"to":   map[string]interface{}{"kind": "Service", "name": "whoami", "weight": int64(80)},
+			"alternateBackends": []interface{}{
+				map[string]interface{}{"kind": "Service", "name": "whoami-canary", "weight": int64(20)},
+			},
+			"port": map[string]interface{}{"targetPort": "http"},
+			"tls": map[string]interface{}{
+				"termination": "edge",
+				"key":         "private-key",
+				"certificate": "certificate",
+			},
+		},
+	}}
+
+	kubeClient := kubemock.NewSimpleClientset()
+	traefikClient := traefikkubemock.NewSimpleClientset()
+	hubClient := hubkubemock.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{openshift.RouteResource: "RouteList"},
+		route,
+	)
+
+	caps := capability.Capabilities{KubernetesVersion: "v1.25.0", NetV1Ingresses: true, NetV1IngressClasses: true, OpenShiftRoutes: true}
+	f, err := NewFetcher(context.Background(), caps, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), dynamicClient, traefikClient, hubClient, 5*time.Minute, nil)
+	require.NoError(t, err)
+
+	got, err := f.getOpenShiftRoutes()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]*OpenShiftRoute{
+		"whoami@myns.route.route.openshift.io": {
+			ResourceMeta: ResourceMeta{
+				Kind:      "Route",
+				Group:     "route.openshift.io",
+				Name:      "whoami",
+				Namespace: "myns",
+			},
+			IngressMeta: IngressMeta{
+				Annotations: map[string]string{"hub.traefik.io/access-control-policy": "my-acp"},
+				Labels:      map[string]string{"app": "whoami"},
+			},
+			Host:        "whoami.apps.example.com",
+			Path:        "/api",
+			Termination: "edge",
+			Services:    []string{"whoami@myns", "whoami-canary@myns"},
+		},
+	}, got)
+}
+
+func TestFetcher_GetOpenShiftRoutes_notServed(t *testing.T) {
+	kubeClient := kubemock.NewSimpleClientset()
+	traefikClient := traefikkubemock.NewSimpleClientset()
+	hubClient := hubkubemock.NewSimpleClientset()
+
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.25.0", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
+	require.NoError(t, err)
+
+	got, err := f.getOpenShiftRoutes()
+	require.NoError(t, err)
+
+	assert.Nil(t, got)
+}
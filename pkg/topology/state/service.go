@@ -27,6 +27,7 @@ import (
 	"sort"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
@@ -81,7 +82,7 @@ func (f *Fetcher) GetServiceLogs(ctx context.Context, namespace, name string, li
 		return nil, fmt.Errorf("invalid service %s/%s: %w", name, namespace, err)
 	}
 
-	pods, err := f.k8s.Core().V1().Pods().Lister().Pods(namespace).List(labels.SelectorFromSet(service.Spec.Selector))
+	pods, err := f.metadata.ForResource(corev1.SchemeGroupVersion.WithResource("pods")).Lister().ByNamespace(namespace).List(labels.SelectorFromSet(service.Spec.Selector))
 	if err != nil {
 		return nil, fmt.Errorf("list pods for %s/%s: %w", namespace, name, err)
 	}
@@ -96,7 +97,12 @@ func (f *Fetcher) GetServiceLogs(ctx context.Context, namespace, name string, li
 	buf := bytes.NewBuffer(make([]byte, 0, maxLen*lines))
 	podLogOpts := corev1.PodLogOptions{Previous: false, TailLines: int64Ptr(int64(lines / len(pods)))}
 	for _, pod := range pods {
-		req := f.clientSet.CoreV1().Pods(service.Namespace).GetLogs(pod.Name, &podLogOpts)
+		podMeta, ok := pod.(*metav1.PartialObjectMetadata)
+		if !ok {
+			return nil, fmt.Errorf("unexpected pod object %T", pod)
+		}
+
+		req := f.clientSet.CoreV1().Pods(service.Namespace).GetLogs(podMeta.Name, &podLogOpts)
 		podLogs, logErr := req.Stream(ctx)
 		if logErr != nil {
 			return nil, fmt.Errorf("opening pod log stream: %w", logErr)
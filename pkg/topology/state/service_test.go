@@ -20,9 +20,11 @@ package state
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/traefik/hub-agent-kubernetes/pkg/capability"
 	hubkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/hub/clientset/versioned/fake"
 	traefikkubemock "github.com/traefik/hub-agent-kubernetes/pkg/crd/generated/client/traefik/clientset/versioned/fake"
 	corev1 "k8s.io/api/core/v1"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	kubemock "k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
 	kubetesting "k8s.io/client-go/testing"
 )
 
@@ -90,7 +93,7 @@ func TestFetcher_GetServices(t *testing.T) {
 	traefikClient := traefikkubemock.NewSimpleClientset()
 	hubClient := hubkubemock.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	gotSvcs, err := f.getServices()
@@ -160,7 +163,7 @@ func TestFetcher_GetServicesWithExternalIPs(t *testing.T) {
 	traefikClient := traefikkubemock.NewSimpleClientset()
 	hubClient := hubkubemock.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	gotSvcs, err := f.getServices()
@@ -325,7 +328,7 @@ func TestFetcher_GetServicesWithOpenAPISpecs(t *testing.T) {
 			traefikClient := traefikkubemock.NewSimpleClientset()
 			hubClient := hubkubemock.NewSimpleClientset()
 
-			f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+			f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme()), nil, traefikClient, hubClient, 5*time.Minute, nil)
 			require.NoError(t, err)
 
 			gotSvcs, err := f.getServices()
@@ -372,7 +375,11 @@ func TestFetcher_GetServiceLogs(t *testing.T) {
 				},
 			},
 		},
-		&corev1.Pod{
+	}
+
+	pods := []runtime.Object{
+		&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "pod1",
 				Namespace: "myns",
@@ -381,7 +388,8 @@ func TestFetcher_GetServiceLogs(t *testing.T) {
 				},
 			},
 		},
-		&corev1.Pod{
+		&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "pod2",
 				Namespace: "myns",
@@ -403,7 +411,7 @@ func TestFetcher_GetServiceLogs(t *testing.T) {
 	traefikClient := traefikkubemock.NewSimpleClientset()
 	hubClient := hubkubemock.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, newMetadataClientSet(t, pods...), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.GetServiceLogs(context.Background(), "myns", "myService", 20, 200)
@@ -448,7 +456,11 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 				},
 			},
 		},
-		&corev1.Pod{
+	}
+
+	pods := []runtime.Object{
+		&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "pod1",
 				Namespace: "myns",
@@ -457,7 +469,8 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 				},
 			},
 		},
-		&corev1.Pod{
+		&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "pod2",
 				Namespace: "myns",
@@ -466,7 +479,8 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 				},
 			},
 		},
-		&corev1.Pod{
+		&metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "pod3",
 				Namespace: "myns",
@@ -488,7 +502,7 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 	traefikClient := traefikkubemock.NewSimpleClientset()
 	hubClient := hubkubemock.NewSimpleClientset()
 
-	f, err := watchAll(context.Background(), kubeClient, traefikClient, hubClient, "v1.20.1")
+	f, err := NewFetcher(context.Background(), capability.Capabilities{KubernetesVersion: "v1.20.1", NetV1Ingresses: true, NetV1IngressClasses: true}, kubeClient, newMetadataClientSet(t, pods...), nil, traefikClient, hubClient, 5*time.Minute, nil)
 	require.NoError(t, err)
 
 	got, err := f.GetServiceLogs(context.Background(), "myns", "myService", 2, 200)
@@ -496,3 +510,12 @@ func TestFetcher_GetServiceLogsHandlesTooManyPods(t *testing.T) {
 
 	assert.Equal(t, []byte("fake logs\nfake logs\n"), got)
 }
+
+func newMetadataClientSet(t *testing.T, objects ...runtime.Object) *metadatafake.FakeMetadataClient {
+	t.Helper()
+
+	scheme := metadatafake.NewTestScheme()
+	require.NoError(t, metav1.AddMetaToScheme(scheme))
+
+	return metadatafake.NewSimpleMetadataClient(scheme, objects...)
+}
@@ -118,6 +118,10 @@ func (_c *platformClientFetchTopologyCall) OnPatchTopology(patch []byte, lastKno
 	return _c.Parent.OnPatchTopology(patch, lastKnownVersion)
 }
 
+func (_c *platformClientFetchTopologyCall) OnCheckTopology(checksum string, lastKnownVersion int64) *platformClientCheckTopologyCall {
+	return _c.Parent.OnCheckTopology(checksum, lastKnownVersion)
+}
+
 func (_c *platformClientFetchTopologyCall) OnFetchTopologyRaw() *platformClientFetchTopologyCall {
 	return _c.Parent.OnFetchTopologyRaw()
 }
@@ -126,6 +130,10 @@ func (_c *platformClientFetchTopologyCall) OnPatchTopologyRaw(patch interface{},
 	return _c.Parent.OnPatchTopologyRaw(patch, lastKnownVersion)
 }
 
+func (_c *platformClientFetchTopologyCall) OnCheckTopologyRaw(checksum interface{}, lastKnownVersion interface{}) *platformClientCheckTopologyCall {
+	return _c.Parent.OnCheckTopologyRaw(checksum, lastKnownVersion)
+}
+
 func (_m *platformClientMock) PatchTopology(_ context.Context, patch []byte, lastKnownVersion int64) (int64, error) {
 	_ret := _m.Called(patch, lastKnownVersion)
 
@@ -219,6 +227,10 @@ func (_c *platformClientPatchTopologyCall) OnPatchTopology(patch []byte, lastKno
 	return _c.Parent.OnPatchTopology(patch, lastKnownVersion)
 }
 
+func (_c *platformClientPatchTopologyCall) OnCheckTopology(checksum string, lastKnownVersion int64) *platformClientCheckTopologyCall {
+	return _c.Parent.OnCheckTopology(checksum, lastKnownVersion)
+}
+
 func (_c *platformClientPatchTopologyCall) OnFetchTopologyRaw() *platformClientFetchTopologyCall {
 	return _c.Parent.OnFetchTopologyRaw()
 }
@@ -226,3 +238,116 @@ func (_c *platformClientPatchTopologyCall) OnFetchTopologyRaw() *platformClientF
 func (_c *platformClientPatchTopologyCall) OnPatchTopologyRaw(patch interface{}, lastKnownVersion interface{}) *platformClientPatchTopologyCall {
 	return _c.Parent.OnPatchTopologyRaw(patch, lastKnownVersion)
 }
+
+func (_c *platformClientPatchTopologyCall) OnCheckTopologyRaw(checksum interface{}, lastKnownVersion interface{}) *platformClientCheckTopologyCall {
+	return _c.Parent.OnCheckTopologyRaw(checksum, lastKnownVersion)
+}
+
+func (_m *platformClientMock) CheckTopology(_ context.Context, checksum string, lastKnownVersion int64) (bool, error) {
+	_ret := _m.Called(checksum, lastKnownVersion)
+
+	if _rf, ok := _ret.Get(0).(func(string, int64) (bool, error)); ok {
+		return _rf(checksum, lastKnownVersion)
+	}
+
+	_ra0, _ := _ret.Get(0).(bool)
+	_rb1 := _ret.Error(1)
+
+	return _ra0, _rb1
+}
+
+func (_m *platformClientMock) OnCheckTopology(checksum string, lastKnownVersion int64) *platformClientCheckTopologyCall {
+	return &platformClientCheckTopologyCall{Call: _m.Mock.On("CheckTopology", checksum, lastKnownVersion), Parent: _m}
+}
+
+func (_m *platformClientMock) OnCheckTopologyRaw(checksum interface{}, lastKnownVersion interface{}) *platformClientCheckTopologyCall {
+	return &platformClientCheckTopologyCall{Call: _m.Mock.On("CheckTopology", checksum, lastKnownVersion), Parent: _m}
+}
+
+type platformClientCheckTopologyCall struct {
+	*mock.Call
+	Parent *platformClientMock
+}
+
+func (_c *platformClientCheckTopologyCall) Panic(msg string) *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Panic(msg)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) Once() *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Once()
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) Twice() *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Twice()
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) Times(i int) *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Times(i)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) WaitUntil(w <-chan time.Time) *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.WaitUntil(w)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) After(d time.Duration) *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.After(d)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) Run(fn func(args mock.Arguments)) *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Run(fn)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) Maybe() *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Maybe()
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) TypedReturns(a bool, b error) *platformClientCheckTopologyCall {
+	_c.Call = _c.Return(a, b)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) ReturnsFn(fn func(string, int64) (bool, error)) *platformClientCheckTopologyCall {
+	_c.Call = _c.Return(fn)
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) TypedRun(fn func(string, int64)) *platformClientCheckTopologyCall {
+	_c.Call = _c.Call.Run(func(args mock.Arguments) {
+		_checksum, _ := args.Get(0).(string)
+		_lastKnownVersion, _ := args.Get(1).(int64)
+		fn(_checksum, _lastKnownVersion)
+	})
+	return _c
+}
+
+func (_c *platformClientCheckTopologyCall) OnFetchTopology() *platformClientFetchTopologyCall {
+	return _c.Parent.OnFetchTopology()
+}
+
+func (_c *platformClientCheckTopologyCall) OnPatchTopology(patch []byte, lastKnownVersion int64) *platformClientPatchTopologyCall {
+	return _c.Parent.OnPatchTopology(patch, lastKnownVersion)
+}
+
+func (_c *platformClientCheckTopologyCall) OnCheckTopology(checksum string, lastKnownVersion int64) *platformClientCheckTopologyCall {
+	return _c.Parent.OnCheckTopology(checksum, lastKnownVersion)
+}
+
+func (_c *platformClientCheckTopologyCall) OnFetchTopologyRaw() *platformClientFetchTopologyCall {
+	return _c.Parent.OnFetchTopologyRaw()
+}
+
+func (_c *platformClientCheckTopologyCall) OnPatchTopologyRaw(patch interface{}, lastKnownVersion interface{}) *platformClientPatchTopologyCall {
+	return _c.Parent.OnPatchTopologyRaw(patch, lastKnownVersion)
+}
+
+func (_c *platformClientCheckTopologyCall) OnCheckTopologyRaw(checksum interface{}, lastKnownVersion interface{}) *platformClientCheckTopologyCall {
+	return _c.Parent.OnCheckTopologyRaw(checksum, lastKnownVersion)
+}
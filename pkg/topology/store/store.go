@@ -20,6 +20,8 @@ package store
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -35,6 +37,7 @@ import (
 type PlatformClient interface {
 	FetchTopology(ctx context.Context) (topology state.Cluster, version int64, err error)
 	PatchTopology(ctx context.Context, patch []byte, lastKnownVersion int64) (int64, error)
+	CheckTopology(ctx context.Context, checksum string, lastKnownVersion int64) (bool, error)
 }
 
 // Store stores the topology on the platform.
@@ -101,6 +104,30 @@ func (s *Store) Write(ctx context.Context, st state.Cluster) error {
 	}
 }
 
+// Check sends the checksum of the topology last written to the platform, which asks for a resynchronization when it
+// holds a different one. The topology of the platform is then fetched again on the next write, for the patch to be
+// computed against it.
+func (s *Store) Check(ctx context.Context) error {
+	if s.lastKnownVersion == 0 {
+		return nil
+	}
+
+	sum := sha256.Sum256(s.lastTopology)
+	resync, err := s.platform.CheckTopology(ctx, hex.EncodeToString(sum[:]), s.lastKnownVersion)
+	if err != nil {
+		return fmt.Errorf("check topology: %w", err)
+	}
+
+	if resync {
+		log.Ctx(ctx).Info().Int64("version", s.lastKnownVersion).Msg("Topology diverged from the platform, resynchronizing")
+
+		s.lastKnownVersion = 0
+		s.lastTopology = nil
+	}
+
+	return nil
+}
+
 func (s *Store) buildPatch(lastTopology []byte, st state.Cluster) ([]byte, []byte, error) {
 	newTopology, err := json.Marshal(st)
 	if err != nil {
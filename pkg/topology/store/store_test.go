@@ -19,6 +19,8 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -631,3 +633,56 @@ func removeSpaces(s string) string {
 
 	return s
 }
+
+func TestStore_Check(t *testing.T) {
+	topology, err := json.Marshal(state.Cluster{})
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(topology)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		desc        string
+		resync      bool
+		wantVersion int64
+	}{
+		{
+			desc:        "same topology",
+			wantVersion: 1,
+		},
+		{
+			desc:        "diverged topology",
+			resync:      true,
+			wantVersion: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			platformClient := newPlatformClientMock(t).
+				OnCheckTopology(checksum, 1).
+				TypedReturns(test.resync, nil).
+				Once().
+				Parent
+
+			s := New(platformClient)
+			s.lastKnownVersion = 1
+			s.lastTopology = topology
+
+			err := s.Check(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, test.wantVersion, s.lastKnownVersion)
+		})
+	}
+}
+
+func TestStore_Check_notSynchronized(t *testing.T) {
+	s := New(newPlatformClientMock(t))
+
+	err := s.Check(context.Background())
+	require.NoError(t, err)
+}
@@ -22,11 +22,18 @@ import (
 	"sync"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
+	"github.com/traefik/hub-agent-kubernetes/pkg/status"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/state"
 	"github.com/traefik/hub-agent-kubernetes/pkg/topology/store"
 )
 
+// checkInterval is the interval at which the checksum of the topology is checked against the platform.
+const checkInterval = 5 * time.Minute
+
+// pushMaxAge is the time after which the topology push is reported as down if it didn't succeed.
+const pushMaxAge = 2 * time.Minute
+
 // ListenerFunc is a function called by the watcher with the
 // current state.
 type ListenerFunc func(ctx context.Context, state *state.Cluster)
@@ -39,16 +46,26 @@ type Watcher struct {
 
 	listenersMu sync.Mutex
 	listeners   []ListenerFunc
+
+	tracker *status.Tracker
+	logger  zerolog.Logger
 }
 
 // NewWatcher instantiates a new watcher that uses a fetcher to periodically get the K8S state and a store to write it.
-func NewWatcher(f *state.Fetcher, s *store.Store) *Watcher {
+func NewWatcher(f *state.Fetcher, s *store.Store, logger zerolog.Logger) *Watcher {
 	return &Watcher{
-		k8s:   f,
-		store: s,
+		k8s:     f,
+		store:   s,
+		tracker: status.NewTracker(),
+		logger:  logger,
 	}
 }
 
+// Status reports the topology push as down when the topology couldn't be written to the platform for pushMaxAge.
+func (w *Watcher) Status(_ context.Context) status.Report {
+	return w.tracker.Report(pushMaxAge)
+}
+
 // AddListener adds a state listener.
 func (w *Watcher) AddListener(listener ListenerFunc) {
 	w.listenersMu.Lock()
@@ -62,15 +79,18 @@ func (w *Watcher) Start(ctx context.Context) {
 	tick := time.NewTicker(5 * time.Second)
 	defer tick.Stop()
 
+	check := time.NewTicker(checkInterval)
+	defer check.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
-			log.Info().Msg("Stopping topology watcher")
+			w.logger.Info().Msg("Stopping topology watcher")
 			return
 		case <-tick.C:
 			s, err := w.k8s.FetchState()
 			if err != nil {
-				log.Error().Err(err).Msg("create state")
+				w.logger.Error().Err(err).Msg("create state")
 				continue
 			}
 			if s == nil {
@@ -83,8 +103,14 @@ func (w *Watcher) Start(ctx context.Context) {
 			}
 			w.listenersMu.Unlock()
 
-			if err = w.store.Write(ctx, *s); err != nil {
-				log.Error().Err(err).Msg("commit cluster state changes")
+			err = w.store.Write(ctx, *s)
+			w.tracker.Record(err)
+			if err != nil {
+				w.logger.Error().Err(err).Msg("commit cluster state changes")
+			}
+		case <-check.C:
+			if err := w.store.Check(ctx); err != nil {
+				w.logger.Error().Err(err).Msg("check cluster state checksum")
 			}
 		}
 	}
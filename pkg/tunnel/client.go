@@ -31,16 +31,21 @@ import (
 	"github.com/traefik/hub-agent-kubernetes/pkg/version"
 )
 
+// TokenSource provides the token authenticating the agent on the tunnel service and on the brokers.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
 // Client allows interacting with the tunnel service.
 type Client struct {
 	baseURL *url.URL
-	token   string
+	tokens  TokenSource
 
 	httpClient *http.Client
 }
 
 // NewClient creates a new client for the tunnel service.
-func NewClient(baseURL, token string) (*Client, error) {
+func NewClient(baseURL string, tokens TokenSource) (*Client, error) {
 	u, err := url.ParseRequestURI(baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse client url: %w", err)
@@ -54,7 +59,7 @@ func NewClient(baseURL, token string) (*Client, error) {
 
 	return &Client{
 		baseURL:    u,
-		token:      token,
+		tokens:     tokens,
 		httpClient: retryClient,
 	}, nil
 }
@@ -87,7 +92,12 @@ func (c *Client) ListClusterTunnelEndpoints(ctx context.Context) ([]Endpoint, er
 		return nil, fmt.Errorf("build request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	token, err := c.tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get token: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
 	version.SetUserAgent(req)
 
 	resp, err := c.httpClient.Do(req)
@@ -49,7 +49,7 @@ func TestClient_ListClusterTunnelEndpoints(t *testing.T) {
 	})
 	srv := httptest.NewServer(mux)
 
-	client, err := NewClient(srv.URL, "token")
+	client, err := NewClient(srv.URL, staticToken("token"))
 	require.NoError(t, err)
 
 	endpoints, err := client.ListClusterTunnelEndpoints(context.Background())
@@ -67,7 +67,7 @@ func TestClient_ListClusterTunnelEndpoints_handleError(t *testing.T) {
 	})
 	srv := httptest.NewServer(mux)
 
-	client, err := NewClient(srv.URL, "token")
+	client, err := NewClient(srv.URL, staticToken("token"))
 	require.NoError(t, err)
 	// We remove the retryable client to not last too long.
 	client.httpClient = http.DefaultClient
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -34,6 +35,63 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// drainTimeout is the maximum time given to the in-flight tunnel streams to complete when the manager stops.
+const drainTimeout = 15 * time.Second
+
+// handshakeTimeout is the maximum time given to obtain a token and open a connection to a broker.
+const handshakeTimeout = 30 * time.Second
+
+// Config configures the tunnels opened by the manager.
+type Config struct {
+	// HeartbeatInterval is the interval at which heartbeats are sent to the broker to detect dead connections and
+	// measure the round-trip time.
+	HeartbeatInterval time.Duration
+	// HeartbeatTimeout is the maximum time to wait for the broker to answer a heartbeat, or for a write to complete,
+	// before considering the connection dead.
+	HeartbeatTimeout time.Duration
+
+	// StreamWindowSize is the maximum amount of bytes buffered for each stream before the broker stops sending data.
+	StreamWindowSize uint32
+	// AcceptBacklog is the maximum number of streams opened by the broker waiting to be accepted.
+	AcceptBacklog int
+
+	// ReconnectMinBackoff is the delay before the first reconnect attempt once a connection is lost.
+	ReconnectMinBackoff time.Duration
+	// ReconnectMaxBackoff is the maximum delay between two reconnect attempts.
+	ReconnectMaxBackoff time.Duration
+}
+
+// backoff returns the delay to wait before the given reconnect attempt. The delay exponentially grows with the number
+// of attempts, up to ReconnectMaxBackoff, and is jittered so tunnels lost at the same time don't reconnect all at once.
+func (c Config) backoff(attempt int) time.Duration {
+	delay := c.ReconnectMaxBackoff
+	if attempt < 32 && c.ReconnectMinBackoff<<attempt < c.ReconnectMaxBackoff {
+		delay = c.ReconnectMinBackoff << attempt
+	}
+
+	if delay <= 1 {
+		return delay
+	}
+
+	half := delay / 2
+
+	return half + time.Duration(rand.Int63n(int64(delay-half))) //nolint:gosec // No need for a cryptographically secure jitter.
+}
+
+func (c Config) yamuxConfig() *yamux.Config {
+	return &yamux.Config{
+		AcceptBacklog: c.AcceptBacklog,
+		// Heartbeats are handled by the tunnel itself to record the round-trip time.
+		EnableKeepAlive:        false,
+		KeepAliveInterval:      c.HeartbeatInterval,
+		ConnectionWriteTimeout: c.HeartbeatTimeout,
+		MaxStreamWindowSize:    c.StreamWindowSize,
+		StreamOpenTimeout:      75 * time.Second,
+		StreamCloseTimeout:     5 * time.Minute,
+		LogOutput:              io.Discard,
+	}
+}
+
 // Backend is able to call hub-tunnel API.
 type Backend interface {
 	ListClusterTunnelEndpoints(ctx context.Context) ([]Endpoint, error)
@@ -42,8 +100,9 @@ type Backend interface {
 // Manager manages tunnels.
 type Manager struct {
 	client            Backend
-	token             string
+	tokens            TokenSource
 	traefikTunnelAddr string
+	config            Config
 
 	tunnelsMu sync.Mutex
 	tunnels   map[string]*tunnel
@@ -53,9 +112,34 @@ type tunnel struct {
 	BrokerEndpoint  string
 	ClusterEndpoint string
 	Client          *closeAwareListener
+
+	config Config
+	stats  tunnelStats
+
+	clientMu  sync.Mutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+
+	proxies sync.WaitGroup
 }
 
+func newTunnel(brokerEndpoint, clusterEndpoint string, config Config) *tunnel {
+	return &tunnel{
+		BrokerEndpoint:  brokerEndpoint,
+		ClusterEndpoint: clusterEndpoint,
+		config:          config,
+		done:            make(chan struct{}),
+	}
+}
+
+// Close closes the tunnel connection and stops reconnecting to the broker.
 func (t *tunnel) Close() error {
+	t.clientMu.Lock()
+	defer t.clientMu.Unlock()
+
+	t.stopReconnecting()
+
 	if t.Client != nil {
 		return t.Client.Close()
 	}
@@ -63,19 +147,87 @@ func (t *tunnel) Close() error {
 	return nil
 }
 
+// stopReconnecting prevents the tunnel from reconnecting once its current connection is lost. It must be called
+// with clientMu held.
+func (t *tunnel) stopReconnecting() {
+	t.closed = true
+	t.closeOnce.Do(func() {
+		if t.done != nil {
+			close(t.done)
+		}
+	})
+}
+
+// setClient sets the listener accepting the broker streams. It returns false if the tunnel has been closed in the
+// meantime, in which case the listener must not be used.
+func (t *tunnel) setClient(client *closeAwareListener) bool {
+	t.clientMu.Lock()
+	defer t.clientMu.Unlock()
+
+	if t.closed {
+		return false
+	}
+
+	t.Client = client
+
+	return true
+}
+
+func (t *tunnel) isClosed() bool {
+	t.clientMu.Lock()
+	defer t.clientMu.Unlock()
+
+	return t.closed
+}
+
+// Shutdown stops accepting new streams from the broker and waits for the in-flight ones to complete, or the context
+// to be done, before closing the tunnel.
+func (t *tunnel) Shutdown(ctx context.Context) error {
+	t.clientMu.Lock()
+	t.stopReconnecting()
+	client := t.Client
+	t.clientMu.Unlock()
+
+	if client == nil {
+		return nil
+	}
+
+	if session, ok := client.Listener.(*yamux.Session); ok {
+		if err := session.GoAway(); err != nil {
+			log.Error().Err(err).Msg("Unable to notify the broker the tunnel is going away")
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		t.proxies.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn().Msg("Closing tunnel before the in-flight streams complete")
+	}
+
+	return t.Close()
+}
+
 // NewManager returns a new manager instance.
-func NewManager(tunnels Backend, traefikTunnelAddr, token string) Manager {
+func NewManager(tunnels Backend, traefikTunnelAddr string, tokens TokenSource, config Config) Manager {
 	return Manager{
 		client:            tunnels,
 		traefikTunnelAddr: traefikTunnelAddr,
-		token:             token,
+		tokens:            tokens,
+		config:            config,
 		tunnels:           make(map[string]*tunnel),
 	}
 }
 
 // Run runs the manager.
 // While running, the manager fetches every minute the tunnels available for
-// this cluster and create/delete tunnels accordingly.
+// this cluster and create/delete tunnels accordingly. Once the context is done,
+// the tunnels are closed after their in-flight streams complete.
 func (m *Manager) Run(ctx context.Context) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
@@ -100,17 +252,29 @@ func (m *Manager) Run(ctx context.Context) {
 }
 
 func (m *Manager) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
 	m.tunnelsMu.Lock()
 	defer m.tunnelsMu.Unlock()
 
+	var wg sync.WaitGroup
 	for id, tun := range m.tunnels {
-		if err := tun.Close(); err != nil {
-			log.Error().Err(err).
-				Str("tunnel_id", id).
-				Msg("Unable to close tunnel")
-		}
+		wg.Add(1)
+		go func(id string, tun *tunnel) {
+			defer wg.Done()
+
+			if err := tun.Shutdown(ctx); err != nil {
+				log.Error().Err(err).
+					Str("tunnel_id", id).
+					Msg("Unable to close tunnel")
+			}
+		}(id, tun)
+
 		delete(m.tunnels, id)
 	}
+
+	wg.Wait()
 }
 
 func (m *Manager) updateTunnels(ctx context.Context) error {
@@ -160,64 +324,125 @@ func (m *Manager) updateTunnels(ctx context.Context) error {
 }
 
 func (m *Manager) launchTunnel(endpoint Endpoint) {
-	t := &tunnel{BrokerEndpoint: endpoint.BrokerEndpoint, ClusterEndpoint: m.traefikTunnelAddr}
+	t := newTunnel(endpoint.BrokerEndpoint, m.traefikTunnelAddr, m.config)
 	m.tunnels[endpoint.TunnelID] = t
 
 	go func(t *tunnel, tunnelID string) {
-		err := t.launch(tunnelID, m.token)
-		if err != nil {
-			log.Error().Err(err).Msg("Launch tunnel")
-		}
+		t.run(tunnelID, m.tokens)
 
 		m.tunnelsMu.Lock()
-		delete(m.tunnels, tunnelID)
+		// The tunnel may have been replaced in the meantime, for instance when its broker endpoint changed.
+		if m.tunnels[tunnelID] == t {
+			delete(m.tunnels, tunnelID)
+		}
 		m.tunnelsMu.Unlock()
 	}(t, endpoint.TunnelID)
 }
 
-func (t *tunnel) launch(tunnelID, token string) error {
+// run keeps the tunnel connected to the broker until it gets closed. Every edge ingress stream opened by the broker
+// is multiplexed over this single connection. When the connection is lost, the tunnel reconnects after a jittered
+// exponential backoff, which is reset as soon as a connection is successfully established.
+func (t *tunnel) run(tunnelID string, tokens TokenSource) {
+	logger := log.With().
+		Str("broker_endpoint", t.BrokerEndpoint).
+		Str("tunnel_id", tunnelID).
+		Logger()
+
+	var attempt int
+	var connected bool
+	for {
+		session, err := t.connect(tunnelID, tokens)
+		if err == nil {
+			if connected {
+				t.stats.reconnects.Add(1)
+			}
+			connected = true
+
+			attempt = 0
+			t.stats.connected.Store(true)
+			err = t.serve(session)
+			t.stats.connected.Store(false)
+		}
+
+		if t.isClosed() {
+			return
+		}
+
+		delay := t.config.backoff(attempt)
+		attempt++
+
+		if err != nil {
+			logger.Error().Err(err).Dur("retry_in", delay).Msg("Tunnel connection lost")
+		} else {
+			logger.Warn().Dur("retry_in", delay).Msg("Tunnel connection closed by the broker")
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-t.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// connect opens a connection to the broker and starts a multiplexed session on it. The token is obtained on each
+// connection, since it may have been renewed since the previous one.
+func (t *tunnel) connect(tunnelID string, tokens TokenSource) (*yamux.Session, error) {
 	u, err := url.Parse(t.BrokerEndpoint)
 	if err != nil {
-		return fmt.Errorf("parse broker endpoint: %w", err)
+		return nil, fmt.Errorf("parse broker endpoint: %w", err)
 	}
 	u.Path = path.Join(u.Path, tunnelID)
 
+	ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+	defer cancel()
+
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get token: %w", err)
+	}
+
 	dialer := websocket.Dialer{
 		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 30 * time.Second,
+		HandshakeTimeout: handshakeTimeout,
 	}
-	connSocket, resp, err := dialer.Dial(u.String(), http.Header{"Authorization": []string{"Bearer " + token}})
+	connSocket, resp, err := dialer.DialContext(ctx, u.String(), http.Header{"Authorization": []string{"Bearer " + token}})
 	if err != nil {
-		return fmt.Errorf("dial: %w", err)
+		return nil, fmt.Errorf("dial: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusSwitchingProtocols {
-		return fmt.Errorf("expected protocol switching, got: %d", resp.StatusCode)
+		_ = connSocket.Close()
+		return nil, fmt.Errorf("expected protocol switching, got: %d", resp.StatusCode)
 	}
 
 	conn := &websocketNetConn{
 		Conn: connSocket,
 	}
 
-	cfg := &yamux.Config{
-		AcceptBacklog:          256,
-		EnableKeepAlive:        true,
-		KeepAliveInterval:      30 * time.Second,
-		ConnectionWriteTimeout: 10 * time.Second,
-		MaxStreamWindowSize:    256 * 1024,
-		StreamOpenTimeout:      75 * time.Second,
-		StreamCloseTimeout:     5 * time.Minute,
-		LogOutput:              io.Discard,
-	}
-	client, err := yamux.Client(conn, cfg)
+	session, err := yamux.Client(conn, t.config.yamuxConfig())
 	if err != nil {
-		return fmt.Errorf("new yamux client: %w", err)
+		_ = connSocket.Close()
+		return nil, fmt.Errorf("new yamux client: %w", err)
 	}
 
-	t.Client = &closeAwareListener{Listener: client}
+	return session, nil
+}
+
+// serve accepts the streams opened by the broker on the session and proxies them to the cluster endpoint until the
+// session is closed.
+func (t *tunnel) serve(session *yamux.Session) error {
+	client := &closeAwareListener{Listener: session}
+	if !t.setClient(client) {
+		return session.Close()
+	}
+
+	go t.heartbeat(session)
 
 	for {
-		brokerConn, acceptErr := t.Client.Accept()
+		brokerConn, acceptErr := client.Accept()
 		if acceptErr != nil {
 			if errors.Is(acceptErr, errListenerClosed) {
 				return nil
@@ -226,14 +451,50 @@ func (t *tunnel) launch(tunnelID, token string) error {
 			return fmt.Errorf("accept: %w", acceptErr)
 		}
 
+		t.proxies.Add(1)
+		t.stats.activeStreams.Add(1)
 		go func(brokerConn net.Conn) {
-			if err = proxy(brokerConn, t.ClusterEndpoint); err != nil {
+			defer func() {
+				t.stats.activeStreams.Add(-1)
+				t.proxies.Done()
+			}()
+
+			if err := proxy(countingConn{Conn: brokerConn, stats: &t.stats}, t.ClusterEndpoint); err != nil {
 				log.Error().Err(err).Msg("Unable to proxy the tunnel traffic to the cluster endpoint")
 			}
 		}(brokerConn)
 	}
 }
 
+// heartbeat periodically pings the broker to record the round-trip time. The session is closed, triggering a
+// reconnect, as soon as the broker fails to answer within the heartbeat timeout.
+func (t *tunnel) heartbeat(session *yamux.Session) {
+	ticker := time.NewTicker(t.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rtt, err := session.Ping()
+			if err != nil {
+				if session.IsClosed() {
+					return
+				}
+
+				log.Warn().Err(err).Str("broker_endpoint", t.BrokerEndpoint).Msg("Tunnel heartbeat failed, closing connection")
+				_ = session.Close()
+
+				return
+			}
+
+			t.stats.rtt.Store(int64(rtt))
+
+		case <-session.CloseChan():
+			return
+		}
+	}
+}
+
 func proxy(sourceConn net.Conn, addr string) error {
 	targetConn, err := net.Dial("tcp", addr)
 	if err != nil {
@@ -24,6 +24,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strconv"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -72,7 +74,7 @@ func TestManager_updateTunnels(t *testing.T) {
 	}
 
 	c := fakeClient(t)
-	manager := NewManager(client, ingCtrlServiceURL, "token")
+	manager := NewManager(client, ingCtrlServiceURL, staticToken("token"), testConfig())
 	manager.tunnels["current-tunnel-new-broker"] = &tunnel{
 		BrokerEndpoint:  "old-endpoint",
 		ClusterEndpoint: ingCtrlServiceURL,
@@ -118,6 +120,100 @@ func TestManager_updateTunnels(t *testing.T) {
 	manager.tunnelsMu.Unlock()
 }
 
+func TestConfig_backoff(t *testing.T) {
+	cfg := Config{
+		ReconnectMinBackoff: time.Second,
+		ReconnectMaxBackoff: 10 * time.Second,
+	}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 0, max: time.Second},
+		{attempt: 1, max: 2 * time.Second},
+		{attempt: 2, max: 4 * time.Second},
+		{attempt: 3, max: 8 * time.Second},
+		{attempt: 4, max: 10 * time.Second},
+		{attempt: 100, max: 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(strconv.Itoa(test.attempt), func(t *testing.T) {
+			t.Parallel()
+
+			for i := 0; i < 100; i++ {
+				delay := cfg.backoff(test.attempt)
+
+				assert.GreaterOrEqual(t, delay, test.max/2)
+				assert.Less(t, delay, test.max)
+			}
+		})
+	}
+}
+
+func Test_tunnel_run_reconnects(t *testing.T) {
+	connected := make(chan struct{}, 10)
+
+	var connections int32
+	broker := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		upgrader := &websocket.Upgrader{}
+		websocketConn, err := upgrader.Upgrade(rw, req, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		connected <- struct{}{}
+
+		// Drop the first connection to force the tunnel to reconnect.
+		if atomic.AddInt32(&connections, 1) == 1 {
+			_ = websocketConn.Close()
+			return
+		}
+
+		cfg := yamux.DefaultConfig()
+		cfg.LogOutput = io.Discard
+		server, err := yamux.Server(&websocketNetConn{Conn: websocketConn}, cfg)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		<-server.CloseChan()
+	}))
+	t.Cleanup(broker.Close)
+
+	tun := newTunnel("ws://"+broker.Listener.Addr().String(), "", testConfig())
+
+	stopped := make(chan struct{})
+	go func() {
+		tun.run("tunnel", staticToken("token"))
+		close(stopped)
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-connected:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the tunnel to connect")
+		}
+	}
+
+	assert.Eventually(t, func() bool {
+		return tun.stats.reconnects.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, tun.Close())
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the tunnel to stop")
+	}
+
+	assert.Len(t, connected, 0)
+}
+
 func Test_proxy(t *testing.T) {
 	echoListener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", "0"))
 	require.NoError(t, err)
@@ -201,6 +297,17 @@ func Test_proxy_targetUnreachable(t *testing.T) {
 	require.Error(t, err)
 }
 
+func testConfig() Config {
+	return Config{
+		HeartbeatInterval:   30 * time.Second,
+		HeartbeatTimeout:    10 * time.Second,
+		StreamWindowSize:    256 * 1024,
+		AcceptBacklog:       256,
+		ReconnectMinBackoff: 10 * time.Millisecond,
+		ReconnectMaxBackoff: 100 * time.Millisecond,
+	}
+}
+
 func createIngCtrlService(t *testing.T, wait chan struct{}, messages ...string) string {
 	t.Helper()
 
@@ -286,3 +393,68 @@ func buildBroker(t *testing.T, message []byte, tunnelID string) *httptest.Server
 		}
 	}))
 }
+
+func Test_tunnel_Shutdown(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+
+	broker, err := yamux.Server(brokerConn, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = broker.Close() })
+
+	client, err := yamux.Client(clientConn, nil)
+	require.NoError(t, err)
+
+	tun := &tunnel{Client: &closeAwareListener{Listener: client}}
+
+	// Simulate an in-flight stream.
+	tun.proxies.Add(1)
+
+	shutdownDone := make(chan error)
+	go func() {
+		shutdownDone <- tun.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdownDone:
+		t.Fatal("tunnel shut down before the in-flight stream completed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The broker is not allowed to open new streams anymore.
+	_, err = broker.OpenStream()
+	assert.Error(t, err)
+
+	tun.proxies.Done()
+
+	select {
+	case err = <-shutdownDone:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the tunnel to shut down")
+	}
+
+	assert.True(t, client.IsClosed())
+}
+
+func Test_tunnel_Shutdown_deadline(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+
+	broker, err := yamux.Server(brokerConn, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = broker.Close() })
+
+	client, err := yamux.Client(clientConn, nil)
+	require.NoError(t, err)
+
+	tun := &tunnel{Client: &closeAwareListener{Listener: client}}
+	tun.proxies.Add(1)
+	t.Cleanup(tun.proxies.Done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = tun.Shutdown(ctx)
+	require.NoError(t, err)
+
+	assert.True(t, client.IsClosed())
+}
@@ -0,0 +1,142 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"net"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Stats holds the statistics of a tunnel.
+type Stats struct {
+	TunnelID      string
+	Connected     bool
+	BytesReceived uint64
+	BytesSent     uint64
+	ActiveStreams int64
+	Reconnects    uint64
+	RTT           time.Duration
+}
+
+// tunnelStats holds the counters of a tunnel. They are updated concurrently by the tunnel streams.
+type tunnelStats struct {
+	bytesReceived atomic.Uint64
+	bytesSent     atomic.Uint64
+	activeStreams atomic.Int64
+	reconnects    atomic.Uint64
+	rtt           atomic.Int64
+	connected     atomic.Bool
+}
+
+var (
+	bytesReceivedDesc = prometheus.NewDesc(
+		"hub_agent_tunnel_received_bytes_total",
+		"Total number of bytes received from the broker through the tunnel.",
+		[]string{"tunnel_id"}, nil,
+	)
+	bytesSentDesc = prometheus.NewDesc(
+		"hub_agent_tunnel_sent_bytes_total",
+		"Total number of bytes sent to the broker through the tunnel.",
+		[]string{"tunnel_id"}, nil,
+	)
+	activeStreamsDesc = prometheus.NewDesc(
+		"hub_agent_tunnel_active_streams",
+		"Number of streams currently proxied through the tunnel.",
+		[]string{"tunnel_id"}, nil,
+	)
+	reconnectsDesc = prometheus.NewDesc(
+		"hub_agent_tunnel_reconnects_total",
+		"Total number of times the tunnel reconnected to the broker after losing its connection.",
+		[]string{"tunnel_id"}, nil,
+	)
+	rttDesc = prometheus.NewDesc(
+		"hub_agent_tunnel_rtt_seconds",
+		"Round-trip time to the broker measured by the last tunnel heartbeat.",
+		[]string{"tunnel_id"}, nil,
+	)
+)
+
+// Stats returns the statistics of the tunnels currently managed, sorted by tunnel ID.
+func (m *Manager) Stats() []Stats {
+	m.tunnelsMu.Lock()
+	defer m.tunnelsMu.Unlock()
+
+	stats := make([]Stats, 0, len(m.tunnels))
+	for id, tun := range m.tunnels {
+		stats = append(stats, Stats{
+			TunnelID:      id,
+			Connected:     tun.stats.connected.Load(),
+			BytesReceived: tun.stats.bytesReceived.Load(),
+			BytesSent:     tun.stats.bytesSent.Load(),
+			ActiveStreams: tun.stats.activeStreams.Load(),
+			Reconnects:    tun.stats.reconnects.Load(),
+			RTT:           time.Duration(tun.stats.rtt.Load()),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TunnelID < stats[j].TunnelID
+	})
+
+	return stats
+}
+
+// Describe implements prometheus.Collector.
+func (m *Manager) Describe(ch chan<- *prometheus.Desc) {
+	ch <- bytesReceivedDesc
+	ch <- bytesSentDesc
+	ch <- activeStreamsDesc
+	ch <- reconnectsDesc
+	ch <- rttDesc
+}
+
+// Collect implements prometheus.Collector.
+func (m *Manager) Collect(ch chan<- prometheus.Metric) {
+	for _, stats := range m.Stats() {
+		ch <- prometheus.MustNewConstMetric(bytesReceivedDesc, prometheus.CounterValue, float64(stats.BytesReceived), stats.TunnelID)
+		ch <- prometheus.MustNewConstMetric(bytesSentDesc, prometheus.CounterValue, float64(stats.BytesSent), stats.TunnelID)
+		ch <- prometheus.MustNewConstMetric(activeStreamsDesc, prometheus.GaugeValue, float64(stats.ActiveStreams), stats.TunnelID)
+		ch <- prometheus.MustNewConstMetric(reconnectsDesc, prometheus.CounterValue, float64(stats.Reconnects), stats.TunnelID)
+		ch <- prometheus.MustNewConstMetric(rttDesc, prometheus.GaugeValue, stats.RTT.Seconds(), stats.TunnelID)
+	}
+}
+
+// countingConn counts the bytes going through a tunnel stream.
+type countingConn struct {
+	net.Conn
+
+	stats *tunnelStats
+}
+
+func (c countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.stats.bytesReceived.Add(uint64(n))
+
+	return n, err
+}
+
+func (c countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.stats.bytesSent.Add(uint64(n))
+
+	return n, err
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package tunnel
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Collect(t *testing.T) {
+	manager := NewManager(&clientMock{}, "", staticToken("token"), testConfig())
+
+	tun := newTunnel("ws://broker", "", testConfig())
+	tun.stats.bytesReceived.Store(42)
+	tun.stats.bytesSent.Store(1024)
+	tun.stats.activeStreams.Store(3)
+	tun.stats.reconnects.Store(2)
+	tun.stats.rtt.Store(int64(150 * time.Millisecond))
+	tun.stats.connected.Store(true)
+	manager.tunnels["tunnel-id"] = tun
+
+	assert.Equal(t, []Stats{
+		{
+			TunnelID:      "tunnel-id",
+			Connected:     true,
+			BytesReceived: 42,
+			BytesSent:     1024,
+			ActiveStreams: 3,
+			Reconnects:    2,
+			RTT:           150 * time.Millisecond,
+		},
+	}, manager.Stats())
+
+	want := `
+# HELP hub_agent_tunnel_active_streams Number of streams currently proxied through the tunnel.
+# TYPE hub_agent_tunnel_active_streams gauge
+hub_agent_tunnel_active_streams{tunnel_id="tunnel-id"} 3
+# HELP hub_agent_tunnel_received_bytes_total Total number of bytes received from the broker through the tunnel.
+# TYPE hub_agent_tunnel_received_bytes_total counter
+hub_agent_tunnel_received_bytes_total{tunnel_id="tunnel-id"} 42
+# HELP hub_agent_tunnel_reconnects_total Total number of times the tunnel reconnected to the broker after losing its connection.
+# TYPE hub_agent_tunnel_reconnects_total counter
+hub_agent_tunnel_reconnects_total{tunnel_id="tunnel-id"} 2
+# HELP hub_agent_tunnel_rtt_seconds Round-trip time to the broker measured by the last tunnel heartbeat.
+# TYPE hub_agent_tunnel_rtt_seconds gauge
+hub_agent_tunnel_rtt_seconds{tunnel_id="tunnel-id"} 0.15
+# HELP hub_agent_tunnel_sent_bytes_total Total number of bytes sent to the broker through the tunnel.
+# TYPE hub_agent_tunnel_sent_bytes_total counter
+hub_agent_tunnel_sent_bytes_total{tunnel_id="tunnel-id"} 1024
+`
+	err := testutil.CollectAndCompare(&manager, strings.NewReader(want))
+	require.NoError(t, err)
+}
+
+func Test_countingConn(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = clientConn.Close()
+		_ = brokerConn.Close()
+	})
+
+	var stats tunnelStats
+	conn := countingConn{Conn: clientConn, stats: &stats}
+
+	go func() {
+		_, _ = brokerConn.Write([]byte("hello"))
+
+		buf := make([]byte, 16)
+		_, _ = brokerConn.Read(buf)
+	}()
+
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	_, err = conn.Write([]byte("hello world"))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(5), stats.bytesReceived.Load())
+	assert.Equal(t, uint64(11), stats.bytesSent.Load())
+}
@@ -31,6 +31,12 @@ func (c *clientMock) ListClusterTunnelEndpoints(_ context.Context) ([]Endpoint,
 	return c.listClusterTunnelEndpoints()
 }
 
+type staticToken string
+
+func (t staticToken) Token(_ context.Context) (string, error) {
+	return string(t), nil
+}
+
 type readWriteCloseMock struct {
 	closedMu sync.Mutex
 	closed   bool
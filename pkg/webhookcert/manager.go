@@ -0,0 +1,545 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package webhookcert manages the certificate used to serve the admission webhooks of the agent.
+package webhookcert
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/hub-agent-kubernetes/pkg/pemkey"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+const (
+	caValidity    = 10 * 365 * 24 * time.Hour
+	certValidity  = 365 * 24 * time.Hour
+	renewBefore   = 30 * 24 * time.Hour
+	checkInterval = time.Hour
+	maxAttempts   = 3
+)
+
+// Secret keys holding the CA key pair, and the previous CA still trusted after a CA renewal. The serving key pair is
+// stored under the standard kubernetes.io/tls keys.
+const (
+	secretKeyCACert         = "ca.crt"
+	secretKeyCAKey          = "ca.key"
+	secretKeyPreviousCACert = "ca-previous.crt"
+)
+
+// Config holds the configuration of the Manager.
+type Config struct {
+	// Namespace is the namespace of the webhook Service and of the Secret storing the certificates.
+	Namespace string
+	// SecretName is the name of the Secret storing the certificates. It is shared by all the agent replicas.
+	SecretName string
+	// ServiceName is the name of the Service exposing the webhooks.
+	ServiceName string
+	// MutatingWebhookConfigurations are the names of the MutatingWebhookConfigurations whose caBundle is kept up to
+	// date.
+	MutatingWebhookConfigurations []string
+	// ValidatingWebhookConfigurations are the names of the ValidatingWebhookConfigurations whose caBundle is kept up
+	// to date.
+	ValidatingWebhookConfigurations []string
+	// CustomResourceDefinitions are the names of the CustomResourceDefinitions whose conversion webhook caBundle is
+	// kept up to date.
+	CustomResourceDefinitions []string
+}
+
+// Manager generates the certificate used to serve the webhooks, renews it before it expires and keeps the caBundle of
+// the webhook configurations and of the CRD conversion webhooks up to date. Certificates are stored in a Secret so all
+// the agent replicas serve certificates signed by the same CA. When the CA is renewed, the previous one stays in the
+// caBundle until it expires so the replicas still serving a certificate it signed keep being trusted.
+type Manager struct {
+	client    clientset.Interface
+	extClient apiextclientset.Interface
+	config    Config
+	now       func() time.Time
+
+	certMu   sync.RWMutex
+	cert     *tls.Certificate
+	caBundle []byte
+}
+
+// NewManager creates a new Manager. The given apiextensions client may be nil when no CustomResourceDefinitions are
+// configured.
+func NewManager(client clientset.Interface, extClient apiextclientset.Interface, config Config) *Manager {
+	return &Manager{
+		client:    client,
+		extClient: extClient,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+// Run periodically renews the certificate, if needed, until the given context is canceled.
+func (m *Manager) Run(ctx context.Context) {
+	t := time.NewTicker(checkInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := m.Sync(ctx); err != nil {
+				log.Error().Err(err).Msg("Unable to sync webhook certificate")
+			}
+		}
+	}
+}
+
+// GetCertificate returns the current serving certificate. It is meant to be used as tls.Config.GetCertificate.
+func (m *Manager) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.certMu.RLock()
+	defer m.certMu.RUnlock()
+
+	if m.cert == nil {
+		return nil, errors.New("webhook certificate not synced")
+	}
+
+	return m.cert, nil
+}
+
+// CABundle returns the PEM encoded CAs to trust: the CA of the current serving certificate, followed by the previous
+// CA while it hasn't expired. It returns nil if not synced yet.
+func (m *Manager) CABundle() []byte {
+	m.certMu.RLock()
+	defer m.certMu.RUnlock()
+
+	return m.caBundle
+}
+
+// Sync loads the certificates from the Secret, renewing them if they are missing or about to expire, and makes sure
+// the webhook configurations and the CRD conversion webhooks trust their CA. The serving certificate is only switched
+// once they do. It must succeed once before serving requests.
+func (m *Manager) Sync(ctx context.Context) error {
+	var (
+		b   *bundle
+		err error
+	)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		b, err = m.syncSecret(ctx)
+		// Another replica may have renewed the certificates concurrently, in which case its certificates are used.
+		if kerror.IsConflict(err) || kerror.IsAlreadyExists(err) {
+			continue
+		}
+		break
+	}
+	if err != nil {
+		return fmt.Errorf("sync secret: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair(b.certPEM, b.keyPEM)
+	if err != nil {
+		return fmt.Errorf("parse serving key pair: %w", err)
+	}
+
+	caBundle := b.caBundle(m.now())
+
+	for _, name := range m.config.MutatingWebhookConfigurations {
+		if err = m.injectMutatingCABundle(ctx, name, caBundle); err != nil {
+			return fmt.Errorf("inject CA bundle in mutating webhook configuration %q: %w", name, err)
+		}
+	}
+	for _, name := range m.config.ValidatingWebhookConfigurations {
+		if err = m.injectValidatingCABundle(ctx, name, caBundle); err != nil {
+			return fmt.Errorf("inject CA bundle in validating webhook configuration %q: %w", name, err)
+		}
+	}
+	for _, name := range m.config.CustomResourceDefinitions {
+		if err = m.injectConversionCABundle(ctx, name, caBundle); err != nil {
+			return fmt.Errorf("inject CA bundle in CRD %q: %w", name, err)
+		}
+	}
+
+	m.certMu.Lock()
+	m.cert = &cert
+	m.caBundle = caBundle
+	m.certMu.Unlock()
+
+	return nil
+}
+
+func (m *Manager) syncSecret(ctx context.Context) (*bundle, error) {
+	secrets := m.client.CoreV1().Secrets(m.config.Namespace)
+
+	secret, err := secrets.Get(ctx, m.config.SecretName, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return nil, fmt.Errorf("get secret: %w", err)
+	}
+	if kerror.IsNotFound(err) {
+		secret = nil
+	}
+
+	now := m.now()
+
+	var current *bundle
+	if secret != nil {
+		current, err = parseBundle(secret.Data)
+		if err != nil {
+			log.Warn().Err(err).Str("secret_name", m.config.SecretName).Msg("Invalid webhook certificate, generating a new one")
+		}
+	}
+
+	if current != nil && !expiresSoon(current.cert, now) && !expiresSoon(current.caCert, now) {
+		return current, nil
+	}
+
+	renewed, err := m.renew(current, now)
+	if err != nil {
+		return nil, fmt.Errorf("renew certificate: %w", err)
+	}
+
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      m.config.SecretName,
+				Namespace: m.config.Namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "traefik-hub",
+				},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: renewed.data(),
+		}
+
+		if _, err = secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("create secret: %w", err)
+		}
+	} else {
+		secret = secret.DeepCopy()
+		secret.Data = renewed.data()
+
+		if _, err = secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return nil, fmt.Errorf("update secret: %w", err)
+		}
+	}
+
+	log.Info().
+		Str("secret_name", m.config.SecretName).
+		Time("not_after", renewed.cert.NotAfter).
+		Msg("Webhook certificate renewed")
+
+	return renewed, nil
+}
+
+// renew issues a new serving certificate. The CA of the current bundle is kept unless it's missing or about to expire
+// so the webhook configurations don't need to be updated. When a new CA is generated, the current one is kept as the
+// previous CA.
+func (m *Manager) renew(current *bundle, now time.Time) (*bundle, error) {
+	var b bundle
+	if current != nil && !expiresSoon(current.caCert, now) {
+		b.caCert, b.caKey, b.caPEM, b.caKeyPEM = current.caCert, current.caKey, current.caPEM, current.caKeyPEM
+		b.previousCACert, b.previousCAPEM = current.previousCACert, current.previousCAPEM
+	} else {
+		var err error
+		b.caCert, b.caKey, b.caPEM, b.caKeyPEM, err = generateCA(now)
+		if err != nil {
+			return nil, fmt.Errorf("generate CA: %w", err)
+		}
+
+		if current != nil {
+			b.previousCACert, b.previousCAPEM = current.caCert, current.caPEM
+		}
+	}
+
+	svc, ns := m.config.ServiceName, m.config.Namespace
+	dnsNames := []string{
+		svc,
+		svc + "." + ns,
+		svc + "." + ns + ".svc",
+		svc + "." + ns + ".svc.cluster.local",
+	}
+
+	var err error
+	b.cert, b.certPEM, b.keyPEM, err = generateServingCert(b.caCert, b.caKey, dnsNames, now)
+	if err != nil {
+		return nil, fmt.Errorf("generate serving certificate: %w", err)
+	}
+
+	return &b, nil
+}
+
+func (m *Manager) injectMutatingCABundle(ctx context.Context, name string, caBundle []byte) error {
+	webhookConfigs := m.client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+
+	webhookConfig, err := webhookConfigs.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get webhook configuration: %w", err)
+	}
+
+	webhookConfig = webhookConfig.DeepCopy()
+
+	var changed bool
+	for i := range webhookConfig.Webhooks {
+		changed = setCABundle(&webhookConfig.Webhooks[i].ClientConfig.CABundle, caBundle) || changed
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if _, err = webhookConfigs.Update(ctx, webhookConfig, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update webhook configuration: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) injectValidatingCABundle(ctx context.Context, name string, caBundle []byte) error {
+	webhookConfigs := m.client.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+
+	webhookConfig, err := webhookConfigs.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get webhook configuration: %w", err)
+	}
+
+	webhookConfig = webhookConfig.DeepCopy()
+
+	var changed bool
+	for i := range webhookConfig.Webhooks {
+		changed = setCABundle(&webhookConfig.Webhooks[i].ClientConfig.CABundle, caBundle) || changed
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if _, err = webhookConfigs.Update(ctx, webhookConfig, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update webhook configuration: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) injectConversionCABundle(ctx context.Context, name string, caBundle []byte) error {
+	if m.extClient == nil {
+		return errors.New("apiextensions client is required")
+	}
+
+	crds := m.extClient.ApiextensionsV1().CustomResourceDefinitions()
+
+	crd, err := crds.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get CRD: %w", err)
+	}
+
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Strategy != apiextv1.WebhookConverter || conversion.Webhook == nil || conversion.Webhook.ClientConfig == nil {
+		return errors.New("no conversion webhook configured")
+	}
+
+	crd = crd.DeepCopy()
+	if !setCABundle(&crd.Spec.Conversion.Webhook.ClientConfig.CABundle, caBundle) {
+		return nil
+	}
+
+	if _, err = crds.Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update CRD: %w", err)
+	}
+
+	return nil
+}
+
+// setCABundle sets the given caBundle to the given value and reports whether it changed.
+func setCABundle(caBundle *[]byte, value []byte) bool {
+	if bytes.Equal(*caBundle, value) {
+		return false
+	}
+
+	*caBundle = value
+
+	return true
+}
+
+// bundle holds the CA and serving key pairs.
+type bundle struct {
+	caCert   *x509.Certificate
+	caKey    crypto.Signer
+	caPEM    []byte
+	caKeyPEM []byte
+
+	// previousCACert is the CA replaced by the last CA renewal, nil if none.
+	previousCACert *x509.Certificate
+	previousCAPEM  []byte
+
+	cert    *x509.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (b *bundle) data() map[string][]byte {
+	data := map[string][]byte{
+		secretKeyCACert:         b.caPEM,
+		secretKeyCAKey:          b.caKeyPEM,
+		corev1.TLSCertKey:       b.certPEM,
+		corev1.TLSPrivateKeyKey: b.keyPEM,
+	}
+	if b.previousCAPEM != nil {
+		data[secretKeyPreviousCACert] = b.previousCAPEM
+	}
+
+	return data
+}
+
+// caBundle returns the PEM encoded CAs to trust at the given time: the current CA, followed by the previous CA unless
+// it has expired.
+func (b *bundle) caBundle(now time.Time) []byte {
+	if b.previousCACert == nil || !now.Before(b.previousCACert.NotAfter) {
+		return b.caPEM
+	}
+
+	caBundle := make([]byte, 0, len(b.caPEM)+len(b.previousCAPEM))
+	caBundle = append(caBundle, b.caPEM...)
+
+	return append(caBundle, b.previousCAPEM...)
+}
+
+func parseBundle(data map[string][]byte) (*bundle, error) {
+	b := bundle{
+		caPEM:    data[secretKeyCACert],
+		caKeyPEM: data[secretKeyCAKey],
+		certPEM:  data[corev1.TLSCertKey],
+		keyPEM:   data[corev1.TLSPrivateKeyKey],
+	}
+
+	var err error
+	if b.caCert, err = parseCertificate(b.caPEM); err != nil {
+		return nil, fmt.Errorf("parse CA certificate: %w", err)
+	}
+	if b.caKey, err = pemkey.ParsePrivateKey(b.caKeyPEM); err != nil {
+		return nil, fmt.Errorf("parse CA key: %w", err)
+	}
+	if b.cert, err = parseCertificate(b.certPEM); err != nil {
+		return nil, fmt.Errorf("parse serving certificate: %w", err)
+	}
+	if _, err = tls.X509KeyPair(b.certPEM, b.keyPEM); err != nil {
+		return nil, fmt.Errorf("parse serving key pair: %w", err)
+	}
+	if err = b.cert.CheckSignatureFrom(b.caCert); err != nil {
+		return nil, fmt.Errorf("check serving certificate signature: %w", err)
+	}
+
+	// The previous CA is only kept to be trusted a bit longer, a broken one is dropped rather than failing the bundle.
+	if previousCAPEM := data[secretKeyPreviousCACert]; previousCAPEM != nil {
+		if b.previousCACert, err = parseCertificate(previousCAPEM); err == nil {
+			b.previousCAPEM = previousCAPEM
+		}
+	}
+
+	return &b, nil
+}
+
+func expiresSoon(cert *x509.Certificate, now time.Time) bool {
+	return !now.Add(renewBefore).Before(cert.NotAfter)
+}
+
+func generateCA(now time.Time) (cert *x509.Certificate, key crypto.Signer, certPEM, keyPEM []byte, err error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "hub-agent-webhook-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	return generateCertificate(template, nil, nil)
+}
+
+func generateServingCert(caCert *x509.Certificate, caKey crypto.Signer, dnsNames []string, now time.Time) (cert *x509.Certificate, certPEM, keyPEM []byte, err error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(certValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	cert, _, certPEM, keyPEM, err = generateCertificate(template, caCert, caKey)
+
+	return cert, certPEM, keyPEM, err
+}
+
+// generateCertificate generates a key pair from the given template, signed by the given parent, or self-signed if
+// none is given.
+func generateCertificate(template, parent *x509.Certificate, parentKey crypto.Signer) (cert *x509.Certificate, key crypto.Signer, certPEM, keyPEM []byte, err error) {
+	key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("generate serial number: %w", err)
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("create certificate: %w", err)
+	}
+
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("parse certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("marshal key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+
+	return cert, key, certPEM, keyPEM, nil
+}
+
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhookcert
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextmock "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+var testConfig = Config{
+	Namespace:                     "hub",
+	SecretName:                    "hub-agent-webhook-tls",
+	ServiceName:                   "admission",
+	MutatingWebhookConfigurations: []string{"hub-acp"},
+}
+
+func TestManager_Sync(t *testing.T) {
+	ctx := context.Background()
+
+	client := kubemock.NewSimpleClientset(&admv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-acp"},
+		Webhooks: []admv1.MutatingWebhook{
+			{Name: "hub-agent.traefik.acp"},
+			{Name: "hub-agent.traefik.ingress"},
+		},
+	})
+
+	m := NewManager(client, nil, testConfig)
+
+	_, err := m.GetCertificate(nil)
+	require.Error(t, err)
+
+	require.NoError(t, m.Sync(ctx))
+
+	secret, err := client.CoreV1().Secrets("hub").Get(ctx, "hub-agent-webhook-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.SecretTypeTLS, secret.Type)
+
+	caPEM := secret.Data[secretKeyCACert]
+	assertCABundle(t, client, caPEM)
+	assert.Equal(t, caPEM, m.CABundle())
+
+	cert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	assert.Contains(t, leaf.DNSNames, "admission.hub.svc")
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caPEM))
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "admission.hub.svc", Roots: roots})
+	require.NoError(t, err)
+
+	// Other replicas use the certificate stored in the secret.
+	other := NewManager(client, nil, testConfig)
+	require.NoError(t, other.Sync(ctx))
+
+	otherCert, err := other.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, otherCert.Certificate)
+}
+
+func TestManager_Sync_renewal(t *testing.T) {
+	ctx := context.Background()
+
+	client := kubemock.NewSimpleClientset(&admv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-acp"},
+		Webhooks:   []admv1.MutatingWebhook{{Name: "hub-agent.traefik.acp"}},
+	})
+
+	start := time.Now()
+	now := start
+	m := NewManager(client, nil, testConfig)
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Sync(ctx))
+
+	cert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+
+	secret, err := client.CoreV1().Secrets("hub").Get(ctx, "hub-agent-webhook-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	caPEM := secret.Data[secretKeyCACert]
+
+	// The certificate is kept until it's about to expire.
+	now = now.Add(certValidity - renewBefore - time.Hour)
+	require.NoError(t, m.Sync(ctx))
+
+	sameCert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Certificate, sameCert.Certificate)
+
+	// The certificate is renewed before it expires, with the same CA.
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, m.Sync(ctx))
+
+	renewedCert, err := m.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, cert.Certificate, renewedCert.Certificate)
+	assertCABundle(t, client, caPEM)
+
+	// The CA is renewed before it expires. The previous CA stays in the webhook configurations for the replicas still
+	// serving a certificate it signed.
+	now = start.Add(caValidity - renewBefore + time.Hour)
+	require.NoError(t, m.Sync(ctx))
+
+	secret, err = client.CoreV1().Secrets("hub").Get(ctx, "hub-agent-webhook-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	renewedCAPEM := secret.Data[secretKeyCACert]
+	assert.NotEqual(t, caPEM, renewedCAPEM)
+	assert.Equal(t, caPEM, secret.Data[secretKeyPreviousCACert])
+	assertCABundle(t, client, append(append([]byte{}, renewedCAPEM...), caPEM...))
+
+	// The previous CA is dropped once it has expired.
+	now = now.Add(renewBefore)
+	require.NoError(t, m.Sync(ctx))
+
+	assertCABundle(t, client, renewedCAPEM)
+	assert.Equal(t, renewedCAPEM, m.CABundle())
+}
+
+func TestManager_Sync_validatingAndConversionWebhooks(t *testing.T) {
+	ctx := context.Background()
+
+	client := kubemock.NewSimpleClientset(&admv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-api-validation"},
+		Webhooks:   []admv1.ValidatingWebhook{{Name: "hub-agent.traefik.api-validation"}},
+	})
+	extClient := apiextmock.NewSimpleClientset(&apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "apis.hub.traefik.io"},
+		Spec: apiextv1.CustomResourceDefinitionSpec{
+			Conversion: &apiextv1.CustomResourceConversion{
+				Strategy: apiextv1.WebhookConverter,
+				Webhook: &apiextv1.WebhookConversion{
+					ClientConfig: &apiextv1.WebhookClientConfig{
+						Service: &apiextv1.ServiceReference{Namespace: "hub", Name: "admission"},
+					},
+				},
+			},
+		},
+	})
+
+	m := NewManager(client, extClient, Config{
+		Namespace:                       "hub",
+		SecretName:                      "hub-agent-webhook-tls",
+		ServiceName:                     "admission",
+		ValidatingWebhookConfigurations: []string{"hub-api-validation"},
+		CustomResourceDefinitions:       []string{"apis.hub.traefik.io"},
+	})
+	require.NoError(t, m.Sync(ctx))
+
+	webhookConfig, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "hub-api-validation", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, m.CABundle(), webhookConfig.Webhooks[0].ClientConfig.CABundle)
+
+	crd, err := extClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, "apis.hub.traefik.io", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, m.CABundle(), crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+}
+
+func TestManager_Sync_crdWithoutConversionWebhook(t *testing.T) {
+	extClient := apiextmock.NewSimpleClientset(&apiextv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "apis.hub.traefik.io"},
+	})
+
+	m := NewManager(kubemock.NewSimpleClientset(), extClient, Config{
+		Namespace:                 "hub",
+		SecretName:                "hub-agent-webhook-tls",
+		ServiceName:               "admission",
+		CustomResourceDefinitions: []string{"apis.hub.traefik.io"},
+	})
+	require.Error(t, m.Sync(context.Background()))
+
+	_, err := m.GetCertificate(nil)
+	assert.Error(t, err)
+}
+
+func TestManager_Sync_invalidSecret(t *testing.T) {
+	ctx := context.Background()
+
+	client := kubemock.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "hub-agent-webhook-tls", Namespace: "hub"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("invalid"),
+			corev1.TLSPrivateKeyKey: []byte("invalid"),
+		},
+	})
+
+	m := NewManager(client, nil, Config{Namespace: "hub", SecretName: "hub-agent-webhook-tls", ServiceName: "admission"})
+	require.NoError(t, m.Sync(ctx))
+
+	secret, err := client.CoreV1().Secrets("hub").Get(ctx, "hub-agent-webhook-tls", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	_, err = parseBundle(secret.Data)
+	require.NoError(t, err)
+}
+
+func assertCABundle(t *testing.T, client *kubemock.Clientset, caPEM []byte) {
+	t.Helper()
+
+	webhookConfig, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), "hub-acp", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	for _, webhook := range webhookConfig.Webhooks {
+		assert.Equal(t, caPEM, webhook.ClientConfig.CABundle)
+	}
+}
@@ -0,0 +1,454 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package webhookconfig reconciles the webhook configurations of the agent.
+package webhookconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/utils/pointer"
+)
+
+// Config holds the configuration of the Reconciler.
+type Config struct {
+	// Name is the name of the MutatingWebhookConfiguration, and of the ValidatingWebhookConfiguration when the API
+	// management webhooks are enabled.
+	Name string
+	// ServiceName and ServiceNamespace identify the Service exposing the webhooks.
+	ServiceName      string
+	ServiceNamespace string
+	// FailurePolicy is the failure policy of the webhooks, either Ignore or Fail.
+	FailurePolicy admv1.FailurePolicyType
+	// NamespaceSelector restricts the namespaces of the namespaced resources sent to the webhooks.
+	NamespaceSelector *metav1.LabelSelector
+	// APIManagement enables the webhooks of the API management resources, including their validation webhook and the
+	// conversion webhook of their CRDs.
+	APIManagement bool
+	// OpenShiftRoutes enables the review of OpenShift Routes by the ingress webhook.
+	OpenShiftRoutes bool
+	// CABundle returns the CA bundle of the webhooks. The CA bundle of the existing webhooks is kept when it is nil or
+	// returns nil, which allows the certificate to be managed externally.
+	CABundle func() []byte
+	// SyncInterval is the interval at which the webhook configuration is reconciled.
+	SyncInterval time.Duration
+}
+
+// Reconciler creates the webhook configurations of the agent and continuously reconciles them with the agent
+// configuration, correcting any manual change. It covers all the webhooks served by the agent: the mutating webhooks,
+// and when API management is enabled, the API validation webhook and the conversion webhook of the API management
+// CRDs. The CRDs themselves are still installed by the Helm chart, only their conversion webhook is reconciled.
+type Reconciler struct {
+	client    clientset.Interface
+	extClient apiextclientset.Interface
+	config    Config
+}
+
+// NewReconciler creates a new Reconciler. The given apiextensions client may be nil when API management is disabled.
+func NewReconciler(client clientset.Interface, extClient apiextclientset.Interface, config Config) (*Reconciler, error) {
+	switch config.FailurePolicy {
+	case admv1.Ignore, admv1.Fail:
+	default:
+		return nil, fmt.Errorf("invalid failure policy %q: must be %s or %s", config.FailurePolicy, admv1.Ignore, admv1.Fail)
+	}
+
+	if config.APIManagement && extClient == nil {
+		return nil, errors.New("apiextensions client is required to reconcile the API management conversion webhook")
+	}
+
+	return &Reconciler{
+		client:    client,
+		extClient: extClient,
+		config:    config,
+	}, nil
+}
+
+// Run reconciles the webhook configuration at the configured interval until the given context is canceled.
+func (r *Reconciler) Run(ctx context.Context) {
+	t := time.NewTicker(r.config.SyncInterval)
+	defer t.Stop()
+
+	for {
+		if err := r.Reconcile(ctx); err != nil {
+			log.Error().Err(err).Str("webhook_configuration", r.config.Name).Msg("Unable to reconcile webhook configuration")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Reconcile creates the webhook configurations or updates them if they drifted from the desired ones.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	err := r.reconcileMutating(ctx)
+	if !r.config.APIManagement {
+		return err
+	}
+
+	return errors.Join(err, r.reconcileValidating(ctx), r.reconcileConversion(ctx))
+}
+
+func (r *Reconciler) reconcileMutating(ctx context.Context) error {
+	webhookConfigs := r.client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+
+	current, err := webhookConfigs.Get(ctx, r.config.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get webhook configuration: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		desired := &admv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: r.config.Name,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "traefik-hub",
+				},
+			},
+			Webhooks: r.buildWebhooks(nil),
+		}
+
+		if _, err = webhookConfigs.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create webhook configuration: %w", err)
+		}
+
+		log.Info().Str("webhook_configuration", r.config.Name).Msg("Webhook configuration created")
+
+		return nil
+	}
+
+	webhooks := r.buildWebhooks(current)
+	if equality.Semantic.DeepEqual(current.Webhooks, webhooks) {
+		return nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Webhooks = webhooks
+
+	if _, err = webhookConfigs.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update webhook configuration: %w", err)
+	}
+
+	log.Info().Str("webhook_configuration", r.config.Name).Msg("Webhook configuration drift corrected")
+
+	return nil
+}
+
+func (r *Reconciler) reconcileValidating(ctx context.Context) error {
+	webhookConfigs := r.client.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+
+	current, err := webhookConfigs.Get(ctx, r.config.Name, metav1.GetOptions{})
+	if err != nil && !kerror.IsNotFound(err) {
+		return fmt.Errorf("get validating webhook configuration: %w", err)
+	}
+
+	if kerror.IsNotFound(err) {
+		desired := &admv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: r.config.Name,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "traefik-hub",
+				},
+			},
+			Webhooks: r.buildValidatingWebhooks(nil),
+		}
+
+		if _, err = webhookConfigs.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create validating webhook configuration: %w", err)
+		}
+
+		log.Info().Str("webhook_configuration", r.config.Name).Msg("Validating webhook configuration created")
+
+		return nil
+	}
+
+	webhooks := r.buildValidatingWebhooks(current)
+	if equality.Semantic.DeepEqual(current.Webhooks, webhooks) {
+		return nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Webhooks = webhooks
+
+	if _, err = webhookConfigs.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update validating webhook configuration: %w", err)
+	}
+
+	log.Info().Str("webhook_configuration", r.config.Name).Msg("Validating webhook configuration drift corrected")
+
+	return nil
+}
+
+// reconcileConversion makes the API management CRDs convert their resources through the conversion webhook of the
+// agent.
+func (r *Reconciler) reconcileConversion(ctx context.Context) error {
+	crds := r.extClient.ApiextensionsV1().CustomResourceDefinitions()
+
+	var errs []error
+	for _, name := range conversionCRDs() {
+		crd, err := crds.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get CRD %q: %w", name, err))
+			continue
+		}
+
+		var currentCABundle []byte
+		if conversion := crd.Spec.Conversion; conversion != nil && conversion.Webhook != nil && conversion.Webhook.ClientConfig != nil {
+			currentCABundle = conversion.Webhook.ClientConfig.CABundle
+		}
+
+		conversion := &apiextv1.CustomResourceConversion{
+			Strategy: apiextv1.WebhookConverter,
+			Webhook: &apiextv1.WebhookConversion{
+				ClientConfig: &apiextv1.WebhookClientConfig{
+					Service: &apiextv1.ServiceReference{
+						Namespace: r.config.ServiceNamespace,
+						Name:      r.config.ServiceName,
+						Path:      pointer.String("/conversion"),
+						Port:      pointer.Int32(443),
+					},
+					CABundle: r.caBundle(currentCABundle),
+				},
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+		if equality.Semantic.DeepEqual(crd.Spec.Conversion, conversion) {
+			continue
+		}
+
+		updated := crd.DeepCopy()
+		updated.Spec.Conversion = conversion
+
+		if _, err = crds.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("update CRD %q: %w", name, err))
+			continue
+		}
+
+		log.Info().Str("crd", name).Msg("CRD conversion webhook reconciled")
+	}
+
+	return errors.Join(errs...)
+}
+
+// conversionCRDs returns the names of the CRDs served in several versions, converted by the conversion webhook.
+func conversionCRDs() []string {
+	return []string{"apis.hub.traefik.io", "apicollections.hub.traefik.io", "apigateways.hub.traefik.io"}
+}
+
+// caBundle returns the CA bundle of the webhooks, or the given current one when the certificate is managed externally.
+func (r *Reconciler) caBundle(current []byte) []byte {
+	if r.config.CABundle == nil {
+		return current
+	}
+
+	if caBundle := r.config.CABundle(); caBundle != nil {
+		return caBundle
+	}
+
+	return current
+}
+
+// buildWebhooks builds the desired webhooks. All the fields defaulted by the API server are set so the desired webhooks
+// can be compared to the current ones.
+func (r *Reconciler) buildWebhooks(current *admv1.MutatingWebhookConfiguration) []admv1.MutatingWebhook {
+	currentCABundles := make(map[string][]byte)
+	if current != nil {
+		for _, webhook := range current.Webhooks {
+			currentCABundles[webhook.Name] = webhook.ClientConfig.CABundle
+		}
+	}
+
+	var (
+		failurePolicy      = r.config.FailurePolicy
+		matchPolicy        = admv1.Equivalent
+		sideEffects        = admv1.SideEffectClassNone
+		reinvocationPolicy = admv1.NeverReinvocationPolicy
+	)
+
+	var webhooks []admv1.MutatingWebhook
+	for _, def := range r.webhookDefinitions() {
+		webhook := admv1.MutatingWebhook{
+			Name: def.name,
+			ClientConfig: admv1.WebhookClientConfig{
+				Service: &admv1.ServiceReference{
+					Namespace: r.config.ServiceNamespace,
+					Name:      r.config.ServiceName,
+					Path:      pointer.String(def.path),
+					Port:      pointer.Int32(443),
+				},
+				CABundle: r.caBundle(currentCABundles[def.name]),
+			},
+			Rules:                   def.rules,
+			FailurePolicy:           &failurePolicy,
+			MatchPolicy:             &matchPolicy,
+			NamespaceSelector:       r.namespaceSelector(),
+			ObjectSelector:          &metav1.LabelSelector{},
+			SideEffects:             &sideEffects,
+			TimeoutSeconds:          pointer.Int32(10),
+			AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			ReinvocationPolicy:      &reinvocationPolicy,
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks
+}
+
+// buildValidatingWebhooks builds the desired validating webhooks, validating the API management resources against
+// the cluster state. All the fields defaulted by the API server are set so the desired webhooks can be compared to the
+// current ones.
+func (r *Reconciler) buildValidatingWebhooks(current *admv1.ValidatingWebhookConfiguration) []admv1.ValidatingWebhook {
+	const name = "hub-agent.traefik.api-validation"
+
+	var currentCABundle []byte
+	if current != nil {
+		for _, webhook := range current.Webhooks {
+			if webhook.Name == name {
+				currentCABundle = webhook.ClientConfig.CABundle
+			}
+		}
+	}
+
+	var (
+		failurePolicy = r.config.FailurePolicy
+		matchPolicy   = admv1.Equivalent
+		sideEffects   = admv1.SideEffectClassNone
+		createUpdate  = []admv1.OperationType{admv1.Create, admv1.Update}
+	)
+
+	return []admv1.ValidatingWebhook{
+		{
+			Name: name,
+			ClientConfig: admv1.WebhookClientConfig{
+				Service: &admv1.ServiceReference{
+					Namespace: r.config.ServiceNamespace,
+					Name:      r.config.ServiceName,
+					Path:      pointer.String("/api-validation"),
+					Port:      pointer.Int32(443),
+				},
+				CABundle: r.caBundle(currentCABundle),
+			},
+			Rules: []admv1.RuleWithOperations{
+				rule(createUpdate, "hub.traefik.io", "v1alpha1", "apis"),
+				rule(createUpdate, "hub.traefik.io", "v1alpha1", "apicollections"),
+				rule(createUpdate, "hub.traefik.io", "v1alpha1", "apiportals"),
+				rule(createUpdate, "hub.traefik.io", "v1alpha1", "apigateways"),
+			},
+			FailurePolicy:           &failurePolicy,
+			MatchPolicy:             &matchPolicy,
+			NamespaceSelector:       r.namespaceSelector(),
+			ObjectSelector:          &metav1.LabelSelector{},
+			SideEffects:             &sideEffects,
+			TimeoutSeconds:          pointer.Int32(10),
+			AdmissionReviewVersions: []string{"v1", "v1beta1"},
+		},
+	}
+}
+
+// namespaceSelector returns a copy of the configured namespace selector, or a selector matching all namespaces.
+func (r *Reconciler) namespaceSelector() *metav1.LabelSelector {
+	if r.config.NamespaceSelector == nil {
+		return &metav1.LabelSelector{}
+	}
+
+	return r.config.NamespaceSelector.DeepCopy()
+}
+
+type webhookDefinition struct {
+	name  string
+	path  string
+	rules []admv1.RuleWithOperations
+}
+
+func (r *Reconciler) webhookDefinitions() []webhookDefinition {
+	createUpdate := []admv1.OperationType{admv1.Create, admv1.Update}
+	createUpdateDelete := []admv1.OperationType{admv1.Create, admv1.Update, admv1.Delete}
+
+	ingressRules := []admv1.RuleWithOperations{
+		rule(createUpdate, "networking.k8s.io", "v1", "ingresses"),
+		rule(createUpdate, "traefik.containo.us", "v1alpha1", "ingressroutes"),
+	}
+	if r.config.OpenShiftRoutes {
+		ingressRules = append(ingressRules, rule(createUpdate, "route.openshift.io", "v1", "routes"))
+	}
+
+	defs := []webhookDefinition{
+		{
+			name:  "hub-agent.traefik.acp",
+			path:  "/acp",
+			rules: []admv1.RuleWithOperations{rule(createUpdateDelete, "hub.traefik.io", "v1alpha1", "accesscontrolpolicies")},
+		},
+		{
+			name:  "hub-agent.traefik.ingress",
+			path:  "/ingress",
+			rules: ingressRules,
+		},
+		{
+			name:  "hub-agent.traefik.edge-ingress",
+			path:  "/edge-ingress",
+			rules: []admv1.RuleWithOperations{rule(createUpdateDelete, "hub.traefik.io", "v1alpha1", "edgeingresses")},
+		},
+	}
+
+	if !r.config.APIManagement {
+		return defs
+	}
+
+	for _, resource := range []struct{ name, path, resource string }{
+		{name: "api", path: "/api", resource: "apis"},
+		{name: "api-collection", path: "/api-collection", resource: "apicollections"},
+		{name: "api-access", path: "/api-access", resource: "apiaccesses"},
+		{name: "api-gateway", path: "/api-gateway", resource: "apigateways"},
+		{name: "api-portal", path: "/api-portal", resource: "apiportals"},
+	} {
+		defs = append(defs, webhookDefinition{
+			name:  "hub-agent.traefik." + resource.name,
+			path:  resource.path,
+			rules: []admv1.RuleWithOperations{rule(createUpdateDelete, "hub.traefik.io", "v1alpha1", resource.resource)},
+		})
+	}
+
+	return defs
+}
+
+func rule(operations []admv1.OperationType, group, version, resource string) admv1.RuleWithOperations {
+	scope := admv1.AllScopes
+
+	return admv1.RuleWithOperations{
+		Operations: operations,
+		Rule: admv1.Rule{
+			APIGroups:   []string{group},
+			APIVersions: []string{version},
+			Resources:   []string{resource},
+			Scope:       &scope,
+		},
+	}
+}
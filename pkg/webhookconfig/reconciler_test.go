@@ -0,0 +1,146 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhookconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextmock "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubemock "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	client := kubemock.NewSimpleClientset()
+
+	config := Config{
+		Name:             "hub-agent",
+		ServiceName:      "admission",
+		ServiceNamespace: "hub",
+		FailurePolicy:    admv1.Ignore,
+		SyncInterval:     time.Minute,
+	}
+	r, err := NewReconciler(client, nil, config)
+	require.NoError(t, err)
+
+	// The webhook configuration is created when missing.
+	require.NoError(t, r.Reconcile(ctx))
+
+	webhookConfig := getWebhookConfig(t, client)
+	require.Len(t, webhookConfig.Webhooks, 3)
+	for _, webhook := range webhookConfig.Webhooks {
+		assert.Equal(t, admv1.Ignore, *webhook.FailurePolicy)
+		assert.Equal(t, "admission", webhook.ClientConfig.Service.Name)
+	}
+
+	// Nothing is updated when the webhook configuration is up to date.
+	client.ClearActions()
+	require.NoError(t, r.Reconcile(ctx))
+	assert.Len(t, client.Actions(), 1)
+
+	// Manual changes are reverted, except for the CA bundle which is managed externally.
+	fail := admv1.Fail
+	webhookConfig.Webhooks[0].FailurePolicy = &fail
+	webhookConfig.Webhooks[1].Rules = nil
+	webhookConfig.Webhooks[2].ClientConfig.CABundle = []byte("ca")
+	_, err = client.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(ctx, webhookConfig, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, r.Reconcile(ctx))
+
+	webhookConfig = getWebhookConfig(t, client)
+	assert.Equal(t, admv1.Ignore, *webhookConfig.Webhooks[0].FailurePolicy)
+	assert.Len(t, webhookConfig.Webhooks[1].Rules, 2)
+	assert.Equal(t, []byte("ca"), webhookConfig.Webhooks[2].ClientConfig.CABundle)
+
+	// The failure policy can be switched, the API management webhooks enabled and OpenShift Routes reviewed.
+	config.FailurePolicy = admv1.Fail
+	config.APIManagement = true
+	config.OpenShiftRoutes = true
+	config.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"hub": "enabled"}}
+	config.CABundle = func() []byte { return []byte("managed-ca") }
+	extClient := apiextmock.NewSimpleClientset(
+		&apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "apis.hub.traefik.io"}},
+		&apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "apicollections.hub.traefik.io"}},
+		&apiextv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "apigateways.hub.traefik.io"}},
+	)
+	r, err = NewReconciler(client, extClient, config)
+	require.NoError(t, err)
+
+	require.NoError(t, r.Reconcile(ctx))
+
+	webhookConfig = getWebhookConfig(t, client)
+	require.Len(t, webhookConfig.Webhooks, 8)
+	for _, webhook := range webhookConfig.Webhooks {
+		assert.Equal(t, admv1.Fail, *webhook.FailurePolicy)
+		assert.Equal(t, config.NamespaceSelector, webhook.NamespaceSelector)
+		assert.Equal(t, []byte("managed-ca"), webhook.ClientConfig.CABundle)
+	}
+	require.Len(t, webhookConfig.Webhooks[1].Rules, 3)
+	assert.Equal(t, []string{"routes"}, webhookConfig.Webhooks[1].Rules[2].Resources)
+
+	// The API validation webhook and the conversion webhook of the API management CRDs are reconciled too.
+	validatingConfig, err := client.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, "hub-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, validatingConfig.Webhooks, 1)
+	assert.Equal(t, "/api-validation", *validatingConfig.Webhooks[0].ClientConfig.Service.Path)
+	assert.Equal(t, []byte("managed-ca"), validatingConfig.Webhooks[0].ClientConfig.CABundle)
+	assert.Equal(t, config.NamespaceSelector, validatingConfig.Webhooks[0].NamespaceSelector)
+
+	for _, name := range []string{"apis.hub.traefik.io", "apicollections.hub.traefik.io", "apigateways.hub.traefik.io"} {
+		crd, errGet := extClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, errGet)
+		require.NotNil(t, crd.Spec.Conversion)
+		assert.Equal(t, apiextv1.WebhookConverter, crd.Spec.Conversion.Strategy)
+		assert.Equal(t, "/conversion", *crd.Spec.Conversion.Webhook.ClientConfig.Service.Path)
+		assert.Equal(t, []byte("managed-ca"), crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+	}
+
+	// Nothing is updated when everything is up to date.
+	client.ClearActions()
+	extClient.ClearActions()
+	require.NoError(t, r.Reconcile(ctx))
+	assert.Len(t, client.Actions(), 2)
+	assert.Len(t, extClient.Actions(), 3)
+}
+
+func TestNewReconciler_apiManagementWithoutExtClient(t *testing.T) {
+	_, err := NewReconciler(kubemock.NewSimpleClientset(), nil, Config{FailurePolicy: admv1.Ignore, APIManagement: true})
+	require.Error(t, err)
+}
+
+func TestNewReconciler_invalidFailurePolicy(t *testing.T) {
+	_, err := NewReconciler(kubemock.NewSimpleClientset(), nil, Config{FailurePolicy: "Retry"})
+	require.Error(t, err)
+}
+
+func getWebhookConfig(t *testing.T, client *kubemock.Clientset) *admv1.MutatingWebhookConfiguration {
+	t.Helper()
+
+	webhookConfig, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(), "hub-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	return webhookConfig
+}
@@ -0,0 +1,204 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhooklimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config configures the limits enforced on the AdmissionReviews sent to the admission webhooks.
+type Config struct {
+	// MaxBodySize is the size, in bytes, above which AdmissionReviews are rejected without being reviewed.
+	MaxBodySize int64
+	// Timeout is the duration after which a review is abandoned. It must be lower than the webhook timeout for the
+	// API server to get the response configured by AllowOnTimeout rather than timing out.
+	Timeout time.Duration
+	// AllowOnTimeout allows, with a warning, the objects whose review timed out, as the Ignore failure policy would.
+	// They are denied otherwise.
+	AllowOnTimeout bool
+}
+
+// Middleware returns a middleware enforcing the given limits on the AdmissionReviews handled by the next handler.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return &handler{next: next, cfg: cfg}
+	}
+}
+
+type handler struct {
+	next http.Handler
+	cfg  Config
+}
+
+// ServeHTTP implements http.Handler.
+func (h *handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if h.cfg.MaxBodySize > 0 && req.ContentLength > h.cfg.MaxBodySize {
+		log.Error().
+			Int64("size", req.ContentLength).
+			Int64("max_size", h.cfg.MaxBodySize).
+			Msg("AdmissionReview too large")
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, err := h.readBody(rw, req)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			log.Error().Int64("max_size", h.cfg.MaxBodySize).Msg("AdmissionReview too large")
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		log.Error().Err(err).Msg("Unable to read AdmissionReview")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if h.cfg.Timeout <= 0 {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		h.next.ServeHTTP(rw, req)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.cfg.Timeout)
+	defer cancel()
+
+	req = req.WithContext(ctx)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	// The review is written in a buffer, for it to be discarded if it completes after the timeout.
+	buf := newBufferedResponse()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.next.ServeHTTP(buf, req)
+	}()
+
+	select {
+	case <-done:
+		buf.writeTo(rw)
+
+	case <-ctx.Done():
+		h.writeTimeoutResponse(rw, body)
+	}
+}
+
+func (h *handler) readBody(rw http.ResponseWriter, req *http.Request) ([]byte, error) {
+	if h.cfg.MaxBodySize <= 0 {
+		return io.ReadAll(req.Body)
+	}
+
+	return io.ReadAll(http.MaxBytesReader(rw, req.Body, h.cfg.MaxBodySize))
+}
+
+// writeTimeoutResponse answers the AdmissionReview held by the given body, whose review timed out.
+func (h *handler) writeTimeoutResponse(rw http.ResponseWriter, body []byte) {
+	var ar admv1.AdmissionReview
+	if err := json.Unmarshal(body, &ar); err != nil || ar.Request == nil {
+		log.Error().Err(err).Msg("AdmissionReview timed out and cannot be answered")
+		http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+		return
+	}
+
+	logger := log.With().
+		Str("uid", string(ar.Request.UID)).
+		Str("resource_kind", ar.Request.Kind.String()).
+		Str("resource_name", ar.Request.Name).
+		Dur("timeout", h.cfg.Timeout).
+		Bool("allowed", h.cfg.AllowOnTimeout).
+		Logger()
+	logger.Warn().Msg("AdmissionReview timed out")
+
+	msg := "Hub agent review timed out after " + h.cfg.Timeout.String()
+	ar.Response = &admv1.AdmissionResponse{
+		UID:     ar.Request.UID,
+		Allowed: h.cfg.AllowOnTimeout,
+	}
+	if h.cfg.AllowOnTimeout {
+		ar.Response.Warnings = []string{msg + ", the object was admitted without being reviewed"}
+	} else {
+		ar.Response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: msg,
+			Reason:  metav1.StatusReasonTimeout,
+			Code:    http.StatusGatewayTimeout,
+		}
+	}
+	ar.Request = nil
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(ar); err != nil {
+		logger.Error().Err(err).Msg("Unable to encode admission response")
+	}
+}
+
+// bufferedResponse is an http.ResponseWriter buffering the response.
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header)}
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.code == 0 {
+		b.code = code
+	}
+}
+
+func (b *bufferedResponse) writeTo(rw http.ResponseWriter) {
+	for name, values := range b.header {
+		rw.Header()[name] = values
+	}
+
+	code := b.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	rw.Header().Set("Content-Length", strconv.Itoa(b.body.Len()))
+	rw.WriteHeader(code)
+	_, _ = rw.Write(b.body.Bytes())
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package webhooklimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+)
+
+func TestMiddleware(t *testing.T) {
+	review := admv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  &admv1.AdmissionRequest{UID: ktypes.UID("id")},
+	}
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc         string
+		cfg          Config
+		body         []byte
+		delay        time.Duration
+		wantStatus   int
+		wantResponse *admv1.AdmissionResponse
+	}{
+		{
+			desc:         "review within limits",
+			cfg:          Config{MaxBodySize: 1024, Timeout: time.Second},
+			body:         body,
+			wantStatus:   http.StatusOK,
+			wantResponse: &admv1.AdmissionResponse{UID: "id", Allowed: true},
+		},
+		{
+			desc:       "review too large",
+			cfg:        Config{MaxBodySize: 10, Timeout: time.Second},
+			body:       body,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:  "review timed out allowed",
+			cfg:   Config{MaxBodySize: 1024, Timeout: 10 * time.Millisecond, AllowOnTimeout: true},
+			body:  body,
+			delay: time.Second,
+			wantResponse: &admv1.AdmissionResponse{
+				UID:      "id",
+				Allowed:  true,
+				Warnings: []string{"Hub agent review timed out after 10ms, the object was admitted without being reviewed"},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:  "review timed out denied",
+			cfg:   Config{MaxBodySize: 1024, Timeout: 10 * time.Millisecond},
+			body:  body,
+			delay: time.Second,
+			wantResponse: &admv1.AdmissionResponse{
+				UID: "id",
+				Result: &metav1.Status{
+					Status:  metav1.StatusFailure,
+					Message: "Hub agent review timed out after 10ms",
+					Reason:  metav1.StatusReasonTimeout,
+					Code:    http.StatusGatewayTimeout,
+				},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "invalid review timed out",
+			cfg:        Config{MaxBodySize: 1024, Timeout: 10 * time.Millisecond, AllowOnTimeout: true},
+			body:       []byte("{"),
+			delay:      time.Second,
+			wantStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				select {
+				case <-time.After(test.delay):
+				case <-req.Context().Done():
+					return
+				}
+
+				var ar admv1.AdmissionReview
+				if err := json.NewDecoder(req.Body).Decode(&ar); err != nil {
+					http.Error(rw, err.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+
+				ar.Response = &admv1.AdmissionResponse{UID: ar.Request.UID, Allowed: true}
+				ar.Request = nil
+				_ = json.NewEncoder(rw).Encode(ar)
+			})
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(test.body))
+
+			Middleware(test.cfg)(next).ServeHTTP(rec, req)
+
+			require.Equal(t, test.wantStatus, rec.Code)
+			if test.wantResponse == nil {
+				return
+			}
+
+			var got admv1.AdmissionReview
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			assert.Equal(t, review.TypeMeta, got.TypeMeta)
+			assert.Nil(t, got.Request)
+			assert.Equal(t, test.wantResponse, got.Response)
+		})
+	}
+}
+
+func TestMiddleware_bodyTooLargeWithoutContentLength(t *testing.T) {
+	var called bool
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader(strings.Repeat("a", 100))))
+	req.ContentLength = -1
+
+	Middleware(Config{MaxBodySize: 10, Timeout: time.Second})(next).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.False(t, called)
+}
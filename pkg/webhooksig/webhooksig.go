@@ -0,0 +1,39 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package webhooksig signs the payloads the agent posts to webhooks, so their receivers can verify where they come
+// from.
+package webhooksig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header is the header holding the HMAC-SHA256 signature of the payloads posted to webhooks, in the
+// `sha256=<hex encoded signature>` form.
+const Header = "X-Hub-Signature-256"
+
+// Sign sets the signature header of the given request to the HMAC-SHA256 of its body, computed with the given secret.
+func Sign(req *http.Request, secret, body []byte) {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+
+	req.Header.Set(Header, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,175 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+// Package workloadidentity authenticates the agent on the platform with its Kubernetes service account, exchanging a
+// projected service account token for platform tokens rather than relying on a static token.
+package workloadidentity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/hub-agent-kubernetes/pkg/httpclient"
+	"github.com/traefik/hub-agent-kubernetes/pkg/version"
+)
+
+// Token exchange parameters, as defined by RFC 8693.
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeJWT           = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// refreshMargin is the time before the expiry of a platform token from which it is renewed.
+const refreshMargin = time.Minute
+
+// TokenSource provides platform tokens exchanged for the service account token projected in the given file. The
+// service account token is read again on each exchange, since the kubelet rotates it.
+type TokenSource struct {
+	exchangeURL string
+	host        string
+	tokenFile   string
+	httpClient  *http.Client
+	now         func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewTokenSource returns a TokenSource exchanging the service account token projected in the given file on the
+// platform at the given URL.
+func NewTokenSource(platformURL, tokenFile string) (*TokenSource, error) {
+	baseURL, err := url.ParseRequestURI(platformURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse platform url: %w", err)
+	}
+
+	exchangeURL, err := baseURL.Parse(path.Join(baseURL.Path, "token-exchange"))
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	return &TokenSource{
+		exchangeURL: exchangeURL.String(),
+		host:        baseURL.Host,
+		tokenFile:   tokenFile,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		now:         time.Now,
+	}, nil
+}
+
+type tokenResp struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns a valid platform token, exchanging the service account token for a new one when the current one
+// expires soon.
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(refreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	token, expiresAt, err := s.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expiresAt = expiresAt
+
+	return token, nil
+}
+
+// invalidate drops the current platform token, so the next call to Token exchanges a new one.
+func (s *TokenSource) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = ""
+}
+
+func (s *TokenSource) exchange(ctx context.Context) (string, time.Time, error) {
+	subjectToken, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("read service account token: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type":         {grantTypeTokenExchange},
+		"subject_token":      {strings.TrimSpace(string(subjectToken))},
+		"subject_token_type": {tokenTypeJWT},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.exchangeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	version.SetUserAgent(req)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("exchange service account token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", time.Time{}, fmt.Errorf("exchange service account token: failed with code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tok tokenResp
+	if err = json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", time.Time{}, fmt.Errorf("decode token exchange response: %w", err)
+	}
+
+	if tok.AccessToken == "" {
+		return "", time.Time{}, errors.New("exchange service account token: empty access token")
+	}
+	if tok.TokenType != "" && !strings.EqualFold(tok.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("exchange service account token: unsupported token type %q", tok.TokenType)
+	}
+
+	return tok.AccessToken, s.now().Add(time.Duration(tok.ExpiresIn) * time.Second), nil
+}
+
+// Wrap returns a transport authenticating the requests sent to the platform with the platform tokens of the
+// TokenSource. Requests sent to other hosts are left untouched, so the tokens never leak. The given transport is
+// returned as is when the TokenSource is nil, which is the case when the agent uses a static token.
+func (s *TokenSource) Wrap(rt http.RoundTripper) http.RoundTripper {
+	if s == nil {
+		return rt
+	}
+
+	// The platform may revoke a token before it expires, a new one is exchanged for the next requests.
+	return httpclient.NewBearerTransport(rt, s.host, s.Token, s.invalidate)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright (C) 2022-2023 Traefik Labs
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program. If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package workloadidentity
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenSource_Token(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token-1\n"), 0o600))
+
+	var exchanges atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/agent/token-exchange", req.URL.Path)
+		require.NoError(t, req.ParseForm())
+		assert.Equal(t, grantTypeTokenExchange, req.PostForm.Get("grant_type"))
+		assert.Equal(t, tokenTypeJWT, req.PostForm.Get("subject_token_type"))
+
+		n := exchanges.Add(1)
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(tokenResp{
+			AccessToken: "platform-" + req.PostForm.Get("subject_token") + "-" + strconv.Itoa(int(n)),
+			TokenType:   "Bearer",
+			ExpiresIn:   600,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	source, err := NewTokenSource(srv.URL+"/agent", tokenFile)
+	require.NoError(t, err)
+
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "platform-sa-token-1-1", token)
+
+	// The token is cached until it expires soon.
+	now = now.Add(5 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "platform-sa-token-1-1", token)
+
+	// The rotated service account token is exchanged once the platform token expires soon.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token-2"), 0o600))
+	now = now.Add(5 * time.Minute)
+
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "platform-sa-token-2-2", token)
+	assert.Equal(t, int32(2), exchanges.Load())
+}
+
+func TestTokenSource_Token_exchangeFailure(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token"), 0o600))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		http.Error(rw, "invalid audience", http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+
+	source, err := NewTokenSource(srv.URL, tokenFile)
+	require.NoError(t, err)
+
+	_, err = source.Token(context.Background())
+	assert.EqualError(t, err, "exchange service account token: failed with code 401: invalid audience")
+}
+
+func TestTokenSource_Wrap(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("sa-token"), 0o600))
+
+	var exchanges atomic.Int32
+	var revoked atomic.Bool
+	platform := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token-exchange" {
+			exchanges.Add(1)
+			_ = json.NewEncoder(rw).Encode(tokenResp{AccessToken: "platform-token", ExpiresIn: 600})
+			return
+		}
+
+		assert.Equal(t, "Bearer platform-token", req.Header.Get("Authorization"))
+		if revoked.Load() {
+			rw.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(platform.Close)
+
+	other := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer static", req.Header.Get("Authorization"))
+	}))
+	t.Cleanup(other.Close)
+
+	source, err := NewTokenSource(platform.URL, tokenFile)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: source.Wrap(nil)}
+
+	send := func(url string) int {
+		t.Helper()
+
+		req, errReq := http.NewRequest(http.MethodGet, url, http.NoBody)
+		require.NoError(t, errReq)
+		req.Header.Set("Authorization", "Bearer static")
+
+		resp, errDo := client.Do(req)
+		require.NoError(t, errDo)
+		require.NoError(t, resp.Body.Close())
+
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, send(platform.URL+"/config"))
+	assert.Equal(t, http.StatusOK, send(platform.URL+"/config"))
+	assert.Equal(t, http.StatusOK, send(other.URL))
+	assert.Equal(t, int32(1), exchanges.Load())
+
+	// A rejected token is exchanged again for the next requests.
+	revoked.Store(true)
+	assert.Equal(t, http.StatusUnauthorized, send(platform.URL+"/config"))
+	revoked.Store(false)
+	assert.Equal(t, http.StatusOK, send(platform.URL+"/config"))
+	assert.Equal(t, int32(2), exchanges.Load())
+}
+
+func TestTokenSource_Wrap_nil(t *testing.T) {
+	var source *TokenSource
+
+	assert.Equal(t, http.DefaultTransport, source.Wrap(http.DefaultTransport))
+}
@@ -10,7 +10,7 @@ docker build --build-arg KUBE_VERSION=v0.20.15 --build-arg USER=$USER -f "./scri
              -t "${IMAGE_NAME}" \
              "."
 
-cmd="/go/src/k8s.io/code-generator/generate-groups.sh all $PROJECT_MODULE/pkg/crd/generated/client/hub $PROJECT_MODULE/pkg/crd/api hub:v1alpha1"
+cmd="/go/src/k8s.io/code-generator/generate-groups.sh all $PROJECT_MODULE/pkg/crd/generated/client/hub $PROJECT_MODULE/pkg/crd/api hub:v1alpha1,v1alpha2"
 
 echo "Generating Hub clientSet code ..."
 docker run --rm \
@@ -27,7 +27,7 @@ docker run --rm \
            "${IMAGE_NAME}" $cmd
 
 
-cmd="controller-gen crd:crdVersions=v1 paths=./pkg/crd/api/hub/v1alpha1/... output:dir=."
+cmd="controller-gen crd:crdVersions=v1 paths=./pkg/crd/api/hub/... output:dir=."
 
 echo "Generating the CRD definitions ..."
 docker run --rm \
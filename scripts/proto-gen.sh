@@ -0,0 +1,22 @@
+#!/bin/bash -e
+
+set -e -o pipefail
+
+PROJECT_MODULE="github.com/traefik/hub-agent-kubernetes"
+IMAGE_NAME="protobuf-codegen:latest"
+
+echo "Building protobuf codegen Docker image..."
+docker build --build-arg PROTOC_VERSION=23.4 --build-arg USER=$USER -f "./scripts/protogen.Dockerfile" \
+             -t "${IMAGE_NAME}" \
+             "."
+
+cmd="protoc --proto_path=pkg/proto \
+            --go_out=pkg/proto --go_opt=paths=source_relative \
+            --go-grpc_out=pkg/proto --go-grpc_opt=paths=source_relative \
+            $(cd pkg/proto && find . -name '*.proto' | sed 's|^\./||' | tr '\n' ' ')"
+
+echo "Generating the gRPC services code ..."
+docker run --rm \
+           -v "$(pwd):/go/src/${PROJECT_MODULE}" \
+           -w "/go/src/${PROJECT_MODULE}" \
+           "${IMAGE_NAME}" $cmd